@@ -21,6 +21,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/controller"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messaging/msghandler"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	arieshttp "github.com/hyperledger/aries-framework-go/pkg/didcomm/transport/http"
@@ -523,6 +524,7 @@ func startAgent(parameters *agentParameters) error {
 	}
 
 	router := mux.NewRouter()
+	router.Use(rest.CorrelationIDMiddleware)
 
 	if parameters.token != "" {
 		router.Use(authorizationMiddleware(parameters.token))
@@ -537,7 +539,9 @@ func startAgent(parameters *agentParameters) error {
 	handler := cors.New(
 		cors.Options{
 			AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodHead},
-			AllowedHeaders: []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization"},
+			AllowedHeaders: []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization",
+				rest.CorrelationIDHeader},
+			ExposedHeaders: []string{rest.CorrelationIDHeader},
 		},
 	).Handler(router)
 
@@ -64,6 +64,9 @@ type protocolService interface {
 	// CreateImplicitInvitation creates implicit invitation. Inviter DID is required, invitee DID is optional.
 	// If invitee DID is not provided new peer DID will be created for implicit invitation exchange request.
 	CreateImplicitInvitation(inviterLabel, inviterDID, inviteeLabel, inviteeDID string) (string, error)
+
+	// UpdateConnectionRouting updates the routing of my DID document of the connection and notifies the peer.
+	UpdateConnectionRouting(connectionID string, routingKeys []string, endpoint string) error
 }
 
 // New return new instance of didexchange client
@@ -271,8 +274,59 @@ func (c *Client) GetConnectionAtState(connectionID, stateID string) (*Connection
 	}, nil
 }
 
-// RemoveConnection removes connection record for given id
+// UpdateConnectionRouting updates the routing keys and service endpoint advertised to the peer of the connection
+// (e.g. after a mediation refresh) and sends them to the peer. The service endpoint is kept if endpoint is empty.
+func (c *Client) UpdateConnectionRouting(connectionID string, routingKeys []string, endpoint string) error {
+	err := c.didexchangeSvc.UpdateConnectionRouting(connectionID, routingKeys, endpoint)
+	if err != nil {
+		return fmt.Errorf("update connection routing: %w", err)
+	}
+
+	return nil
+}
+
+// SetConnectionDIDCommVersion selects the DIDComm plaintext message structure (v1 or v2) of the messages sent
+// to the peer of the connection. Messages from the peer are accepted in both structures.
+func (c *Client) SetConnectionDIDCommVersion(connectionID string, version service.DIDCommVersion) error {
+	if version != service.DIDCommV1 && version != service.DIDCommV2 {
+		return fmt.Errorf("unsupported DIDComm version: %s", version)
+	}
+
+	record, err := c.connectionStore.GetConnectionRecord(connectionID)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return ErrConnectionNotFound
+		}
+
+		return fmt.Errorf("cannot fetch state from store: connectionid=%s err=%w", connectionID, err)
+	}
+
+	record.DIDCommVersion = string(version)
+
+	err = c.connectionStore.SaveConnectionRecord(record)
+	if err != nil {
+		return fmt.Errorf("set connection DIDComm version: %w", err)
+	}
+
+	return nil
+}
+
+// connectionKeyReleaser is implemented by the route services allocating a recipient key to each connection (see
+// route.Service.AllocateKey).
+type connectionKeyReleaser interface {
+	ReleaseKey(connectionID string, deleteKey bool) error
+}
+
+// RemoveConnection removes connection record for given id. The recipient key allocated to the connection by the
+// router, if any, is removed from the router first.
 func (c *Client) RemoveConnection(connectionID string) error {
+	if releaser, ok := c.routeSvc.(connectionKeyReleaser); ok {
+		err := releaser.ReleaseKey(connectionID, false)
+		if err != nil && !errors.Is(err, storage.ErrDataNotFound) && !errors.Is(err, route.ErrRouterNotRegistered) {
+			return fmt.Errorf("cannot release the connection key from the router: err=%w", err)
+		}
+	}
+
 	err := c.connectionStore.RemoveConnection(connectionID)
 	if err != nil {
 		return fmt.Errorf("cannot remove connection from the store: err=%w", err)
@@ -557,6 +557,48 @@ func TestClient_RemoveConnection(t *testing.T) {
 		require.Error(t, err)
 		require.Equal(t, err.Error(), ErrConnectionNotFound.Error())
 	})
+	t.Run("test the connection key is released", func(t *testing.T) {
+		var released []string
+
+		routeSvc := &mockroute.MockRouteSvc{
+			ReleaseKeyFunc: func(connectionID string, deleteKey bool) error {
+				if connectionID == "release-error" {
+					return errors.New("release error")
+				}
+
+				released = append(released, connectionID)
+
+				return nil
+			},
+		}
+
+		c, err := New(&mockprovider.Provider{
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mocksvc.MockDIDExchangeSvc{},
+				route.Coordination:      routeSvc,
+			},
+		})
+		require.NoError(t, err)
+
+		for _, connID := range []string{"id1", "release-error"} {
+			require.NoError(t, c.connectionStore.SaveConnectionRecord(&connection.Record{
+				ConnectionID: connID, ThreadID: "thid-" + connID, State: "complete",
+			}))
+		}
+
+		require.NoError(t, c.RemoveConnection("id1"))
+		require.Equal(t, []string{"id1"}, released)
+
+		// the connection is kept if its key can't be released
+		err = c.RemoveConnection("release-error")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cannot release the connection key from the router")
+
+		_, err = c.GetConnection("release-error")
+		require.NoError(t, err)
+	})
 	t.Run("test error data not found", func(t *testing.T) {
 		svc, err := didexchange.New(&mockprotocol.MockProvider{
 			ServiceMap: map[string]interface{}{
@@ -581,6 +623,46 @@ func TestClient_RemoveConnection(t *testing.T) {
 	})
 }
 
+func TestClient_SetConnectionDIDCommVersion(t *testing.T) {
+	c, err := New(&mockprovider.Provider{
+		TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+		StorageProviderValue:          mockstore.NewMockStoreProvider(),
+		ServiceMap: map[string]interface{}{
+			didexchange.DIDExchange: &mocksvc.MockDIDExchangeSvc{},
+			route.Coordination:      &mockroute.MockRouteSvc{},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("test success", func(t *testing.T) {
+		connRec := &connection.Record{ConnectionID: "id1", ThreadID: "thid1", State: "completed"}
+		require.NoError(t, c.connectionStore.SaveConnectionRecord(connRec))
+
+		require.NoError(t, c.SetConnectionDIDCommVersion("id1", service.DIDCommV2))
+
+		conn, err := c.GetConnection("id1")
+		require.NoError(t, err)
+		require.Equal(t, string(service.DIDCommV2), conn.DIDCommVersion)
+
+		require.NoError(t, c.SetConnectionDIDCommVersion("id1", service.DIDCommV1))
+
+		conn, err = c.GetConnection("id1")
+		require.NoError(t, err)
+		require.Equal(t, string(service.DIDCommV1), conn.DIDCommVersion)
+	})
+
+	t.Run("test unsupported version", func(t *testing.T) {
+		err := c.SetConnectionDIDCommVersion("id1", "v3")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported DIDComm version: v3")
+	})
+
+	t.Run("test connection not found", func(t *testing.T) {
+		err := c.SetConnectionDIDCommVersion("sample-id", service.DIDCommV2)
+		require.Equal(t, ErrConnectionNotFound, err)
+	})
+}
+
 func TestClient_HandleInvitation(t *testing.T) {
 	t.Run("test success", func(t *testing.T) {
 		c, err := New(&mockprovider.Provider{
@@ -726,6 +808,51 @@ func TestClient_CreateImplicitInvitationWithDID(t *testing.T) {
 	})
 }
 
+func TestClient_UpdateConnectionRouting(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		var updated []string
+
+		c, err := New(&mockprovider.Provider{
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mocksvc.MockDIDExchangeSvc{
+					UpdateRoutingFunc: func(connectionID string, routingKeys []string, endpoint string) error {
+						updated = append([]string{connectionID, endpoint}, routingKeys...)
+
+						return nil
+					},
+				},
+				route.Coordination: &mockroute.MockRouteSvc{},
+			},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, c.UpdateConnectionRouting("conn-1", []string{"key-1"}, "endpoint"))
+		require.Equal(t, []string{"conn-1", "endpoint", "key-1"}, updated)
+	})
+
+	t.Run("test error from service", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mocksvc.MockDIDExchangeSvc{
+					UpdateRoutingFunc: func(string, []string, string) error {
+						return errors.New("update error")
+					},
+				},
+				route.Coordination: &mockroute.MockRouteSvc{},
+			},
+		})
+		require.NoError(t, err)
+
+		err = c.UpdateConnectionRouting("conn-1", nil, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "update error")
+	})
+}
+
 func TestClient_QueryConnectionsByParams(t *testing.T) {
 	t.Run("test get all connections", func(t *testing.T) {
 		svc, err := didexchange.New(&mockprotocol.MockProvider{
@@ -0,0 +1,227 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+const (
+	// states
+	stateNameCompleted = "completed"
+
+	// errors
+	errMsgSvcNameRequired      = "service name is required"
+	errMsgTypeRequired         = "message type is required"
+	errMsgConnectionIDRequired = "connection ID is required"
+	errMsgConnectionNotReady   = "connection is not in completed state"
+	errMsgInboundIDRequired    = "inbound message ID is required"
+	errMsgReceiptsUnsupported  = "the messenger does not support delivery receipts"
+)
+
+// receiptSender is implemented by the messengers supporting delivery receipts (see messenger.SendWithReceipt).
+type receiptSender interface {
+	SendWithReceipt(msg service.DIDCommMsgMap, myDID, theirDID string, timeout time.Duration) error
+}
+
+// provider contains dependencies for the messaging client and is typically created by using aries.Context()
+type provider interface {
+	Messenger() service.Messenger
+	StorageProvider() storage.Provider
+	TransientStorageProvider() storage.Provider
+}
+
+// MessageHandler maintains registered message services
+// and it allows dynamic registration of message services.
+type MessageHandler interface {
+	// Services returns list of available message services in this message handler
+	Services() []dispatcher.MessageService
+	// Register registers given message services to this message handler
+	Register(msgServices ...dispatcher.MessageService) error
+	// Unregister unregisters message service with given name from this message handler
+	Unregister(name string) error
+}
+
+// Notifier represents a notification dispatcher.
+type Notifier interface {
+	Notify(topic string, message []byte) error
+}
+
+// Client enables access to messaging features.
+type Client struct {
+	messenger        service.Messenger
+	connectionLookup *connection.Lookup
+	registrar        MessageHandler
+	notifier         Notifier
+}
+
+// New returns new instance of message client.
+func New(ctx provider, registrar MessageHandler, notifier Notifier) (*Client, error) {
+	if registrar == nil || notifier == nil {
+		return nil, errors.New("message handler and notifier are mandatory")
+	}
+
+	connectionLookup, err := connection.NewLookup(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize connection lookup : %w", err)
+	}
+
+	return &Client{
+		messenger:        ctx.Messenger(),
+		connectionLookup: connectionLookup,
+		registrar:        registrar,
+		notifier:         notifier,
+	}, nil
+}
+
+// RegisterMessageService registers new message service which accepts incoming messages of given type and purpose.
+// Every accepted message will be sent to the notifier using service name as topic.
+//
+// Args:
+//
+// name - is name of the message service, it is also the topic on which accepted messages get notified (mandatory).
+//
+// msgType - is type of the messages to be handled by this message service (mandatory).
+//
+// purpose - is optional list of purposes, if provided incoming message will be accepted only
+// if any one of its purposes matches.
+func (c *Client) RegisterMessageService(name, msgType string, purpose []string) error {
+	if name == "" {
+		return errors.New(errMsgSvcNameRequired)
+	}
+
+	if msgType == "" {
+		return errors.New(errMsgTypeRequired)
+	}
+
+	if err := c.registrar.Register(newMessageService(name, msgType, purpose, c.notifier)); err != nil {
+		return fmt.Errorf("register message service : %w", err)
+	}
+
+	return nil
+}
+
+// UnregisterMessageService unregisters given message service by name.
+func (c *Client) UnregisterMessageService(name string) error {
+	if name == "" {
+		return errors.New(errMsgSvcNameRequired)
+	}
+
+	if err := c.registrar.Unregister(name); err != nil {
+		return fmt.Errorf("unregister message service : %w", err)
+	}
+
+	return nil
+}
+
+// Services returns list of names of registered message services.
+func (c *Client) Services() []string {
+	names := []string{}
+	for _, svc := range c.registrar.Services() {
+		names = append(names, svc.Name())
+	}
+
+	return names
+}
+
+// SendMessage sends given DIDComm message to the agent on the other side of given connection.
+// Message will be packed and dispatched to the service endpoint of the connection by starting a new thread,
+// message ID will be generated if it is missing.
+func (c *Client) SendMessage(connectionID string, msg json.RawMessage) error {
+	didCommMsg, conn, err := c.prepareMessage(connectionID, msg)
+	if err != nil {
+		return err
+	}
+
+	if err := c.messenger.Send(didCommMsg, conn.MyDID, conn.TheirDID); err != nil {
+		return fmt.Errorf("send message : %w", err)
+	}
+
+	return nil
+}
+
+// SendWithReceipt sends given DIDComm message, like SendMessage, requesting an acknowledgement from the agent on the
+// other side of given connection. It blocks until the message is acknowledged or the timeout expires, then it returns
+// an error wrapping messenger.ErrReceiptTimeout.
+func (c *Client) SendWithReceipt(connectionID string, msg json.RawMessage, timeout time.Duration) error {
+	sender, ok := c.messenger.(receiptSender)
+	if !ok {
+		return errors.New(errMsgReceiptsUnsupported)
+	}
+
+	didCommMsg, conn, err := c.prepareMessage(connectionID, msg)
+	if err != nil {
+		return err
+	}
+
+	if err := sender.SendWithReceipt(didCommMsg, conn.MyDID, conn.TheirDID, timeout); err != nil {
+		return fmt.Errorf("send message with receipt : %w", err)
+	}
+
+	return nil
+}
+
+// prepareMessage validates the message msg to send over the connection connectionID, which must be completed.
+func (c *Client) prepareMessage(connectionID string, msg json.RawMessage) (service.DIDCommMsgMap,
+	*connection.Record, error) {
+	if connectionID == "" {
+		return nil, nil, errors.New(errMsgConnectionIDRequired)
+	}
+
+	didCommMsg, err := service.ParseDIDCommMsgMap(msg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("send message : %w", err)
+	}
+
+	if didCommMsg.Type() == "" {
+		return nil, nil, errors.New(errMsgTypeRequired)
+	}
+
+	conn, err := c.connectionLookup.GetConnectionRecord(connectionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("send message : get connection : %w", err)
+	}
+
+	if conn.State != stateNameCompleted {
+		return nil, nil, errors.New(errMsgConnectionNotReady)
+	}
+
+	return didCommMsg, conn, nil
+}
+
+// ReplyTo sends given reply message to the agent which sent the inbound message.
+// Thread ID and parent thread ID of the inbound message are copied to the `~thread` decorator of the reply
+// and the reply is sent over the connection on which inbound message was received.
+// Any `~thread` decorator in the reply message will be overwritten.
+func (c *Client) ReplyTo(inbound service.DIDCommMsg, reply json.RawMessage) error {
+	if inbound == nil || inbound.ID() == "" {
+		return errors.New(errMsgInboundIDRequired)
+	}
+
+	didCommMsg, err := service.ParseDIDCommMsgMap(reply)
+	if err != nil {
+		return fmt.Errorf("reply to message : %w", err)
+	}
+
+	if didCommMsg.Type() == "" {
+		return errors.New(errMsgTypeRequired)
+	}
+
+	if err := c.messenger.ReplyTo(inbound.ID(), didCommMsg); err != nil {
+		return fmt.Errorf("reply to message : %w", err)
+	}
+
+	return nil
+}
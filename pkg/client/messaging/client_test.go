@@ -0,0 +1,610 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messaging/msghandler"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messenger"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/context"
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
+	mockmsghandler "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/msghandler"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol"
+	mocksvc "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/service"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+const (
+	sampleMsgType = "https://didcomm.org/custom/1.0/msg"
+	sampleSvcName = "custom-svc-01"
+	sampleConnID  = "sample-conn-01"
+	sampleMyDID   = "did:peer:sample-my-did"
+	sampleTheir   = "did:peer:sample-their-did"
+	theirEndpoint = "https://their.agent.example.com/didcomm"
+)
+
+type mockNotifier struct {
+	notifyFunc func(topic string, message []byte) error
+}
+
+func (n *mockNotifier) Notify(topic string, message []byte) error {
+	if n.notifyFunc != nil {
+		return n.notifyFunc(topic, message)
+	}
+
+	return nil
+}
+
+type recordingPackager struct {
+	envelopes []*commontransport.Envelope
+}
+
+func (p *recordingPackager) PackMessage(e *commontransport.Envelope) ([]byte, error) {
+	p.envelopes = append(p.envelopes, e)
+
+	return []byte("packed-" + string(e.Message)), nil
+}
+
+func (p *recordingPackager) UnpackMessage(encMessage []byte) (*commontransport.Envelope, error) {
+	return nil, errors.New("not supported")
+}
+
+type recordingTransport struct {
+	sent         [][]byte
+	destinations []*service.Destination
+}
+
+func (o *recordingTransport) Start(prov transport.Provider) error {
+	return nil
+}
+
+func (o *recordingTransport) Send(data []byte, destination *service.Destination) (string, error) {
+	o.sent = append(o.sent, data)
+	o.destinations = append(o.destinations, destination)
+
+	return "", nil
+}
+
+func (o *recordingTransport) AcceptRecipient([]string) bool {
+	return false
+}
+
+func (o *recordingTransport) Accept(url string) bool {
+	return url == theirEndpoint
+}
+
+func TestNew(t *testing.T) {
+	t.Run("test new client", func(t *testing.T) {
+		c, err := New(&protocol.MockProvider{}, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+		require.NotNil(t, c)
+	})
+
+	t.Run("test new client with missing arguments", func(t *testing.T) {
+		c, err := New(&protocol.MockProvider{}, nil, &mockNotifier{})
+		require.Error(t, err)
+		require.Nil(t, c)
+
+		c, err = New(&protocol.MockProvider{}, msghandler.NewRegistrar(), nil)
+		require.Error(t, err)
+		require.Nil(t, c)
+	})
+
+	t.Run("test new client with store error", func(t *testing.T) {
+		c, err := New(&protocol.MockProvider{
+			StoreProvider: &mockstore.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("store error")},
+		}, msghandler.NewRegistrar(), &mockNotifier{})
+		require.Error(t, err)
+		require.Nil(t, c)
+		require.Contains(t, err.Error(), "store error")
+	})
+}
+
+func TestClient_RegisterMessageService(t *testing.T) {
+	t.Run("test register message service and deliver matching messages", func(t *testing.T) {
+		registrar := msghandler.NewRegistrar()
+
+		topics := make(chan []byte, 1)
+		notifier := &mockNotifier{notifyFunc: func(topic string, message []byte) error {
+			require.Equal(t, sampleSvcName, topic)
+			topics <- message
+
+			return nil
+		}}
+
+		c, err := New(&protocol.MockProvider{}, registrar, notifier)
+		require.NoError(t, err)
+
+		err = c.RegisterMessageService(sampleSvcName, sampleMsgType, []string{"prp-01"})
+		require.NoError(t, err)
+		require.Equal(t, []string{sampleSvcName}, c.Services())
+
+		msg, err := service.ParseDIDCommMsgMap([]byte(`{"@id":"msg-01","@type":"` + sampleMsgType +
+			`","~purpose":["prp-01"],"text":"hello"}`))
+		require.NoError(t, err)
+
+		svcs := registrar.Services()
+		require.Len(t, svcs, 1)
+		require.False(t, svcs[0].Accept("https://didcomm.org/other/1.0/msg", []string{"prp-01"}))
+		require.False(t, svcs[0].Accept(sampleMsgType, []string{"prp-02"}))
+		require.True(t, svcs[0].Accept(sampleMsgType, []string{"prp-01"}))
+
+		_, err = svcs[0].HandleInbound(msg, "sample-my-did", "sample-their-did")
+		require.NoError(t, err)
+
+		var topic Message
+		require.NoError(t, json.Unmarshal(<-topics, &topic))
+		require.Equal(t, "sample-my-did", topic.MyDID)
+		require.Equal(t, "sample-their-did", topic.TheirDID)
+		require.Equal(t, "msg-01", topic.Message.ID())
+		require.Equal(t, sampleMsgType, topic.Message.Type())
+		require.Equal(t, "hello", topic.Message["text"])
+	})
+
+	t.Run("test register message service without purpose", func(t *testing.T) {
+		registrar := msghandler.NewRegistrar()
+
+		c, err := New(&protocol.MockProvider{}, registrar, &mockNotifier{})
+		require.NoError(t, err)
+
+		err = c.RegisterMessageService(sampleSvcName, sampleMsgType, nil)
+		require.NoError(t, err)
+
+		require.True(t, registrar.Services()[0].Accept(sampleMsgType, nil))
+		require.True(t, registrar.Services()[0].Accept(sampleMsgType, []string{"prp-01"}))
+	})
+
+	t.Run("test register message service validation", func(t *testing.T) {
+		c, err := New(&protocol.MockProvider{}, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		err = c.RegisterMessageService("", sampleMsgType, nil)
+		require.EqualError(t, err, errMsgSvcNameRequired)
+
+		err = c.RegisterMessageService(sampleSvcName, "", nil)
+		require.EqualError(t, err, errMsgTypeRequired)
+	})
+
+	t.Run("test register message service error", func(t *testing.T) {
+		registrar := mockmsghandler.NewMockMsgServiceProvider()
+		registrar.RegisterErr = errors.New("register error")
+
+		c, err := New(&protocol.MockProvider{}, registrar, &mockNotifier{})
+		require.NoError(t, err)
+
+		err = c.RegisterMessageService(sampleSvcName, sampleMsgType, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "register error")
+	})
+
+	t.Run("test notifier error", func(t *testing.T) {
+		registrar := msghandler.NewRegistrar()
+
+		c, err := New(&protocol.MockProvider{}, registrar, &mockNotifier{notifyFunc: func(topic string, message []byte) error {
+			return errors.New("notify error")
+		}})
+		require.NoError(t, err)
+
+		err = c.RegisterMessageService(sampleSvcName, sampleMsgType, nil)
+		require.NoError(t, err)
+
+		_, err = registrar.Services()[0].HandleInbound(service.NewDIDCommMsgMap(struct {
+			Type string `json:"@type"`
+		}{Type: sampleMsgType}), "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "notify error")
+	})
+}
+
+func TestClient_UnregisterMessageService(t *testing.T) {
+	registrar := msghandler.NewRegistrar()
+
+	c, err := New(&protocol.MockProvider{}, registrar, &mockNotifier{})
+	require.NoError(t, err)
+
+	err = c.UnregisterMessageService("")
+	require.EqualError(t, err, errMsgSvcNameRequired)
+
+	err = c.UnregisterMessageService(sampleSvcName)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unregister message service")
+
+	require.NoError(t, c.RegisterMessageService(sampleSvcName, sampleMsgType, nil))
+	require.NoError(t, c.UnregisterMessageService(sampleSvcName))
+	require.Empty(t, c.Services())
+}
+
+func TestClient_SendMessage(t *testing.T) {
+	t.Run("test send message over connection", func(t *testing.T) {
+		packager := &recordingPackager{}
+		outbound := &recordingTransport{}
+
+		outboundCtx, err := context.New(
+			context.WithPackager(packager),
+			context.WithOutboundTransports(outbound),
+			context.WithVDRIRegistry(&mockvdri.MockVDRIRegistry{
+				ResolveFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+					return newPeerDIDDoc(didID), nil
+				},
+			}),
+		)
+		require.NoError(t, err)
+
+		storeProvider := mockstore.NewMockStoreProvider()
+
+		messengerCtx, err := context.New(
+			context.WithOutboundDispatcher(dispatcher.NewOutbound(outboundCtx)),
+			context.WithStorageProvider(storeProvider),
+		)
+		require.NoError(t, err)
+
+		msgr, err := messenger.NewMessenger(messengerCtx)
+		require.NoError(t, err)
+
+		ctx, err := context.New(
+			context.WithMessengerHandler(msgr),
+			context.WithStorageProvider(storeProvider),
+			context.WithTransientStorageProvider(mockstore.NewMockStoreProvider()),
+		)
+		require.NoError(t, err)
+
+		c, err := New(ctx, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		saveConnection(t, ctx, &connection.Record{
+			ConnectionID: sampleConnID,
+			State:        stateNameCompleted,
+			MyDID:        sampleMyDID,
+			TheirDID:     sampleTheir,
+		})
+
+		err = c.SendMessage(sampleConnID, json.RawMessage(`{"@id":"msg-01","@type":"`+sampleMsgType+`","text":"hi"}`))
+		require.NoError(t, err)
+
+		require.Len(t, packager.envelopes, 1)
+		require.Equal(t, []string{base58.Encode(keyFor(sampleTheir))}, packager.envelopes[0].ToVerKeys)
+		require.Equal(t, keyFor(sampleMyDID), packager.envelopes[0].FromVerKey)
+
+		sent, err := service.ParseDIDCommMsgMap(packager.envelopes[0].Message)
+		require.NoError(t, err)
+		require.Equal(t, "msg-01", sent.ID())
+		require.Equal(t, sampleMsgType, sent.Type())
+
+		require.Len(t, outbound.sent, 1)
+		require.Equal(t, "packed-"+string(packager.envelopes[0].Message), string(outbound.sent[0]))
+		require.Equal(t, theirEndpoint, outbound.destinations[0].ServiceEndpoint)
+	})
+
+	t.Run("test send message validation", func(t *testing.T) {
+		c, err := New(&protocol.MockProvider{}, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		err = c.SendMessage("", json.RawMessage(`{"@type":"`+sampleMsgType+`"}`))
+		require.EqualError(t, err, errMsgConnectionIDRequired)
+
+		err = c.SendMessage(sampleConnID, json.RawMessage(`{"@id":"msg-01"}`))
+		require.EqualError(t, err, errMsgTypeRequired)
+
+		err = c.SendMessage(sampleConnID, json.RawMessage(`--`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid payload data format")
+	})
+
+	t.Run("test send message to unknown or incomplete connection", func(t *testing.T) {
+		prov := &protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+		}
+
+		c, err := New(prov, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		err = c.SendMessage(sampleConnID, json.RawMessage(`{"@type":"`+sampleMsgType+`"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get connection")
+
+		saveConnection(t, prov, &connection.Record{ConnectionID: sampleConnID, State: "requested"})
+
+		err = c.SendMessage(sampleConnID, json.RawMessage(`{"@type":"`+sampleMsgType+`"}`))
+		require.EqualError(t, err, errMsgConnectionNotReady)
+	})
+
+	t.Run("test send message messenger error", func(t *testing.T) {
+		prov := &protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			CustomMessenger:        &mocksvc.MockMessenger{ErrSend: errors.New("send error")},
+		}
+
+		c, err := New(prov, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		saveConnection(t, prov, &connection.Record{ConnectionID: sampleConnID, State: stateNameCompleted})
+
+		err = c.SendMessage(sampleConnID, json.RawMessage(`{"@type":"`+sampleMsgType+`"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "send error")
+	})
+}
+
+func TestClient_SendWithReceipt(t *testing.T) {
+	newClient := func(t *testing.T, msgr service.MessengerHandler) *Client {
+		ctx, err := context.New(
+			context.WithMessengerHandler(msgr),
+			context.WithStorageProvider(mockstore.NewMockStoreProvider()),
+			context.WithTransientStorageProvider(mockstore.NewMockStoreProvider()),
+		)
+		require.NoError(t, err)
+
+		c, err := New(ctx, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		saveConnection(t, ctx, &connection.Record{
+			ConnectionID: sampleConnID,
+			State:        stateNameCompleted,
+			MyDID:        sampleMyDID,
+			TheirDID:     sampleTheir,
+		})
+
+		return c
+	}
+
+	t.Run("test send message acknowledged by the other agent", func(t *testing.T) {
+		var msgr *messenger.Messenger
+
+		msgr, err := messenger.NewMessenger(&protocol.MockProvider{
+			StoreProvider: mockstore.NewMockStoreProvider(),
+			CustomOutbound: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					sent := msg.(service.DIDCommMsgMap)
+					require.Contains(t, sent, "~please_ack")
+
+					// the other agent acknowledges the message
+					go func() {
+						consumed, err := msgr.HandleAck(service.DIDCommMsgMap{
+							"@id":     "ack-01",
+							"@type":   messenger.AckMsgType,
+							"~thread": map[string]interface{}{"thid": sent.ID()},
+						}, myDID, theirDID)
+						require.NoError(t, err)
+						require.True(t, consumed)
+					}()
+
+					return nil
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		err = newClient(t, msgr).SendWithReceipt(sampleConnID,
+			json.RawMessage(`{"@id":"msg-01","@type":"`+sampleMsgType+`"}`), time.Second)
+		require.NoError(t, err)
+	})
+
+	t.Run("test send message not acknowledged in time", func(t *testing.T) {
+		msgr, err := messenger.NewMessenger(&protocol.MockProvider{
+			StoreProvider:  mockstore.NewMockStoreProvider(),
+			CustomOutbound: &mockdispatcher.MockOutbound{},
+		})
+		require.NoError(t, err)
+
+		err = newClient(t, msgr).SendWithReceipt(sampleConnID,
+			json.RawMessage(`{"@id":"msg-01","@type":"`+sampleMsgType+`"}`), 10*time.Millisecond)
+		require.True(t, errors.Is(err, messenger.ErrReceiptTimeout))
+		require.Contains(t, err.Error(), "send message with receipt")
+	})
+
+	t.Run("test send message validation", func(t *testing.T) {
+		msgr, err := messenger.NewMessenger(&protocol.MockProvider{StoreProvider: mockstore.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		c := newClient(t, msgr)
+
+		err = c.SendWithReceipt("", json.RawMessage(`{"@type":"`+sampleMsgType+`"}`), time.Second)
+		require.EqualError(t, err, errMsgConnectionIDRequired)
+
+		err = c.SendWithReceipt(sampleConnID, json.RawMessage(`{"@id":"msg-01"}`), time.Second)
+		require.EqualError(t, err, errMsgTypeRequired)
+
+		err = c.SendWithReceipt("unknown", json.RawMessage(`{"@type":"`+sampleMsgType+`"}`), time.Second)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get connection")
+	})
+
+	t.Run("test messenger without delivery receipts", func(t *testing.T) {
+		prov := &protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			CustomMessenger:        &mocksvc.MockMessenger{},
+		}
+
+		c, err := New(prov, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		err = c.SendWithReceipt(sampleConnID, json.RawMessage(`{"@type":"`+sampleMsgType+`"}`), time.Second)
+		require.EqualError(t, err, errMsgReceiptsUnsupported)
+	})
+}
+
+func saveConnection(t *testing.T, prov connectionStoreProvider, record *connection.Record) {
+	t.Helper()
+
+	recorder, err := connection.NewRecorder(prov)
+	require.NoError(t, err)
+	require.NoError(t, recorder.SaveConnectionRecord(record))
+}
+
+type connectionStoreProvider interface {
+	StorageProvider() storage.Provider
+	TransientStorageProvider() storage.Provider
+}
+
+func keyFor(didID string) []byte {
+	return []byte(fmt.Sprintf("%-32s", didID))[:32]
+}
+
+func newPeerDIDDoc(didID string) *did.Doc {
+	keyID := didID + "#key-1"
+
+	return &did.Doc{
+		Context: []string{did.Context},
+		ID:      didID,
+		PublicKey: []did.PublicKey{{
+			ID:         keyID,
+			Type:       "Ed25519VerificationKey2018",
+			Controller: didID,
+			Value:      keyFor(didID),
+		}},
+		Service: []did.Service{{
+			ID:              didID + "#didcomm",
+			Type:            "did-communication",
+			ServiceEndpoint: theirEndpoint,
+			RecipientKeys:   []string{keyID},
+		}},
+	}
+}
+
+func TestClient_ReplyTo(t *testing.T) {
+	t.Run("test reply to threaded message", func(t *testing.T) {
+		const (
+			threadID       = "thread-01"
+			parentThreadID = "parent-thread-01"
+		)
+
+		sent := make(chan service.DIDCommMsgMap, 1)
+
+		msgr, err := messenger.NewMessenger(&protocol.MockProvider{
+			StoreProvider: mockstore.NewMockStoreProvider(),
+			CustomOutbound: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					require.Equal(t, sampleMyDID, myDID)
+					require.Equal(t, sampleTheir, theirDID)
+
+					didCommMsg, ok := msg.(service.DIDCommMsgMap)
+					require.True(t, ok)
+
+					sent <- didCommMsg
+
+					return nil
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		ctx, err := context.New(
+			context.WithMessengerHandler(msgr),
+			context.WithStorageProvider(mockstore.NewMockStoreProvider()),
+			context.WithTransientStorageProvider(mockstore.NewMockStoreProvider()),
+		)
+		require.NoError(t, err)
+
+		c, err := New(ctx, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		inbound, err := service.ParseDIDCommMsgMap([]byte(`{"@id":"msg-01","@type":"` + sampleMsgType +
+			`","~thread":{"thid":"` + threadID + `","pthid":"` + parentThreadID + `"}}`))
+		require.NoError(t, err)
+
+		// simulate inbound message handling done by the framework
+		require.NoError(t, msgr.HandleInbound(inbound, sampleMyDID, sampleTheir))
+
+		err = c.ReplyTo(inbound, json.RawMessage(`{"@type":"`+sampleMsgType+`","text":"reply"}`))
+		require.NoError(t, err)
+
+		reply := <-sent
+		require.NotEmpty(t, reply.ID())
+		require.NotEqual(t, inbound.ID(), reply.ID())
+		require.Equal(t, "reply", reply["text"])
+
+		thID, err := reply.ThreadID()
+		require.NoError(t, err)
+		require.Equal(t, threadID, thID)
+		require.Equal(t, parentThreadID, reply.ParentThreadID())
+	})
+
+	t.Run("test reply to message without thread", func(t *testing.T) {
+		sent := make(chan service.DIDCommMsgMap, 1)
+
+		msgr, err := messenger.NewMessenger(&protocol.MockProvider{
+			StoreProvider: mockstore.NewMockStoreProvider(),
+			CustomOutbound: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					sent <- msg.(service.DIDCommMsgMap)
+
+					return nil
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		ctx, err := context.New(
+			context.WithMessengerHandler(msgr),
+			context.WithStorageProvider(mockstore.NewMockStoreProvider()),
+			context.WithTransientStorageProvider(mockstore.NewMockStoreProvider()),
+		)
+		require.NoError(t, err)
+
+		c, err := New(ctx, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		inbound := service.DIDCommMsgMap{"@id": "msg-02", "@type": sampleMsgType}
+		require.NoError(t, msgr.HandleInbound(inbound, sampleMyDID, sampleTheir))
+
+		err = c.ReplyTo(inbound, json.RawMessage(`{"@type":"`+sampleMsgType+`"}`))
+		require.NoError(t, err)
+
+		thID, err := (<-sent).ThreadID()
+		require.NoError(t, err)
+		require.Equal(t, "msg-02", thID)
+	})
+
+	t.Run("test reply to validation", func(t *testing.T) {
+		c, err := New(&protocol.MockProvider{}, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		err = c.ReplyTo(nil, json.RawMessage(`{"@type":"`+sampleMsgType+`"}`))
+		require.EqualError(t, err, errMsgInboundIDRequired)
+
+		err = c.ReplyTo(service.DIDCommMsgMap{}, json.RawMessage(`{"@type":"`+sampleMsgType+`"}`))
+		require.EqualError(t, err, errMsgInboundIDRequired)
+
+		err = c.ReplyTo(service.DIDCommMsgMap{"@id": "msg-01"}, json.RawMessage(`{"@id":"msg-02"}`))
+		require.EqualError(t, err, errMsgTypeRequired)
+
+		err = c.ReplyTo(service.DIDCommMsgMap{"@id": "msg-01"}, json.RawMessage(`--`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid payload data format")
+	})
+
+	t.Run("test reply to messenger error", func(t *testing.T) {
+		c, err := New(&protocol.MockProvider{
+			CustomMessenger: &mocksvc.MockMessenger{ErrReplyTo: errors.New("reply error")},
+		}, msghandler.NewRegistrar(), &mockNotifier{})
+		require.NoError(t, err)
+
+		err = c.ReplyTo(service.DIDCommMsgMap{"@id": "msg-01"}, json.RawMessage(`{"@type":"`+sampleMsgType+`"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "reply error")
+	})
+}
@@ -0,0 +1,11 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package messaging enables the agent to handle generic DIDComm messages which are not handled by any of the
+// protocol services. Message services are registered by message type and purpose, and every matching inbound
+// message is delivered to the notifier using the message service name as topic.
+// (RFC Reference : https://github.com/hyperledger/aries-rfcs/blob/master/features/0351-purpose-decorator/README.md)
+package messaging
@@ -0,0 +1,77 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+)
+
+// Message is the topic payload sent to the notifier for every message accepted by a message service.
+type Message struct {
+	Message  service.DIDCommMsgMap `json:"message"`
+	MyDID    string                `json:"mydid"`
+	TheirDID string                `json:"theirdid"`
+}
+
+// newMessageService returns new message service instance.
+func newMessageService(name, msgType string, purpose []string, notifier Notifier) *msgService {
+	return &msgService{
+		name:     name,
+		msgType:  msgType,
+		purpose:  purpose,
+		notifier: notifier,
+	}
+}
+
+// msgService is generic message service implementation
+// which delegates handling to registered notifier.
+type msgService struct {
+	name     string
+	purpose  []string
+	msgType  string
+	notifier Notifier
+}
+
+func (m *msgService) Name() string {
+	return m.name
+}
+
+func (m *msgService) Accept(msgType string, purpose []string) bool {
+	if m.msgType != msgType {
+		return false
+	}
+
+	if len(m.purpose) == 0 {
+		return true
+	}
+
+	for _, purposeCriteria := range m.purpose {
+		for _, msgPurpose := range purpose {
+			if purposeCriteria == msgPurpose {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (m *msgService) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string) (string, error) {
+	bytes, err := json.Marshal(&Message{
+		Message:  msg.Clone(),
+		MyDID:    myDID,
+		TheirDID: theirDID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal topic : %w", err)
+	}
+
+	return "", m.notifier.Notify(m.name, bytes)
+}
@@ -10,26 +10,43 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/google/uuid"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 )
 
+var logger = log.New("aries-framework/client/outofband")
+
 const (
 	// RequestMsgType is the request message's '@type'.
 	RequestMsgType = outofband.RequestMsgType
+	// HandshakeReuseAcceptedMsgType is the '@type' of the message accepting the reuse of a connection.
+	HandshakeReuseAcceptedMsgType = outofband.HandshakeReuseAcceptedMsgType
+	// StateIDReuseAccepted is the state of the message events fired when the reuse of a connection is accepted.
+	StateIDReuseAccepted = outofband.StateIDReuseAccepted
+
+	ed25519KeyType = "Ed25519VerificationKey2018"
 )
 
 // RequestOptions allow you to customize the way request messages are built.
 type RequestOptions func(*Request) error
 
 type oobService interface {
-	AcceptRequest(request *outofband.Request) (string, error)
-	SaveRequest(request *outofband.Request) error
+	service.Event
+	AcceptRequest(request *outofband.Request, opts ...outofband.AcceptOption) (string, error)
+	SaveRequest(request *outofband.Request, opts ...outofband.SaveOption) error
+	SavePendingRequest(request *outofband.Request) error
+	RevokeRequest(id string) error
+	Acceptances(id string) ([]*outofband.Acceptance, error)
 }
 
 // Provider provides the dependencies for the client.
@@ -37,13 +54,18 @@ type Provider interface {
 	ServiceEndpoint() string
 	Service(id string) (interface{}, error)
 	LegacyKMS() legacykms.KeyManager
+	Signer() legacykms.Signer
+	VDRIRegistry() vdriapi.Registry
 }
 
 // Client for the Out-Of-Band protocol:
 // https://github.com/hyperledger/aries-rfcs/blob/master/features/0434-outofband/README.md
 type Client struct {
+	service.Event
 	didDocSvcFunc func() (*did.Service, error)
 	oobService    oobService
+	vdriRegistry  vdriapi.Registry
+	signer        legacykms.Signer
 }
 
 // New returns a new Client for the Out-Of-Band protocol.
@@ -59,17 +81,24 @@ func New(p Provider) (*Client, error) {
 	}
 
 	return &Client{
+		Event:         oobSvc,
 		didDocSvcFunc: didServiceBlockFunc(p),
 		oobService:    oobSvc,
+		vdriRegistry:  p.VDRIRegistry(),
+		signer:        p.Signer(),
 	}, nil
 }
 
 // CreateRequest creates and saves an Out-Of-Band request message.
-// At least one attachment must be provided.
+// At least one attachment must be provided. A request may offer several protocols (eg: a credential offer and a
+// proof request), each attachment then inlines a DIDComm message of a distinct @type.
 // Service entries can be optionally provided. If none are provided then a new one will be automatically created for
 // you.
+// The request is single-use unless created with WithMultiUse: it is consumed by its first acceptance and the
+// did-exchange requests of the other agents accepting it are rejected without response. Requests were never consumed before, requests
+// accepted by several agents must now be created with WithMultiUse.
 func (c *Client) CreateRequest(opts ...RequestOptions) (*Request, error) {
-	req := &Request{&outofband.Request{}}
+	req := &Request{Request: &outofband.Request{}}
 
 	for _, opt := range opts {
 		if err := opt(req); err != nil {
@@ -81,6 +110,10 @@ func (c *Client) CreateRequest(opts ...RequestOptions) (*Request, error) {
 		return nil, errors.New("must provide at least one attachment to create an out-of-band request")
 	}
 
+	if err := outofband.CheckAttachments(req.Requests); err != nil {
+		return nil, fmt.Errorf("invalid attachments : %w", err)
+	}
+
 	if len(req.Service) == 0 {
 		svc, err := c.didDocSvcFunc()
 		if err != nil {
@@ -93,7 +126,20 @@ func (c *Client) CreateRequest(opts ...RequestOptions) (*Request, error) {
 	req.ID = uuid.New().String()
 	req.Type = RequestMsgType
 
-	err := c.oobService.SaveRequest(req.Request)
+	if req.sign {
+		err := c.signRequest(req.Request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign request : %w", err)
+		}
+	}
+
+	var saveOpts []outofband.SaveOption
+
+	if req.multiUse {
+		saveOpts = append(saveOpts, outofband.WithMultiUse())
+	}
+
+	err := c.oobService.SaveRequest(req.Request, saveOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("outofband service failed to save request : %w", err)
 	}
@@ -101,17 +147,100 @@ func (c *Client) CreateRequest(opts ...RequestOptions) (*Request, error) {
 	return req, nil
 }
 
-// AcceptRequest from another agent and return the ID of a new connection record.
-func (c *Client) AcceptRequest(r *Request) (string, error) {
+// AcceptOptions allow you to customize the way requests are accepted.
+type AcceptOptions func(*acceptOpts)
+
+type acceptOpts struct {
+	attachmentHandler  func(*decorator.Attachment) error
+	selectedAttachment string
+	reuseConnection    string
+	inviterKey         string
+	inviterDID         string
+}
+
+// WithAttachmentHandler sets a handler invoked with each attachment of the request before the did-exchange
+// proceeds. The request is not accepted if the handler returns an error for any attachment.
+func WithAttachmentHandler(h func(a *decorator.Attachment) error) AcceptOptions {
+	return func(opts *acceptOpts) {
+		opts.attachmentHandler = h
+	}
+}
+
+// WithSelectedAttachment selects, by its @id, the attachment of a request offering several protocols whose protocol
+// is started once the connection is established. The first attachment is selected by default.
+func WithSelectedAttachment(id string) AcceptOptions {
+	return func(opts *acceptOpts) {
+		opts.selectedAttachment = id
+	}
+}
+
+// WithReuseConnection accepts the request by reusing connID, an existing connection with the sender of the request,
+// instead of creating a new connection. The sender is notified with a StateIDReuseAccepted message event once it
+// accepts the reuse (see ReuseAcceptedEvent), the protocol of the selected attachment is then started.
+func WithReuseConnection(connID string) AcceptOptions {
+	return func(opts *acceptOpts) {
+		opts.reuseConnection = connID
+	}
+}
+
+// WithInviterKey pins the base58 encoded public key of the inviter, the request must be signed with. The signature
+// of a request signed with a recipient key of its inlined service blocks is verified only against a pinned key.
+func WithInviterKey(verKey string) AcceptOptions {
+	return func(opts *acceptOpts) {
+		opts.inviterKey = verKey
+	}
+}
+
+// WithInviterDID pins the DID of the inviter, the request must be signed with a key of this DID.
+func WithInviterDID(didID string) AcceptOptions {
+	return func(opts *acceptOpts) {
+		opts.inviterDID = didID
+	}
+}
+
+// AcceptRequest from another agent and return the ID of a new connection record, or of the reused connection.
+func (c *Client) AcceptRequest(r *Request, opts ...AcceptOptions) (string, error) {
+	options := &acceptOpts{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var svcOpts []outofband.AcceptOption
+
+	if options.attachmentHandler != nil {
+		svcOpts = append(svcOpts, outofband.WithAttachmentHandler(options.attachmentHandler))
+	}
+
+	if options.selectedAttachment != "" {
+		svcOpts = append(svcOpts, outofband.WithSelectedAttachment(options.selectedAttachment))
+	}
+
+	if options.reuseConnection != "" {
+		svcOpts = append(svcOpts, outofband.WithReuseConnection(options.reuseConnection))
+	}
+
+	if options.inviterKey != "" {
+		svcOpts = append(svcOpts, outofband.WithInviterKey(options.inviterKey))
+	}
+
+	if options.inviterDID != "" {
+		svcOpts = append(svcOpts, outofband.WithInviterDID(options.inviterDID))
+	}
+
 	connID, err := c.oobService.AcceptRequest(&outofband.Request{
-		ID:       r.ID,
-		Type:     r.Type,
-		Label:    r.Label,
-		Goal:     r.Goal,
-		GoalCode: r.GoalCode,
-		Requests: r.Requests,
-		Service:  r.Service,
-	})
+		ID:        r.ID,
+		Type:      r.Type,
+		Label:     r.Label,
+		Goal:      r.Goal,
+		GoalCode:  r.GoalCode,
+		Requests:  r.Requests,
+		Service:   r.Service,
+		Accept:    r.Accept,
+		ImageURL:  r.ImageURL,
+		Signature: r.Signature,
+		Timing:    r.Timing,
+	}, svcOpts...)
 	if err != nil {
 		return "", fmt.Errorf("out-of-band service failed to accept request : %w", err)
 	}
@@ -119,6 +248,27 @@ func (c *Client) AcceptRequest(r *Request) (string, error) {
 	return connID, err
 }
 
+// RevokeRequest revokes the request id created with CreateRequest, eg: when the request was printed as a QR code
+// which must no longer be used. Accepting a revoked request fails with an error wrapping outofband.ErrRequestRevoked.
+func (c *Client) RevokeRequest(id string) error {
+	err := c.oobService.RevokeRequest(id)
+	if err != nil {
+		return fmt.Errorf("out-of-band service failed to revoke request : %w", err)
+	}
+
+	return nil
+}
+
+// Acceptances returns the acceptances of the request id created with CreateRequest, one per acceptor and connection.
+func (c *Client) Acceptances(id string) ([]*outofband.Acceptance, error) {
+	acceptances, err := c.oobService.Acceptances(id)
+	if err != nil {
+		return nil, fmt.Errorf("out-of-band service failed to fetch the acceptances : %w", err)
+	}
+
+	return acceptances, nil
+}
+
 // WithLabel allows you to specify the label on the message.
 func WithLabel(l string) RequestOptions {
 	return func(r *Request) error {
@@ -127,10 +277,43 @@ func WithLabel(l string) RequestOptions {
 	}
 }
 
-// WithAttachments allows you to specify attachments to include in the `request~attach` property.
+// WithAttachments allows you to specify attachments to include in the `request~attach` property. The attachments are
+// appended to the ones of the previous options (eg: WithCredentialManifest), whatever the order of the options.
 func WithAttachments(a ...*decorator.Attachment) RequestOptions {
 	return func(r *Request) error {
-		r.Requests = a
+		r.Requests = append(r.Requests, a...)
+		return nil
+	}
+}
+
+// WithCredentialManifest attaches a DIF Credential Manifest describing the credential the issuer will issue, so the
+// holder can pre-approve the issuance. The manifest is validated, it is surfaced to the receiver in the properties
+// of the request's action event (see Event).
+func WithCredentialManifest(m *outofband.CredentialManifest) RequestOptions {
+	return func(r *Request) error {
+		a, err := outofband.CredentialManifestAttachment(m)
+		if err != nil {
+			return err
+		}
+
+		r.Requests = append(r.Requests, a)
+
+		return nil
+	}
+}
+
+// WithAccept allows you to specify the media types accepted for the exchange started by the request, by order of
+// preference (eg: outofband.MediaTypeDIDCommV2). The receiver chooses a media type it supports among them.
+func WithAccept(mediaTypes ...string) RequestOptions {
+	return func(r *Request) error {
+		for _, mediaType := range mediaTypes {
+			if mediaType == "" {
+				return errors.New("accepted media types must not be empty")
+			}
+		}
+
+		r.Accept = mediaTypes
+
 		return nil
 	}
 }
@@ -145,6 +328,21 @@ func WithGoal(goal, goalCode string) RequestOptions {
 	}
 }
 
+// WithImageURL sets the URL of the image displayed by wallets for the request, eg: the icon of the issuer. It must be
+// an https URL or a data URL of an image.
+func WithImageURL(imageURL string) RequestOptions {
+	return func(r *Request) error {
+		err := outofband.ValidateImageURL(imageURL)
+		if err != nil {
+			return err
+		}
+
+		r.ImageURL = imageURL
+
+		return nil
+	}
+}
+
 // WithServices allows you to specify service entries to include in the request message.
 // Each entry must be either a valid DID (string) or a `service` object.
 func WithServices(svcs ...interface{}) RequestOptions {
@@ -174,6 +372,82 @@ func WithServices(svcs ...interface{}) RequestOptions {
 	}
 }
 
+// WithSignature allows you to sign the request with the key of its first service entry: the first recipient key of
+// a `service` object, or the first Ed25519 public key of a DID. The signature allows the invitee to detect requests
+// tampered with.
+func WithSignature() RequestOptions {
+	return func(r *Request) error {
+		r.sign = true
+
+		return nil
+	}
+}
+
+// WithMultiUse allows you to create a request accepted by many agents, eg: a request printed once for many holders.
+// The request is not consumed by its first acceptance, a distinct connection is established with each acceptor
+// (see Acceptances). Requests are single-use by default (see CreateRequest).
+func WithMultiUse() RequestOptions {
+	return func(r *Request) error {
+		r.multiUse = true
+
+		return nil
+	}
+}
+
+func (c *Client) signRequest(r *outofband.Request) error {
+	verKey, kid, err := c.signingKey(r.Service[0])
+	if err != nil {
+		return err
+	}
+
+	return outofband.SignRequest(r, &requestSigner{signer: c.signer, verKey: verKey, kid: kid})
+}
+
+// signingKey returns the base58 encoded public key of the service entry svc, along with its key ID.
+func (c *Client) signingKey(svc interface{}) (string, string, error) {
+	switch s := svc.(type) {
+	case *did.Service:
+		if len(s.RecipientKeys) == 0 {
+			return "", "", errors.New("no recipient keys in the service entry")
+		}
+
+		return s.RecipientKeys[0], s.RecipientKeys[0], nil
+	case string:
+		doc, err := c.vdriRegistry.Resolve(s)
+		if err != nil {
+			return "", "", fmt.Errorf("resolve DID : %w", err)
+		}
+
+		for _, pk := range doc.PublicKey {
+			if pk.Type == ed25519KeyType {
+				return base58.Encode(pk.Value), pk.ID, nil
+			}
+		}
+
+		return "", "", fmt.Errorf("no %s public key in DID %s", ed25519KeyType, s)
+	default:
+		return "", "", fmt.Errorf("unsupported service data type : %+v", svc)
+	}
+}
+
+// requestSigner signs requests with the legacy KMS key verKey.
+type requestSigner struct {
+	signer legacykms.Signer
+	verKey string
+	kid    string
+}
+
+func (s *requestSigner) Sign(data []byte) ([]byte, error) {
+	return s.signer.SignMessage(data, s.verKey)
+}
+
+func (s *requestSigner) Headers() jose.Headers {
+	return jose.Headers{
+		jose.HeaderAlgorithm: outofband.SignatureAlgorithm,
+		jose.HeaderKeyID:     s.kid,
+	}
+}
+
 // DidDocServiceFunc returns a function that returns a DID doc `service` entry.
 // Used when no service entries are specified when creating messages.
 func didServiceBlockFunc(p Provider) func() (*did.Service, error) {
@@ -7,23 +7,35 @@ SPDX-License-Identifier: Apache-2.0
 package outofband
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/issuecredential"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/presentproof"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol"
+	mockdidexchange "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/didexchange"
 	mockroute "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/route"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
 	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
 )
 
 func TestNew(t *testing.T) {
@@ -66,6 +78,87 @@ func TestCreateRequest(t *testing.T) {
 		require.Contains(t, req.Requests, first)
 		require.Contains(t, req.Requests, second)
 	})
+	t.Run("rejects attachments of the same protocol", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+		first := base64Attachment(t, &issuecredential.OfferCredential{Type: issuecredential.OfferCredentialMsgType})
+		second := base64Attachment(t, &issuecredential.OfferCredential{Type: issuecredential.OfferCredentialMsgType})
+		_, err = c.CreateRequest(WithAttachments(first, second))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid attachments")
+	})
+	t.Run("WithAttachments appends to the previous attachments", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+		first := dummyAttachment(t)
+		second := base64Attachment(t, &issuecredential.OfferCredential{Type: issuecredential.OfferCredentialMsgType})
+		req, err := c.CreateRequest(WithCredentialManifest(sampleManifest()), WithAttachments(first),
+			WithAttachments(second))
+		require.NoError(t, err)
+		require.Len(t, req.Requests, 3)
+		require.Equal(t, "application/json", req.Requests[0].MimeType)
+		require.Equal(t, []*decorator.Attachment{first, second}, req.Requests[1:])
+	})
+	t.Run("WithCredentialManifest", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+		offer := base64Attachment(t, &issuecredential.OfferCredential{Type: issuecredential.OfferCredentialMsgType})
+		req, err := c.CreateRequest(WithAttachments(offer), WithCredentialManifest(sampleManifest()))
+		require.NoError(t, err)
+		require.Len(t, req.Requests, 2)
+		require.Equal(t, offer, req.Requests[0])
+		require.Equal(t, "application/json", req.Requests[1].MimeType)
+
+		// the receiver is surfaced the manifest in the action event
+		oobService, err := outofband.New(&protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{},
+			},
+		})
+		require.NoError(t, err)
+
+		events := make(chan service.DIDCommAction)
+		require.NoError(t, oobService.RegisterActionEvent(events))
+
+		bytes, err := json.Marshal(req.Request)
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(bytes)
+		require.NoError(t, err)
+
+		_, err = oobService.HandleInbound(msg, "did:example:mine", "did:example:theirs")
+		require.NoError(t, err)
+
+		select {
+		case e := <-events:
+			props, ok := e.Properties.(Event)
+			require.True(t, ok)
+			require.Equal(t, sampleManifest(), props.CredentialManifest())
+		case <-time.After(time.Second):
+			t.Error("timeout waiting for action event")
+		}
+	})
+	t.Run("WithCredentialManifest rejects invalid manifests", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+		manifest := sampleManifest()
+		manifest.Issuer.ID = ""
+		_, err = c.CreateRequest(WithAttachments(dummyAttachment(t)), WithCredentialManifest(manifest))
+		require.True(t, errors.Is(err, outofband.ErrInvalidCredentialManifest))
+	})
+	t.Run("WithAccept", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)),
+			WithAccept(outofband.MediaTypeDIDCommV2, outofband.MediaTypeAIP2RFC19))
+		require.NoError(t, err)
+		require.Equal(t, []string{outofband.MediaTypeDIDCommV2, outofband.MediaTypeAIP2RFC19}, req.Accept)
+
+		_, err = c.CreateRequest(WithAttachments(dummyAttachment(t)), WithAccept(""))
+		require.Error(t, err)
+	})
 	t.Run("includes the diddoc Service block returned by provider", func(t *testing.T) {
 		expected := &did.Service{
 			ID:              uuid.New().String(),
@@ -108,6 +201,58 @@ func TestCreateRequest(t *testing.T) {
 		require.Equal(t, expectedGoal, req.Goal)
 		require.Equal(t, expectedGoalCode, req.GoalCode)
 	})
+	t.Run("WithImageURL", func(t *testing.T) {
+		const imageURL = "https://issuer.example.com/icon.png"
+
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+		req, err := c.CreateRequest(
+			WithAttachments(dummyAttachment(t)),
+			WithImageURL(imageURL))
+		require.NoError(t, err)
+		require.Equal(t, imageURL, req.ImageURL)
+
+		bytes, err := json.Marshal(req.Request)
+		require.NoError(t, err)
+		require.Contains(t, string(bytes), `"imageUrl":"`+imageURL+`"`)
+
+		// the receiver reads the image url from the action event
+		oobService, err := outofband.New(&protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{},
+			},
+		})
+		require.NoError(t, err)
+
+		events := make(chan service.DIDCommAction)
+		require.NoError(t, oobService.RegisterActionEvent(events))
+
+		msg, err := service.ParseDIDCommMsgMap(bytes)
+		require.NoError(t, err)
+
+		_, err = oobService.HandleInbound(msg, "did:example:mine", "did:example:theirs")
+		require.NoError(t, err)
+
+		select {
+		case e := <-events:
+			props, ok := e.Properties.(Event)
+			require.True(t, ok)
+			require.Equal(t, imageURL, props.ImageURL())
+		case <-time.After(time.Second):
+			t.Error("timeout waiting for action event")
+		}
+	})
+	t.Run("WithImageURL rejects unsupported schemes", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+
+		_, err = c.CreateRequest(
+			WithAttachments(dummyAttachment(t)),
+			WithImageURL("http://issuer.example.com/icon.png"))
+		require.True(t, errors.Is(err, outofband.ErrInvalidImageURL))
+	})
 	t.Run("WithServices diddoc service blocks", func(t *testing.T) {
 		c, err := New(withTestProvider())
 		require.NoError(t, err)
@@ -234,7 +379,7 @@ func TestAcceptRequest(t *testing.T) {
 		}
 		c, err := New(provider)
 		require.NoError(t, err)
-		result, err := c.AcceptRequest(&Request{&outofband.Request{}})
+		result, err := c.AcceptRequest(&Request{Request: &outofband.Request{}})
 		require.NoError(t, err)
 		require.Equal(t, expected, result)
 	})
@@ -250,10 +395,127 @@ func TestAcceptRequest(t *testing.T) {
 		}
 		c, err := New(provider)
 		require.NoError(t, err)
-		_, err = c.AcceptRequest(&Request{&outofband.Request{}})
+		_, err = c.AcceptRequest(&Request{Request: &outofband.Request{}})
 		require.Error(t, err)
 		require.True(t, errors.Is(err, expected))
 	})
+	t.Run("attachment handler vetoes request", func(t *testing.T) {
+		oobService, err := outofband.New(&protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{},
+			},
+		})
+		require.NoError(t, err)
+
+		provider := withTestProvider()
+		provider.ServiceMap[outofband.Name] = oobService
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		attachment := dummyAttachment(t)
+		attachment.MimeType = "text/plain"
+
+		req, err := c.CreateRequest(WithAttachments(attachment))
+		require.NoError(t, err)
+
+		rejectText := func(a *decorator.Attachment) error {
+			if a.MimeType == "text/plain" {
+				return errors.New("text attachments are not accepted")
+			}
+
+			return nil
+		}
+
+		_, err = c.AcceptRequest(req, WithAttachmentHandler(rejectText))
+		require.True(t, errors.Is(err, outofband.ErrAttachmentRejected))
+
+		req.Requests[0].MimeType = "application/json"
+
+		_, err = c.AcceptRequest(req, WithAttachmentHandler(rejectText))
+		require.NoError(t, err)
+	})
+	t.Run("negotiates the media type of the exchange", func(t *testing.T) {
+		var mediaType string
+
+		oobService, err := outofband.New(&protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{
+					RespondToFunc: func(i *didexchange.OOBInvitation) (string, error) {
+						mediaType = i.MediaType
+						return "conn-1", nil
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		provider := withTestProvider()
+		provider.ServiceMap[outofband.Name] = oobService
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)),
+			WithAccept(outofband.MediaTypeDIDCommV2, outofband.MediaTypeAIP1))
+		require.NoError(t, err)
+
+		_, err = c.AcceptRequest(req)
+		require.NoError(t, err)
+		require.Equal(t, outofband.MediaTypeAIP1, mediaType)
+
+		req, err = c.CreateRequest(WithAttachments(dummyAttachment(t)), WithAccept(outofband.MediaTypeDIDCommV2))
+		require.NoError(t, err)
+
+		_, err = c.AcceptRequest(req)
+		require.True(t, errors.Is(err, outofband.ErrNoCommonMediaType))
+	})
+	t.Run("accepts a request offering a credential and a proof request", func(t *testing.T) {
+		oobService, err := outofband.New(&protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{},
+			},
+		})
+		require.NoError(t, err)
+
+		provider := withTestProvider()
+		provider.ServiceMap[outofband.Name] = oobService
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		offer := base64Attachment(t, &issuecredential.OfferCredential{Type: issuecredential.OfferCredentialMsgType})
+		proofReq := base64Attachment(t, &presentproof.RequestPresentation{
+			Type: presentproof.RequestPresentationMsgType,
+		})
+
+		req, err := c.CreateRequest(WithAttachments(offer, proofReq))
+		require.NoError(t, err)
+		require.Len(t, req.Requests, 2)
+
+		_, err = c.AcceptRequest(req, WithSelectedAttachment(proofReq.ID))
+		require.NoError(t, err)
+
+		_, err = c.AcceptRequest(req, WithSelectedAttachment(uuid.New().String()))
+		require.True(t, errors.Is(err, outofband.ErrAttachmentNotFound))
+	})
+}
+
+func sampleManifest() *outofband.CredentialManifest {
+	return &outofband.CredentialManifest{
+		Type: outofband.CredentialManifestType,
+		Issuer: &outofband.ManifestIssuer{
+			ID:   "did:example:123",
+			Name: "Washington State Government",
+		},
+		Credential: &outofband.ManifestCredential{
+			Schema: "https://schema.org/EducationalOccupationalCredential",
+			Name:   "Washington State Driver License",
+		},
+	}
 }
 
 func dummyAttachment(t *testing.T) *decorator.Attachment {
@@ -281,8 +543,8 @@ func base64Attachment(t *testing.T, data interface{}) *decorator.Attachment {
 }
 
 type didcommMsg struct {
-	ID   string
-	Type string
+	ID   string `json:"@id"`
+	Type string `json:"@type"`
 }
 
 func withTestProvider() *mockprovider.Provider {
@@ -299,11 +561,15 @@ func withTestProvider() *mockprovider.Provider {
 }
 
 type stubOOBService struct {
-	acceptReqFunc func(request *outofband.Request) (string, error)
-	saveReqFunc   func(*outofband.Request) error
+	service.Event
+	acceptReqFunc      func(request *outofband.Request) (string, error)
+	saveReqFunc        func(*outofband.Request, ...outofband.SaveOption) error
+	savePendingReqFunc func(*outofband.Request) error
+	revokeReqFunc      func(string) error
+	acceptancesFunc    func(string) ([]*outofband.Acceptance, error)
 }
 
-func (s *stubOOBService) AcceptRequest(request *outofband.Request) (string, error) {
+func (s *stubOOBService) AcceptRequest(request *outofband.Request, _ ...outofband.AcceptOption) (string, error) {
 	if s.acceptReqFunc != nil {
 		return s.acceptReqFunc(request)
 	}
@@ -311,10 +577,407 @@ func (s *stubOOBService) AcceptRequest(request *outofband.Request) (string, erro
 	return "", nil
 }
 
-func (s *stubOOBService) SaveRequest(request *outofband.Request) error {
+func (s *stubOOBService) RevokeRequest(id string) error {
+	if s.revokeReqFunc != nil {
+		return s.revokeReqFunc(id)
+	}
+
+	return nil
+}
+
+func (s *stubOOBService) SaveRequest(request *outofband.Request, opts ...outofband.SaveOption) error {
 	if s.saveReqFunc != nil {
-		return s.saveReqFunc(request)
+		return s.saveReqFunc(request, opts...)
 	}
 
 	return nil
 }
+
+func (s *stubOOBService) Acceptances(id string) ([]*outofband.Acceptance, error) {
+	if s.acceptancesFunc != nil {
+		return s.acceptancesFunc(id)
+	}
+
+	return nil, nil
+}
+
+func (s *stubOOBService) SavePendingRequest(request *outofband.Request) error {
+	if s.savePendingReqFunc != nil {
+		return s.savePendingReqFunc(request)
+	}
+
+	return nil
+}
+
+func TestSignedRequest(t *testing.T) {
+	const didID = "did:example:inviter"
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verKey := base58.Encode(pubKey)
+
+	inviterDoc := &did.Doc{
+		ID: didID,
+		PublicKey: []did.PublicKey{
+			{ID: didID + "#key-1", Type: "JwsVerificationKey2020", Controller: didID, Value: []byte("jwk")},
+			{ID: didID + "#key-2", Type: ed25519KeyType, Controller: didID, Value: pubKey},
+		},
+	}
+
+	newProvider := func() *mockprovider.Provider {
+		provider := withTestProvider()
+		provider.KMSValue = &mockkms.CloseableKMS{CreateSigningKeyValue: verKey}
+		provider.SignerValue = &ed25519Signer{verKey: verKey, privKey: privKey}
+		provider.VDRIRegistryValue = &mockvdri.MockVDRIRegistry{ResolveValue: inviterDoc}
+
+		return provider
+	}
+
+	oobService, err := outofband.New(&protocol.MockProvider{
+		StoreProvider:          mockstore.NewMockStoreProvider(),
+		TransientStoreProvider: mockstore.NewMockStoreProvider(),
+		ServiceMap: map[string]interface{}{
+			didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{},
+		},
+		CustomVDRI: &mockvdri.MockVDRIRegistry{ResolveValue: inviterDoc},
+	}, outofband.WithUnsignedRequests(false))
+	require.NoError(t, err)
+
+	t.Run("signs request with the key of the service block", func(t *testing.T) {
+		c, err := New(newProvider())
+		require.NoError(t, err)
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)), WithSignature())
+		require.NoError(t, err)
+		require.NotEmpty(t, req.Signature)
+
+		// the inlined key must be pinned by the invitee
+		_, err = oobService.AcceptRequest(req.Request)
+		require.True(t, errors.Is(err, outofband.ErrInvalidSignature))
+
+		_, err = oobService.AcceptRequest(req.Request, outofband.WithInviterKey(verKey))
+		require.NoError(t, err)
+	})
+
+	t.Run("signs request with the key of the DID", func(t *testing.T) {
+		c, err := New(newProvider())
+		require.NoError(t, err)
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)), WithServices(didID), WithSignature())
+		require.NoError(t, err)
+
+		jws, err := jose.ParseJWS(req.Signature, jose.SignatureVerifierFunc(
+			func(_ jose.Headers, _, _, _ []byte) error {
+				return nil
+			}), jose.WithJWSDetachedPayload([]byte{}))
+		require.NoError(t, err)
+
+		kid, ok := jws.ProtectedHeaders.KeyID()
+		require.True(t, ok)
+		require.Equal(t, didID+"#key-2", kid)
+
+		_, err = oobService.AcceptRequest(req.Request)
+		require.NoError(t, err)
+	})
+
+	t.Run("accepts request signed with the pinned key of the inviter", func(t *testing.T) {
+		provider := newProvider()
+		provider.ServiceMap[outofband.Name] = oobService
+
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)), WithSignature())
+		require.NoError(t, err)
+
+		_, err = c.AcceptRequest(req)
+		require.True(t, errors.Is(err, outofband.ErrInvalidSignature))
+
+		_, err = c.AcceptRequest(req, WithInviterKey(verKey))
+		require.NoError(t, err)
+
+		req, err = c.CreateRequest(WithAttachments(dummyAttachment(t)), WithServices(didID), WithSignature())
+		require.NoError(t, err)
+
+		_, err = c.AcceptRequest(req, WithInviterDID(didID))
+		require.NoError(t, err)
+	})
+
+	t.Run("accepts signed request carrying a ~timing decorator", func(t *testing.T) {
+		provider := newProvider()
+		provider.ServiceMap[outofband.Name] = oobService
+
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)), WithServices(didID))
+		require.NoError(t, err)
+
+		// the ~timing decorator is covered by the signature
+		req.Timing = &decorator.Timing{ExpiresTime: time.Now().Add(time.Hour).UTC().Truncate(time.Second)}
+		require.NoError(t, c.signRequest(req.Request))
+
+		_, err = c.AcceptRequest(req, WithInviterDID(didID))
+		require.NoError(t, err)
+	})
+
+	t.Run("request with attachment modified after signing is rejected", func(t *testing.T) {
+		c, err := New(newProvider())
+		require.NoError(t, err)
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)), WithSignature())
+		require.NoError(t, err)
+
+		req.Requests[0].Data.Base64 = base64.StdEncoding.EncodeToString([]byte("tampered"))
+
+		_, err = oobService.AcceptRequest(req.Request)
+		require.True(t, errors.Is(err, outofband.ErrInvalidSignature))
+	})
+
+	t.Run("signed request is verified after a round trip through a request url", func(t *testing.T) {
+		c, err := New(newProvider())
+		require.NoError(t, err)
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)), WithSignature(), WithGoal("goal", "code"),
+			WithAccept(outofband.MediaTypeAIP1), WithImageURL("https://example.com/image.png"))
+		require.NoError(t, err)
+
+		reqURL, err := c.CreateRequestURL(baseURL, req)
+		require.NoError(t, err)
+
+		imported, err := c.ImportRequest([]byte(reqURL.URL))
+		require.NoError(t, err)
+		require.Equal(t, req.Signature, imported.Signature)
+		require.Equal(t, req.Goal, imported.Goal)
+		require.Equal(t, req.Accept, imported.Accept)
+		require.Equal(t, req.ImageURL, imported.ImageURL)
+
+		_, err = oobService.AcceptRequest(imported.Request, outofband.WithInviterKey(verKey))
+		require.NoError(t, err)
+
+		_, err = c.CreateRequestURL(baseURL, req, WithDIDKeyService())
+		require.EqualError(t, err, "the services of a signed request can't be replaced with did:key services")
+	})
+
+	t.Run("unsigned request is rejected", func(t *testing.T) {
+		c, err := New(newProvider())
+		require.NoError(t, err)
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)))
+		require.NoError(t, err)
+		require.Empty(t, req.Signature)
+
+		_, err = oobService.AcceptRequest(req.Request)
+		require.True(t, errors.Is(err, outofband.ErrInvalidSignature))
+	})
+
+	t.Run("fails to sign with a DID without Ed25519 key", func(t *testing.T) {
+		provider := newProvider()
+		provider.VDRIRegistryValue = &mockvdri.MockVDRIRegistry{ResolveValue: &did.Doc{ID: didID}}
+
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		_, err = c.CreateRequest(WithAttachments(dummyAttachment(t)), WithServices(didID), WithSignature())
+		require.EqualError(t, err, "failed to sign request : no Ed25519VerificationKey2018 public key in DID "+didID)
+	})
+
+	t.Run("wraps error from signer", func(t *testing.T) {
+		expected := errors.New("test")
+		provider := newProvider()
+		provider.SignerValue = &mockkms.CloseableKMS{SignMessageErr: expected}
+
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		_, err = c.CreateRequest(WithAttachments(dummyAttachment(t)), WithSignature())
+		require.True(t, errors.Is(err, expected))
+	})
+}
+
+type ed25519Signer struct {
+	verKey  string
+	privKey ed25519.PrivateKey
+}
+
+func (s *ed25519Signer) SignMessage(message []byte, fromVerKey string) ([]byte, error) {
+	if fromVerKey != s.verKey {
+		return nil, errors.New("unknown key")
+	}
+
+	return ed25519.Sign(s.privKey, message), nil
+}
+
+func TestReuseConnection(t *testing.T) {
+	t.Run("sender of the request receives the reuse-accepted event", func(t *testing.T) {
+		const connID = "inviter-conn"
+
+		oobProvider := &protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{},
+			},
+		}
+
+		r, err := connection.NewRecorder(oobProvider)
+		require.NoError(t, err)
+		require.NoError(t, r.SaveConnectionRecord(&connection.Record{
+			ConnectionID: connID,
+			State:        "completed",
+			MyDID:        "did:example:inviter",
+			TheirDID:     "did:example:invitee",
+		}))
+
+		oobService, err := outofband.New(oobProvider)
+		require.NoError(t, err)
+
+		provider := withTestProvider()
+		provider.ServiceMap[outofband.Name] = oobService
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		events := make(chan service.StateMsg, 1)
+		require.NoError(t, c.RegisterMsgEvent(events))
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)))
+		require.NoError(t, err)
+
+		// the receiver of the request reuses the connection
+		reuse := service.NewDIDCommMsgMap(&outofband.HandshakeReuse{
+			ID:     uuid.New().String(),
+			Type:   outofband.HandshakeReuseMsgType,
+			Thread: &decorator.Thread{PID: req.ID},
+		})
+
+		_, err = oobService.HandleInbound(reuse, "did:example:inviter", "did:example:invitee")
+		require.NoError(t, err)
+
+		select {
+		case e := <-events:
+			require.Equal(t, StateIDReuseAccepted, e.StateID)
+
+			props, ok := e.Properties.(ReuseAcceptedEvent)
+			require.True(t, ok)
+			require.Equal(t, connID, props.ConnectionID())
+		case <-time.After(time.Second):
+			require.FailNow(t, "timeout waiting for the reuse-accepted event")
+		}
+	})
+	t.Run("receiver of the request reuses a connection", func(t *testing.T) {
+		const connID = "invitee-conn"
+
+		oobProvider := &protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{
+					RespondToFunc: func(*didexchange.OOBInvitation) (string, error) {
+						return "", errors.New("a new connection should not be created")
+					},
+				},
+			},
+		}
+
+		r, err := connection.NewRecorder(oobProvider)
+		require.NoError(t, err)
+		require.NoError(t, r.SaveConnectionRecord(&connection.Record{
+			ConnectionID: connID,
+			MyDID:        "did:example:invitee",
+			TheirDID:     "did:example:inviter",
+		}))
+
+		oobService, err := outofband.New(oobProvider)
+		require.NoError(t, err)
+
+		provider := withTestProvider()
+		provider.ServiceMap[outofband.Name] = oobService
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)))
+		require.NoError(t, err)
+
+		result, err := c.AcceptRequest(req, WithReuseConnection(connID))
+		require.NoError(t, err)
+		require.Equal(t, connID, result)
+	})
+}
+
+func TestRevokeRequest(t *testing.T) {
+	t.Run("accepting a revoked request fails", func(t *testing.T) {
+		oobService, err := outofband.New(&protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{},
+			},
+		})
+		require.NoError(t, err)
+
+		provider := withTestProvider()
+		provider.ServiceMap[outofband.Name] = oobService
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		req, err := c.CreateRequest(WithAttachments(dummyAttachment(t)))
+		require.NoError(t, err)
+
+		require.NoError(t, c.RevokeRequest(req.ID))
+
+		_, err = c.AcceptRequest(req)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, outofband.ErrRequestRevoked))
+	})
+	t.Run("wraps error from outofband service", func(t *testing.T) {
+		expected := errors.New("test")
+		provider := withTestProvider()
+		provider.ServiceMap = map[string]interface{}{
+			outofband.Name: &stubOOBService{
+				revokeReqFunc: func(string) error {
+					return expected
+				},
+			},
+		}
+		c, err := New(provider)
+		require.NoError(t, err)
+		err = c.RevokeRequest("id")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, expected))
+	})
+}
+
+func TestMultiUseRequest(t *testing.T) {
+	t.Run("multi-use requests are saved as multi-use", func(t *testing.T) {
+		provider := withTestProvider()
+		provider.ServiceMap[outofband.Name] = &stubOOBService{
+			saveReqFunc: func(_ *outofband.Request, opts ...outofband.SaveOption) error {
+				require.Len(t, opts, 1)
+
+				return nil
+			},
+		}
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		_, err = c.CreateRequest(WithAttachments(dummyAttachment(t)), WithMultiUse())
+		require.NoError(t, err)
+	})
+	t.Run("wraps error from outofband service", func(t *testing.T) {
+		expected := errors.New("test")
+		provider := withTestProvider()
+		provider.ServiceMap = map[string]interface{}{
+			outofband.Name: &stubOOBService{
+				acceptancesFunc: func(string) ([]*outofband.Acceptance, error) {
+					return nil, expected
+				},
+			},
+		}
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		_, err = c.Acceptances("id")
+		require.True(t, errors.Is(err, expected))
+	})
+}
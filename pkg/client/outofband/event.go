@@ -0,0 +1,25 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import "github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband"
+
+// Event properties related api. This can be used to cast the properties of the action events of out-of-band
+// requests.
+type Event interface {
+	// CredentialManifest attached to the request, nil if there is none
+	CredentialManifest() *outofband.CredentialManifest
+	// ImageURL of the image to display for the request, eg: the icon of the issuer, empty if there is none
+	ImageURL() string
+}
+
+// ReuseAcceptedEvent properties related api. This can be used to cast the properties of the StateIDReuseAccepted
+// message events, fired on both sides once the reuse of a connection for a request is accepted.
+type ReuseAcceptedEvent interface {
+	// ConnectionID of the reused connection
+	ConnectionID() string
+}
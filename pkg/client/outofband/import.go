@@ -0,0 +1,106 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+)
+
+// ImportRequest parses and validates a request serialized by another agent and saves it as pending until it is
+// accepted with AcceptRequest. data is either the JSON request, a request URL (see CreateRequestURL), eg: scanned
+// from a QR code, or the base64url encoded request of such a URL.
+func (c *Client) ImportRequest(data []byte) (*Request, error) {
+	payload, err := requestPayload(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import request : %w", err)
+	}
+
+	req := &outofband.Request{}
+
+	err = json.Unmarshal(payload, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import request : unmarshal request : %w", err)
+	}
+
+	err = toServiceBlocks(req.Service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import request : %w", err)
+	}
+
+	err = outofband.ValidateRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import request : %w", err)
+	}
+
+	err = c.oobService.SavePendingRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("outofband service failed to save pending request : %w", err)
+	}
+
+	return &Request{Request: req}, nil
+}
+
+// requestPayload returns the JSON request of data, the JSON request itself or a request URL or its encoded request.
+func requestPayload(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) == 0 {
+		return nil, errors.New("empty request")
+	}
+
+	if data[0] == '{' {
+		return data, nil
+	}
+
+	encoded := string(data)
+
+	if u, err := url.Parse(encoded); err == nil && u.Query().Get(URLQueryParam) != "" {
+		encoded = u.Query().Get(URLQueryParam)
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.StdEncoding} {
+		if payload, err := encoding.DecodeString(encoded); err == nil {
+			return payload, nil
+		}
+	}
+
+	return nil, errors.New("request is neither JSON nor a request url nor a base64 encoded request")
+}
+
+// toServiceBlocks replaces the JSON service blocks of svcs with did.Service entries, as in the requests created by
+// CreateRequest. DIDs are left as is.
+func toServiceBlocks(svcs []interface{}) error {
+	for i := range svcs {
+		if _, ok := svcs[i].(map[string]interface{}); !ok {
+			continue
+		}
+
+		raw, err := json.Marshal(svcs[i])
+		if err != nil {
+			return fmt.Errorf("marshal service block : %w", err)
+		}
+
+		svc := &did.Service{}
+
+		err = json.Unmarshal(raw, svc)
+		if err != nil {
+			return fmt.Errorf("unmarshal service block : %w", err)
+		}
+
+		svcs[i] = svc
+	}
+
+	return nil
+}
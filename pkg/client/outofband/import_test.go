@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol"
+	mockdidexchange "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/didexchange"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestImportRequest(t *testing.T) {
+	newRequest := func(t *testing.T) *Request {
+		return newURLTestRequest(t, &did.Service{
+			ID:              uuid.New().String(),
+			Type:            didCommServiceType,
+			RecipientKeys:   []string{newVerKey(t)},
+			ServiceEndpoint: "https://alice.example.com/didcomm",
+		})
+	}
+
+	t.Run("imports a serialized request", func(t *testing.T) {
+		var pending *outofband.Request
+
+		provider := withTestProvider()
+		provider.ServiceMap[outofband.Name] = &stubOOBService{
+			savePendingReqFunc: func(r *outofband.Request) error {
+				pending = r
+				return nil
+			},
+		}
+
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		expected := newRequest(t)
+
+		data, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		result, err := c.ImportRequest(data)
+		require.NoError(t, err)
+		require.Equal(t, expected.ID, result.ID)
+		require.Equal(t, expected.Goal, result.Goal)
+		require.Equal(t, expected.Service, result.Service)
+		require.Equal(t, result.Request, pending)
+	})
+
+	t.Run("imports a request url", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+
+		expected := newRequest(t)
+
+		reqURL, err := c.CreateRequestURL(baseURL, expected)
+		require.NoError(t, err)
+
+		result, err := c.ImportRequest([]byte(reqURL.URL))
+		require.NoError(t, err)
+		require.Equal(t, expected.ID, result.ID)
+		require.Len(t, result.Service, 1)
+
+		svc, ok := result.Service[0].(*did.Service)
+		require.True(t, ok)
+		require.Equal(t, "https://alice.example.com/didcomm", svc.ServiceEndpoint)
+		require.Equal(t, expected.Service[0].(*did.Service).RecipientKeys, svc.RecipientKeys)
+
+		payload, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		result, err = c.ImportRequest([]byte(base64.URLEncoding.EncodeToString(payload)))
+		require.NoError(t, err)
+		require.Equal(t, expected.ID, result.ID)
+	})
+
+	t.Run("imported request is accepted", func(t *testing.T) {
+		oobService, err := outofband.New(&protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{},
+			},
+		})
+		require.NoError(t, err)
+
+		provider := withTestProvider()
+		provider.ServiceMap[outofband.Name] = oobService
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		reqURL, err := c.CreateRequestURL(baseURL, newRequest(t))
+		require.NoError(t, err)
+
+		result, err := c.ImportRequest([]byte(reqURL.URL))
+		require.NoError(t, err)
+
+		pending, err := oobService.PendingRequest(result.ID)
+		require.NoError(t, err)
+		require.Equal(t, result.ID, pending.ID)
+
+		_, err = c.AcceptRequest(result)
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects malformed requests", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+
+		noService := newRequest(t)
+		noService.Service = nil
+
+		noServiceBytes, err := json.Marshal(noService)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name string
+			data string
+			err  string
+		}{
+			{"empty", " ", "empty request"},
+			{"not encoded", "not a request!", "neither JSON nor a request url"},
+			{"invalid JSON", "{", "unmarshal request"},
+			{"no service", string(noServiceBytes), "request has no service"},
+			{"invalid service block", `{"@id":"1","@type":"` + RequestMsgType +
+				`","request~attach":[{}],"service":[{"serviceEndpoint":"https://example.com"}]}`,
+				"service block has no recipientKeys"},
+		}
+
+		for _, test := range tests {
+			_, err := c.ImportRequest([]byte(test.data))
+			require.Error(t, err, test.name)
+			require.Contains(t, err.Error(), test.err, test.name)
+		}
+	})
+
+	t.Run("wraps error from outofband service", func(t *testing.T) {
+		expected := errors.New("test")
+		provider := withTestProvider()
+		provider.ServiceMap[outofband.Name] = &stubOOBService{
+			savePendingReqFunc: func(*outofband.Request) error {
+				return expected
+			},
+		}
+
+		c, err := New(provider)
+		require.NoError(t, err)
+
+		data, err := json.Marshal(newRequest(t))
+		require.NoError(t, err)
+
+		_, err = c.ImportRequest(data)
+		require.True(t, errors.Is(err, expected))
+	})
+}
@@ -11,4 +11,6 @@ import "github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband
 // Request is the out-of-band protocol's 'request' message.
 type Request struct {
 	*outofband.Request
+	sign     bool
+	multiUse bool
 }
@@ -0,0 +1,200 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+)
+
+const (
+	// QRCodeSizeThreshold is the size (in bytes) of a request URL above which the QR code becomes too dense to be
+	// reliably scanned by mobile devices.
+	QRCodeSizeThreshold = 512
+
+	// URLQueryParam is the query parameter holding the encoded out-of-band message.
+	URLQueryParam = "oob"
+
+	didCommServiceType = "did-communication"
+	inlineServiceID    = "#inline"
+	peerDIDPrefix      = "did:peer:"
+)
+
+// RequestURL is an out-of-band request encoded in a URL, e.g. to be rendered as a QR code.
+type RequestURL struct {
+	// URL is the base URL with the request encoded in the `oob` query parameter.
+	URL string
+	// Size is the length of the URL in bytes. It allows to choose the QR code error correction level.
+	Size int
+}
+
+// URLOptions allow you to customize the way request URLs are built.
+type URLOptions func(*urlOpts)
+
+type urlOpts struct {
+	didKeyService bool
+}
+
+// WithDIDKeyService replaces did:peer service entries with an inline service block with did:key recipient and
+// routing keys, so the invitee doesn't need the (verbose) peer DID document to reach the inviter.
+func WithDIDKeyService() URLOptions {
+	return func(opts *urlOpts) {
+		opts.didKeyService = true
+	}
+}
+
+// inlineService is the compact form of a DID doc `service` entry.
+type inlineService struct {
+	ID              string   `json:"id"`
+	Type            string   `json:"type"`
+	RecipientKeys   []string `json:"recipientKeys"`
+	RoutingKeys     []string `json:"routingKeys,omitempty"`
+	ServiceEndpoint string   `json:"serviceEndpoint"`
+}
+
+// CreateRequestURL encodes the request in the shortest valid URL (e.g. for QR codes): the inline service blocks of
+// unsigned requests are compacted. Signed requests are encoded as is, since their signature covers the service
+// blocks, and can't be encoded with WithDIDKeyService. The URL is returned along with its size so callers can choose
+// a QR code error correction level. A warning is logged if the size exceeds QRCodeSizeThreshold.
+func (c *Client) CreateRequestURL(baseURL string, r *Request, opts ...URLOptions) (*RequestURL, error) {
+	if r == nil || r.Request == nil {
+		return nil, errors.New("request is required to create a request url")
+	}
+
+	options := &urlOpts{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base url : %w", err)
+	}
+
+	compact := *r.Request
+
+	switch {
+	case r.Signature == "":
+		compact.Service, err = c.compactServices(r.Service, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compact services : %w", err)
+		}
+	case options.didKeyService:
+		return nil, errors.New("the services of a signed request can't be replaced with did:key services")
+	}
+
+	payload, err := json.Marshal(&compact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request : %w", err)
+	}
+
+	query := base.Query()
+	query.Set(URLQueryParam, base64.RawURLEncoding.EncodeToString(payload))
+	base.RawQuery = query.Encode()
+
+	reqURL := &RequestURL{URL: base.String()}
+	reqURL.Size = len(reqURL.URL)
+
+	if reqURL.Size > QRCodeSizeThreshold {
+		logger.Warnf("request url size (%d bytes) exceeds the QR code friendly size (%d bytes)",
+			reqURL.Size, QRCodeSizeThreshold)
+	}
+
+	return reqURL, nil
+}
+
+func (c *Client) compactServices(svcs []interface{}, opts *urlOpts) ([]interface{}, error) {
+	compact := make([]interface{}, len(svcs))
+
+	for i := range svcs {
+		switch svc := svcs[i].(type) {
+		case string:
+			if !opts.didKeyService || !strings.HasPrefix(svc, peerDIDPrefix) {
+				compact[i] = svc
+
+				continue
+			}
+
+			inline, err := c.peerDIDService(svc)
+			if err != nil {
+				return nil, err
+			}
+
+			compact[i] = inline
+		case *did.Service:
+			compact[i] = &inlineService{
+				ID:              inlineServiceID,
+				Type:            svc.Type,
+				RecipientKeys:   svc.RecipientKeys,
+				RoutingKeys:     svc.RoutingKeys,
+				ServiceEndpoint: svc.ServiceEndpoint,
+			}
+		default:
+			compact[i] = svc
+		}
+	}
+
+	return compact, nil
+}
+
+// peerDIDService resolves the peer DID and returns its did-communication service as an inline service block.
+func (c *Client) peerDIDService(peerDID string) (*inlineService, error) {
+	doc, err := c.vdriRegistry.Resolve(peerDID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s : %w", peerDID, err)
+	}
+
+	svc, found := did.LookupService(doc, didCommServiceType)
+	if !found {
+		return nil, fmt.Errorf("no %s service found on %s", didCommServiceType, peerDID)
+	}
+
+	recipientKeys, err := toDIDKeys(svc.RecipientKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	routingKeys, err := toDIDKeys(svc.RoutingKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inlineService{
+		ID:              inlineServiceID,
+		Type:            svc.Type,
+		RecipientKeys:   recipientKeys,
+		RoutingKeys:     routingKeys,
+		ServiceEndpoint: svc.ServiceEndpoint,
+	}, nil
+}
+
+func toDIDKeys(keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	didKeys := make([]string, len(keys))
+
+	for i, key := range keys {
+		didKey, err := route.RoutingKeyToDIDKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("convert key to did:key : %w", err)
+		}
+
+		didKeys[i] = didKey
+	}
+
+	return didKeys, nil
+}
@@ -0,0 +1,231 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+)
+
+const (
+	baseURL = "https://example.com/connect"
+	peerDID = "did:peer:1zQmZMygzYqNwU6Uhmewx5Xepf2VLp5S4HLSwwgf2aiKZuwa"
+)
+
+func TestCreateRequestURL(t *testing.T) {
+	t.Run("optimized url is shorter than the full payload", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+
+		req := newURLTestRequest(t, &did.Service{
+			ID:              uuid.New().String(),
+			Type:            didCommServiceType,
+			RecipientKeys:   []string{newVerKey(t)},
+			ServiceEndpoint: "https://alice.example.com/didcomm",
+		})
+
+		full, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		fullURL := baseURL + "?" + URLQueryParam + "=" + base64.URLEncoding.EncodeToString(full)
+
+		result, err := c.CreateRequestURL(baseURL, req)
+		require.NoError(t, err)
+		require.Equal(t, len(result.URL), result.Size)
+		require.Less(t, result.Size, len(fullURL))
+		require.True(t, strings.HasPrefix(result.URL, baseURL+"?"+URLQueryParam+"="))
+
+		decoded := decodeRequestURL(t, result.URL)
+		require.Equal(t, req.ID, decoded.ID)
+		require.Equal(t, req.Type, decoded.Type)
+		require.Equal(t, req.Label, decoded.Label)
+		require.Equal(t, req.Goal, decoded.Goal)
+		require.Equal(t, req.GoalCode, decoded.GoalCode)
+		require.Len(t, decoded.Requests, 1)
+		require.Len(t, decoded.Service, 1)
+
+		svc, ok := decoded.Service[0].(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, inlineServiceID, svc["id"])
+		require.Equal(t, "https://alice.example.com/didcomm", svc["serviceEndpoint"])
+		require.NotContains(t, svc, "routingKeys")
+	})
+
+	t.Run("did:key inline service instead of did:peer", func(t *testing.T) {
+		doc := newPeerDIDDoc(t)
+
+		prov := withTestProvider()
+		prov.VDRIRegistryValue = &mockvdri.MockVDRIRegistry{ResolveValue: doc}
+
+		c, err := New(prov)
+		require.NoError(t, err)
+
+		// the full payload inlines the service block of the peer DID doc
+		full, err := json.Marshal(newURLTestRequest(t, &doc.Service[0]))
+		require.NoError(t, err)
+
+		fullURL := baseURL + "?" + URLQueryParam + "=" + base64.URLEncoding.EncodeToString(full)
+
+		result, err := c.CreateRequestURL(baseURL, newURLTestRequest(t, peerDID), WithDIDKeyService())
+		require.NoError(t, err)
+		require.Less(t, result.Size, len(fullURL))
+
+		decoded := decodeRequestURL(t, result.URL)
+		require.Len(t, decoded.Service, 1)
+
+		svc, ok := decoded.Service[0].(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, doc.Service[0].ServiceEndpoint, svc["serviceEndpoint"])
+
+		recipientKeys, ok := svc["recipientKeys"].([]interface{})
+		require.True(t, ok)
+		require.Len(t, recipientKeys, 1)
+		require.True(t, strings.HasPrefix(recipientKeys[0].(string), "did:key:z6Mk"))
+	})
+
+	t.Run("did service is kept without did:key option", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+
+		result, err := c.CreateRequestURL(baseURL, newURLTestRequest(t, peerDID))
+		require.NoError(t, err)
+
+		decoded := decodeRequestURL(t, result.URL)
+		require.Equal(t, []interface{}{peerDID}, decoded.Service)
+	})
+
+	t.Run("size above the qr code threshold", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+
+		req := newURLTestRequest(t, peerDID)
+		req.Label = strings.Repeat("label", QRCodeSizeThreshold)
+
+		result, err := c.CreateRequestURL(baseURL, req)
+		require.NoError(t, err)
+		require.Greater(t, result.Size, QRCodeSizeThreshold)
+	})
+
+	t.Run("fails with no request", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+
+		_, err = c.CreateRequestURL(baseURL, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "request is required")
+	})
+
+	t.Run("fails with invalid base url", func(t *testing.T) {
+		c, err := New(withTestProvider())
+		require.NoError(t, err)
+
+		_, err = c.CreateRequestURL("://invalid", newURLTestRequest(t, peerDID))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse base url")
+	})
+
+	t.Run("fails to resolve peer did", func(t *testing.T) {
+		prov := withTestProvider()
+		prov.VDRIRegistryValue = &mockvdri.MockVDRIRegistry{ResolveErr: errors.New("resolve error")}
+
+		c, err := New(prov)
+		require.NoError(t, err)
+
+		_, err = c.CreateRequestURL(baseURL, newURLTestRequest(t, peerDID), WithDIDKeyService())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolve error")
+	})
+
+	t.Run("fails when peer did has no did-communication service", func(t *testing.T) {
+		prov := withTestProvider()
+		prov.VDRIRegistryValue = &mockvdri.MockVDRIRegistry{ResolveValue: &did.Doc{ID: peerDID}}
+
+		c, err := New(prov)
+		require.NoError(t, err)
+
+		_, err = c.CreateRequestURL(baseURL, newURLTestRequest(t, peerDID), WithDIDKeyService())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no did-communication service found")
+	})
+
+	t.Run("fails with invalid recipient key", func(t *testing.T) {
+		doc := newPeerDIDDoc(t)
+		doc.Service[0].RecipientKeys = []string{"invalid"}
+
+		prov := withTestProvider()
+		prov.VDRIRegistryValue = &mockvdri.MockVDRIRegistry{ResolveValue: doc}
+
+		c, err := New(prov)
+		require.NoError(t, err)
+
+		_, err = c.CreateRequestURL(baseURL, newURLTestRequest(t, peerDID), WithDIDKeyService())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "convert key to did:key")
+	})
+}
+
+func newURLTestRequest(t *testing.T, svc interface{}) *Request {
+	req := &Request{Request: &outofband.Request{
+		ID:       uuid.New().String(),
+		Type:     RequestMsgType,
+		Label:    "Alice",
+		Goal:     "To issue a Faber College Graduate credential",
+		GoalCode: "issue-vc",
+		Service:  []interface{}{svc},
+	}}
+
+	require.NoError(t, WithAttachments(dummyAttachment(t))(req))
+
+	return req
+}
+
+func newPeerDIDDoc(t *testing.T) *did.Doc {
+	return &did.Doc{
+		ID: peerDID,
+		Service: []did.Service{{
+			ID:              peerDID + "#didcomm",
+			Type:            didCommServiceType,
+			RecipientKeys:   []string{newVerKey(t)},
+			RoutingKeys:     []string{newVerKey(t)},
+			ServiceEndpoint: "https://router.example.com/didcomm",
+		}},
+	}
+}
+
+func newVerKey(t *testing.T) string {
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return base58.Encode(pubKey)
+}
+
+func decodeRequestURL(t *testing.T, reqURL string) *outofband.Request {
+	u, err := url.Parse(reqURL)
+	require.NoError(t, err)
+
+	payload, err := base64.RawURLEncoding.DecodeString(u.Query().Get(URLQueryParam))
+	require.NoError(t, err)
+
+	req := &outofband.Request{}
+	require.NoError(t, json.Unmarshal(payload, req))
+
+	return req
+}
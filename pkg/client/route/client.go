@@ -7,8 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package route
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
@@ -22,6 +24,7 @@ type provider interface {
 // Client enable access to route api.
 type Client struct {
 	routeSvc protocolService
+	after    func(d time.Duration) <-chan time.Time
 }
 
 // protocolService defines DID Exchange service.
@@ -37,6 +40,24 @@ type protocolService interface {
 
 	// GetConnection returns the connectionID of the router.
 	GetConnection() (string, error)
+
+	// Renew requests the route again to the router to renew the route lease
+	Renew() error
+
+	// Config gives back the router configuration
+	Config() (*route.Config, error)
+
+	// Keys returns the recipient keys registered with the router
+	Keys(connectionID string) ([]string, error)
+
+	// RemoveKeys removes all the recipient keys registered with the router
+	RemoveKeys(connectionID string) error
+
+	// AllocateKey creates a recipient key dedicated to a connection and registers it with the router
+	AllocateKey(connectionID string) (string, error)
+
+	// ReleaseKey removes the recipient key allocated to a connection from the router
+	ReleaseKey(connectionID string, deleteKey bool) error
 }
 
 // New return new instance of route client.
@@ -53,6 +74,7 @@ func New(ctx provider) (*Client, error) {
 
 	return &Client{
 		routeSvc: routeSvc,
+		after:    time.After,
 	}, nil
 }
 
@@ -66,6 +88,29 @@ func (c *Client) Register(connectionID string) error {
 	return nil
 }
 
+// RegisterWithRetry registers the agent with the router like Register. When the router denies mediation with a
+// retry-after hint (see route.MediationDeniedError), the route is requested again once the retry-after elapsed,
+// until the router grants the route, denies mediation without hint, or ctx is done.
+func (c *Client) RegisterWithRetry(ctx context.Context, connectionID string) error {
+	for {
+		err := c.routeSvc.Register(connectionID)
+		if err == nil {
+			return nil
+		}
+
+		var denied *route.MediationDeniedError
+		if !errors.As(err, &denied) || denied.RetryAfter <= 0 {
+			return fmt.Errorf("router registration : %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("router registration : %s : %w", err, ctx.Err())
+		case <-c.after(denied.RetryAfter):
+		}
+	}
+}
+
 // Unregister unregisters the agent with the router.
 func (c *Client) Unregister() error {
 	if err := c.routeSvc.Unregister(); err != nil {
@@ -85,3 +130,66 @@ func (c *Client) GetConnection() (string, error) {
 
 	return connectionID, nil
 }
+
+// LeaseExpiry returns the expiry of the route lease granted by the router, or the zero time if the router granted
+// the route without lease.
+func (c *Client) LeaseExpiry() (time.Time, error) {
+	conf, err := c.routeSvc.Config()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get router config : %w", err)
+	}
+
+	return conf.LeaseExpires(), nil
+}
+
+// Renew requests the route again to the router to renew the route lease. It must be called before the
+// lease expires (see LeaseExpiry), the router stops forwarding messages once the lease expired.
+func (c *Client) Renew() error {
+	if err := c.routeSvc.Renew(); err != nil {
+		return fmt.Errorf("router renew : %w", err)
+	}
+
+	return nil
+}
+
+// Keys returns the recipient keys of the agent registered with the router of connectionID.
+func (c *Client) Keys(connectionID string) ([]string, error) {
+	keys, err := c.routeSvc.Keys(connectionID)
+	if err != nil {
+		return nil, fmt.Errorf("get router keys : %w", err)
+	}
+
+	return keys, nil
+}
+
+// RemoveKeys asks the router of connectionID to drop all the recipient keys of the agent at once, eg: when the
+// agent is decommissioned. The router stops forwarding messages for these keys.
+func (c *Client) RemoveKeys(connectionID string) error {
+	if err := c.routeSvc.RemoveKeys(connectionID); err != nil {
+		return fmt.Errorf("router remove keys : %w", err)
+	}
+
+	return nil
+}
+
+// AllocateKey creates a recipient key dedicated to the connection connectionID, registers it with the router and
+// returns it, so the router can't correlate the connections of the agent. The DID exchanges allocate the key of their
+// connection when the agent is registered with a router, the key is then the recipient key of the connection.
+func (c *Client) AllocateKey(connectionID string) (string, error) {
+	recKey, err := c.routeSvc.AllocateKey(connectionID)
+	if err != nil {
+		return "", fmt.Errorf("router allocate key : %w", err)
+	}
+
+	return recKey, nil
+}
+
+// ReleaseKey asks the router to stop forwarding messages for the recipient key allocated to the connection
+// connectionID, eg: when the connection is torn down. The key is also deleted from the KMS if deleteKey is set.
+func (c *Client) ReleaseKey(connectionID string, deleteKey bool) error {
+	if err := c.routeSvc.ReleaseKey(connectionID, deleteKey); err != nil {
+		return fmt.Errorf("router release key : %w", err)
+	}
+
+	return nil
+}
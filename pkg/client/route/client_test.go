@@ -7,12 +7,15 @@ SPDX-License-Identifier: Apache-2.0
 package route
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
 	mockroute "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/route"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
 )
@@ -119,3 +122,211 @@ func TestGetConnection(t *testing.T) {
 		require.Empty(t, connID)
 	})
 }
+
+func TestRenew(t *testing.T) {
+	t.Run("test renew - success", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{},
+		})
+		require.NoError(t, err)
+
+		err = c.Renew()
+		require.NoError(t, err)
+	})
+
+	t.Run("test renew - error", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{
+				RenewErr: errors.New("renew error"),
+			},
+		})
+		require.NoError(t, err)
+
+		err = c.Renew()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "router renew")
+	})
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	t.Run("test lease expiry - route granted without lease", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{
+				RouterEndpoint: "http://router.example.com",
+				RoutingKeys:    []string{"abc"},
+			},
+		})
+		require.NoError(t, err)
+
+		expiry, err := c.LeaseExpiry()
+		require.NoError(t, err)
+		require.True(t, expiry.IsZero())
+	})
+
+	t.Run("test lease expiry - error", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{
+				ConfigErr: errors.New("config error"),
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = c.LeaseExpiry()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get router config")
+	})
+}
+
+func TestKeys(t *testing.T) {
+	t.Run("test keys - success", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{KeysValue: []string{"abc", "xyz"}},
+		})
+		require.NoError(t, err)
+
+		keys, err := c.Keys("conn1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"abc", "xyz"}, keys)
+	})
+
+	t.Run("test keys - error", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{KeysErr: errors.New("keys error")},
+		})
+		require.NoError(t, err)
+
+		_, err = c.Keys("conn1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get router keys")
+	})
+}
+
+func TestRemoveKeys(t *testing.T) {
+	t.Run("test remove keys - success", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, c.RemoveKeys("conn1"))
+	})
+
+	t.Run("test remove keys - error", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{RemoveKeysErr: errors.New("remove error")},
+		})
+		require.NoError(t, err)
+
+		err = c.RemoveKeys("conn1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "router remove keys")
+	})
+}
+
+func TestConnectionKeys(t *testing.T) {
+	t.Run("test allocate and release key - success", func(t *testing.T) {
+		allocated := map[string]string{}
+
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{
+				AllocateKeyFunc: func(connectionID string) (string, error) {
+					allocated[connectionID] = "key-" + connectionID
+					return allocated[connectionID], nil
+				},
+				ReleaseKeyFunc: func(connectionID string, deleteKey bool) error {
+					require.True(t, deleteKey)
+					delete(allocated, connectionID)
+
+					return nil
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		recKey, err := c.AllocateKey("conn1")
+		require.NoError(t, err)
+		require.Equal(t, "key-conn1", recKey)
+
+		require.NoError(t, c.ReleaseKey("conn1", true))
+		require.Empty(t, allocated)
+	})
+
+	t.Run("test allocate and release key - error", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{
+				ReleaseKeyFunc: func(string, bool) error {
+					return errors.New("release error")
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = c.AllocateKey("conn1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "router allocate key")
+
+		err = c.ReleaseKey("conn1", false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "router release key")
+	})
+}
+
+func TestRegisterWithRetry(t *testing.T) {
+	t.Run("test register with retry - the retry-after is honored", func(t *testing.T) {
+		registered := 0
+
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{RegisterFunc: func(connectionID string) error {
+				registered++
+
+				if registered < 3 {
+					return &route.MediationDeniedError{RetryAfter: time.Duration(registered) * time.Minute}
+				}
+
+				return nil
+			}}})
+		require.NoError(t, err)
+
+		// fake clock: the retry-after elapses immediately
+		var waited []time.Duration
+
+		c.after = func(d time.Duration) <-chan time.Time {
+			waited = append(waited, d)
+
+			ch := make(chan time.Time, 1)
+			ch <- time.Now()
+
+			return ch
+		}
+
+		require.NoError(t, c.RegisterWithRetry(context.Background(), "conn1"))
+		require.Equal(t, 3, registered)
+		require.Equal(t, []time.Duration{time.Minute, 2 * time.Minute}, waited)
+	})
+
+	t.Run("test register with retry - denied without retry-after", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{RegisterFunc: func(connectionID string) error {
+				return &route.MediationDeniedError{Reason: "policy"}
+			}}})
+		require.NoError(t, err)
+
+		err = c.RegisterWithRetry(context.Background(), "conn1")
+		require.True(t, errors.Is(err, route.ErrMediationDenied))
+		require.Contains(t, err.Error(), "router registration")
+	})
+
+	t.Run("test register with retry - context done", func(t *testing.T) {
+		c, err := New(&mockprovider.Provider{
+			ServiceValue: &mockroute.MockRouteSvc{RegisterFunc: func(connectionID string) error {
+				return &route.MediationDeniedError{RetryAfter: time.Hour}
+			}}})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err = c.RegisterWithRetry(ctx, "conn1")
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+}
@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package idgen
+
+import (
+	"encoding/binary"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/google/uuid"
+)
+
+// IDGenerator generates the IDs of the keys and of the records created by the framework, eg: the keysets stored by
+// the KMS and the connection records. The IDs must be unique.
+type IDGenerator interface {
+	NewID() string
+}
+
+// UUID generates random (version 4) UUIDs.
+type UUID struct{}
+
+// NewID returns a new random UUID.
+func (UUID) NewID() string {
+	return uuid.New().String()
+}
+
+const (
+	// ksuidEpoch is the epoch of the KSUID timestamps (2014-05-13), in Unix time
+	ksuidEpoch         = 1400000000
+	ksuidTimestampSize = 4
+	ksuidPayloadSize   = 16
+	ksuidLength        = 27
+	base62Alphabet     = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// KSUID generates K-Sortable Unique IDs: a 32 bit timestamp, in seconds, followed by a 128 bit random payload,
+// encoded as 27 base62 characters. The IDs generated in different seconds sort by time.
+type KSUID struct {
+	// now returns the current time, time.Now if nil
+	now func() time.Time
+}
+
+// NewID returns a new KSUID.
+func (k KSUID) NewID() string {
+	now := time.Now
+	if k.now != nil {
+		now = k.now
+	}
+
+	id := make([]byte, ksuidTimestampSize, ksuidTimestampSize+ksuidPayloadSize)
+	binary.BigEndian.PutUint32(id, uint32(now().Unix()-ksuidEpoch))
+	id = append(id, random.GetRandomBytes(ksuidPayloadSize)...)
+
+	n := new(big.Int).SetBytes(id)
+	base := big.NewInt(int64(len(base62Alphabet)))
+	mod := new(big.Int)
+
+	var encoded []byte
+
+	for n.Sign() > 0 {
+		n.DivMod(n, base, mod)
+		encoded = append(encoded, base62Alphabet[mod.Int64()])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return strings.Repeat("0", ksuidLength-len(encoded)) + string(encoded)
+}
+
+// Sequential generates the IDs prefix1, prefix2, ..., eg: for deterministic IDs in tests. The IDs are unique for
+// a generator only, the sequence restarts with a new generator.
+type Sequential struct {
+	prefix string
+	last   uint64
+}
+
+// NewSequential returns a generator of sequential IDs starting with prefix.
+func NewSequential(prefix string) *Sequential {
+	return &Sequential{prefix: prefix}
+}
+
+// NewID returns the next ID of the sequence.
+func (s *Sequential) NewID() string {
+	return s.prefix + strconv.FormatUint(atomic.AddUint64(&s.last, 1), 10)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package idgen
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUUID(t *testing.T) {
+	id := UUID{}.NewID()
+
+	parsed, err := uuid.Parse(id)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Version(4), parsed.Version())
+	require.NotEqual(t, id, UUID{}.NewID())
+}
+
+func TestKSUID(t *testing.T) {
+	t.Run("test KSUID format", func(t *testing.T) {
+		id := KSUID{}.NewID()
+		require.Regexp(t, regexp.MustCompile("^[0-9A-Za-z]{27}$"), id)
+		require.NotEqual(t, id, KSUID{}.NewID())
+	})
+
+	t.Run("test KSUIDs sort by time", func(t *testing.T) {
+		start := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+
+		var ids []string
+
+		for i := 0; i < 10; i++ {
+			created := start.Add(time.Duration(i) * time.Hour)
+			ids = append(ids, KSUID{now: func() time.Time { return created }}.NewID())
+		}
+
+		require.True(t, sort.StringsAreSorted(ids))
+	})
+
+	t.Run("test KSUID at the epoch is padded", func(t *testing.T) {
+		id := KSUID{now: func() time.Time { return time.Unix(ksuidEpoch, 0) }}.NewID()
+		require.Len(t, id, ksuidLength)
+	})
+}
+
+func TestSequential(t *testing.T) {
+	t.Run("test sequential IDs", func(t *testing.T) {
+		gen := NewSequential("key-")
+
+		require.Equal(t, "key-1", gen.NewID())
+		require.Equal(t, "key-2", gen.NewID())
+		require.Equal(t, "1", NewSequential("").NewID())
+	})
+
+	t.Run("test concurrent sequential IDs are unique", func(t *testing.T) {
+		gen := NewSequential("")
+		ids := sync.Map{}
+		wg := sync.WaitGroup{}
+
+		const count = 100
+
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, loaded := ids.LoadOrStore(gen.NewID(), true)
+				require.False(t, loaded)
+			}()
+		}
+
+		wg.Wait()
+
+		require.Equal(t, "101", gen.NewID())
+	})
+}
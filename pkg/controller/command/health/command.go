@@ -0,0 +1,182 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/logutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+var logger = log.New("aries-framework/command/health")
+
+const (
+	// command name
+	commandName = "health"
+
+	// command methods
+	healthCheckCommandMethod = "HealthCheck"
+
+	// subsystem names
+	kmsSubsystem       = "kms"
+	storageSubsystem   = "storage"
+	transportSubsystem = "transport"
+
+	// healthCheckStore is the store opened and read to check the storage health, the connection store is opened by
+	// the framework anyway so the probe doesn't create any store
+	healthCheckStore = connection.Namespace
+	healthCheckKey   = "healthcheck"
+)
+
+// provider contains dependencies for the health command and is typically created by using aries.Context().
+type provider interface {
+	KMS() kms.KeyManager
+	StorageProvider() storage.Provider
+	OutboundTransports() []transport.OutboundTransport
+	InboundTransports() []transport.InboundTransport
+}
+
+// healthChecker is implemented by key managers and inbound transports able to check their health (eg: LocalKMS).
+type healthChecker interface {
+	HealthCheck() error
+}
+
+// Command contains command operations provided by health controller.
+type Command struct {
+	ctx provider
+}
+
+// New returns new health command instance.
+func New(p provider) *Command {
+	return &Command{
+		ctx: p,
+	}
+}
+
+// GetHandlers returns list of all commands supported by this controller command.
+func (o *Command) GetHandlers() []command.Handler {
+	return []command.Handler{
+		cmdutil.NewCommandHandler(commandName, healthCheckCommandMethod, o.HealthCheck),
+	}
+}
+
+// HealthCheck checks the health of the KMS, the storage and the transports, and returns the status of each of them
+// along with the overall status (see Check).
+func (o *Command) HealthCheck(rw io.Writer, req io.Reader) command.Error {
+	response := o.Check()
+
+	command.WriteNillableResponse(rw, response, logger)
+
+	logutil.LogDebug(logger, commandName, healthCheckCommandMethod, "success",
+		logutil.CreateKeyValueString("status", response.Status))
+
+	return nil
+}
+
+// Check checks the health of the KMS, the storage and the transports, and returns the status of each of them along
+// with the overall status: ok if every subsystem is healthy, failed if all of them are failing and degraded otherwise.
+// Subsystems not supporting health checks are reported with the unknown status.
+func (o *Command) Check() *HealthCheckResponse {
+	subsystems := []SubsystemHealth{
+		subsystemHealth(kmsSubsystem, o.checkKMS()),
+		subsystemHealth(storageSubsystem, o.checkStorage()),
+		subsystemHealth(transportSubsystem, o.checkTransport()),
+	}
+
+	return &HealthCheckResponse{Status: overallStatus(subsystems), Subsystems: subsystems}
+}
+
+// errHealthCheckUnsupported is returned by the subsystems not supporting health checks.
+var errHealthCheckUnsupported = errors.New("health check not supported")
+
+func (o *Command) checkKMS() error {
+	checker, ok := o.ctx.KMS().(healthChecker)
+	if !ok {
+		return errHealthCheckUnsupported
+	}
+
+	return checker.HealthCheck()
+}
+
+func (o *Command) checkStorage() error {
+	store, err := o.ctx.StorageProvider().OpenStore(healthCheckStore)
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+
+	// the probe key is never stored: a read reaching the store reports it as not found
+	_, err = store.Get(healthCheckKey)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("read: %w", err)
+	}
+
+	return nil
+}
+
+// checkTransport checks that the agent has outbound transports, and that its inbound transports (if any, an agent
+// may only be reached through return routes) have an endpoint and are healthy if they support health checks.
+func (o *Command) checkTransport() error {
+	if len(o.ctx.OutboundTransports()) == 0 {
+		return errors.New("no outbound transport")
+	}
+
+	for i, inbound := range o.ctx.InboundTransports() {
+		if inbound.Endpoint() == "" {
+			return fmt.Errorf("inbound transport %d: no endpoint", i)
+		}
+
+		if checker, ok := inbound.(healthChecker); ok {
+			if err := checker.HealthCheck(); err != nil {
+				return fmt.Errorf("inbound transport %s: %w", inbound.Endpoint(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func subsystemHealth(name string, err error) SubsystemHealth {
+	switch {
+	case err == nil:
+		return SubsystemHealth{Name: name, Status: StatusOK}
+	case errors.Is(err, errHealthCheckUnsupported):
+		return SubsystemHealth{Name: name, Status: StatusUnknown}
+	default:
+		logutil.LogError(logger, commandName, healthCheckCommandMethod, err.Error(),
+			logutil.CreateKeyValueString("subsystem", name))
+
+		return SubsystemHealth{Name: name, Status: StatusFailed, Error: err.Error()}
+	}
+}
+
+func overallStatus(subsystems []SubsystemHealth) string {
+	failed := 0
+
+	for _, s := range subsystems {
+		if s.Status == StatusFailed {
+			failed++
+		}
+	}
+
+	switch failed {
+	case 0:
+		return StatusOK
+	case len(subsystems):
+		return StatusFailed
+	default:
+		return StatusDegraded
+	}
+}
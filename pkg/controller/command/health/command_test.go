@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestNew(t *testing.T) {
+	cmd := New(&mockprovider.Provider{})
+	require.NotNil(t, cmd)
+	require.Equal(t, 1, len(cmd.GetHandlers()))
+}
+
+func TestHealthCheck(t *testing.T) {
+	healthyProvider := func() *mockprovider.Provider {
+		return &mockprovider.Provider{
+			CustomKMS:               &mockkms.KeyManager{},
+			StorageProviderValue:    mockstore.NewMockStoreProvider(),
+			OutboundTransportsValue: []transport.OutboundTransport{didcomm.NewMockOutboundTransport("")},
+		}
+	}
+
+	healthCheck := func(t *testing.T, p provider) *HealthCheckResponse {
+		var b bytes.Buffer
+		cmdErr := New(p).HealthCheck(&b, nil)
+		require.NoError(t, cmdErr)
+
+		response := &HealthCheckResponse{}
+		err := json.NewDecoder(&b).Decode(response)
+		require.NoError(t, err)
+
+		return response
+	}
+
+	t.Run("test all subsystems healthy", func(t *testing.T) {
+		response := healthCheck(t, healthyProvider())
+		require.Equal(t, &HealthCheckResponse{
+			Status: StatusOK,
+			Subsystems: []SubsystemHealth{
+				{Name: kmsSubsystem, Status: StatusOK},
+				{Name: storageSubsystem, Status: StatusOK},
+				{Name: transportSubsystem, Status: StatusOK},
+			},
+		}, response)
+	})
+
+	t.Run("test kms failure", func(t *testing.T) {
+		p := healthyProvider()
+		p.CustomKMS = &mockkms.KeyManager{HealthCheckErr: errors.New("secret lock unavailable")}
+
+		response := healthCheck(t, p)
+		require.Equal(t, StatusDegraded, response.Status)
+		require.Equal(t, SubsystemHealth{Name: kmsSubsystem, Status: StatusFailed, Error: "secret lock unavailable"},
+			response.Subsystems[0])
+		require.Equal(t, StatusOK, response.Subsystems[1].Status)
+		require.Equal(t, StatusOK, response.Subsystems[2].Status)
+	})
+
+	t.Run("test kms without health check", func(t *testing.T) {
+		p := healthyProvider()
+		p.CustomKMS = struct{ kmsapi.KeyManager }{&mockkms.KeyManager{}}
+
+		response := healthCheck(t, p)
+		require.Equal(t, StatusOK, response.Status)
+		require.Equal(t, SubsystemHealth{Name: kmsSubsystem, Status: StatusUnknown}, response.Subsystems[0])
+	})
+
+	t.Run("test storage failures", func(t *testing.T) {
+		p := healthyProvider()
+		p.StorageProviderValue = &mockstore.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")}
+
+		response := healthCheck(t, p)
+		require.Equal(t, StatusDegraded, response.Status)
+		require.Equal(t, "open store: open error", response.Subsystems[1].Error)
+
+		p.StorageProviderValue = mockstore.NewCustomMockStoreProvider(&mockstore.MockStore{
+			Store:  map[string][]byte{},
+			ErrGet: errors.New("get error"),
+		})
+
+		response = healthCheck(t, p)
+		require.Equal(t, "read: get error", response.Subsystems[1].Error)
+	})
+
+	t.Run("test storage probe is read only", func(t *testing.T) {
+		p := healthyProvider()
+		store := &mockstore.MockStore{Store: map[string][]byte{}, ErrPut: errors.New("put error")}
+		p.StorageProviderValue = mockstore.NewCustomMockStoreProvider(store)
+
+		response := healthCheck(t, p)
+		require.Equal(t, SubsystemHealth{Name: storageSubsystem, Status: StatusOK}, response.Subsystems[1])
+		require.Empty(t, store.Store)
+	})
+
+	t.Run("test inbound transports", func(t *testing.T) {
+		p := healthyProvider()
+		p.InboundTransportsValue = []transport.InboundTransport{&mockInboundTransport{endpoint: "http://agent"}}
+
+		response := healthCheck(t, p)
+		require.Equal(t, SubsystemHealth{Name: transportSubsystem, Status: StatusOK}, response.Subsystems[2])
+
+		p.InboundTransportsValue = append(p.InboundTransportsValue, &mockInboundTransport{})
+
+		response = healthCheck(t, p)
+		require.Equal(t, StatusDegraded, response.Status)
+		require.Equal(t, SubsystemHealth{Name: transportSubsystem, Status: StatusFailed,
+			Error: "inbound transport 1: no endpoint"}, response.Subsystems[2])
+
+		p.InboundTransportsValue = []transport.InboundTransport{&mockInboundTransport{
+			endpoint:       "ws://agent",
+			healthCheckErr: errors.New("listener closed"),
+		}}
+
+		response = healthCheck(t, p)
+		require.Equal(t, SubsystemHealth{Name: transportSubsystem, Status: StatusFailed,
+			Error: "inbound transport ws://agent: listener closed"}, response.Subsystems[2])
+	})
+
+	t.Run("test all subsystems failing", func(t *testing.T) {
+		response := healthCheck(t, &mockprovider.Provider{
+			CustomKMS:            &mockkms.KeyManager{HealthCheckErr: errors.New("kms error")},
+			StorageProviderValue: &mockstore.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")},
+		})
+		require.Equal(t, StatusFailed, response.Status)
+		require.Equal(t, SubsystemHealth{Name: transportSubsystem, Status: StatusFailed, Error: "no outbound transport"},
+			response.Subsystems[2])
+	})
+}
+
+type mockInboundTransport struct {
+	endpoint       string
+	healthCheckErr error
+}
+
+func (m *mockInboundTransport) Start(prov transport.Provider) error {
+	return nil
+}
+
+func (m *mockInboundTransport) Stop() error {
+	return nil
+}
+
+func (m *mockInboundTransport) Endpoint() string {
+	return m.endpoint
+}
+
+func (m *mockInboundTransport) HealthCheck() error {
+	return m.healthCheckErr
+}
@@ -0,0 +1,37 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+// Health statuses
+const (
+	// StatusOK is the status of a healthy subsystem, or the overall status if every subsystem is healthy
+	StatusOK = "ok"
+	// StatusFailed is the status of a failing subsystem, or the overall status if every subsystem is failing
+	StatusFailed = "failed"
+	// StatusDegraded is the overall status if some of the subsystems are failing
+	StatusDegraded = "degraded"
+	// StatusUnknown is the status of a subsystem not supporting health checks
+	StatusUnknown = "unknown"
+)
+
+// SubsystemHealth is the health of an agent subsystem
+type SubsystemHealth struct {
+	// Name of the subsystem (kms, storage or transport)
+	Name string `json:"name"`
+	// Status of the subsystem (ok, failed or unknown)
+	Status string `json:"status"`
+	// Error is the reason of the failure of the subsystem
+	Error string `json:"error,omitempty"`
+}
+
+// HealthCheckResponse for returning the health of the agent subsystems
+type HealthCheckResponse struct {
+	// Status is the overall status (ok, degraded or failed)
+	Status string `json:"status"`
+	// Subsystems are the statuses of the agent subsystems
+	Subsystems []SubsystemHealth `json:"subsystems"`
+}
@@ -7,13 +7,18 @@ SPDX-License-Identifier: Apache-2.0
 package kms
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
+	"sync"
 
 	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/logutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 )
 
 var logger = log.New("aries-framework/command/kms")
@@ -22,6 +27,18 @@ var logger = log.New("aries-framework/command/kms")
 const (
 	// CreateKeySetError is for failures while creating key set
 	CreateKeySetError = command.Code(iota + command.KMS)
+	// VerifyKeystoreError is for failures while verifying the keystore integrity
+	VerifyKeystoreError
+	// GetStatsError is for failures while getting the KMS statistics
+	GetStatsError
+	// InvalidRequestErrorCode is for invalid requests
+	InvalidRequestErrorCode
+	// RotateKeysError is for failures while listing the keys to rotate
+	RotateKeysError
+	// ExportArchiveError is for failures while exporting the keystore archive
+	ExportArchiveError
+	// ImportArchiveError is for failures while importing a keystore archive
+	ImportArchiveError
 )
 
 const (
@@ -29,12 +46,42 @@ const (
 	commandName = "kms"
 
 	// command methods
-	createKeySetCommandMethod = "CreateKeySet"
+	createKeySetCommandMethod   = "CreateKeySet"
+	verifyKeystoreCommandMethod = "VerifyKeystore"
+	getStatsCommandMethod       = "GetStats"
+	rotateKeysCommandMethod     = "RotateKeys"
+	exportArchiveCommandMethod  = "ExportArchive"
+	importArchiveCommandMethod  = "ImportArchive"
+
+	// maxConcurrentRotations bounds the number of keys rotated concurrently by RotateKeys
+	maxConcurrentRotations = 4
 )
 
 // provider contains dependencies for the kms command and is typically created by using aries.Context().
 type provider interface {
 	LegacyKMS() legacykms.KeyManager
+	KMS() kms.KeyManager
+}
+
+// keystoreVerifier is implemented by key managers able to verify the integrity of their keystore (eg: LocalKMS).
+type keystoreVerifier interface {
+	Verify() ([]string, error)
+}
+
+// statsProvider is implemented by key managers providing statistics of their stored keys (eg: LocalKMS).
+type statsProvider interface {
+	Stats() (*localkms.KMSStats, error)
+}
+
+// keyLister is implemented by key managers able to list their keys of a key type (eg: LocalKMS).
+type keyLister interface {
+	ListByKeyType(kt kms.KeyType) ([]string, error)
+}
+
+// archiver is implemented by key managers able to back up and restore their keystore (eg: LocalKMS).
+type archiver interface {
+	ExportArchive(w io.Writer) error
+	ImportArchive(r io.Reader) (int, error)
 }
 
 // Command contains command operations provided by verifiable credential controller.
@@ -53,6 +100,11 @@ func New(p provider) *Command {
 func (o *Command) GetHandlers() []command.Handler {
 	return []command.Handler{
 		cmdutil.NewCommandHandler(commandName, createKeySetCommandMethod, o.CreateKeySet),
+		cmdutil.NewCommandHandler(commandName, verifyKeystoreCommandMethod, o.VerifyKeystore),
+		cmdutil.NewCommandHandler(commandName, getStatsCommandMethod, o.GetStats),
+		cmdutil.NewCommandHandler(commandName, rotateKeysCommandMethod, o.RotateKeys),
+		cmdutil.NewCommandHandler(commandName, exportArchiveCommandMethod, o.ExportArchive),
+		cmdutil.NewCommandHandler(commandName, importArchiveCommandMethod, o.ImportArchive),
 	}
 }
 
@@ -73,3 +125,204 @@ func (o *Command) CreateKeySet(rw io.Writer, req io.Reader) command.Error {
 
 	return nil
 }
+
+// VerifyKeystore verifies the integrity of the keystore and returns the IDs of the keysets failing verification.
+func (o *Command) VerifyKeystore(rw io.Writer, req io.Reader) command.Error {
+	verifier, ok := o.ctx.KMS().(keystoreVerifier)
+	if !ok {
+		err := fmt.Errorf("kms does not support keystore verification")
+		logutil.LogError(logger, commandName, verifyKeystoreCommandMethod, err.Error())
+
+		return command.NewExecuteError(VerifyKeystoreError, err)
+	}
+
+	failed, err := verifier.Verify()
+	if err != nil {
+		logutil.LogError(logger, commandName, verifyKeystoreCommandMethod, err.Error())
+		return command.NewExecuteError(VerifyKeystoreError, err)
+	}
+
+	command.WriteNillableResponse(rw, &VerifyKeystoreResponse{FailedKeyIDs: failed}, logger)
+
+	logutil.LogDebug(logger, commandName, verifyKeystoreCommandMethod, "success")
+
+	return nil
+}
+
+// GetStats returns the statistics of the keys stored by the KMS: total keys, counts per key type, oldest and newest
+// creation time and keys failing to be unwrapped. It never returns key material.
+func (o *Command) GetStats(rw io.Writer, req io.Reader) command.Error {
+	provider, ok := o.ctx.KMS().(statsProvider)
+	if !ok {
+		err := fmt.Errorf("kms does not support statistics")
+		logutil.LogError(logger, commandName, getStatsCommandMethod, err.Error())
+
+		return command.NewExecuteError(GetStatsError, err)
+	}
+
+	stats, err := provider.Stats()
+	if err != nil {
+		logutil.LogError(logger, commandName, getStatsCommandMethod, err.Error())
+		return command.NewExecuteError(GetStatsError, err)
+	}
+
+	command.WriteNillableResponse(rw, &GetStatsResponse{KMSStats: stats}, logger)
+
+	logutil.LogDebug(logger, commandName, getStatsCommandMethod, "success")
+
+	return nil
+}
+
+// RotateKeys rotates all the keys of a key type (eg: all the ED25519 keys for incident response). It continues past
+// the keys failing to be rotated and returns the old and new IDs of the rotated keys along with the failures.
+// The previous keysets are kept if RetainPrevious is set, so signatures of the previous keys can still be verified.
+func (o *Command) RotateKeys(rw io.Writer, req io.Reader) command.Error {
+	var request RotateKeysRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, rotateKeysCommandMethod, "request decode : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("request decode : %w", err))
+	}
+
+	if request.KeyType == "" {
+		logutil.LogDebug(logger, commandName, rotateKeysCommandMethod, "missing key type")
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("key type is mandatory"))
+	}
+
+	lister, ok := o.ctx.KMS().(keyLister)
+	if !ok {
+		err = fmt.Errorf("kms does not support listing keys by key type")
+		logutil.LogError(logger, commandName, rotateKeysCommandMethod, err.Error())
+
+		return command.NewExecuteError(RotateKeysError, err)
+	}
+
+	rotate := o.ctx.KMS().Rotate
+
+	if request.RetainPrevious {
+		creator, ok := o.ctx.KMS().(kms.KeyCreatorWithOptions)
+		if !ok {
+			err = fmt.Errorf("kms does not support retaining the previous keys")
+			logutil.LogError(logger, commandName, rotateKeysCommandMethod, err.Error())
+
+			return command.NewExecuteError(RotateKeysError, err)
+		}
+
+		rotate = func(kt kms.KeyType, keyID string) (string, interface{}, error) {
+			return creator.RotateWithOptions(kt, keyID, kms.WithRetainPrevious())
+		}
+	}
+
+	keyIDs, err := lister.ListByKeyType(request.KeyType)
+	if err != nil {
+		logutil.LogError(logger, commandName, rotateKeysCommandMethod, err.Error())
+		return command.NewExecuteError(RotateKeysError, err)
+	}
+
+	command.WriteNillableResponse(rw, o.rotateKeys(keyIDs, request.KeyType, rotate), logger)
+
+	logutil.LogDebug(logger, commandName, rotateKeysCommandMethod, "success")
+
+	return nil
+}
+
+// ExportArchive writes the archive of the keystore to rw as it is read from the keystore, without buffering it. The
+// keys of the archive are encrypted with the master key of the KMS, it can be restored with ImportArchive.
+func (o *Command) ExportArchive(rw io.Writer, req io.Reader) command.Error {
+	a, ok := o.ctx.KMS().(archiver)
+	if !ok {
+		err := fmt.Errorf("kms does not support archives")
+		logutil.LogError(logger, commandName, exportArchiveCommandMethod, err.Error())
+
+		return command.NewExecuteError(ExportArchiveError, err)
+	}
+
+	err := a.ExportArchive(rw)
+	if err != nil {
+		logutil.LogError(logger, commandName, exportArchiveCommandMethod, err.Error())
+		return command.NewExecuteError(ExportArchiveError, err)
+	}
+
+	logutil.LogDebug(logger, commandName, exportArchiveCommandMethod, "success")
+
+	return nil
+}
+
+// ImportArchive restores the keystore archive req exported by ExportArchive and returns the number of imported keys.
+// The archive must be exported by a KMS using the same master key.
+func (o *Command) ImportArchive(rw io.Writer, req io.Reader) command.Error {
+	if req == nil {
+		logutil.LogDebug(logger, commandName, importArchiveCommandMethod, "missing archive")
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("archive is mandatory"))
+	}
+
+	a, ok := o.ctx.KMS().(archiver)
+	if !ok {
+		err := fmt.Errorf("kms does not support archives")
+		logutil.LogError(logger, commandName, importArchiveCommandMethod, err.Error())
+
+		return command.NewExecuteError(ImportArchiveError, err)
+	}
+
+	imported, err := a.ImportArchive(req)
+	if err != nil {
+		logutil.LogError(logger, commandName, importArchiveCommandMethod, err.Error())
+		return command.NewExecuteError(ImportArchiveError, err)
+	}
+
+	command.WriteNillableResponse(rw, &ImportArchiveResponse{Imported: imported}, logger)
+
+	logutil.LogDebug(logger, commandName, importArchiveCommandMethod, "success")
+
+	return nil
+}
+
+// keyRotator rotates the key keyID to a new key of type kt.
+type keyRotator func(kt kms.KeyType, keyID string) (string, interface{}, error)
+
+// rotateKeys rotates the keys keyIDs to keys of type kt with rotate, at most maxConcurrentRotations at a time to avoid
+// hammering the keystore.
+func (o *Command) rotateKeys(keyIDs []string, kt kms.KeyType, rotate keyRotator) *RotateKeysResponse {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		sem  = make(chan struct{}, maxConcurrentRotations)
+		resp = &RotateKeysResponse{Rotated: []RotatedKey{}, Failed: []RotateKeyFailure{}}
+	)
+
+	for _, keyID := range keyIDs {
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func(keyID string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			newID, _, err := rotate(kt, keyID)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				logger.Warnf("failed to rotate key %s : %s", keyID, err)
+
+				resp.Failed = append(resp.Failed, RotateKeyFailure{KeyID: keyID, Error: err.Error()})
+
+				return
+			}
+
+			resp.Rotated = append(resp.Rotated, RotatedKey{OldKeyID: keyID, NewKeyID: newID})
+		}(keyID)
+	}
+
+	wg.Wait()
+
+	return resp
+}
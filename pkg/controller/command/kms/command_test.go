@@ -14,26 +14,32 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
-	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mocklegacykms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
 )
 
 func TestNew(t *testing.T) {
 	t.Run("test new command - success", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{},
+			KMSValue: &mocklegacykms.CloseableKMS{},
 		})
 		require.NotNil(t, cmd)
 
 		handlers := cmd.GetHandlers()
-		require.Equal(t, 1, len(handlers))
+		require.Equal(t, 6, len(handlers))
 	})
 }
 
 func TestCreateKeySet(t *testing.T) {
 	t.Run("test create key set - success", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{CreateEncryptionKeyValue: "encryptionKey",
+			KMSValue: &mocklegacykms.CloseableKMS{CreateEncryptionKeyValue: "encryptionKey",
 				CreateSigningKeyValue: "signingKey"},
 		})
 		require.NotNil(t, cmd)
@@ -53,7 +59,7 @@ func TestCreateKeySet(t *testing.T) {
 
 	t.Run("test create key set - error", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{CreateKeyErr: fmt.Errorf("error create key set")},
+			KMSValue: &mocklegacykms.CloseableKMS{CreateKeyErr: fmt.Errorf("error create key set")},
 		})
 		require.NotNil(t, cmd)
 
@@ -63,3 +69,302 @@ func TestCreateKeySet(t *testing.T) {
 		require.Contains(t, err.Error(), "error create key set")
 	})
 }
+
+func TestVerifyKeystore(t *testing.T) {
+	t.Run("test verify keystore - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{VerifyValue: []string{"corrupted"}},
+		})
+		require.NotNil(t, cmd)
+
+		var getRW bytes.Buffer
+		cmdErr := cmd.VerifyKeystore(&getRW, nil)
+		require.NoError(t, cmdErr)
+
+		response := VerifyKeystoreResponse{}
+		err := json.NewDecoder(&getRW).Decode(&response)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"corrupted"}, response.FailedKeyIDs)
+	})
+
+	t.Run("test verify keystore - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{VerifyErr: fmt.Errorf("error verify keystore")},
+		})
+		require.NotNil(t, cmd)
+
+		var b bytes.Buffer
+		err := cmd.VerifyKeystore(&b, nil)
+		require.Error(t, err)
+		require.Equal(t, VerifyKeystoreError, err.Code())
+		require.Contains(t, err.Error(), "error verify keystore")
+	})
+
+	t.Run("test verify keystore - not supported by the kms", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: struct{ kmsapi.KeyManager }{&mockkms.KeyManager{}},
+		})
+		require.NotNil(t, cmd)
+
+		var b bytes.Buffer
+		err := cmd.VerifyKeystore(&b, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not support keystore verification")
+	})
+}
+
+func TestGetStats(t *testing.T) {
+	t.Run("test get stats - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{StatsValue: &localkms.KMSStats{
+				TotalKeys:    3,
+				KeyTypes:     map[kmsapi.KeyType]int{kmsapi.ED25519Type: 2, kmsapi.AES256GCMType: 1},
+				FailedKeyIDs: []string{"corrupted"},
+			}},
+		})
+		require.NotNil(t, cmd)
+
+		var getRW bytes.Buffer
+		cmdErr := cmd.GetStats(&getRW, nil)
+		require.NoError(t, cmdErr)
+
+		response := GetStatsResponse{}
+		err := json.NewDecoder(&getRW).Decode(&response)
+		require.NoError(t, err)
+
+		require.Equal(t, 3, response.TotalKeys)
+		require.Equal(t, map[kmsapi.KeyType]int{kmsapi.ED25519Type: 2, kmsapi.AES256GCMType: 1}, response.KeyTypes)
+		require.Equal(t, []string{"corrupted"}, response.FailedKeyIDs)
+	})
+
+	t.Run("test get stats - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{StatsErr: fmt.Errorf("error get stats")},
+		})
+		require.NotNil(t, cmd)
+
+		var b bytes.Buffer
+		err := cmd.GetStats(&b, nil)
+		require.Error(t, err)
+		require.Equal(t, GetStatsError, err.Code())
+		require.Contains(t, err.Error(), "error get stats")
+	})
+
+	t.Run("test get stats - not supported by the kms", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: struct{ kmsapi.KeyManager }{&mockkms.KeyManager{}},
+		})
+		require.NotNil(t, cmd)
+
+		var b bytes.Buffer
+		err := cmd.GetStats(&b, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not support statistics")
+	})
+}
+
+func TestRotateKeys(t *testing.T) {
+	newLocalKMS := func(t *testing.T) *localkms.LocalKMS {
+		k, err := localkms.New("local-lock://custom/master/key/",
+			mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+		require.NoError(t, err)
+
+		return k
+	}
+
+	rotateKeys := func(t *testing.T, cmd *Command, request string) *RotateKeysResponse {
+		var getRW bytes.Buffer
+		cmdErr := cmd.RotateKeys(&getRW, bytes.NewBufferString(request))
+		require.NoError(t, cmdErr)
+
+		response := &RotateKeysResponse{}
+		err := json.NewDecoder(&getRW).Decode(response)
+		require.NoError(t, err)
+
+		return response
+	}
+
+	t.Run("test rotate keys - all keys of the type are rotated", func(t *testing.T) {
+		k := newLocalKMS(t)
+
+		seeded := map[string]bool{}
+
+		for i := 0; i < 10; i++ {
+			keyID, _, err := k.Create(kmsapi.ED25519Type)
+			require.NoError(t, err)
+
+			seeded[keyID] = true
+		}
+
+		otherID, _, err := k.Create(kmsapi.AES256GCMType)
+		require.NoError(t, err)
+
+		response := rotateKeys(t, New(&mockprovider.Provider{CustomKMS: k}), `{"keyType":"ED25519"}`)
+		require.Empty(t, response.Failed)
+		require.Len(t, response.Rotated, len(seeded))
+
+		var newIDs []string
+
+		for _, rotated := range response.Rotated {
+			require.True(t, seeded[rotated.OldKeyID])
+			delete(seeded, rotated.OldKeyID)
+
+			_, err = k.Get(rotated.OldKeyID)
+			require.Error(t, err)
+
+			newIDs = append(newIDs, rotated.NewKeyID)
+		}
+
+		require.Empty(t, seeded)
+
+		keyIDs, err := k.ListByKeyType(kmsapi.ED25519Type)
+		require.NoError(t, err)
+		require.ElementsMatch(t, newIDs, keyIDs)
+
+		_, err = k.Get(otherID)
+		require.NoError(t, err)
+	})
+
+	t.Run("test rotate keys - retain previous keys", func(t *testing.T) {
+		k := newLocalKMS(t)
+
+		keyID, _, err := k.Create(kmsapi.ED25519Type)
+		require.NoError(t, err)
+
+		response := rotateKeys(t, New(&mockprovider.Provider{CustomKMS: k}),
+			`{"keyType":"ED25519","retainPrevious":true}`)
+		require.Empty(t, response.Failed)
+		require.Len(t, response.Rotated, 1)
+		require.Equal(t, keyID, response.Rotated[0].OldKeyID)
+
+		_, err = k.Get(keyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("test rotate keys - continues past failures", func(t *testing.T) {
+		response := rotateKeys(t, New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{
+			ListValue:    []string{"key1", "key2"},
+			RotateKeyErr: fmt.Errorf("error rotate key"),
+		}}), `{"keyType":"ED25519"}`)
+		require.Empty(t, response.Rotated)
+		require.ElementsMatch(t, []RotateKeyFailure{
+			{KeyID: "key1", Error: "error rotate key"},
+			{KeyID: "key2", Error: "error rotate key"},
+		}, response.Failed)
+	})
+
+	t.Run("test rotate keys - invalid request", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		var getRW bytes.Buffer
+		cmdErr := cmd.RotateKeys(&getRW, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Equal(t, command.ValidationError, cmdErr.Type())
+
+		cmdErr = cmd.RotateKeys(&getRW, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "key type is mandatory")
+	})
+
+	t.Run("test rotate keys - list error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ListErr: fmt.Errorf("error list keys")}})
+
+		var getRW bytes.Buffer
+		cmdErr := cmd.RotateKeys(&getRW, bytes.NewBufferString(`{"keyType":"ED25519"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, RotateKeysError, cmdErr.Code())
+		require.Equal(t, command.ExecuteError, cmdErr.Type())
+
+		cmd = New(&mockprovider.Provider{CustomKMS: struct{ kmsapi.KeyManager }{&mockkms.KeyManager{}}})
+
+		cmdErr = cmd.RotateKeys(&getRW, bytes.NewBufferString(`{"keyType":"ED25519"}`))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), "kms does not support listing keys by key type")
+	})
+
+	t.Run("test rotate keys - retain previous keys not supported by the kms", func(t *testing.T) {
+		km := &mockkms.KeyManager{ListValue: []string{"key1"}}
+		cmd := New(&mockprovider.Provider{CustomKMS: struct {
+			kmsapi.KeyManager
+			keyLister
+		}{km, km}})
+
+		var getRW bytes.Buffer
+		cmdErr := cmd.RotateKeys(&getRW, bytes.NewBufferString(`{"keyType":"ED25519","retainPrevious":true}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, RotateKeysError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "kms does not support retaining the previous keys")
+
+		// the keys are rotated without the key options
+		response := rotateKeys(t, cmd, `{"keyType":"ED25519"}`)
+		require.Len(t, response.Rotated, 1)
+	})
+}
+
+func TestArchive(t *testing.T) {
+	newLocalKMS := func(t *testing.T) *localkms.LocalKMS {
+		k, err := localkms.New("local-lock://custom/master/key/",
+			mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+		require.NoError(t, err)
+
+		return k
+	}
+
+	t.Run("test export and import archive - success", func(t *testing.T) {
+		source := newLocalKMS(t)
+
+		keyID, _, err := source.Create(kmsapi.ED25519Type)
+		require.NoError(t, err)
+
+		var archive bytes.Buffer
+		cmdErr := New(&mockprovider.Provider{CustomKMS: source}).ExportArchive(&archive, nil)
+		require.NoError(t, cmdErr)
+
+		target := newLocalKMS(t)
+
+		var getRW bytes.Buffer
+		cmdErr = New(&mockprovider.Provider{CustomKMS: target}).ImportArchive(&getRW, &archive)
+		require.NoError(t, cmdErr)
+
+		response := ImportArchiveResponse{}
+		err = json.NewDecoder(&getRW).Decode(&response)
+		require.NoError(t, err)
+		require.Equal(t, 1, response.Imported)
+
+		_, err = target.Get(keyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("test import archive - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: newLocalKMS(t)})
+
+		var getRW bytes.Buffer
+		cmdErr := cmd.ImportArchive(&getRW, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Equal(t, command.ValidationError, cmdErr.Type())
+
+		cmdErr = cmd.ImportArchive(&getRW, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, ImportArchiveError, cmdErr.Code())
+		require.Equal(t, command.ExecuteError, cmdErr.Type())
+	})
+
+	t.Run("test archive - kms does not support archives", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		var getRW bytes.Buffer
+		cmdErr := cmd.ExportArchive(&getRW, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, ExportArchiveError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "kms does not support archives")
+
+		cmdErr = cmd.ImportArchive(&getRW, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Equal(t, ImportArchiveError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "kms does not support archives")
+	})
+}
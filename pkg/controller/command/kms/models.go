@@ -6,6 +6,11 @@ SPDX-License-Identifier: Apache-2.0
 
 package kms
 
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+)
+
 // CreateKeySetResponse for returning key pair
 type CreateKeySetResponse struct {
 	//  encryption public key base58 encoded
@@ -13,3 +18,48 @@ type CreateKeySetResponse struct {
 	//  signature public key base58 encoded
 	SignaturePublicKey string `json:"signaturePublicKey,omitempty"`
 }
+
+// VerifyKeystoreResponse for returning the keystore verification result
+type VerifyKeystoreResponse struct {
+	// IDs of the keysets which failed verification (could not be read, decrypted or used)
+	FailedKeyIDs []string `json:"failedKeyIDs"`
+}
+
+// GetStatsResponse for returning the statistics of the keys stored by the KMS
+type GetStatsResponse struct {
+	*localkms.KMSStats
+}
+
+// RotateKeysRequest for rotating all the keys of a key type
+type RotateKeysRequest struct {
+	// type of the keys to rotate (eg: ED25519)
+	KeyType kms.KeyType `json:"keyType"`
+	// keep the keysets of the rotated keys under their previous IDs
+	RetainPrevious bool `json:"retainPrevious,omitempty"`
+}
+
+// RotateKeysResponse for returning the report of a keys rotation
+type RotateKeysResponse struct {
+	// keys successfully rotated
+	Rotated []RotatedKey `json:"rotated"`
+	// keys which failed to be rotated
+	Failed []RotateKeyFailure `json:"failed"`
+}
+
+// RotatedKey is the previous and new ID of a rotated key
+type RotatedKey struct {
+	OldKeyID string `json:"oldKeyID"`
+	NewKeyID string `json:"newKeyID"`
+}
+
+// RotateKeyFailure is a key which failed to be rotated
+type RotateKeyFailure struct {
+	KeyID string `json:"keyID"`
+	Error string `json:"error"`
+}
+
+// ImportArchiveResponse for returning the result of a keystore archive import
+type ImportArchiveResponse struct {
+	// number of imported keys
+	Imported int `json:"imported"`
+}
@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -39,6 +40,12 @@ const (
 
 	// GetDIDErrorCode for get did error
 	GetDIDErrorCode
+
+	// GetDIDRecordsErrorCode for get did records error
+	GetDIDRecordsErrorCode
+
+	// ValidateDIDErrorCode is for failures while validating the creation of public DIDs
+	ValidateDIDErrorCode
 )
 
 const (
@@ -50,14 +57,20 @@ const (
 	saveDIDCommandMethod         = "SaveDID"
 	getDIDsCommandMethod         = "GetDIDRecords"
 	getDIDCommandMethod          = "GetDID"
+	validateDIDCommandMethod     = "ValidateDID"
 
 	// error messages
 	errDIDMethodMandatory = "invalid method name"
 	errEmptyDIDName       = "name is mandatory"
 	errEmptyDIDID         = "did is mandatory"
+	errInvalidPage        = "limit and offset must not be negative"
+	errNoDIDValidation    = "the vdri registry does not support validation"
 
 	// log constants
 	didID = "did"
+
+	// maxInlineRecords is the page size of the did records returned with their documents inline
+	maxInlineRecords = 100
 )
 
 // provider contains dependencies for the vdri controller command operations
@@ -67,15 +80,22 @@ type provider interface {
 	StorageProvider() storage.Provider
 }
 
+// didValidator is implemented by the vdri registries able to validate the creation of a DID without creating it
+// (see vdri.Registry.Validate).
+type didValidator interface {
+	Validate(didMethod string, opts ...vdriapi.DocOpts) (*did.Doc, []error)
+}
+
 // Command contains command operations provided by vdri controller
 type Command struct {
 	ctx      provider
 	didStore *didstore.Store
 }
 
-// New returns new vdri controller command instance
-func New(ctx provider) (*Command, error) {
-	didStore, err := didstore.New(ctx)
+// New returns new vdri controller command instance. The DID store options allow, for instance, to store
+// the DID documents in a custom format (see didstore.WithSerializer).
+func New(ctx provider, opts ...didstore.Option) (*Command, error) {
+	didStore, err := didstore.New(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("new did store : %w", err)
 	}
@@ -93,6 +113,7 @@ func (o *Command) GetHandlers() []command.Handler {
 		cmdutil.NewCommandHandler(commandName, saveDIDCommandMethod, o.SaveDID),
 		cmdutil.NewCommandHandler(commandName, getDIDCommandMethod, o.GetDID),
 		cmdutil.NewCommandHandler(commandName, getDIDsCommandMethod, o.GetDIDRecords),
+		cmdutil.NewCommandHandler(commandName, validateDIDCommandMethod, o.ValidateDID),
 	}
 }
 
@@ -129,6 +150,46 @@ func (o *Command) CreatePublicDID(rw io.Writer, req io.Reader) command.Error {
 	return nil
 }
 
+// ValidateDID runs the validation CreatePublicDID would run (key material, service blocks, DID method constraints)
+// without creating the public DID: no key is created and nothing is published. It returns the would-be DID document
+// and the validation errors.
+func (o *Command) ValidateDID(rw io.Writer, req io.Reader) command.Error {
+	var request CreatePublicDIDArgs
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, validateDIDCommandMethod, err.Error())
+		return command.NewValidationError(InvalidRequestErrorCode, err)
+	}
+
+	if request.Method == "" {
+		logutil.LogDebug(logger, commandName, validateDIDCommandMethod, errDIDMethodMandatory)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errDIDMethodMandatory))
+	}
+
+	validator, ok := o.ctx.VDRIRegistry().(didValidator)
+	if !ok {
+		logutil.LogError(logger, commandName, validateDIDCommandMethod, errNoDIDValidation)
+		return command.NewExecuteError(ValidateDIDErrorCode, fmt.Errorf(errNoDIDValidation))
+	}
+
+	doc, errs := validator.Validate(strings.ToLower(request.Method),
+		vdriapi.WithRequestBuilder(getBasicRequestBuilder(request.RequestHeader)))
+
+	response := &ValidateDIDResponse{DID: doc, Valid: len(errs) == 0}
+
+	for _, e := range errs {
+		response.Errors = append(response.Errors, e.Error())
+	}
+
+	command.WriteNillableResponse(rw, response, logger)
+
+	logutil.LogDebug(logger, commandName, validateDIDCommandMethod, "success",
+		logutil.CreateKeyValueString("method", request.Method))
+
+	return nil
+}
+
 // SaveDID saves the did doc to the store
 func (o *Command) SaveDID(rw io.Writer, req io.Reader) command.Error {
 	request := &DIDArgs{}
@@ -207,12 +268,45 @@ func (o *Command) GetDID(rw io.Writer, req io.Reader) command.Error {
 	return nil
 }
 
-// GetDIDRecords retrieves the did doc containing name and didID. //TODO Add pagination feature #1566
+// GetDIDRecords retrieves the did doc records containing name and didID. The records can be paged with the limit
+// and offset args, and returned with their did documents inline. Inline results are paged by maxInlineRecords
+// records at most.
 func (o *Command) GetDIDRecords(rw io.Writer, req io.Reader) command.Error {
-	didRecords := o.didStore.GetDIDRecords()
+	request, err := getDIDRecordsArgs(req)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, getDIDsCommandMethod, err.Error())
+		return command.NewValidationError(InvalidRequestErrorCode, err)
+	}
+
+	limit := request.Limit
+	if request.Inline && (limit == 0 || limit > maxInlineRecords) {
+		limit = maxInlineRecords
+	}
+
+	didRecords := pageDIDRecords(o.didStore.GetDIDRecords(), request.Offset, limit)
+
+	result := make([]*DIDRecord, len(didRecords))
+
+	for i, record := range didRecords {
+		result[i] = &DIDRecord{Record: record}
+
+		if !request.Inline {
+			continue
+		}
+
+		docBytes, err := o.didDocumentBytes(record.ID)
+		if err != nil {
+			logutil.LogError(logger, commandName, getDIDsCommandMethod, err.Error(),
+				logutil.CreateKeyValueString(didID, record.ID))
+
+			return command.NewExecuteError(GetDIDRecordsErrorCode, err)
+		}
+
+		result[i].DID = docBytes
+	}
 
 	command.WriteNillableResponse(rw, &DIDRecordResult{
-		Result: didRecords,
+		Result: result,
 	}, logger)
 
 	logutil.LogDebug(logger, commandName, getDIDsCommandMethod, "success")
@@ -220,6 +314,54 @@ func (o *Command) GetDIDRecords(rw io.Writer, req io.Reader) command.Error {
 	return nil
 }
 
+// getDIDRecordsArgs decodes the get did records request, an empty request returns all the records.
+func getDIDRecordsArgs(req io.Reader) (*GetDIDRecordsArgs, error) {
+	request := &GetDIDRecordsArgs{}
+
+	if req != nil {
+		err := json.NewDecoder(req).Decode(request)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("request decode : %w", err)
+		}
+	}
+
+	if request.Limit < 0 || request.Offset < 0 {
+		return nil, fmt.Errorf(errInvalidPage)
+	}
+
+	return request, nil
+}
+
+// didDocumentBytes returns the stored did document of the did id.
+func (o *Command) didDocumentBytes(id string) (json.RawMessage, error) {
+	didDoc, err := o.didStore.GetDID(id)
+	if err != nil {
+		return nil, fmt.Errorf("get did doc: %w", err)
+	}
+
+	docBytes, err := didDoc.JSONBytes()
+	if err != nil {
+		return nil, fmt.Errorf("marshal did doc: %w", err)
+	}
+
+	return docBytes, nil
+}
+
+// pageDIDRecords returns the page of records starting at offset, with limit records at most (0 for no limit).
+func pageDIDRecords(records []*didstore.Record, offset, limit int) []*didstore.Record {
+	if offset >= len(records) {
+		return nil
+	}
+
+	records = records[offset:]
+
+	if limit > 0 && limit < len(records) {
+		records = records[:limit]
+	}
+
+	return records
+}
+
 // prepareBasicRequestBuilder is basic request builder for public DID creation
 // request body format is : {"header": {raw header}, "payload": "payload"}
 func getBasicRequestBuilder(header string) func(payload []byte) (io.Reader, error) {
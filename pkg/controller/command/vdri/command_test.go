@@ -10,15 +10,26 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
 	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/httpbinding"
 )
 
 const sampleDIDName = "sampleDIDName"
@@ -151,6 +162,101 @@ func TestBuildSideTreeRequest(t *testing.T) {
 	require.Nil(t, r)
 }
 
+func TestValidateDID(t *testing.T) {
+	var published int32
+
+	ledger := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&published, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ledger.Close()
+
+	ledgerVDRI, err := httpbinding.New(ledger.URL, httpbinding.WithAccept(func(method string) bool {
+		return method == "sidetree"
+	}))
+	require.NoError(t, err)
+
+	newCommand := func(t *testing.T, opts ...vdri.Option) *Command {
+		cmd, err := New(&mockprovider.Provider{
+			StorageProviderValue: mockstore.NewMockStoreProvider(),
+			VDRIRegistryValue: vdri.New(&mockprovider.Provider{}, append([]vdri.Option{vdri.WithVDRI(ledgerVDRI),
+				vdri.WithDefaultServiceType(vdriapi.DIDCommServiceType)}, opts...)...),
+		})
+		require.NoError(t, err)
+
+		return cmd
+	}
+
+	validate := func(t *testing.T, cmd *Command, req string) *ValidateDIDResponse {
+		var b bytes.Buffer
+		require.NoError(t, cmd.ValidateDID(&b, bytes.NewBufferString(req)))
+
+		response := &ValidateDIDResponse{}
+		require.NoError(t, json.NewDecoder(&b).Decode(response))
+
+		return response
+	}
+
+	t.Run("test valid public DID", func(t *testing.T) {
+		cmd := newCommand(t, vdri.WithDefaultServiceEndpoint("https://agent.example.com"))
+
+		response := validate(t, cmd, `{"method":"sidetree", "header":"{}"}`)
+		require.True(t, response.Valid)
+		require.Empty(t, response.Errors)
+		require.NotNil(t, response.DID)
+		require.NotEmpty(t, response.DID.PublicKey)
+		require.NotEmpty(t, response.DID.Service)
+	})
+
+	t.Run("test invalid public DIDs", func(t *testing.T) {
+		cmd := newCommand(t, vdri.WithDefaultServiceEndpoint("https://agent.example.com"))
+
+		response := validate(t, cmd, `{"method":"sidetree", "header":"{"}`)
+		require.False(t, response.Valid)
+		require.Nil(t, response.DID)
+		require.Len(t, response.Errors, 1)
+		require.Contains(t, response.Errors[0], "failed to build request")
+
+		response = validate(t, cmd, `{"method":"unknown"}`)
+		require.False(t, response.Valid)
+		require.Equal(t, []string{"did method unknown not supported for vdri"}, response.Errors)
+
+		// no default service endpoint
+		response = validate(t, newCommand(t), `{"method":"sidetree", "header":"{}"}`)
+		require.False(t, response.Valid)
+		require.NotNil(t, response.DID)
+		require.Equal(t, []string{"service #endpoint-1: endpoint is empty"}, response.Errors)
+	})
+
+	t.Run("test validate DID errors", func(t *testing.T) {
+		cmd := newCommand(t)
+
+		var b bytes.Buffer
+
+		cmdErr := cmd.ValidateDID(&b, bytes.NewBufferString(`"""`))
+		require.Error(t, cmdErr)
+		require.Equal(t, command.ValidationError, cmdErr.Type())
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.ValidateDID(&b, bytes.NewBufferString(`{}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errDIDMethodMandatory)
+
+		cmd, err = New(&protocol.MockProvider{})
+		require.NoError(t, err)
+
+		cmdErr = cmd.ValidateDID(&b, bytes.NewBufferString(`{"method":"sidetree"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, command.ExecuteError, cmdErr.Type())
+		require.Equal(t, ValidateDIDErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errNoDIDValidation)
+	})
+
+	// nothing is published
+	require.Zero(t, atomic.LoadInt32(&published))
+}
+
 func TestNew(t *testing.T) {
 	t.Run("test new command - success", func(t *testing.T) {
 		cmd, err := New(&mockprovider.Provider{
@@ -160,7 +266,7 @@ func TestNew(t *testing.T) {
 		require.NoError(t, err)
 
 		handlers := cmd.GetHandlers()
-		require.Equal(t, 4, len(handlers))
+		require.Equal(t, 5, len(handlers))
 	})
 
 	t.Run("test new command - did store error", func(t *testing.T) {
@@ -381,5 +487,159 @@ func TestGetDIDRecords(t *testing.T) {
 		// verify response
 		require.NotEmpty(t, response)
 		require.Equal(t, 1, len(response.Result))
+		require.Empty(t, response.Result[0].DID)
 	})
+
+	t.Run("test get did records inline", func(t *testing.T) {
+		cmd, err := New(&mockprovider.Provider{
+			StorageProviderValue: mockstore.NewMockStoreProvider(),
+		})
+		require.NoError(t, err)
+
+		didReqBytes, err := json.Marshal(DIDArgs{
+			Document: Document{DID: json.RawMessage(doc)},
+			Name:     sampleDIDName,
+		})
+		require.NoError(t, err)
+
+		var b bytes.Buffer
+		require.NoError(t, cmd.SaveDID(&b, bytes.NewBuffer(didReqBytes)))
+
+		var getRW bytes.Buffer
+		cmdErr := cmd.GetDIDRecords(&getRW, bytes.NewBufferString(`{"inline":true}`))
+		require.NoError(t, cmdErr)
+
+		var response DIDRecordResult
+		require.NoError(t, json.NewDecoder(&getRW).Decode(&response))
+		require.Equal(t, 1, len(response.Result))
+		require.Equal(t, sampleDIDName, response.Result[0].Name)
+
+		didDoc, err := did.ParseDocument(response.Result[0].DID)
+		require.NoError(t, err)
+		require.Equal(t, response.Result[0].ID, didDoc.ID)
+
+		getRW.Reset()
+		cmdErr = cmd.GetDIDRecords(&getRW, bytes.NewBufferString(`{"inline":false}`))
+		require.NoError(t, cmdErr)
+
+		response = DIDRecordResult{}
+		require.NoError(t, json.NewDecoder(&getRW).Decode(&response))
+		require.Equal(t, 1, len(response.Result))
+		require.Empty(t, response.Result[0].DID)
+	})
+
+	t.Run("test get did records pages", func(t *testing.T) {
+		cmd, err := New(&mockprovider.Provider{
+			StorageProviderValue: mockstore.NewMockStoreProvider(),
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			didDoc, err := did.ParseDocument([]byte(doc))
+			require.NoError(t, err)
+
+			didDoc.ID = fmt.Sprintf("did:peer:%d", i)
+
+			didBytes, err := didDoc.JSONBytes()
+			require.NoError(t, err)
+
+			didReqBytes, err := json.Marshal(DIDArgs{
+				Document: Document{DID: didBytes},
+				Name:     fmt.Sprintf("%s%d", sampleDIDName, i),
+			})
+			require.NoError(t, err)
+
+			var b bytes.Buffer
+			require.NoError(t, cmd.SaveDID(&b, bytes.NewBuffer(didReqBytes)))
+		}
+
+		for args, expected := range map[string]int{
+			`{}`:                        3,
+			`{"limit":2}`:               2,
+			`{"offset":2}`:              1,
+			`{"offset":3}`:              0,
+			`{"limit":2,"offset":2}`:    1,
+			`{"inline":true,"limit":1}`: 1,
+		} {
+			var getRW bytes.Buffer
+			cmdErr := cmd.GetDIDRecords(&getRW, bytes.NewBufferString(args))
+			require.NoError(t, cmdErr)
+
+			var response DIDRecordResult
+			require.NoError(t, json.NewDecoder(&getRW).Decode(&response))
+			require.Equal(t, expected, len(response.Result), args)
+		}
+	})
+
+	t.Run("test get did records errors", func(t *testing.T) {
+		store := mockstore.NewMockStoreProvider()
+
+		cmd, err := New(&mockprovider.Provider{
+			StorageProviderValue: store,
+		})
+		require.NoError(t, err)
+
+		var getRW bytes.Buffer
+		cmdErr := cmd.GetDIDRecords(&getRW, bytes.NewBufferString("--"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.GetDIDRecords(&getRW, bytes.NewBufferString(`{"limit":-1}`))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errInvalidPage)
+
+		// a record without its did document
+		store.Store.Store["didname_"+sampleDIDName] = []byte("did:peer:unknown")
+
+		cmdErr = cmd.GetDIDRecords(&getRW, bytes.NewBufferString(`{"inline":true}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, GetDIDRecordsErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "get did doc")
+	})
+}
+
+func TestSaveDIDSkeleton(t *testing.T) {
+	k, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProvider(mockstore.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	edKeyID, _, err := k.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	p256KeyID, _, err := k.Create(kms.ECDSAP256Type)
+	require.NoError(t, err)
+
+	skeleton, err := k.DIDDocSkeleton(edKeyID, p256KeyID)
+	require.NoError(t, err)
+
+	skeletonBytes, err := skeleton.JSONBytes()
+	require.NoError(t, err)
+
+	cmd, err := New(&mockprovider.Provider{
+		StorageProviderValue: mockstore.NewMockStoreProvider(),
+	})
+	require.NoError(t, err)
+
+	didReqBytes, err := json.Marshal(DIDArgs{
+		Document: Document{DID: skeletonBytes},
+		Name:     sampleDIDName,
+	})
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	cmdErr := cmd.SaveDID(&b, bytes.NewBuffer(didReqBytes))
+	require.NoError(t, cmdErr)
+
+	var getRW bytes.Buffer
+	cmdErr = cmd.GetDID(&getRW, bytes.NewBufferString(fmt.Sprintf(`{"id":"%s"}`, localkms.DIDSkeletonID)))
+	require.NoError(t, cmdErr)
+
+	response := Document{}
+	err = json.NewDecoder(&getRW).Decode(&response)
+	require.NoError(t, err)
+
+	saved, err := did.ParseDocument(response.DID)
+	require.NoError(t, err)
+	require.Equal(t, skeleton.PublicKey, saved.PublicKey)
+	require.Equal(t, skeleton.Authentication, saved.Authentication)
 }
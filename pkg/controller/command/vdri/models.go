@@ -28,6 +28,18 @@ type CreatePublicDIDResponse struct {
 	DID *did.Doc `json:"did"`
 }
 
+// ValidateDIDResponse for returning the validation of the creation of a public DID
+type ValidateDIDResponse struct {
+	// DID is the DID document the creation would build, nil if it could not be built
+	DID *did.Doc `json:"did,omitempty"`
+
+	// Valid is set if the public DID can be created
+	Valid bool `json:"valid"`
+
+	// Errors are the validation errors
+	Errors []string `json:"errors,omitempty"`
+}
+
 // Document is model for did document.
 type Document struct {
 	DID json.RawMessage `json:"did,omitempty"`
@@ -48,10 +60,30 @@ type IDArg struct {
 	ID string `json:"id"`
 }
 
+// GetDIDRecordsArgs model
+//
+// This is used for querying the did records.
+type GetDIDRecordsArgs struct {
+	// Inline includes the did document in each record
+	Inline bool `json:"inline,omitempty"`
+
+	// Limit is the maximum number of records to return, 0 for no limit
+	Limit int `json:"limit,omitempty"`
+
+	// Offset is the number of records to skip
+	Offset int `json:"offset,omitempty"`
+}
+
+// DIDRecord is the did doc record, with the did document when requested inline.
+type DIDRecord struct {
+	*storeDID.Record
+	DID json.RawMessage `json:"did,omitempty"`
+}
+
 // DIDRecordResult holds the did doc records.
 type DIDRecordResult struct {
 	// Result
-	Result []*storeDID.Record `json:"result,omitempty"`
+	Result []*DIDRecord `json:"result,omitempty"`
 }
 
 // NameArg model
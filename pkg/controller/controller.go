@@ -11,6 +11,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
 	didexchangecmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/didexchange"
+	healthcmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/health"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/kms"
 	messagingcmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/messaging"
 	routercmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/route"
@@ -18,6 +19,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
 	didexchangerest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/didexchange"
+	healthrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/health"
 	kmsrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/kms"
 	messagingrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/messaging"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest/route"
@@ -122,6 +124,9 @@ func GetRESTHandlers(ctx *context.Provider, opts ...Opt) ([]rest.Handler, error)
 	// kms command operation
 	kmscmd := kmsrest.New(ctx)
 
+	// health command operation
+	healthOp := healthrest.New(ctx)
+
 	// creat handlers from all operations
 	var allHandlers []rest.Handler
 	allHandlers = append(allHandlers, exchangeOp.GetRESTHandlers()...)
@@ -130,6 +135,7 @@ func GetRESTHandlers(ctx *context.Provider, opts ...Opt) ([]rest.Handler, error)
 	allHandlers = append(allHandlers, routeOp.GetRESTHandlers()...)
 	allHandlers = append(allHandlers, verifiablecmd.GetRESTHandlers()...)
 	allHandlers = append(allHandlers, kmscmd.GetRESTHandlers()...)
+	allHandlers = append(allHandlers, healthOp.GetRESTHandlers()...)
 
 	nhp, ok := notifier.(handlerProvider)
 	if ok {
@@ -190,6 +196,9 @@ func GetCommandHandlers(ctx *context.Provider, opts ...Opt) ([]command.Handler,
 	// kms command operation
 	kmscmd := kms.New(ctx)
 
+	// health command operation
+	healthCmd := healthcmd.New(ctx)
+
 	var allHandlers []command.Handler
 	allHandlers = append(allHandlers, didexcmd.GetHandlers()...)
 	allHandlers = append(allHandlers, vcmd.GetHandlers()...)
@@ -197,6 +206,7 @@ func GetCommandHandlers(ctx *context.Provider, opts ...Opt) ([]command.Handler,
 	allHandlers = append(allHandlers, routecmd.GetHandlers()...)
 	allHandlers = append(allHandlers, verifiablecmd.GetHandlers()...)
 	allHandlers = append(allHandlers, kmscmd.GetHandlers()...)
+	allHandlers = append(allHandlers, healthCmd.GetHandlers()...)
 
 	return allHandlers, nil
 }
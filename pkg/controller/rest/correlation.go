@@ -0,0 +1,53 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rest
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// CorrelationIDHeader is the header carrying the correlation ID of the REST requests and responses.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// correlationIDPattern is the pattern of the correlation IDs accepted from the clients: up to 128 letters, digits,
+// dots, underscores and dashes, so they can't inject anything into the logs or the response headers.
+var correlationIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`) //nolint:gochecknoglobals
+
+type correlationIDKey struct{}
+
+// CorrelationIDMiddleware sets the correlation ID of every request in the response header CorrelationIDHeader.
+// The correlation ID is echoed from the request header CorrelationIDHeader or generated if the request has none or
+// an invalid one (more than 128 characters, or characters other than letters, digits, '.', '_' and '-'). It is
+// available to the handlers from the request context (see CorrelationID) and logged with the command errors
+// (see Execute).
+func CorrelationIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		correlationID := req.Header.Get(CorrelationIDHeader)
+		if !correlationIDPattern.MatchString(correlationID) {
+			correlationID = uuid.New().String()
+		}
+
+		rw.Header().Set(CorrelationIDHeader, correlationID)
+
+		next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), correlationIDKey{}, correlationID)))
+	})
+}
+
+// CorrelationID returns the correlation ID set by CorrelationIDMiddleware in the request context ctx,
+// or an empty string if there is none.
+func CorrelationID(ctx context.Context) string {
+	correlationID, ok := ctx.Value(correlationIDKey{}).(string)
+	if !ok {
+		return ""
+	}
+
+	return correlationID
+}
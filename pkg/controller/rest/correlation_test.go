@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+)
+
+func TestCorrelationIDMiddleware(t *testing.T) {
+	var handlerCorrelationID string
+
+	handler := CorrelationIDMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		handlerCorrelationID = CorrelationID(req.Context())
+
+		Execute(func(rw io.Writer, req io.Reader) command.Error {
+			return command.NewValidationError(sampleErr1, fmt.Errorf("sample error"))
+		}, rw, req.Body)
+	}))
+
+	t.Run("test correlation ID is echoed from the request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/sample", nil)
+		req.Header.Set(CorrelationIDHeader, "sample-correlation-id")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, "sample-correlation-id", rr.Header().Get(CorrelationIDHeader))
+		require.Equal(t, "sample-correlation-id", handlerCorrelationID)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("test correlation ID is generated", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sample", nil))
+
+		correlationID := rr.Header().Get(CorrelationIDHeader)
+		require.NotEmpty(t, correlationID)
+		require.Equal(t, correlationID, handlerCorrelationID)
+
+		// every request has its own correlation ID
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sample", nil))
+		require.NotEmpty(t, rr.Header().Get(CorrelationIDHeader))
+		require.NotEqual(t, correlationID, rr.Header().Get(CorrelationIDHeader))
+	})
+
+	t.Run("test invalid correlation ID is replaced", func(t *testing.T) {
+		for _, invalid := range []string{
+			"sample correlation id",
+			"sample-correlation-id\r\nX-Injected: true",
+			"sample/correlation/id",
+			strings.Repeat("a", 129),
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/sample", nil)
+			req.Header[CorrelationIDHeader] = []string{invalid}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			correlationID := rr.Header().Get(CorrelationIDHeader)
+			require.NotEmpty(t, correlationID)
+			require.NotEqual(t, invalid, correlationID)
+			require.Equal(t, correlationID, handlerCorrelationID)
+		}
+
+		// the longest valid correlation ID is echoed
+		req := httptest.NewRequest(http.MethodGet, "/sample", nil)
+		req.Header.Set(CorrelationIDHeader, strings.Repeat("a", 128))
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, strings.Repeat("a", 128), rr.Header().Get(CorrelationIDHeader))
+	})
+
+	t.Run("test no correlation ID without the middleware", func(t *testing.T) {
+		require.Empty(t, CorrelationID(context.Background()))
+	})
+}
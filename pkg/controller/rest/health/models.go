@@ -0,0 +1,22 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command/health"
+)
+
+// healthCheckRes model
+//
+// This is used for returning the health of the agent subsystems
+//
+// swagger:response healthCheckRes
+type healthCheckRes struct {
+
+	// in: body
+	health.HealthCheckResponse
+}
@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"net/http"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command/health"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var logger = log.New("aries-framework/rest/health")
+
+const (
+	healthCheckPath = "/healthcheck"
+)
+
+// provider contains dependencies for the health command and is typically created by using aries.Context().
+type provider interface {
+	KMS() kms.KeyManager
+	StorageProvider() storage.Provider
+	OutboundTransports() []transport.OutboundTransport
+	InboundTransports() []transport.InboundTransport
+}
+
+// Operation contains basic common operations provided by controller REST API
+type Operation struct {
+	handlers []rest.Handler
+	command  *health.Command
+}
+
+// New returns new health operations rest client instance
+func New(p provider) *Operation {
+	cmd := health.New(p)
+
+	o := &Operation{command: cmd}
+	o.registerHandler()
+
+	return o
+}
+
+// GetRESTHandlers get all controller API handler available for this service
+func (o *Operation) GetRESTHandlers() []rest.Handler {
+	return o.handlers
+}
+
+// registerHandler register handlers to be exposed from this protocol service as REST API endpoints
+func (o *Operation) registerHandler() {
+	o.handlers = []rest.Handler{
+		cmdutil.NewHTTPHandler(healthCheckPath, http.MethodGet, o.HealthCheck),
+	}
+}
+
+// HealthCheck swagger:route GET /healthcheck health healthCheck
+//
+// Returns the health of the KMS, storage and transport subsystems, with the overall status (ok, degraded or failed).
+// The status code is 503 if any subsystem is failing, so load balancers and orchestrators can act on it.
+//
+// Responses:
+//    default: genericError
+//        200: healthCheckRes
+//        503: healthCheckRes
+func (o *Operation) HealthCheck(rw http.ResponseWriter, req *http.Request) {
+	response := o.command.Check()
+
+	status := http.StatusOK
+	if response.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+
+	command.WriteNillableResponse(rw, response, logger)
+}
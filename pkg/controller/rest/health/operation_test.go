@@ -0,0 +1,81 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command/health"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestNew(t *testing.T) {
+	op := New(&mockprovider.Provider{})
+	require.NotNil(t, op)
+	require.Equal(t, 1, len(op.GetRESTHandlers()))
+}
+
+func TestHealthCheck(t *testing.T) {
+	healthCheck := func(t *testing.T, p provider, status int) *healthCheckRes {
+		handler := New(p).GetRESTHandlers()[0]
+		require.Equal(t, healthCheckPath, handler.Path())
+		require.Equal(t, http.MethodGet, handler.Method())
+
+		router := mux.NewRouter()
+		router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, healthCheckPath, nil))
+		require.Equal(t, status, rr.Code)
+		require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+		response := &healthCheckRes{}
+		err := json.Unmarshal(rr.Body.Bytes(), response)
+		require.NoError(t, err)
+
+		return response
+	}
+
+	t.Run("test healthy agent", func(t *testing.T) {
+		response := healthCheck(t, &mockprovider.Provider{
+			CustomKMS:               &mockkms.KeyManager{},
+			StorageProviderValue:    mockstore.NewMockStoreProvider(),
+			OutboundTransportsValue: []transport.OutboundTransport{didcomm.NewMockOutboundTransport("")},
+		}, http.StatusOK)
+		require.Equal(t, health.StatusOK, response.Status)
+		require.Len(t, response.Subsystems, 3)
+	})
+
+	t.Run("test kms failure", func(t *testing.T) {
+		response := healthCheck(t, &mockprovider.Provider{
+			CustomKMS:               &mockkms.KeyManager{HealthCheckErr: errors.New("secret lock unavailable")},
+			StorageProviderValue:    mockstore.NewMockStoreProvider(),
+			OutboundTransportsValue: []transport.OutboundTransport{didcomm.NewMockOutboundTransport("")},
+		}, http.StatusServiceUnavailable)
+		require.Equal(t, health.StatusDegraded, response.Status)
+		require.Equal(t, health.SubsystemHealth{Name: "kms", Status: health.StatusFailed,
+			Error: "secret lock unavailable"}, response.Subsystems[0])
+	})
+	t.Run("test all subsystems failing", func(t *testing.T) {
+		response := healthCheck(t, &mockprovider.Provider{
+			CustomKMS:            &mockkms.KeyManager{HealthCheckErr: errors.New("kms error")},
+			StorageProviderValue: &mockstore.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")},
+		}, http.StatusServiceUnavailable)
+		require.Equal(t, health.StatusFailed, response.Status)
+	})
+}
@@ -20,3 +20,82 @@ type createKeySetRes struct {
 	// in: body
 	kms.CreateKeySetResponse
 }
+
+// verifyKeystoreRes model
+//
+// This is used for returning the keystore verification response
+//
+// swagger:response verifyKeystoreRes
+type verifyKeystoreRes struct {
+
+	// in: body
+	kms.VerifyKeystoreResponse
+}
+
+// getStatsRes model
+//
+// This is used for returning the statistics of the keys stored by the KMS
+//
+// swagger:response getStatsRes
+type getStatsRes struct {
+
+	// in: body
+	kms.GetStatsResponse
+}
+
+// rotateKeysReq model
+//
+// This is used for rotating all the keys of a key type
+//
+// swagger:parameters rotateKeys
+type rotateKeysReq struct { // nolint: unused,deadcode
+	// Params for rotating the keys
+	//
+	// in: body
+	Params kms.RotateKeysRequest
+}
+
+// rotateKeysRes model
+//
+// This is used for returning the report of a keys rotation
+//
+// swagger:response rotateKeysRes
+type rotateKeysRes struct {
+
+	// in: body
+	kms.RotateKeysResponse
+}
+
+// exportArchiveRes model
+//
+// This is used for returning the keystore archive, one JSON entry per line
+//
+// swagger:response exportArchiveRes
+type exportArchiveRes struct { // nolint: unused,deadcode
+
+	// in: body
+	Archive []byte
+}
+
+// importArchiveReq model
+//
+// This is used for importing a keystore archive
+//
+// swagger:parameters importArchive
+type importArchiveReq struct { // nolint: unused,deadcode
+	// Keystore archive exported by /kms/export
+	//
+	// in: body
+	Archive []byte
+}
+
+// importArchiveRes model
+//
+// This is used for returning the result of a keystore archive import
+//
+// swagger:response importArchiveRes
+type importArchiveRes struct {
+
+	// in: body
+	kms.ImportArchiveResponse
+}
@@ -12,17 +12,27 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 )
 
 const (
-	kmseOperationID  = "/kms"
-	createKeySetPath = kmseOperationID + "/keyset"
+	kmseOperationID    = "/kms"
+	createKeySetPath   = kmseOperationID + "/keyset"
+	verifyKeystorePath = kmseOperationID + "/verify"
+	getStatsPath       = kmseOperationID + "/stats"
+	rotateKeysPath     = kmseOperationID + "/rotate"
+	exportArchivePath  = kmseOperationID + "/export"
+	importArchivePath  = kmseOperationID + "/import"
+
+	// archiveContentType is the content type of the keystore archives, one JSON entry per line
+	archiveContentType = "application/x-ndjson"
 )
 
 // provider contains dependencies for the kms command and is typically created by using aries.Context().
 type provider interface {
 	LegacyKMS() legacykms.KeyManager
+	KMS() kmsapi.KeyManager
 }
 
 // Operation contains basic common operations provided by controller REST API
@@ -50,6 +60,11 @@ func (o *Operation) GetRESTHandlers() []rest.Handler {
 func (o *Operation) registerHandler() {
 	o.handlers = []rest.Handler{
 		cmdutil.NewHTTPHandler(createKeySetPath, http.MethodPost, o.CreateKeySet),
+		cmdutil.NewHTTPHandler(verifyKeystorePath, http.MethodGet, o.VerifyKeystore),
+		cmdutil.NewHTTPHandler(getStatsPath, http.MethodGet, o.GetStats),
+		cmdutil.NewHTTPHandler(rotateKeysPath, http.MethodPost, o.RotateKeys),
+		cmdutil.NewHTTPHandler(exportArchivePath, http.MethodPost, o.ExportArchive),
+		cmdutil.NewHTTPHandler(importArchivePath, http.MethodPost, o.ImportArchive),
 	}
 }
 
@@ -63,3 +78,79 @@ func (o *Operation) registerHandler() {
 func (o *Operation) CreateKeySet(rw http.ResponseWriter, req *http.Request) {
 	rest.Execute(o.command.CreateKeySet, rw, req.Body)
 }
+
+// VerifyKeystore swagger:route GET /kms/verify kms verifyKeystore
+//
+// Verifies the integrity of the keystore and returns the IDs of the keysets failing verification.
+//
+// Responses:
+//    default: genericError
+//        200: verifyKeystoreRes
+func (o *Operation) VerifyKeystore(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.VerifyKeystore, rw, req.Body)
+}
+
+// GetStats swagger:route GET /kms/stats kms getStats
+//
+// Returns the statistics of the keys stored by the KMS.
+//
+// Responses:
+//    default: genericError
+//        200: getStatsRes
+func (o *Operation) GetStats(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.GetStats, rw, req.Body)
+}
+
+// RotateKeys swagger:route POST /kms/rotate kms rotateKeys
+//
+// Rotates all the keys of a key type and returns the old and new IDs of the rotated keys along with the failures.
+//
+// Responses:
+//    default: genericError
+//        200: rotateKeysRes
+func (o *Operation) RotateKeys(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.RotateKeys, rw, req.Body)
+}
+
+// ExportArchive swagger:route POST /kms/export kms exportArchive
+//
+// Exports the archive of the keystore, its keys are encrypted with the master key of the KMS. The archive is streamed
+// as it is read from the keystore: a failure after the first entry ends the archive with the error entry.
+//
+// Produces:
+//    - application/x-ndjson
+//
+// Responses:
+//    default: genericError
+//        200: exportArchiveRes
+func (o *Operation) ExportArchive(rw http.ResponseWriter, req *http.Request) {
+	if err := o.command.ExportArchive(&archiveWriter{ResponseWriter: rw}, req.Body); err != nil {
+		rest.SendError(rw, err)
+	}
+}
+
+// ImportArchive swagger:route POST /kms/import kms importArchive
+//
+// Imports a keystore archive exported by a KMS using the same master key.
+//
+// Responses:
+//    default: genericError
+//        200: importArchiveRes
+func (o *Operation) ImportArchive(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.ImportArchive, rw, req.Body)
+}
+
+// archiveWriter streams the archive to the response, setting the archive content type before the first write.
+type archiveWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *archiveWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.Header().Set("Content-Type", archiveContentType)
+		w.started = true
+	}
+
+	return w.ResponseWriter.Write(p)
+}
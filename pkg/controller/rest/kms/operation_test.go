@@ -22,23 +22,28 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
-	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mocklegacykms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
 )
 
 func TestNew(t *testing.T) {
 	t.Run("test new command - success", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{},
+			KMSValue: &mocklegacykms.CloseableKMS{},
 		})
 		require.NotNil(t, cmd)
-		require.Equal(t, 1, len(cmd.GetRESTHandlers()))
+		require.Equal(t, 6, len(cmd.GetRESTHandlers()))
 	})
 }
 
 func TestCreateKeySet(t *testing.T) {
 	t.Run("test create key set - success", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{CreateEncryptionKeyValue: "encryptionKey",
+			KMSValue: &mocklegacykms.CloseableKMS{CreateEncryptionKeyValue: "encryptionKey",
 				CreateSigningKeyValue: "signingKey"},
 		})
 		require.NotNil(t, cmd)
@@ -58,7 +63,7 @@ func TestCreateKeySet(t *testing.T) {
 
 	t.Run("test create key set - error", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{CreateKeyErr: fmt.Errorf("error create key set")},
+			KMSValue: &mocklegacykms.CloseableKMS{CreateKeyErr: fmt.Errorf("error create key set")},
 		})
 		require.NotNil(t, cmd)
 
@@ -72,6 +77,115 @@ func TestCreateKeySet(t *testing.T) {
 	})
 }
 
+func TestVerifyKeystore(t *testing.T) {
+	t.Run("test verify keystore - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{VerifyValue: []string{"corrupted"}},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, verifyKeystorePath, http.MethodGet)
+		buf, err := getSuccessResponseFromHandler(handler, nil, verifyKeystorePath)
+		require.NoError(t, err)
+
+		response := verifyKeystoreRes{}
+		err = json.Unmarshal(buf.Bytes(), &response)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"corrupted"}, response.FailedKeyIDs)
+	})
+
+	t.Run("test verify keystore - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{VerifyErr: fmt.Errorf("error verify keystore")},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, verifyKeystorePath, http.MethodGet)
+		buf, code, err := sendRequestToHandler(handler, nil, verifyKeystorePath)
+		require.NoError(t, err)
+		require.NotEmpty(t, buf)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.VerifyKeystoreError, "error verify keystore", buf.Bytes())
+	})
+}
+
+func TestGetStats(t *testing.T) {
+	t.Run("test get stats - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{StatsValue: &localkms.KMSStats{
+				TotalKeys: 2,
+				KeyTypes:  map[kmsapi.KeyType]int{kmsapi.ED25519Type: 2},
+			}},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, getStatsPath, http.MethodGet)
+		buf, err := getSuccessResponseFromHandler(handler, nil, getStatsPath)
+		require.NoError(t, err)
+
+		response := getStatsRes{}
+		err = json.Unmarshal(buf.Bytes(), &response)
+		require.NoError(t, err)
+
+		require.Equal(t, 2, response.TotalKeys)
+		require.Equal(t, map[kmsapi.KeyType]int{kmsapi.ED25519Type: 2}, response.KeyTypes)
+	})
+
+	t.Run("test get stats - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{StatsErr: fmt.Errorf("error get stats")},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, getStatsPath, http.MethodGet)
+		buf, code, err := sendRequestToHandler(handler, nil, getStatsPath)
+		require.NoError(t, err)
+		require.NotEmpty(t, buf)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.GetStatsError, "error get stats", buf.Bytes())
+	})
+}
+
+func TestRotateKeys(t *testing.T) {
+	t.Run("test rotate keys - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{ListValue: []string{"key1"}, RotateKeyID: "key2"},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, rotateKeysPath, http.MethodPost)
+		buf, err := getSuccessResponseFromHandler(handler, bytes.NewBufferString(`{"keyType":"ED25519"}`),
+			rotateKeysPath)
+		require.NoError(t, err)
+
+		response := rotateKeysRes{}
+		err = json.Unmarshal(buf.Bytes(), &response)
+		require.NoError(t, err)
+
+		require.Equal(t, []kms.RotatedKey{{OldKeyID: "key1", NewKeyID: "key2"}}, response.Rotated)
+		require.Empty(t, response.Failed)
+	})
+
+	t.Run("test rotate keys - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{ListErr: fmt.Errorf("error list keys")},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, rotateKeysPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(`{"keyType":"ED25519"}`),
+			rotateKeysPath)
+		require.NoError(t, err)
+		require.NotEmpty(t, buf)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.RotateKeysError, "error list keys", buf.Bytes())
+	})
+}
+
 func lookupHandler(t *testing.T, op *Operation, path, method string) rest.Handler {
 	handlers := op.GetRESTHandlers()
 	require.NotEmpty(t, handlers)
@@ -139,3 +253,80 @@ func verifyError(t *testing.T, expectedCode command.Code, expectedMsg string, da
 		require.Contains(t, errResponse.Message, expectedMsg)
 	}
 }
+
+func TestArchive(t *testing.T) {
+	newLocalKMS := func(t *testing.T) *localkms.LocalKMS {
+		k, err := localkms.New("local-lock://custom/master/key/",
+			mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+		require.NoError(t, err)
+
+		return k
+	}
+
+	t.Run("test export archive and import it into an empty kms - success", func(t *testing.T) {
+		source := newLocalKMS(t)
+
+		signingKeyID, _, err := source.Create(kmsapi.ED25519Type)
+		require.NoError(t, err)
+
+		aeadKeyID, _, err := source.Create(kmsapi.AES256GCMType)
+		require.NoError(t, err)
+
+		handler := lookupHandler(t, New(&mockprovider.Provider{CustomKMS: source}), exportArchivePath,
+			http.MethodPost)
+		archive, err := getSuccessResponseFromHandler(handler, nil, exportArchivePath)
+		require.NoError(t, err)
+		require.NotEmpty(t, archive)
+
+		target := newLocalKMS(t)
+
+		handler = lookupHandler(t, New(&mockprovider.Provider{CustomKMS: target}), importArchivePath,
+			http.MethodPost)
+		buf, err := getSuccessResponseFromHandler(handler, archive, importArchivePath)
+		require.NoError(t, err)
+
+		response := importArchiveRes{}
+		err = json.Unmarshal(buf.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, 2, response.Imported)
+
+		for _, keyID := range []string{signingKeyID, aeadKeyID} {
+			_, err = target.Get(keyID)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("test export archive sets the archive content type", func(t *testing.T) {
+		handler := lookupHandler(t, New(&mockprovider.Provider{CustomKMS: newLocalKMS(t)}), exportArchivePath,
+			http.MethodPost)
+
+		req, err := http.NewRequest(http.MethodPost, exportArchivePath, nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handler.Handle()(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, archiveContentType, rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("test import archive - error", func(t *testing.T) {
+		handler := lookupHandler(t, New(&mockprovider.Provider{CustomKMS: newLocalKMS(t)}), importArchivePath,
+			http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString("{"), importArchivePath)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.ImportArchiveError, "invalid header", buf.Bytes())
+	})
+
+	t.Run("test export archive - error", func(t *testing.T) {
+		handler := lookupHandler(t, New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}}),
+			exportArchivePath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, nil, exportArchivePath)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.ExportArchiveError, "kms does not support archives", buf.Bytes())
+	})
+}
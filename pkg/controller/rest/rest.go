@@ -25,10 +25,14 @@ type Handler interface {
 }
 
 // Execute executes given command with args provided and writes error to
-// response writer
+// response writer. Command errors are logged with the request correlation ID, if any (see CorrelationIDMiddleware).
 func Execute(exec command.Exec, rw http.ResponseWriter, req io.Reader) {
 	err := exec(rw, req)
 	if err != nil {
+		if correlationID := rw.Header().Get(CorrelationIDHeader); correlationID != "" {
+			logger.Debugf("command failed [correlationID=%s]: %s", correlationID, err)
+		}
+
 		SendError(rw, err)
 	}
 
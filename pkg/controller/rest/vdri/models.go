@@ -11,7 +11,6 @@ import (
 
 	vdricommand "github.com/hyperledger/aries-framework-go/pkg/controller/command/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
-	didstore "github.com/hyperledger/aries-framework-go/pkg/store/did"
 )
 
 // createPublicDIDRequest model
@@ -36,6 +35,28 @@ type createPublicDIDResponse struct {
 	DID did.Doc `json:"did"`
 }
 
+// validateDIDRequest model
+//
+// This is used for operation to validate the creation of a public DID
+//
+// swagger:parameters validateDID
+type validateDIDRequest struct { // nolint: unused,deadcode
+	// Params for validating the creation of a public DID
+	//
+	// in: path
+	vdricommand.CreatePublicDIDArgs
+}
+
+// validateDIDResponse model
+//
+// This is used for returning the would-be public DID and its validation errors
+//
+// swagger:response validateDIDResponse
+type validateDIDResponse struct {
+	// in: body
+	vdricommand.ValidateDIDResponse
+}
+
 // saveDIDReq model
 //
 // This is used to save the did with did document.
@@ -72,6 +93,28 @@ type documentRes struct { // nolint: unused,deadcode
 	DID json.RawMessage `json:"did,omitempty"`
 }
 
+// getDIDRecordsReq model
+//
+// This is used to retrieve the did records.
+//
+// swagger:parameters getDIDRecords
+type getDIDRecordsReq struct { // nolint: unused,deadcode
+	// Inline includes the did document in each record
+	//
+	// in: query
+	Inline bool `json:"inline"`
+
+	// Limit is the maximum number of records to return
+	//
+	// in: query
+	Limit int `json:"limit"`
+
+	// Offset is the number of records to skip
+	//
+	// in: query
+	Offset int `json:"offset"`
+}
+
 // didRecordResult model
 //
 // This is used to return did records.
@@ -79,5 +122,5 @@ type documentRes struct { // nolint: unused,deadcode
 // swagger:response didRecordResult
 type didRecordResult struct {
 	// in: body
-	Result []*didstore.Record `json:"result,omitempty"`
+	Result []*vdricommand.DIDRecord `json:"result,omitempty"`
 }
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/gorilla/mux"
 
@@ -30,6 +31,7 @@ const (
 	saveDIDPath         = vdriDIDPath
 	getDIDPath          = vdriDIDPath + "/{id}"
 	getDIDRecordsPath   = vdriDIDPath + "/records"
+	validateDIDPath     = vdriOperationID + "/validate-did"
 )
 
 // provider contains dependencies for the common controller operations
@@ -71,6 +73,7 @@ func (o *Operation) registerHandler() {
 		cmdutil.NewHTTPHandler(saveDIDPath, http.MethodPost, o.SaveDID),
 		cmdutil.NewHTTPHandler(getDIDPath, http.MethodGet, o.GetDID),
 		cmdutil.NewHTTPHandler(getDIDRecordsPath, http.MethodGet, o.GetDIDRecords),
+		cmdutil.NewHTTPHandler(validateDIDPath, http.MethodPost, o.ValidateDID),
 	}
 }
 
@@ -91,6 +94,24 @@ func (o *Operation) CreatePublicDID(rw http.ResponseWriter, req *http.Request) {
 	rest.Execute(o.command.CreatePublicDID, rw, bytes.NewReader(reqBytes))
 }
 
+// ValidateDID swagger:route POST /vdri/validate-did vdri validateDID
+//
+// Validates the creation of a new Public DID without creating it: returns the would-be DID document and
+// the validation errors, if any.
+//
+// Responses:
+//    default: genericError
+//        200: validateDIDResponse
+func (o *Operation) ValidateDID(rw http.ResponseWriter, req *http.Request) {
+	reqBytes, err := queryValuesAsJSON(req.URL.Query())
+	if err != nil {
+		rest.SendHTTPStatusError(rw, http.StatusBadRequest, vdri.InvalidRequestErrorCode, err)
+		return
+	}
+
+	rest.Execute(o.command.ValidateDID, rw, bytes.NewReader(reqBytes))
+}
+
 // SaveDID swagger:route POST /vdri/did vdri saveDIDReq
 //
 // Saves a did document with the friendly name.
@@ -124,13 +145,55 @@ func (o *Operation) GetDID(rw http.ResponseWriter, req *http.Request) {
 
 // GetDIDRecords swagger:route GET /vdri/did/records vdri getDIDRecords
 //
-// Retrieves the did records
+// Retrieves the did records, with their did documents when inline is true.
 //
 // Responses:
 //    default: genericError
 //        200: didRecordResult
 func (o *Operation) GetDIDRecords(rw http.ResponseWriter, req *http.Request) {
-	rest.Execute(o.command.GetDIDRecords, rw, req.Body)
+	request, err := getDIDRecordsArgs(req.URL.Query())
+	if err != nil {
+		rest.SendHTTPStatusError(rw, http.StatusBadRequest, vdri.InvalidRequestErrorCode, err)
+		return
+	}
+
+	reqBytes, err := json.Marshal(request)
+	if err != nil {
+		rest.SendHTTPStatusError(rw, http.StatusBadRequest, vdri.InvalidRequestErrorCode, err)
+		return
+	}
+
+	rest.Execute(o.command.GetDIDRecords, rw, bytes.NewReader(reqBytes))
+}
+
+// getDIDRecordsArgs parses the inline, limit and offset query strings of the get did records request.
+func getDIDRecordsArgs(vals url.Values) (*vdri.GetDIDRecordsArgs, error) {
+	request := &vdri.GetDIDRecordsArgs{}
+
+	var err error
+
+	if inline := vals.Get("inline"); inline != "" {
+		request.Inline, err = strconv.ParseBool(inline)
+		if err != nil {
+			return nil, fmt.Errorf("invalid inline : %w", err)
+		}
+	}
+
+	if limit := vals.Get("limit"); limit != "" {
+		request.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit : %w", err)
+		}
+	}
+
+	if offset := vals.Get("offset"); offset != "" {
+		request.Offset, err = strconv.Atoi(offset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset : %w", err)
+		}
+	}
+
+	return request, nil
 }
 
 // queryValuesAsJSON converts query strings to `map[string]string`
@@ -22,6 +22,8 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
 	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
@@ -57,7 +59,7 @@ func TestNew(t *testing.T) {
 		})
 		require.NoError(t, err)
 		require.NotNil(t, cmd)
-		require.Equal(t, 4, len(cmd.GetRESTHandlers()))
+		require.Equal(t, 5, len(cmd.GetRESTHandlers()))
 	})
 
 	t.Run("test new command - error", func(t *testing.T) {
@@ -133,6 +135,69 @@ func TestOperation_CreatePublicDID(t *testing.T) {
 	})
 }
 
+// validatorRegistry is a vdri registry validating the creation of public DIDs
+type validatorRegistry struct {
+	mockvdri.MockVDRIRegistry
+	doc  *did.Doc
+	errs []error
+}
+
+func (v *validatorRegistry) Validate(string, ...vdriapi.DocOpts) (*did.Doc, []error) {
+	return v.doc, v.errs
+}
+
+func TestOperation_ValidateDID(t *testing.T) {
+	t.Run("Successful validate DID", func(t *testing.T) {
+		didDoc, err := did.ParseDocument([]byte(doc))
+		require.NoError(t, err)
+
+		svc, err := New(&protocol.MockProvider{CustomVDRI: &validatorRegistry{doc: didDoc}})
+		require.NoError(t, err)
+
+		handler := lookupHandler(t, svc, validateDIDPath, http.MethodPost)
+		buf, err := getSuccessResponseFromHandler(handler, nil, handler.Path()+"?method=sidetree")
+		require.NoError(t, err)
+
+		response := validateDIDResponse{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.True(t, response.Valid)
+		require.Empty(t, response.Errors)
+		require.Equal(t, didDoc.ID, response.DID.ID)
+	})
+
+	t.Run("Validate DID with validation errors", func(t *testing.T) {
+		svc, err := New(&protocol.MockProvider{CustomVDRI: &validatorRegistry{
+			errs: []error{fmt.Errorf("service #endpoint-1: endpoint is empty")},
+		}})
+		require.NoError(t, err)
+
+		handler := lookupHandler(t, svc, validateDIDPath, http.MethodPost)
+		buf, err := getSuccessResponseFromHandler(handler, nil, handler.Path()+"?method=sidetree")
+		require.NoError(t, err)
+
+		response := validateDIDResponse{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.False(t, response.Valid)
+		require.Equal(t, []string{"service #endpoint-1: endpoint is empty"}, response.Errors)
+	})
+
+	t.Run("Failed validate DID", func(t *testing.T) {
+		svc, err := New(&protocol.MockProvider{})
+		require.NoError(t, err)
+
+		handler := lookupHandler(t, svc, validateDIDPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, nil, handler.Path()+"?-----")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, code)
+		verifyError(t, vdri.InvalidRequestErrorCode, "", buf.Bytes())
+
+		buf, code, err = sendRequestToHandler(handler, nil, handler.Path()+"?method=sidetree")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, vdri.ValidateDIDErrorCode, "does not support validation", buf.Bytes())
+	})
+}
+
 func TestSaveDID(t *testing.T) {
 	t.Run("test save did - success", func(t *testing.T) {
 		cmd, err := New(&mockprovider.Provider{
@@ -252,6 +317,38 @@ func TestGetDIDRecords(t *testing.T) {
 		// verify response
 		require.NotEmpty(t, response)
 		require.Equal(t, 1, len(response.Result))
+		require.Empty(t, response.Result[0].DID)
+
+		buf, err = getSuccessResponseFromHandler(handler, nil, getDIDRecordsPath+"?inline=true&limit=10")
+		require.NoError(t, err)
+
+		response = didRecordResult{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.Equal(t, 1, len(response.Result))
+		require.NotEmpty(t, response.Result[0].DID)
+
+		buf, err = getSuccessResponseFromHandler(handler, nil, getDIDRecordsPath+"?offset=1")
+		require.NoError(t, err)
+
+		response = didRecordResult{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.Empty(t, response.Result)
+	})
+
+	t.Run("test get did records invalid query", func(t *testing.T) {
+		cmd, err := New(&mockprovider.Provider{
+			StorageProviderValue: mockstore.NewMockStoreProvider(),
+		})
+		require.NoError(t, err)
+
+		handler := lookupHandler(t, cmd, getDIDRecordsPath, http.MethodGet)
+
+		for _, query := range []string{"?inline=maybe", "?limit=ten", "?offset=one"} {
+			buf, code, err := sendRequestToHandler(handler, nil, getDIDRecordsPath+query)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusBadRequest, code)
+			verifyError(t, vdri.InvalidRequestErrorCode, "invalid", buf.Bytes())
+		}
 	})
 }
 
@@ -0,0 +1,180 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webnotifier
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+)
+
+const (
+	// sseTopicQueryParam is the query parameter filtering the topics streamed to a client, it can be repeated or
+	// hold comma separated topics (eg: /events?topic=connections,route)
+	sseTopicQueryParam = "topic"
+	// sseClientBufferSize is the number of notifications buffered for a client, the next ones are dropped until the
+	// client catches up
+	sseClientBufferSize = 64
+)
+
+// SSENotifier is a dispatcher capable of notifying multiple subscribers via Server-Sent Events.
+type SSENotifier struct {
+	clients     map[*sseClient]struct{}
+	clientsLock sync.RWMutex
+	handlers    []rest.Handler
+}
+
+type sseClient struct {
+	topics map[string]struct{}
+	events chan *sseEvent
+}
+
+type sseEvent struct {
+	topic string
+	data  []byte
+}
+
+// NewSSENotifier returns a new instance of an SSENotifier streaming the notifications on path.
+func NewSSENotifier(path string) *SSENotifier {
+	n := SSENotifier{
+		clients: map[*sseClient]struct{}{},
+	}
+
+	n.registerHandler(path)
+
+	return &n
+}
+
+// Notify sends the given message to all of the SSE clients subscribed to topic.
+func (n *SSENotifier) Notify(topic string, message []byte) error {
+	if topic == "" {
+		return fmt.Errorf(emptyTopicErrMsg)
+	}
+
+	if len(message) == 0 {
+		return fmt.Errorf(emptyMessageErrMsg)
+	}
+
+	topicMsg, err := prepareTopicMessage(topic, message)
+	if err != nil {
+		return fmt.Errorf(failedToCreateErrMsg, err)
+	}
+
+	event := &sseEvent{topic: topic, data: topicMsg}
+
+	n.clientsLock.RLock()
+	defer n.clientsLock.RUnlock()
+
+	for client := range n.clients {
+		if !client.subscribed(topic) {
+			continue
+		}
+
+		select {
+		case client.events <- event:
+		default:
+			logger.Warnf("sse notification client is too slow, dropping notification on topic '%s'", topic)
+		}
+	}
+
+	return nil
+}
+
+func (c *sseClient) subscribed(topic string) bool {
+	if len(c.topics) == 0 {
+		return true
+	}
+
+	_, ok := c.topics[topic]
+
+	return ok
+}
+
+func (n *SSENotifier) handleSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client := &sseClient{
+		topics: sseTopics(r),
+		events: make(chan *sseEvent, sseClientBufferSize),
+	}
+
+	n.addClient(client)
+	defer n.removeClient(client)
+
+	logger.Debugf("sse notification client connected")
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			logger.Debugf("sse notification client dropped")
+			return
+		case event := <-client.events:
+			// the topic messages are compact JSON, they hold on a single data line
+			_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.topic, event.data)
+			if err != nil {
+				logger.Infof("writing to sse notification client failed: %v", err)
+				return
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
+func sseTopics(r *http.Request) map[string]struct{} {
+	topics := map[string]struct{}{}
+
+	for _, param := range r.URL.Query()[sseTopicQueryParam] {
+		for _, topic := range strings.Split(param, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics[topic] = struct{}{}
+			}
+		}
+	}
+
+	return topics
+}
+
+func (n *SSENotifier) addClient(client *sseClient) {
+	n.clientsLock.Lock()
+	defer n.clientsLock.Unlock()
+
+	n.clients[client] = struct{}{}
+}
+
+func (n *SSENotifier) removeClient(client *sseClient) {
+	n.clientsLock.Lock()
+	defer n.clientsLock.Unlock()
+
+	delete(n.clients, client)
+}
+
+// registerHandler register handlers to be exposed from this protocol service as REST API endpoints
+func (n *SSENotifier) registerHandler(path string) {
+	n.handlers = []rest.Handler{
+		cmdutil.NewHTTPHandler(path, http.MethodGet, n.handleSSE),
+	}
+}
+
+// GetRESTHandlers returns all REST handlers provided by notifier.
+func (n *SSENotifier) GetRESTHandlers() []rest.Handler {
+	return n.handlers
+}
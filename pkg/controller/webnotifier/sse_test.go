@@ -0,0 +1,154 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webnotifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifySSE(t *testing.T) {
+	const path = "/events"
+
+	n := NewSSENotifier(path)
+
+	handler := n.GetRESTHandlers()[0]
+
+	router := mux.NewRouter()
+	router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	subscribe := func(t *testing.T, query string) (*bufio.Reader, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path+query, nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		t.Cleanup(func() {
+			require.NoError(t, resp.Body.Close())
+		})
+
+		return bufio.NewReader(resp.Body), cancel
+	}
+
+	readEvent := func(t *testing.T, r *bufio.Reader) (string, []byte) {
+		event, err := r.ReadString('\n')
+		require.NoError(t, err)
+
+		data, err := r.ReadString('\n')
+		require.NoError(t, err)
+
+		blank, err := r.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "\n", blank)
+
+		var topicMsg struct {
+			Topic   string          `json:"topic"`
+			Message json.RawMessage `json:"message"`
+		}
+
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(data, "data: ")), &topicMsg))
+		require.Equal(t, strings.TrimSpace(strings.TrimPrefix(event, "event: ")), topicMsg.Topic)
+
+		return topicMsg.Topic, topicMsg.Message
+	}
+
+	t.Run("notifications are delivered over the stream", func(t *testing.T) {
+		r, cancel := subscribe(t, "")
+		defer cancel()
+
+		validateSSEClientCount(t, n, 1)
+
+		require.NoError(t, n.Notify("connections", []byte(`{"msg":"payload1"}`)))
+		require.NoError(t, n.Notify("route", []byte(`{"msg":"payload2"}`)))
+
+		topic, msg := readEvent(t, r)
+		require.Equal(t, "connections", topic)
+		require.JSONEq(t, `{"msg":"payload1"}`, string(msg))
+
+		topic, msg = readEvent(t, r)
+		require.Equal(t, "route", topic)
+		require.JSONEq(t, `{"msg":"payload2"}`, string(msg))
+
+		cancel()
+		validateSSEClientCount(t, n, 0)
+	})
+
+	t.Run("notifications are filtered by topic", func(t *testing.T) {
+		r, cancel := subscribe(t, "?topic=route,outofband&topic=introduce")
+		defer cancel()
+
+		validateSSEClientCount(t, n, 1)
+
+		require.NoError(t, n.Notify("connections", []byte(`{"msg":"payload1"}`)))
+		require.NoError(t, n.Notify("outofband", []byte(`{"msg":"payload2"}`)))
+		require.NoError(t, n.Notify("introduce", []byte(`{"msg":"payload3"}`)))
+
+		topic, msg := readEvent(t, r)
+		require.Equal(t, "outofband", topic)
+		require.JSONEq(t, `{"msg":"payload2"}`, string(msg))
+
+		topic, _ = readEvent(t, r)
+		require.Equal(t, "introduce", topic)
+
+		cancel()
+		validateSSEClientCount(t, n, 0)
+	})
+
+	t.Run("invalid notifications", func(t *testing.T) {
+		require.EqualError(t, n.Notify("", []byte(`{}`)), emptyTopicErrMsg)
+		require.EqualError(t, n.Notify("connections", nil), emptyMessageErrMsg)
+	})
+}
+
+func TestNotifySSE_SlowClient(t *testing.T) {
+	n := NewSSENotifier("/events")
+
+	client := &sseClient{events: make(chan *sseEvent, 1)}
+	n.addClient(client)
+
+	require.NoError(t, n.Notify("connections", []byte(`{"msg":"payload1"}`)))
+	require.NoError(t, n.Notify("connections", []byte(`{"msg":"payload2"}`)))
+	require.Len(t, client.events, 1)
+}
+
+func validateSSEClientCount(t *testing.T, n *SSENotifier, expectedCount int) {
+	const (
+		attemptWait = 50 * time.Millisecond
+		maxAttempts = 20
+	)
+
+	for i := 0; i < maxAttempts; i++ {
+		n.clientsLock.RLock()
+		clientCount := len(n.clients)
+		n.clientsLock.RUnlock()
+
+		if clientCount == expectedCount {
+			return
+		}
+
+		time.Sleep(attemptWait)
+	}
+
+	t.Fatal("invalid sse client count")
+}
@@ -23,11 +23,14 @@ const (
 	emptyTopicErrMsg        = "cannot notify with an empty topic"
 	emptyMessageErrMsg      = "cannot notify with an empty message"
 	failedToCreateErrMsg    = "failed to create topic message : %w"
+	// ssePath is the path of the Server-Sent Events endpoint
+	ssePath = "/events"
 )
 
 var logger = log.New("aries-framework/webnotifier")
 
-// WebNotifier is a dispatcher capable of notifying multiple subscribers via HTTP Webhooks and WebSockets.
+// WebNotifier is a dispatcher capable of notifying multiple subscribers via HTTP Webhooks, WebSockets and
+// Server-Sent Events.
 type WebNotifier struct {
 	notifiers []command.Notifier
 	handlers  []rest.Handler
@@ -37,10 +40,11 @@ type WebNotifier struct {
 func New(wsPath string, webhookURLs []string) *WebNotifier {
 	webhook := NewHTTPNotifier(webhookURLs)
 	ws := NewWSNotifier(wsPath)
+	sse := NewSSENotifier(ssePath)
 
 	n := WebNotifier{
-		notifiers: []command.Notifier{webhook, ws},
-		handlers:  ws.GetRESTHandlers(),
+		notifiers: []command.Notifier{webhook, ws, sse},
+		handlers:  append(ws.GetRESTHandlers(), sse.GetRESTHandlers()...),
 	}
 
 	return &n
@@ -16,15 +16,15 @@ func TestNew(t *testing.T) {
 	t.Run("New WebNotifier (populated)", func(t *testing.T) {
 		n := New("/", []string{"http://localhost:8080"})
 		require.NotNil(t, n)
-		require.Equal(t, 2, len(n.notifiers))
-		require.Equal(t, 1, len(n.handlers))
+		require.Equal(t, 3, len(n.notifiers))
+		require.Equal(t, 2, len(n.handlers))
 	})
 
 	t.Run("New WebNotifier (nil)", func(t *testing.T) {
 		n := New("", nil)
 		require.NotNil(t, n)
-		require.Equal(t, 2, len(n.notifiers))
-		require.Equal(t, 1, len(n.handlers))
+		require.Equal(t, 3, len(n.notifiers))
+		require.Equal(t, 2, len(n.handlers))
 	})
 }
 
@@ -41,5 +41,5 @@ func TestGetHandlers(t *testing.T) {
 	require.NotNil(t, n)
 
 	handlers := n.GetRESTHandlers()
-	require.Equal(t, 1, len(handlers))
+	require.Equal(t, 2, len(handlers))
 }
@@ -0,0 +1,114 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tinkcrypto
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// secretPackages are the source trees handling keys, MACs and signatures, relative to this package.
+var secretPackages = []string{ //nolint:gochecknoglobals
+	"../../crypto",
+	"../../kms",
+	"../../secretlock",
+	"../../didcomm/packer",
+	"../../doc/jose",
+}
+
+// variableTimeComparisons are the byte comparisons which return on the first differing byte, secret-dependent
+// values (MACs, tags, signatures, keys) must be compared with crypto/subtle (or hmac.Equal) instead.
+var variableTimeComparisons = map[string]string{ //nolint:gochecknoglobals
+	"bytes": "Equal",
+}
+
+// TestConstantTimeComparisons guarantees that the non-test sources of the packages handling secrets do not compare
+// bytes in variable time.
+func TestConstantTimeComparisons(t *testing.T) {
+	for _, root := range secretPackages {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return err
+			}
+
+			for _, call := range variableTimeCalls(t, path) {
+				t.Errorf("%s: variable time comparison %s, use crypto/subtle.ConstantTimeCompare", path, call)
+			}
+
+			return nil
+		})
+		require.NoError(t, err)
+	}
+}
+
+func variableTimeCalls(t *testing.T, path string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	require.NoError(t, err)
+
+	// the local names of the imported packages, eg: bytes may be renamed
+	names := map[string]string{}
+
+	for _, imp := range file.Imports {
+		pkg := strings.Trim(imp.Path.Value, `"`)
+		if _, found := variableTimeComparisons[pkg]; !found {
+			continue
+		}
+
+		name := filepath.Base(pkg)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+
+		names[name] = pkg
+	}
+
+	var calls []string
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			if pkg, found := names[ident.Name]; found && variableTimeComparisons[pkg] == sel.Sel.Name {
+				calls = append(calls, pkg+"."+sel.Sel.Name)
+			}
+		}
+
+		return true
+	})
+
+	return calls
+}
+
+func TestVariableTimeCalls(t *testing.T) {
+	dir, err := ioutil.TempDir("", "constanttime")
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	path := filepath.Join(dir, "compare.go")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`package compare
+
+import b "bytes"
+
+func compare(tag, expected []byte) bool {
+	return b.Equal(tag, expected)
+}
+`), 0600))
+
+	require.Equal(t, []string{"bytes.Equal"}, variableTimeCalls(t, path))
+}
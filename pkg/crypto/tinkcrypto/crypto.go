@@ -15,15 +15,20 @@ import (
 	"fmt"
 
 	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/core/cryptofmt"
 	"github.com/google/tink/go/core/primitiveset"
 	"github.com/google/tink/go/keyset"
 	"github.com/google/tink/go/mac"
 	"github.com/google/tink/go/signature"
 	aeadsubtle "github.com/google/tink/go/subtle/aead"
+	"github.com/google/tink/go/tink"
 	"golang.org/x/crypto/chacha20poly1305"
 )
 
-var errBadKeyHandleFormat = errors.New("bad key handle format")
+var (
+	errBadKeyHandleFormat = errors.New("bad key handle format")
+	errInvalidMAC         = errors.New("invalid MAC")
+)
 
 // Package provider/tinkcrypto includes implementation of spi/crypto. SPI implementation will be built
 // as a Framework option and fed into pkg/common/crypto implementation that includes a combined crypto
@@ -135,7 +140,14 @@ func (t *Crypto) Sign(msg []byte, kh interface{}) ([]byte, error) {
 	return s, nil
 }
 
-// Verify will verify sig signature of msg using the implementation's corresponding signing key referenced by kh
+// Verify will verify sig signature of msg using the implementation's corresponding signing key referenced by kh.
+// The signature verifies regardless of the output prefix of the keys of kh, as long as the key matches: a signature
+// made with a RAW key verifies with a TINK key and a TINK signature verifies with a RAW key, eg: with the key handles
+// built from the public key bytes, since the key material identifies the key. With a TINK key, a TINK signature only
+// verifies if its prefix is the prefix of the key. If the signature does not verify with the output prefix of the keys
+// of kh, each key is tried again with the RAW and, if sig starts with a TINK prefix, the TINK interpretation of sig,
+// so verifying an invalid signature costs up to twice the verifications per key. LEGACY prefixed signatures are not
+// supported.
 func (t *Crypto) Verify(sig, msg []byte, kh interface{}) error {
 	keyHandle, ok := kh.(*keyset.Handle)
 	if !ok {
@@ -148,11 +160,53 @@ func (t *Crypto) Verify(sig, msg []byte, kh interface{}) error {
 	}
 
 	err = verifier.Verify(sig, msg)
+	if err != nil && verifyAnyPrefix(sig, msg, keyHandle) != nil {
+		return fmt.Errorf("verify msg: %w", err)
+	}
+
+	return nil
+}
+
+// verifyAnyPrefix verifies sig signature of msg with the keys of keyHandle, ignoring their output prefix. sig is
+// verified as a RAW signature, then as a TINK signature if it starts with the TINK prefix of the key or, for RAW keys,
+// with a TINK prefix of any key ID.
+func verifyAnyPrefix(sig, msg []byte, keyHandle *keyset.Handle) error {
+	ps, err := keyHandle.Primitives()
 	if err != nil {
-		err = fmt.Errorf("verify msg: %w", err)
+		return fmt.Errorf("get primitives: %w", err)
+	}
+
+	for _, entries := range ps.Entries {
+		for _, entry := range entries {
+			verifier, ok := entry.Primitive.(tink.Verifier)
+			if !ok {
+				return errors.New("not a verifier primitive")
+			}
+
+			candidates := [][]byte{sig}
+			if hasTinkPrefix(sig, entry.Prefix) {
+				candidates = append(candidates, sig[cryptofmt.TinkPrefixSize:])
+			}
+
+			for _, candidate := range candidates {
+				if verifier.Verify(candidate, msg) == nil {
+					return nil
+				}
+			}
+		}
 	}
 
-	return err
+	return errors.New("invalid signature")
+}
+
+// hasTinkPrefix reports whether sig starts with a TINK prefix which may be stripped for the key of prefix keyPrefix:
+// RAW keys (empty prefix) accept the TINK prefix of any key ID, TINK keys only their own prefix.
+func hasTinkPrefix(sig []byte, keyPrefix string) bool {
+	if len(sig) <= cryptofmt.TinkPrefixSize || sig[0] != cryptofmt.TinkStartByte {
+		return false
+	}
+
+	return keyPrefix == cryptofmt.RawPrefix || string(sig[:cryptofmt.TinkPrefixSize]) == keyPrefix
 }
 
 // ComputeMAC computes message authentication code (MAC) for code data
@@ -173,7 +227,17 @@ func (t *Crypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
 
 // VerifyMAC determines if mac is a correct authentication code (MAC) for data
 // using a matching MAC primitive in kh key handle and returns nil if so, otherwise it returns an error.
+// The MAC is compared in constant time (see VerifyMACConstantTime).
 func (t *Crypto) VerifyMAC(macBytes, data []byte, kh interface{}) error {
+	return t.VerifyMACConstantTime(macBytes, data, kh)
+}
+
+// VerifyMACConstantTime determines if mac is a correct authentication code (MAC) for data using the MAC primitives
+// in kh key handle. It is constant-time: the MAC is verified by the Tink MAC primitive of kh, which compares the MAC
+// computed by the keys matching the prefix of mac (and by the RAW keys) with crypto/hmac.Equal, so the time taken
+// does not depend on the secret MACs. The MAC is computed as Tink does for the output prefix type of each key
+// (eg: LEGACY keys), so the MACs of ComputeMAC are verified for any output prefix type.
+func (t *Crypto) VerifyMACConstantTime(macBytes, data []byte, kh interface{}) error {
 	keyHandle, ok := kh.(*keyset.Handle)
 	if !ok {
 		return errBadKeyHandleFormat
@@ -181,8 +245,12 @@ func (t *Crypto) VerifyMAC(macBytes, data []byte, kh interface{}) error {
 
 	macPrimitive, err := mac.New(keyHandle)
 	if err != nil {
-		return err
+		return fmt.Errorf("verify MAC: %w", err)
+	}
+
+	if err = macPrimitive.VerifyMAC(macBytes, data); err != nil {
+		return errInvalidMAC
 	}
 
-	return macPrimitive.VerifyMAC(macBytes, data)
+	return nil
 }
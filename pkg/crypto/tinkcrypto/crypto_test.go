@@ -9,15 +9,23 @@ package tinkcrypto
 import (
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/insecurecleartextkeyset"
 	"github.com/google/tink/go/keyset"
 	"github.com/google/tink/go/mac"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/signature"
 	aeadsubtle "github.com/google/tink/go/subtle/aead"
 	"github.com/stretchr/testify/require"
 	chacha "golang.org/x/crypto/chacha20poly1305"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
 )
 
 const testMessage = "test message"
@@ -167,6 +175,135 @@ func TestCrypto_SignVerify(t *testing.T) {
 	})
 }
 
+func TestCrypto_VerifyAnyPrefix(t *testing.T) {
+	c := Crypto{}
+	msg := []byte(testMessage)
+
+	for name, template := range map[string]*tinkpb.KeyTemplate{
+		"Ed25519": signature.ED25519KeyTemplate(),
+		"P-256":   signature.ECDSAP256KeyTemplate(),
+	} {
+		template := template
+
+		t.Run("test RAW and TINK signatures verify with the same public key for "+name, func(t *testing.T) {
+			tinkKH, err := keyset.NewHandle(template)
+			require.NoError(t, err)
+
+			// the same key with RAW output prefix and another key ID, as built from the public key bytes
+			rawKH := withOutputPrefix(t, tinkKH, tinkpb.OutputPrefixType_RAW)
+
+			tinkSig, err := c.Sign(msg, tinkKH)
+			require.NoError(t, err)
+
+			rawSig, err := c.Sign(msg, rawKH)
+			require.NoError(t, err)
+			require.NotEqual(t, len(tinkSig), len(rawSig))
+
+			tinkPubKH, err := tinkKH.Public()
+			require.NoError(t, err)
+
+			require.NoError(t, c.Verify(tinkSig, msg, tinkPubKH))
+			require.NoError(t, c.Verify(rawSig, msg, tinkPubKH))
+
+			require.Error(t, c.Verify(tinkSig, []byte("other message"), tinkPubKH))
+			require.Error(t, c.Verify(rawSig, []byte("other message"), tinkPubKH))
+
+			rawPubKH, err := rawKH.Public()
+			require.NoError(t, err)
+
+			require.NoError(t, c.Verify(rawSig, msg, rawPubKH))
+			require.Error(t, c.Verify(rawSig, []byte("other message"), rawPubKH))
+
+			// the key material identifies the RAW key, the TINK prefix is ignored
+			require.NoError(t, c.Verify(tinkSig, msg, rawPubKH))
+			require.Error(t, c.Verify(tinkSig, []byte("other message"), rawPubKH))
+		})
+
+		t.Run("test TINK signatures do not verify with the prefix of another key ID for "+name, func(t *testing.T) {
+			kh, err := keyset.NewHandle(template)
+			require.NoError(t, err)
+
+			sig, err := c.Sign(msg, kh)
+			require.NoError(t, err)
+
+			// the same key with another key ID
+			pubKH, err := withOutputPrefix(t, kh, tinkpb.OutputPrefixType_TINK).Public()
+			require.NoError(t, err)
+
+			err = c.Verify(sig, msg, pubKH)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "verify msg")
+		})
+	}
+
+	t.Run("test RAW and TINK signatures verify with the handle built from the public key bytes", func(t *testing.T) {
+		k, err := localkms.New("local-lock://custom/master/key/",
+			mockkms.NewProvider(storage.NewMockStoreProvider(), &noop.NoLock{}))
+		require.NoError(t, err)
+
+		for _, kt := range []kms.KeyType{kms.ED25519Type, kms.ECDSAP256Type} {
+			keyID, kh, err := k.Create(kt)
+			require.NoError(t, err)
+
+			tinkSig, err := c.Sign(msg, withOutputPrefix(t, kh.(*keyset.Handle), tinkpb.OutputPrefixType_TINK))
+			require.NoError(t, err)
+
+			rawSig, err := c.Sign(msg, withOutputPrefix(t, kh.(*keyset.Handle), tinkpb.OutputPrefixType_RAW))
+			require.NoError(t, err)
+
+			pubKey, err := k.ExportPubKeyBytes(keyID)
+			require.NoError(t, err)
+
+			pubKH, err := k.PubKeyBytesToHandle(pubKey, kt)
+			require.NoError(t, err)
+
+			require.NoError(t, c.Verify(tinkSig, msg, pubKH))
+			require.NoError(t, c.Verify(rawSig, msg, pubKH))
+
+			require.Error(t, c.Verify(tinkSig, []byte("other message"), pubKH))
+			require.Error(t, c.Verify(rawSig, []byte("other message"), pubKH))
+		}
+	})
+
+	t.Run("test signature of another key does not verify", func(t *testing.T) {
+		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+		require.NoError(t, err)
+
+		otherKH, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+		require.NoError(t, err)
+
+		sig, err := c.Sign(msg, otherKH)
+		require.NoError(t, err)
+
+		pubKH, err := withOutputPrefix(t, kh, tinkpb.OutputPrefixType_RAW).Public()
+		require.NoError(t, err)
+
+		err = c.Verify(sig, msg, pubKH)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verify msg")
+	})
+}
+
+// withOutputPrefix returns a copy of the keys of kh with the output prefix prefix and other key IDs.
+func withOutputPrefix(t *testing.T, kh *keyset.Handle, prefix tinkpb.OutputPrefixType) *keyset.Handle {
+	t.Helper()
+
+	ks, ok := proto.Clone(insecurecleartextkeyset.KeysetMaterial(kh)).(*tinkpb.Keyset)
+	require.True(t, ok)
+
+	for _, key := range ks.Key {
+		key.OutputPrefixType = prefix
+		key.KeyId++
+	}
+
+	ks.PrimaryKeyId++
+
+	copied, err := insecurecleartextkeyset.Read(&keyset.MemReaderWriter{Keyset: ks})
+	require.NoError(t, err)
+
+	return copied
+}
+
 func TestCrypto_ComputeMAC(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		kh, err := keyset.NewHandle(mac.HMACSHA256Tag256KeyTemplate())
@@ -218,3 +355,84 @@ func TestCrypto_VerifyMAC(t *testing.T) {
 		require.Equal(t, errBadKeyHandleFormat, err)
 	})
 }
+
+func TestCrypto_VerifyMACConstantTime(t *testing.T) {
+	c := Crypto{}
+	msg := []byte(testMessage)
+
+	kh, err := keyset.NewHandle(mac.HMACSHA256Tag256KeyTemplate())
+	require.NoError(t, err)
+
+	oldMAC, err := c.ComputeMAC(msg, kh)
+	require.NoError(t, err)
+
+	km := keyset.NewManagerFromHandle(kh)
+	require.NoError(t, km.Rotate(mac.HMACSHA512Tag256KeyTemplate()))
+
+	rotatedKH, err := km.Handle()
+	require.NoError(t, err)
+
+	newMAC, err := c.ComputeMAC(msg, rotatedKH)
+	require.NoError(t, err)
+	require.NotEqual(t, oldMAC, newMAC)
+
+	t.Run("success with the MACs of the keys of the keyset", func(t *testing.T) {
+		require.NoError(t, c.VerifyMACConstantTime(newMAC, msg, rotatedKH))
+		require.NoError(t, c.VerifyMACConstantTime(oldMAC, msg, rotatedKH))
+		require.NoError(t, c.VerifyMAC(oldMAC, msg, rotatedKH))
+
+		otherKH, err := keyset.NewHandle(mac.HMACSHA256Tag256KeyTemplate())
+		require.NoError(t, err)
+		require.Equal(t, errInvalidMAC, c.VerifyMACConstantTime(newMAC, msg, otherKH))
+	})
+	t.Run("fail - tampered MAC or data", func(t *testing.T) {
+		// flip a bit in the key prefix, in the first byte and in the last byte of the MAC value
+		for _, i := range []int{0, len(newMAC) - 32, len(newMAC) - 1} {
+			tampered := append([]byte{}, newMAC...)
+			tampered[i] ^= 0x01
+
+			require.Equal(t, errInvalidMAC, c.VerifyMACConstantTime(tampered, msg, rotatedKH))
+			require.Equal(t, errInvalidMAC, c.VerifyMAC(tampered, msg, rotatedKH))
+		}
+
+		require.Equal(t, errInvalidMAC, c.VerifyMACConstantTime(newMAC[:len(newMAC)-1], msg, rotatedKH))
+		require.Equal(t, errInvalidMAC, c.VerifyMACConstantTime(nil, msg, rotatedKH))
+		require.Equal(t, errInvalidMAC, c.VerifyMACConstantTime(newMAC, []byte("other message"), rotatedKH))
+	})
+	t.Run("success with the MACs of LEGACY keys", func(t *testing.T) {
+		legacyTemplate := mac.HMACSHA256Tag256KeyTemplate()
+		legacyTemplate.OutputPrefixType = tinkpb.OutputPrefixType_LEGACY
+
+		legacyKH, err := keyset.NewHandle(legacyTemplate)
+		require.NoError(t, err)
+
+		legacyMAC, err := c.ComputeMAC(msg, legacyKH)
+		require.NoError(t, err)
+
+		require.NoError(t, c.VerifyMACConstantTime(legacyMAC, msg, legacyKH))
+		require.NoError(t, c.VerifyMAC(legacyMAC, msg, legacyKH))
+		require.Equal(t, errInvalidMAC, c.VerifyMAC(legacyMAC, []byte("other message"), legacyKH))
+
+		// the MACs of the LEGACY key are still verified once the keyset is rotated to a TINK key
+		km := keyset.NewManagerFromHandle(legacyKH)
+		require.NoError(t, km.Rotate(mac.HMACSHA256Tag256KeyTemplate()))
+
+		rotatedLegacyKH, err := km.Handle()
+		require.NoError(t, err)
+
+		require.NoError(t, c.VerifyMAC(legacyMAC, msg, rotatedLegacyKH))
+	})
+	t.Run("fail - empty data", func(t *testing.T) {
+		require.Equal(t, errInvalidMAC, c.VerifyMACConstantTime(newMAC, nil, rotatedKH))
+	})
+	t.Run("fail - not a MAC key handle", func(t *testing.T) {
+		sigKH, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+		require.NoError(t, err)
+
+		err = c.VerifyMACConstantTime(newMAC, msg, sigKH)
+		require.EqualError(t, err, "verify MAC: mac_factory: not a MAC primitive")
+	})
+	t.Run("bad key handle format", func(t *testing.T) {
+		require.Equal(t, errBadKeyHandleFormat, c.VerifyMACConstantTime(newMAC, msg, nil))
+	})
+}
@@ -22,8 +22,18 @@ type Destination struct {
 	TransportReturnRoute string
 }
 
+// DIDCommV2Destination provides the uri, accepted media types and routing keys of the DIDComm v2 service
+// of a DID.
+type DIDCommV2Destination struct {
+	URI         string
+	Accept      []string
+	RoutingKeys []string
+}
+
 const (
-	didCommServiceType = "did-communication"
+	didCommServiceType   = "did-communication"
+	didCommV2ServiceType = "DIDCommMessaging"
+	didCommV2Accept      = "accept"
 	// TODO: hardcoded key type https://github.com/hyperledger/aries-framework-go/issues/1008
 	ed25519KeyType = "Ed25519VerificationKey2018"
 )
@@ -57,3 +67,58 @@ func CreateDestination(didDoc *diddoc.Doc) (*Destination, error) {
 		RoutingKeys:     didCommService.RoutingKeys,
 	}, nil
 }
+
+// GetDIDCommV2Destination constructs a DIDCommV2Destination based on the given DID.
+// It resolves the DID using the given VDR, and collects the DIDComm v2 service data from the resolved DIDDoc.
+func GetDIDCommV2Destination(did string, vdr vdri.Registry) (*DIDCommV2Destination, error) {
+	didDoc, err := vdr.Resolve(did)
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateDIDCommV2Destination(didDoc)
+}
+
+// CreateDIDCommV2Destination makes a DIDCommV2Destination from the DIDComm v2 (DIDCommMessaging) service of
+// a DID Doc. The first endpoint is used if the service has many. The serviceEndpoint can be an object, an array
+// or a uri (with accept and routingKeys in the service).
+func CreateDIDCommV2Destination(didDoc *diddoc.Doc) (*DIDCommV2Destination, error) {
+	didCommService, ok := diddoc.LookupService(didDoc, didCommV2ServiceType)
+	if !ok {
+		if _, ok = diddoc.LookupService(didDoc, didCommServiceType); ok {
+			return nil, fmt.Errorf("create DIDComm v2 destination: %w", ErrDIDCommV1ServiceOnly)
+		}
+
+		return nil, fmt.Errorf("create DIDComm v2 destination: missing DID doc service")
+	}
+
+	if len(didCommService.StructuredEndpoints) != 0 {
+		endpoint := didCommService.StructuredEndpoints[0]
+
+		return &DIDCommV2Destination{
+			URI:         endpoint.URI,
+			Accept:      endpoint.Accept,
+			RoutingKeys: endpoint.RoutingKeys,
+		}, nil
+	}
+
+	if didCommService.ServiceEndpoint == "" {
+		return nil, fmt.Errorf("create DIDComm v2 destination: missing service endpoint")
+	}
+
+	var accept []string
+
+	if rawAccept, ok := didCommService.Properties[didCommV2Accept].([]interface{}); ok {
+		for _, a := range rawAccept {
+			if mediaType, ok := a.(string); ok {
+				accept = append(accept, mediaType)
+			}
+		}
+	}
+
+	return &DIDCommV2Destination{
+		URI:         didCommService.ServiceEndpoint,
+		Accept:      accept,
+		RoutingKeys: didCommService.RoutingKeys,
+	}, nil
+}
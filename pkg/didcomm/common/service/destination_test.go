@@ -8,6 +8,7 @@ package service
 import (
 	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -91,6 +92,111 @@ func TestPrepareDestination(t *testing.T) {
 	})
 }
 
+func TestDIDCommV2Destination(t *testing.T) {
+	const (
+		uri      = "https://agent.example.com/v2"
+		mediaTyp = "didcomm/v2"
+		routeKey = "did:example:mediator#key-1"
+	)
+
+	v2DIDDoc := func(serviceEndpoint interface{}) *did.Doc {
+		doc := createDIDDoc()
+		doc.Service = append(doc.Service, did.Service{ID: doc.ID + "#didcomm-1", Type: "DIDCommMessaging"})
+
+		docBytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		raw := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(docBytes, &raw))
+
+		rawService := raw["service"].([]interface{})[1].(map[string]interface{})
+		rawService["serviceEndpoint"] = serviceEndpoint
+		rawService["accept"] = []string{mediaTyp}
+		rawService["routingKeys"] = []string{routeKey}
+
+		docBytes, err = json.Marshal(raw)
+		require.NoError(t, err)
+
+		doc, err = did.ParseDocument(docBytes)
+		require.NoError(t, err)
+
+		return doc
+	}
+
+	expected := &DIDCommV2Destination{URI: uri, Accept: []string{mediaTyp}, RoutingKeys: []string{routeKey}}
+
+	t.Run("test serviceEndpoint object", func(t *testing.T) {
+		doc := v2DIDDoc(map[string]interface{}{
+			"uri":         uri,
+			"accept":      []string{mediaTyp},
+			"routingKeys": []string{routeKey},
+		})
+
+		dest, err := GetDIDCommV2Destination(doc.ID, &mockvdri.MockVDRIRegistry{ResolveValue: doc})
+		require.NoError(t, err)
+		require.Equal(t, expected, dest)
+	})
+
+	t.Run("test serviceEndpoint array", func(t *testing.T) {
+		doc := v2DIDDoc([]interface{}{
+			map[string]interface{}{
+				"uri":         uri,
+				"accept":      []string{mediaTyp},
+				"routingKeys": []string{routeKey},
+			},
+			map[string]interface{}{"uri": "wss://agent.example.com/ws"},
+		})
+
+		dest, err := CreateDIDCommV2Destination(doc)
+		require.NoError(t, err)
+		require.Equal(t, expected, dest)
+	})
+
+	t.Run("test serviceEndpoint uri", func(t *testing.T) {
+		dest, err := CreateDIDCommV2Destination(v2DIDDoc(uri))
+		require.NoError(t, err)
+		require.Equal(t, expected, dest)
+	})
+
+	t.Run("test DID doc with only DIDComm v1 service", func(t *testing.T) {
+		doc := createDIDDoc()
+
+		dest, err := GetDIDCommV2Destination(doc.ID, &mockvdri.MockVDRIRegistry{ResolveValue: doc})
+		require.True(t, errors.Is(err, ErrDIDCommV1ServiceOnly))
+		require.Nil(t, dest)
+
+		// the v1 destination is still available
+		_, err = GetDestination(doc.ID, &mockvdri.MockVDRIRegistry{ResolveValue: doc})
+		require.NoError(t, err)
+	})
+
+	t.Run("test DID doc without service", func(t *testing.T) {
+		doc := createDIDDoc()
+		doc.Service = nil
+
+		_, err := CreateDIDCommV2Destination(doc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing DID doc service")
+	})
+
+	t.Run("test service without endpoint", func(t *testing.T) {
+		doc := createDIDDoc()
+		doc.Service = []did.Service{{ID: doc.ID + "#didcomm-1", Type: "DIDCommMessaging"}}
+
+		_, err := CreateDIDCommV2Destination(doc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing service endpoint")
+	})
+
+	t.Run("test did document not found", func(t *testing.T) {
+		_, err := GetDIDCommV2Destination("did:example:123", &mockvdri.MockVDRIRegistry{
+			ResolveErr: errors.New("resolver error"),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolver error")
+	})
+}
+
 func createDIDDoc() *did.Doc {
 	pubKey, _ := generateKeyPair()
 	return createDIDDocWithKey(pubKey)
@@ -42,6 +42,7 @@ type Metadata struct {
 type DIDCommMsgMap map[string]interface{}
 
 // ParseDIDCommMsgMap returns DIDCommMsg with Header
+// DIDComm v2 plaintext messages are converted to the v1 structure, so they are handled the same way.
 func ParseDIDCommMsgMap(payload []byte) (DIDCommMsgMap, error) {
 	var msg DIDCommMsgMap
 
@@ -50,6 +51,10 @@ func ParseDIDCommMsgMap(payload []byte) (DIDCommMsgMap, error) {
 		return nil, fmt.Errorf("invalid payload data format: %w", err)
 	}
 
+	if isDIDCommV2(msg) {
+		msg = fromDIDCommV2(msg)
+	}
+
 	// sets empty metadata
 	msg[jsonMetadata] = map[string]interface{}{}
 
@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DIDCommVersion is the version of the DIDComm plaintext message structure.
+type DIDCommVersion string
+
+const (
+	// DIDCommV1 is the plaintext structure of Aries RFCs with "@id", "@type" and decorators (default).
+	DIDCommV1 DIDCommVersion = "v1"
+	// DIDCommV2 is the DIDComm v2 plaintext structure with "id", "type", "body", "from" and "to".
+	DIDCommV2 DIDCommVersion = "v2"
+)
+
+const (
+	jsonV2ID             = "id"
+	jsonV2Type           = "type"
+	jsonV2Body           = "body"
+	jsonV2From           = "from"
+	jsonV2To             = "to"
+	jsonV2ThreadID       = "thid"
+	jsonV2ParentThreadID = "pthid"
+)
+
+// isDIDCommV2 checks whether the message has the DIDComm v2 plaintext structure.
+func isDIDCommV2(msg map[string]interface{}) bool {
+	if _, ok := msg[jsonType]; ok {
+		return false
+	}
+
+	_, hasType := msg[jsonV2Type].(string)
+	_, hasBody := msg[jsonV2Body].(map[string]interface{})
+
+	return hasType && hasBody
+}
+
+// fromDIDCommV2 converts the DIDComm v2 plaintext message to the v1 structure handled by the services.
+// The body fields are moved to the top level, "id" and "type" become "@id" and "@type", "thid" and "pthid"
+// become the ~thread decorator, "from", "to" and any other headers are kept as they are.
+func fromDIDCommV2(msg map[string]interface{}) DIDCommMsgMap {
+	res := DIDCommMsgMap{}
+
+	for k, v := range msg {
+		switch k {
+		case jsonV2ID, jsonV2Type, jsonV2Body, jsonV2ThreadID, jsonV2ParentThreadID:
+		default:
+			res[k] = v
+		}
+	}
+
+	for k, v := range msg[jsonV2Body].(map[string]interface{}) {
+		res[k] = v
+	}
+
+	res[jsonType] = msg[jsonV2Type]
+
+	if id, ok := msg[jsonV2ID]; ok {
+		res[jsonID] = id
+	}
+
+	thread := map[string]interface{}{}
+
+	if thID, ok := msg[jsonV2ThreadID]; ok {
+		thread[jsonThreadID] = thID
+	}
+
+	if pthID, ok := msg[jsonV2ParentThreadID]; ok {
+		thread[jsonParentThreadID] = pthID
+	}
+
+	if len(thread) != 0 {
+		res[jsonThread] = thread
+	}
+
+	return res
+}
+
+// ToDIDCommV2 converts the (v1) message payload to the DIDComm v2 plaintext structure. "@id", "@type" and the
+// ~thread decorator become "id", "type", "thid" and "pthid", the other fields are moved into "body".
+// The from and to DIDs are optional.
+func ToDIDCommV2(payload []byte, from string, to ...string) (map[string]interface{}, error) {
+	msg := map[string]interface{}{}
+
+	err := json.Unmarshal(payload, &msg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload data format: %w", err)
+	}
+
+	res := map[string]interface{}{
+		jsonV2Type: msg[jsonType],
+	}
+
+	if id, ok := msg[jsonID]; ok {
+		res[jsonV2ID] = id
+	}
+
+	if thread, ok := msg[jsonThread].(map[string]interface{}); ok {
+		if thID, ok := thread[jsonThreadID]; ok {
+			res[jsonV2ThreadID] = thID
+		}
+
+		if pthID, ok := thread[jsonParentThreadID]; ok {
+			res[jsonV2ParentThreadID] = pthID
+		}
+	}
+
+	if from != "" {
+		res[jsonV2From] = from
+	}
+
+	if len(to) != 0 {
+		res[jsonV2To] = to
+	}
+
+	for _, k := range []string{jsonID, jsonType, jsonThread, jsonMetadata} {
+		delete(msg, k)
+	}
+
+	res[jsonV2Body] = msg
+
+	return res, nil
+}
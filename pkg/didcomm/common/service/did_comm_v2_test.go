@@ -0,0 +1,117 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDIDCommMsgMap_V2(t *testing.T) {
+	t.Run("test v2 plaintext message", func(t *testing.T) {
+		msg, err := ParseDIDCommMsgMap([]byte(`{
+			"id": "msg-id",
+			"type": "https://didcomm.org/basicmessage/1.0/message",
+			"thid": "thread-id",
+			"pthid": "parent-thread-id",
+			"from": "did:example:alice",
+			"to": ["did:example:bob"],
+			"~transport": {"return_route": "all"},
+			"body": {"content": "hello"}
+		}`))
+		require.NoError(t, err)
+
+		require.Equal(t, "msg-id", msg.ID())
+		require.Equal(t, "https://didcomm.org/basicmessage/1.0/message", msg.Type())
+		require.Equal(t, "parent-thread-id", msg.ParentThreadID())
+		require.Equal(t, "hello", msg["content"])
+		require.Equal(t, "did:example:alice", msg["from"])
+		require.Equal(t, []interface{}{"did:example:bob"}, msg["to"])
+		require.Equal(t, map[string]interface{}{"return_route": "all"}, msg["~transport"])
+		require.NotNil(t, msg.Metadata())
+		require.NotContains(t, msg, "body")
+
+		thID, err := msg.ThreadID()
+		require.NoError(t, err)
+		require.Equal(t, "thread-id", thID)
+	})
+
+	t.Run("test v2 plaintext message without id and thread", func(t *testing.T) {
+		msg, err := ParseDIDCommMsgMap([]byte(`{"type": "message-type", "body": {}}`))
+		require.NoError(t, err)
+
+		require.Equal(t, "message-type", msg.Type())
+		require.Empty(t, msg.ID())
+		require.NotContains(t, msg, jsonThread)
+	})
+
+	t.Run("test v1 message with type and body fields", func(t *testing.T) {
+		msg, err := ParseDIDCommMsgMap([]byte(`{
+			"@id": "msg-id",
+			"@type": "message-type",
+			"type": "field",
+			"body": {"content": "hello"}
+		}`))
+		require.NoError(t, err)
+
+		require.Equal(t, "message-type", msg.Type())
+		require.Equal(t, "field", msg["type"])
+		require.Equal(t, map[string]interface{}{"content": "hello"}, msg["body"])
+	})
+}
+
+func TestToDIDCommV2(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		payload := []byte(`{
+			"@id": "msg-id",
+			"@type": "https://didcomm.org/basicmessage/1.0/message",
+			"~thread": {"thid": "thread-id", "pthid": "parent-thread-id"},
+			"_internal_metadata": {},
+			"content": "hello"
+		}`)
+
+		msg, err := ToDIDCommV2(payload, "did:example:alice", "did:example:bob")
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"id":    "msg-id",
+			"type":  "https://didcomm.org/basicmessage/1.0/message",
+			"thid":  "thread-id",
+			"pthid": "parent-thread-id",
+			"from":  "did:example:alice",
+			"to":    []string{"did:example:bob"},
+			"body":  map[string]interface{}{"content": "hello"},
+		}, msg)
+
+		// converts back to the original message
+		v2Payload, err := json.Marshal(msg)
+		require.NoError(t, err)
+
+		v1Msg, err := ParseDIDCommMsgMap(v2Payload)
+		require.NoError(t, err)
+		require.Equal(t, "msg-id", v1Msg.ID())
+		require.Equal(t, "https://didcomm.org/basicmessage/1.0/message", v1Msg.Type())
+		require.Equal(t, "parent-thread-id", v1Msg.ParentThreadID())
+		require.Equal(t, "hello", v1Msg["content"])
+	})
+
+	t.Run("test without from and to", func(t *testing.T) {
+		msg, err := ToDIDCommV2([]byte(`{"@type": "message-type"}`), "")
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"type": "message-type",
+			"body": map[string]interface{}{},
+		}, msg)
+	})
+
+	t.Run("test invalid payload", func(t *testing.T) {
+		_, err := ToDIDCommV2([]byte("invalid json"), "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid payload data format")
+	})
+}
@@ -14,6 +14,9 @@ const (
 	ErrThreadIDNotFound  = serviceError("threadID not found")
 	ErrInvalidMessage    = serviceError("invalid message")
 	ErrNilMessage        = serviceError("message is nil")
+	// ErrDIDCommV1ServiceOnly is returned when a DIDComm v2 service is requested but the DID doc has only
+	// a DIDComm v1 (did-communication) service
+	ErrDIDCommV1ServiceOnly = serviceError("DID doc has only a DIDComm v1 (did-communication) service")
 )
 
 // serviceError defines service error
@@ -0,0 +1,221 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dispatcher
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+)
+
+var logger = log.New("aries-framework/dispatcher")
+
+// ErrInboundQueueClosed is returned when a message is queued after the inbound queue is closed.
+var ErrInboundQueueClosed = errors.New("inbound queue closed")
+
+// InboundQueueStats are the metrics of an inbound queue.
+type InboundQueueStats struct {
+	// Workers is the number of workers handling the queued messages
+	Workers int `json:"workers"`
+	// Capacity is the maximum number of queued messages
+	Capacity int `json:"capacity"`
+	// Depth is the number of messages waiting in the queue
+	Depth int `json:"depth"`
+	// Active is the number of messages being handled by the workers
+	Active int64 `json:"active"`
+	// Processed is the number of messages handled successfully
+	Processed int64 `json:"processed"`
+	// Failed is the number of messages that failed to be handled
+	Failed int64 `json:"failed"`
+}
+
+// InboundErrorHandler is notified of the errors of the handlers of the queued inbound messages, with the messages
+// that failed to be handled.
+type InboundErrorHandler func(err error, message []byte, myDID, theirDID string)
+
+// InboundQueueOption configures the inbound queue.
+type InboundQueueOption func(q *InboundQueue)
+
+// WithInboundErrorHandler notifies errHandler of the errors of the handlers of the queued inbound messages. The
+// handling errors are logged if no error handler is set.
+func WithInboundErrorHandler(errHandler InboundErrorHandler) InboundQueueOption {
+	return func(q *InboundQueue) {
+		q.errHandler = errHandler
+	}
+}
+
+type inboundMessage struct {
+	handler  transport.InboundMessageHandler
+	message  []byte
+	myDID    string
+	theirDID string
+}
+
+// InboundQueue is a bounded queue of inbound messages handled by a fixed pool of workers, so bursts of inbound
+// messages are handled with bounded concurrency. Messages are queued until the queue is full, then queueing blocks
+// the inbound transports until a worker frees a slot (back-pressure). The workers are started by Start and stopped
+// by Close.
+type InboundQueue struct {
+	queue      chan *inboundMessage
+	done       chan struct{}
+	lock       sync.Mutex
+	started    bool
+	closed     bool
+	wg         sync.WaitGroup
+	workers    int
+	errHandler InboundErrorHandler
+	active     int64
+	processed  int64
+	failed     int64
+}
+
+// NewInboundQueue returns an inbound queue of size messages to be handled by workers workers. The workers aren't
+// started until Start is called: messages are queued meanwhile.
+func NewInboundQueue(workers, size int, opts ...InboundQueueOption) (*InboundQueue, error) {
+	if workers <= 0 {
+		return nil, fmt.Errorf("invalid number of inbound queue workers %d", workers)
+	}
+
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid inbound queue size %d", size)
+	}
+
+	q := &InboundQueue{
+		queue:   make(chan *inboundMessage, size),
+		done:    make(chan struct{}),
+		workers: workers,
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q, nil
+}
+
+// Start starts the workers handling the queued messages. Starting a started or closed queue is a no-op.
+func (q *InboundQueue) Start() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.started || q.closed {
+		return
+	}
+
+	q.started = true
+
+	q.wg.Add(q.workers)
+
+	for i := 0; i < q.workers; i++ {
+		go q.work()
+	}
+}
+
+// Handler returns an inbound message handler queueing the messages to be handled by handler. The returned handler
+// blocks while the queue is full, and returns once the message is queued: the errors of handler aren't returned to
+// the inbound transports, they are passed to the error handler of the queue (see WithInboundErrorHandler).
+func (q *InboundQueue) Handler(handler transport.InboundMessageHandler) transport.InboundMessageHandler {
+	return func(message []byte, myDID, theirDID string) error {
+		return q.enqueue(&inboundMessage{handler: handler, message: message, myDID: myDID, theirDID: theirDID})
+	}
+}
+
+// Stats returns the metrics of the queue.
+func (q *InboundQueue) Stats() *InboundQueueStats {
+	return &InboundQueueStats{
+		Workers:   q.workers,
+		Capacity:  cap(q.queue),
+		Depth:     len(q.queue),
+		Active:    atomic.LoadInt64(&q.active),
+		Processed: atomic.LoadInt64(&q.processed),
+		Failed:    atomic.LoadInt64(&q.failed),
+	}
+}
+
+// Close stops queueing messages, and waits for the workers to handle the queued messages. The messages queued in a
+// queue that was never started are dropped.
+func (q *InboundQueue) Close() {
+	q.lock.Lock()
+
+	if !q.closed {
+		q.closed = true
+
+		close(q.done)
+	}
+
+	q.lock.Unlock()
+
+	q.wg.Wait()
+}
+
+func (q *InboundQueue) enqueue(msg *inboundMessage) error {
+	// a closed queue rejects the messages even if it has free slots
+	select {
+	case <-q.done:
+		return ErrInboundQueueClosed
+	default:
+	}
+
+	select {
+	case q.queue <- msg:
+		return nil
+	case <-q.done:
+		return ErrInboundQueueClosed
+	}
+}
+
+func (q *InboundQueue) work() {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case msg := <-q.queue:
+			q.handle(msg)
+		case <-q.done:
+			q.drain()
+			return
+		}
+	}
+}
+
+// drain handles the messages left in the queue once it is closed.
+func (q *InboundQueue) drain() {
+	for {
+		select {
+		case msg := <-q.queue:
+			q.handle(msg)
+		default:
+			return
+		}
+	}
+}
+
+func (q *InboundQueue) handle(msg *inboundMessage) {
+	atomic.AddInt64(&q.active, 1)
+	defer atomic.AddInt64(&q.active, -1)
+
+	err := msg.handler(msg.message, msg.myDID, msg.theirDID)
+	if err != nil {
+		atomic.AddInt64(&q.failed, 1)
+
+		if q.errHandler != nil {
+			q.errHandler(err, msg.message, msg.myDID, msg.theirDID)
+
+			return
+		}
+
+		logger.Errorf("failed to handle inbound message: %s", err)
+
+		return
+	}
+
+	atomic.AddInt64(&q.processed, 1)
+}
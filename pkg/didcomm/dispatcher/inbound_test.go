@@ -0,0 +1,288 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dispatcher
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInboundQueue(t *testing.T) {
+	t.Run("test invalid workers", func(t *testing.T) {
+		_, err := NewInboundQueue(0, 1)
+		require.EqualError(t, err, "invalid number of inbound queue workers 0")
+	})
+
+	t.Run("test invalid size", func(t *testing.T) {
+		_, err := NewInboundQueue(1, -1)
+		require.EqualError(t, err, "invalid inbound queue size -1")
+	})
+}
+
+func TestInboundQueue(t *testing.T) {
+	const (
+		workers = 2
+		size    = 3
+	)
+
+	t.Run("test flooding the queue", func(t *testing.T) {
+		q, err := NewInboundQueue(workers, size)
+		require.NoError(t, err)
+
+		q.Start()
+
+		release := make(chan struct{})
+
+		var active, maxActive, handled int64
+
+		handler := q.Handler(func(message []byte, myDID, theirDID string) error {
+			current := atomic.AddInt64(&active, 1)
+			defer atomic.AddInt64(&active, -1)
+
+			for {
+				max := atomic.LoadInt64(&maxActive)
+				if current <= max || atomic.CompareAndSwapInt64(&maxActive, max, current) {
+					break
+				}
+			}
+
+			<-release
+
+			atomic.AddInt64(&handled, 1)
+
+			return nil
+		})
+
+		const messages = 20
+
+		var queued int64
+
+		var wg sync.WaitGroup
+
+		wg.Add(messages)
+
+		for i := 0; i < messages; i++ {
+			go func() {
+				defer wg.Done()
+
+				require.NoError(t, handler([]byte("{}"), "myDID", "theirDID"))
+				atomic.AddInt64(&queued, 1)
+			}()
+		}
+
+		// the workers are busy and the queue is full: the other messages are held back
+		require.Eventually(t, func() bool {
+			stats := q.Stats()
+			return stats.Active == workers && stats.Depth == size
+		}, time.Second, 10*time.Millisecond)
+
+		time.Sleep(50 * time.Millisecond)
+		require.EqualValues(t, workers+size, atomic.LoadInt64(&queued))
+		require.EqualValues(t, workers, atomic.LoadInt64(&maxActive))
+
+		close(release)
+		wg.Wait()
+
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt64(&handled) == messages
+		}, time.Second, 10*time.Millisecond)
+
+		q.Close()
+
+		stats := q.Stats()
+		require.Equal(t, &InboundQueueStats{
+			Workers:   workers,
+			Capacity:  size,
+			Processed: messages,
+		}, stats)
+		require.EqualValues(t, workers, atomic.LoadInt64(&maxActive))
+	})
+
+	t.Run("test handling errors are counted", func(t *testing.T) {
+		q, err := NewInboundQueue(workers, size)
+		require.NoError(t, err)
+
+		q.Start()
+
+		handler := q.Handler(func(message []byte, myDID, theirDID string) error {
+			return errors.New("handle error")
+		})
+
+		require.NoError(t, handler([]byte("{}"), "myDID", "theirDID"))
+
+		q.Close()
+		require.EqualValues(t, 1, q.Stats().Failed)
+	})
+
+	t.Run("test close handles the queued messages", func(t *testing.T) {
+		q, err := NewInboundQueue(1, size)
+		require.NoError(t, err)
+
+		q.Start()
+
+		release := make(chan struct{})
+
+		var handled int64
+
+		handler := q.Handler(func(message []byte, myDID, theirDID string) error {
+			<-release
+			atomic.AddInt64(&handled, 1)
+
+			return nil
+		})
+
+		for i := 0; i < size+1; i++ {
+			require.NoError(t, handler([]byte("{}"), "myDID", "theirDID"))
+		}
+
+		closed := make(chan struct{})
+
+		go func() {
+			q.Close()
+			close(closed)
+		}()
+
+		require.Eventually(t, func() bool {
+			return errors.Is(handler([]byte("{}"), "myDID", "theirDID"), ErrInboundQueueClosed)
+		}, time.Second, 10*time.Millisecond)
+
+		close(release)
+
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			require.FailNow(t, "inbound queue not closed")
+		}
+
+		require.EqualValues(t, size+1, atomic.LoadInt64(&handled))
+
+		// closing again is a no-op
+		q.Close()
+	})
+
+	t.Run("test queueing blocked by a full queue is released on close", func(t *testing.T) {
+		q, err := NewInboundQueue(1, 1)
+		require.NoError(t, err)
+
+		q.Start()
+
+		release := make(chan struct{})
+
+		handler := q.Handler(func(message []byte, myDID, theirDID string) error {
+			<-release
+			return nil
+		})
+
+		// one message handled and one queued
+		require.NoError(t, handler([]byte("{}"), "myDID", "theirDID"))
+		require.Eventually(t, func() bool {
+			return q.Stats().Active == 1
+		}, time.Second, 10*time.Millisecond)
+		require.NoError(t, handler([]byte("{}"), "myDID", "theirDID"))
+
+		blocked := make(chan error)
+
+		go func() {
+			blocked <- handler([]byte("{}"), "myDID", "theirDID")
+		}()
+
+		select {
+		case <-blocked:
+			require.FailNow(t, "message queued in a full queue")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		go q.Close()
+
+		select {
+		case err := <-blocked:
+			require.True(t, errors.Is(err, ErrInboundQueueClosed))
+		case <-time.After(time.Second):
+			require.FailNow(t, "blocked message not released on close")
+		}
+
+		close(release)
+	})
+	t.Run("test handling errors are passed to the error handler", func(t *testing.T) {
+		handleErr := errors.New("handle error")
+
+		var errs []error
+
+		q, err := NewInboundQueue(1, size, WithInboundErrorHandler(func(err error, message []byte, myDID, theirDID string) {
+			require.Equal(t, []byte("{}"), message)
+			require.Equal(t, "myDID", myDID)
+			require.Equal(t, "theirDID", theirDID)
+
+			errs = append(errs, err)
+		}))
+		require.NoError(t, err)
+
+		q.Start()
+
+		handler := q.Handler(func(message []byte, myDID, theirDID string) error {
+			return handleErr
+		})
+
+		require.NoError(t, handler([]byte("{}"), "myDID", "theirDID"))
+
+		q.Close()
+		require.Equal(t, []error{handleErr}, errs)
+		require.EqualValues(t, 1, q.Stats().Failed)
+	})
+
+	t.Run("test messages are handled once the queue is started", func(t *testing.T) {
+		q, err := NewInboundQueue(workers, size)
+		require.NoError(t, err)
+
+		var handled int64
+
+		handler := q.Handler(func(message []byte, myDID, theirDID string) error {
+			atomic.AddInt64(&handled, 1)
+			return nil
+		})
+
+		require.NoError(t, handler([]byte("{}"), "myDID", "theirDID"))
+
+		time.Sleep(50 * time.Millisecond)
+		require.EqualValues(t, 0, atomic.LoadInt64(&handled))
+		require.Equal(t, 1, q.Stats().Depth)
+
+		q.Start()
+		// starting again is a no-op
+		q.Start()
+
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt64(&handled) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		q.Close()
+	})
+
+	t.Run("test a closed queue isn't started", func(t *testing.T) {
+		q, err := NewInboundQueue(workers, size)
+		require.NoError(t, err)
+
+		handler := q.Handler(func(message []byte, myDID, theirDID string) error {
+			require.FailNow(t, "message handled by a closed queue")
+			return nil
+		})
+
+		require.NoError(t, handler([]byte("{}"), "myDID", "theirDID"))
+
+		q.Close()
+		q.Start()
+
+		time.Sleep(50 * time.Millisecond)
+		require.EqualValues(t, 0, q.Stats().Active)
+		require.True(t, errors.Is(handler([]byte("{}"), "myDID", "theirDID"), ErrInboundQueueClosed))
+	})
+}
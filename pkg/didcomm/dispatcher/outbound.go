@@ -8,6 +8,7 @@ package dispatcher
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
 // provider interface for outbound ctx
@@ -39,17 +41,41 @@ type OutboundDispatcher struct {
 	transportReturnRoute string
 	vdRegistry           vdri.Registry
 	kms                  legacykms.KeyManager
+	versionLookup        DIDCommVersionLookup
+}
+
+// DIDCommVersionLookup looks up the DIDComm version selected for the connection between the DIDs.
+// It returns an error wrapping storage.ErrDataNotFound if there is no connection.
+type DIDCommVersionLookup interface {
+	GetDIDCommVersionByDIDs(myDID, theirDID string) (string, error)
+}
+
+// OutboundOption configures the outbound dispatcher.
+type OutboundOption func(opts *OutboundDispatcher)
+
+// WithDIDCommVersionLookup option is for sending the messages to DIDs with the DIDComm plaintext structure
+// selected for their connection (eg. connection.Lookup). Messages are sent with the v1 structure by default.
+func WithDIDCommVersionLookup(l DIDCommVersionLookup) OutboundOption {
+	return func(opts *OutboundDispatcher) {
+		opts.versionLookup = l
+	}
 }
 
 // NewOutbound return new dispatcher outbound instance
-func NewOutbound(prov provider) *OutboundDispatcher {
-	return &OutboundDispatcher{
+func NewOutbound(prov provider, opts ...OutboundOption) *OutboundDispatcher {
+	o := &OutboundDispatcher{
 		outboundTransports:   prov.OutboundTransports(),
 		packager:             prov.Packager(),
 		transportReturnRoute: prov.TransportReturnRoute(),
 		vdRegistry:           prov.VDRIRegistry(),
 		kms:                  prov.LegacyKMS(),
 	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
 }
 
 // SendToDID sends a message from myDID to the agent who owns theirDID
@@ -59,6 +85,11 @@ func (o *OutboundDispatcher) SendToDID(msg interface{}, myDID, theirDID string)
 		return err
 	}
 
+	msg, err = o.toConnectionVersion(msg, myDID, theirDID)
+	if err != nil {
+		return fmt.Errorf("send to DID : %w", err)
+	}
+
 	src, err := service.GetDestination(myDID, o.vdRegistry)
 	if err != nil {
 		return err
@@ -72,6 +103,34 @@ func (o *OutboundDispatcher) SendToDID(msg interface{}, myDID, theirDID string)
 	return o.Send(msg, key, dest)
 }
 
+// toConnectionVersion converts the message to the DIDComm v2 plaintext structure if it is selected for
+// the connection between the DIDs.
+func (o *OutboundDispatcher) toConnectionVersion(msg interface{}, myDID, theirDID string) (interface{}, error) {
+	if o.versionLookup == nil {
+		return msg, nil
+	}
+
+	version, err := o.versionLookup.GetDIDCommVersionByDIDs(myDID, theirDID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return msg, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get DIDComm version : %w", err)
+	}
+
+	if service.DIDCommVersion(version) != service.DIDCommV2 {
+		return msg, nil
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed marshal to bytes: %w", err)
+	}
+
+	return service.ToDIDCommV2(payload, myDID, theirDID)
+}
+
 // Send sends the message after packing with the sender key and recipient keys.
 func (o *OutboundDispatcher) Send(msg interface{}, senderVerKey string, des *service.Destination) error {
 	for _, v := range o.outboundTransports {
@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 	mockdiddoc "github.com/hyperledger/aries-framework-go/pkg/mock/diddoc"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
 func TestOutboundDispatcher_Send(t *testing.T) {
@@ -156,6 +157,56 @@ func TestOutboundDispatcher_SendToDID(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "resolve error")
 	})
+
+	t.Run("connection set to DIDComm v2", func(t *testing.T) {
+		const myDID, theirDID = "did:example:alice", "did:example:bob"
+
+		packager := &mockPackager{}
+
+		o := NewOutbound(&mockProvider{
+			packagerValue: packager,
+			vdriRegistry:  &mockvdri.MockVDRIRegistry{ResolveValue: mockDoc},
+			outboundTransportsValue: []transport.OutboundTransport{
+				&mockdidcomm.MockOutboundTransport{AcceptValue: true},
+			},
+		}, WithDIDCommVersionLookup(&mockVersionLookup{
+			versions: map[string]string{myDID + theirDID: string(service.DIDCommV2)},
+		}))
+
+		msg := map[string]interface{}{
+			"@id":     "msg-id",
+			"@type":   "https://didcomm.org/basicmessage/1.0/message",
+			"content": "hello",
+		}
+
+		require.NoError(t, o.SendToDID(msg, myDID, theirDID))
+		require.JSONEq(t, `{
+			"id": "msg-id",
+			"type": "https://didcomm.org/basicmessage/1.0/message",
+			"from": "did:example:alice",
+			"to": ["did:example:bob"],
+			"body": {"content": "hello"}
+		}`, string(packager.packedMessages[0]))
+
+		// no connection, uses the v1 structure (the message and its forward message are packed for each send)
+		require.NoError(t, o.SendToDID(msg, theirDID, myDID))
+		require.JSONEq(t, `{
+			"@id": "msg-id",
+			"@type": "https://didcomm.org/basicmessage/1.0/message",
+			"content": "hello"
+		}`, string(packager.packedMessages[2]))
+	})
+
+	t.Run("DIDComm version lookup error", func(t *testing.T) {
+		o := NewOutbound(&mockProvider{
+			packagerValue: &mockpackager.Packager{},
+			vdriRegistry:  &mockvdri.MockVDRIRegistry{ResolveValue: mockDoc},
+		}, WithDIDCommVersionLookup(&mockVersionLookup{err: errors.New("lookup error")}))
+
+		err := o.SendToDID("data", "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "lookup error")
+	})
 }
 
 func TestOutboundDispatcherTransportReturnRoute(t *testing.T) {
@@ -347,9 +398,12 @@ func (o *mockOutboundTransport) Accept(url string) bool {
 
 // mockPackager mock packager
 type mockPackager struct {
+	packedMessages [][]byte
 }
 
 func (m *mockPackager) PackMessage(e *commontransport.Envelope) ([]byte, error) {
+	m.packedMessages = append(m.packedMessages, e.Message)
+
 	return e.Message, nil
 }
 
@@ -357,6 +411,25 @@ func (m *mockPackager) UnpackMessage(encMessage []byte) (*commontransport.Envelo
 	return nil, nil
 }
 
+// mockVersionLookup mock DIDComm version lookup
+type mockVersionLookup struct {
+	versions map[string]string
+	err      error
+}
+
+func (m *mockVersionLookup) GetDIDCommVersionByDIDs(myDID, theirDID string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+
+	version, ok := m.versions[myDID+theirDID]
+	if !ok {
+		return "", fmt.Errorf("get did-connection map : %w", storage.ErrDataNotFound)
+	}
+
+	return version, nil
+}
+
 // mockKMS mock Key Management Service (LegacyKMS)
 type mockKMS struct {
 	CreateEncryptionKeyValue string
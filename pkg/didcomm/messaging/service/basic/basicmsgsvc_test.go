@@ -116,6 +116,42 @@ func TestMessageService_HandleInbound(t *testing.T) {
 		}
 	})
 
+	t.Run("test MessageService.HandleInbound() with DIDComm v2 message", func(t *testing.T) {
+		const jsonStr = `{
+			    "id": "123456780",
+			    "type": "https://didcomm.org/basicmessage/1.0/message",
+			    "from": "sample-their-did",
+			    "to": ["sample-my-did"],
+			    "body": { "~l10n": { "locale": "en" }, "content": "Your hovercraft is full of eels." }
+			}`
+
+		testCh := make(chan Message)
+
+		svc, err := NewMessageService("sample-name", func(message Message, myDID, theirDID string) error {
+			testCh <- message
+			return nil
+		})
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap([]byte(jsonStr))
+		require.NoError(t, err)
+		require.True(t, svc.Accept(msg.Type(), nil))
+
+		go func() {
+			_, err := svc.HandleInbound(msg, myDID, theirDID)
+			require.NoError(t, err)
+		}()
+
+		select {
+		case message := <-testCh:
+			require.Equal(t, "en", message.I10n.Locale)
+			require.Equal(t, "Your hovercraft is full of eels.", message.Content)
+			require.Equal(t, "123456780", message.ID)
+		case <-time.After(2 * time.Second):
+			require.Fail(t, "didn't receive basic message to handle")
+		}
+	})
+
 	t.Run("test MessageService.HandleInbound() error", func(t *testing.T) {
 		const sampleErr = "sample-error"
 		svc, err := NewMessageService("sample-name", getMockMessageHandle())
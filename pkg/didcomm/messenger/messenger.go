@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/google/uuid"
 
@@ -48,8 +49,10 @@ type Provider interface {
 
 // Messenger describes the messenger structure
 type Messenger struct {
-	store      storage.Store
-	dispatcher dispatcher.Outbound
+	store        storage.Store
+	dispatcher   dispatcher.Outbound
+	receipts     map[string]*receipt
+	receiptsLock sync.Mutex
 }
 
 // NewMessenger returns a new instance of the Messenger
@@ -62,6 +65,7 @@ func NewMessenger(ctx Provider) (*Messenger, error) {
 	return &Messenger{
 		store:      store,
 		dispatcher: ctx.OutboundDispatcher(),
+		receipts:   make(map[string]*receipt),
 	}, nil
 }
 
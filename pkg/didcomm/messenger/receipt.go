@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package messenger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+)
+
+const (
+	// AckMsgType is the type of the acknowledgements sent for the messages requesting one (see SendWithReceipt).
+	// https://github.com/hyperledger/aries-rfcs/tree/master/features/0015-acks
+	AckMsgType = "https://didcomm.org/notification/1.0/ack"
+
+	// AckStatusOK is the status of the acknowledgements of the messages received and processed.
+	AckStatusOK = "OK"
+
+	jsonPleaseAck = "~please_ack"
+)
+
+// ErrReceiptTimeout is returned by SendWithReceipt when the message is not acknowledged in time.
+var ErrReceiptTimeout = errors.New("receipt timeout")
+
+// ack is the acknowledgement of a message.
+type ack struct {
+	ID     string            `json:"@id,omitempty"`
+	Type   string            `json:"@type,omitempty"`
+	Status string            `json:"status,omitempty"`
+	Thread *decorator.Thread `json:"~thread,omitempty"`
+}
+
+// receipt is awaited by SendWithReceipt for a message sent from myDID to theirDID.
+type receipt struct {
+	received chan struct{}
+	myDID    string
+	theirDID string
+}
+
+// SendWithReceipt sends the message by starting a new thread, like Send, requesting an acknowledgement with the
+// ~please_ack decorator. It blocks until the acknowledgement of the message (matched by the message @id) is received
+// or the timeout expires, then it returns an error wrapping ErrReceiptTimeout.
+func (m *Messenger) SendWithReceipt(msg service.DIDCommMsgMap, myDID, theirDID string, timeout time.Duration) error {
+	// fills missing fields
+	fillIfMissing(msg)
+
+	msg[jsonPleaseAck] = &decorator.PleaseAck{}
+
+	r := m.awaitReceipt(msg.ID(), myDID, theirDID)
+	defer m.removeReceipt(msg.ID())
+
+	if err := m.Send(msg, myDID, theirDID); err != nil {
+		return err
+	}
+
+	select {
+	case <-r.received:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("message %s: %w", msg.ID(), ErrReceiptTimeout)
+	}
+}
+
+// HandleAck handles an inbound acknowledgement, received by myDID from theirDID, it releases the SendWithReceipt
+// awaiting it and returns true: the acknowledgement is consumed. Acknowledgements no one awaits, eg: received after
+// the timeout or acknowledging a message not sent with SendWithReceipt, are not consumed, they are left to the
+// services handling acknowledgements. An acknowledgement not sent by the DID the message was sent to, or not sent to
+// the DID the message was sent from, is rejected.
+func (m *Messenger) HandleAck(msg service.DIDCommMsgMap, myDID, theirDID string) (bool, error) {
+	thID, err := msg.ThreadID()
+	if err != nil {
+		return false, fmt.Errorf("threadID: %w", err)
+	}
+
+	m.receiptsLock.Lock()
+	defer m.receiptsLock.Unlock()
+
+	r, ok := m.receipts[thID]
+	if !ok {
+		return false, nil
+	}
+
+	if r.myDID != myDID || r.theirDID != theirDID {
+		return false, fmt.Errorf("ack of message %s: received by %s from %s, the message was sent by %s to %s",
+			thID, myDID, theirDID, r.myDID, r.theirDID)
+	}
+
+	close(r.received)
+	delete(m.receipts, thID)
+
+	return true, nil
+}
+
+// AckIfRequested acknowledges the inbound message, once processed, if it requests an acknowledgement with the
+// ~please_ack decorator.
+func (m *Messenger) AckIfRequested(msg service.DIDCommMsgMap, myDID, theirDID string) error {
+	if _, ok := msg[jsonPleaseAck]; !ok {
+		return nil
+	}
+
+	thID, err := msg.ThreadID()
+	if err != nil {
+		return fmt.Errorf("threadID: %w", err)
+	}
+
+	return m.dispatcher.SendToDID(&ack{
+		ID:     uuid.New().String(),
+		Type:   AckMsgType,
+		Status: AckStatusOK,
+		Thread: &decorator.Thread{ID: thID},
+	}, myDID, theirDID)
+}
+
+func (m *Messenger) awaitReceipt(msgID, myDID, theirDID string) *receipt {
+	r := &receipt{received: make(chan struct{}), myDID: myDID, theirDID: theirDID}
+
+	m.receiptsLock.Lock()
+	m.receipts[msgID] = r
+	m.receiptsLock.Unlock()
+
+	return r
+}
+
+func (m *Messenger) removeReceipt(msgID string) {
+	m.receiptsLock.Lock()
+	delete(m.receipts, msgID)
+	m.receiptsLock.Unlock()
+}
@@ -0,0 +1,161 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package messenger
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	dispatcherMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/dispatcher"
+	messengerMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/messenger"
+	storageMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/storage"
+)
+
+func TestMessenger_SendWithReceipt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newMessenger := func(outbound *dispatcherMocks.MockOutbound) *Messenger {
+		storageProvider := storageMocks.NewMockProvider(ctrl)
+		storageProvider.EXPECT().OpenStore(gomock.Any()).Return(nil, nil)
+
+		provider := messengerMocks.NewMockProvider(ctrl)
+		provider.EXPECT().StorageProvider().Return(storageProvider)
+		provider.EXPECT().OutboundDispatcher().Return(outbound)
+
+		msgr, err := NewMessenger(provider)
+		require.NoError(t, err)
+
+		return msgr
+	}
+
+	t.Run("test receipt of a message acknowledged by the peer", func(t *testing.T) {
+		senderOutbound := dispatcherMocks.NewMockOutbound(ctrl)
+		peerOutbound := dispatcherMocks.NewMockOutbound(ctrl)
+
+		sender := newMessenger(senderOutbound)
+		peer := newMessenger(peerOutbound)
+
+		// the peer processes the message and acknowledges it
+		senderOutbound.EXPECT().SendToDID(gomock.Any(), myDID, theirDID).
+			Do(func(msg interface{}, myDID, theirDID string) {
+				received := toDIDCommMsgMap(t, msg)
+				require.Contains(t, received, jsonPleaseAck)
+
+				go func() {
+					require.NoError(t, peer.AckIfRequested(received, theirDID, myDID))
+				}()
+			})
+
+		peerOutbound.EXPECT().SendToDID(gomock.Any(), theirDID, myDID).
+			Do(func(msg interface{}, myDID, theirDID string) {
+				ack := toDIDCommMsgMap(t, msg)
+				require.Equal(t, AckMsgType, ack.Type())
+				require.Equal(t, AckStatusOK, ack["status"])
+
+				// the ack is sent by the peer (myDID here) to the sender (theirDID here)
+				consumed, err := sender.HandleAck(ack, theirDID, myDID)
+				require.NoError(t, err)
+				require.True(t, consumed)
+			})
+
+		err := sender.SendWithReceipt(service.DIDCommMsgMap{jsonID: ID}, myDID, theirDID, time.Second)
+		require.NoError(t, err)
+		require.Empty(t, sender.receipts)
+	})
+
+	t.Run("test receipt timeout when the peer does not acknowledge", func(t *testing.T) {
+		outbound := dispatcherMocks.NewMockOutbound(ctrl)
+		outbound.EXPECT().SendToDID(gomock.Any(), myDID, theirDID).Return(nil)
+
+		sender := newMessenger(outbound)
+
+		err := sender.SendWithReceipt(service.DIDCommMsgMap{jsonID: ID}, myDID, theirDID, 50*time.Millisecond)
+		require.True(t, errors.Is(err, ErrReceiptTimeout))
+		require.Contains(t, err.Error(), ID)
+		require.Empty(t, sender.receipts)
+
+		// a late ack is not consumed
+		consumed, err := sender.HandleAck(service.DIDCommMsgMap{
+			jsonID: "ack", jsonThread: map[string]interface{}{jsonThreadID: ID},
+		}, myDID, theirDID)
+		require.NoError(t, err)
+		require.False(t, consumed)
+	})
+
+	t.Run("test ack from another DID is rejected", func(t *testing.T) {
+		outbound := dispatcherMocks.NewMockOutbound(ctrl)
+		sender := newMessenger(outbound)
+
+		ack := service.DIDCommMsgMap{jsonID: "ack", jsonThread: map[string]interface{}{jsonThreadID: ID}}
+
+		outbound.EXPECT().SendToDID(gomock.Any(), myDID, theirDID).
+			Do(func(msg interface{}, myDID, theirDID string) {
+				go func() {
+					consumed, err := sender.HandleAck(ack, myDID, "did:example:other")
+					require.Error(t, err)
+					require.Contains(t, err.Error(), "the message was sent by")
+					require.False(t, consumed)
+
+					_, err = sender.HandleAck(ack, "did:example:other", theirDID)
+					require.Error(t, err)
+
+					consumed, err = sender.HandleAck(ack, myDID, theirDID)
+					require.NoError(t, err)
+					require.True(t, consumed)
+				}()
+			})
+
+		err := sender.SendWithReceipt(service.DIDCommMsgMap{jsonID: ID}, myDID, theirDID, time.Second)
+		require.NoError(t, err)
+	})
+
+	t.Run("test send error", func(t *testing.T) {
+		outbound := dispatcherMocks.NewMockOutbound(ctrl)
+		outbound.EXPECT().SendToDID(gomock.Any(), myDID, theirDID).Return(errors.New(errMsg))
+
+		sender := newMessenger(outbound)
+
+		err := sender.SendWithReceipt(service.DIDCommMsgMap{}, myDID, theirDID, time.Second)
+		require.EqualError(t, err, errMsg)
+		require.Empty(t, sender.receipts)
+	})
+
+	t.Run("test messages not requesting an ack are not acknowledged", func(t *testing.T) {
+		msgr := newMessenger(dispatcherMocks.NewMockOutbound(ctrl))
+
+		require.NoError(t, msgr.AckIfRequested(service.DIDCommMsgMap{jsonID: ID}, myDID, theirDID))
+	})
+
+	t.Run("test ack without thread", func(t *testing.T) {
+		msgr := newMessenger(dispatcherMocks.NewMockOutbound(ctrl))
+
+		_, err := msgr.HandleAck(service.DIDCommMsgMap{}, myDID, theirDID)
+		require.Error(t, err)
+		require.Error(t, msgr.AckIfRequested(service.DIDCommMsgMap{jsonPleaseAck: map[string]interface{}{}},
+			myDID, theirDID))
+	})
+}
+
+// toDIDCommMsgMap returns the message msg as received by the peer.
+func toDIDCommMsgMap(t *testing.T, msg interface{}) service.DIDCommMsgMap {
+	t.Helper()
+
+	raw, err := json.Marshal(msg)
+	require.NoError(t, err)
+
+	received, err := service.ParseDIDCommMsgMap(raw)
+	require.NoError(t, err)
+
+	return received
+}
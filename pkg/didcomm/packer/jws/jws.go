@@ -0,0 +1,47 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+)
+
+// encodingType is the `typ` string identifier in a message that identifies the format as being a signed message
+const encodingType string = "application/didcomm-signed+json"
+
+// algEdDSA is the JWS algorithm of the signatures made with the (Ed25519) sender keys
+const algEdDSA = "EdDSA"
+
+// Provider contains dependencies for the signed messages Packer and is typically created by using aries.Context()
+type Provider interface {
+	Signer() legacykms.Signer
+}
+
+// Packer represents a Pack/Unpacker of signed (but not encrypted) messages that outputs/reads JWS envelopes.
+// The sender key is used to sign the message, the recipient keys are not used as the message is not encrypted.
+type Packer struct {
+	signer legacykms.Signer
+}
+
+// New will create a Packer that signs messages with the sender keys stored in the LegacyKMS.
+func New(ctx Provider) *Packer {
+	return &Packer{
+		signer: ctx.Signer(),
+	}
+}
+
+// signedEnvelope is the JWS (flattened) JSON serialization of the signed message
+type signedEnvelope struct {
+	Protected string `json:"protected,omitempty"`
+	Payload   string `json:"payload,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// EncodingType returns the type of the encoding, as in the `Typ` field of the envelope header
+func (p *Packer) EncodingType() string {
+	return encodingType
+}
@@ -0,0 +1,270 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packager"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	mocklegacykms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+type provider struct {
+	storeProvider storage.Provider
+	kms           *legacykms.BaseKMS
+}
+
+func (p *provider) StorageProvider() storage.Provider {
+	return p.storeProvider
+}
+
+func (p *provider) Signer() legacykms.Signer {
+	return p.kms
+}
+
+func newProvider(t *testing.T) *provider {
+	p := &provider{storeProvider: mockstorage.NewMockStoreProvider()}
+
+	k, err := legacykms.New(p)
+	require.NoError(t, err)
+
+	p.kms = k
+
+	return p
+}
+
+func TestPacker_EncodingType(t *testing.T) {
+	require.Equal(t, encodingType, New(newProvider(t)).EncodingType())
+}
+
+func TestPacker_PackUnpack(t *testing.T) {
+	prov := newProvider(t)
+	signedPacker := New(prov)
+
+	_, senderKey, err := prov.kms.CreateKeySet()
+	require.NoError(t, err)
+
+	msg := []byte(`{"@type": "https://didcomm.org/basicmessage/1.0/message", "content": "hello"}`)
+
+	t.Run("test success", func(t *testing.T) {
+		envelope, err := signedPacker.Pack(msg, base58.Decode(senderKey), nil)
+		require.NoError(t, err)
+
+		// the message is signed, not encrypted
+		env := &signedEnvelope{}
+		require.NoError(t, json.Unmarshal(envelope, env))
+
+		payload, err := base64.RawURLEncoding.DecodeString(env.Payload)
+		require.NoError(t, err)
+		require.Equal(t, msg, payload)
+
+		// receiver side, with its own packer
+		unpacked, err := New(newProvider(t)).Unpack(envelope)
+		require.NoError(t, err)
+		require.Equal(t, &transport.Envelope{Message: msg, FromVerKey: base58.Decode(senderKey)}, unpacked)
+	})
+
+	t.Run("test unpack signed message with packager", func(t *testing.T) {
+		envelope, err := signedPacker.Pack(msg, base58.Decode(senderKey), nil)
+		require.NoError(t, err)
+
+		p, err := packager.New(&packagerProvider{
+			primaryPacker: &mockPacker{},
+			packers:       []packer.Packer{signedPacker},
+		})
+		require.NoError(t, err)
+
+		unpacked, err := p.UnpackMessage(envelope)
+		require.NoError(t, err)
+		require.Equal(t, msg, unpacked.Message)
+		require.Equal(t, base58.Decode(senderKey), unpacked.FromVerKey)
+	})
+
+	t.Run("test pack without sender key", func(t *testing.T) {
+		_, err := signedPacker.Pack(msg, nil, nil)
+		require.EqualError(t, err, "sender key is required to sign the message")
+	})
+
+	t.Run("test pack with a key not in the KMS", func(t *testing.T) {
+		_, otherKey, err := newProvider(t).kms.CreateKeySet()
+		require.NoError(t, err)
+
+		_, err = signedPacker.Pack(msg, base58.Decode(otherKey), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "sign JWS")
+	})
+
+	t.Run("test unpack tampered message", func(t *testing.T) {
+		envelope, err := signedPacker.Pack(msg, base58.Decode(senderKey), nil)
+		require.NoError(t, err)
+
+		env := &signedEnvelope{}
+		require.NoError(t, json.Unmarshal(envelope, env))
+
+		env.Payload = base64.RawURLEncoding.EncodeToString([]byte(`{"content": "tampered"}`))
+
+		_, err = signedPacker.Unpack(toJSON(t, env))
+		require.EqualError(t, err, "unpack: invalid signature")
+	})
+
+	t.Run("test unpack message signed with another key", func(t *testing.T) {
+		_, otherKey, err := prov.kms.CreateKeySet()
+		require.NoError(t, err)
+
+		envelope, err := signedPacker.Pack(msg, base58.Decode(senderKey), nil)
+		require.NoError(t, err)
+
+		env := &signedEnvelope{}
+		require.NoError(t, json.Unmarshal(envelope, env))
+
+		env.Protected = encodeHeaders(t, jose.Headers{
+			jose.HeaderType:      encodingType,
+			jose.HeaderAlgorithm: algEdDSA,
+			jose.HeaderKeyID:     otherKey,
+		})
+
+		_, err = signedPacker.Unpack(toJSON(t, env))
+		require.EqualError(t, err, "unpack: invalid signature")
+	})
+
+	t.Run("test unpack invalid envelope", func(t *testing.T) {
+		_, err := signedPacker.Unpack([]byte("invalid json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unpack:")
+
+		_, err = signedPacker.Unpack([]byte(`{"payload": "e30"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unpack:")
+	})
+
+	t.Run("test unpack invalid headers", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			headers jose.Headers
+			err     string
+		}{
+			{
+				name:    "unsupported algorithm",
+				headers: jose.Headers{jose.HeaderAlgorithm: "ES256", jose.HeaderKeyID: senderKey},
+				err:     "unpack: signature algorithm ES256 not supported",
+			},
+			{
+				name:    "missing kid",
+				headers: jose.Headers{jose.HeaderAlgorithm: algEdDSA},
+				err:     "unpack: sender key is missing in 'kid' header",
+			},
+			{
+				name:    "invalid kid",
+				headers: jose.Headers{jose.HeaderAlgorithm: algEdDSA, jose.HeaderKeyID: "abc"},
+				err:     "unpack: invalid sender key",
+			},
+		}
+
+		for _, test := range tests {
+			tc := test
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := signedPacker.Unpack(toJSON(t, &signedEnvelope{
+					Protected: encodeHeaders(t, tc.headers),
+					Payload:   base64.RawURLEncoding.EncodeToString(msg),
+					Signature: base64.RawURLEncoding.EncodeToString([]byte("signature")),
+				}))
+				require.EqualError(t, err, tc.err)
+			})
+		}
+	})
+
+	t.Run("test unpack message with another type", func(t *testing.T) {
+		jws, err := jose.NewJWS(jose.Headers{jose.HeaderType: "JWM/1.0"}, nil, msg,
+			&signer{signer: prov.kms, verKey: senderKey})
+		require.NoError(t, err)
+
+		compact, err := jws.SerializeCompact(false)
+		require.NoError(t, err)
+
+		parts := strings.Split(compact, ".")
+
+		_, err = signedPacker.Unpack(toJSON(t, &signedEnvelope{
+			Protected: parts[0],
+			Payload:   parts[1],
+			Signature: parts[2],
+		}))
+		require.EqualError(t, err, "message type JWM/1.0 not supported")
+	})
+}
+
+func TestSigner(t *testing.T) {
+	s := &signer{signer: &mocklegacykms.CloseableKMS{SignMessageErr: errors.New("sign error")}, verKey: "key"}
+
+	_, err := s.Sign([]byte("data"))
+	require.EqualError(t, err, "sign error")
+	require.Equal(t, jose.Headers{jose.HeaderAlgorithm: algEdDSA, jose.HeaderKeyID: "key"}, s.Headers())
+}
+
+func toJSON(t *testing.T, env *signedEnvelope) []byte {
+	envelope, err := json.Marshal(env)
+	require.NoError(t, err)
+
+	return envelope
+}
+
+func encodeHeaders(t *testing.T, headers jose.Headers) string {
+	headersBytes, err := json.Marshal(headers)
+	require.NoError(t, err)
+
+	return base64.RawURLEncoding.EncodeToString(headersBytes)
+}
+
+type packagerProvider struct {
+	primaryPacker packer.Packer
+	packers       []packer.Packer
+}
+
+func (p *packagerProvider) Packers() []packer.Packer {
+	return p.packers
+}
+
+func (p *packagerProvider) PrimaryPacker() packer.Packer {
+	return p.primaryPacker
+}
+
+func (p *packagerProvider) StorageProvider() storage.Provider {
+	return mockstorage.NewMockStoreProvider()
+}
+
+func (p *packagerProvider) VDRIRegistry() vdri.Registry {
+	return &mockvdri.MockVDRIRegistry{}
+}
+
+// mockPacker is the primary (encrypting) packer of the packager
+type mockPacker struct{}
+
+func (m *mockPacker) Pack(payload, senderKey []byte, recipients [][]byte) ([]byte, error) {
+	return payload, nil
+}
+
+func (m *mockPacker) Unpack(envelope []byte) (*transport.Envelope, error) {
+	return nil, errors.New("not supported")
+}
+
+func (m *mockPacker) EncodingType() string {
+	return "mock"
+}
@@ -0,0 +1,64 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+)
+
+// Pack will sign the payload with the senderKey and return the JWS envelope.
+// The payload is not encrypted, the recipients are not used.
+func (p *Packer) Pack(payload, senderKey []byte, _ [][]byte) ([]byte, error) {
+	if len(senderKey) == 0 {
+		return nil, errors.New("sender key is required to sign the message")
+	}
+
+	headers := jose.Headers{jose.HeaderType: encodingType}
+
+	jws, err := jose.NewJWS(headers, nil, payload, &signer{signer: p.signer, verKey: base58.Encode(senderKey)})
+	if err != nil {
+		return nil, fmt.Errorf("pack: %w", err)
+	}
+
+	compact, err := jws.SerializeCompact(false)
+	if err != nil {
+		return nil, fmt.Errorf("pack: %w", err)
+	}
+
+	parts := strings.Split(compact, ".")
+
+	return json.Marshal(&signedEnvelope{
+		Protected: parts[0],
+		Payload:   parts[1],
+		Signature: parts[2],
+	})
+}
+
+// signer signs the JWS with the private key of verKey stored in the LegacyKMS
+type signer struct {
+	signer legacykms.Signer
+	verKey string
+}
+
+func (s *signer) Sign(data []byte) ([]byte, error) {
+	return s.signer.SignMessage(data, s.verKey)
+}
+
+func (s *signer) Headers() jose.Headers {
+	return jose.Headers{
+		jose.HeaderAlgorithm: algEdDSA,
+		jose.HeaderKeyID:     s.verKey,
+	}
+}
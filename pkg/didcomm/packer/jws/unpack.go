@@ -0,0 +1,71 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+)
+
+// Unpack will verify the signature of the JWS envelope and return the message with the sender key (the `kid`
+// header) that signed it.
+func (p *Packer) Unpack(envelope []byte) (*transport.Envelope, error) {
+	env := &signedEnvelope{}
+
+	err := json.Unmarshal(envelope, env)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: %w", err)
+	}
+
+	jws, err := jose.ParseJWS(strings.Join([]string{env.Protected, env.Payload, env.Signature}, "."),
+		jose.SignatureVerifierFunc(verifySignature))
+	if err != nil {
+		return nil, fmt.Errorf("unpack: %w", err)
+	}
+
+	if typ, _ := jws.ProtectedHeaders[jose.HeaderType].(string); typ != encodingType {
+		return nil, fmt.Errorf("message type %s not supported", typ)
+	}
+
+	senderKey, _ := jws.ProtectedHeaders.KeyID()
+
+	return &transport.Envelope{
+		Message:    jws.Payload,
+		FromVerKey: base58.Decode(senderKey),
+	}, nil
+}
+
+// verifySignature verifies the Ed25519 signature with the sender key found in the `kid` header
+func verifySignature(joseHeaders jose.Headers, _, signingInput, signature []byte) error {
+	if alg, _ := joseHeaders.Algorithm(); alg != algEdDSA {
+		return fmt.Errorf("signature algorithm %s not supported", alg)
+	}
+
+	kid, ok := joseHeaders.KeyID()
+	if !ok {
+		return errors.New("sender key is missing in 'kid' header")
+	}
+
+	senderKey := base58.Decode(kid)
+	if len(senderKey) != ed25519.PublicKeySize {
+		return errors.New("invalid sender key")
+	}
+
+	if !ed25519.Verify(senderKey, signingInput, signature) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
@@ -32,6 +32,20 @@ type Timing struct {
 	ExpiresTime time.Time `json:"expires_time,omitempty"`
 }
 
+// PleaseAck requests an acknowledgement of the message, sent once it is received and processed.
+// https://github.com/hyperledger/aries-rfcs/tree/master/features/0317-please-ack
+type PleaseAck struct {
+	On []string `json:"on,omitempty"`
+}
+
+// Service is the ~service decorator of connectionless messages, the destination of the replies.
+// https://github.com/hyperledger/aries-rfcs/tree/master/features/0056-service-decorator
+type Service struct {
+	RecipientKeys   []string `json:"recipientKeys"`
+	RoutingKeys     []string `json:"routingKeys,omitempty"`
+	ServiceEndpoint string   `json:"serviceEndpoint"`
+}
+
 // Transport transport decorator
 // https://github.com/hyperledger/aries-rfcs/tree/master/features/0092-transport-return-route
 type Transport struct {
@@ -86,4 +100,18 @@ type AttachmentData struct {
 	// JSON is a directly embedded JSON data, when representing content inline instead of via links,
 	// and when the content is natively conveyable as JSON. Optional.
 	JSON interface{} `json:"json,omitempty"`
+	// JWS is a detached JSON Web Signature of the inlined content, signed by the sender of the attachment. Optional.
+	JWS *AttachmentJWS `json:"jws,omitempty"`
+}
+
+// AttachmentJWS is the detached JSON Web Signature of the content of an attachment, as defined by RFC 0017: the
+// flattened JWS JSON serialization, without payload.
+// https://github.com/hyperledger/aries-rfcs/tree/master/concepts/0017-attachments#signing-attachments
+type AttachmentJWS struct {
+	// Header is the unprotected JOSE header, eg: the ID of the signing key ("kid").
+	Header map[string]interface{} `json:"header,omitempty"`
+	// Protected is the base64url encoded protected JOSE header.
+	Protected string `json:"protected,omitempty"`
+	// Signature is the base64url encoded signature.
+	Signature string `json:"signature,omitempty"`
 }
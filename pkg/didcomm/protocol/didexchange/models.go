@@ -28,6 +28,8 @@ type OOBInvitation struct {
 	// - a string with a valid DID
 	// - a valid `did.Service`
 	Target interface{}
+	// MediaType of the exchange negotiated by the out-of-band request, empty if none was negotiated.
+	MediaType string
 }
 
 // Invitation model
@@ -83,6 +85,14 @@ type Response struct {
 	Thread              *decorator.Thread    `json:"~thread,omitempty"`
 }
 
+// ServiceUpdate notifies the peer of a completed connection that the did-communication service of the sender's
+// DID document changed (e.g. new routing keys after a mediation refresh).
+type ServiceUpdate struct {
+	Type       string      `json:"@type,omitempty"`
+	ID         string      `json:"@id,omitempty"`
+	Connection *Connection `json:"connection,omitempty"`
+}
+
 // ConnectionSignature connection signature
 type ConnectionSignature struct {
 	Type       string `json:"@type,omitempty"`
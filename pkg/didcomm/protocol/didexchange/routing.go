@@ -0,0 +1,107 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didexchange
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+)
+
+// UpdateConnectionRouting updates the routing keys and, if not empty, the service endpoint of the did-communication
+// service of my DID document for the given (completed) connection and notifies the peer with a service update
+// message.
+func (s *Service) UpdateConnectionRouting(connectionID string, routingKeys []string, endpoint string) error {
+	connRecord, err := s.connectionStore.GetConnectionRecord(connectionID)
+	if err != nil {
+		return fmt.Errorf("get connection record : %w", err)
+	}
+
+	if connRecord.State != stateNameCompleted {
+		return fmt.Errorf("connection %s is not completed : state=%s", connectionID, connRecord.State)
+	}
+
+	myDIDDoc, err := s.ctx.vdriRegistry.Resolve(connRecord.MyDID)
+	if err != nil {
+		return fmt.Errorf("resolve my did : %w", err)
+	}
+
+	didCommService, ok := did.LookupService(myDIDDoc, didCommServiceType)
+	if !ok {
+		return fmt.Errorf("no %s service in did document %s", didCommServiceType, myDIDDoc.ID)
+	}
+
+	didCommService.RoutingKeys = routingKeys
+
+	if endpoint != "" {
+		didCommService.ServiceEndpoint = endpoint
+	}
+
+	if err = s.ctx.vdriRegistry.Store(myDIDDoc); err != nil {
+		return fmt.Errorf("store my did document : %w", err)
+	}
+
+	senderVerKeys, ok := did.LookupRecipientKeys(myDIDDoc, didCommServiceType, ed25519KeyType)
+	if !ok {
+		return errors.New("getting sender verification keys")
+	}
+
+	destination, err := service.GetDestination(connRecord.TheirDID, s.ctx.vdriRegistry)
+	if err != nil {
+		return fmt.Errorf("get destination : %w", err)
+	}
+
+	return s.ctx.outboundDispatcher.Send(&ServiceUpdate{
+		Type:       ServiceUpdateMsgType,
+		ID:         uuid.New().String(),
+		Connection: &Connection{DID: myDIDDoc.ID, DIDDoc: myDIDDoc},
+	}, senderVerKeys[0], destination)
+}
+
+// handleServiceUpdate stores the updated DID document of the peer and updates the routing of the connection record.
+func (s *Service) handleServiceUpdate(msg service.DIDCommMsg, myDID, theirDID string) (string, error) {
+	update := &ServiceUpdate{}
+	if err := msg.Decode(update); err != nil {
+		return "", fmt.Errorf("decode service update : %w", err)
+	}
+
+	if update.Connection == nil || update.Connection.DIDDoc == nil || update.Connection.DID != theirDID {
+		return "", fmt.Errorf("service update : invalid did document for %s", theirDID)
+	}
+
+	connectionID, err := s.connectionStore.GetConnectionIDByDIDs(myDID, theirDID)
+	if err != nil {
+		return "", fmt.Errorf("service update - get connection id : %w", err)
+	}
+
+	connRecord, err := s.connectionStore.GetConnectionRecord(connectionID)
+	if err != nil {
+		return "", fmt.Errorf("service update - get connection record : %w", err)
+	}
+
+	didCommService, ok := did.LookupService(update.Connection.DIDDoc, didCommServiceType)
+	if !ok {
+		return "", fmt.Errorf("service update : no %s service in did document %s", didCommServiceType, theirDID)
+	}
+
+	if err = s.ctx.vdriRegistry.Store(update.Connection.DIDDoc); err != nil {
+		return "", fmt.Errorf("service update - store their did document : %w", err)
+	}
+
+	connRecord.RoutingKeys = didCommService.RoutingKeys
+	connRecord.ServiceEndPoint = didCommService.ServiceEndpoint
+
+	if err = s.connectionStore.saveConnectionRecord(connRecord); err != nil {
+		return "", fmt.Errorf("service update - save connection record : %w", err)
+	}
+
+	return connectionID, nil
+}
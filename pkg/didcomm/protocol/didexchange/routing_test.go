@@ -0,0 +1,211 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didexchange
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol"
+	mockroute "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/route"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+func TestService_UpdateConnectionRouting(t *testing.T) {
+	const (
+		newEndpoint   = "https://new-mediator.example.com"
+		newRoutingKey = "new-routing-key"
+	)
+
+	t.Run("test routing update is stored and sent to the peer", func(t *testing.T) {
+		myDoc, theirDoc := createDIDDoc(), createDIDDoc()
+		registry := newMemVDRIRegistry(myDoc, theirDoc)
+
+		var sent *ServiceUpdate
+
+		alice := newRoutingTestService(t, registry, &mockdispatcher.MockOutbound{
+			ValidateSend: func(msg interface{}, senderVerKey string, des *service.Destination) error {
+				update, ok := msg.(*ServiceUpdate)
+				require.True(t, ok)
+				require.NotEmpty(t, senderVerKey)
+				require.Equal(t, theirDoc.Service[0].ServiceEndpoint, des.ServiceEndpoint)
+
+				sent = update
+
+				return nil
+			},
+		})
+		connectionID := saveCompletedConnection(t, alice, myDoc.ID, theirDoc.ID)
+
+		err := alice.UpdateConnectionRouting(connectionID, []string{newRoutingKey}, newEndpoint)
+		require.NoError(t, err)
+
+		// the local did document is updated
+		updated, err := registry.Resolve(myDoc.ID)
+		require.NoError(t, err)
+		require.Equal(t, []string{newRoutingKey}, updated.Service[0].RoutingKeys)
+		require.Equal(t, newEndpoint, updated.Service[0].ServiceEndpoint)
+
+		// the peer is notified
+		require.NotNil(t, sent)
+		require.Equal(t, ServiceUpdateMsgType, sent.Type)
+		require.Equal(t, myDoc.ID, sent.Connection.DID)
+		require.Equal(t, []string{newRoutingKey}, sent.Connection.DIDDoc.Service[0].RoutingKeys)
+
+		// the peer updates the routing of its connection record
+		bob := newRoutingTestService(t, newMemVDRIRegistry(theirDoc), &mockdispatcher.MockOutbound{})
+		bobConnectionID := saveCompletedConnection(t, bob, theirDoc.ID, myDoc.ID)
+
+		connID, err := bob.HandleInbound(toDIDCommMsg(t, sent), theirDoc.ID, myDoc.ID)
+		require.NoError(t, err)
+		require.Equal(t, bobConnectionID, connID)
+
+		record, err := bob.connectionStore.GetConnectionRecord(bobConnectionID)
+		require.NoError(t, err)
+		require.Equal(t, []string{newRoutingKey}, record.RoutingKeys)
+		require.Equal(t, newEndpoint, record.ServiceEndPoint)
+	})
+
+	t.Run("test endpoint is kept if not provided", func(t *testing.T) {
+		myDoc, theirDoc := createDIDDoc(), createDIDDoc()
+		registry := newMemVDRIRegistry(myDoc, theirDoc)
+
+		alice := newRoutingTestService(t, registry, &mockdispatcher.MockOutbound{})
+		connectionID := saveCompletedConnection(t, alice, myDoc.ID, theirDoc.ID)
+
+		require.NoError(t, alice.UpdateConnectionRouting(connectionID, nil, ""))
+
+		updated, err := registry.Resolve(myDoc.ID)
+		require.NoError(t, err)
+		require.Empty(t, updated.Service[0].RoutingKeys)
+		require.Equal(t, "http://localhost:58416", updated.Service[0].ServiceEndpoint)
+	})
+
+	t.Run("test connection not found", func(t *testing.T) {
+		alice := newRoutingTestService(t, newMemVDRIRegistry(), &mockdispatcher.MockOutbound{})
+
+		err := alice.UpdateConnectionRouting("unknown", nil, newEndpoint)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get connection record")
+	})
+
+	t.Run("test connection not completed", func(t *testing.T) {
+		alice := newRoutingTestService(t, newMemVDRIRegistry(), &mockdispatcher.MockOutbound{})
+
+		connectionID := uuid.New().String()
+		require.NoError(t, alice.connectionStore.saveConnectionRecord(&connection.Record{
+			ConnectionID: connectionID,
+			State:        stateNameRequested,
+		}))
+
+		err := alice.UpdateConnectionRouting(connectionID, nil, newEndpoint)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not completed")
+	})
+
+	t.Run("test no did-communication service", func(t *testing.T) {
+		myDoc, theirDoc := createDIDDoc(), createDIDDoc()
+		myDoc.Service = nil
+
+		alice := newRoutingTestService(t, newMemVDRIRegistry(myDoc, theirDoc), &mockdispatcher.MockOutbound{})
+		connectionID := saveCompletedConnection(t, alice, myDoc.ID, theirDoc.ID)
+
+		err := alice.UpdateConnectionRouting(connectionID, nil, newEndpoint)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no did-communication service")
+	})
+
+	t.Run("test send error", func(t *testing.T) {
+		myDoc, theirDoc := createDIDDoc(), createDIDDoc()
+
+		alice := newRoutingTestService(t, newMemVDRIRegistry(myDoc, theirDoc), &mockdispatcher.MockOutbound{
+			SendErr: errors.New("send error"),
+		})
+		connectionID := saveCompletedConnection(t, alice, myDoc.ID, theirDoc.ID)
+
+		err := alice.UpdateConnectionRouting(connectionID, nil, newEndpoint)
+		require.EqualError(t, err, "send error")
+	})
+
+	t.Run("test service update from an unknown DID", func(t *testing.T) {
+		theirDoc := createDIDDoc()
+		bob := newRoutingTestService(t, newMemVDRIRegistry(), &mockdispatcher.MockOutbound{})
+
+		_, err := bob.HandleInbound(toDIDCommMsg(t, &ServiceUpdate{
+			Type:       ServiceUpdateMsgType,
+			ID:         uuid.New().String(),
+			Connection: &Connection{DID: theirDoc.ID, DIDDoc: theirDoc},
+		}), "did:example:me", "did:example:other")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid did document")
+
+		_, err = bob.HandleInbound(toDIDCommMsg(t, &ServiceUpdate{
+			Type:       ServiceUpdateMsgType,
+			ID:         uuid.New().String(),
+			Connection: &Connection{DID: theirDoc.ID, DIDDoc: theirDoc},
+		}), "did:example:me", theirDoc.ID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get connection id")
+	})
+}
+
+func newRoutingTestService(t *testing.T, registry vdriapi.Registry, outbound *mockdispatcher.MockOutbound) *Service {
+	svc, err := New(&protocol.MockProvider{
+		StoreProvider:          mockstorage.NewMockStoreProvider(),
+		TransientStoreProvider: mockstorage.NewMockStoreProvider(),
+		CustomVDRI:             registry,
+		CustomOutbound:         outbound,
+		ServiceMap: map[string]interface{}{
+			route.Coordination: &mockroute.MockRouteSvc{},
+		},
+	})
+	require.NoError(t, err)
+
+	return svc
+}
+
+func newMemVDRIRegistry(docs ...*did.Doc) *mockvdri.MockVDRIRegistry {
+	registry := &mockvdri.MockVDRIRegistry{MemStore: map[string]*did.Doc{}}
+
+	for _, doc := range docs {
+		registry.MemStore[doc.ID] = doc
+	}
+
+	registry.ResolveFunc = func(didID string, _ ...vdriapi.ResolveOpts) (*did.Doc, error) {
+		doc, ok := registry.MemStore[didID]
+		if !ok {
+			return nil, vdriapi.ErrNotFound
+		}
+
+		return doc, nil
+	}
+
+	return registry
+}
+
+func saveCompletedConnection(t *testing.T, svc *Service, myDID, theirDID string) string {
+	connectionID := uuid.New().String()
+
+	require.NoError(t, svc.connectionStore.saveConnectionRecord(&connection.Record{
+		ConnectionID: connectionID,
+		State:        stateNameCompleted,
+		MyDID:        myDID,
+		TheirDID:     theirDID,
+	}))
+
+	return connectionID
+}
@@ -10,9 +10,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/google/uuid"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/idgen"
 	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
@@ -40,6 +42,9 @@ const (
 	ResponseMsgType = DIDExchangeSpec + "response"
 	// AckMsgType defines the did-exchange ack message type.
 	AckMsgType = DIDExchangeSpec + "ack"
+	// ServiceUpdateMsgType defines the message notifying the peer of a completed connection of an update
+	// of the sender's did-communication service (e.g. new routing keys after a mediation refresh).
+	ServiceUpdateMsgType = DIDExchangeSpec + "service_update"
 
 	oobMsgType = "oob-invitation"
 )
@@ -67,6 +72,12 @@ type provider interface {
 	Service(id string) (interface{}, error)
 }
 
+// idGeneratorProvider is implemented by the providers configuring the generator of the connection IDs, they are
+// UUIDs otherwise.
+type idGeneratorProvider interface {
+	IDGenerator() idgen.IDGenerator
+}
+
 // stateMachineMsg is an internal struct used to pass data to state machine.
 type stateMachineMsg struct {
 	service.DIDCommMsg
@@ -81,6 +92,11 @@ type Service struct {
 	ctx             *context
 	callbackChannel chan *message
 	connectionStore *connectionStore
+	stateStore      *stateStore
+	// recovered keeps the exchanges restored on startup until an action event channel is registered
+	recovered     []*protocolState
+	recoveredLock sync.Mutex
+	idGenerator   idgen.IDGenerator
 }
 
 type context struct {
@@ -89,8 +105,18 @@ type context struct {
 	connectionStore    *connectionStore
 	vdriRegistry       vdriapi.Registry
 	routeSvc           route.ProtocolService
+	// keyAgreement is whether the created DIDs have a key agreement key, keyAgreementKey if set or a new X25519 key
+	keyAgreement    bool
+	keyAgreementKey string
+	requestClaimer  RequestClaimer
+	claimerLock     sync.RWMutex
 }
 
+// RequestClaimer claims the out-of-band request parentThreadID for the connection connectionID before the response
+// to the did-exchange request made for it is sent. The did-exchange request is rejected, and no response is sent, if
+// an error is returned.
+type RequestClaimer func(parentThreadID, connectionID string) error
+
 // opts are used to provide client properties to DID Exchange service
 type opts interface {
 	// PublicDID allows for setting public DID
@@ -100,13 +126,38 @@ type opts interface {
 	Label() string
 }
 
+// ServiceOption configures the DID exchange service.
+type ServiceOption func(opts *Service)
+
+// WithKeyAgreement option sets whether the DIDs created for the connections have an X25519 key agreement key,
+// created with the KMS along with the signing key (enabled by default).
+func WithKeyAgreement(keyAgreement bool) ServiceOption {
+	return func(opts *Service) {
+		opts.ctx.keyAgreement = keyAgreement
+	}
+}
+
+// WithKeyAgreementKey option sets the existing base58 encoded X25519 key reused as the key agreement key of the DIDs
+// created for the connections, instead of creating a new key for each DID.
+func WithKeyAgreementKey(base58PubKey string) ServiceOption {
+	return func(opts *Service) {
+		opts.ctx.keyAgreement = true
+		opts.ctx.keyAgreementKey = base58PubKey
+	}
+}
+
 // New return didexchange service
-func New(prov provider) (*Service, error) {
+func New(prov provider, opts ...ServiceOption) (*Service, error) {
 	connRecorder, err := newConnectionStore(prov)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize connection store : %w", err)
 	}
 
+	stateStore, err := newStateStore(prov)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize state store : %w", err)
+	}
+
 	s, err := prov.Service(route.Coordination)
 	if err != nil {
 		return nil, err
@@ -126,15 +177,30 @@ func New(prov provider) (*Service, error) {
 			vdriRegistry:       prov.VDRIRegistry(),
 			connectionStore:    connRecorder,
 			routeSvc:           routeSvc,
+			keyAgreement:       true,
 		},
 		// TODO channel size - https://github.com/hyperledger/aries-framework-go/issues/246
 		callbackChannel: make(chan *message, callbackChannelSize),
 		connectionStore: connRecorder,
+		stateStore:      stateStore,
+	}
+
+	if gp, ok := prov.(idGeneratorProvider); ok {
+		svc.idGenerator = gp.IDGenerator()
+	}
+
+	for _, opt := range opts {
+		opt(svc)
 	}
 
 	// start the listener
 	go svc.startInternalListener()
 
+	// resume the exchanges which were in progress when the service was stopped
+	if err := svc.resume(); err != nil {
+		return nil, fmt.Errorf("failed to resume did exchanges : %w", err)
+	}
+
 	return svc, nil
 }
 
@@ -142,6 +208,10 @@ func New(prov provider) (*Service, error) {
 func (s *Service) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string) (string, error) {
 	logger.Debugf("receive inbound message : %s", msg)
 
+	if msg.Type() == ServiceUpdateMsgType {
+		return s.handleServiceUpdate(msg, myDID, theirDID)
+	}
+
 	// fetch the thread id
 	thID, err := threadID(msg)
 	if err != nil {
@@ -209,7 +279,8 @@ func (s *Service) Accept(msgType string) bool {
 	return msgType == InvitationMsgType ||
 		msgType == RequestMsgType ||
 		msgType == ResponseMsgType ||
-		msgType == AckMsgType
+		msgType == AckMsgType ||
+		msgType == ServiceUpdateMsgType
 }
 
 // HandleOutbound handles outbound didexchange messages.
@@ -254,7 +325,13 @@ func (s *Service) handle(msg *message, aEvent chan<- service.DIDCommAction) erro
 		return fmt.Errorf("invalid state name: %w", err)
 	}
 
+	latest := msg.ConnRecord
+
 	for !isNoOp(next) {
+		if err = s.saveState(msg, next.Name(), latest, false); err != nil {
+			return fmt.Errorf("failed to persist protocol state %s : %w", next.Name(), err)
+		}
+
 		s.sendMsgEvents(&service.StateMsg{
 			ProtocolName: DIDExchange,
 			Type:         service.PreState,
@@ -290,6 +367,8 @@ func (s *Service) handle(msg *message, aEvent chan<- service.DIDCommAction) erro
 			return fmt.Errorf("failed to persist state %s %w", next.Name(), err)
 		}
 
+		latest = connectionRecord
+
 		logger.Debugf("updated connection record %+v", connectionRecord)
 
 		if err = action(); err != nil {
@@ -305,7 +384,7 @@ func (s *Service) handle(msg *message, aEvent chan<- service.DIDCommAction) erro
 		// trigger action event based on message type for inbound messages
 		if canTriggerActionEvents(connectionRecord.State, connectionRecord.Namespace) {
 			msg.NextStateName = next.Name()
-			if err = s.sendActionEvent(msg, aEvent); err != nil {
+			if err = s.sendActionEvent(msg, connectionRecord, aEvent); err != nil {
 				return fmt.Errorf("handle inbound: %w", err)
 			}
 
@@ -322,11 +401,16 @@ func (s *Service) handle(msg *message, aEvent chan<- service.DIDCommAction) erro
 		logger.Debugf("sent post event for state %s", prev.Name())
 
 		if haltExecution {
-			logger.Debugf("halted execution before state=%s", msg.NextStateName)
+			logger.Debugf("halted execution before state=%s", next.Name())
 			break
 		}
 	}
 
+	if isNoOp(next) {
+		// the exchange is done, nothing to resume
+		return s.stateStore.delete(msg.ConnRecord.Namespace, msg.ThreadID)
+	}
+
 	return nil
 }
 
@@ -350,13 +434,20 @@ func createErrorEventProperties(connectionID, invitationID string, err error) *d
 
 // sendActionEvent triggers the action event. This function stores the state of current processing and passes a callback
 // function in the event message.
-func (s *Service) sendActionEvent(internalMsg *message, aEvent chan<- service.DIDCommAction) error {
+func (s *Service) sendActionEvent(internalMsg *message, connRecord *connection.Record,
+	aEvent chan<- service.DIDCommAction) error {
 	// save data to support AcceptExchangeRequest APIs (when client will not be able to invoke the callback function)
 	err := s.storeEventTransientData(internalMsg)
 	if err != nil {
 		return fmt.Errorf("send action event : %w", err)
 	}
 
+	// persist the state to wait for the action after a restart
+	err = s.saveState(internalMsg, internalMsg.NextStateName, connRecord, true)
+	if err != nil {
+		return fmt.Errorf("send action event : %w", err)
+	}
+
 	if aEvent != nil {
 		// trigger action event
 		aEvent <- service.DIDCommAction{
@@ -422,6 +513,15 @@ func (s *Service) AcceptExchangeRequest(connectionID, publicDID, label string) e
 	return s.accept(connectionID, publicDID, label, stateNameRequested, "accept exchange request")
 }
 
+// SetRequestClaimer sets the claimer of the out-of-band requests for which did-exchange requests are received, eg: the
+// out-of-band service consuming its single-use requests. It replaces the claimer set before, if any.
+func (s *Service) SetRequestClaimer(claimer RequestClaimer) {
+	s.ctx.claimerLock.Lock()
+	defer s.ctx.claimerLock.Unlock()
+
+	s.ctx.requestClaimer = claimer
+}
+
 // RespondTo this inbound invitation and return with the new connection record's ID.
 func (s *Service) RespondTo(i *OOBInvitation) (string, error) {
 	i.Type = oobMsgType
@@ -487,6 +587,79 @@ func (s *Service) getEventTransientData(connectionID string) (*message, error) {
 	return msg, nil
 }
 
+func (s *Service) saveState(msg *message, nextStateName string, connRecord *connection.Record,
+	awaitsAction bool) error {
+	return s.stateStore.save(newProtocolState(msg, nextStateName, connRecord, awaitsAction))
+}
+
+// resume restores the exchanges which were in progress when the service was stopped. The exchanges are resumed once
+// an action event channel is registered.
+func (s *Service) resume() error {
+	states, err := s.stateStore.all()
+	if err != nil {
+		return err
+	}
+
+	for _, st := range states {
+		// the transient store might not survive the restart
+		err = s.connectionStore.SaveConnectionRecordWithMappings(st.Connection)
+		if err != nil {
+			return fmt.Errorf("restore connection record : %w", err)
+		}
+
+		if st.AwaitsAction {
+			// save data to support AcceptExchangeRequest APIs before the action event is sent again
+			err = s.storeEventTransientData(st.message())
+			if err != nil {
+				return fmt.Errorf("restore event data : %w", err)
+			}
+		}
+
+		logger.Debugf("restored did exchange thID=%s state=%s", st.ThreadID, st.NextStateName)
+	}
+
+	s.recoveredLock.Lock()
+	s.recovered = states
+	s.recoveredLock.Unlock()
+
+	return nil
+}
+
+// RegisterActionEvent registers a channel to receive DID exchange action events. The exchanges restored on startup
+// are resumed once the channel is registered: the ones waiting for an action send the action event again.
+func (s *Service) RegisterActionEvent(ch chan<- service.DIDCommAction) error {
+	if err := s.Action.RegisterActionEvent(ch); err != nil {
+		return err
+	}
+
+	s.recoveredLock.Lock()
+	recovered := s.recovered
+	s.recovered = nil
+	s.recoveredLock.Unlock()
+
+	for _, st := range recovered {
+		go s.resumeState(st, ch)
+	}
+
+	return nil
+}
+
+func (s *Service) resumeState(st *protocolState, aEvent chan<- service.DIDCommAction) {
+	var err error
+
+	msg := st.message()
+
+	if st.AwaitsAction {
+		err = s.sendActionEvent(msg, st.Connection, aEvent)
+	} else {
+		err = s.handle(msg, aEvent)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to resume did exchange thID=%s : %s", st.ThreadID, err)
+	}
+}
+
 // abandon updates the state to abandoned and trigger failure event.
 func (s *Service) abandon(thID string, msg service.DIDCommMsg, processErr error) error {
 	// update the state to abandoned
@@ -507,6 +680,11 @@ func (s *Service) abandon(thID string, msg service.DIDCommMsg, processErr error)
 		return fmt.Errorf("unable to update the state to abandoned: %w", err)
 	}
 
+	err = s.stateStore.delete(connRec.Namespace, thID)
+	if err != nil {
+		return fmt.Errorf("unable to update the state to abandoned: %w", err)
+	}
+
 	// send the message event
 	s.sendMsgEvents(&service.StateMsg{
 		ProtocolName: DIDExchange,
@@ -596,8 +774,13 @@ func (s *Service) oobInvitationMsgRecord(msg service.DIDCommMsg) (*connection.Re
 		return nil, fmt.Errorf("failed to get the did service block from oob invitation : %w", err)
 	}
 
+	connID, err := s.newConnectionID()
+	if err != nil {
+		return nil, err
+	}
+
 	connRecord := &connection.Record{
-		ConnectionID:    generateRandomID(),
+		ConnectionID:    connID,
 		ThreadID:        thID,
 		ParentThreadID:  oobInvitation.ThreadID,
 		State:           stateNameNull,
@@ -639,8 +822,13 @@ func (s *Service) invitationMsgRecord(msg service.DIDCommMsg) (*connection.Recor
 		return nil, err
 	}
 
+	connID, err := s.newConnectionID()
+	if err != nil {
+		return nil, err
+	}
+
 	connRecord := &connection.Record{
-		ConnectionID:    generateRandomID(),
+		ConnectionID:    connID,
 		ThreadID:        thID,
 		State:           stateNameNull,
 		InvitationID:    invitation.ID,
@@ -666,8 +854,13 @@ func (s *Service) requestMsgRecord(msg service.DIDCommMsg) (*connection.Record,
 		return nil, fmt.Errorf("unmarshalling failed: %s", err)
 	}
 
+	connID, err := s.newConnectionID()
+	if err != nil {
+		return nil, err
+	}
+
 	connRecord := &connection.Record{
-		ConnectionID: generateRandomID(),
+		ConnectionID: connID,
 		ThreadID:     request.ID,
 		State:        stateNameNull,
 		TheirDID:     request.Connection.DID,
@@ -711,6 +904,34 @@ func generateRandomID() string {
 	return uuid.New().String()
 }
 
+// newConnectionID returns the ID of a new connection record. The IDs of the existing connection records are skipped,
+// eg: the IDs generated again by a sequential generator after a restart.
+func (s *Service) newConnectionID() (string, error) {
+	if s.idGenerator == nil {
+		return generateRandomID(), nil
+	}
+
+	var previous string
+
+	for {
+		connID := s.idGenerator.NewID()
+		if connID == previous {
+			return "", fmt.Errorf("new connection ID: the ID generator returned %s again", connID)
+		}
+
+		_, err := s.connectionStore.GetConnectionRecord(connID)
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return connID, nil
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("new connection ID: %w", err)
+		}
+
+		previous = connID
+	}
+}
+
 // canTriggerActionEvents true based on role and state.
 // 1. Role is invitee and state is invited
 // 2. Role is inviter and state is requested
@@ -738,9 +959,14 @@ func (s *Service) CreateImplicitInvitation(inviterLabel, inviterDID, inviteeLabe
 		return "", err
 	}
 
+	connID, err := s.newConnectionID()
+	if err != nil {
+		return "", err
+	}
+
 	thID := generateRandomID()
 	connRecord := &connection.Record{
-		ConnectionID:    generateRandomID(),
+		ConnectionID:    connID,
 		ThreadID:        thID,
 		State:           stateNameNull,
 		InvitationDID:   inviterDID,
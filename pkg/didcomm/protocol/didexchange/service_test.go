@@ -14,21 +14,28 @@ import (
 	"testing"
 	"time"
 
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/idgen"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol"
 	mockroute "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/route"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 	mockdiddoc "github.com/hyperledger/aries-framework-go/pkg/mock/diddoc"
 	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
 )
 
 const testMethod = "peer"
@@ -493,6 +500,7 @@ func TestService_Accept(t *testing.T) {
 	require.Equal(t, true, s.Accept("https://didcomm.org/didexchange/1.0/request"))
 	require.Equal(t, true, s.Accept("https://didcomm.org/didexchange/1.0/response"))
 	require.Equal(t, true, s.Accept("https://didcomm.org/didexchange/1.0/ack"))
+	require.Equal(t, true, s.Accept("https://didcomm.org/didexchange/1.0/service_update"))
 	require.Equal(t, false, s.Accept("unsupported msg type"))
 }
 
@@ -604,6 +612,9 @@ func TestService_Update(t *testing.T) {
 	cr := &connection.Record{}
 	err = json.Unmarshal(bytes, cr)
 	require.NoError(t, err)
+
+	// the saved record has the version of the stored record
+	cr.Version = storage.Version(bytes)
 	require.Equal(t, cr, connRec)
 }
 
@@ -630,7 +641,7 @@ func (m *mockStore) Delete(k string) error {
 
 // Search returns storage iterator
 func (m *mockStore) Iterator(start, limit string) storage.StoreIterator {
-	return nil
+	return mockstorage.NewMockIterator(nil)
 }
 
 func randomString() string {
@@ -1056,6 +1067,69 @@ func TestRequestRecord(t *testing.T) {
 		randomString(), ""))
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "save connection record")
+
+	// injected ID generator
+	svc, err = New(&protocol.MockProvider{
+		ServiceMap: map[string]interface{}{
+			route.Coordination: &mockroute.MockRouteSvc{},
+		},
+		CustomIDGenerator: idgen.NewSequential("conn-"),
+	})
+	require.NoError(t, err)
+
+	for _, expected := range []string{"conn-1", "conn-2"} {
+		conn, err = svc.requestMsgRecord(generateRequestMsgPayload(t, &protocol.MockProvider{},
+			randomString(), ""))
+		require.NoError(t, err)
+		require.Equal(t, expected, conn.ConnectionID)
+
+		stored, err := svc.connectionStore.GetConnectionRecord(expected)
+		require.NoError(t, err)
+		require.Equal(t, conn.ThreadID, stored.ThreadID)
+	}
+
+	// the IDs of the existing records are skipped by a new sequential generator, eg: after a restart
+	storeProvider, transientStoreProvider := mockstorage.NewMockStoreProvider(), mockstorage.NewMockStoreProvider()
+
+	for _, expected := range []string{"conn-1", "conn-2"} {
+		svc, err = New(&protocol.MockProvider{
+			StoreProvider:          storeProvider,
+			TransientStoreProvider: transientStoreProvider,
+			ServiceMap: map[string]interface{}{
+				route.Coordination: &mockroute.MockRouteSvc{},
+			},
+			CustomIDGenerator: idgen.NewSequential("conn-"),
+		})
+		require.NoError(t, err)
+
+		conn, err = svc.requestMsgRecord(generateRequestMsgPayload(t, &protocol.MockProvider{},
+			randomString(), ""))
+		require.NoError(t, err)
+		require.Equal(t, expected, conn.ConnectionID)
+	}
+
+	// the generator must not return the same ID again
+	svc, err = New(&protocol.MockProvider{
+		StoreProvider:          storeProvider,
+		TransientStoreProvider: transientStoreProvider,
+		ServiceMap: map[string]interface{}{
+			route.Coordination: &mockroute.MockRouteSvc{},
+		},
+		CustomIDGenerator: constantIDGenerator("conn-1"),
+	})
+	require.NoError(t, err)
+
+	_, err = svc.requestMsgRecord(generateRequestMsgPayload(t, &protocol.MockProvider{},
+		randomString(), ""))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the ID generator returned conn-1 again")
+}
+
+// constantIDGenerator generates the same ID.
+type constantIDGenerator string
+
+func (g constantIDGenerator) NewID() string {
+	return string(g)
 }
 
 func TestAcceptExchangeRequest(t *testing.T) {
@@ -1802,6 +1876,142 @@ func TestSave(t *testing.T) {
 	})
 }
 
+func TestService_SetRequestClaimer(t *testing.T) {
+	s, err := New(testProvider())
+	require.NoError(t, err)
+	require.Nil(t, s.ctx.requestClaimer)
+
+	expected := errors.New("claimed")
+
+	s.SetRequestClaimer(func(string, string) error { return expected })
+	require.NotNil(t, s.ctx.requestClaimer)
+	require.Equal(t, expected, s.ctx.requestClaimer("pthid", "connID"))
+}
+
+func TestServiceKeyAgreement(t *testing.T) {
+	newService := func(t *testing.T, opts ...ServiceOption) *Service {
+		kms, err := legacykms.New(&mockprovider.Provider{StorageProviderValue: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		peerVDRI, err := peer.New(mockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		provider := testProvider()
+		provider.CustomVDRI = vdri.New(&mockprovider.Provider{KMSValue: kms}, vdri.WithVDRI(peerVDRI))
+
+		s, err := New(provider, opts...)
+		require.NoError(t, err)
+
+		return s
+	}
+
+	t.Run("creates an X25519 key agreement key by default", func(t *testing.T) {
+		didDoc, conn, err := newService(t).ctx.getDIDDocAndConnection("", "")
+		require.NoError(t, err)
+		require.Equal(t, didDoc, conn.DIDDoc)
+		require.Len(t, didDoc.KeyAgreement, 1)
+
+		keyAgreementKey := didDoc.KeyAgreement[0].PublicKey
+		require.Equal(t, vdriapi.X25519KeyAgreementKeyType, keyAgreementKey.Type)
+		require.Len(t, keyAgreementKey.Value, 32)
+
+		// the key agreement key is one of the public keys of the connection DID doc
+		pubKey, ok := did.LookupPublicKey(keyAgreementKey.ID, conn.DIDDoc)
+		require.True(t, ok)
+		require.Equal(t, keyAgreementKey, *pubKey)
+
+		// a new key is created for each connection
+		other, _, err := newService(t).ctx.getDIDDocAndConnection("", "")
+		require.NoError(t, err)
+		require.NotEqual(t, keyAgreementKey.Value, other.KeyAgreement[0].PublicKey.Value)
+	})
+
+	t.Run("reuses the existing key agreement key", func(t *testing.T) {
+		pubKey, _ := generateKeyPair()
+
+		didDoc, _, err := newService(t, WithKeyAgreementKey(pubKey)).ctx.getDIDDocAndConnection("", "")
+		require.NoError(t, err)
+		require.Len(t, didDoc.KeyAgreement, 1)
+		require.Equal(t, base58.Decode(pubKey), didDoc.KeyAgreement[0].PublicKey.Value)
+	})
+
+	t.Run("key agreement key disabled", func(t *testing.T) {
+		didDoc, _, err := newService(t, WithKeyAgreement(false)).ctx.getDIDDocAndConnection("", "")
+		require.NoError(t, err)
+		require.Empty(t, didDoc.KeyAgreement)
+		require.Len(t, didDoc.PublicKey, 1)
+	})
+}
+
+func TestServiceConnectionKey(t *testing.T) {
+	newService := func(t *testing.T, routeSvc *mockroute.MockRouteSvc) *Service {
+		kms, err := legacykms.New(&mockprovider.Provider{StorageProviderValue: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		peerVDRI, err := peer.New(mockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		provider := testProvider()
+		provider.ServiceMap[route.Coordination] = routeSvc
+		provider.CustomVDRI = vdri.New(&mockprovider.Provider{KMSValue: kms}, vdri.WithVDRI(peerVDRI),
+			vdri.WithDefaultServiceType(didCommServiceType))
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		return s
+	}
+
+	t.Run("creates the DIDs with the keys allocated to the connections", func(t *testing.T) {
+		allocated := map[string]string{}
+
+		s := newService(t, &mockroute.MockRouteSvc{
+			RouterEndpoint: "http://router.example.com",
+			RoutingKeys:    []string{"routingKey"},
+			// the allocated keys are registered with the router by AllocateKey
+			AddKeyErr: errors.New("add key error"),
+			AllocateKeyFunc: func(connectionID string) (string, error) {
+				pubKey, _ := generateKeyPair()
+				allocated[connectionID] = pubKey
+
+				return pubKey, nil
+			},
+		})
+
+		for _, connectionID := range []string{"connection-1", "connection-2"} {
+			didDoc, _, err := s.ctx.getDIDDocAndConnection("", connectionID)
+			require.NoError(t, err)
+
+			recipientKeys, ok := did.LookupRecipientKeys(didDoc, didCommServiceType, ed25519KeyType)
+			require.True(t, ok)
+			require.Equal(t, []string{allocated[connectionID]}, recipientKeys)
+		}
+
+		require.Len(t, allocated, 2)
+		require.NotEqual(t, allocated["connection-1"], allocated["connection-2"])
+	})
+
+	t.Run("registers the keys of the DIDs if the router is not registered", func(t *testing.T) {
+		s := newService(t, &mockroute.MockRouteSvc{AddKeyErr: errors.New("add key error")})
+
+		_, _, err := s.ctx.getDIDDocAndConnection("", "connection-1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "did doc - add key to the router")
+	})
+
+	t.Run("fails to allocate a key", func(t *testing.T) {
+		s := newService(t, &mockroute.MockRouteSvc{
+			AllocateKeyFunc: func(string) (string, error) {
+				return "", errors.New("allocate key error")
+			},
+		})
+
+		_, _, err := s.ctx.getDIDDocAndConnection("", "connection-1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "did doc - allocate connection key : allocate key error")
+	})
+}
+
 func newInvitation(target interface{}) *OOBInvitation {
 	return &OOBInvitation{
 		ID:       uuid.New().String(),
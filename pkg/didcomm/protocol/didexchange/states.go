@@ -296,17 +296,7 @@ func (s *abandoned) ExecuteInbound(msg *stateMachineMsg, thid string, ctx *conte
 
 func (ctx *context) handleInboundOOBInvitation(
 	msg *stateMachineMsg, thid string) (stateAction, *connectionstore.Record, error) {
-	// get the route configs (pass empty service endpoint, as default service endpoint added in VDRI)
-	myEndpoint, myRoutingKeys, err := route.GetRouterConfig(ctx.routeSvc, "")
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to fetch my routing configuration : %w", err)
-	}
-
-	myDID, err := ctx.vdriRegistry.Create(
-		didMethod,
-		vdri.WithServiceEndpoint(myEndpoint),
-		vdri.WithRoutingKeys(myRoutingKeys),
-	)
+	myDID, err := ctx.createConnectionDID(msg.connRecord.ConnectionID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create myDID : %w", err)
 	}
@@ -363,7 +353,7 @@ func (ctx *context) handleInboundInvitation(invitation *Invitation, thid string,
 	}
 
 	// get did document that will be used in exchange request
-	didDoc, conn, err := ctx.getDIDDocAndConnection(getPublicDID(options))
+	didDoc, conn, err := ctx.getDIDDocAndConnection(getPublicDID(options), connRec.ConnectionID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -396,6 +386,10 @@ func (ctx *context) handleInboundInvitation(invitation *Invitation, thid string,
 
 func (ctx *context) handleInboundRequest(request *Request, options *options,
 	connRec *connectionstore.Record) (stateAction, *connectionstore.Record, error) {
+	if err := ctx.claimRequest(request, connRec.ConnectionID); err != nil {
+		return nil, nil, err
+	}
+
 	requestDidDoc, err := ctx.resolveDidDocFromConnection(request.Connection)
 	if err != nil {
 		return nil, nil, fmt.Errorf("resolve did doc from exchange request connection: %w", err)
@@ -403,7 +397,7 @@ func (ctx *context) handleInboundRequest(request *Request, options *options,
 
 	// get did document that will be used in exchange response
 	// (my did doc)
-	responseDidDoc, connection, err := ctx.getDIDDocAndConnection(getPublicDID(options))
+	responseDidDoc, connection, err := ctx.getDIDDocAndConnection(getPublicDID(options), connRec.ConnectionID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -444,6 +438,27 @@ func (ctx *context) handleInboundRequest(request *Request, options *options,
 	}, connRec, nil
 }
 
+// claimRequest claims the out-of-band request request is made for, if any, with the request claimer.
+func (ctx *context) claimRequest(request *Request, connectionID string) error {
+	if request.Thread == nil || request.Thread.PID == "" {
+		return nil
+	}
+
+	ctx.claimerLock.RLock()
+	claimer := ctx.requestClaimer
+	ctx.claimerLock.RUnlock()
+
+	if claimer == nil {
+		return nil
+	}
+
+	if err := claimer(request.Thread.PID, connectionID); err != nil {
+		return fmt.Errorf("claim out-of-band request %s: %w", request.Thread.PID, err)
+	}
+
+	return nil
+}
+
 func getPublicDID(options *options) string {
 	if options == nil {
 		return ""
@@ -472,7 +487,7 @@ func (ctx *context) getDestination(invitation *Invitation) (*service.Destination
 	}, nil
 }
 
-func (ctx *context) getDIDDocAndConnection(pubDID string) (*did.Doc, *Connection, error) {
+func (ctx *context) getDIDDocAndConnection(pubDID, connectionID string) (*did.Doc, *Connection, error) {
 	if pubDID != "" {
 		logger.Debugf("using public did[%s] for connection", pubDID)
 
@@ -491,20 +506,60 @@ func (ctx *context) getDIDDocAndConnection(pubDID string) (*did.Doc, *Connection
 
 	logger.Debugf("creating new '%s' did for connection", didMethod)
 
+	newDidDoc, err := ctx.createConnectionDID(connectionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = ctx.connectionStore.SaveDIDFromDoc(newDidDoc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	connection := &Connection{
+		DID:    newDidDoc.ID,
+		DIDDoc: newDidDoc,
+	}
+
+	return newDidDoc, connection, nil
+}
+
+// connectionKeyAllocator is implemented by the route services allocating a recipient key to each connection (see
+// route.Service.AllocateKey).
+type connectionKeyAllocator interface {
+	AllocateKey(connectionID string) (string, error)
+}
+
+// createConnectionDID creates my DID of the connection connectionID. If the route service allocates a recipient key
+// to each connection and the agent is registered with a router, the DID is created with the key allocated to the
+// connection. Otherwise the recipient keys of the new DID are registered with the router, if any.
+func (ctx *context) createConnectionDID(connectionID string) (*did.Doc, error) {
 	// get the route configs (pass empty service endpoint, as default servie endpoint added in VDRI)
 	serviceEndpoint, routingKeys, err := route.GetRouterConfig(ctx.routeSvc, "")
 	if err != nil {
-		return nil, nil, fmt.Errorf("did doc - fetch router config : %w", err)
+		return nil, fmt.Errorf("did doc - fetch router config : %w", err)
+	}
+
+	opts := ctx.createDIDOpts(serviceEndpoint, routingKeys)
+
+	connectionKey, err := ctx.allocateConnectionKey(connectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if connectionKey != "" {
+		opts = append(opts, vdri.WithSigningKey(connectionKey))
 	}
 
 	// by default use peer did
-	newDidDoc, err := ctx.vdriRegistry.Create(
-		didMethod,
-		vdri.WithServiceEndpoint(serviceEndpoint),
-		vdri.WithRoutingKeys(routingKeys),
-	)
+	newDidDoc, err := ctx.vdriRegistry.Create(didMethod, opts...)
 	if err != nil {
-		return nil, nil, fmt.Errorf("create %s did: %w", didMethod, err)
+		return nil, fmt.Errorf("create %s did: %w", didMethod, err)
+	}
+
+	// the key allocated to the connection is registered with the router already
+	if connectionKey != "" {
+		return newDidDoc, nil
 	}
 
 	recipientKeys, ok := did.LookupRecipientKeys(newDidDoc, didCommServiceType, ed25519KeyType)
@@ -513,22 +568,32 @@ func (ctx *context) getDIDDocAndConnection(pubDID string) (*did.Doc, *Connection
 			// TODO https://github.com/hyperledger/aries-framework-go/issues/1105 Support to Add multiple
 			//  recKeys to the Router
 			if err = route.AddKeyToRouter(ctx.routeSvc, recKey); err != nil {
-				return nil, nil, fmt.Errorf("did doc - add key to the router : %w", err)
+				return nil, fmt.Errorf("did doc - add key to the router : %w", err)
 			}
 		}
 	}
 
-	err = ctx.connectionStore.SaveDIDFromDoc(newDidDoc)
-	if err != nil {
-		return nil, nil, err
+	return newDidDoc, nil
+}
+
+// allocateConnectionKey returns the recipient key allocated to the connection connectionID by the route service, empty
+// if the route service doesn't allocate keys to the connections or the agent is not registered with a router.
+func (ctx *context) allocateConnectionKey(connectionID string) (string, error) {
+	allocator, ok := ctx.routeSvc.(connectionKeyAllocator)
+	if !ok {
+		return "", nil
 	}
 
-	connection := &Connection{
-		DID:    newDidDoc.ID,
-		DIDDoc: newDidDoc,
+	recKey, err := allocator.AllocateKey(connectionID)
+	if errors.Is(err, route.ErrRouterNotRegistered) {
+		return "", nil
 	}
 
-	return newDidDoc, connection, nil
+	if err != nil {
+		return "", fmt.Errorf("did doc - allocate connection key : %w", err)
+	}
+
+	return recKey, nil
 }
 
 func (ctx *context) resolveDidDocFromConnection(conn *Connection) (*did.Doc, error) {
@@ -820,3 +885,22 @@ func isDID(str string) bool {
 	const didPrefix = "did:"
 	return strings.HasPrefix(str, didPrefix)
 }
+
+// createDIDOpts returns the options of the DIDs created for the connections: the service endpoint and routing keys,
+// and the key agreement key if enabled.
+func (ctx *context) createDIDOpts(serviceEndpoint string, routingKeys []string) []vdri.DocOpts {
+	opts := []vdri.DocOpts{
+		vdri.WithServiceEndpoint(serviceEndpoint),
+		vdri.WithRoutingKeys(routingKeys),
+	}
+
+	if !ctx.keyAgreement {
+		return opts
+	}
+
+	if ctx.keyAgreementKey != "" {
+		return append(opts, vdri.WithKeyAgreementKey(ctx.keyAgreementKey))
+	}
+
+	return append(opts, vdri.WithKeyAgreement(true))
+}
@@ -406,6 +406,41 @@ func TestRespondedState_Execute(t *testing.T) {
 		require.NotNil(t, connRec)
 		require.IsType(t, &noOp{}, followup)
 	})
+	t.Run("inbound requests are claimed before responding", func(t *testing.T) {
+		var claimed []string
+
+		ctx.requestClaimer = func(pthid, connectionID string) error {
+			claimed = append(claimed, connectionID)
+
+			require.Equal(t, request.Thread.PID, pthid)
+
+			if len(claimed) > 1 {
+				return errors.New("request consumed")
+			}
+
+			return nil
+		}
+
+		defer func() { ctx.requestClaimer = nil }()
+
+		_, followup, _, err := (&responded{}).ExecuteInbound(&stateMachineMsg{
+			DIDCommMsg: bytesToDIDCommMsg(t, requestPayloadBytes),
+			connRecord: &connection.Record{ConnectionID: "conn-1"},
+		}, "", ctx)
+		require.NoError(t, err)
+		require.IsType(t, &noOp{}, followup)
+
+		// the request of another acceptor is rejected, no response is sent
+		_, followup, action, err := (&responded{}).ExecuteInbound(&stateMachineMsg{
+			DIDCommMsg: bytesToDIDCommMsg(t, requestPayloadBytes),
+			connRecord: &connection.Record{ConnectionID: "conn-2"},
+		}, "", ctx)
+		require.EqualError(t, err, fmt.Sprintf("handle inbound request: claim out-of-band request %s: "+
+			"request consumed", request.Thread.PID))
+		require.Nil(t, followup)
+		require.Nil(t, action)
+		require.Equal(t, []string{"conn-1", "conn-2"}, claimed)
+	})
 	t.Run("followup to 'completed' on inbound responses", func(t *testing.T) {
 		connRec := &connection.Record{
 			State:        (&responded{}).Name(),
@@ -1001,7 +1036,7 @@ func TestGetDIDDocAndConnection(t *testing.T) {
 		ctx := context{
 			vdriRegistry:    &mockvdri.MockVDRIRegistry{ResolveValue: doc},
 			connectionStore: connectionStore}
-		didDoc, conn, err := ctx.getDIDDocAndConnection(doc.ID)
+		didDoc, conn, err := ctx.getDIDDocAndConnection(doc.ID, "")
 		require.NoError(t, err)
 		require.NotNil(t, didDoc)
 		require.NotNil(t, conn)
@@ -1010,7 +1045,7 @@ func TestGetDIDDocAndConnection(t *testing.T) {
 	t.Run("error getting public did doc from resolver", func(t *testing.T) {
 		ctx := context{
 			vdriRegistry: &mockvdri.MockVDRIRegistry{ResolveErr: errors.New("resolver error")}}
-		didDoc, conn, err := ctx.getDIDDocAndConnection("did-id")
+		didDoc, conn, err := ctx.getDIDDocAndConnection("did-id", "")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "resolver error")
 		require.Nil(t, didDoc)
@@ -1033,7 +1068,7 @@ func TestGetDIDDocAndConnection(t *testing.T) {
 		ctx := context{
 			vdriRegistry:    &mockvdri.MockVDRIRegistry{ResolveValue: doc},
 			connectionStore: connectionStore}
-		didDoc, conn, err := ctx.getDIDDocAndConnection(doc.ID)
+		didDoc, conn, err := ctx.getDIDDocAndConnection(doc.ID, "")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "did error")
 		require.Nil(t, didDoc)
@@ -1044,7 +1079,7 @@ func TestGetDIDDocAndConnection(t *testing.T) {
 			vdriRegistry: &mockvdri.MockVDRIRegistry{CreateErr: errors.New("creator error")},
 			routeSvc:     &mockroute.MockRouteSvc{},
 		}
-		didDoc, conn, err := ctx.getDIDDocAndConnection("")
+		didDoc, conn, err := ctx.getDIDDocAndConnection("", "")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "creator error")
 		require.Nil(t, didDoc)
@@ -1058,7 +1093,7 @@ func TestGetDIDDocAndConnection(t *testing.T) {
 			connectionStore: connectionStore,
 			routeSvc:        &mockroute.MockRouteSvc{},
 		}
-		didDoc, conn, err := ctx.getDIDDocAndConnection("")
+		didDoc, conn, err := ctx.getDIDDocAndConnection("", "")
 		require.NoError(t, err)
 		require.NotNil(t, didDoc)
 		require.NotNil(t, conn)
@@ -1081,7 +1116,7 @@ func TestGetDIDDocAndConnection(t *testing.T) {
 			connectionStore: connectionStore,
 			routeSvc:        &mockroute.MockRouteSvc{},
 		}
-		didDoc, conn, err := ctx.getDIDDocAndConnection("")
+		didDoc, conn, err := ctx.getDIDDocAndConnection("", "")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "did error")
 		require.Nil(t, didDoc)
@@ -1096,7 +1131,7 @@ func TestGetDIDDocAndConnection(t *testing.T) {
 			connectionStore: connectionStore,
 			routeSvc:        &mockroute.MockRouteSvc{ConfigErr: errors.New("router config error")},
 		}
-		didDoc, conn, err := ctx.getDIDDocAndConnection("")
+		didDoc, conn, err := ctx.getDIDDocAndConnection("", "")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "did doc - fetch router config")
 		require.Nil(t, didDoc)
@@ -1111,7 +1146,7 @@ func TestGetDIDDocAndConnection(t *testing.T) {
 			connectionStore: connectionStore,
 			routeSvc:        &mockroute.MockRouteSvc{AddKeyErr: errors.New("router add key error")},
 		}
-		didDoc, conn, err := ctx.getDIDDocAndConnection("")
+		didDoc, conn, err := ctx.getDIDDocAndConnection("", "")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "did doc - add key to the router")
 		require.Nil(t, didDoc)
@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didexchange
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+const (
+	// StateStoreName is the name of the store where the state of in-flight DID exchanges is persisted.
+	StateStoreName = "didexchange_state"
+
+	stateKeyPrefix = "state_"
+	limitPattern   = "%s~"
+)
+
+// protocolState is the state of an in-flight DID exchange. It is persisted (keyed by thread ID) in the permanent
+// store so the exchange can be resumed after a restart. Connection is the latest saved connection record, it is used
+// to restore the connection store if the transient store didn't survive the restart.
+type protocolState struct {
+	ThreadID      string                `json:"thid"`
+	NextStateName string                `json:"next_state"`
+	AwaitsAction  bool                  `json:"awaits_action,omitempty"`
+	Msg           service.DIDCommMsgMap `json:"msg"`
+	ConnRecord    *connection.Record    `json:"conn_record"`
+	Connection    *connection.Record    `json:"connection"`
+	PublicDID     string                `json:"public_did,omitempty"`
+	Label         string                `json:"label,omitempty"`
+}
+
+func newProtocolState(msg *message, nextStateName string, connRecord *connection.Record,
+	awaitsAction bool) *protocolState {
+	st := &protocolState{
+		ThreadID:      msg.ThreadID,
+		NextStateName: nextStateName,
+		AwaitsAction:  awaitsAction,
+		Msg:           msg.Msg,
+		ConnRecord:    msg.ConnRecord,
+		Connection:    connRecord,
+	}
+
+	if msg.Options != nil {
+		st.PublicDID = msg.Options.publicDID
+		st.Label = msg.Options.label
+	}
+
+	return st
+}
+
+// message restores the internal message from the persisted state.
+func (st *protocolState) message() *message {
+	msg := &message{
+		Msg:           st.Msg,
+		ThreadID:      st.ThreadID,
+		NextStateName: st.NextStateName,
+		ConnRecord:    st.ConnRecord,
+	}
+
+	if st.PublicDID != "" || st.Label != "" {
+		msg.Options = &options{publicDID: st.PublicDID, label: st.Label}
+	}
+
+	return msg
+}
+
+// stateStore persists the state of in-flight DID exchanges.
+type stateStore struct {
+	store storage.Store
+}
+
+func newStateStore(p provider) (*stateStore, error) {
+	store, err := p.StorageProvider().OpenStore(StateStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open state store : %w", err)
+	}
+
+	return &stateStore{store: store}, nil
+}
+
+func (s *stateStore) save(st *protocolState) error {
+	bytes, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("marshal protocol state : %w", err)
+	}
+
+	return s.store.Put(stateKey(st.ConnRecord.Namespace, st.ThreadID), bytes)
+}
+
+func (s *stateStore) delete(namespace, thID string) error {
+	err := s.store.Delete(stateKey(namespace, thID))
+	if err != nil {
+		return fmt.Errorf("delete protocol state : %w", err)
+	}
+
+	return nil
+}
+
+// all returns the states of all in-flight DID exchanges.
+func (s *stateStore) all() ([]*protocolState, error) {
+	itr := s.store.Iterator(stateKeyPrefix, fmt.Sprintf(limitPattern, stateKeyPrefix))
+	defer itr.Release()
+
+	var states []*protocolState
+
+	for itr.Next() {
+		st := &protocolState{}
+
+		err := json.Unmarshal(itr.Value(), st)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal protocol state : %w", err)
+		}
+
+		states = append(states, st)
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, fmt.Errorf("iterate protocol states : %w", err)
+	}
+
+	return states, nil
+}
+
+// stateKey namespaces the thread ID, as the inviter and the invitee of the same exchange may share the store.
+func stateKey(namespace, thID string) string {
+	return stateKeyPrefix + namespace + "_" + thID
+}
@@ -0,0 +1,195 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didexchange
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol"
+	mockroute "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/route"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestService_ResumeAfterRestart(t *testing.T) {
+	t.Run("test resume exchange waiting for an action", func(t *testing.T) {
+		prov, thid, connectionID := inviterWaitingForAction(t)
+
+		// the agent is restarted, transient data is lost
+		prov.TransientStoreProvider = mockstorage.NewMockStoreProvider()
+
+		restarted, err := New(prov)
+		require.NoError(t, err)
+
+		statusCh := make(chan service.StateMsg, 10)
+		require.NoError(t, restarted.RegisterMsgEvent(statusCh))
+
+		completedFlag := make(chan struct{})
+		respondedFlag := make(chan struct{})
+
+		go msgEventListener(t, statusCh, respondedFlag, completedFlag)
+
+		actionCh := make(chan service.DIDCommAction, 10)
+		require.NoError(t, restarted.RegisterActionEvent(actionCh))
+
+		select {
+		case action := <-actionCh:
+			require.Equal(t, RequestMsgType, action.Message.Type())
+			require.Equal(t, connectionID, action.Properties.(event).ConnectionID())
+			action.Continue(nil)
+		case <-time.After(2 * time.Second):
+			require.Fail(t, "didn't receive the action event after restart")
+		}
+
+		select {
+		case <-respondedFlag:
+		case <-time.After(2 * time.Second):
+			require.Fail(t, "didn't receive post event responded")
+		}
+
+		sendAck(t, restarted, thid)
+
+		select {
+		case <-completedFlag:
+		case <-time.After(2 * time.Second):
+			require.Fail(t, "didn't receive post event complete")
+		}
+
+		validateState(t, restarted, thid, findNamespace(AckMsgType), (&completed{}).Name())
+
+		states, err := restarted.stateStore.all()
+		require.NoError(t, err)
+		require.Empty(t, states)
+	})
+
+	t.Run("test accept exchange request after restart", func(t *testing.T) {
+		prov, thid, connectionID := inviterWaitingForAction(t)
+
+		// the agent is restarted, transient data is lost
+		prov.TransientStoreProvider = mockstorage.NewMockStoreProvider()
+
+		restarted, err := New(prov)
+		require.NoError(t, err)
+
+		validateState(t, restarted, thid, theirNSPrefix, (&requested{}).Name())
+
+		require.NoError(t, restarted.AcceptExchangeRequest(connectionID, "", ""))
+		validateState(t, restarted, thid, theirNSPrefix, (&responded{}).Name())
+
+		states, err := restarted.stateStore.all()
+		require.NoError(t, err)
+		require.Empty(t, states)
+	})
+
+	t.Run("test restore states error", func(t *testing.T) {
+		_, err := New(&protocol.MockProvider{
+			StoreProvider: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+				Store:  make(map[string][]byte),
+				ErrItr: errors.New("iterator error"),
+			}),
+			ServiceMap: map[string]interface{}{
+				route.Coordination: &mockroute.MockRouteSvc{},
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resume did exchanges")
+	})
+}
+
+// inviterWaitingForAction handles the exchange request on the inviter side and stops when the request waits for
+// an action, it returns the provider of the stopped agent, the thread ID and the connection ID of the exchange.
+func inviterWaitingForAction(t *testing.T) (*protocol.MockProvider, string, string) {
+	pubKey, _ := generateKeyPair()
+	doc := createDIDDocWithKey(pubKey)
+
+	prov := &protocol.MockProvider{
+		StoreProvider:          mockstorage.NewMockStoreProvider(),
+		TransientStoreProvider: mockstorage.NewMockStoreProvider(),
+		ServiceMap: map[string]interface{}{
+			route.Coordination: &mockroute.MockRouteSvc{},
+		},
+	}
+
+	s, err := New(prov)
+	require.NoError(t, err)
+
+	actionCh := make(chan service.DIDCommAction, 10)
+	require.NoError(t, s.RegisterActionEvent(actionCh))
+
+	invitation := &Invitation{
+		Type:            InvitationMsgType,
+		ID:              randomString(),
+		Label:           "Bob",
+		RecipientKeys:   []string{pubKey},
+		ServiceEndpoint: "http://alice.agent.example.com:8081",
+	}
+
+	require.NoError(t, s.connectionStore.SaveInvitation(invitation.ID, invitation))
+
+	thid := randomString()
+
+	payloadBytes, err := json.Marshal(
+		&Request{
+			Type:  RequestMsgType,
+			ID:    thid,
+			Label: "Bob",
+			Thread: &decorator.Thread{
+				PID: invitation.ID,
+			},
+			Connection: &Connection{
+				DID:    doc.ID,
+				DIDDoc: doc,
+			},
+		})
+	require.NoError(t, err)
+
+	msg, err := service.ParseDIDCommMsgMap(payloadBytes)
+	require.NoError(t, err)
+
+	connectionID, err := s.HandleInbound(msg, doc.ID, "")
+	require.NoError(t, err)
+
+	// the agent stops before the action is executed
+	select {
+	case <-actionCh:
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "didn't receive the action event")
+	}
+
+	states, err := s.stateStore.all()
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	require.True(t, states[0].AwaitsAction)
+	require.Equal(t, thid, states[0].ThreadID)
+
+	return prov, thid, connectionID
+}
+
+func sendAck(t *testing.T, s *Service, thid string) {
+	payloadBytes, err := json.Marshal(
+		&model.Ack{
+			Type:   AckMsgType,
+			ID:     randomString(),
+			Status: "OK",
+			Thread: &decorator.Thread{ID: thid},
+		})
+	require.NoError(t, err)
+
+	msg, err := service.ParseDIDCommMsgMap(payloadBytes)
+	require.NoError(t, err)
+
+	_, err = s.HandleInbound(msg, "myDID", "theirDID")
+	require.NoError(t, err)
+}
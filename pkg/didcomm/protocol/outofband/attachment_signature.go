@@ -0,0 +1,262 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+)
+
+// ErrUnsignedAttachment is returned when verifying an attachment which is not signed (see SignAttachment).
+var ErrUnsignedAttachment = errors.New("out-of-band request attachment not signed")
+
+// SignAttachment signs the inlined content of the attachment a with the inviter's key: the signature is a detached
+// JWS of the base64 decoded data or of the canonical JSON data, set in a.Data.JWS (see decorator.AttachmentJWS).
+// Like with SignRequest, the signer headers must have the EdDSA algorithm and a key ID of the inviter. Attachments
+// are signed before the request.
+func SignAttachment(a *decorator.Attachment, signer jose.Signer) error {
+	payload, err := attachmentPayload(a)
+	if err != nil {
+		return fmt.Errorf("sign attachment: %w", err)
+	}
+
+	jws, err := jose.NewJWS(nil, nil, payload, signer)
+	if err != nil {
+		return fmt.Errorf("sign attachment: %w", err)
+	}
+
+	compact, err := jws.SerializeCompact(true)
+	if err != nil {
+		return fmt.Errorf("sign attachment: %w", err)
+	}
+
+	// the compact serialization with detached payload is: protected..signature
+	parts := strings.Split(compact, ".")
+
+	a.Data.JWS = &decorator.AttachmentJWS{Protected: parts[0], Signature: parts[2]}
+
+	if kid, ok := signer.Headers().KeyID(); ok {
+		a.Data.JWS.Header = map[string]interface{}{jose.HeaderKeyID: kid}
+	}
+
+	return nil
+}
+
+// AttachmentHandle is an attachment of an accepted request whose signature is verified lazily, on first access:
+// the inviter's key is resolved and the signature verified once, the result is cached.
+type AttachmentHandle struct {
+	// Attachment is the attachment, not verified.
+	Attachment *decorator.Attachment
+	verify     func() error
+	once       sync.Once
+	err        error
+}
+
+// Verify verifies the signature of the attachment the first time it is called, it returns an error wrapping
+// ErrInvalidSignature if the signature is invalid, or ErrUnsignedAttachment if the attachment is not signed.
+func (h *AttachmentHandle) Verify() error {
+	h.once.Do(func() {
+		h.err = h.verify()
+	})
+
+	return h.err
+}
+
+// Content returns the inlined content of the attachment once its signature is verified (see Verify).
+func (h *AttachmentHandle) Content() ([]byte, error) {
+	if err := h.Verify(); err != nil {
+		return nil, err
+	}
+
+	return extractDIDCommMsgBytes(h.Attachment)
+}
+
+// AcceptRequestWithAttachments accepts the request r like AcceptRequest and returns, with the connection ID, the
+// handles of the request attachments. The attachment signatures are not verified when the request is accepted but
+// when the attachments are accessed, so only the attachments used by the application are verified, and a failed
+// verification fails the access to its attachment only. The signature of the selected attachment is verified, through
+// its handle, before its protocol is started.
+func (s *Service) AcceptRequestWithAttachments(r *Request, opts ...AcceptOption) (string, []*AttachmentHandle, error) {
+	c := &callback{}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	handles := make([]*AttachmentHandle, len(r.Requests))
+
+	for i := range r.Requests {
+		a := r.Requests[i]
+
+		handles[i] = &AttachmentHandle{
+			Attachment: a,
+			verify: func() error {
+				return s.verifyAttachment(r.Service, &c.inviter, a)
+			},
+		}
+	}
+
+	// the handles are kept for the protocol started once the DID exchange completes, they expire with the request
+	// (see RemoveExpiredPendingRequests)
+	s.attachmentHandles.set(r.ID, handles, s.pendingRequestExpiry(r))
+
+	connID, err := s.AcceptRequest(r, opts...)
+	if err != nil {
+		s.attachmentHandles.remove(r.ID)
+
+		return "", nil, err
+	}
+
+	return connID, handles, nil
+}
+
+// verifySelectedAttachment verifies the signature of the attachment a of the request of state, selected to start a
+// protocol, if it is signed. The signature is verified through the handle of the attachment if the request was
+// accepted with AcceptRequestWithAttachments.
+func (s *Service) verifySelectedAttachment(state *myState, a *decorator.Attachment) error {
+	r := state.Request
+
+	if a.Data.JWS == nil {
+		return nil
+	}
+
+	if h := s.attachmentHandles.get(r.ID, a.ID); h != nil {
+		return h.Verify()
+	}
+
+	return s.verifyAttachment(r.Service, &state.Inviter, a)
+}
+
+// attachmentHandles are the handles of the attachments of the requests accepted with AcceptRequestWithAttachments,
+// by request ID, until their protocol is started or their request expires.
+type attachmentHandles struct {
+	mu      sync.Mutex
+	handles map[string]*requestHandles
+}
+
+type requestHandles struct {
+	handles []*AttachmentHandle
+	expiry  time.Time
+}
+
+// set sets the handles of the request requestID, which expire at expiry, never if zero.
+func (a *attachmentHandles) set(requestID string, handles []*AttachmentHandle, expiry time.Time) {
+	a.mu.Lock()
+	a.handles[requestID] = &requestHandles{handles: handles, expiry: expiry}
+	a.mu.Unlock()
+}
+
+func (a *attachmentHandles) get(requestID, attachmentID string) *AttachmentHandle {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rh, ok := a.handles[requestID]
+	if !ok {
+		return nil
+	}
+
+	for _, h := range rh.handles {
+		if h.Attachment.ID == attachmentID {
+			return h
+		}
+	}
+
+	return nil
+}
+
+func (a *attachmentHandles) remove(requestID string) {
+	a.mu.Lock()
+	delete(a.handles, requestID)
+	a.mu.Unlock()
+}
+
+// removeExpired removes the handles expired at now.
+func (a *attachmentHandles) removeExpired(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for id, rh := range a.handles {
+		if !rh.expiry.IsZero() && rh.expiry.Before(now) {
+			delete(a.handles, id)
+		}
+	}
+}
+
+// verifyAttachment verifies the signature of the attachment a with the inviter's key, like verifyRequest: the
+// pinned key, or else the key identified by the signature key ID among the keys of the DIDs of the services svcs.
+func (s *Service) verifyAttachment(svcs []interface{}, pins *inviterPins, a *decorator.Attachment) error {
+	if a.Data.JWS == nil {
+		return fmt.Errorf("%w : %s", ErrUnsignedAttachment, a.ID)
+	}
+
+	payload, err := attachmentPayload(a)
+	if err != nil {
+		return fmt.Errorf("%w: attachment %s : %v", ErrInvalidSignature, a.ID, err)
+	}
+
+	compact := a.Data.JWS.Protected + ".." + a.Data.JWS.Signature
+
+	_, err = jose.ParseJWS(compact, jose.SignatureVerifierFunc(
+		func(joseHeaders jose.Headers, _, signingInput, signature []byte) error {
+			// the key ID may be in the unprotected header only, the key is verified to be the inviter's anyway
+			if _, ok := joseHeaders.KeyID(); !ok {
+				joseHeaders = mergeKeyID(joseHeaders, a.Data.JWS.Header)
+			}
+
+			return s.verifySignature(svcs, pins, joseHeaders, signingInput, signature)
+		}), jose.WithJWSDetachedPayload(payload))
+	if err != nil {
+		return fmt.Errorf("%w: attachment %s : %v", ErrInvalidSignature, a.ID, err)
+	}
+
+	return nil
+}
+
+// mergeKeyID returns the JOSE headers with the key ID of the unprotected header, if it has one.
+func mergeKeyID(headers jose.Headers, unprotected map[string]interface{}) jose.Headers {
+	kid, ok := unprotected[jose.HeaderKeyID]
+	if !ok {
+		return headers
+	}
+
+	merged := jose.Headers{jose.HeaderKeyID: kid}
+
+	for k, v := range headers {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// attachmentPayload returns the signed payload of the attachment a: its base64 decoded data or its canonical JSON
+// data, with sorted object members.
+func attachmentPayload(a *decorator.Attachment) ([]byte, error) {
+	content, err := extractDIDCommMsgBytes(a)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.Data.JSON == nil {
+		return content, nil
+	}
+
+	var generic interface{}
+
+	err = json.Unmarshal(content, &generic)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal attachment data: %w", err)
+	}
+
+	return json.Marshal(generic)
+}
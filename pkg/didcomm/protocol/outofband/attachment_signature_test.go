@@ -0,0 +1,367 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+func TestAcceptRequestWithAttachments(t *testing.T) {
+	const didID = "did:example:inviter"
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer := &testSigner{privKey: privKey, kid: didID + "#key-1"}
+
+	var resolved int32
+
+	provider := testProvider()
+	provider.CustomVDRI = &mockvdri.MockVDRIRegistry{
+		ResolveFunc: func(didID string, _ ...vdriapi.ResolveOpts) (*did.Doc, error) {
+			atomic.AddInt32(&resolved, 1)
+
+			return &did.Doc{
+				ID: didID,
+				PublicKey: []did.PublicKey{{
+					ID: didID + "#key-1", Type: ed25519KeyType, Controller: didID, Value: pubKey,
+				}},
+			}, nil
+		},
+	}
+
+	newSignedRequest := func(t *testing.T) *Request {
+		req := newRequest()
+		req.Service = []interface{}{didID}
+		req.Requests = nil
+
+		for i := 0; i < 3; i++ {
+			a := &decorator.Attachment{
+				ID: uuid.New().String(),
+				Data: decorator.AttachmentData{JSON: map[string]interface{}{
+					"@id":   uuid.New().String(),
+					"@type": fmt.Sprintf("https://didcomm.org/test/1.0/message-%d", i),
+				}},
+			}
+
+			require.NoError(t, SignAttachment(a, signer))
+			require.NotEmpty(t, a.Data.JWS)
+
+			req.Requests = append(req.Requests, a)
+		}
+
+		return req
+	}
+
+	t.Run("only the accessed attachment is verified", func(t *testing.T) {
+		s := newAutoService(t, provider)
+		atomic.StoreInt32(&resolved, 0)
+
+		_, handles, err := s.AcceptRequestWithAttachments(newSignedRequest(t))
+		require.NoError(t, err)
+		require.Len(t, handles, 3)
+
+		// nothing is verified on accept
+		require.Zero(t, atomic.LoadInt32(&resolved))
+
+		content, err := handles[1].Content()
+		require.NoError(t, err)
+		require.Contains(t, string(content), "message-1")
+		require.EqualValues(t, 1, atomic.LoadInt32(&resolved))
+
+		// the verification is cached
+		require.NoError(t, handles[1].Verify())
+		require.EqualValues(t, 1, atomic.LoadInt32(&resolved))
+	})
+
+	t.Run("verification errors fail their attachment only", func(t *testing.T) {
+		s := newAutoService(t, provider)
+
+		req := newSignedRequest(t)
+		req.Requests[0].Data.JSON.(map[string]interface{})["tampered"] = true
+
+		_, handles, err := s.AcceptRequestWithAttachments(req)
+		require.NoError(t, err)
+
+		_, err = handles[0].Content()
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.Contains(t, err.Error(), "attachment "+req.Requests[0].ID)
+
+		require.NoError(t, handles[2].Verify())
+	})
+
+	t.Run("base64 attachments", func(t *testing.T) {
+		s := newAutoService(t, provider)
+
+		req := newSignedRequest(t)
+		req.Requests = req.Requests[:1]
+		req.Requests[0].Data = decorator.AttachmentData{
+			Base64: base64.StdEncoding.EncodeToString([]byte(`{"@type":"https://didcomm.org/test/1.0/message"}`)),
+		}
+		require.NoError(t, SignAttachment(req.Requests[0], signer))
+
+		_, handles, err := s.AcceptRequestWithAttachments(req)
+		require.NoError(t, err)
+		require.NoError(t, handles[0].Verify())
+	})
+
+	t.Run("unsigned attachments", func(t *testing.T) {
+		s := newAutoService(t, provider)
+
+		req := newRequest()
+
+		_, handles, err := s.AcceptRequestWithAttachments(req)
+		require.NoError(t, err)
+
+		_, err = handles[0].Content()
+		require.True(t, errors.Is(err, ErrUnsignedAttachment))
+	})
+
+	t.Run("accept errors", func(t *testing.T) {
+		s := newAutoService(t, provider, WithUnsignedRequests(false))
+
+		_, handles, err := s.AcceptRequestWithAttachments(newSignedRequest(t))
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.Nil(t, handles)
+	})
+
+	t.Run("the selected attachment is verified before its protocol is started", func(t *testing.T) {
+		var dispatched int32
+
+		provider := testProvider()
+		provider.CustomVDRI = &mockvdri.MockVDRIRegistry{
+			ResolveFunc: func(didID string, _ ...vdriapi.ResolveOpts) (*did.Doc, error) {
+				atomic.AddInt32(&resolved, 1)
+
+				return &did.Doc{
+					ID: didID,
+					PublicKey: []did.PublicKey{{
+						ID: didID + "#key-1", Type: ed25519KeyType, Controller: didID, Value: pubKey,
+					}},
+				}, nil
+			},
+		}
+		provider.InboundMsgHandler = func([]byte, string, string) error {
+			atomic.AddInt32(&dispatched, 1)
+
+			return nil
+		}
+
+		connID := uuid.New().String()
+
+		r, err := connection.NewRecorder(provider)
+		require.NoError(t, err)
+		require.NoError(t, r.SaveConnectionRecord(&connection.Record{
+			ConnectionID: connID, MyDID: "did:example:mine", TheirDID: "did:example:theirs",
+		}))
+
+		// the state of the request, as stored until the DID exchange completes
+		stateOf := func(t *testing.T, req *Request) *myState {
+			bytes, err := json.Marshal(&myState{ID: uuid.New().String(), ConnectionID: connID, Request: req})
+			require.NoError(t, err)
+
+			state := &myState{}
+			require.NoError(t, json.Unmarshal(bytes, state))
+
+			return state
+		}
+
+		s := newAutoService(t, provider)
+
+		// a tampered signed attachment is not dispatched
+		req := newSignedRequest(t)
+		req.Requests[0].Data.JSON.(map[string]interface{})["tampered"] = true
+
+		_, _, err = s.AcceptRequestWithAttachments(req)
+		require.NoError(t, err)
+
+		state := stateOf(t, req)
+
+		err = s.startProtocol(state)
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.False(t, state.Done)
+		require.Zero(t, atomic.LoadInt32(&dispatched))
+
+		// the same for a request accepted without attachment handles
+		_, err = s.AcceptRequest(req)
+		require.NoError(t, err)
+
+		err = s.startProtocol(stateOf(t, req))
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.Zero(t, atomic.LoadInt32(&dispatched))
+
+		// a valid attachment is verified once, through its handle
+		req = newSignedRequest(t)
+
+		_, handles, err := s.AcceptRequestWithAttachments(req)
+		require.NoError(t, err)
+
+		atomic.StoreInt32(&resolved, 0)
+		require.NoError(t, handles[0].Verify())
+
+		state = stateOf(t, req)
+		require.NoError(t, s.startProtocol(state))
+		require.True(t, state.Done)
+		require.EqualValues(t, 1, atomic.LoadInt32(&dispatched))
+		require.EqualValues(t, 1, atomic.LoadInt32(&resolved))
+	})
+
+	t.Run("the handles expire with their request", func(t *testing.T) {
+		s := newAutoService(t, provider, WithPendingRequestTTL(time.Millisecond))
+
+		expiring := newSignedRequest(t)
+		_, _, err := s.AcceptRequestWithAttachments(expiring)
+		require.NoError(t, err)
+
+		s.pendingRequestTTL = 0
+
+		// no expiry
+		pending := newSignedRequest(t)
+		_, _, err = s.AcceptRequestWithAttachments(pending)
+		require.NoError(t, err)
+
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = s.RemoveExpiredPendingRequests()
+		require.NoError(t, err)
+
+		require.Nil(t, s.attachmentHandles.get(expiring.ID, expiring.Requests[0].ID))
+		require.NotNil(t, s.attachmentHandles.get(pending.ID, pending.Requests[0].ID))
+	})
+
+	t.Run("the handles are removed with their pending request", func(t *testing.T) {
+		s := newAutoService(t, provider)
+
+		req := newSignedRequest(t)
+		req.Timing = &decorator.Timing{ExpiresTime: time.Now().Add(time.Hour)}
+		require.NoError(t, s.SavePendingRequest(req))
+
+		_, _, err := s.AcceptRequestWithAttachments(req)
+		require.NoError(t, err)
+		require.NotNil(t, s.attachmentHandles.get(req.ID, req.Requests[0].ID))
+
+		require.NoError(t, s.removePendingRequest(req.ID))
+		require.Nil(t, s.attachmentHandles.get(req.ID, req.Requests[0].ID))
+	})
+
+	t.Run("attachments signed with an inlined key are verified with the pinned key", func(t *testing.T) {
+		verKey := base58.Encode(pubKey)
+
+		s := newAutoService(t, provider)
+
+		req := newRequest()
+		req.Service = []interface{}{&did.Service{
+			ID:              uuid.New().String(),
+			Type:            "did-communication",
+			RecipientKeys:   []string{verKey},
+			ServiceEndpoint: "https://inviter.example.com",
+		}}
+		require.NoError(t, SignAttachment(req.Requests[0], &testSigner{privKey: privKey, kid: verKey}))
+
+		_, handles, err := s.AcceptRequestWithAttachments(req)
+		require.NoError(t, err)
+		require.True(t, errors.Is(handles[0].Verify(), ErrInvalidSignature))
+
+		_, handles, err = s.AcceptRequestWithAttachments(req, WithInviterKey(verKey))
+		require.NoError(t, err)
+		require.NoError(t, handles[0].Verify())
+
+		// the pinned key is kept with the state of the request, until its protocol is started
+		s.attachmentHandles.remove(req.ID)
+
+		err = s.verifySelectedAttachment(&myState{Request: req}, req.Requests[0])
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+
+		err = s.verifySelectedAttachment(&myState{Request: req, Inviter: inviterPins{Key: verKey}}, req.Requests[0])
+		require.NoError(t, err)
+	})
+
+	t.Run("the signature is the JWS object of RFC 0017", func(t *testing.T) {
+		req := newSignedRequest(t)
+
+		bytes, err := json.Marshal(req.Requests[0])
+		require.NoError(t, err)
+
+		raw := struct {
+			Data struct {
+				JWS map[string]interface{} `json:"jws"`
+			} `json:"data"`
+		}{}
+		require.NoError(t, json.Unmarshal(bytes, &raw))
+		require.NotEmpty(t, raw.Data.JWS["protected"])
+		require.NotEmpty(t, raw.Data.JWS["signature"])
+		require.Equal(t, map[string]interface{}{"kid": didID + "#key-1"}, raw.Data.JWS["header"])
+
+		// the signature is verified once the request is transmitted
+		transmitted := &Request{}
+		require.NoError(t, json.Unmarshal(mustMarshal(t, req), transmitted))
+
+		_, handles, err := newAutoService(t, provider).AcceptRequestWithAttachments(transmitted)
+		require.NoError(t, err)
+		require.NoError(t, handles[0].Verify())
+	})
+
+	t.Run("the key ID may be in the unprotected header only", func(t *testing.T) {
+		req := newSignedRequest(t)
+		req.Requests = req.Requests[:1]
+
+		require.NoError(t, SignAttachment(req.Requests[0], &unprotectedKeyIDSigner{signer}))
+		require.Nil(t, req.Requests[0].Data.JWS.Header)
+
+		_, handles, err := newAutoService(t, provider).AcceptRequestWithAttachments(req)
+		require.NoError(t, err)
+		require.True(t, errors.Is(handles[0].Verify(), ErrInvalidSignature))
+
+		req.Requests[0].Data.JWS.Header = map[string]interface{}{"kid": signer.kid}
+
+		_, handles, err = newAutoService(t, provider).AcceptRequestWithAttachments(req)
+		require.NoError(t, err)
+		require.NoError(t, handles[0].Verify())
+	})
+
+	t.Run("sign attachment without inlined content", func(t *testing.T) {
+		err := SignAttachment(&decorator.Attachment{ID: "a"}, signer)
+		require.EqualError(t, err, "sign attachment: attachment a : no inlined DIDComm message")
+	})
+}
+
+// unprotectedKeyIDSigner signs without key ID in the protected header.
+type unprotectedKeyIDSigner struct {
+	*testSigner
+}
+
+func (s *unprotectedKeyIDSigner) Headers() jose.Headers {
+	return jose.Headers{jose.HeaderAlgorithm: SignatureAlgorithm}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+
+	bytes, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return bytes
+}
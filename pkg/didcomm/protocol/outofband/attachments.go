@@ -0,0 +1,117 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+)
+
+// ErrAttachmentNotFound is returned when accepting a request with a selected attachment (see WithSelectedAttachment)
+// which is not one of the request's attachments.
+var ErrAttachmentNotFound = errors.New("out-of-band request attachment not found")
+
+// WithSelectedAttachment option selects the attachment of the request, by its '@id', whose protocol is started
+// once the did-exchange completes. A request may offer several protocols (eg: a credential offer and a proof
+// request), the first attachment is selected by default.
+func WithSelectedAttachment(id string) AcceptOption {
+	return func(opts *callback) {
+		opts.selectedAttachment = id
+	}
+}
+
+// AttachedMsgType returns the '@type' of the DIDComm message inlined (base64 or JSON data) in the attachment.
+func AttachedMsgType(a *decorator.Attachment) (string, error) {
+	bytes, err := extractDIDCommMsgBytes(a)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := service.ParseDIDCommMsgMap(bytes)
+	if err != nil {
+		return "", fmt.Errorf("attachment %s : %w", a.ID, err)
+	}
+
+	if msg.Type() == "" {
+		return "", fmt.Errorf("attachment %s : message has no @type", a.ID)
+	}
+
+	return msg.Type(), nil
+}
+
+// CheckAttachments checks that the attachments of a request offering several protocols each inline a DIDComm
+// message, of distinct '@type's, so the protocol started is unambiguous when one of them is selected.
+func CheckAttachments(attachments []*decorator.Attachment) error {
+	if len(attachments) < 2 {
+		return nil
+	}
+
+	ids := make(map[string]struct{}, len(attachments))
+	msgTypes := make(map[string]string, len(attachments))
+
+	for _, a := range attachments {
+		if _, found := ids[a.ID]; a.ID == "" || found {
+			return fmt.Errorf("attachments of a request with several attachments must have distinct @id : '%s'", a.ID)
+		}
+
+		ids[a.ID] = struct{}{}
+
+		msgType, err := AttachedMsgType(a)
+		if err != nil {
+			return err
+		}
+
+		if other, found := msgTypes[msgType]; found {
+			return fmt.Errorf("attachments %s and %s have the same message @type %s", other, a.ID, msgType)
+		}
+
+		msgTypes[msgType] = a.ID
+	}
+
+	return nil
+}
+
+func extractDIDCommMsgBytes(a *decorator.Attachment) ([]byte, error) {
+	switch {
+	case a.Data.JSON != nil:
+		return json.Marshal(a.Data.JSON)
+	case a.Data.Base64 != "":
+		bytes, err := base64.StdEncoding.DecodeString(a.Data.Base64)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %s : failed to decode base64 data : %w", a.ID, err)
+		}
+
+		return bytes, nil
+	default:
+		return nil, fmt.Errorf("attachment %s : no inlined DIDComm message", a.ID)
+	}
+}
+
+// selectAttachment returns the attachment id of the request, or its first attachment if id is empty. The credential
+// manifest attachment, if any, is not selected as it doesn't start a protocol.
+func selectAttachment(req *Request, id string) (*decorator.Attachment, error) {
+	for _, a := range req.Requests {
+		if isCredentialManifest(a) {
+			continue
+		}
+
+		if id == "" || a.ID == id {
+			return a, nil
+		}
+	}
+
+	if id == "" {
+		return nil, fmt.Errorf("%w : the request has no attachments", ErrAttachmentNotFound)
+	}
+
+	return nil, fmt.Errorf("%w : %s", ErrAttachmentNotFound, id)
+}
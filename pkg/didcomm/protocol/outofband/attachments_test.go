@@ -0,0 +1,221 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/issuecredential"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/presentproof"
+	mockdidexchange "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/didexchange"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+func TestAttachedMsgType(t *testing.T) {
+	t.Run("returns the type of a base64 message", func(t *testing.T) {
+		msgType, err := AttachedMsgType(base64Attachment(t, &issuecredential.OfferCredential{
+			Type: issuecredential.OfferCredentialMsgType,
+		}))
+		require.NoError(t, err)
+		require.Equal(t, issuecredential.OfferCredentialMsgType, msgType)
+	})
+	t.Run("returns the type of a JSON message", func(t *testing.T) {
+		msgType, err := AttachedMsgType(&decorator.Attachment{
+			ID: uuid.New().String(),
+			Data: decorator.AttachmentData{
+				JSON: map[string]interface{}{"@type": presentproof.RequestPresentationMsgType},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, presentproof.RequestPresentationMsgType, msgType)
+	})
+	t.Run("fails if the message has no type", func(t *testing.T) {
+		_, err := AttachedMsgType(base64Attachment(t, map[string]interface{}{"comment": "test"}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "message has no @type")
+	})
+	t.Run("fails if the data is not a message", func(t *testing.T) {
+		_, err := AttachedMsgType(&decorator.Attachment{
+			Data: decorator.AttachmentData{Base64: base64.StdEncoding.EncodeToString([]byte("test"))},
+		})
+		require.Error(t, err)
+	})
+	t.Run("fails if the data is not valid base64", func(t *testing.T) {
+		_, err := AttachedMsgType(&decorator.Attachment{Data: decorator.AttachmentData{Base64: "%"}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode base64 data")
+	})
+	t.Run("fails if there is no inlined message", func(t *testing.T) {
+		_, err := AttachedMsgType(&decorator.Attachment{
+			Data: decorator.AttachmentData{Links: []string{"https://example.com/offer"}},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no inlined DIDComm message")
+	})
+}
+
+func TestCheckAttachments(t *testing.T) {
+	t.Run("accepts attachments of distinct protocols", func(t *testing.T) {
+		require.NoError(t, CheckAttachments(newMultiProtocolRequest(t).Requests))
+	})
+	t.Run("accepts a single attachment without a message type", func(t *testing.T) {
+		require.NoError(t, CheckAttachments(newRequest().Requests))
+	})
+	t.Run("rejects attachments of the same message type", func(t *testing.T) {
+		first := base64Attachment(t, &issuecredential.OfferCredential{Type: issuecredential.OfferCredentialMsgType})
+		second := base64Attachment(t, &issuecredential.OfferCredential{Type: issuecredential.OfferCredentialMsgType})
+		err := CheckAttachments([]*decorator.Attachment{first, second})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "have the same message @type")
+	})
+	t.Run("rejects attachments with the same id", func(t *testing.T) {
+		req := newMultiProtocolRequest(t)
+		req.Requests[1].ID = req.Requests[0].ID
+		err := CheckAttachments(req.Requests)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "must have distinct @id")
+	})
+	t.Run("rejects attachments without a message", func(t *testing.T) {
+		req := newMultiProtocolRequest(t)
+		req.Requests = append(req.Requests, newRequest().Requests...)
+		require.Error(t, CheckAttachments(req.Requests))
+	})
+}
+
+func TestMultiProtocolRequest(t *testing.T) {
+	t.Run("starts the protocol of the selected attachment", func(t *testing.T) {
+		for _, expected := range []string{
+			issuecredential.OfferCredentialMsgType,
+			presentproof.RequestPresentationMsgType,
+		} {
+			req := newMultiProtocolRequest(t)
+			selected := attachmentOfType(t, req, expected)
+			dispatched := acceptAndCompleteDIDExchange(t, req, WithSelectedAttachment(selected.ID))
+
+			msg, err := service.ParseDIDCommMsgMap(dispatched)
+			require.NoError(t, err)
+			require.Equal(t, expected, msg.Type())
+		}
+	})
+	t.Run("starts the protocol of the first attachment by default", func(t *testing.T) {
+		req := newMultiProtocolRequest(t)
+		dispatched := acceptAndCompleteDIDExchange(t, req)
+
+		msg, err := service.ParseDIDCommMsgMap(dispatched)
+		require.NoError(t, err)
+		require.Equal(t, issuecredential.OfferCredentialMsgType, msg.Type())
+	})
+	t.Run("fails to accept a request without the selected attachment", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+		_, err := s.AcceptRequest(newMultiProtocolRequest(t), WithSelectedAttachment(uuid.New().String()))
+		require.True(t, errors.Is(err, ErrAttachmentNotFound))
+	})
+}
+
+// acceptAndCompleteDIDExchange accepts the request, completes the did-exchange and returns the message dispatched.
+func acceptAndCompleteDIDExchange(t *testing.T, req *Request, opts ...AcceptOption) []byte {
+	connID := uuid.New().String()
+	dispatched := make(chan []byte, 1)
+
+	var pthid string
+
+	provider := testProvider()
+	provider.ServiceMap = map[string]interface{}{
+		didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{
+			RespondToFunc: func(invitation *didexchange.OOBInvitation) (string, error) {
+				// the pthid of the did-exchange thread is the ID of the invitation
+				pthid = invitation.ID
+
+				return connID, nil
+			},
+		},
+	}
+	provider.InboundMsgHandler = func(msg []byte, _, _ string) error {
+		dispatched <- msg
+		return nil
+	}
+
+	r, err := connection.NewRecorder(provider)
+	require.NoError(t, err)
+	require.NoError(t, r.SaveConnectionRecord(&connection.Record{
+		ConnectionID: connID,
+		MyDID:        "did:example:mine",
+		TheirDID:     "did:example:theirs",
+	}))
+
+	s := newAutoService(t, provider)
+
+	_, err = s.AcceptRequest(req, opts...)
+	require.NoError(t, err)
+
+	require.NoError(t, s.handleDIDEvent(service.StateMsg{
+		ProtocolName: didexchange.DIDExchange,
+		Type:         service.PostState,
+		Msg:          service.NewDIDCommMsgMap(newAck(pthid)),
+	}))
+
+	select {
+	case msg := <-dispatched:
+		return msg
+	case <-time.After(time.Second):
+		require.FailNow(t, "timeout waiting for the protocol message to be dispatched")
+	}
+
+	return nil
+}
+
+func newMultiProtocolRequest(t *testing.T) *Request {
+	req := newRequest()
+	req.Requests = []*decorator.Attachment{
+		base64Attachment(t, &issuecredential.OfferCredential{
+			Type:    issuecredential.OfferCredentialMsgType,
+			Comment: "credential offer",
+		}),
+		base64Attachment(t, &presentproof.RequestPresentation{
+			Type:    presentproof.RequestPresentationMsgType,
+			Comment: "proof request",
+		}),
+	}
+
+	return req
+}
+
+func attachmentOfType(t *testing.T, req *Request, msgType string) *decorator.Attachment {
+	for _, a := range req.Requests {
+		if attachedType, err := AttachedMsgType(a); err == nil && attachedType == msgType {
+			return a
+		}
+	}
+
+	require.FailNow(t, "no attachment of type "+msgType)
+
+	return nil
+}
+
+func base64Attachment(t *testing.T, msg interface{}) *decorator.Attachment {
+	bytes, err := json.Marshal(msg)
+	require.NoError(t, err)
+
+	return &decorator.Attachment{
+		ID:          uuid.New().String(),
+		MimeType:    "application/json",
+		LastModTime: time.Now(),
+		Data: decorator.AttachmentData{
+			Base64: base64.StdEncoding.EncodeToString(bytes),
+		},
+	}
+}
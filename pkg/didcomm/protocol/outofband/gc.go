@@ -0,0 +1,165 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// StateIDPendingRequestExpired is the state of the message events fired when an expired pending request is
+	// removed by the garbage collector (see StartPendingRequestGC), the message of the event is the request.
+	StateIDPendingRequestExpired = "pending-request-expired"
+
+	// key prefix of the expiry of the pending requests
+	pendingRequestExpiryKeyPrefix = "pending-request-expiry-"
+)
+
+// WithPendingRequestTTL option sets the time to live of the pending requests (see SavePendingRequest) which have no
+// expires_time in their ~timing decorator. Pending requests without expiry are never removed, this is the default.
+func WithPendingRequestTTL(ttl time.Duration) ServiceOption {
+	return func(opts *Service) {
+		opts.pendingRequestTTL = ttl
+	}
+}
+
+// pendingRequestExpiry returns the expiry of the pending request r, zero if it does not expire.
+func (s *Service) pendingRequestExpiry(r *Request) time.Time {
+	if r.Timing != nil && !r.Timing.ExpiresTime.IsZero() {
+		return r.Timing.ExpiresTime
+	}
+
+	if s.pendingRequestTTL > 0 {
+		return time.Now().Add(s.pendingRequestTTL)
+	}
+
+	return time.Time{}
+}
+
+// savePendingRequestExpiry saves the expiry of the pending request r, if it expires.
+func (s *Service) savePendingRequestExpiry(r *Request) error {
+	expiry := s.pendingRequestExpiry(r)
+	if expiry.IsZero() {
+		return nil
+	}
+
+	return s.revocations.Put(pendingRequestExpiryKeyPrefix+r.ID, []byte(expiry.UTC().Format(time.RFC3339Nano)))
+}
+
+// StartPendingRequestGC starts the garbage collector of the pending requests (see SavePendingRequest): the expired
+// pending requests are removed every interval, until ctx is done. A message event with state
+// StateIDPendingRequestExpired is fired for each removed request.
+func (s *Service) StartPendingRequestGC(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.RemoveExpiredPendingRequests(); err != nil {
+					logger.Errorf("failed to remove expired pending requests : %s", err)
+				}
+			}
+		}
+	}()
+}
+
+// RemoveExpiredPendingRequests removes the pending requests expired by now and returns their IDs. A message event
+// with state StateIDPendingRequestExpired is fired for each removed request. The attachment handles of the expired
+// requests accepted with AcceptRequestWithAttachments are removed too.
+func (s *Service) RemoveExpiredPendingRequests() ([]string, error) {
+	now := time.Now()
+
+	s.attachmentHandles.removeExpired(now)
+
+	expired, err := s.expiredPendingRequests(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch expired pending requests : %w", err)
+	}
+
+	var removed []string
+
+	for _, id := range expired {
+		err = s.removePendingRequest(id)
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove expired pending request %s : %w", id, err)
+		}
+
+		removed = append(removed, id)
+	}
+
+	return removed, nil
+}
+
+// expiredPendingRequests returns the IDs of the pending requests expired at now.
+func (s *Service) expiredPendingRequests(now time.Time) ([]string, error) {
+	itr := s.revocations.Iterator(pendingRequestExpiryKeyPrefix, pendingRequestExpiryKeyPrefix+"~")
+	defer itr.Release()
+
+	var expired []string
+
+	for itr.Next() {
+		expiry, err := time.Parse(time.RFC3339Nano, string(itr.Value()))
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry of %s : %w", itr.Key(), err)
+		}
+
+		if expiry.Before(now) {
+			expired = append(expired, strings.TrimPrefix(string(itr.Key()), pendingRequestExpiryKeyPrefix))
+		}
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, err
+	}
+
+	return expired, nil
+}
+
+// removePendingRequest removes the pending request id, its expiry and its attachment handles, and fires its expired
+// message event.
+func (s *Service) removePendingRequest(id string) error {
+	s.attachmentHandles.remove(id)
+
+	r, err := s.PendingRequest(id)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return err
+	}
+
+	// the request may already be removed, eg: by an interrupted collection
+	if err == nil {
+		err = s.connections.RemoveInvitation(pendingRequestKeyPrefix + id)
+		if err != nil {
+			return err
+		}
+
+		s.sendPendingRequestExpiredEvent(r)
+	}
+
+	return s.revocations.Delete(pendingRequestExpiryKeyPrefix + id)
+}
+
+func (s *Service) sendPendingRequestExpiredEvent(r *Request) {
+	for _, handler := range s.MsgEvents() {
+		handler <- service.StateMsg{
+			ProtocolName: Name,
+			Type:         service.PostState,
+			StateID:      StateIDPendingRequestExpired,
+			Msg:          service.NewDIDCommMsgMap(r),
+		}
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestRemoveExpiredPendingRequests(t *testing.T) {
+	t.Run("removes the expired pending requests", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+
+		events := make(chan service.StateMsg, 10)
+		require.NoError(t, s.RegisterMsgEvent(events))
+
+		expired := newRequest()
+		expired.Timing = &decorator.Timing{ExpiresTime: time.Now().Add(-time.Minute)}
+		require.NoError(t, s.SavePendingRequest(expired))
+
+		valid := newRequest()
+		valid.Timing = &decorator.Timing{ExpiresTime: time.Now().Add(time.Hour)}
+		require.NoError(t, s.SavePendingRequest(valid))
+
+		// no expiry
+		pending := newRequest()
+		require.NoError(t, s.SavePendingRequest(pending))
+
+		removed, err := s.RemoveExpiredPendingRequests()
+		require.NoError(t, err)
+		require.Equal(t, []string{expired.ID}, removed)
+
+		_, err = s.PendingRequest(expired.ID)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		for _, id := range []string{valid.ID, pending.ID} {
+			_, err = s.PendingRequest(id)
+			require.NoError(t, err)
+		}
+
+		event := <-events
+		require.Equal(t, StateIDPendingRequestExpired, event.StateID)
+		require.Equal(t, service.PostState, event.Type)
+		require.Equal(t, expired.ID, event.Msg.ID())
+
+		removed, err = s.RemoveExpiredPendingRequests()
+		require.NoError(t, err)
+		require.Empty(t, removed)
+	})
+
+	t.Run("pending requests expire after the TTL", func(t *testing.T) {
+		s := newAutoService(t, testProvider(), WithPendingRequestTTL(time.Millisecond))
+
+		req := newRequest()
+		require.NoError(t, s.SavePendingRequest(req))
+
+		time.Sleep(10 * time.Millisecond)
+
+		removed, err := s.RemoveExpiredPendingRequests()
+		require.NoError(t, err)
+		require.Equal(t, []string{req.ID}, removed)
+	})
+
+	t.Run("removes the expiry of requests already removed", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+
+		req := newRequest()
+		req.Timing = &decorator.Timing{ExpiresTime: time.Now().Add(-time.Minute)}
+		require.NoError(t, s.SavePendingRequest(req))
+		require.NoError(t, s.connections.RemoveInvitation(pendingRequestKeyPrefix+req.ID))
+
+		removed, err := s.RemoveExpiredPendingRequests()
+		require.NoError(t, err)
+		require.Equal(t, []string{req.ID}, removed)
+
+		_, err = s.revocations.Get(pendingRequestExpiryKeyPrefix + req.ID)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("fails on an invalid expiry", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+
+		require.NoError(t, s.revocations.Put(pendingRequestExpiryKeyPrefix+"id", []byte("invalid")))
+
+		_, err := s.RemoveExpiredPendingRequests()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to fetch expired pending requests")
+	})
+}
+
+func TestStartPendingRequestGC(t *testing.T) {
+	s := newAutoService(t, testProvider())
+
+	events := make(chan service.StateMsg, 10)
+	require.NoError(t, s.RegisterMsgEvent(events))
+
+	req := newRequest()
+	req.Timing = &decorator.Timing{ExpiresTime: time.Now().Add(-time.Minute)}
+	require.NoError(t, s.SavePendingRequest(req))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.StartPendingRequestGC(ctx, time.Millisecond)
+
+	select {
+	case event := <-events:
+		require.Equal(t, StateIDPendingRequestExpired, event.StateID)
+		require.Equal(t, req.ID, event.Msg.ID())
+	case <-time.After(time.Second):
+		require.Fail(t, "timeout waiting for the expired pending request event")
+	}
+
+	_, err := s.PendingRequest(req.ID)
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	cancel()
+
+	// the garbage collector is stopped
+	time.Sleep(10 * time.Millisecond)
+
+	req = newRequest()
+	req.Timing = &decorator.Timing{ExpiresTime: time.Now().Add(-time.Minute)}
+	require.NoError(t, s.SavePendingRequest(req))
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = s.PendingRequest(req.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,44 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidImageURL is returned for request image URLs which are neither https URLs nor data URLs of images.
+var ErrInvalidImageURL = errors.New("invalid image url")
+
+// ValidateImageURL checks that imageURL, the URL of the image (eg: an issuer icon) displayed by wallets for a request,
+// is an https URL or a data URL of an image. Other schemes are rejected since wallets fetch the image when they
+// display the request.
+func ValidateImageURL(imageURL string) error {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return fmt.Errorf("%w : %s", ErrInvalidImageURL, err)
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		if u.Host == "" {
+			return fmt.Errorf("%w : no host in '%s'", ErrInvalidImageURL, imageURL)
+		}
+
+		return nil
+	case "data":
+		if !strings.HasPrefix(strings.ToLower(u.Opaque), "image/") {
+			return fmt.Errorf("%w : data url is not an image", ErrInvalidImageURL)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w : unsupported scheme '%s'", ErrInvalidImageURL, u.Scheme)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateImageURL(t *testing.T) {
+	t.Run("valid image urls", func(t *testing.T) {
+		for _, imageURL := range []string{
+			"https://issuer.example.com/icon.png",
+			"HTTPS://issuer.example.com/icon.png",
+			"data:image/png;base64,iVBORw0KGgo=",
+		} {
+			require.NoError(t, ValidateImageURL(imageURL), imageURL)
+		}
+	})
+
+	t.Run("invalid image urls", func(t *testing.T) {
+		tests := []struct {
+			imageURL string
+			err      string
+		}{
+			{"http://issuer.example.com/icon.png", "unsupported scheme 'http'"},
+			{"javascript:alert(1)", "unsupported scheme 'javascript'"},
+			{"/icon.png", "unsupported scheme ''"},
+			{"https:///icon.png", "no host"},
+			{"data:text/html;base64,PGh0bWw+", "data url is not an image"},
+			{"https://issuer.example.com/%zz", "invalid URL escape"},
+		}
+
+		for _, test := range tests {
+			err := ValidateImageURL(test.imageURL)
+			require.True(t, errors.Is(err, ErrInvalidImageURL), test.imageURL)
+			require.Contains(t, err.Error(), test.err, test.imageURL)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+)
+
+const (
+	// CredentialManifestType is the '@type' of the DIF Credential Manifest attached to requests.
+	CredentialManifestType = "https://identity.foundation/credential-manifest/1.0/manifest"
+
+	credentialManifestMimeType = "application/json"
+)
+
+// ErrInvalidCredentialManifest is returned when the credential manifest attached to a request is invalid.
+var ErrInvalidCredentialManifest = errors.New("invalid credential manifest")
+
+// CredentialManifest is a DIF Credential Manifest describing the credential an issuer will issue, attached to a
+// request so the holder can pre-approve the issuance before connecting:
+// https://github.com/decentralized-identity/credential-manifest
+type CredentialManifest struct {
+	Type       string              `json:"@type"`
+	Locale     string              `json:"locale,omitempty"`
+	Issuer     *ManifestIssuer     `json:"issuer"`
+	Credential *ManifestCredential `json:"credential"`
+	// PresentationDefinition is the optional DIF presentation definition of the proofs required from the holder.
+	PresentationDefinition json.RawMessage `json:"presentation_definition,omitempty"`
+}
+
+// ManifestIssuer is the issuer of the credential described by a credential manifest.
+type ManifestIssuer struct {
+	ID     string          `json:"id"`
+	Name   string          `json:"name,omitempty"`
+	Styles json.RawMessage `json:"styles,omitempty"`
+}
+
+// ManifestCredential is the credential described by a credential manifest.
+type ManifestCredential struct {
+	Schema      string `json:"schema"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Validate checks that the required top-level fields of the manifest are set.
+func (m *CredentialManifest) Validate() error {
+	switch {
+	case m.Type != CredentialManifestType:
+		return fmt.Errorf("%w : unsupported @type '%s'", ErrInvalidCredentialManifest, m.Type)
+	case m.Issuer == nil || m.Issuer.ID == "":
+		return fmt.Errorf("%w : missing issuer id", ErrInvalidCredentialManifest)
+	case m.Credential == nil || m.Credential.Schema == "":
+		return fmt.Errorf("%w : missing credential schema", ErrInvalidCredentialManifest)
+	}
+
+	return nil
+}
+
+// CredentialManifestAttachment validates the manifest and returns the attachment carrying it in a request.
+func CredentialManifestAttachment(m *CredentialManifest) (*decorator.Attachment, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &decorator.Attachment{
+		ID:       uuid.New().String(),
+		MimeType: credentialManifestMimeType,
+		Data:     decorator.AttachmentData{JSON: m},
+	}, nil
+}
+
+// ParseCredentialManifest returns the validated credential manifest attached to the request, nil if there is none.
+func ParseCredentialManifest(req *Request) (*CredentialManifest, error) {
+	for _, a := range req.Requests {
+		if !isCredentialManifest(a) {
+			continue
+		}
+
+		bytes, err := extractDIDCommMsgBytes(a)
+		if err != nil {
+			return nil, err
+		}
+
+		m := &CredentialManifest{}
+
+		if err = json.Unmarshal(bytes, m); err != nil {
+			return nil, fmt.Errorf("%w : %s", ErrInvalidCredentialManifest, err)
+		}
+
+		if err = m.Validate(); err != nil {
+			return nil, err
+		}
+
+		return m, nil
+	}
+
+	return nil, nil
+}
+
+// isCredentialManifest tells whether the attachment carries a credential manifest rather than the message of a
+// protocol to start.
+func isCredentialManifest(a *decorator.Attachment) bool {
+	if a.MimeType != credentialManifestMimeType {
+		return false
+	}
+
+	msgType, err := AttachedMsgType(a)
+
+	return err == nil && msgType == CredentialManifestType
+}
+
+// requestEvent holds the properties of the action event of an inbound request.
+type requestEvent struct {
+	manifest *CredentialManifest
+	imageURL string
+}
+
+// CredentialManifest returns the credential manifest attached to the request, nil if there is none.
+func (e *requestEvent) CredentialManifest() *CredentialManifest {
+	return e.manifest
+}
+
+// ImageURL returns the URL of the image to display for the request, eg: the icon of the issuer, or an empty string if
+// the request has no image URL or its image URL is invalid (see ValidateImageURL).
+func (e *requestEvent) ImageURL() string {
+	return e.imageURL
+}
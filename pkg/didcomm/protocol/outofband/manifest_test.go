@@ -0,0 +1,167 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/issuecredential"
+)
+
+const sampleManifest = `{
+	"@type": "https://identity.foundation/credential-manifest/1.0/manifest",
+	"locale": "en-US",
+	"issuer": {
+		"id": "did:example:123?linked-domains=3",
+		"name": "Washington State Government",
+		"styles": {"background":{"color":"#ff0000"}}
+	},
+	"credential": {
+		"schema": "https://schema.org/EducationalOccupationalCredential",
+		"name": "Washington State Driver License",
+		"description": "License to operate a vehicle with a gross combined weight rating (GCWR) of 26,001 pounds."
+	},
+	"presentation_definition": {"input_descriptors":[{"id":"banking_input"}]}
+}`
+
+func TestCredentialManifest_Validate(t *testing.T) {
+	t.Run("test sample manifest is valid", func(t *testing.T) {
+		require.NoError(t, newManifest(t).Validate())
+	})
+	t.Run("test missing required fields", func(t *testing.T) {
+		m := newManifest(t)
+		m.Type = "invalid"
+		require.True(t, errors.Is(m.Validate(), ErrInvalidCredentialManifest))
+
+		m = newManifest(t)
+		m.Issuer = nil
+		require.EqualError(t, m.Validate(), "invalid credential manifest : missing issuer id")
+
+		m = newManifest(t)
+		m.Issuer.ID = ""
+		require.EqualError(t, m.Validate(), "invalid credential manifest : missing issuer id")
+
+		m = newManifest(t)
+		m.Credential = nil
+		require.EqualError(t, m.Validate(), "invalid credential manifest : missing credential schema")
+
+		m = newManifest(t)
+		m.Credential.Schema = ""
+		require.EqualError(t, m.Validate(), "invalid credential manifest : missing credential schema")
+	})
+}
+
+func TestParseCredentialManifest(t *testing.T) {
+	t.Run("test parse attached manifest", func(t *testing.T) {
+		req := newManifestRequest(t)
+
+		m, err := ParseCredentialManifest(req)
+		require.NoError(t, err)
+		require.Equal(t, newManifest(t), m)
+	})
+	t.Run("test request without manifest", func(t *testing.T) {
+		m, err := ParseCredentialManifest(newRequest())
+		require.NoError(t, err)
+		require.Nil(t, m)
+	})
+	t.Run("test invalid attached manifest", func(t *testing.T) {
+		req := newRequest()
+		req.Requests = append(req.Requests, &decorator.Attachment{
+			MimeType: credentialManifestMimeType,
+			Data: decorator.AttachmentData{JSON: map[string]interface{}{
+				"@type":  CredentialManifestType,
+				"issuer": map[string]interface{}{"id": "did:example:123"},
+			}},
+		})
+
+		_, err := ParseCredentialManifest(req)
+		require.True(t, errors.Is(err, ErrInvalidCredentialManifest))
+
+		req.Requests[1].Data.JSON = map[string]interface{}{"@type": CredentialManifestType, "issuer": "invalid"}
+		_, err = ParseCredentialManifest(req)
+		require.True(t, errors.Is(err, ErrInvalidCredentialManifest))
+	})
+	t.Run("test attach invalid manifest", func(t *testing.T) {
+		_, err := CredentialManifestAttachment(&CredentialManifest{Type: CredentialManifestType})
+		require.True(t, errors.Is(err, ErrInvalidCredentialManifest))
+	})
+}
+
+func TestHandleInbound_CredentialManifest(t *testing.T) {
+	t.Run("test manifest surfaced in the action event", func(t *testing.T) {
+		s, err := New(testProvider())
+		require.NoError(t, err)
+
+		events := make(chan service.DIDCommAction)
+		require.NoError(t, s.RegisterActionEvent(events))
+
+		_, err = s.HandleInbound(service.NewDIDCommMsgMap(newManifestRequest(t)), "did:example:mine",
+			"did:example:theirs")
+		require.NoError(t, err)
+
+		select {
+		case e := <-events:
+			props, ok := e.Properties.(interface{ CredentialManifest() *CredentialManifest })
+			require.True(t, ok)
+			require.Equal(t, newManifest(t), props.CredentialManifest())
+		case <-time.After(1 * time.Second):
+			t.Error("timeout waiting for action event")
+		}
+	})
+	t.Run("test request with an invalid manifest rejected", func(t *testing.T) {
+		s, err := New(testProvider())
+		require.NoError(t, err)
+
+		req := newManifestRequest(t)
+		manifest, ok := req.Requests[1].Data.JSON.(*CredentialManifest)
+		require.True(t, ok)
+		manifest.Credential = nil
+
+		_, err = s.HandleInbound(service.NewDIDCommMsgMap(req), "did:example:mine", "did:example:theirs")
+		require.True(t, errors.Is(err, ErrInvalidCredentialManifest))
+	})
+	t.Run("test protocol started is not the manifest", func(t *testing.T) {
+		req := newManifestRequest(t)
+		req.Requests[0], req.Requests[1] = req.Requests[1], req.Requests[0]
+
+		msg, err := service.ParseDIDCommMsgMap(acceptAndCompleteDIDExchange(t, req))
+		require.NoError(t, err)
+		require.Equal(t, issuecredential.OfferCredentialMsgType, msg.Type())
+
+		s := newAutoService(t, testProvider())
+		_, err = s.AcceptRequest(req, WithSelectedAttachment(req.Requests[0].ID))
+		require.True(t, errors.Is(err, ErrAttachmentNotFound))
+	})
+}
+
+func newManifest(t *testing.T) *CredentialManifest {
+	m := &CredentialManifest{}
+	require.NoError(t, json.Unmarshal([]byte(sampleManifest), m))
+
+	return m
+}
+
+// newManifestRequest returns a request offering a credential, along with the manifest of the credential.
+func newManifestRequest(t *testing.T) *Request {
+	manifest, err := CredentialManifestAttachment(newManifest(t))
+	require.NoError(t, err)
+
+	req := newRequest()
+	req.Requests = []*decorator.Attachment{
+		base64Attachment(t, &issuecredential.OfferCredential{Type: issuecredential.OfferCredentialMsgType}),
+		manifest,
+	}
+
+	return req
+}
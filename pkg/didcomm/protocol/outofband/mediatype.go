@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+// Media types of the DIDComm messages of the exchange started by a request (see RFC 0434 'accept').
+const (
+	// MediaTypeDIDCommV2 is DIDComm v2 messaging.
+	MediaTypeDIDCommV2 = "didcomm/v2"
+	// MediaTypeAIP1 is Aries Interop Profile 1.0 messaging.
+	MediaTypeAIP1 = "didcomm/aip1"
+	// MediaTypeAIP2RFC19 is Aries Interop Profile 2.0 messaging with RFC 0019 encryption envelopes.
+	MediaTypeAIP2RFC19 = "didcomm/aip2;env=rfc19"
+	// MediaTypeAIP2RFC587 is Aries Interop Profile 2.0 messaging with RFC 0587 (DIDComm v2) encryption envelopes.
+	MediaTypeAIP2RFC587 = "didcomm/aip2;env=rfc587"
+)
+
+// ErrNoCommonMediaType is returned when accepting a request which accepts none of the supported media types.
+var ErrNoCommonMediaType = errors.New("no common media type")
+
+// defaultMediaTypes are the media types supported by the framework's packers, by order of preference.
+func defaultMediaTypes() []string {
+	return []string{MediaTypeAIP2RFC19, MediaTypeAIP1}
+}
+
+// WithMediaTypes option sets the media types supported for the exchanges started by accepted requests, by order of
+// preference (defaults to didcomm/aip2;env=rfc19 and didcomm/aip1).
+func WithMediaTypes(mediaTypes ...string) ServiceOption {
+	return func(opts *Service) {
+		opts.mediaTypes = mediaTypes
+	}
+}
+
+// negotiateMediaType returns the media type of the exchange started by accepting a request accepting the media types
+// accept, by order of preference of the requester: the first one which is supported. The most preferred supported
+// media type is returned if the request doesn't specify accepted media types.
+func negotiateMediaType(accept, supported []string) (string, error) {
+	if len(supported) == 0 {
+		return "", fmt.Errorf("%w : no supported media types", ErrNoCommonMediaType)
+	}
+
+	if len(accept) == 0 {
+		return supported[0], nil
+	}
+
+	for _, a := range accept {
+		for _, s := range supported {
+			if equalMediaTypes(a, s) {
+				return s, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w : request accepts [%s], supported [%s]", ErrNoCommonMediaType,
+		strings.Join(accept, ", "), strings.Join(supported, ", "))
+}
+
+// equalMediaTypes compares media types case insensitively, ignoring the spaces around parameters.
+func equalMediaTypes(a, b string) bool {
+	return strings.EqualFold(normalizeMediaType(a), normalizeMediaType(b))
+}
+
+func normalizeMediaType(mediaType string) string {
+	parts := strings.Split(mediaType, ";")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// didCommVersion returns the DIDComm plaintext message structure of the messages of the media type mediaType.
+func didCommVersion(mediaType string) service.DIDCommVersion {
+	if equalMediaTypes(mediaType, MediaTypeDIDCommV2) || equalMediaTypes(mediaType, MediaTypeAIP2RFC587) {
+		return service.DIDCommV2
+	}
+
+	return service.DIDCommV1
+}
+
+// applyMediaType selects the DIDComm version of the negotiated media type mediaType for the messages sent on the
+// connection of the record, before the protocol of the request is started on it.
+func (s *Service) applyMediaType(record *connection.Record, mediaType string) error {
+	if mediaType == "" {
+		return nil
+	}
+
+	version := string(didCommVersion(mediaType))
+
+	current := record.DIDCommVersion
+	if current == "" {
+		current = string(service.DIDCommV1)
+	}
+
+	if current == version {
+		return nil
+	}
+
+	record.DIDCommVersion = version
+
+	err := s.connections.SaveConnectionRecord(record)
+	if err != nil {
+		return fmt.Errorf("failed to set the DIDComm version of the connection : %w", err)
+	}
+
+	return nil
+}
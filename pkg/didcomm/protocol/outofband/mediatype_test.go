@@ -0,0 +1,151 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
+	mockdidexchange "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/didexchange"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+func TestNegotiateMediaType(t *testing.T) {
+	t.Run("test the requester's most preferred supported media type is chosen", func(t *testing.T) {
+		mediaType, err := negotiateMediaType(
+			[]string{MediaTypeDIDCommV2, MediaTypeAIP1, MediaTypeAIP2RFC19},
+			[]string{MediaTypeAIP2RFC19, MediaTypeAIP1})
+		require.NoError(t, err)
+		require.Equal(t, MediaTypeAIP1, mediaType)
+	})
+	t.Run("test media types are compared case insensitively ignoring parameter spaces", func(t *testing.T) {
+		mediaType, err := negotiateMediaType([]string{"DIDComm/AIP2; env=RFC19"}, defaultMediaTypes())
+		require.NoError(t, err)
+		require.Equal(t, MediaTypeAIP2RFC19, mediaType)
+	})
+	t.Run("test the most preferred supported media type is chosen without accept", func(t *testing.T) {
+		mediaType, err := negotiateMediaType(nil, []string{MediaTypeDIDCommV2, MediaTypeAIP1})
+		require.NoError(t, err)
+		require.Equal(t, MediaTypeDIDCommV2, mediaType)
+	})
+	t.Run("test no common media type", func(t *testing.T) {
+		_, err := negotiateMediaType([]string{MediaTypeDIDCommV2, MediaTypeAIP2RFC587}, defaultMediaTypes())
+		require.True(t, errors.Is(err, ErrNoCommonMediaType))
+		require.Contains(t, err.Error(), "request accepts [didcomm/v2, didcomm/aip2;env=rfc587]")
+
+		_, err = negotiateMediaType(nil, nil)
+		require.True(t, errors.Is(err, ErrNoCommonMediaType))
+	})
+}
+
+func TestAcceptRequest_MediaType(t *testing.T) {
+	newService := func(t *testing.T, invitations chan<- *didexchange.OOBInvitation, opts ...ServiceOption) *Service {
+		provider := testProvider()
+		provider.ServiceMap = map[string]interface{}{
+			didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{
+				RespondToFunc: func(i *didexchange.OOBInvitation) (string, error) {
+					invitations <- i
+					return "conn-1", nil
+				},
+			},
+		}
+
+		s, err := New(provider, opts...)
+		require.NoError(t, err)
+
+		return s
+	}
+
+	t.Run("test negotiated media type passed to the exchange and saved", func(t *testing.T) {
+		invitations := make(chan *didexchange.OOBInvitation, 1)
+		s := newService(t, invitations, WithMediaTypes(MediaTypeDIDCommV2, MediaTypeAIP2RFC19))
+
+		req := newRequest()
+		req.Accept = []string{MediaTypeAIP2RFC587, MediaTypeAIP2RFC19, MediaTypeDIDCommV2}
+
+		_, err := s.AcceptRequest(req)
+		require.NoError(t, err)
+
+		invitation := <-invitations
+		require.Equal(t, MediaTypeAIP2RFC19, invitation.MediaType)
+
+		state, err := s.fetchMyState(invitation.ID)
+		require.NoError(t, err)
+		require.Equal(t, MediaTypeAIP2RFC19, state.MediaType)
+	})
+	t.Run("test default media type without accept", func(t *testing.T) {
+		invitations := make(chan *didexchange.OOBInvitation, 1)
+		s := newService(t, invitations)
+
+		_, err := s.AcceptRequest(newRequest())
+		require.NoError(t, err)
+		require.Equal(t, MediaTypeAIP2RFC19, (<-invitations).MediaType)
+	})
+	t.Run("test fails when no media type overlaps", func(t *testing.T) {
+		invitations := make(chan *didexchange.OOBInvitation, 1)
+		s := newService(t, invitations)
+
+		req := newRequest()
+		req.Accept = []string{MediaTypeDIDCommV2}
+
+		_, err := s.AcceptRequest(req)
+		require.True(t, errors.Is(err, ErrNoCommonMediaType))
+		require.Empty(t, invitations)
+	})
+}
+
+func TestStartProtocol_MediaType(t *testing.T) {
+	startProtocol := func(t *testing.T, mediaType string) *connection.Record {
+		provider := testProvider()
+		provider.InboundMsgHandler = func([]byte, string, string) error {
+			return nil
+		}
+
+		r, err := connection.NewRecorder(provider)
+		require.NoError(t, err)
+		require.NoError(t, r.SaveConnectionRecord(&connection.Record{
+			ConnectionID: "conn-1",
+			MyDID:        "did:example:mine",
+			TheirDID:     "did:example:theirs",
+		}))
+
+		pthid := uuid.New().String()
+		s := newAutoService(t, provider, withState(t, &myState{
+			ID:           pthid,
+			ConnectionID: "conn-1",
+			Request:      newRequest(),
+			MediaType:    mediaType,
+		}))
+
+		require.NoError(t, s.handleDIDEvent(service.StateMsg{
+			ProtocolName: didexchange.DIDExchange,
+			Type:         service.PostState,
+			Msg:          service.NewDIDCommMsgMap(newAck(pthid)),
+		}))
+
+		record, err := r.GetConnectionRecord("conn-1")
+		require.NoError(t, err)
+
+		return record
+	}
+
+	t.Run("test DIDComm v2 media types select DIDComm v2 for the connection", func(t *testing.T) {
+		for _, mediaType := range []string{MediaTypeDIDCommV2, MediaTypeAIP2RFC587} {
+			require.Equal(t, string(service.DIDCommV2), startProtocol(t, mediaType).DIDCommVersion)
+		}
+	})
+	t.Run("test DIDComm v1 media types keep the default DIDComm version", func(t *testing.T) {
+		for _, mediaType := range []string{"", MediaTypeAIP1, MediaTypeAIP2RFC19} {
+			require.Empty(t, startProtocol(t, mediaType).DIDCommVersion)
+		}
+	})
+}
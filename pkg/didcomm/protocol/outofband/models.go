@@ -17,4 +17,14 @@ type Request struct {
 	GoalCode string                  `json:"goal-code,omitempty"`
 	Requests []*decorator.Attachment `json:"request~attach"`
 	Service  []interface{}           `json:"service"` // Service is an array of either DIDs or 'service' block entries.
+	// Accept lists the media types accepted for the ensuing exchange, by order of preference (eg: didcomm/v2).
+	Accept []string `json:"accept,omitempty"`
+	// ImageURL is the URL of the image displayed by wallets for the request, eg: the icon of the issuer
+	// (see ValidateImageURL).
+	ImageURL string `json:"imageUrl,omitempty"`
+	// Signature is an optional compact JWS, with detached payload, of the request without signature
+	// (see SignRequest).
+	Signature string `json:"signature,omitempty"`
+	// Timing is the expiry of the request, pending requests are removed once expired (see StartPendingRequestGC).
+	Timing *decorator.Timing `json:"~timing,omitempty"`
 }
@@ -0,0 +1,214 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// key prefix of the markers of the multi-use requests saved with SaveRequest
+	multiUseRequestKeyPrefix = "multi-use-request-"
+	// key prefix of the acceptances of the requests saved with SaveRequest
+	requestAcceptanceKeyPrefix = "request-acceptance-"
+	// key prefix of the consumptions of the single-use requests saved with SaveRequest, by the connection ID of
+	// their acceptor
+	consumedRequestKeyPrefix = "consumed-request-"
+
+	// the did-exchange state in which the inviter has responded to the did-exchange request of an acceptor
+	didExchangeStateResponded = "responded"
+)
+
+// ErrRequestConsumed is returned when a single-use request is accepted again after its first acceptance.
+var ErrRequestConsumed = errors.New("out-of-band request consumed")
+
+// Acceptance is the acceptance, by another agent, of a request saved with SaveRequest: each acceptor gets its own
+// connection.
+type Acceptance struct {
+	RequestID    string    `json:"requestID"`
+	ConnectionID string    `json:"connectionID"`
+	AcceptedAt   time.Time `json:"acceptedAt"`
+}
+
+// SaveOption configures the saving of a request created by the outofband client.
+type SaveOption func(opts *saveOpts)
+
+type saveOpts struct {
+	multiUse bool
+}
+
+// WithMultiUse option saves a multi-use request, eg: a request printed once and accepted by many holders. Requests
+// are single-use by default: the first acceptor whose did-exchange request is responded to consumes the request, the
+// did-exchange requests of the other acceptors are rejected without response. Requests saved before this option was introduced were never consumed, requests which must still be
+// accepted by several agents must now be saved with this option. A multi-use request is never consumed, a distinct
+// connection is established with each acceptor (see Acceptances).
+func WithMultiUse() SaveOption {
+	return func(opts *saveOpts) {
+		opts.multiUse = true
+	}
+}
+
+// Acceptances returns the acceptances of the request id saved with SaveRequest, one per acceptor.
+func (s *Service) Acceptances(id string) ([]*Acceptance, error) {
+	prefix := requestAcceptanceKeyPrefix + id + "-"
+
+	itr := s.requestUses.Iterator(prefix, prefix+"~")
+	defer itr.Release()
+
+	var acceptances []*Acceptance
+
+	for itr.Next() {
+		acceptance := &Acceptance{}
+
+		err := json.Unmarshal(itr.Value(), acceptance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the acceptance %s : %w", itr.Key(), err)
+		}
+
+		acceptances = append(acceptances, acceptance)
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, fmt.Errorf("failed to fetch the acceptances of request %s : %w", id, err)
+	}
+
+	return acceptances, nil
+}
+
+// isRequestAcceptance returns true if e is the response of the inviter to a did-exchange request made for an
+// out-of-band request, the parent thread of the did-exchange.
+func isRequestAcceptance(e service.StateMsg) bool {
+	return e.Type == service.PostState && e.StateID == didExchangeStateResponded &&
+		e.Msg.Type() == didexchange.RequestMsgType && e.Msg.ParentThreadID() != ""
+}
+
+// recordAcceptance records the acceptance of a request saved with SaveRequest once the did-exchange response is
+// sent, and removes the did-exchange invitation of a single-use request. The single-use request was consumed by the
+// acceptor before the response was sent (see claimRequest).
+func (s *Service) recordAcceptance(e service.StateMsg) error {
+	id := e.Msg.ParentThreadID()
+
+	err := s.connections.GetInvitation(savedRequestKey(id), &Request{})
+	if errors.Is(err, storage.ErrDataNotFound) {
+		// not the acceptance of a request of ours, eg: a did-exchange invitation
+		return errIgnoredDidEvent
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to fetch the saved request %s : %w", id, err)
+	}
+
+	props, ok := e.Properties.(interface{ ConnectionID() string })
+	if !ok {
+		return fmt.Errorf("no connection ID in the acceptance of request %s", id)
+	}
+
+	connID := props.ConnectionID()
+
+	multiUse, err := s.isMultiUse(id)
+	if err != nil {
+		return err
+	}
+
+	if !multiUse {
+		// the request is consumed here if the did-exchange service did not claim it before responding
+		if err = s.consumeRequest(id, connID); err != nil {
+			return err
+		}
+	}
+
+	bytes, err := json.Marshal(&Acceptance{RequestID: id, ConnectionID: connID, AcceptedAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the acceptance of request %s : %w", id, err)
+	}
+
+	err = s.requestUses.Put(requestAcceptanceKeyPrefix+id+"-"+connID, bytes)
+	if err != nil {
+		return fmt.Errorf("failed to save the acceptance of request %s : %w", id, err)
+	}
+
+	if multiUse {
+		return nil
+	}
+
+	// the did-exchange invitation of the request is saved with the request ID
+	err = s.connections.RemoveInvitation(id)
+	if err != nil {
+		return fmt.Errorf("failed to consume request %s : %w", id, err)
+	}
+
+	return nil
+}
+
+// claimRequest claims the request id for the connection connID before the did-exchange response is sent (see
+// didexchange.RequestClaimer): a single-use request is consumed, the did-exchange requests of the connections of the
+// other acceptors are rejected with ErrRequestConsumed. The requests not saved with SaveRequest are not claimed.
+func (s *Service) claimRequest(id, connID string) error {
+	err := s.connections.GetInvitation(savedRequestKey(id), &Request{})
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to fetch the saved request %s : %w", id, err)
+	}
+
+	multiUse, err := s.isMultiUse(id)
+	if err != nil || multiUse {
+		return err
+	}
+
+	return s.consumeRequest(id, connID)
+}
+
+// isMultiUse returns true if the request id was saved with WithMultiUse.
+func (s *Service) isMultiUse(id string) (bool, error) {
+	_, err := s.requestUses.Get(multiUseRequestKeyPrefix + id)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch the multi-use marker of request %s : %w", id, err)
+	}
+
+	return true, nil
+}
+
+// consumeRequest consumes the single-use request id for the connection connID atomically, unless another connection
+// consumed it first: ErrRequestConsumed is then returned.
+func (s *Service) consumeRequest(id, connID string) error {
+	key := consumedRequestKeyPrefix + id
+
+	err := storage.PutIfMatch(s.requestUses, key, []byte(connID), nil)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, storage.ErrVersionConflict) {
+		return fmt.Errorf("failed to consume request %s : %w", id, err)
+	}
+
+	consumer, err := s.requestUses.Get(key)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the consumption of request %s : %w", id, err)
+	}
+
+	if string(consumer) == connID {
+		// the request is claimed again, eg: its acceptance is recorded after the claim
+		return nil
+	}
+
+	return fmt.Errorf("request %s accepted by connection %s : %w", id, connID, ErrRequestConsumed)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
+	mockdidexchange "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/didexchange"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+func TestMultiUseRequest(t *testing.T) {
+	var claimRequest didexchange.RequestClaimer
+
+	// newInviter returns the inviter service, its did-exchange invitations are saved in its connection store
+	newInviter := func(t *testing.T) (*Service, *connection.Recorder) {
+		provider := testProvider()
+
+		recorder, err := connection.NewRecorder(provider)
+		require.NoError(t, err)
+
+		provider.ServiceMap[didexchange.DIDExchange] = &mockdidexchange.MockDIDExchangeSvc{
+			SaveFunc: func(i *didexchange.OOBInvitation) error {
+				return recorder.SaveInvitation(i.ThreadID, i)
+			},
+			SetRequestClaimerFunc: func(claimer didexchange.RequestClaimer) {
+				claimRequest = claimer
+			},
+		}
+
+		inviter := newAutoService(t, provider)
+		require.NotNil(t, claimRequest)
+
+		return inviter, recorder
+	}
+
+	// responded is the event of the inviter responding, with the connection connID, to the did-exchange request
+	// made by the receiver with DID theirDID for req
+	responded := func(req *Request, theirDID, connID string) service.StateMsg {
+		return service.StateMsg{
+			ProtocolName: didexchange.DIDExchange,
+			Type:         service.PostState,
+			StateID:      didExchangeStateResponded,
+			Msg: service.NewDIDCommMsgMap(&didexchange.Request{
+				Type:       didexchange.RequestMsgType,
+				ID:         uuid.New().String(),
+				Connection: &didexchange.Connection{DID: theirDID},
+				Thread:     &decorator.Thread{PID: req.ID},
+			}),
+			Properties: &testDIDExchangeEvent{connectionID: connID},
+		}
+	}
+
+	// accept has the receiver with DID theirDID accept req, and the inviter respond to its did-exchange request
+	accept := func(t *testing.T, inviter *Service, req *Request, theirDID string) string {
+		provider := testProvider()
+		provider.ServiceMap[didexchange.DIDExchange] = &mockdidexchange.MockDIDExchangeSvc{
+			RespondToFunc: func(i *didexchange.OOBInvitation) (string, error) {
+				require.Equal(t, req.ID, i.ThreadID)
+
+				return uuid.New().String(), nil
+			},
+		}
+
+		_, err := newAutoService(t, provider).AcceptRequest(req)
+		require.NoError(t, err)
+
+		// the inviter's connection with the receiver, the request is claimed before the inviter responds
+		connID := uuid.New().String()
+
+		require.NoError(t, claimRequest(req.ID, connID))
+		require.NoError(t, inviter.handleDIDEvent(responded(req, theirDID, connID)))
+
+		return connID
+	}
+
+	t.Run("multi-use request accepted by two receivers", func(t *testing.T) {
+		inviter, recorder := newInviter(t)
+
+		req := newRequest()
+		require.NoError(t, inviter.SaveRequest(req, WithMultiUse()))
+
+		connA := accept(t, inviter, req, "did:example:receiverA")
+		connB := accept(t, inviter, req, "did:example:receiverB")
+		require.NotEqual(t, connA, connB)
+
+		acceptances, err := inviter.Acceptances(req.ID)
+		require.NoError(t, err)
+		require.Len(t, acceptances, 2)
+
+		var connIDs []string
+
+		for _, a := range acceptances {
+			require.Equal(t, req.ID, a.RequestID)
+			require.False(t, a.AcceptedAt.IsZero())
+
+			connIDs = append(connIDs, a.ConnectionID)
+		}
+
+		require.ElementsMatch(t, []string{connA, connB}, connIDs)
+
+		// the request is not consumed
+		require.NoError(t, recorder.GetInvitation(req.ID, &didexchange.OOBInvitation{}))
+	})
+
+	t.Run("single-use request is consumed by its first acceptance", func(t *testing.T) {
+		inviter, recorder := newInviter(t)
+
+		req := newRequest()
+		require.NoError(t, inviter.SaveRequest(req))
+		require.NoError(t, recorder.GetInvitation(req.ID, &didexchange.OOBInvitation{}))
+
+		connID := accept(t, inviter, req, "did:example:receiverA")
+
+		acceptances, err := inviter.Acceptances(req.ID)
+		require.NoError(t, err)
+		require.Len(t, acceptances, 1)
+		require.Equal(t, connID, acceptances[0].ConnectionID)
+
+		// the did-exchange invitation is removed, the did-exchanges of other receivers are rejected
+		err = recorder.GetInvitation(req.ID, &didexchange.OOBInvitation{})
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("single-use request accepted concurrently", func(t *testing.T) {
+		inviter, recorder := newInviter(t)
+
+		req := newRequest()
+		require.NoError(t, inviter.SaveRequest(req))
+
+		// both did-exchange requests are received before the request is consumed
+		connA, connB := uuid.New().String(), uuid.New().String()
+
+		for _, connID := range []string{connA, connB} {
+			require.NoError(t, recorder.SaveConnectionRecord(&connection.Record{
+				ConnectionID: connID, State: "requested", ParentThreadID: req.ID,
+				ThreadID: uuid.New().String(), Namespace: "their",
+				MyDID: "did:example:inviter", TheirDID: "did:example:" + connID,
+			}))
+		}
+
+		require.NoError(t, claimRequest(req.ID, connA))
+
+		// the did-exchange request of the second acceptor is rejected before the inviter responds
+		err := claimRequest(req.ID, connB)
+		require.True(t, errors.Is(err, ErrRequestConsumed))
+
+		// the request may be claimed again by its consumer
+		require.NoError(t, claimRequest(req.ID, connA))
+		require.NoError(t, inviter.handleDIDEvent(responded(req, "did:example:receiverA", connA)))
+
+		// no connection is removed
+		for _, connID := range []string{connA, connB} {
+			_, err = recorder.GetConnectionRecord(connID)
+			require.NoError(t, err)
+		}
+
+		acceptances, err := inviter.Acceptances(req.ID)
+		require.NoError(t, err)
+		require.Len(t, acceptances, 1)
+		require.Equal(t, connA, acceptances[0].ConnectionID)
+	})
+
+	t.Run("single-use request consumed on acceptance when it was not claimed", func(t *testing.T) {
+		inviter, _ := newInviter(t)
+
+		req := newRequest()
+		require.NoError(t, inviter.SaveRequest(req))
+
+		connA, connB := uuid.New().String(), uuid.New().String()
+
+		require.NoError(t, inviter.handleDIDEvent(responded(req, "did:example:receiverA", connA)))
+
+		err := inviter.handleDIDEvent(responded(req, "did:example:receiverB", connB))
+		require.True(t, errors.Is(err, ErrRequestConsumed))
+	})
+
+	t.Run("multi-use requests and requests of others are not claimed", func(t *testing.T) {
+		inviter, _ := newInviter(t)
+
+		req := newRequest()
+		require.NoError(t, inviter.SaveRequest(req, WithMultiUse()))
+
+		require.NoError(t, claimRequest(req.ID, uuid.New().String()))
+		require.NoError(t, claimRequest(req.ID, uuid.New().String()))
+		require.NoError(t, claimRequest(uuid.New().String(), uuid.New().String()))
+	})
+
+	t.Run("ignores the did-exchanges of other requests", func(t *testing.T) {
+		inviter, _ := newInviter(t)
+
+		err := inviter.handleDIDEvent(service.StateMsg{
+			ProtocolName: didexchange.DIDExchange,
+			Type:         service.PostState,
+			StateID:      didExchangeStateResponded,
+			Msg: service.NewDIDCommMsgMap(&didexchange.Request{
+				Type:   didexchange.RequestMsgType,
+				ID:     uuid.New().String(),
+				Thread: &decorator.Thread{PID: uuid.New().String()},
+			}),
+			Properties: &testDIDExchangeEvent{connectionID: uuid.New().String()},
+		})
+		require.True(t, errors.Is(err, errIgnoredDidEvent))
+	})
+
+	t.Run("fails without connection ID", func(t *testing.T) {
+		inviter, _ := newInviter(t)
+
+		req := newRequest()
+		require.NoError(t, inviter.SaveRequest(req))
+
+		err := inviter.handleDIDEvent(service.StateMsg{
+			ProtocolName: didexchange.DIDExchange,
+			Type:         service.PostState,
+			StateID:      didExchangeStateResponded,
+			Msg: service.NewDIDCommMsgMap(&didexchange.Request{
+				Type:   didexchange.RequestMsgType,
+				ID:     uuid.New().String(),
+				Thread: &decorator.Thread{PID: req.ID},
+			}),
+		})
+		require.EqualError(t, err, "no connection ID in the acceptance of request "+req.ID)
+	})
+}
+
+type testDIDExchangeEvent struct {
+	connectionID string
+}
+
+func (e *testDIDExchangeEvent) ConnectionID() string {
+	return e.connectionID
+}
+
+func (e *testDIDExchangeEvent) All() map[string]interface{} {
+	return map[string]interface{}{"connectionID": e.connectionID}
+}
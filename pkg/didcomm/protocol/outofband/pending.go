@@ -0,0 +1,113 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// key prefix of the requests received from other agents and saved with SavePendingRequest
+const pendingRequestKeyPrefix = "pending-request-"
+
+// ValidateRequest checks that the request r, eg: received from another agent, has the required fields: an @id, the
+// request @type, at least one attachment (see CheckAttachments) and at least one service entry. Service entries
+// must be DIDs or service blocks with a service endpoint and at least one recipient key. The image URL, if any, is
+// validated with ValidateImageURL.
+func ValidateRequest(r *Request) error {
+	if r.ID == "" {
+		return errors.New("request has no @id")
+	}
+
+	if r.Type != RequestMsgType {
+		return fmt.Errorf("unexpected request @type '%s'", r.Type)
+	}
+
+	if len(r.Requests) == 0 {
+		return errors.New("request has no attachment")
+	}
+
+	if err := CheckAttachments(r.Requests); err != nil {
+		return fmt.Errorf("invalid attachments : %w", err)
+	}
+
+	if len(r.Service) == 0 {
+		return errors.New("request has no service")
+	}
+
+	for i := range r.Service {
+		if err := validateServiceEntry(r.Service[i]); err != nil {
+			return fmt.Errorf("invalid service %d : %w", i, err)
+		}
+	}
+
+	if r.ImageURL != "" {
+		return ValidateImageURL(r.ImageURL)
+	}
+
+	return nil
+}
+
+func validateServiceEntry(entry interface{}) error {
+	if didID, ok := entry.(string); ok {
+		if !strings.HasPrefix(didID, "did:") {
+			return fmt.Errorf("'%s' is not a DID", didID)
+		}
+
+		return nil
+	}
+
+	svc, err := toService(entry)
+	if err != nil {
+		return err
+	}
+
+	if svc.ServiceEndpoint == "" {
+		return errors.New("service block has no serviceEndpoint")
+	}
+
+	if len(svc.RecipientKeys) == 0 {
+		return errors.New("service block has no recipientKeys")
+	}
+
+	return nil
+}
+
+// SavePendingRequest saves the request r received from another agent (eg: imported from a QR code) until it is
+// accepted. r is validated with ValidateRequest. The request expires at the expires_time of its ~timing decorator,
+// or after the pending request TTL of the service (see WithPendingRequestTTL); expired requests are removed by the
+// garbage collector of the service (see StartPendingRequestGC).
+func (s *Service) SavePendingRequest(r *Request) error {
+	if err := ValidateRequest(r); err != nil {
+		return fmt.Errorf("invalid oob request : %w", err)
+	}
+
+	err := s.connections.SaveInvitation(pendingRequestKeyPrefix+r.ID, r)
+	if err != nil {
+		return fmt.Errorf("failed to save pending oob request : %w", err)
+	}
+
+	err = s.savePendingRequestExpiry(r)
+	if err != nil {
+		return fmt.Errorf("failed to save pending oob request expiry : %w", err)
+	}
+
+	return nil
+}
+
+// PendingRequest returns the request id saved with SavePendingRequest.
+func (s *Service) PendingRequest(id string) (*Request, error) {
+	r := &Request{}
+
+	err := s.connections.GetInvitation(pendingRequestKeyPrefix+id, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the pending request %s : %w", id, err)
+	}
+
+	return r, nil
+}
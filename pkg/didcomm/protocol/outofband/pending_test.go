@@ -0,0 +1,94 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestValidateRequest(t *testing.T) {
+	t.Run("valid requests", func(t *testing.T) {
+		require.NoError(t, ValidateRequest(newRequest()))
+
+		req := newRequest()
+		req.Service = []interface{}{
+			&did.Service{RecipientKeys: []string{"key"}, ServiceEndpoint: "https://example.com"},
+			map[string]interface{}{"recipientKeys": []string{"key"}, "serviceEndpoint": "https://example.com"},
+		}
+		require.NoError(t, ValidateRequest(req))
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(*Request)
+			err    string
+		}{
+			{"no @id", func(r *Request) { r.ID = "" }, "request has no @id"},
+			{"wrong @type", func(r *Request) { r.Type = "invalid" }, "unexpected request @type 'invalid'"},
+			{"no attachment", func(r *Request) { r.Requests = nil }, "request has no attachment"},
+			{"invalid attachments", func(r *Request) {
+				r.Requests = []*decorator.Attachment{{ID: "a"}, {ID: "a"}}
+			}, "invalid attachments"},
+			{"no service", func(r *Request) { r.Service = nil }, "request has no service"},
+			{"not a DID", func(r *Request) { r.Service = []interface{}{"example"} }, "'example' is not a DID"},
+			{"no service endpoint", func(r *Request) {
+				r.Service = []interface{}{map[string]interface{}{"recipientKeys": []string{"key"}}}
+			}, "service block has no serviceEndpoint"},
+			{"no recipient keys", func(r *Request) {
+				r.Service = []interface{}{&did.Service{ServiceEndpoint: "https://example.com"}}
+			}, "service block has no recipientKeys"},
+			{"malformed service block", func(r *Request) {
+				r.Service = []interface{}{map[string]interface{}{"serviceEndpoint": 1}}
+			}, "unmarshal service block"},
+			{"invalid image url", func(r *Request) { r.ImageURL = "ftp://example.com/icon.png" }, "invalid image url"},
+		}
+
+		for _, test := range tests {
+			req := newRequest()
+			test.modify(req)
+
+			err := ValidateRequest(req)
+			require.Error(t, err, test.name)
+			require.Contains(t, err.Error(), test.err, test.name)
+		}
+	})
+}
+
+func TestSavePendingRequest(t *testing.T) {
+	t.Run("saves a pending request", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+		req := newRequest()
+
+		require.NoError(t, s.SavePendingRequest(req))
+
+		pending, err := s.PendingRequest(req.ID)
+		require.NoError(t, err)
+		require.Equal(t, req.ID, pending.ID)
+		require.Equal(t, req.Service, pending.Service)
+	})
+
+	t.Run("rejects an invalid request", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+		req := newRequest()
+		req.Service = nil
+
+		err := s.SavePendingRequest(req)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid oob request")
+
+		_, err = s.PendingRequest(req.ID)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+}
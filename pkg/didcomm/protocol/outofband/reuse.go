@@ -0,0 +1,194 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+)
+
+const (
+	// HandshakeReuseMsgType is the '@type' of the message sent to reuse an existing connection for a request.
+	HandshakeReuseMsgType = "https://didcomm.org/out-of-band/1.0/handshake-reuse"
+	// HandshakeReuseAcceptedMsgType is the '@type' of the reply to a handshake-reuse message.
+	HandshakeReuseAcceptedMsgType = "https://didcomm.org/out-of-band/1.0/handshake-reuse-accepted"
+
+	// StateIDReuseAccepted is the state of the message events fired when the reuse of a connection is accepted, by
+	// the sender of the request when it accepts the reuse and by the receiver when it is notified of it.
+	StateIDReuseAccepted = "reuse-accepted"
+)
+
+// ErrRequestExpired is returned when a connection is reused for a request which expired.
+var ErrRequestExpired = errors.New("out-of-band request expired")
+
+// HandshakeReuse is the message sent, on an existing connection, to reuse the connection instead of performing
+// a did-exchange for a request. Its parent thread is the request.
+type HandshakeReuse struct {
+	ID     string            `json:"@id"`
+	Type   string            `json:"@type"`
+	Thread *decorator.Thread `json:"~thread,omitempty"`
+}
+
+// HandshakeReuseAccepted is the reply to a HandshakeReuse message, once the connection reuse is accepted.
+type HandshakeReuseAccepted struct {
+	ID     string            `json:"@id"`
+	Type   string            `json:"@type"`
+	Thread *decorator.Thread `json:"~thread,omitempty"`
+}
+
+// WithReuseConnection option accepts the request by reusing the existing connection connID with the sender of the
+// request instead of performing a did-exchange. A handshake-reuse message is sent on the connection, the protocol of
+// the selected attachment is started once the sender accepts the reuse.
+func WithReuseConnection(connID string) AcceptOption {
+	return func(opts *callback) {
+		opts.reuseConnection = connID
+	}
+}
+
+// reuseEvent holds the properties of the reuse-accepted message events.
+type reuseEvent struct {
+	connectionID string
+}
+
+// ConnectionID returns the ID of the reused connection.
+func (e *reuseEvent) ConnectionID() string {
+	return e.connectionID
+}
+
+// sendHandshakeReuse sends a handshake-reuse message for the request on the connection connID.
+func (s *Service) sendHandshakeReuse(req *Request, connID string) error {
+	record, err := s.fetchConnectionRecord(connID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch connection record to reuse with id=%s : %w", connID, err)
+	}
+
+	reuse := &HandshakeReuse{
+		ID:   uuid.New().String(),
+		Type: HandshakeReuseMsgType,
+	}
+
+	// the request's thread is the parent thread of the reuse
+	err = s.messenger.ReplyToNested(req.ID, service.NewDIDCommMsgMap(reuse), record.MyDID, record.TheirDID)
+	if err != nil {
+		return fmt.Errorf("failed to send handshake-reuse : %w", err)
+	}
+
+	return nil
+}
+
+// handleHandshakeReuse accepts the reuse, by the receiver of a request, of its connection with the sender of msg.
+// The parent thread of msg must be a request saved with SaveRequest, neither revoked nor expired.
+func (s *Service) handleHandshakeReuse(msg service.DIDCommMsg, myDID, theirDID string) error {
+	if msg.ParentThreadID() == "" {
+		return errors.New("handshake-reuse has no parent thread")
+	}
+
+	err := s.checkLiveRequest(msg.ParentThreadID())
+	if err != nil {
+		return fmt.Errorf("handshake-reuse rejected : %w", err)
+	}
+
+	connID, err := s.connections.GetConnectionIDByDIDs(myDID, theirDID)
+	if err != nil {
+		return fmt.Errorf("no connection to reuse : %w", err)
+	}
+
+	accepted := &HandshakeReuseAccepted{
+		ID:   uuid.New().String(),
+		Type: HandshakeReuseAcceptedMsgType,
+	}
+
+	err = s.messenger.ReplyTo(msg.ID(), service.NewDIDCommMsgMap(accepted))
+	if err != nil {
+		return fmt.Errorf("failed to send handshake-reuse-accepted : %w", err)
+	}
+
+	s.sendReuseAcceptedEvent(msg, connID)
+
+	return nil
+}
+
+// checkLiveRequest returns an error if id is not a request saved with SaveRequest, or if the request was revoked
+// (ErrRequestRevoked) or expired (ErrRequestExpired).
+func (s *Service) checkLiveRequest(id string) error {
+	req := &Request{}
+
+	err := s.connections.GetInvitation(savedRequestKey(id), req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the saved request %s : %w", id, err)
+	}
+
+	err = s.checkRevoked(id)
+	if err != nil {
+		return err
+	}
+
+	if req.Timing != nil && !req.Timing.ExpiresTime.IsZero() && !time.Now().Before(req.Timing.ExpiresTime) {
+		return fmt.Errorf("%w : id=%s expiredAt=%s", ErrRequestExpired, id,
+			req.Timing.ExpiresTime.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// handleHandshakeReuseAccepted starts the protocol of the request once the reuse of the connection is accepted.
+func (s *Service) handleHandshakeReuseAccepted(msg service.DIDCommMsg) error {
+	state, err := s.fetchMyState(msg.ParentThreadID())
+	if err != nil {
+		return fmt.Errorf("failed to load state data with id=%s : %w", msg.ParentThreadID(), err)
+	}
+
+	s.sendReuseAcceptedEvent(msg, state.ConnectionID)
+
+	err = s.startProtocol(state)
+	if err != nil && !errors.Is(err, errIgnoredDidEvent) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) sendReuseAcceptedEvent(msg service.DIDCommMsg, connID string) {
+	for _, handler := range s.MsgEvents() {
+		handler <- service.StateMsg{
+			ProtocolName: Name,
+			Type:         service.PostState,
+			StateID:      StateIDReuseAccepted,
+			Msg:          msg,
+			Properties:   &reuseEvent{connectionID: connID},
+		}
+	}
+}
+
+// reuseConnection accepts the request by reusing the connection of the callback, the state is saved under the
+// request's ID: the parent thread of the handshake-reuse.
+func (s *Service) reuseConnection(c *callback, req *Request, mediaType string) (string, error) {
+	err := s.save(&myState{
+		ID:                 req.ID,
+		ConnectionID:       c.reuseConnection,
+		Request:            req,
+		SelectedAttachment: c.selectedAttachment,
+		MediaType:          mediaType,
+		Inviter:            c.inviter,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to save my state : %w", err)
+	}
+
+	err = s.sendHandshakeReuse(req, c.reuseConnection)
+	if err != nil {
+		return "", err
+	}
+
+	return c.reuseConnection, nil
+}
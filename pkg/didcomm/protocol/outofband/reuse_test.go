@@ -0,0 +1,227 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	serviceMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+const (
+	inviterDID = "did:example:inviter"
+	inviteeDID = "did:example:invitee"
+)
+
+func TestHandshakeReuse(t *testing.T) {
+	t.Run("sender of the request receives the reuse-accepted event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		req := newMultiProtocolRequest(t)
+
+		// the receiver of the request reuses its connection with the sender
+		invitee, inviteeEvents, dispatched := newReuseService(t, "invitee-conn", inviteeDID, inviterDID)
+		inviteeMessenger := serviceMocks.NewMockMessenger(ctrl)
+		invitee.messenger = inviteeMessenger
+
+		var reuse service.DIDCommMsgMap
+
+		inviteeMessenger.EXPECT().ReplyToNested(req.ID, gomock.Any(), inviteeDID, inviterDID).
+			DoAndReturn(func(threadID string, msg service.DIDCommMsgMap, _, _ string) error {
+				msg["~thread"] = map[string]interface{}{"pthid": threadID}
+				reuse = msg
+
+				return nil
+			})
+
+		connID, err := invitee.AcceptRequest(req, WithReuseConnection("invitee-conn"))
+		require.NoError(t, err)
+		require.Equal(t, "invitee-conn", connID)
+		require.Equal(t, HandshakeReuseMsgType, reuse.Type())
+		require.Equal(t, req.ID, reuse.ParentThreadID())
+
+		// the sender of the request accepts the reuse
+		inviter, inviterEvents, _ := newReuseService(t, "inviter-conn", inviterDID, inviteeDID)
+		require.NoError(t, inviter.SaveRequest(req))
+		inviterMessenger := serviceMocks.NewMockMessenger(ctrl)
+		inviter.messenger = inviterMessenger
+
+		var accepted service.DIDCommMsgMap
+
+		inviterMessenger.EXPECT().ReplyTo(reuse.ID(), gomock.Any()).
+			DoAndReturn(func(msgID string, msg service.DIDCommMsgMap) error {
+				msg["~thread"] = map[string]interface{}{"thid": msgID, "pthid": req.ID}
+				accepted = msg
+
+				return nil
+			})
+
+		_, err = inviter.HandleInbound(reuse, inviterDID, inviteeDID)
+		require.NoError(t, err)
+		require.Equal(t, HandshakeReuseAcceptedMsgType, accepted.Type())
+		requireReuseAcceptedEvent(t, inviterEvents, "inviter-conn")
+
+		// the receiver of the request is notified and starts the protocol of the request
+		_, err = invitee.HandleInbound(accepted, inviteeDID, inviterDID)
+		require.NoError(t, err)
+		requireReuseAcceptedEvent(t, inviteeEvents, "invitee-conn")
+
+		select {
+		case msg := <-dispatched:
+			dispatchedMsg, err := service.ParseDIDCommMsgMap(msg)
+			require.NoError(t, err)
+			require.Equal(t, firstAttachedMsgType(t, req), dispatchedMsg.Type())
+		case <-time.After(time.Second):
+			require.FailNow(t, "timeout waiting for the protocol message to be dispatched")
+		}
+	})
+	t.Run("fails to reuse an unknown connection", func(t *testing.T) {
+		s, _, _ := newReuseService(t, "conn", inviteeDID, inviterDID)
+		_, err := s.AcceptRequest(newRequest(), WithReuseConnection("unknown"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to fetch connection record to reuse")
+	})
+	t.Run("wraps error thrown by the messenger when sending the reuse", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expected := errors.New("test")
+		s, _, _ := newReuseService(t, "conn", inviteeDID, inviterDID)
+		messenger := serviceMocks.NewMockMessenger(ctrl)
+		messenger.EXPECT().ReplyToNested(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(expected)
+		s.messenger = messenger
+
+		_, err := s.AcceptRequest(newRequest(), WithReuseConnection("conn"))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, expected))
+	})
+	t.Run("rejects handshake-reuse without a parent thread", func(t *testing.T) {
+		s, _, _ := newReuseService(t, "conn", inviterDID, inviteeDID)
+		_, err := s.HandleInbound(service.NewDIDCommMsgMap(&HandshakeReuse{
+			ID:   uuid.New().String(),
+			Type: HandshakeReuseMsgType,
+		}), inviterDID, inviteeDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no parent thread")
+	})
+	t.Run("rejects handshake-reuse without a connection", func(t *testing.T) {
+		s, _, _ := newReuseService(t, "conn", inviterDID, inviteeDID)
+		req := newRequest()
+		require.NoError(t, s.SaveRequest(req))
+		msg := newHandshakeReuse(req.ID)
+		_, err := s.HandleInbound(msg, "did:example:other", inviteeDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no connection to reuse")
+	})
+	t.Run("rejects handshake-reuse for a request which is not live", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, _, _ := newReuseService(t, "conn", inviterDID, inviteeDID)
+		// no handshake-reuse-accepted is sent
+		s.messenger = serviceMocks.NewMockMessenger(ctrl)
+
+		_, err := s.HandleInbound(newHandshakeReuse(uuid.New().String()), inviterDID, inviteeDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to fetch the saved request")
+
+		revoked := newRequest()
+		require.NoError(t, s.SaveRequest(revoked))
+		require.NoError(t, s.RevokeRequest(revoked.ID))
+
+		_, err = s.HandleInbound(newHandshakeReuse(revoked.ID), inviterDID, inviteeDID)
+		require.True(t, errors.Is(err, ErrRequestRevoked))
+
+		expired := newRequest()
+		expired.Timing = &decorator.Timing{ExpiresTime: time.Now().Add(-time.Minute)}
+		require.NoError(t, s.SaveRequest(expired))
+
+		_, err = s.HandleInbound(newHandshakeReuse(expired.ID), inviterDID, inviteeDID)
+		require.True(t, errors.Is(err, ErrRequestExpired))
+	})
+	t.Run("fails on handshake-reuse-accepted for an unknown request", func(t *testing.T) {
+		s, _, _ := newReuseService(t, "conn", inviteeDID, inviterDID)
+		msg := service.NewDIDCommMsgMap(&HandshakeReuseAccepted{
+			ID:   uuid.New().String(),
+			Type: HandshakeReuseAcceptedMsgType,
+		})
+		msg["~thread"] = map[string]interface{}{"pthid": uuid.New().String()}
+		_, err := s.HandleInbound(msg, inviteeDID, inviterDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to load state data")
+	})
+}
+
+// newReuseService returns a service with a completed connection connID between myDID and theirDID, along with
+// its message events and the messages it dispatches.
+func newReuseService(t *testing.T, connID, myDID, theirDID string) (*Service, chan service.StateMsg, chan []byte) {
+	dispatched := make(chan []byte, 1)
+
+	provider := testProvider()
+	provider.InboundMsgHandler = func(msg []byte, _, _ string) error {
+		dispatched <- msg
+		return nil
+	}
+
+	r, err := connection.NewRecorder(provider)
+	require.NoError(t, err)
+	require.NoError(t, r.SaveConnectionRecord(&connection.Record{
+		ConnectionID: connID,
+		State:        "completed",
+		MyDID:        myDID,
+		TheirDID:     theirDID,
+	}))
+
+	s := newAutoService(t, provider)
+
+	events := make(chan service.StateMsg, 1)
+	require.NoError(t, s.RegisterMsgEvent(events))
+
+	return s, events, dispatched
+}
+
+// newHandshakeReuse returns a handshake-reuse message for the request requestID.
+func newHandshakeReuse(requestID string) service.DIDCommMsgMap {
+	msg := service.NewDIDCommMsgMap(&HandshakeReuse{
+		ID:   uuid.New().String(),
+		Type: HandshakeReuseMsgType,
+	})
+	msg["~thread"] = map[string]interface{}{"pthid": requestID}
+
+	return msg
+}
+
+func requireReuseAcceptedEvent(t *testing.T, events chan service.StateMsg, connID string) {
+	select {
+	case e := <-events:
+		require.Equal(t, StateIDReuseAccepted, e.StateID)
+
+		props, ok := e.Properties.(interface{ ConnectionID() string })
+		require.True(t, ok)
+		require.Equal(t, connID, props.ConnectionID())
+	case <-time.After(time.Second):
+		require.FailNow(t, "timeout waiting for the reuse-accepted event")
+	}
+}
+
+// firstAttachedMsgType returns the type of the message attached to the first request of req.
+func firstAttachedMsgType(t *testing.T, req *Request) string {
+	msgType, err := AttachedMsgType(req.Requests[0])
+	require.NoError(t, err)
+
+	return msgType
+}
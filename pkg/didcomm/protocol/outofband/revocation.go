@@ -0,0 +1,66 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// key prefix of the revocations of the requests saved with SaveRequest
+const revokedRequestKeyPrefix = "revoked-request-"
+
+// ErrRequestRevoked is returned when accepting a request which was revoked.
+var ErrRequestRevoked = errors.New("out-of-band request revoked")
+
+// RevokeRequest revokes the request id saved with SaveRequest (eg: when the request was printed as a QR code which
+// must no longer be used). Revoked requests are rejected by AcceptRequest with ErrRequestRevoked, and the did-exchange
+// invitation of the request is removed: the did-exchange requests and handshake-reuse messages of other agents
+// accepting the request are rejected.
+func (s *Service) RevokeRequest(id string) error {
+	err := s.connections.GetInvitation(savedRequestKey(id), &Request{})
+	if err != nil {
+		return fmt.Errorf("failed to fetch the saved request %s : %w", id, err)
+	}
+
+	err = s.revocations.Put(revokedRequestKeyPrefix+id, []byte(time.Now().UTC().Format(time.RFC3339)))
+	if err != nil {
+		return fmt.Errorf("failed to save the revocation of request %s : %w", id, err)
+	}
+
+	// the did-exchange invitation of the request is saved with the request ID, the did-exchange service fails to
+	// respond to the exchange requests whose parent thread is the request without it
+	err = s.connections.RemoveInvitation(id)
+	if err != nil {
+		return fmt.Errorf("failed to remove the did-exchange invitation of request %s : %w", id, err)
+	}
+
+	return nil
+}
+
+// checkRevoked returns ErrRequestRevoked if the request id was revoked.
+func (s *Service) checkRevoked(id string) error {
+	revokedAt, err := s.revocations.Get(revokedRequestKeyPrefix + id)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to fetch the revocation of request %s : %w", id, err)
+	}
+
+	return fmt.Errorf("%w : id=%s revokedAt=%s", ErrRequestRevoked, id, revokedAt)
+}
+
+// savedRequestKey is the key of the requests saved with SaveRequest.
+func savedRequestKey(id string) string {
+	// TODO where should we save this request? - https://github.com/hyperledger/aries-framework-go/issues/1547
+	return id + "-TODO"
+}
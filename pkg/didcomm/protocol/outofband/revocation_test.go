@@ -0,0 +1,154 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol"
+	mockroute "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/route"
+	mockdiddoc "github.com/hyperledger/aries-framework-go/pkg/mock/diddoc"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestRevokeRequest(t *testing.T) {
+	t.Run("revoked request is rejected", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+		req := newRequest()
+
+		require.NoError(t, s.SaveRequest(req))
+
+		_, err := s.AcceptRequest(req)
+		require.NoError(t, err)
+
+		require.NoError(t, s.RevokeRequest(req.ID))
+
+		_, err = s.AcceptRequest(req)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrRequestRevoked))
+
+		_, err = s.AcceptRequest(newRequest())
+		require.NoError(t, err)
+	})
+	t.Run("revocation survives a restart", func(t *testing.T) {
+		provider := testProvider()
+		req := newRequest()
+
+		s := newAutoService(t, provider)
+		require.NoError(t, s.SaveRequest(req))
+		require.NoError(t, s.RevokeRequest(req.ID))
+
+		provider.TransientStoreProvider = mockstore.NewMockStoreProvider()
+
+		s = newAutoService(t, provider)
+		_, err := s.AcceptRequest(req)
+		require.True(t, errors.Is(err, ErrRequestRevoked))
+	})
+	t.Run("fails to revoke an unknown request", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+		err := s.RevokeRequest("unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to fetch the saved request")
+	})
+	t.Run("wraps error thrown by the revocations store", func(t *testing.T) {
+		expected := errors.New("test")
+		provider := testProvider()
+		provider.StoreProvider = mockstore.NewCustomMockStoreProvider(&mockstore.MockStore{
+			Store:  make(map[string][]byte),
+			ErrGet: expected,
+		})
+		s := newAutoService(t, provider)
+
+		_, err := s.AcceptRequest(newRequest())
+		require.True(t, errors.Is(err, expected))
+	})
+}
+
+func TestRevokeRequest_ExchangeRequest(t *testing.T) {
+	// exchange sends the did-exchange request of another agent accepting the request saved by the inviter,
+	// it returns whether the inviter responded to or abandoned the exchange
+	exchange := func(t *testing.T, revoke bool) string {
+		provider := &protocol.MockProvider{
+			StoreProvider:          mockstore.NewMockStoreProvider(),
+			TransientStoreProvider: mockstore.NewMockStoreProvider(),
+			ServiceMap: map[string]interface{}{
+				route.Coordination: &mockroute.MockRouteSvc{},
+			},
+		}
+
+		didSvc, err := didexchange.New(provider)
+		require.NoError(t, err)
+
+		provider.ServiceMap[didexchange.DIDExchange] = didSvc
+
+		actions := make(chan service.DIDCommAction, 1)
+		require.NoError(t, didSvc.RegisterActionEvent(actions))
+
+		go service.AutoExecuteActionEvent(actions)
+
+		states := make(chan service.StateMsg, 10)
+		require.NoError(t, didSvc.RegisterMsgEvent(states))
+
+		inviter := newAutoService(t, provider)
+
+		req := newRequest()
+		req.Service = []interface{}{&did.Service{
+			ID:              uuid.New().String(),
+			Type:            "did-communication",
+			RecipientKeys:   []string{base58.Encode([]byte("inviter key of thirty-two bytes!"))},
+			ServiceEndpoint: "https://inviter.example.com",
+		}}
+		require.NoError(t, inviter.SaveRequest(req))
+
+		if revoke {
+			require.NoError(t, inviter.RevokeRequest(req.ID))
+		}
+
+		inviteeDoc := mockdiddoc.GetMockDIDDoc()
+
+		bytes, err := json.Marshal(&didexchange.Request{
+			Type:       didexchange.RequestMsgType,
+			ID:         uuid.New().String(),
+			Label:      "invitee",
+			Thread:     &decorator.Thread{PID: req.ID},
+			Connection: &didexchange.Connection{DID: inviteeDoc.ID, DIDDoc: inviteeDoc},
+		})
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(bytes)
+		require.NoError(t, err)
+
+		_, err = didSvc.HandleInbound(msg, "", "")
+		require.NoError(t, err)
+
+		for {
+			select {
+			case e := <-states:
+				if e.Type == service.PostState && (e.StateID == "responded" || e.StateID == "abandoned") {
+					return e.StateID
+				}
+			case <-time.After(time.Second):
+				require.FailNow(t, "timeout waiting for the did-exchange state")
+			}
+		}
+	}
+
+	require.Equal(t, "responded", exchange(t, false))
+	require.Equal(t, "abandoned", exchange(t, true))
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/logutil"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
@@ -29,6 +31,9 @@ const (
 	Name = "out-of-band"
 	// RequestMsgType is the '@type' for the request message.
 	RequestMsgType = "https://didcomm.org/oob-request/1.0/request"
+	// RequestUsesStoreName is the name of the store where the uses of the saved requests are persisted: the
+	// multi-use markers, the acceptances and the consumptions of the single-use requests.
+	RequestUsesStoreName = "out-of-band_request_uses"
 
 	// TODO channel size - https://github.com/hyperledger/aries-framework-go/issues/246
 	callbackChannelSize = 10
@@ -38,11 +43,20 @@ var logger = log.New(fmt.Sprintf("aries-framework/%s/service", Name))
 
 var errIgnoredDidEvent = errors.New("ignored")
 
+// ErrAttachmentRejected is returned when an attachment handler (see WithAttachmentHandler) rejects a request.
+var ErrAttachmentRejected = errors.New("out-of-band request attachment rejected")
+
 type didExchSvc interface {
 	RespondTo(*didexchange.OOBInvitation) (string, error)
 	SaveInvitation(invitation *didexchange.OOBInvitation) error
 }
 
+// requestClaimers are the did-exchange services letting the out-of-band service claim its requests before responding
+// to the did-exchange requests made for them.
+type requestClaimers interface {
+	SetRequestClaimer(claimer didexchange.RequestClaimer)
+}
+
 // Service implements the Out-Of-Band protocol.
 type Service struct {
 	service.Action
@@ -51,24 +65,42 @@ type Service struct {
 	didSvc                     didExchSvc
 	didEvents                  chan service.StateMsg
 	store                      storage.Store
+	revocations                storage.Store
+	requestUses                storage.Store
 	connections                *connection.Recorder
 	dispatch                   transport.InboundMessageHandler
 	getNextRequestFunc         func(*myState) (*decorator.Attachment, bool)
 	extractDIDCommMsgBytesFunc func(*decorator.Attachment) ([]byte, error)
 	listenerFunc               func()
+	vdriRegistry               vdriapi.Registry
+	unsignedRequests           bool
+	mediaTypes                 []string
+	messenger                  service.Messenger
+	pendingRequestTTL          time.Duration
+	attachmentHandles          *attachmentHandles
 }
 
 type callback struct {
-	msg      service.DIDCommMsg
-	myDID    string
-	theirDID string
+	msg                service.DIDCommMsg
+	myDID              string
+	theirDID           string
+	attachmentHandler  AttachmentHandler
+	selectedAttachment string
+	reuseConnection    string
+	inviter            inviterPins
 }
 
 type myState struct {
 	ID           string
 	ConnectionID string
 	Request      *Request
-	Done         bool
+	// SelectedAttachment is the @id of the attachment of the request whose protocol is started, the first one if empty
+	SelectedAttachment string
+	// MediaType is the media type negotiated for the exchange
+	MediaType string
+	// Inviter is the inviter pinned on accept (see WithInviterKey and WithInviterDID)
+	Inviter inviterPins
+	Done    bool
 }
 
 // Provider provides this service's dependencies.
@@ -77,10 +109,23 @@ type Provider interface {
 	StorageProvider() storage.Provider
 	TransientStorageProvider() storage.Provider
 	InboundMessageHandler() transport.InboundMessageHandler
+	VDRIRegistry() vdriapi.Registry
+	Messenger() service.Messenger
+}
+
+// ServiceOption configures the out-of-band service.
+type ServiceOption func(opts *Service)
+
+// WithUnsignedRequests option sets whether requests without signature (see SignRequest) are accepted,
+// they are accepted by default. Signed requests are always verified and rejected if their signature is invalid.
+func WithUnsignedRequests(accept bool) ServiceOption {
+	return func(opts *Service) {
+		opts.unsignedRequests = accept
+	}
 }
 
 // New creates a new instance of the out-of-band service.
-func New(p Provider) (*Service, error) {
+func New(p Provider, opts ...ServiceOption) (*Service, error) {
 	svc, err := p.Service(didexchange.DIDExchange)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize outofband service : %w", err)
@@ -96,6 +141,16 @@ func New(p Provider) (*Service, error) {
 		return nil, fmt.Errorf("failed to open the store : %w", err)
 	}
 
+	revocations, err := p.StorageProvider().OpenStore(Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the revocations store : %w", err)
+	}
+
+	requestUses, err := p.StorageProvider().OpenStore(RequestUsesStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the request uses store : %w", err)
+	}
+
 	connectionRecorder, err := connection.NewRecorder(p)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open a connection.Lookup : %w", err)
@@ -106,10 +161,21 @@ func New(p Provider) (*Service, error) {
 		didSvc:                     didSvc,
 		didEvents:                  make(chan service.StateMsg, callbackChannelSize),
 		store:                      store,
+		revocations:                revocations,
+		requestUses:                requestUses,
 		connections:                connectionRecorder,
 		dispatch:                   p.InboundMessageHandler(),
 		getNextRequestFunc:         getNextRequest,
 		extractDIDCommMsgBytesFunc: extractDIDCommMsgBytes,
+		vdriRegistry:               p.VDRIRegistry(),
+		unsignedRequests:           true,
+		mediaTypes:                 defaultMediaTypes(),
+		messenger:                  p.Messenger(),
+		attachmentHandles:          &attachmentHandles{handles: map[string]*requestHandles{}},
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
 
 	s.listenerFunc = listener(s.callbackChannel, s.didEvents, s.handleRequestCallback, s.handleDIDEvent)
@@ -123,6 +189,11 @@ func New(p Provider) (*Service, error) {
 		return nil, fmt.Errorf("failed to register for didexchange protocol msgs : %w", err)
 	}
 
+	// single-use requests are consumed before the did-exchange response is sent
+	if claimers, ok := didSvc.(requestClaimers); ok {
+		claimers.SetRequestClaimer(s.claimRequest)
+	}
+
 	go s.listenerFunc()
 
 	return s, nil
@@ -136,20 +207,53 @@ func (s *Service) Name() string {
 // Accept determines whether this service can handle the given type of message
 func (s *Service) Accept(msgType string) bool {
 	// TODO add invitation msg type https://github.com/hyperledger/aries-rfcs/issues/451
-	return msgType == RequestMsgType
+	switch msgType {
+	case RequestMsgType, HandshakeReuseMsgType, HandshakeReuseAcceptedMsgType:
+		return true
+	default:
+		return false
+	}
 }
 
 // HandleInbound handles inbound messages
 func (s *Service) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string) (string, error) {
 	logger.Debugf("receive inbound message : %s", msg)
 
-	if !s.Accept(msg.Type()) {
+	switch msg.Type() {
+	case RequestMsgType:
+		return "", s.handleRequest(msg, myDID, theirDID)
+	case HandshakeReuseMsgType:
+		return "", s.handleHandshakeReuse(msg, myDID, theirDID)
+	case HandshakeReuseAcceptedMsgType:
+		return "", s.handleHandshakeReuseAccepted(msg)
+	default:
 		return "", fmt.Errorf("unsupported message type %s", msg.Type())
 	}
+}
 
+func (s *Service) handleRequest(msg service.DIDCommMsg, myDID, theirDID string) error {
 	// TODO should request messages with no attachments be rejected?
 	//  https://github.com/hyperledger/aries-rfcs/issues/451
 
+	req := &Request{}
+
+	err := msg.Decode(req)
+	if err != nil {
+		return fmt.Errorf("failed to decode out-of-band request message : %w", err)
+	}
+
+	// the credential manifest is surfaced so the issuance can be pre-approved
+	manifest, err := ParseCredentialManifest(req)
+	if err != nil {
+		return fmt.Errorf("failed to parse credential manifest : %w", err)
+	}
+
+	// an invalid image url is not surfaced, but does not prevent the request from being accepted
+	imageURL := req.ImageURL
+	if imageURL != "" && ValidateImageURL(imageURL) != nil {
+		imageURL = ""
+	}
+
 	go func() {
 		s.ActionEvent() <- service.DIDCommAction{
 			ProtocolName: Name,
@@ -158,11 +262,11 @@ func (s *Service) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string)
 			Stop: func(e error) {
 				// TODO noop - nothing to do here (not even cleanup)
 			},
-			Properties: nil,
+			Properties: &requestEvent{manifest: manifest, imageURL: imageURL},
 		}
 	}()
 
-	return "", nil
+	return nil
 }
 
 // HandleOutbound handles outbound messages
@@ -171,11 +275,38 @@ func (s *Service) HandleOutbound(_ service.DIDCommMsg, _, _ string) error {
 	return errors.New("not implemented")
 }
 
-// AcceptRequest from another agent and return the connection ID.
-func (s *Service) AcceptRequest(r *Request) (string, error) {
-	connID, err := s.handleRequestCallback(&callback{
+// AttachmentHandler handles an attachment of a request being accepted. Returning an error vetoes the acceptance.
+type AttachmentHandler func(a *decorator.Attachment) error
+
+// AcceptOption configures the acceptance of a request.
+type AcceptOption func(opts *callback)
+
+// WithAttachmentHandler option sets a handler invoked with each attachment of the request once its signature is
+// verified, before the did-exchange proceeds. The request is rejected if the handler returns an error for any
+// of its attachments.
+func WithAttachmentHandler(h AttachmentHandler) AcceptOption {
+	return func(opts *callback) {
+		opts.attachmentHandler = h
+	}
+}
+
+// AcceptRequest from another agent and return the connection ID. Revoked requests (see RevokeRequest) are rejected
+// with ErrRequestRevoked.
+func (s *Service) AcceptRequest(r *Request, opts ...AcceptOption) (string, error) {
+	err := s.checkRevoked(r.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to accept request : %w", err)
+	}
+
+	c := &callback{
 		msg: service.NewDIDCommMsgMap(r),
-	})
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	connID, err := s.handleRequestCallback(c)
 	if err != nil {
 		return "", fmt.Errorf("failed to accept request : %w", err)
 	}
@@ -183,14 +314,27 @@ func (s *Service) AcceptRequest(r *Request) (string, error) {
 	return connID, err
 }
 
-// SaveRequest created by the outofband client.
-func (s *Service) SaveRequest(r *Request) error {
-	// TODO where should we save this request? - https://github.com/hyperledger/aries-framework-go/issues/1547
-	err := s.connections.SaveInvitation(r.ID+"-TODO", r)
+// SaveRequest created by the outofband client. The request is single-use unless saved with WithMultiUse: it is
+// consumed by its first acceptance (see WithMultiUse).
+func (s *Service) SaveRequest(r *Request, opts ...SaveOption) error {
+	options := &saveOpts{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	err := s.connections.SaveInvitation(savedRequestKey(r.ID), r)
 	if err != nil {
 		return fmt.Errorf("failed to save oob request : %w", err)
 	}
 
+	if options.multiUse {
+		err = s.requestUses.Put(multiUseRequestKeyPrefix+r.ID, []byte("true"))
+		if err != nil {
+			return fmt.Errorf("failed to save the multi-use marker of oob request : %w", err)
+		}
+	}
+
 	target, err := chooseTarget(r.Service)
 	if err != nil {
 		return fmt.Errorf("failed to choose a target to perform did-exchange against : %w", err)
@@ -261,6 +405,25 @@ func (s *Service) handleRequestCallback(c *callback) (string, error) {
 		return "", fmt.Errorf("failed to decode didexchange invitation and out-of-band request : %w", err)
 	}
 
+	err = s.verifyRequest(req, &c.inviter)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify out-of-band request : %w", err)
+	}
+
+	err = c.handleAttachments(req)
+	if err != nil {
+		return "", err
+	}
+
+	invitation.MediaType, err = negotiateMediaType(req.Accept, s.mediaTypes)
+	if err != nil {
+		return "", err
+	}
+
+	if c.reuseConnection != "" {
+		return s.reuseConnection(c, req, invitation.MediaType)
+	}
+
 	connID, err := s.didSvc.RespondTo(invitation)
 	if err != nil {
 		return "", fmt.Errorf("didexchange service failed to handle inbound request : %w", err)
@@ -271,9 +434,12 @@ func (s *Service) handleRequestCallback(c *callback) (string, error) {
 
 	err = s.save(&myState{
 		// the pthid of the didexchange thread will equal this invitation's ID as per the RFC
-		ID:           invitation.ID,
-		ConnectionID: connID,
-		Request:      req,
+		ID:                 invitation.ID,
+		ConnectionID:       connID,
+		Request:            req,
+		SelectedAttachment: c.selectedAttachment,
+		MediaType:          invitation.MediaType,
+		Inviter:            c.inviter,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to save my state : %w", err)
@@ -282,7 +448,31 @@ func (s *Service) handleRequestCallback(c *callback) (string, error) {
 	return connID, nil
 }
 
+func (c *callback) handleAttachments(req *Request) error {
+	if c.selectedAttachment != "" {
+		if _, err := selectAttachment(req, c.selectedAttachment); err != nil {
+			return err
+		}
+	}
+
+	if c.attachmentHandler == nil {
+		return nil
+	}
+
+	for _, a := range req.Requests {
+		if err := c.attachmentHandler(a); err != nil {
+			return fmt.Errorf("%w: attachment %s : %v", ErrAttachmentRejected, a.ID, err)
+		}
+	}
+
+	return nil
+}
+
 func (s *Service) handleDIDEvent(e service.StateMsg) error {
+	if isRequestAcceptance(e) {
+		return s.recordAcceptance(e)
+	}
+
 	// TODO remove 'empty parent threadID check'?
 	if e.Type != service.PostState || e.Msg.Type() != didexchange.AckMsgType || e.Msg.ParentThreadID() == "" {
 		// we are only interested in a successfully completed didexchange.
@@ -295,11 +485,22 @@ func (s *Service) handleDIDEvent(e service.StateMsg) error {
 		return fmt.Errorf("failed to load state data with id=%s : %w", e.Msg.ParentThreadID(), err)
 	}
 
+	return s.startProtocol(state)
+}
+
+// startProtocol dispatches the message of the request's selected attachment on the connection established (or
+// reused) for the request. A signed attachment is dispatched only if its signature is valid.
+func (s *Service) startProtocol(state *myState) error {
 	req, found := s.getNextRequestFunc(state)
 	if !found {
 		return errIgnoredDidEvent
 	}
 
+	err := s.verifySelectedAttachment(state, req)
+	if err != nil {
+		return fmt.Errorf("failed to verify attachment : %w", err)
+	}
+
 	bytes, err := s.extractDIDCommMsgBytesFunc(req)
 	if err != nil {
 		return fmt.Errorf("failed to extract didcomm message from attachment : %w", err)
@@ -310,6 +511,11 @@ func (s *Service) handleDIDEvent(e service.StateMsg) error {
 		return fmt.Errorf("failed to fetch connection record with id=%s : %w", state.ConnectionID, err)
 	}
 
+	err = s.applyMediaType(record, state.MediaType)
+	if err != nil {
+		return err
+	}
+
 	err = s.dispatch(bytes, record.MyDID, record.TheirDID)
 	if err != nil {
 		return fmt.Errorf("failed to dispatch message : %w", err)
@@ -317,9 +523,11 @@ func (s *Service) handleDIDEvent(e service.StateMsg) error {
 
 	// TODO do we need the capability to register for events from whatever protocol service is handling that msg?
 
-	// TODO we're only processing a single message for now
+	// a single protocol is started per request, the one of the selected attachment
 	state.Done = true
 
+	s.attachmentHandles.remove(state.Request.ID)
+
 	err = s.save(state)
 	if err != nil {
 		return fmt.Errorf("failed to update state : %w", err)
@@ -367,19 +575,19 @@ func (s *Service) fetchConnectionRecord(id string) (*connection.Record, error) {
 	return r, nil
 }
 
-// TODO a request message contains an array of attachments (each a request in of itself).
-//  Should we process in parallel? Would need a spec update.
+// getNextRequest returns the selected attachment of the request, its protocol is started once the did-exchange
+// completes. A single protocol is started per request.
 func getNextRequest(state *myState) (*decorator.Attachment, bool) {
-	if !state.Done {
-		return state.Request.Requests[0], true
+	if state.Done {
+		return nil, false
 	}
 
-	return nil, false
-}
+	a, err := selectAttachment(state.Request, state.SelectedAttachment)
+	if err != nil {
+		return nil, false
+	}
 
-func extractDIDCommMsgBytes(_ *decorator.Attachment) ([]byte, error) {
-	// TODO implement
-	return nil, nil
+	return a, true
 }
 
 func decodeInvitationAndRequest(msg service.DIDCommMsg) (*didexchange.OOBInvitation, *Request, error) {
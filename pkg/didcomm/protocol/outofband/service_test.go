@@ -67,6 +67,14 @@ func TestNew(t *testing.T) {
 		require.Error(t, err)
 		require.True(t, errors.Is(err, expected))
 	})
+	t.Run("fails if the request uses store cannot be opened", func(t *testing.T) {
+		provider := testProvider()
+		provider.StoreProvider = mockstore.NewMockStoreProvider()
+		provider.StoreProvider.FailNamespace = RequestUsesStoreName
+		_, err := New(provider)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open the request uses store")
+	})
 	t.Run("fails if the didexchange service cannot be cast to service.Event", func(t *testing.T) {
 		provider := testProvider()
 		provider.ServiceMap[didexchange.DIDExchange] = &struct{ service.InboundHandler }{}
@@ -606,6 +614,44 @@ func TestAcceptRequest(t *testing.T) {
 		require.Error(t, err)
 		require.True(t, errors.Is(err, expected))
 	})
+	t.Run("attachment handler vetoes request based on MIME type", func(t *testing.T) {
+		handled := 0
+		s := newAutoService(t, testProvider())
+		_, err := s.AcceptRequest(newRequest(), WithAttachmentHandler(func(a *decorator.Attachment) error {
+			handled++
+
+			if a.MimeType != "application/json" {
+				return fmt.Errorf("unsupported MIME type %s", a.MimeType)
+			}
+
+			return nil
+		}))
+		require.True(t, errors.Is(err, ErrAttachmentRejected))
+		require.Contains(t, err.Error(), "unsupported MIME type text/plain")
+		require.Equal(t, 1, handled)
+	})
+	t.Run("attachment handler allows request", func(t *testing.T) {
+		expected := "123456"
+		provider := testProvider()
+		provider.ServiceMap = map[string]interface{}{
+			didexchange.DIDExchange: &mockdidexchange.MockDIDExchangeSvc{
+				RespondToFunc: func(_ *didexchange.OOBInvitation) (string, error) {
+					return expected, nil
+				},
+			},
+		}
+		req := newRequest()
+		var handled []*decorator.Attachment
+		s := newAutoService(t, provider)
+		result, err := s.AcceptRequest(req, WithAttachmentHandler(func(a *decorator.Attachment) error {
+			handled = append(handled, a)
+
+			return nil
+		}))
+		require.NoError(t, err)
+		require.Equal(t, expected, result)
+		require.Equal(t, req.Requests, handled)
+	})
 }
 
 func TestSaveRequest(t *testing.T) {
@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+)
+
+const (
+	// SignatureAlgorithm is the JWS algorithm of the out-of-band request signatures.
+	SignatureAlgorithm = "EdDSA"
+
+	ed25519KeyType = "Ed25519VerificationKey2018"
+)
+
+// ErrInvalidSignature is returned when an out-of-band request has no valid signature of the inviter.
+var ErrInvalidSignature = errors.New("invalid out-of-band request signature")
+
+// SignRequest signs the out-of-band request r with the inviter's key: the signature is a compact JWS, with detached
+// payload, of the canonical JSON of the request without signature, set in r.Signature.
+// The signer headers must have the EdDSA algorithm and, as key ID, either the ID of a public key of a DID of the
+// request services or a recipient key of an inlined service block of the request. The latter is verified only
+// against the key pinned by the invitee (see WithInviterKey).
+func SignRequest(r *Request, signer jose.Signer) error {
+	payload, err := canonicalRequest(r)
+	if err != nil {
+		return fmt.Errorf("sign out-of-band request: %w", err)
+	}
+
+	jws, err := jose.NewJWS(nil, nil, payload, signer)
+	if err != nil {
+		return fmt.Errorf("sign out-of-band request: %w", err)
+	}
+
+	r.Signature, err = jws.SerializeCompact(true)
+	if err != nil {
+		return fmt.Errorf("sign out-of-band request: %w", err)
+	}
+
+	return nil
+}
+
+// inviterPins are the key or the DID of the inviter, pinned by the invitee to verify the request signature.
+type inviterPins struct {
+	Key string `json:"key,omitempty"`
+	DID string `json:"did,omitempty"`
+}
+
+// WithInviterKey option pins the base58 encoded public key of the inviter: the request must be signed with this key.
+// The recipient keys of the inlined service blocks of a request are not trusted to verify its signature, since
+// whoever tampers with the request can replace them and sign it again: a request signed with such a key is accepted
+// only if the key is pinned.
+func WithInviterKey(verKey string) AcceptOption {
+	return func(opts *callback) {
+		opts.inviter.Key = verKey
+	}
+}
+
+// WithInviterDID option pins the DID of the inviter: the request must be signed with a key of this DID, whatever
+// the DIDs of the request services.
+func WithInviterDID(didID string) AcceptOption {
+	return func(opts *callback) {
+		opts.inviter.DID = didID
+	}
+}
+
+// verifyRequest verifies the signature of the out-of-band request r with the inviter's key: the key or DID pinned
+// by the invitee (see WithInviterKey and WithInviterDID), or else the key identified by the signature key ID among
+// the public keys of the resolved DIDs of the request services. Unsigned requests are accepted only if the service
+// is configured to (see WithUnsignedRequests).
+func (s *Service) verifyRequest(r *Request, pins *inviterPins) error {
+	if r.Signature == "" {
+		if s.unsignedRequests {
+			return nil
+		}
+
+		return fmt.Errorf("%w: the request is not signed", ErrInvalidSignature)
+	}
+
+	payload, err := canonicalRequest(r)
+	if err != nil {
+		return fmt.Errorf("verify out-of-band request: %w", err)
+	}
+
+	_, err = jose.ParseJWS(r.Signature, jose.SignatureVerifierFunc(
+		func(joseHeaders jose.Headers, _, signingInput, signature []byte) error {
+			return s.verifySignature(r.Service, pins, joseHeaders, signingInput, signature)
+		}), jose.WithJWSDetachedPayload(payload))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	return nil
+}
+
+func (s *Service) verifySignature(svcs []interface{}, pins *inviterPins, joseHeaders jose.Headers,
+	signingInput, signature []byte) error {
+	alg, _ := joseHeaders.Algorithm()
+	if alg != SignatureAlgorithm {
+		return fmt.Errorf("unsupported algorithm %s", alg)
+	}
+
+	kid, ok := joseHeaders.KeyID()
+	if !ok {
+		return errors.New("missing key ID")
+	}
+
+	pubKey, err := s.inviterKey(svcs, pins, kid)
+	if err != nil {
+		return err
+	}
+
+	return verifier.NewEd25519SignatureVerifier().Verify(
+		&verifier.PublicKey{Type: ed25519KeyType, Value: pubKey}, signingInput, signature)
+}
+
+// inviterKey returns the public key kid of the inviter: the pinned key, or a public key of the pinned DID, or else
+// a public key of the resolved DIDs svcs. The recipient keys of the inlined service blocks are not trusted.
+func (s *Service) inviterKey(svcs []interface{}, pins *inviterPins, kid string) ([]byte, error) {
+	if pins.Key != "" {
+		if kid != pins.Key {
+			return nil, fmt.Errorf("key %s is not the pinned key of the inviter", kid)
+		}
+
+		return base58.Decode(kid), nil
+	}
+
+	if pins.DID != "" {
+		svcs = []interface{}{pins.DID}
+	}
+
+	for i := range svcs {
+		didID, ok := svcs[i].(string)
+		if !ok {
+			continue
+		}
+
+		doc, err := s.vdriRegistry.Resolve(didID)
+		if err != nil {
+			return nil, fmt.Errorf("resolve inviter DID: %w", err)
+		}
+
+		for _, pk := range doc.PublicKey {
+			if pk.ID == kid && pk.Type == ed25519KeyType {
+				return pk.Value, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("key %s is not a key of the inviter", kid)
+}
+
+// canonicalRequest returns the canonical JSON of the request r without signature: the JSON object members are
+// sorted, so the canonical JSON of a request doesn't depend on the type of its service blocks.
+func canonicalRequest(r *Request) ([]byte, error) {
+	unsigned := *r
+	unsigned.Signature = ""
+
+	raw, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	var generic interface{}
+
+	err = json.Unmarshal(raw, &generic)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal request: %w", err)
+	}
+
+	return json.Marshal(generic)
+}
+
+func toService(svc interface{}) (*did.Service, error) {
+	if s, ok := svc.(*did.Service); ok {
+		return s, nil
+	}
+
+	raw, err := json.Marshal(svc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal service block: %w", err)
+	}
+
+	s := &did.Service{}
+
+	err = json.Unmarshal(raw, s)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal service block: %w", err)
+	}
+
+	return s, nil
+}
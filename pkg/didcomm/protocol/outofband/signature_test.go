@@ -0,0 +1,209 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outofband
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+)
+
+func TestSignedRequest(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verKey := base58.Encode(pubKey)
+
+	newSignedRequest := func(t *testing.T) *Request {
+		req := newRequest()
+		req.Service = []interface{}{&did.Service{
+			ID:              uuid.New().String(),
+			Type:            "did-communication",
+			RecipientKeys:   []string{verKey},
+			ServiceEndpoint: "http://my.test.endpoint.com",
+		}}
+
+		require.NoError(t, SignRequest(req, &testSigner{privKey: privKey, kid: verKey}))
+		require.NotEmpty(t, req.Signature)
+
+		return req
+	}
+
+	t.Run("accepts valid signed request", func(t *testing.T) {
+		s := newAutoService(t, testProvider(), WithUnsignedRequests(false))
+
+		_, err := s.AcceptRequest(newSignedRequest(t), WithInviterKey(verKey))
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects request signed with an inlined key not pinned", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+
+		// whoever tampers with the request can replace the inlined key and sign again
+		_, err := s.AcceptRequest(newSignedRequest(t))
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.Contains(t, err.Error(), "is not a key of the inviter")
+
+		_, err = s.AcceptRequest(newSignedRequest(t), WithInviterKey(base58.Encode([]byte("other"))))
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.Contains(t, err.Error(), "is not the pinned key of the inviter")
+	})
+
+	t.Run("accepts valid request signed with a key of a DID", func(t *testing.T) {
+		const didID = "did:example:inviter"
+
+		provider := testProvider()
+		provider.CustomVDRI = &mockvdri.MockVDRIRegistry{ResolveValue: &did.Doc{
+			ID: didID,
+			PublicKey: []did.PublicKey{{
+				ID: didID + "#key-1", Type: ed25519KeyType, Controller: didID, Value: pubKey,
+			}},
+		}}
+		s := newAutoService(t, provider, WithUnsignedRequests(false))
+
+		req := newRequest()
+		req.Service = []interface{}{didID}
+
+		require.NoError(t, SignRequest(req, &testSigner{privKey: privKey, kid: didID + "#key-1"}))
+
+		_, err := s.AcceptRequest(req)
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects request signed with a key of a DID other than the pinned one", func(t *testing.T) {
+		const didID = "did:example:inviter"
+
+		provider := testProvider()
+		provider.CustomVDRI = &mockvdri.MockVDRIRegistry{
+			ResolveFunc: func(id string, _ ...vdriapi.ResolveOpts) (*did.Doc, error) {
+				return &did.Doc{
+					ID: id,
+					PublicKey: []did.PublicKey{{
+						ID: id + "#key-1", Type: ed25519KeyType, Controller: id, Value: pubKey,
+					}},
+				}, nil
+			},
+		}
+		s := newAutoService(t, provider)
+
+		req := newRequest()
+		req.Service = []interface{}{"did:example:tamperer"}
+
+		require.NoError(t, SignRequest(req, &testSigner{privKey: privKey, kid: "did:example:tamperer#key-1"}))
+
+		_, err := s.AcceptRequest(req, WithInviterDID(didID))
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.Contains(t, err.Error(), "is not a key of the inviter")
+
+		req.Service = []interface{}{didID}
+
+		require.NoError(t, SignRequest(req, &testSigner{privKey: privKey, kid: didID + "#key-1"}))
+
+		_, err = s.AcceptRequest(req, WithInviterDID(didID))
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects request with attachment modified after signing", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+
+		req := newSignedRequest(t)
+		req.Requests = append(req.Requests, &decorator.Attachment{
+			ID:   uuid.New().String(),
+			Data: decorator.AttachmentData{Base64: "tampered"},
+		})
+
+		_, err := s.AcceptRequest(req, WithInviterKey(verKey))
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+
+		req = newSignedRequest(t)
+		req.Requests[0].Data.Base64 = "tampered"
+
+		_, err = s.AcceptRequest(req, WithInviterKey(verKey))
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+	})
+
+	t.Run("rejects request signed with a key not of the inviter", func(t *testing.T) {
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		s := newAutoService(t, testProvider())
+
+		req := newSignedRequest(t)
+		require.NoError(t, SignRequest(req, &testSigner{privKey: otherKey, kid: verKey}))
+
+		_, err = s.AcceptRequest(req, WithInviterKey(verKey))
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+
+		require.NoError(t, SignRequest(req, &testSigner{privKey: otherKey, kid: "unknown"}))
+
+		_, err = s.AcceptRequest(req)
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.Contains(t, err.Error(), "key unknown is not a key of the inviter")
+	})
+
+	t.Run("rejects request with unsupported algorithm", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+
+		req := newSignedRequest(t)
+		require.NoError(t, SignRequest(req, &testSigner{privKey: privKey, kid: verKey, alg: "ES256"}))
+
+		_, err := s.AcceptRequest(req)
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.Contains(t, err.Error(), "unsupported algorithm ES256")
+	})
+
+	t.Run("rejects request with unresolvable inviter DID", func(t *testing.T) {
+		s := newAutoService(t, testProvider())
+
+		req := newRequest()
+		req.Service = []interface{}{"did:example:unknown"}
+		require.NoError(t, SignRequest(req, &testSigner{privKey: privKey, kid: "did:example:unknown#key-1"}))
+
+		_, err := s.AcceptRequest(req)
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.Contains(t, err.Error(), "resolve inviter DID")
+	})
+
+	t.Run("unsigned requests", func(t *testing.T) {
+		_, err := newAutoService(t, testProvider()).AcceptRequest(newRequest())
+		require.NoError(t, err)
+
+		_, err = newAutoService(t, testProvider(), WithUnsignedRequests(false)).AcceptRequest(newRequest())
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+		require.Contains(t, err.Error(), "the request is not signed")
+	})
+}
+
+type testSigner struct {
+	privKey ed25519.PrivateKey
+	kid     string
+	alg     string
+}
+
+func (s *testSigner) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.privKey, data), nil
+}
+
+func (s *testSigner) Headers() jose.Headers {
+	alg := s.alg
+	if alg == "" {
+		alg = SignatureAlgorithm
+	}
+
+	return jose.Headers{jose.HeaderAlgorithm: alg, jose.HeaderKeyID: s.kid}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package presentproof
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/returnroute"
+)
+
+const (
+	jsonService  = "~service"
+	jsonThreadID = "thid"
+	jsonParentID = "pthid"
+
+	// the expired reply destinations are removed every replyDestinationGCInterval
+	replyDestinationGCInterval = time.Minute
+)
+
+// connectionlessProvider is implemented by the providers supporting connectionless replies, eg: the framework
+// context. The replies to the inbound messages with a ~service decorator (eg: a request-presentation of an
+// out-of-band request) are sent to the ~service of the message, with a sender key of their own.
+type connectionlessProvider interface {
+	TransientStorageProvider() storage.Provider
+	LegacyKMS() legacykms.KeyManager
+	OutboundDispatcher() dispatcher.Outbound
+}
+
+// connectionless sends the replies of the threads of connectionless messages to their reply destination.
+type connectionless struct {
+	routes     *returnroute.TTLStore
+	kms        legacykms.KeyManager
+	dispatcher dispatcher.Outbound
+	stop       chan struct{}
+	stopOnce   sync.Once
+}
+
+// newConnectionless returns the connectionless replies of the provider p, nil if p does not support them. The
+// expired reply destinations are removed in the background, until close is called.
+func newConnectionless(p Provider) (*connectionless, error) {
+	cp, ok := p.(connectionlessProvider)
+	if !ok {
+		return nil, nil
+	}
+
+	routes, err := returnroute.New(cp)
+	if err != nil {
+		return nil, fmt.Errorf("connectionless replies: %w", err)
+	}
+
+	c := &connectionless{
+		routes:     routes,
+		kms:        cp.LegacyKMS(),
+		dispatcher: cp.OutboundDispatcher(),
+		stop:       make(chan struct{}),
+	}
+
+	go removeExpiredReplyDestinations(routes, replyDestinationGCInterval, c.stop)
+
+	return c, nil
+}
+
+// close stops the removal of the expired reply destinations.
+func (c *connectionless) close() {
+	if c == nil {
+		return
+	}
+
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+// removeExpiredReplyDestinations removes the expired reply destinations of routes every interval, until stop is
+// closed.
+func removeExpiredReplyDestinations(routes *returnroute.TTLStore, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := routes.RemoveExpired(); err != nil {
+				logger.Errorf("remove expired reply destinations: %s", err)
+			}
+		}
+	}
+}
+
+// saveReplyDestination saves the reply destination of the thread piID if msg, received without connection, has a
+// ~service decorator. The replies to the messages received through a connection are sent through the connection.
+func (c *connectionless) saveReplyDestination(piID string, msg service.DIDCommMsgMap, theirDID string) error {
+	if c == nil || theirDID != "" {
+		return nil
+	}
+
+	if _, ok := msg[jsonService]; !ok {
+		return nil
+	}
+
+	svc := struct {
+		Service *decorator.Service `json:"~service"`
+	}{}
+
+	if err := msg.Decode(&svc); err != nil {
+		return fmt.Errorf("decode service decorator: %w", err)
+	}
+
+	return c.routes.Put(piID, returnroute.Destination(svc.Service))
+}
+
+// messenger returns the messenger of the thread of md: the replies are sent to the reply destination of the thread,
+// if it has one, with messenger otherwise.
+func (c *connectionless) messenger(md *metaData, messenger service.Messenger) (service.Messenger, error) {
+	if c == nil {
+		return messenger, nil
+	}
+
+	destination, err := c.routes.Get(md.PIID)
+	if errors.Is(err, returnroute.ErrNotFound) {
+		return messenger, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reply destination: %w", err)
+	}
+
+	return &connectionlessMessenger{
+		Messenger:   messenger,
+		replies:     c,
+		piID:        md.PIID,
+		msg:         md.Msg,
+		destination: destination,
+	}, nil
+}
+
+// connectionlessMessenger replies to the connectionless message msg of the thread piID at its reply destination.
+type connectionlessMessenger struct {
+	service.Messenger
+	replies     *connectionless
+	piID        string
+	msg         service.DIDCommMsgMap
+	destination *service.Destination
+}
+
+// ReplyTo sends the reply msg to the reply destination of the thread, which is removed once the reply is sent.
+func (m *connectionlessMessenger) ReplyTo(_ string, msg service.DIDCommMsgMap) error {
+	thID, err := m.msg.ThreadID()
+	if err != nil {
+		return fmt.Errorf("threadID: %w", err)
+	}
+
+	thread := map[string]interface{}{jsonThreadID: thID}
+
+	if pthID := m.msg.ParentThreadID(); pthID != "" {
+		thread[jsonParentID] = pthID
+	}
+
+	if msg.ID() == "" {
+		if err = msg.SetID(uuid.New().String()); err != nil {
+			return fmt.Errorf("set ID: %w", err)
+		}
+	}
+
+	msg[jsonThread] = thread
+
+	// the sender key of the reply, there is no connection to take it from
+	_, sender, err := m.replies.kms.CreateKeySet()
+	if err != nil {
+		return fmt.Errorf("create sender key: %w", err)
+	}
+
+	if err = m.replies.dispatcher.Send(msg, sender, m.destination); err != nil {
+		return fmt.Errorf("send connectionless reply: %w", err)
+	}
+
+	return m.replies.routes.Delete(m.piID)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package presentproof
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	serviceMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/common/service"
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/store/returnroute"
+)
+
+// testConnectionlessProvider provides the dependencies of the connectionless replies
+type testConnectionlessProvider struct {
+	*mockprovider.Provider
+	messenger service.Messenger
+}
+
+func (p *testConnectionlessProvider) Messenger() service.Messenger {
+	return p.messenger
+}
+
+func TestService_ConnectionlessReply(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	verifier := &decorator.Service{
+		RecipientKeys:   []string{"verifier-key"},
+		RoutingKeys:     []string{"routing-key"},
+		ServiceEndpoint: "https://verifier.example.com",
+	}
+
+	// connectionlessRequest is a request-presentation of the verifier, received without connection
+	connectionlessRequest := func() service.DIDCommMsgMap {
+		return service.NewDIDCommMsgMap(struct {
+			ID      string             `json:"@id"`
+			Type    string             `json:"@type"`
+			Thread  decorator.Thread   `json:"~thread"`
+			Service *decorator.Service `json:"~service"`
+		}{
+			ID:      uuid.New().String(),
+			Type:    RequestPresentationMsgType,
+			Thread:  decorator.Thread{ID: uuid.New().String()},
+			Service: verifier,
+		})
+	}
+
+	newProver := func(t *testing.T, outbound *mockdispatcher.MockOutbound) (*Service, *returnroute.TTLStore) {
+		provider := &testConnectionlessProvider{
+			Provider: &mockprovider.Provider{
+				StorageProviderValue:          mem.NewProvider(),
+				TransientStorageProviderValue: mem.NewProvider(),
+				KMSValue:                      &mockkms.CloseableKMS{CreateSigningKeyValue: "prover-key"},
+				OutboundDispatcherValue:       outbound,
+			},
+			// the replies are not sent through a connection
+			messenger: serviceMocks.NewMockMessenger(ctrl),
+		}
+
+		svc, err := New(provider)
+		require.NoError(t, err)
+
+		routes, err := returnroute.New(provider)
+		require.NoError(t, err)
+
+		return svc, routes
+	}
+
+	t.Run("the presentation is sent to the ~service of the request", func(t *testing.T) {
+		sent := make(chan service.DIDCommMsgMap, 1)
+
+		svc, routes := newProver(t, &mockdispatcher.MockOutbound{
+			ValidateSend: func(msg interface{}, senderVerKey string, des *service.Destination) error {
+				require.Equal(t, "prover-key", senderVerKey)
+				require.Equal(t, returnroute.Destination(verifier), des)
+
+				sent <- msg.(service.DIDCommMsgMap)
+
+				return nil
+			},
+		})
+
+		actions := make(chan service.DIDCommAction, 1)
+		require.NoError(t, svc.RegisterActionEvent(actions))
+
+		request := connectionlessRequest()
+		thID, err := request.ThreadID()
+		require.NoError(t, err)
+
+		_, err = svc.HandleInbound(request, "", "")
+		require.NoError(t, err)
+
+		destination, err := routes.Get(thID)
+		require.NoError(t, err)
+		require.Equal(t, verifier.ServiceEndpoint, destination.ServiceEndpoint)
+
+		select {
+		case action := <-actions:
+			action.Continue(WithPresentation(&Presentation{Comment: "connectionless"}))
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+
+		select {
+		case msg := <-sent:
+			require.Equal(t, PresentationMsgType, msg.Type())
+			require.NotEmpty(t, msg.ID())
+
+			replyThID, err := msg.ThreadID()
+			require.NoError(t, err)
+			require.Equal(t, thID, replyThID)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+
+		// the reply destination is removed once the reply is sent
+		require.Eventually(t, func() bool {
+			_, err := routes.Get(thID)
+
+			return errors.Is(err, returnroute.ErrNotFound)
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("the reply destination is kept if the reply is not sent", func(t *testing.T) {
+		svc, _ := newProver(t, &mockdispatcher.MockOutbound{SendErr: errors.New("send error")})
+
+		request := connectionlessRequest()
+		thID, err := request.ThreadID()
+		require.NoError(t, err)
+
+		require.NoError(t, svc.connectionless.saveReplyDestination(thID, request, ""))
+
+		md := &metaData{transitionalPayload: transitionalPayload{PIID: thID, Msg: request}}
+
+		messenger, err := svc.connectionless.messenger(md, svc.messenger)
+		require.NoError(t, err)
+
+		err = messenger.ReplyTo(request.ID(), service.NewDIDCommMsgMap(&Presentation{Type: PresentationMsgType}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "send connectionless reply")
+
+		_, err = svc.connectionless.routes.Get(thID)
+		require.NoError(t, err)
+	})
+
+	t.Run("the ~service of messages received through a connection is ignored", func(t *testing.T) {
+		svc, routes := newProver(t, &mockdispatcher.MockOutbound{})
+
+		actions := make(chan service.DIDCommAction, 1)
+		require.NoError(t, svc.RegisterActionEvent(actions))
+
+		request := connectionlessRequest()
+		thID, err := request.ThreadID()
+		require.NoError(t, err)
+
+		_, err = svc.HandleInbound(request, Alice, Bob)
+		require.NoError(t, err)
+
+		_, err = routes.Get(thID)
+		require.True(t, errors.Is(err, returnroute.ErrNotFound))
+	})
+
+	t.Run("messages without ~service are replied through their connection", func(t *testing.T) {
+		svc, _ := newProver(t, &mockdispatcher.MockOutbound{})
+
+		request := randomInboundMessage(RequestPresentationMsgType)
+		require.NoError(t, svc.connectionless.saveReplyDestination(request.ID(), request, ""))
+
+		md := &metaData{transitionalPayload: transitionalPayload{PIID: request.ID(), Msg: request}}
+
+		messenger, err := svc.connectionless.messenger(md, svc.messenger)
+		require.NoError(t, err)
+		require.Equal(t, svc.messenger, messenger)
+	})
+}
+
+func TestRemoveExpiredReplyDestinations(t *testing.T) {
+	provider := &mockprovider.Provider{TransientStorageProviderValue: mem.NewProvider()}
+
+	routes, err := returnroute.New(provider, returnroute.WithTTL(time.Millisecond))
+	require.NoError(t, err)
+
+	require.NoError(t, routes.Put("thread", &service.Destination{ServiceEndpoint: "https://verifier.example.com"}))
+
+	store, err := provider.TransientStorageProvider().OpenStore(returnroute.NameSpace)
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		removeExpiredReplyDestinations(routes, 5*time.Millisecond, stop)
+		close(stopped)
+	}()
+
+	// the expired destination is removed without being read
+	require.Eventually(t, func() bool {
+		itr := store.Iterator("", "~")
+		defer itr.Release()
+
+		return !itr.Next()
+	}, time.Second, 10*time.Millisecond)
+
+	close(stop)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the removal of the expired reply destinations is not stopped")
+	}
+}
+
+func TestService_Close(t *testing.T) {
+	svc, err := New(&testConnectionlessProvider{
+		Provider: &mockprovider.Provider{
+			StorageProviderValue:          mem.NewProvider(),
+			TransientStorageProviderValue: mem.NewProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue:       &mockdispatcher.MockOutbound{},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Close())
+	require.NoError(t, svc.Close())
+
+	select {
+	case <-svc.connectionless.stop:
+	default:
+		t.Fatal("the service is not stopped")
+	}
+}
@@ -108,6 +108,8 @@ func WithRequestPresentation(msg *RequestPresentation) Opt {
 }
 
 // Provider contains dependencies for the protocol and is typically created by using aries.Context()
+// The replies to connectionless messages are supported if the provider also has a transient storage, a legacy KMS and
+// an outbound dispatcher, like the framework context.
 type Provider interface {
 	Messenger() service.Messenger
 	StorageProvider() storage.Provider
@@ -118,10 +120,11 @@ type Provider interface {
 type Service struct {
 	service.Action
 	service.Message
-	store        storage.Store
-	callbacks    chan *metaData
-	messenger    service.Messenger
-	registryVDRI vdri.Registry
+	store          storage.Store
+	callbacks      chan *metaData
+	messenger      service.Messenger
+	registryVDRI   vdri.Registry
+	connectionless *connectionless
 }
 
 // New returns the presentproof service
@@ -131,11 +134,17 @@ func New(p Provider) (*Service, error) {
 		return nil, err
 	}
 
+	connectionless, err := newConnectionless(p)
+	if err != nil {
+		return nil, err
+	}
+
 	svc := &Service{
-		messenger:    p.Messenger(),
-		registryVDRI: p.VDRIRegistry(),
-		store:        store,
-		callbacks:    make(chan *metaData),
+		messenger:      p.Messenger(),
+		registryVDRI:   p.VDRIRegistry(),
+		store:          store,
+		callbacks:      make(chan *metaData),
+		connectionless: connectionless,
 	}
 
 	// start the listener
@@ -168,6 +177,13 @@ func (s *Service) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string)
 	md.MyDID = myDID
 	md.TheirDID = theirDID
 
+	// the replies to a connectionless message are sent to its ~service
+	if canReply {
+		if err = s.connectionless.saveReplyDestination(md.PIID, msgMap, theirDID); err != nil {
+			return "", fmt.Errorf("save reply destination: %w", err)
+		}
+	}
+
 	// trigger action event based on message type for inbound messages
 	if canReply && canTriggerActionEvents(msg) {
 		err = s.saveTransitionalPayload(md.PIID, md.transitionalPayload)
@@ -183,6 +199,13 @@ func (s *Service) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string)
 	return "", s.handle(md)
 }
 
+// Close stops the removal of the expired reply destinations of the connectionless messages.
+func (s *Service) Close() error {
+	s.connectionless.close()
+
+	return nil
+}
+
 // HandleOutbound handles outbound message (presentproof protocol)
 func (s *Service) HandleOutbound(_ service.DIDCommMsg, _, _ string) error {
 	return nil
@@ -280,7 +303,12 @@ func (s *Service) handle(md *metaData) error {
 			return fmt.Errorf("failed to persist state %s: %w", current.Name(), err)
 		}
 
-		if err := action(s.messenger); err != nil {
+		messenger, err := s.connectionless.messenger(md, s.messenger)
+		if err != nil {
+			return fmt.Errorf("messenger: %w", err)
+		}
+
+		if err := action(messenger); err != nil {
 			return fmt.Errorf("action %s: %w", md.state.Name(), err)
 		}
 
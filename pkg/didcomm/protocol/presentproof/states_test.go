@@ -367,6 +367,7 @@ func TestPresentationReceived_Execute(t *testing.T) {
 		registry.EXPECT().Resolve("did:example:ebfeb1f712ebc6f1c276e12ec21").Return(&did.Doc{
 			PublicKey: []did.PublicKey{{
 				ID:    "key-1",
+				Type:  "Ed25519VerificationKey2018",
 				Value: []byte{61, 133, 23, 17, 77, 132, 169, 196, 47, 203, 19, 71, 145, 144, 92, 145, 131, 101, 36, 251, 89, 216, 117, 140, 132, 226, 78, 187, 59, 58, 200, 255}, //nolint:lll
 			}},
 		}, nil)
@@ -6,10 +6,13 @@ SPDX-License-Identifier: Apache-2.0
 
 package route
 
+import "time"
+
 // Config provides the router configuration.
 type Config struct {
 	routerEndpoint string
 	routingKeys    []string
+	leaseExpires   time.Time
 }
 
 // NewConfig creates new config instance.
@@ -29,3 +32,8 @@ func (c *Config) Endpoint() string {
 func (c *Config) Keys() []string {
 	return c.routingKeys
 }
+
+// LeaseExpires returns the expiry of the route lease, or the zero time if the route was granted without lease.
+func (c *Config) LeaseExpires() time.Time {
+	return c.leaseExpires
+}
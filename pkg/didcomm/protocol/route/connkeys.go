@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// data key prefix to store the recipient key allocated to a connection
+const routeConnectionKeyDataKey = "route-connection-key-"
+
+// keySetDeleter is implemented by the key managers able to delete their key sets (eg: legacykms.BaseKMS).
+type keySetDeleter interface {
+	DeleteKeySet(verKey string) error
+}
+
+// AllocateKey creates a recipient key dedicated to the connection connectionID, registers it with the router and
+// returns it. Each connection using its own recipient key, the router can't correlate the connections of the agent.
+// The key allocated to the connection is returned if it already has one. It returns ErrRouterNotRegistered if the
+// agent is not registered with a router. This method blocks until a response is received from the router or it
+// times out.
+func (s *Service) AllocateKey(connectionID string) (string, error) {
+	recKey, err := s.ConnectionKey(connectionID)
+	if err == nil {
+		return recKey, nil
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return "", err
+	}
+
+	if _, err = s.GetConnection(); err != nil {
+		return "", err
+	}
+
+	_, recKey, err = s.kms.CreateKeySet()
+	if err != nil {
+		return "", fmt.Errorf("create connection key : %w", err)
+	}
+
+	// the key is allocated before it is registered, so a registered key can always be released
+	if err := s.routeStore.Put(connectionKeyDataKey(connectionID), []byte(recKey)); err != nil {
+		return "", fmt.Errorf("save connection key : %w", err)
+	}
+
+	if err := s.AddKey(recKey); err != nil {
+		if e := s.routeStore.Delete(connectionKeyDataKey(connectionID)); e != nil {
+			logger.Warnf("failed to delete the key of connection %s after a failed registration : %s",
+				connectionID, e)
+		}
+
+		return "", fmt.Errorf("register connection key : %w", err)
+	}
+
+	return recKey, nil
+}
+
+// ConnectionKey returns the recipient key allocated to the connection connectionID (see AllocateKey).
+func (s *Service) ConnectionKey(connectionID string) (string, error) {
+	recKey, err := s.routeStore.Get(connectionKeyDataKey(connectionID))
+	if err != nil {
+		return "", fmt.Errorf("get connection key : %w", err)
+	}
+
+	return string(recKey), nil
+}
+
+// ReleaseKey asks the router to stop forwarding messages for the recipient key allocated to the connection
+// connectionID (eg: when the connection is torn down) and removes the allocation. The key is also deleted from the
+// KMS if deleteKey is set. This method blocks until a response is received from the router or it times out.
+func (s *Service) ReleaseKey(connectionID string, deleteKey bool) error {
+	recKey, err := s.ConnectionKey(connectionID)
+	if err != nil {
+		return err
+	}
+
+	routerConnID, err := s.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	if err := s.removeRouterKeys(routerConnID, []string{recKey}); err != nil {
+		return fmt.Errorf("unregister connection key : %w", err)
+	}
+
+	if err := s.routeStore.Delete(connectionKeyDataKey(connectionID)); err != nil {
+		return fmt.Errorf("delete connection key : %w", err)
+	}
+
+	if !deleteKey {
+		return nil
+	}
+
+	deleter, ok := s.kms.(keySetDeleter)
+	if !ok {
+		return errors.New("kms does not support key deletion")
+	}
+
+	if err := deleter.DeleteKeySet(recKey); err != nil {
+		return fmt.Errorf("delete connection key from kms : %w", err)
+	}
+
+	return nil
+}
+
+func connectionKeyDataKey(connectionID string) string {
+	return routeConnectionKeyDataKey + connectionID
+}
@@ -0,0 +1,188 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+func TestConnectionKeys(t *testing.T) {
+	// newAgentAndRouter returns an agent registered with a router through the connection "router-conn", the
+	// keylist messages are delivered to the other service.
+	newAgentAndRouter := func(t *testing.T, kms legacykms.KeyManager) (*Service, map[string][]byte) {
+		var agent, router *Service
+
+		routerStore := make(map[string][]byte)
+
+		router, err := New(&mockprovider.Provider{
+			StorageProviderValue:          &mockstore.MockStoreProvider{Store: &mockstore.MockStore{Store: routerStore}},
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					resp, ok := msg.(*KeylistUpdateResponse)
+					require.True(t, ok)
+
+					go func() {
+						require.NoError(t, agent.handleKeylistUpdateResponse(
+							generateKeylistUpdateResponseMsgPayload(t, resp.ID, resp.Updated)))
+					}()
+
+					return nil
+				}}})
+		require.NoError(t, err)
+
+		agentStore := make(map[string][]byte)
+
+		agent, err = New(&mockprovider.Provider{
+			StorageProviderValue:          &mockstore.MockStoreProvider{Store: &mockstore.MockStore{Store: agentStore}},
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      kms,
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					update, ok := msg.(*KeylistUpdate)
+					require.True(t, ok)
+
+					return router.handleKeylistUpdate(
+						generateKeyUpdateListMsgPayload(t, update.ID, update.Updates), theirDID, myDID)
+				}}})
+		require.NoError(t, err)
+
+		connBytes, err := json.Marshal(&connection.Record{
+			ConnectionID: "router-conn", MyDID: MYDID, TheirDID: THEIRDID, State: "complete"})
+		require.NoError(t, err)
+
+		agentStore["conn_router-conn"] = connBytes
+		require.NoError(t, agent.saveRouterConnectionID("router-conn"))
+
+		return agent, routerStore
+	}
+
+	newKMS := func(t *testing.T) *legacykms.BaseKMS {
+		kms, err := legacykms.New(&mockprovider.Provider{StorageProviderValue: mockstore.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		return kms
+	}
+
+	t.Run("test connections are allocated distinct registered keys", func(t *testing.T) {
+		kms := newKMS(t)
+		agent, routerStore := newAgentAndRouter(t, kms)
+
+		aliceKey, err := agent.AllocateKey("alice-conn")
+		require.NoError(t, err)
+
+		bobKey, err := agent.AllocateKey("bob-conn")
+		require.NoError(t, err)
+
+		require.NotEqual(t, aliceKey, bobKey)
+
+		for _, recKey := range []string{aliceKey, bobKey} {
+			require.Equal(t, MYDID, string(routerStore[dataKey(recKey)]))
+
+			_, err = kms.FindVerKey([]string{recKey})
+			require.NoError(t, err)
+		}
+
+		keys, err := agent.Keys("router-conn")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{aliceKey, bobKey}, keys)
+
+		// the allocated key is reused
+		recKey, err := agent.AllocateKey("alice-conn")
+		require.NoError(t, err)
+		require.Equal(t, aliceKey, recKey)
+
+		recKey, err = agent.ConnectionKey("bob-conn")
+		require.NoError(t, err)
+		require.Equal(t, bobKey, recKey)
+	})
+
+	t.Run("test release connection key", func(t *testing.T) {
+		kms := newKMS(t)
+		agent, routerStore := newAgentAndRouter(t, kms)
+
+		aliceKey, err := agent.AllocateKey("alice-conn")
+		require.NoError(t, err)
+
+		bobKey, err := agent.AllocateKey("bob-conn")
+		require.NoError(t, err)
+
+		require.NoError(t, agent.ReleaseKey("alice-conn", false))
+		require.NotContains(t, routerStore, dataKey(aliceKey))
+
+		_, err = kms.FindVerKey([]string{aliceKey})
+		require.NoError(t, err)
+
+		require.NoError(t, agent.ReleaseKey("bob-conn", true))
+		require.NotContains(t, routerStore, dataKey(bobKey))
+
+		_, err = kms.FindVerKey([]string{bobKey})
+		require.Error(t, err)
+
+		keys, err := agent.Keys("router-conn")
+		require.NoError(t, err)
+		require.Empty(t, keys)
+
+		_, err = agent.ConnectionKey("alice-conn")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		err = agent.ReleaseKey("alice-conn", false)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test release connection key - kms does not support key deletion", func(t *testing.T) {
+		agent, routerStore := newAgentAndRouter(t, &mockkms.CloseableKMS{CreateSigningKeyValue: "key1"})
+
+		recKey, err := agent.AllocateKey("alice-conn")
+		require.NoError(t, err)
+		require.Equal(t, "key1", recKey)
+
+		err = agent.ReleaseKey("alice-conn", true)
+		require.EqualError(t, err, "kms does not support key deletion")
+		require.NotContains(t, routerStore, dataKey(recKey))
+	})
+
+	t.Run("test allocate key - errors", func(t *testing.T) {
+		agent, _ := newAgentAndRouter(t, &mockkms.CloseableKMS{CreateKeyErr: errors.New("create error")})
+
+		_, err := agent.AllocateKey("alice-conn")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create error")
+
+		require.NoError(t, agent.saveRouterConnectionID(""))
+		agent.kms = &mockkms.CloseableKMS{}
+
+		_, err = agent.AllocateKey("alice-conn")
+		require.True(t, errors.Is(err, ErrRouterNotRegistered))
+
+		_, err = agent.ConnectionKey("alice-conn")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		// the key is not allocated if it can't be registered
+		require.NoError(t, agent.saveRouterConnectionID("unknown-conn"))
+
+		_, err = agent.AllocateKey("alice-conn")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "register connection key")
+
+		_, err = agent.ConnectionKey("alice-conn")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+}
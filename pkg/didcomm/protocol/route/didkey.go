@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+const (
+	// didKeyPrefix is the prefix of did:key identifiers with base58-btc multibase encoding.
+	// https://w3c-ccg.github.io/did-method-key/
+	didKeyPrefix = "did:key:z"
+)
+
+// ed25519 public key multicodec prefix (0xed) in varint form
+// nolint:gochecknoglobals
+var ed25519MulticodecPrefix = []byte{0xed, 0x01}
+
+// RoutingKeyToDIDKey converts raw base58 encoded ed25519 routing key to its did:key identifier.
+func RoutingKeyToDIDKey(routingKey string) (string, error) {
+	pubKey := base58.Decode(routingKey)
+	if len(pubKey) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("invalid ed25519 routing key : %s", routingKey)
+	}
+
+	return didKeyPrefix + base58.Encode(append(ed25519MulticodecPrefix, pubKey...)), nil
+}
+
+// ResolveRoutingKey returns raw base58 encoded public key for given routing key.
+// Routing key can be in raw base58 form or in did:key form.
+func ResolveRoutingKey(routingKey string) (string, error) {
+	if !strings.HasPrefix(routingKey, "did:key:") {
+		return routingKey, nil
+	}
+
+	if !strings.HasPrefix(routingKey, didKeyPrefix) {
+		return "", fmt.Errorf("unsupported did:key multibase encoding : %s", routingKey)
+	}
+
+	// strip fragment if key reference is provided (ex: did:key:z6Mk...#z6Mk...)
+	id := strings.Split(strings.TrimPrefix(routingKey, didKeyPrefix), "#")[0]
+
+	multicodecKey := base58.Decode(id)
+	if !bytes.HasPrefix(multicodecKey, ed25519MulticodecPrefix) {
+		return "", errors.New("unsupported did:key public key type")
+	}
+
+	pubKey := multicodecKey[len(ed25519MulticodecPrefix):]
+	if len(pubKey) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("invalid did:key public key size : %d", len(pubKey))
+	}
+
+	return base58.Encode(pubKey), nil
+}
+
+// ResolveRoutingKeys returns raw base58 encoded public keys for given routing keys,
+// see ResolveRoutingKey.
+func ResolveRoutingKeys(routingKeys []string) ([]string, error) {
+	if len(routingKeys) == 0 {
+		return routingKeys, nil
+	}
+
+	keys := make([]string, len(routingKeys))
+
+	for i, routingKey := range routingKeys {
+		key, err := ResolveRoutingKey(routingKey)
+		if err != nil {
+			return nil, err
+		}
+
+		keys[i] = key
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutingKeyToDIDKey(t *testing.T) {
+	t.Run("test round trip routing key through did:key", func(t *testing.T) {
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		routingKey := base58.Encode(pubKey)
+
+		didKey, err := RoutingKeyToDIDKey(routingKey)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(didKey, "did:key:z6Mk"))
+
+		key, err := ResolveRoutingKey(didKey)
+		require.NoError(t, err)
+		require.Equal(t, routingKey, key)
+
+		key, err = ResolveRoutingKey(didKey + "#" + strings.TrimPrefix(didKey, "did:key:"))
+		require.NoError(t, err)
+		require.Equal(t, routingKey, key)
+	})
+
+	t.Run("test invalid routing key", func(t *testing.T) {
+		_, err := RoutingKeyToDIDKey("invalid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid ed25519 routing key")
+	})
+}
+
+func TestResolveRoutingKeys(t *testing.T) {
+	t.Run("test resolve raw and did:key routing keys", func(t *testing.T) {
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		rawKey := base58.Encode(pubKey)
+
+		didKey, err := RoutingKeyToDIDKey(rawKey)
+		require.NoError(t, err)
+
+		keys, err := ResolveRoutingKeys([]string{rawKey, didKey})
+		require.NoError(t, err)
+		require.Equal(t, []string{rawKey, rawKey}, keys)
+
+		keys, err = ResolveRoutingKeys(nil)
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+
+	t.Run("test resolve invalid did:key routing keys", func(t *testing.T) {
+		_, err := ResolveRoutingKeys([]string{"did:key:invalid"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported did:key multibase encoding")
+
+		_, err = ResolveRoutingKeys([]string{didKeyPrefix + base58.Encode([]byte{0xec, 0x01, 0x02})})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported did:key public key type")
+
+		_, err = ResolveRoutingKeys([]string{didKeyPrefix + base58.Encode([]byte{0xed, 0x01, 0x02})})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid did:key public key size")
+	})
+}
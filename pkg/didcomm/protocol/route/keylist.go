@@ -0,0 +1,256 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// data key prefix to store the recipient keys registered with the router
+	routeRegisteredKeyDataKey = "route-registered-key-"
+
+	// data key prefix to store the scopes of the recipient keys registered with the router under a scope
+	routeRegisteredKeyScopeDataKey = "route-registered-scope-"
+
+	// the key was not registered, nothing to remove
+	noChange = "no_change"
+
+	// the key is registered by another agent
+	clientError = "client_error"
+)
+
+// Keys returns the recipient keys registered with the router of connectionID (see AddKey).
+func (s *Service) Keys(connectionID string) ([]string, error) {
+	prefix := registeredKeyDataKey(connectionID, "")
+
+	itr := s.routeStore.Iterator(prefix, prefix+"~")
+	defer itr.Release()
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, strings.TrimPrefix(string(itr.Key()), prefix))
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, fmt.Errorf("iterate registered keys : %w", err)
+	}
+
+	return keys, nil
+}
+
+// RemoveKeys asks the router of connectionID to stop forwarding messages for all the recipient keys registered
+// with it (eg: when the agent is decommissioned) with a single keylist update. This method blocks until a response
+// is received from the router or it times out. Nothing is sent to the router if no key is registered.
+func (s *Service) RemoveKeys(connectionID string) error {
+	keys, err := s.Keys(connectionID)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		logger.Debugf("no recipient key registered with the router of connection %s", connectionID)
+
+		return nil
+	}
+
+	return s.removeRouterKeys(connectionID, keys)
+}
+
+// removeRouterKeys asks the router of connectionID to stop forwarding messages for the recipient keys with a single
+// keylist update and blocks until a response is received from the router or it times out.
+func (s *Service) removeRouterKeys(connectionID string, keys []string) error {
+	conn, err := s.getConnection(connectionID)
+	if err != nil {
+		return err
+	}
+
+	keyUpdate := &KeylistUpdate{ID: uuid.New().String(), Type: KeylistUpdateMsgType}
+
+	for _, recKey := range keys {
+		keyUpdate.Updates = append(keyUpdate.Updates, Update{RecipientKey: recKey, Action: remove})
+	}
+
+	keyUpdateCh := make(chan *KeylistUpdateResponse)
+	s.setKeyUpdateResponseCh(keyUpdate.ID, keyUpdateCh)
+
+	defer s.setKeyUpdateResponseCh(keyUpdate.ID, nil)
+
+	if err := s.outbound.SendToDID(keyUpdate, conn.MyDID, conn.TheirDID); err != nil {
+		return fmt.Errorf("send keylist update : %w", err)
+	}
+
+	select {
+	case keyUpdateResp := <-keyUpdateCh:
+		return s.processRemoveKeysResp(connectionID, keyUpdateResp)
+	case <-time.After(updateTimeout):
+		return errors.New("timeout waiting for keylist update response from the router")
+	}
+}
+
+func (s *Service) processRemoveKeysResp(connectionID string, keyUpdateResp *KeylistUpdateResponse) error {
+	var failed []string
+
+	for _, result := range keyUpdateResp.Updated {
+		if result.Action != remove {
+			continue
+		}
+
+		if result.Result != success && result.Result != noChange {
+			failed = append(failed, result.RecipientKey)
+
+			continue
+		}
+
+		if err := s.deleteRegisteredKey(connectionID, result.RecipientKey); err != nil {
+			return fmt.Errorf("delete registered key : %w", err)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to remove the recipient keys %v from the router", failed)
+	}
+
+	return nil
+}
+
+// updateKeylist applies the updates of the keylist of theirDID and returns the per-key results. The updates of a
+// keylist update message are applied altogether: they don't interleave with other keylist updates.
+func (s *Service) updateKeylist(updates []Update, theirDID string) []UpdateResponse {
+	s.keylistLock.Lock()
+	defer s.keylistLock.Unlock()
+
+	var results []UpdateResponse
+
+	for _, v := range updates {
+		var result string
+
+		switch v.Action {
+		case add:
+			result = s.addRouteKey(v.RecipientKey, v.Scope, theirDID)
+		case remove:
+			result = s.removeRouteKey(v.RecipientKey, theirDID)
+		default:
+			continue
+		}
+
+		results = append(results, UpdateResponse{
+			RecipientKey: v.RecipientKey,
+			Action:       v.Action,
+			Result:       result,
+			Scope:        v.Scope,
+		})
+	}
+
+	return results
+}
+
+func (s *Service) addRouteKey(recKey, scope, theirDID string) string {
+	if err := s.routeStore.Put(dataKey(recKey), []byte(theirDID)); err != nil {
+		logger.Errorf("failed to add the route key to store : %s", err)
+
+		return serverError
+	}
+
+	if err := s.routeStore.Put(keylistDataKey(theirDID, recKey), []byte(scope)); err != nil {
+		logger.Errorf("failed to add the route key to the keylist : %s", err)
+
+		return serverError
+	}
+
+	return success
+}
+
+func (s *Service) removeRouteKey(recKey, theirDID string) string {
+	did, err := s.routeStore.Get(dataKey(recKey))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return noChange
+	}
+
+	if err != nil {
+		logger.Errorf("failed to get the route key from store : %s", err)
+
+		return serverError
+	}
+
+	// only the agent which registered the key can remove it
+	if string(did) != theirDID {
+		return clientError
+	}
+
+	if err := s.routeStore.Delete(dataKey(recKey)); err != nil {
+		logger.Errorf("failed to remove the route key from store : %s", err)
+
+		return serverError
+	}
+
+	if err := s.routeStore.Delete(keylistDataKey(theirDID, recKey)); err != nil {
+		logger.Errorf("failed to remove the route key from the keylist : %s", err)
+
+		return serverError
+	}
+
+	return success
+}
+
+// saveRegisteredKey records recKey as registered with the router of connectionID under scope, or as global if scope
+// is empty.
+func (s *Service) saveRegisteredKey(connectionID, recKey, scope string) error {
+	var err error
+
+	if scope != "" {
+		err = s.routeStore.Put(registeredKeyScopeDataKey(connectionID, recKey), []byte(scope))
+	} else {
+		err = s.routeStore.Delete(registeredKeyScopeDataKey(connectionID, recKey))
+	}
+
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return err
+	}
+
+	return s.routeStore.Put(registeredKeyDataKey(connectionID, recKey), []byte(recKey))
+}
+
+// registeredKeyScope returns the scope recKey is registered under with the router of connectionID, it is empty if
+// the key is global.
+func (s *Service) registeredKeyScope(connectionID, recKey string) (string, error) {
+	scope, err := s.routeStore.Get(registeredKeyScopeDataKey(connectionID, recKey))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(scope), nil
+}
+
+func (s *Service) deleteRegisteredKey(connectionID, recKey string) error {
+	err := s.routeStore.Delete(registeredKeyScopeDataKey(connectionID, recKey))
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return err
+	}
+
+	return s.routeStore.Delete(registeredKeyDataKey(connectionID, recKey))
+}
+
+func registeredKeyDataKey(connectionID, recKey string) string {
+	return routeRegisteredKeyDataKey + connectionID + "-" + recKey
+}
+
+func registeredKeyScopeDataKey(connectionID, recKey string) string {
+	return routeRegisteredKeyScopeDataKey + connectionID + "-" + recKey
+}
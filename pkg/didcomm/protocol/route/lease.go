@@ -0,0 +1,191 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// data key prefix to store the route lease granted to a DID
+	routeLeaseDataKey = "route-lease-"
+
+	// problem report code sent when forwarding for a DID whose route lease expired
+	leaseExpiredCode = "lease_expired"
+)
+
+// ErrLeaseExpired route lease expired error
+var ErrLeaseExpired = errors.New("route lease expired")
+
+// lease is the route lease granted by the router, persisted to stop forwarding once expired.
+type lease struct {
+	MyDID    string    `json:"myDID"`
+	TheirDID string    `json:"theirDID"`
+	Expires  time.Time `json:"expires"`
+}
+
+// WithGrantLease option makes the router grant routes for a limited duration. Once the lease expires, the router
+// stops forwarding messages and sends a problem-report prompting the agent to request the route again.
+// By default, routes are granted without lease.
+func WithGrantLease(duration time.Duration) ServiceOption {
+	return func(opts *Service) {
+		opts.leaseDuration = duration
+	}
+}
+
+// grantLease saves a new lease for theirDID and returns its expiry, or nil if routes are granted without lease.
+func (s *Service) grantLease(myDID, theirDID string) (*time.Time, error) {
+	if s.leaseDuration <= 0 {
+		return nil, nil
+	}
+
+	l := &lease{
+		MyDID:    myDID,
+		TheirDID: theirDID,
+		Expires:  s.now().Add(s.leaseDuration).UTC(),
+	}
+
+	bytes, err := json.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("marshal route lease : %w", err)
+	}
+
+	if err := s.routeStore.Put(leaseDataKey(theirDID), bytes); err != nil {
+		return nil, fmt.Errorf("save route lease : %w", err)
+	}
+
+	return &l.Expires, nil
+}
+
+// checkLease returns ErrLeaseExpired, after sending a problem-report to theirDID, if the route lease of
+// theirDID expired. Routes granted without lease never expire.
+func (s *Service) checkLease(theirDID string) error {
+	bytes, err := s.routeStore.Get(leaseDataKey(theirDID))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetch route lease : %w", err)
+	}
+
+	l := &lease{}
+
+	if err := json.Unmarshal(bytes, l); err != nil {
+		return fmt.Errorf("unmarshal route lease : %w", err)
+	}
+
+	if s.now().Before(l.Expires) {
+		return nil
+	}
+
+	report := &model.ProblemReport{
+		Type:        ProblemReportMsgType,
+		ID:          uuid.New().String(),
+		Description: model.Code{Code: leaseExpiredCode},
+	}
+
+	if err := s.outbound.SendToDID(report, l.MyDID, l.TheirDID); err != nil {
+		return fmt.Errorf("send route lease expired problem report : %w", err)
+	}
+
+	return fmt.Errorf("%w for %s at %s", ErrLeaseExpired, theirDID, l.Expires.Format(time.RFC3339))
+}
+
+func (s *Service) handleProblemReport(msg service.DIDCommMsg, myDID, theirDID string) error {
+	report := &model.ProblemReport{}
+
+	err := msg.Decode(report)
+	if err != nil {
+		return fmt.Errorf("route problem report message unmarshal : %w", err)
+	}
+
+	code := report.Description.Code
+
+	// only the router can prompt the agent to request the route again
+	if code == leaseExpiredCode || code == routingKeysRotatedCode {
+		fromRouter, checkErr := s.isRouterConnection(myDID, theirDID)
+		if checkErr != nil || !fromRouter {
+			return dropProblemReport(report, checkErr)
+		}
+	}
+
+	switch report.Description.Code {
+	case leaseExpiredCode:
+		// the router stopped forwarding, request the route again
+		logger.Infof("route lease expired, renewing the route grant")
+
+		return s.Renew()
+	case routingKeysRotatedCode:
+		// the routing keys of the grant are stale, request the route again and register the keys again
+		logger.Infof("router rotated its routing keys, refreshing the route grant")
+
+		return s.refreshRoute()
+	default:
+		logger.Warnf("route problem report received : code=%s", report.Description.Code)
+
+		return nil
+	}
+}
+
+// dropProblemReport drops the route problem report, not sent by the router (or whose sender couldn't be checked).
+func dropProblemReport(report *model.ProblemReport, err error) error {
+	if err != nil {
+		return fmt.Errorf("check route problem report %s sender : %w", report.Description.Code, err)
+	}
+
+	logger.Warnf("route problem report %s not sent by the router, dropped", report.Description.Code)
+
+	return nil
+}
+
+// isRouterConnection returns true if myDID and theirDID are the DIDs of the connection with the registered router.
+func (s *Service) isRouterConnection(myDID, theirDID string) (bool, error) {
+	routerConnID, err := s.GetConnection()
+	if errors.Is(err, ErrRouterNotRegistered) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	connID, err := s.connectionLookup.GetConnectionIDByDIDs(myDID, theirDID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("fetch connection id : %w", err)
+	}
+
+	return connID == routerConnID, nil
+}
+
+// Renew requests the route again to the registered router, renewing the route lease. Agents registered with
+// a router granting routes with lease should renew it before it expires (see Config.LeaseExpires).
+func (s *Service) Renew() error {
+	routerConnID, err := s.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	return s.requestGrant(routerConnID)
+}
+
+func leaseDataKey(theirDID string) string {
+	return routeLeaseDataKey + theirDID
+}
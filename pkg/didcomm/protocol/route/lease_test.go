@@ -0,0 +1,340 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockdiddoc "github.com/hyperledger/aries-framework-go/pkg/mock/diddoc"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+// fakeClock is a clock advanced manually by the tests.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestGrantLease(t *testing.T) {
+	const leaseDuration = time.Hour
+
+	newRouter := func(t *testing.T, outbound *mockdispatcher.MockOutbound, clock *fakeClock) *Service {
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{CreateSigningKeyValue: "routingKey"},
+			OutboundDispatcherValue:       outbound,
+			VDRIRegistryValue: &mockvdri.MockVDRIRegistry{
+				ResolveFunc: func(didID string, opts ...vdri.ResolveOpts) (*did.Doc, error) {
+					return mockdiddoc.GetMockDIDDoc(), nil
+				},
+			},
+		}, WithGrantLease(leaseDuration))
+		require.NoError(t, err)
+
+		svc.now = clock.Now
+
+		return svc
+	}
+
+	t.Run("test grant with lease - forwarding stops once the lease expired", func(t *testing.T) {
+		clock := &fakeClock{now: time.Now()}
+
+		var (
+			grant     *Grant
+			report    *model.ProblemReport
+			forwarded int
+		)
+
+		router := newRouter(t, &mockdispatcher.MockOutbound{
+			ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+				require.Equal(t, MYDID, myDID)
+				require.Equal(t, THEIRDID, theirDID)
+
+				switch m := msg.(type) {
+				case *Grant:
+					grant = m
+				case *model.ProblemReport:
+					report = m
+				}
+
+				return nil
+			},
+			ValidateForward: func(msg interface{}, des *service.Destination) error {
+				forwarded++
+
+				return nil
+			},
+		}, clock)
+
+		require.NoError(t, router.handleRequest(generateRequestMsgPayload(t, randomID()), MYDID, THEIRDID))
+		require.NotNil(t, grant)
+		require.NotNil(t, grant.LeaseExpires)
+		require.True(t, clock.Now().Add(leaseDuration).Equal(*grant.LeaseExpires))
+
+		recKey := randomID()
+		require.NoError(t, router.routeStore.Put(dataKey(recKey), []byte(THEIRDID)))
+
+		forward := generateForwardMsgPayload(t, randomID(), recKey, nil)
+
+		// within the lease, messages are forwarded
+		clock.Advance(leaseDuration - time.Minute)
+		require.NoError(t, router.handleForward(forward))
+		require.Equal(t, 1, forwarded)
+		require.Nil(t, report)
+
+		// past the lease, forwarding stops and a problem report prompts the agent to request the route again
+		clock.Advance(2 * time.Minute)
+
+		err := router.handleForward(forward)
+		require.True(t, errors.Is(err, ErrLeaseExpired))
+		require.Equal(t, 1, forwarded)
+		require.NotNil(t, report)
+		require.Equal(t, ProblemReportMsgType, report.Type)
+		require.Equal(t, leaseExpiredCode, report.Description.Code)
+
+		// a new route request renews the lease
+		require.NoError(t, router.handleRequest(generateRequestMsgPayload(t, randomID()), MYDID, THEIRDID))
+		require.NoError(t, router.handleForward(forward))
+		require.Equal(t, 2, forwarded)
+	})
+
+	t.Run("test grant without lease - forwarding never expires", func(t *testing.T) {
+		var grant *Grant
+
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					grant = msg.(*Grant)
+
+					return nil
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, svc.handleRequest(generateRequestMsgPayload(t, randomID()), MYDID, THEIRDID))
+		require.NotNil(t, grant)
+		require.Nil(t, grant.LeaseExpires)
+
+		_, err = svc.routeStore.Get(leaseDataKey(THEIRDID))
+		require.Error(t, err)
+		require.NoError(t, svc.checkLease(THEIRDID))
+	})
+
+	t.Run("test grant with lease - save lease error", func(t *testing.T) {
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue: &mockstore.MockStoreProvider{
+				Store: &mockstore.MockStore{Store: map[string][]byte{}, ErrPut: errors.New("put error")},
+			},
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue:       &mockdispatcher.MockOutbound{},
+		}, WithGrantLease(leaseDuration))
+		require.NoError(t, err)
+
+		err = svc.handleRequest(generateRequestMsgPayload(t, randomID()), MYDID, THEIRDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "save route lease")
+	})
+
+	t.Run("test lease check errors", func(t *testing.T) {
+		clock := &fakeClock{now: time.Now()}
+		router := newRouter(t, &mockdispatcher.MockOutbound{SendErr: errors.New("send error")}, clock)
+
+		require.NoError(t, router.routeStore.Put(leaseDataKey(THEIRDID), []byte("invalid")))
+		err := router.checkLease(THEIRDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal route lease")
+
+		_, err = router.grantLease(MYDID, THEIRDID)
+		require.NoError(t, err)
+
+		clock.Advance(2 * leaseDuration)
+
+		err = router.checkLease(THEIRDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "send route lease expired problem report")
+	})
+}
+
+func TestRenew(t *testing.T) {
+	leaseExpires := time.Now().Add(time.Hour).UTC().Round(time.Second)
+
+	newAgent := func(t *testing.T, grants chan<- string) *Service {
+		prov := &mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					grants <- msg.(*Request).ID
+
+					return nil
+				}},
+		}
+
+		svc, err := New(prov)
+		require.NoError(t, err)
+
+		recorder, err := connection.NewRecorder(prov)
+		require.NoError(t, err)
+
+		// the router connection, and the connection with another peer
+		require.NoError(t, recorder.SaveConnectionRecord(&connection.Record{
+			ConnectionID: "conn1", MyDID: MYDID, TheirDID: THEIRDID, State: "completed"}))
+		require.NoError(t, recorder.SaveConnectionRecord(&connection.Record{
+			ConnectionID: "conn2", MyDID: MYDID, TheirDID: "did:example:peer", State: "completed"}))
+
+		return svc
+	}
+
+	grantWithLease := func(t *testing.T, svc *Service, requests <-chan string) {
+		id := <-requests
+
+		grantBytes, err := json.Marshal(&Grant{
+			Type:         GrantMsgType,
+			ID:           id,
+			Endpoint:     ENDPOINT,
+			LeaseExpires: &leaseExpires,
+		})
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(grantBytes)
+		require.NoError(t, err)
+
+		require.NoError(t, svc.handleGrant(msg))
+	}
+
+	t.Run("test renew - lease is saved with the grant", func(t *testing.T) {
+		requests := make(chan string)
+		svc := newAgent(t, requests)
+
+		err := svc.Renew()
+		require.True(t, errors.Is(err, ErrRouterNotRegistered))
+
+		go grantWithLease(t, svc, requests)
+
+		require.NoError(t, svc.Register("conn1"))
+
+		conf, err := svc.Config()
+		require.NoError(t, err)
+		require.True(t, leaseExpires.Equal(conf.LeaseExpires()))
+
+		leaseExpires = leaseExpires.Add(time.Hour)
+
+		go grantWithLease(t, svc, requests)
+
+		require.NoError(t, svc.Renew())
+
+		conf, err = svc.Config()
+		require.NoError(t, err)
+		require.True(t, leaseExpires.Equal(conf.LeaseExpires()))
+	})
+
+	t.Run("test renew - lease expired problem report triggers the renewal", func(t *testing.T) {
+		requests := make(chan string)
+		svc := newAgent(t, requests)
+
+		require.NoError(t, svc.saveRouterConnectionID("conn1"))
+
+		report, err := json.Marshal(&model.ProblemReport{
+			Type:        ProblemReportMsgType,
+			ID:          randomID(),
+			Description: model.Code{Code: leaseExpiredCode},
+		})
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(report)
+		require.NoError(t, err)
+
+		go grantWithLease(t, svc, requests)
+
+		require.NoError(t, svc.handleProblemReport(msg, MYDID, THEIRDID))
+
+		conf, err := svc.Config()
+		require.NoError(t, err)
+		require.True(t, leaseExpires.Equal(conf.LeaseExpires()))
+	})
+
+	t.Run("test renew - lease expired problem report not sent by the router is dropped", func(t *testing.T) {
+		requests := make(chan string, 1)
+		svc := newAgent(t, requests)
+
+		report, err := json.Marshal(&model.ProblemReport{
+			Type:        ProblemReportMsgType,
+			ID:          randomID(),
+			Description: model.Code{Code: leaseExpiredCode},
+		})
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(report)
+		require.NoError(t, err)
+
+		// no router registered
+		require.NoError(t, svc.handleProblemReport(msg, MYDID, THEIRDID))
+
+		require.NoError(t, svc.saveRouterConnectionID("conn1"))
+
+		// a connected peer other than the router, and an unknown sender
+		require.NoError(t, svc.handleProblemReport(msg, MYDID, "did:example:peer"))
+		require.NoError(t, svc.handleProblemReport(msg, MYDID, "did:example:unknown"))
+
+		_, err = svc.HandleInbound(msg, MYDID, "did:example:peer")
+		require.NoError(t, err)
+
+		select {
+		case <-requests:
+			require.FailNow(t, "route requested on a problem report not sent by the router")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("test renew - other problem reports are ignored", func(t *testing.T) {
+		svc := newAgent(t, make(chan string))
+
+		report, err := json.Marshal(&model.ProblemReport{
+			Type:        ProblemReportMsgType,
+			ID:          randomID(),
+			Description: model.Code{Code: "other"},
+		})
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(report)
+		require.NoError(t, err)
+
+		require.NoError(t, svc.handleProblemReport(msg, MYDID, THEIRDID))
+
+		err = svc.handleProblemReport(&service.DIDCommMsgMap{"@id": map[int]int{}}, MYDID, THEIRDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "route problem report message unmarshal")
+	})
+}
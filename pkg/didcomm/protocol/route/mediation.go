@@ -0,0 +1,195 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// data key prefix to store the mediation denial of the router on the other end of a connection
+const routeDenialDataKey = "route-denial-"
+
+// ErrMediationDenied mediation denied error, the errors returned when the router denies mediation are
+// *MediationDeniedError.
+var ErrMediationDenied = errors.New("mediation denied")
+
+// MediationDeniedError is returned when the router denies the route request. The route must not be requested
+// again before RetryAfter, the requests made before are denied without contacting the router.
+type MediationDeniedError struct {
+	Reason string
+	// RetryAfter is the time to wait before requesting the route again, zero if the router gave no hint.
+	RetryAfter time.Duration
+}
+
+func (e *MediationDeniedError) Error() string {
+	msg := ErrMediationDenied.Error()
+
+	if e.Reason != "" {
+		msg += " : " + e.Reason
+	}
+
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter)
+	}
+
+	return msg
+}
+
+// Unwrap returns ErrMediationDenied.
+func (e *MediationDeniedError) Unwrap() error {
+	return ErrMediationDenied
+}
+
+// MediationPolicy decides whether the router grants the route requested by the agent theirDID: it returns nil to
+// grant the route, or the mediation-deny message sent to the agent, eg: when the agent exceeded its quota. The
+// policy is applied to every route request, an agent denied mediation is granted the route on a later request
+// once the policy allows it.
+type MediationPolicy func(myDID, theirDID string) *MediationDeny
+
+// WithMediationPolicy option sets the policy of the router to grant routes, routes are always granted by default.
+func WithMediationPolicy(policy MediationPolicy) ServiceOption {
+	return func(opts *Service) {
+		opts.mediationPolicy = policy
+	}
+}
+
+// routeResponse is the response of the router to a route request: a grant or a mediation denial.
+type routeResponse struct {
+	grant *Grant
+	deny  *MediationDeny
+}
+
+// denial is the mediation denial of a router, persisted to not request the route again before retryAt.
+type denial struct {
+	Reason  string    `json:"reason,omitempty"`
+	RetryAt time.Time `json:"retryAt"`
+}
+
+// denyMediation returns the mediation-deny message answering the route request msgID of theirDID, or nil if the
+// route is granted.
+func (s *Service) denyMediation(msgID, myDID, theirDID string) *MediationDeny {
+	if s.mediationPolicy == nil {
+		return nil
+	}
+
+	deny := s.mediationPolicy(myDID, theirDID)
+	if deny == nil {
+		return nil
+	}
+
+	deny.Type = MediationDenyMsgType
+	deny.ID = msgID
+
+	return deny
+}
+
+func (s *Service) handleMediationDeny(msg service.DIDCommMsg) error {
+	deny := &MediationDeny{}
+
+	err := msg.Decode(deny)
+	if err != nil {
+		return fmt.Errorf("mediation deny message unmarshal : %w", err)
+	}
+
+	// check if there are any channels registered for the message ID
+	responseCh := s.getRouteRegistrationCh(deny.ID)
+
+	if responseCh != nil {
+		responseCh <- routeResponse{deny: deny}
+
+		return nil
+	}
+
+	// nothing waits for the response, the request might have been sent before a restart
+	connectionID, err := s.routeStore.Get(pendingGrantDataKey(deny.ID))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		logger.Debugf("no route request found for the mediation denial : id=%s", deny.ID)
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetch pending route request : %w", err)
+	}
+
+	s.deletePendingGrant(deny.ID)
+
+	return s.saveDenial(string(connectionID), deny)
+}
+
+// denied saves the mediation denial of the router on the other end of connectionID and returns the matching
+// *MediationDeniedError.
+func (s *Service) denied(connectionID string, deny *MediationDeny) error {
+	if err := s.saveDenial(connectionID, deny); err != nil {
+		return err
+	}
+
+	return &MediationDeniedError{Reason: deny.Reason, RetryAfter: retryAfter(deny)}
+}
+
+// saveDenial saves the mediation denial of the router on the other end of connectionID, if it has a retry-after.
+func (s *Service) saveDenial(connectionID string, deny *MediationDeny) error {
+	if retryAfter(deny) <= 0 {
+		return nil
+	}
+
+	bytes, err := json.Marshal(&denial{Reason: deny.Reason, RetryAt: s.now().Add(retryAfter(deny)).UTC()})
+	if err != nil {
+		return fmt.Errorf("marshal mediation denial : %w", err)
+	}
+
+	if err := s.routeStore.Put(denialDataKey(connectionID), bytes); err != nil {
+		return fmt.Errorf("save mediation denial : %w", err)
+	}
+
+	return nil
+}
+
+// checkDenial returns a *MediationDeniedError if the router on the other end of connectionID denied mediation and
+// its retry-after did not elapse.
+func (s *Service) checkDenial(connectionID string) error {
+	bytes, err := s.routeStore.Get(denialDataKey(connectionID))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetch mediation denial : %w", err)
+	}
+
+	d := &denial{}
+
+	if err := json.Unmarshal(bytes, d); err != nil {
+		return fmt.Errorf("unmarshal mediation denial : %w", err)
+	}
+
+	if now := s.now(); now.Before(d.RetryAt) {
+		return &MediationDeniedError{Reason: d.Reason, RetryAfter: d.RetryAt.Sub(now)}
+	}
+
+	return nil
+}
+
+func (s *Service) deleteDenial(connectionID string) {
+	if err := s.routeStore.Delete(denialDataKey(connectionID)); err != nil {
+		logger.Warnf("failed to delete the mediation denial of connection %s : %s", connectionID, err)
+	}
+}
+
+func retryAfter(deny *MediationDeny) time.Duration {
+	return time.Duration(deny.RetryAfter) * time.Second
+}
+
+func denialDataKey(connectionID string) string {
+	return routeDenialDataKey + connectionID
+}
@@ -0,0 +1,235 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+func TestMediationPolicy(t *testing.T) {
+	t.Run("test deny then grant - the policy is applied to every request", func(t *testing.T) {
+		allowed := false
+
+		var sent []interface{}
+
+		router, err := New(&mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{CreateSigningKeyValue: "routingKey"},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					require.Equal(t, MYDID, myDID)
+					require.Equal(t, THEIRDID, theirDID)
+
+					sent = append(sent, msg)
+
+					return nil
+				},
+			},
+		}, WithMediationPolicy(func(myDID, theirDID string) *MediationDeny {
+			if allowed {
+				return nil
+			}
+
+			return &MediationDeny{Reason: "quota exceeded", RetryAfter: 60}
+		}))
+		require.NoError(t, err)
+
+		requestID := randomID()
+		require.NoError(t, router.handleRequest(generateRequestMsgPayload(t, requestID), MYDID, THEIRDID))
+		require.Len(t, sent, 1)
+		require.Equal(t, &MediationDeny{
+			Type:       MediationDenyMsgType,
+			ID:         requestID,
+			Reason:     "quota exceeded",
+			RetryAfter: 60,
+		}, sent[0])
+
+		allowed = true
+
+		requestID = randomID()
+		require.NoError(t, router.handleRequest(generateRequestMsgPayload(t, requestID), MYDID, THEIRDID))
+		require.Len(t, sent, 2)
+
+		grant, ok := sent[1].(*Grant)
+		require.True(t, ok)
+		require.Equal(t, requestID, grant.ID)
+	})
+
+	t.Run("test routes are granted without policy", func(t *testing.T) {
+		var grant *Grant
+
+		router, err := New(&mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					grant = msg.(*Grant)
+
+					return nil
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, router.handleRequest(generateRequestMsgPayload(t, randomID()), MYDID, THEIRDID))
+		require.NotNil(t, grant)
+	})
+}
+
+func TestMediationDeny(t *testing.T) {
+	newAgent := func(t *testing.T, requests chan<- string, clock *fakeClock) *Service {
+		s := make(map[string][]byte)
+
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          &mockstore.MockStoreProvider{Store: &mockstore.MockStore{Store: s}},
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					requests <- msg.(*Request).ID
+
+					return nil
+				}}})
+		require.NoError(t, err)
+
+		svc.now = clock.Now
+
+		connBytes, err := json.Marshal(&connection.Record{
+			ConnectionID: "conn1", MyDID: MYDID, TheirDID: THEIRDID, State: "complete"})
+		require.NoError(t, err)
+		s["conn_conn1"] = connBytes
+
+		return svc
+	}
+
+	respond := func(t *testing.T, svc *Service, requests <-chan string, response interface{}) {
+		id := <-requests
+
+		switch r := response.(type) {
+		case *MediationDeny:
+			r.Type, r.ID = MediationDenyMsgType, id
+		case *Grant:
+			r.Type, r.ID = GrantMsgType, id
+		}
+
+		bytes, err := json.Marshal(response)
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(bytes)
+		require.NoError(t, err)
+
+		if msg.Type() == GrantMsgType {
+			require.NoError(t, svc.handleGrant(msg))
+		} else {
+			require.NoError(t, svc.handleMediationDeny(msg))
+		}
+	}
+
+	t.Run("test deny then grant - the retry-after is honored", func(t *testing.T) {
+		clock := &fakeClock{now: time.Now()}
+		requests := make(chan string, 1)
+		svc := newAgent(t, requests, clock)
+
+		go respond(t, svc, requests, &MediationDeny{Reason: "quota exceeded", RetryAfter: 60})
+
+		err := svc.Register("conn1")
+		require.True(t, errors.Is(err, ErrMediationDenied))
+		require.EqualError(t, err, "mediation denied : quota exceeded (retry after 1m0s)")
+
+		_, err = svc.GetConnection()
+		require.True(t, errors.Is(err, ErrRouterNotRegistered))
+
+		// within the retry-after, the route is not requested again
+		clock.Advance(20 * time.Second)
+
+		err = svc.Register("conn1")
+
+		var denied *MediationDeniedError
+		require.True(t, errors.As(err, &denied))
+		require.Equal(t, "quota exceeded", denied.Reason)
+		require.Equal(t, 40*time.Second, denied.RetryAfter)
+		require.Empty(t, requests)
+
+		// once the retry-after elapsed, the route is requested again and granted
+		clock.Advance(41 * time.Second)
+
+		go respond(t, svc, requests, &Grant{Endpoint: ENDPOINT})
+
+		require.NoError(t, svc.Register("conn1"))
+
+		conf, err := svc.Config()
+		require.NoError(t, err)
+		require.Equal(t, ENDPOINT, conf.Endpoint())
+
+		_, err = svc.routeStore.Get(denialDataKey("conn1"))
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test deny without retry-after - the route can be requested again", func(t *testing.T) {
+		clock := &fakeClock{now: time.Now()}
+		requests := make(chan string, 1)
+		svc := newAgent(t, requests, clock)
+
+		go respond(t, svc, requests, &MediationDeny{})
+
+		err := svc.Register("conn1")
+		require.EqualError(t, err, "mediation denied")
+
+		go respond(t, svc, requests, &Grant{Endpoint: ENDPOINT})
+
+		require.NoError(t, svc.Register("conn1"))
+	})
+
+	t.Run("test deny received after a restart", func(t *testing.T) {
+		clock := &fakeClock{now: time.Now()}
+		svc := newAgent(t, make(chan string), clock)
+
+		svc.savePendingGrant("request1", "conn1")
+
+		denyBytes, err := json.Marshal(&MediationDeny{Type: MediationDenyMsgType, ID: "request1", RetryAfter: 60})
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(denyBytes)
+		require.NoError(t, err)
+
+		require.NoError(t, svc.handleMediationDeny(msg))
+
+		_, err = svc.routeStore.Get(pendingGrantDataKey("request1"))
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		err = svc.checkDenial("conn1")
+		require.True(t, errors.Is(err, ErrMediationDenied))
+
+		// a denial nothing waits for is ignored
+		require.NoError(t, svc.handleMediationDeny(msg))
+	})
+
+	t.Run("test mediation deny message is accepted", func(t *testing.T) {
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+		})
+		require.NoError(t, err)
+
+		require.True(t, svc.Accept(MediationDenyMsgType))
+	})
+}
@@ -6,6 +6,8 @@ SPDX-License-Identifier: Apache-2.0
 
 package route
 
+import "time"
+
 // Request route request message.
 // https://github.com/hyperledger/aries-rfcs/tree/master/features/0211-route-coordination#route-request
 type Request struct {
@@ -20,6 +22,19 @@ type Grant struct {
 	ID          string   `json:"@id,omitempty"`
 	Endpoint    string   `json:"endpoint,omitempty"`
 	RoutingKeys []string `json:"routing_keys,omitempty"`
+	// LeaseExpires is set when the route is granted for a limited time, the route must be requested again
+	// before it expires.
+	LeaseExpires *time.Time `json:"lease_expires,omitempty"`
+}
+
+// MediationDeny mediation deny message, the router's response to a route request it denies.
+type MediationDeny struct {
+	Type   string `json:"@type,omitempty"`
+	ID     string `json:"@id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// RetryAfter is the number of seconds to wait before requesting the route again, zero if the router gives
+	// no hint.
+	RetryAfter int `json:"retry_after,omitempty"`
 }
 
 // KeylistUpdate route keylist update message.
@@ -34,6 +49,9 @@ type KeylistUpdate struct {
 type Update struct {
 	RecipientKey string `json:"recipient_key,omitempty"`
 	Action       string `json:"action,omitempty"`
+	// Scope of the added key, eg: the protocol it is used for, the key is global if it has no scope
+	// (see AddScopedKey).
+	Scope string `json:"scope,omitempty"`
 }
 
 // KeylistUpdateResponse route keylist update response message.
@@ -49,4 +67,5 @@ type UpdateResponse struct {
 	RecipientKey string `json:"recipient_key,omitempty"`
 	Action       string `json:"action,omitempty"`
 	Result       string `json:"result,omitempty"`
+	Scope        string `json:"scope,omitempty"`
 }
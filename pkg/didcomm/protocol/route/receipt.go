@@ -0,0 +1,62 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
+)
+
+// DeliveryReceipt is emitted by the router for each forward message it handles, to trace the path of the messages.
+type DeliveryReceipt struct {
+	// ForwardID is the @id of the forward message.
+	ForwardID string
+	// RecipientKey is the recipient key the message is forwarded to.
+	RecipientKey string
+	// Timestamp is the time the message was forwarded, or the forwarding failed.
+	Timestamp time.Time
+	// Err is the reason the message was not forwarded, nil if it was.
+	Err error
+}
+
+// Delivered returns true if the message was forwarded.
+func (r *DeliveryReceipt) Delivered() bool {
+	return r.Err == nil
+}
+
+// DeliveryReceiptSubscriber receives the delivery receipts emitted by the router (see WithDeliveryReceipts).
+type DeliveryReceiptSubscriber interface {
+	HandleDeliveryReceipt(receipt *DeliveryReceipt)
+}
+
+// WithDeliveryReceipts option makes the router emit a delivery receipt to subscribers for each forward message,
+// whether it is forwarded or not, eg: for debugging undelivered messages. The subscribers are called synchronously
+// by the router. By default, no receipt is emitted.
+func WithDeliveryReceipts(subscribers ...DeliveryReceiptSubscriber) ServiceOption {
+	return func(opts *Service) {
+		opts.receiptSubscribers = append(opts.receiptSubscribers, subscribers...)
+	}
+}
+
+// emitDeliveryReceipt emits the receipt of forward to the subscribers, err is the error forwarding it, if any.
+func (s *Service) emitDeliveryReceipt(forward *model.Forward, err error) {
+	if len(s.receiptSubscribers) == 0 {
+		return
+	}
+
+	receipt := &DeliveryReceipt{
+		ForwardID:    forward.ID,
+		RecipientKey: forward.To,
+		Timestamp:    s.now().UTC(),
+		Err:          err,
+	}
+
+	for _, subscriber := range s.receiptSubscribers {
+		subscriber.HandleDeliveryReceipt(receipt)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockdiddoc "github.com/hyperledger/aries-framework-go/pkg/mock/diddoc"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+)
+
+type receiptRecorder struct {
+	receipts []*DeliveryReceipt
+}
+
+func (r *receiptRecorder) HandleDeliveryReceipt(receipt *DeliveryReceipt) {
+	r.receipts = append(r.receipts, receipt)
+}
+
+type receiptChan chan *DeliveryReceipt
+
+func (c receiptChan) HandleDeliveryReceipt(receipt *DeliveryReceipt) {
+	c <- receipt
+}
+
+func TestDeliveryReceipts(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	newRouter := func(t *testing.T, forwardErr error, opts ...ServiceOption) *Service {
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateForward: func(msg interface{}, des *service.Destination) error {
+					return forwardErr
+				},
+			},
+			VDRIRegistryValue: &mockvdri.MockVDRIRegistry{
+				ResolveFunc: func(didID string, opts ...vdri.ResolveOpts) (*did.Doc, error) {
+					return mockdiddoc.GetMockDIDDoc(), nil
+				},
+			},
+		}, opts...)
+		require.NoError(t, err)
+
+		svc.now = func() time.Time { return now }
+
+		require.NoError(t, svc.routeStore.Put(dataKey("recipient-key"), []byte(THEIRDID)))
+
+		return svc
+	}
+
+	t.Run("test success receipt emitted for forwarded message", func(t *testing.T) {
+		receipts := make(receiptChan, 1)
+		svc := newRouter(t, nil, WithDeliveryReceipts(receipts))
+
+		forwardID := randomID()
+
+		id, err := svc.HandleInbound(generateForwardMsgPayload(t, forwardID, "recipient-key", nil), "", "")
+		require.NoError(t, err)
+		require.Equal(t, forwardID, id)
+
+		select {
+		case receipt := <-receipts:
+			require.Equal(t, &DeliveryReceipt{ForwardID: forwardID, RecipientKey: "recipient-key", Timestamp: now},
+				receipt)
+			require.True(t, receipt.Delivered())
+		case <-time.After(time.Second):
+			require.Fail(t, "no delivery receipt emitted")
+		}
+	})
+
+	t.Run("test failure receipts emitted for undelivered messages", func(t *testing.T) {
+		recorder := &receiptRecorder{}
+		svc := newRouter(t, errors.New("forward error"), WithDeliveryReceipts(recorder))
+
+		forwardID := randomID()
+
+		err := svc.handleForward(generateForwardMsgPayload(t, forwardID, "recipient-key", nil))
+		require.EqualError(t, err, "forward error")
+
+		err = svc.handleForward(generateForwardMsgPayload(t, randomID(), "unknown-key", nil))
+		require.Error(t, err)
+
+		require.Len(t, recorder.receipts, 2)
+		require.Equal(t, forwardID, recorder.receipts[0].ForwardID)
+		require.False(t, recorder.receipts[0].Delivered())
+		require.EqualError(t, recorder.receipts[0].Err, "forward error")
+		require.Equal(t, "unknown-key", recorder.receipts[1].RecipientKey)
+		require.Contains(t, recorder.receipts[1].Err.Error(), "route key fetch")
+	})
+
+	t.Run("test receipts emitted to all subscribers", func(t *testing.T) {
+		recorder1, recorder2 := &receiptRecorder{}, &receiptRecorder{}
+		svc := newRouter(t, nil, WithDeliveryReceipts(recorder1), WithDeliveryReceipts(recorder2))
+
+		require.NoError(t, svc.handleForward(generateForwardMsgPayload(t, randomID(), "recipient-key", nil)))
+		require.Len(t, recorder1.receipts, 1)
+		require.Equal(t, recorder1.receipts, recorder2.receipts)
+	})
+}
@@ -0,0 +1,110 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
+)
+
+// problem report code sent by the router when the routing keys it granted are stale
+const routingKeysRotatedCode = "routing_keys_rotated"
+
+// NotifyRoutingKeysRotated notifies the agent on the other end of connectionID, registered with this router, that
+// the routing keys previously granted are stale (eg: the router rotated its keys). The agent requests the route
+// again to get a grant with fresh routing keys and registers its recipient keys again.
+func (s *Service) NotifyRoutingKeysRotated(connectionID string) error {
+	conn, err := s.getConnection(connectionID)
+	if err != nil {
+		return err
+	}
+
+	report := &model.ProblemReport{
+		Type:        ProblemReportMsgType,
+		ID:          uuid.New().String(),
+		Description: model.Code{Code: routingKeysRotatedCode},
+	}
+
+	if err := s.outbound.SendToDID(report, conn.MyDID, conn.TheirDID); err != nil {
+		return fmt.Errorf("send routing keys rotated problem report : %w", err)
+	}
+
+	return nil
+}
+
+// refreshRoute requests the route again to the registered router, saving the grant with the updated routing keys,
+// and registers the recipient keys again with the router.
+func (s *Service) refreshRoute() error {
+	routerConnID, err := s.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	if err := s.requestGrant(routerConnID); err != nil {
+		return err
+	}
+
+	return s.reRegisterKeys(routerConnID)
+}
+
+// reRegisterKeys sends the recipient keys registered with the router of connectionID (see AddKey and AddScopedKey)
+// again, under their scope, with a single keylist update. This method blocks until a response is received from the
+// router or it times out.
+func (s *Service) reRegisterKeys(connectionID string) error {
+	keys, err := s.Keys(connectionID)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	conn, err := s.getConnection(connectionID)
+	if err != nil {
+		return err
+	}
+
+	keyUpdate := &KeylistUpdate{ID: uuid.New().String(), Type: KeylistUpdateMsgType}
+
+	// the keys are registered again under their scope
+	for _, recKey := range keys {
+		scope, err := s.registeredKeyScope(connectionID, recKey)
+		if err != nil {
+			return fmt.Errorf("get registered key scope : %w", err)
+		}
+
+		keyUpdate.Updates = append(keyUpdate.Updates, Update{RecipientKey: recKey, Action: add, Scope: scope})
+	}
+
+	keyUpdateCh := make(chan *KeylistUpdateResponse)
+	s.setKeyUpdateResponseCh(keyUpdate.ID, keyUpdateCh)
+
+	defer s.setKeyUpdateResponseCh(keyUpdate.ID, nil)
+
+	if err := s.outbound.SendToDID(keyUpdate, conn.MyDID, conn.TheirDID); err != nil {
+		return fmt.Errorf("send keylist update : %w", err)
+	}
+
+	select {
+	case keyUpdateResp := <-keyUpdateCh:
+		for _, recKey := range keys {
+			if err := processKeylistUpdateResp(recKey, keyUpdateResp); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case <-time.After(updateTimeout):
+		return errors.New("timeout waiting for keylist update response from the router")
+	}
+}
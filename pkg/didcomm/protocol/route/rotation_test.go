@@ -0,0 +1,237 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockdiddoc "github.com/hyperledger/aries-framework-go/pkg/mock/diddoc"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+func TestRoutingKeysRotated(t *testing.T) {
+	// the agent's DIDs are MYDID (agent) and THEIRDID (router), the router's DIDs are the other way around
+	newService := func(t *testing.T, connID, myDID, theirDID string, kms *mockkms.CloseableKMS,
+		outbound *mockdispatcher.MockOutbound) *Service {
+		prov := &mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      kms,
+			OutboundDispatcherValue:       outbound,
+			VDRIRegistryValue: &mockvdri.MockVDRIRegistry{
+				ResolveFunc: func(didID string, opts ...vdri.ResolveOpts) (*did.Doc, error) {
+					return mockdiddoc.GetMockDIDDoc(), nil
+				},
+			},
+		}
+
+		svc, err := New(prov)
+		require.NoError(t, err)
+
+		recorder, err := connection.NewRecorder(prov)
+		require.NoError(t, err)
+
+		require.NoError(t, recorder.SaveConnectionRecord(&connection.Record{
+			ConnectionID: connID, MyDID: myDID, TheirDID: theirDID, State: "completed"}))
+		// a connection with a peer other than the router
+		require.NoError(t, recorder.SaveConnectionRecord(&connection.Record{
+			ConnectionID: "peer-conn", MyDID: myDID, TheirDID: "did:example:peer", State: "completed"}))
+
+		return svc
+	}
+
+	// deliver sends the message to the inbound handler of the service on the other end
+	deliver := func(t *testing.T, to func() *Service) func(msg interface{}, myDID, theirDID string) error {
+		return func(msg interface{}, myDID, theirDID string) error {
+			bytes, err := json.Marshal(msg)
+			require.NoError(t, err)
+
+			didCommMsg, err := service.ParseDIDCommMsgMap(bytes)
+			require.NoError(t, err)
+
+			_, err = to().HandleInbound(didCommMsg, theirDID, myDID)
+
+			return err
+		}
+	}
+
+	t.Run("test agent re-requests the route and recovers forwarding", func(t *testing.T) {
+		var agent, router *Service
+
+		forwarded := make(chan struct{}, 1)
+		routerKMS := &mockkms.CloseableKMS{CreateSigningKeyValue: "routingKey1"}
+
+		router = newService(t, "agent-conn", THEIRDID, MYDID, routerKMS, &mockdispatcher.MockOutbound{
+			ValidateSendToDID: deliver(t, func() *Service { return agent }),
+			ValidateForward: func(msg interface{}, des *service.Destination) error {
+				forwarded <- struct{}{}
+
+				return nil
+			},
+		})
+		agent = newService(t, "router-conn", MYDID, THEIRDID, &mockkms.CloseableKMS{}, &mockdispatcher.MockOutbound{
+			ValidateSendToDID: deliver(t, func() *Service { return router }),
+		})
+
+		require.NoError(t, agent.Register("router-conn"))
+
+		recKey, scopedKey := randomID(), randomID()
+		require.NoError(t, agent.AddKey(recKey))
+		require.NoError(t, agent.AddScopedKey(scopedKey, "didexchange"))
+
+		conf, err := agent.Config()
+		require.NoError(t, err)
+		require.Equal(t, []string{"routingKey1"}, conf.Keys())
+
+		// the router rotates its keys, the routes registered with the old keys are lost
+		routerKMS.CreateSigningKeyValue = "routingKey2"
+
+		for _, k := range []string{recKey, scopedKey} {
+			require.NoError(t, router.routeStore.Delete(dataKey(k)))
+			require.NoError(t, router.routeStore.Delete(keylistDataKey(MYDID, k)))
+		}
+
+		forward := generateForwardMsgPayload(t, randomID(), recKey, nil)
+		require.Error(t, router.handleForward(forward))
+
+		require.NoError(t, router.NotifyRoutingKeysRotated("agent-conn"))
+
+		require.Eventually(t, func() bool {
+			conf, err := agent.Config()
+
+			return err == nil && len(conf.Keys()) == 1 && conf.Keys()[0] == "routingKey2"
+		}, time.Second, 10*time.Millisecond)
+
+		require.Eventually(t, func() bool {
+			return router.handleForward(forward) == nil
+		}, time.Second, 10*time.Millisecond)
+
+		select {
+		case <-forwarded:
+		case <-time.After(time.Second):
+			require.FailNow(t, "message not forwarded after the route refresh")
+		}
+
+		// the scoped key is registered again under its scope
+		scoped, err := router.Keylist(MYDID, "didexchange")
+		require.NoError(t, err)
+		require.Equal(t, []string{scopedKey}, scoped)
+	})
+
+	t.Run("test notify errors", func(t *testing.T) {
+		router := newService(t, "agent-conn", THEIRDID, MYDID, &mockkms.CloseableKMS{},
+			&mockdispatcher.MockOutbound{SendErr: errors.New("send error")})
+
+		err := router.NotifyRoutingKeysRotated("unknown")
+		require.True(t, errors.Is(err, ErrConnectionNotFound))
+
+		err = router.NotifyRoutingKeysRotated("agent-conn")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "send routing keys rotated problem report")
+	})
+
+	t.Run("test routing keys rotated problem report not sent by the router is dropped", func(t *testing.T) {
+		// refreshing the route would fail to send the route request
+		agent := newService(t, "router-conn", MYDID, THEIRDID, &mockkms.CloseableKMS{},
+			&mockdispatcher.MockOutbound{SendErr: errors.New("send error")})
+		require.NoError(t, agent.saveRouterConnectionID("router-conn"))
+
+		report, err := json.Marshal(&model.ProblemReport{
+			Type:        ProblemReportMsgType,
+			ID:          randomID(),
+			Description: model.Code{Code: routingKeysRotatedCode},
+		})
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(report)
+		require.NoError(t, err)
+
+		require.NoError(t, agent.handleProblemReport(msg, MYDID, "did:example:peer"))
+		require.NoError(t, agent.handleProblemReport(msg, MYDID, "did:example:unknown"))
+
+		// the report of the router refreshes the route
+		err = agent.handleProblemReport(msg, MYDID, THEIRDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "send route request")
+	})
+
+	t.Run("test problem report sender check error", func(t *testing.T) {
+		agent := newService(t, "router-conn", MYDID, THEIRDID, &mockkms.CloseableKMS{}, &mockdispatcher.MockOutbound{})
+		require.NoError(t, agent.saveRouterConnectionID("router-conn"))
+
+		lookup, err := connection.NewLookup(&mockprovider.Provider{
+			StorageProviderValue: mockstore.NewCustomMockStoreProvider(&mockstore.MockStore{
+				Store:  make(map[string][]byte),
+				ErrGet: errors.New("get error"),
+			}),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+		})
+		require.NoError(t, err)
+
+		agent.connectionLookup = lookup
+
+		report, err := json.Marshal(&model.ProblemReport{
+			Type:        ProblemReportMsgType,
+			ID:          randomID(),
+			Description: model.Code{Code: routingKeysRotatedCode},
+		})
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(report)
+		require.NoError(t, err)
+
+		err = agent.handleProblemReport(msg, MYDID, THEIRDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "check route problem report routing_keys_rotated sender")
+	})
+
+	t.Run("test refresh errors", func(t *testing.T) {
+		agent := newService(t, "router-conn", MYDID, THEIRDID, &mockkms.CloseableKMS{},
+			&mockdispatcher.MockOutbound{SendErr: errors.New("send error")})
+
+		report, err := json.Marshal(&model.ProblemReport{
+			Type:        ProblemReportMsgType,
+			ID:          randomID(),
+			Description: model.Code{Code: routingKeysRotatedCode},
+		})
+		require.NoError(t, err)
+
+		msg, err := service.ParseDIDCommMsgMap(report)
+		require.NoError(t, err)
+
+		// the route isn't refreshed without router
+		require.NoError(t, agent.handleProblemReport(msg, MYDID, THEIRDID))
+
+		require.NoError(t, agent.saveRouterConnectionID("router-conn"))
+
+		err = agent.handleProblemReport(msg, MYDID, THEIRDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "send route request")
+
+		require.NoError(t, agent.saveRegisteredKey("router-conn", randomID(), ""))
+
+		err = agent.reRegisterKeys("router-conn")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "send keylist update")
+
+		require.NoError(t, agent.reRegisterKeys("no-keys"))
+	})
+}
@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// data key prefix of the keylists of the agents registered with the router, the keys of the records are the DID of
+// the agent and the recipient key, their values the scope of the key
+const routeKeylistDataKey = "route-keylist-"
+
+// ErrScopeNotForwarded is returned by the router for forward messages to recipient keys registered under a scope it
+// does not forward (see WithForwardScopes).
+var ErrScopeNotForwarded = errors.New("recipient key scope not forwarded")
+
+// WithForwardScopes option makes the router forward only the messages to the recipient keys registered under one of
+// scopes (see AddScopedKey), eg: to dedicate a router to some protocols. The keys registered without scope are global,
+// the messages to these keys are always forwarded. By default, the messages to all the keys are forwarded.
+func WithForwardScopes(scopes ...string) ServiceOption {
+	return func(opts *Service) {
+		opts.forwardScopes = map[string]bool{}
+
+		for _, scope := range scopes {
+			opts.forwardScopes[scope] = true
+		}
+	}
+}
+
+// AddScopedKey adds a recKey of the agent to the registered router under scope, eg: the protocol the key is used
+// for, so the router can filter the messages it forwards (see WithForwardScopes). A key added without scope is global.
+// This method blocks until a response is received from the router or it times out.
+func (s *Service) AddScopedKey(recKey, scope string) error {
+	return s.addKey(recKey, scope)
+}
+
+// Keylist returns the recipient keys registered with this router by the agent theirDID under scope, or all its keys
+// if scope is empty.
+func (s *Service) Keylist(theirDID, scope string) ([]string, error) {
+	prefix := keylistDataKey(theirDID, "")
+
+	itr := s.routeStore.Iterator(prefix, prefix+"~")
+	defer itr.Release()
+
+	var keys []string
+
+	for itr.Next() {
+		if scope == "" || string(itr.Value()) == scope {
+			keys = append(keys, strings.TrimPrefix(string(itr.Key()), prefix))
+		}
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, fmt.Errorf("iterate keylist : %w", err)
+	}
+
+	return keys, nil
+}
+
+// checkForwardScope checks that the router forwards the messages to recKey, registered by theirDID.
+func (s *Service) checkForwardScope(recKey, theirDID string) error {
+	if s.forwardScopes == nil {
+		return nil
+	}
+
+	scope, err := s.routeStore.Get(keylistDataKey(theirDID, recKey))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		// the key was registered before the keylist was recorded, it is global
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("route key scope fetch : %w", err)
+	}
+
+	if len(scope) > 0 && !s.forwardScopes[string(scope)] {
+		return fmt.Errorf("%w : %s", ErrScopeNotForwarded, scope)
+	}
+
+	return nil
+}
+
+func keylistDataKey(theirDID, recKey string) string {
+	return routeKeylistDataKey + theirDID + "|" + recKey
+}
@@ -0,0 +1,159 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package route
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockdiddoc "github.com/hyperledger/aries-framework-go/pkg/mock/diddoc"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+)
+
+func TestScopedKeys(t *testing.T) {
+	t.Run("test keylist filtered by scope", func(t *testing.T) {
+		agent, router, routerStore := newAgentAndRouter(t)
+
+		require.NoError(t, agent.AddScopedKey("issuance-key1", "issuance"))
+		require.NoError(t, agent.AddScopedKey("issuance-key2", "issuance"))
+		require.NoError(t, agent.AddScopedKey("presentation-key", "presentation"))
+		require.NoError(t, agent.AddKey("global-key"))
+
+		require.Equal(t, MYDID, string(routerStore[dataKey("issuance-key1")]))
+		require.Equal(t, "issuance", string(routerStore[keylistDataKey(MYDID, "issuance-key1")]))
+
+		keys, err := router.Keylist(MYDID, "issuance")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"issuance-key1", "issuance-key2"}, keys)
+
+		keys, err = router.Keylist(MYDID, "presentation")
+		require.NoError(t, err)
+		require.Equal(t, []string{"presentation-key"}, keys)
+
+		keys, err = router.Keylist(MYDID, "")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"issuance-key1", "issuance-key2", "presentation-key", "global-key"}, keys)
+
+		keys, err = router.Keylist("otherDID", "")
+		require.NoError(t, err)
+		require.Empty(t, keys)
+
+		require.Equal(t, success, router.removeRouteKey("issuance-key1", MYDID))
+
+		keys, err = router.Keylist(MYDID, "issuance")
+		require.NoError(t, err)
+		require.Equal(t, []string{"issuance-key2"}, keys)
+	})
+
+	t.Run("test keylist update response carries the scope", func(t *testing.T) {
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					resp, ok := msg.(*KeylistUpdateResponse)
+					require.True(t, ok)
+					require.Len(t, resp.Updated, 1)
+					require.Equal(t, "issuance", resp.Updated[0].Scope)
+					require.Equal(t, success, resp.Updated[0].Result)
+
+					return nil
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		err = svc.handleKeylistUpdate(generateKeyUpdateListMsgPayload(t, randomID(), []Update{{
+			RecipientKey: "ABC",
+			Action:       add,
+			Scope:        "issuance",
+		}}), MYDID, THEIRDID)
+		require.NoError(t, err)
+	})
+
+	t.Run("test keylist iterator error", func(t *testing.T) {
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue: &mockstore.MockStoreProvider{Store: &mockstore.MockStore{
+				Store: make(map[string][]byte), ErrItr: errors.New("iterator error")}},
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue:       &mockdispatcher.MockOutbound{},
+		})
+		require.NoError(t, err)
+
+		_, err = svc.Keylist(MYDID, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "iterate keylist")
+	})
+}
+
+func TestForwardScopes(t *testing.T) {
+	newRouter := func(t *testing.T, opts ...ServiceOption) *Service {
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateForward: func(msg interface{}, des *service.Destination) error {
+					return nil
+				},
+			},
+			VDRIRegistryValue: &mockvdri.MockVDRIRegistry{
+				ResolveFunc: func(didID string, opts ...vdri.ResolveOpts) (*did.Doc, error) {
+					return mockdiddoc.GetMockDIDDoc(), nil
+				},
+			},
+		}, opts...)
+		require.NoError(t, err)
+
+		for recKey, scope := range map[string]string{
+			"issuance-key": "issuance", "presentation-key": "presentation", "global-key": "",
+		} {
+			require.Equal(t, success, svc.addRouteKey(recKey, scope, THEIRDID))
+		}
+
+		return svc
+	}
+
+	t.Run("test only the keys of the forwarded scopes and the global keys are forwarded", func(t *testing.T) {
+		svc := newRouter(t, WithForwardScopes("issuance"))
+
+		for _, to := range []string{"issuance-key", "global-key"} {
+			require.NoError(t, svc.handleForward(generateForwardMsgPayload(t, randomID(), to, nil)), to)
+		}
+
+		err := svc.handleForward(generateForwardMsgPayload(t, randomID(), "presentation-key", nil))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrScopeNotForwarded))
+		require.Contains(t, err.Error(), "presentation")
+	})
+
+	t.Run("test all the keys are forwarded by default", func(t *testing.T) {
+		svc := newRouter(t)
+
+		for _, to := range []string{"issuance-key", "presentation-key", "global-key"} {
+			require.NoError(t, svc.handleForward(generateForwardMsgPayload(t, randomID(), to, nil)), to)
+		}
+	})
+
+	t.Run("test keys registered without keylist record are global", func(t *testing.T) {
+		svc := newRouter(t, WithForwardScopes("issuance"))
+
+		require.NoError(t, svc.routeStore.Put(dataKey("legacy-key"), []byte(THEIRDID)))
+		require.NoError(t, svc.handleForward(generateForwardMsgPayload(t, randomID(), "legacy-key", nil)))
+	})
+}
@@ -47,6 +47,12 @@ const (
 
 	// KeyListUpdateResponseMsgType defines the route coordination key list update message response type.
 	KeylistUpdateResponseMsgType = CoordinationSpec + "keylist_update_response"
+
+	// ProblemReportMsgType defines the route coordination problem-report message type.
+	ProblemReportMsgType = CoordinationSpec + "problem-report"
+
+	// MediationDenyMsgType defines the route coordination mediation-deny message type.
+	MediationDenyMsgType = CoordinationSpec + "mediation-deny"
 )
 
 // constants for key list update processing
@@ -71,6 +77,9 @@ const (
 
 	// data key to store router config
 	routeConfigDataKey = "route-config"
+
+	// data key prefix to store the connection ID of the route request waiting for a grant
+	routePendingGrantDataKey = "route-pending-grant-"
 )
 
 const (
@@ -104,14 +113,32 @@ type Service struct {
 	endpoint                 string
 	kms                      legacykms.KeyManager
 	vdRegistry               vdri.Registry
-	routeRegistrationMap     map[string]chan Grant
+	routeRegistrationMap     map[string]chan routeResponse
 	routeRegistrationMapLock sync.RWMutex
 	keylistUpdateMap         map[string]chan *KeylistUpdateResponse
 	keylistUpdateMapLock     sync.RWMutex
+	keylistLock              sync.Mutex
+	didKeyRoutingKeys        bool
+	leaseDuration            time.Duration
+	now                      func() time.Time
+	forwardScopes            map[string]bool
+	receiptSubscribers       []DeliveryReceiptSubscriber
+	mediationPolicy          MediationPolicy
+}
+
+// ServiceOption configures the route coordination service.
+type ServiceOption func(opts *Service)
+
+// WithDIDKeyRoutingKeys option makes the router to send routing keys in the route grant as did:key identifiers
+// instead of raw base58 encoded public keys.
+func WithDIDKeyRoutingKeys() ServiceOption {
+	return func(opts *Service) {
+		opts.didKeyRoutingKeys = true
+	}
 }
 
 // New return route coordination service.
-func New(prov provider) (*Service, error) {
+func New(prov provider, opts ...ServiceOption) (*Service, error) {
 	store, err := prov.StorageProvider().OpenStore(Coordination)
 	if err != nil {
 		return nil, fmt.Errorf("open route coordination store : %w", err)
@@ -122,20 +149,27 @@ func New(prov provider) (*Service, error) {
 		return nil, err
 	}
 
-	return &Service{
+	svc := &Service{
 		routeStore:           store,
 		outbound:             prov.OutboundDispatcher(),
 		endpoint:             prov.RouterEndpoint(),
 		kms:                  prov.LegacyKMS(),
 		vdRegistry:           prov.VDRIRegistry(),
 		connectionLookup:     connectionLookup,
-		routeRegistrationMap: make(map[string]chan Grant),
+		routeRegistrationMap: make(map[string]chan routeResponse),
 		keylistUpdateMap:     make(map[string]chan *KeylistUpdateResponse),
-	}, nil
+		now:                  time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	return svc, nil
 }
 
 // HandleInbound handles inbound route coordination messages.
-func (s *Service) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string) (string, error) { // nolint gocyclo (5 switch cases)
+func (s *Service) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string) (string, error) { // nolint gocyclo (7 switch cases)
 	// perform action on inbound message asynchronously
 	go func() {
 		var err error
@@ -151,6 +185,10 @@ func (s *Service) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string)
 			err = s.handleKeylistUpdateResponse(msg)
 		case service.ForwardMsgType:
 			err = s.handleForward(msg)
+		case ProblemReportMsgType:
+			err = s.handleProblemReport(msg, myDID, theirDID)
+		case MediationDenyMsgType:
+			err = s.handleMediationDeny(msg)
 		}
 
 		connectionID, connErr := s.connectionLookup.GetConnectionIDByDIDs(myDID, theirDID)
@@ -182,7 +220,8 @@ func (s *Service) HandleOutbound(msg service.DIDCommMsg, myDID, theirDID string)
 // Accept checks whether the service can handle the message type.
 func (s *Service) Accept(msgType string) bool {
 	switch msgType {
-	case RequestMsgType, GrantMsgType, KeylistUpdateMsgType, KeylistUpdateResponseMsgType, service.ForwardMsgType:
+	case RequestMsgType, GrantMsgType, KeylistUpdateMsgType, KeylistUpdateResponseMsgType, service.ForwardMsgType,
+		ProblemReportMsgType, MediationDenyMsgType:
 		return true
 	}
 
@@ -203,8 +242,9 @@ func (s *Service) handleRequest(msg service.DIDCommMsg, myDID, theirDID string)
 		return fmt.Errorf("route request message unmarshal : %w", err)
 	}
 
-	// TODO https://github.com/hyperledger/aries-framework-go/issues/1133 Support to
-	//  add business logic for Route Request Approval
+	if deny := s.denyMediation(request.ID, myDID, theirDID); deny != nil {
+		return s.outbound.SendToDID(deny, myDID, theirDID)
+	}
 
 	// create keys
 	_, sigPubKey, err := s.kms.CreateKeySet()
@@ -212,12 +252,25 @@ func (s *Service) handleRequest(msg service.DIDCommMsg, myDID, theirDID string)
 		return fmt.Errorf("failed to create keys : %w", err)
 	}
 
+	if s.didKeyRoutingKeys {
+		sigPubKey, err = RoutingKeyToDIDKey(sigPubKey)
+		if err != nil {
+			return fmt.Errorf("convert routing key to did:key : %w", err)
+		}
+	}
+
+	leaseExpires, err := s.grantLease(myDID, theirDID)
+	if err != nil {
+		return err
+	}
+
 	// send the grant response
 	grant := &Grant{
-		Type:        GrantMsgType,
-		ID:          msg.ID(),
-		Endpoint:    s.endpoint,
-		RoutingKeys: []string{sigPubKey},
+		Type:         GrantMsgType,
+		ID:           msg.ID(),
+		Endpoint:     s.endpoint,
+		RoutingKeys:  []string{sigPubKey},
+		LeaseExpires: leaseExpires,
 	}
 
 	return s.outbound.SendToDID(grant, myDID, theirDID)
@@ -233,13 +286,44 @@ func (s *Service) handleGrant(msg service.DIDCommMsg) error {
 	}
 
 	// check if there are any channels registered for the message ID
-	grantCh := s.getRouteRegistrationCh(grantMsg.ID)
+	responseCh := s.getRouteRegistrationCh(grantMsg.ID)
 
-	if grantCh != nil {
+	if responseCh != nil {
 		// invoke the channel for the incoming message
-		grantCh <- *grantMsg
+		responseCh <- routeResponse{grant: grantMsg}
+
+		return nil
+	}
+
+	// nothing waits for the grant, the request might have been sent before a restart
+	return s.resumeRegistration(grantMsg)
+}
+
+// resumeRegistration completes the registration of the route request which was waiting for the grant when
+// the service was stopped.
+func (s *Service) resumeRegistration(grant *Grant) error {
+	connectionID, err := s.routeStore.Get(pendingGrantDataKey(grant.ID))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		logger.Debugf("no route request found for the grant : id=%s", grant.ID)
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetch pending route request : %w", err)
 	}
 
+	if err := s.saveGrant(grant); err != nil {
+		return err
+	}
+
+	if err := s.saveRouterConnectionID(string(connectionID)); err != nil {
+		return fmt.Errorf("save router connection id : %w", err)
+	}
+
+	s.deletePendingGrant(grant.ID)
+	s.deleteDenial(string(connectionID))
+
 	return nil
 }
 
@@ -252,38 +336,7 @@ func (s *Service) handleKeylistUpdate(msg service.DIDCommMsg, myDID, theirDID st
 		return fmt.Errorf("route key list update message unmarshal : %w", err)
 	}
 
-	var updates []UpdateResponse
-
-	// update the db
-	for _, v := range keyUpdate.Updates {
-		if v.Action == add {
-			val := theirDID
-			result := success
-
-			err = s.routeStore.Put(dataKey(v.RecipientKey), []byte(val))
-			if err != nil {
-				logger.Errorf("failed to add the route key to store : %s", err)
-
-				result = serverError
-			}
-
-			// construct the response doc
-			updates = append(updates, UpdateResponse{
-				RecipientKey: v.RecipientKey,
-				Action:       v.Action,
-				Result:       result,
-			})
-		} else if v.Action == remove {
-			// TODO remove from the store
-
-			// construct the response doc
-			updates = append(updates, UpdateResponse{
-				RecipientKey: v.RecipientKey,
-				Action:       v.Action,
-				Result:       serverError,
-			})
-		}
-	}
+	updates := s.updateKeylist(keyUpdate.Updates, theirDID)
 
 	// send the key update response
 	updateResponse := &KeylistUpdateResponse{
@@ -324,6 +377,13 @@ func (s *Service) handleForward(msg service.DIDCommMsg) error {
 		return fmt.Errorf("forward message unmarshal : %w", err)
 	}
 
+	err = s.forward(forward)
+	s.emitDeliveryReceipt(forward, err)
+
+	return err
+}
+
+func (s *Service) forward(forward *model.Forward) error {
 	// TODO Open question - https://github.com/hyperledger/aries-framework-go/issues/965 Mismatch between Route
 	//  Coordination and Forward RFC. For now assume, the TO field contains the recipient key.
 	theirDID, err := s.routeStore.Get(dataKey(forward.To))
@@ -331,6 +391,14 @@ func (s *Service) handleForward(msg service.DIDCommMsg) error {
 		return fmt.Errorf("route key fetch : %w", err)
 	}
 
+	if err := s.checkLease(string(theirDID)); err != nil {
+		return err
+	}
+
+	if err := s.checkForwardScope(forward.To, string(theirDID)); err != nil {
+		return err
+	}
+
 	dest, err := service.GetDestination(string(theirDID), s.vdRegistry)
 	if err != nil {
 		return fmt.Errorf("get destination : %w", err)
@@ -356,6 +424,23 @@ func (s *Service) Register(connectionID string) error {
 		return errors.New("router is already registered")
 	}
 
+	if err := s.requestGrant(connectionID); err != nil {
+		return err
+	}
+
+	// save the connectionID of the router
+	return s.saveRouterConnectionID(connectionID)
+}
+
+// requestGrant sends a route request to the router on the other end of the connection identified by connectionID
+// and saves the grant received in response. It blocks until the grant is received or it times out. If the router
+// denies mediation, a *MediationDeniedError is returned and the route is not requested again before the retry-after
+// of the router.
+func (s *Service) requestGrant(connectionID string) error {
+	if err := s.checkDenial(connectionID); err != nil {
+		return err
+	}
+
 	// get the connection record for the ID to fetch DID information
 	conn, err := s.getConnection(connectionID)
 	if err != nil {
@@ -366,8 +451,15 @@ func (s *Service) Register(connectionID string) error {
 	msgID := uuid.New().String()
 
 	// register chan for callback processing
-	grantCh := make(chan Grant)
-	s.setRouteRegistrationCh(msgID, grantCh)
+	responseCh := make(chan routeResponse)
+	s.setRouteRegistrationCh(msgID, responseCh)
+
+	// remove the channel once its been processed
+	defer s.setRouteRegistrationCh(msgID, nil)
+
+	// persist the request to complete the registration if the grant is received after a restart
+	s.savePendingGrant(msgID, connectionID)
+	defer s.deletePendingGrant(msgID)
 
 	// create request message
 	req := &Request{
@@ -382,25 +474,18 @@ func (s *Service) Register(connectionID string) error {
 
 	// callback processing (to make this function look like a sync function)
 	select {
-	case grantResp := <-grantCh:
-		conf := &config{
-			RouterEndpoint: grantResp.Endpoint,
-			RoutingKeys:    grantResp.RoutingKeys,
+	case resp := <-responseCh:
+		if resp.deny != nil {
+			return s.denied(connectionID, resp.deny)
 		}
 
-		if err := s.saveRouterConfig(conf); err != nil {
-			return fmt.Errorf("save route config : %w", err)
-		}
+		s.deleteDenial(connectionID)
+
+		return s.saveGrant(resp.grant)
 	// TODO https://github.com/hyperledger/aries-framework-go/issues/1134 configure this timeout at decorator level
 	case <-time.After(updateTimeout):
 		return errors.New("timeout waiting for grant from the router")
 	}
-
-	// remove the channel once its been processed
-	s.setRouteRegistrationCh(msgID, nil)
-
-	// save the connectionID of the router
-	return s.saveRouterConnectionID(connectionID)
 }
 
 // Unregister unregisters the agent with the router.
@@ -438,6 +523,10 @@ func (s *Service) GetConnection() (string, error) {
 // TODO https://github.com/hyperledger/aries-framework-go/issues/1105 Support to Add multiple
 //  recKeys to the Router
 func (s *Service) AddKey(recKey string) error {
+	return s.addKey(recKey, "")
+}
+
+func (s *Service) addKey(recKey, scope string) error {
 	// check if router is already registered
 	routerConnID, err := s.getRouterConnectionID()
 	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
@@ -468,6 +557,7 @@ func (s *Service) AddKey(recKey string) error {
 			{
 				RecipientKey: recKey,
 				Action:       add,
+				Scope:        scope,
 			},
 		},
 	}
@@ -481,6 +571,10 @@ func (s *Service) AddKey(recKey string) error {
 		if err := processKeylistUpdateResp(recKey, keyUpdateResp); err != nil {
 			return err
 		}
+
+		if err := s.saveRegisteredKey(routerConnID, recKey, scope); err != nil {
+			return fmt.Errorf("save registered key : %w", err)
+		}
 	// TODO https://github.com/hyperledger/aries-framework-go/issues/1134 configure this timeout at decorator level
 	case <-time.After(updateTimeout):
 		return errors.New("timeout waiting for keylist update response from the router")
@@ -515,21 +609,21 @@ func processKeylistUpdateResp(recKey string, keyUpdateResp *KeylistUpdateRespons
 	return nil
 }
 
-func (s *Service) getRouteRegistrationCh(msgID string) chan Grant {
+func (s *Service) getRouteRegistrationCh(msgID string) chan routeResponse {
 	s.routeRegistrationMapLock.RLock()
 	defer s.routeRegistrationMapLock.RUnlock()
 
 	return s.routeRegistrationMap[msgID]
 }
 
-func (s *Service) setRouteRegistrationCh(msgID string, grantCh chan Grant) {
+func (s *Service) setRouteRegistrationCh(msgID string, responseCh chan routeResponse) {
 	s.routeRegistrationMapLock.Lock()
 	defer s.routeRegistrationMapLock.Unlock()
 
-	if grantCh == nil {
+	if responseCh == nil {
 		delete(s.routeRegistrationMap, msgID)
 	} else {
-		s.routeRegistrationMap[msgID] = grantCh
+		s.routeRegistrationMap[msgID] = responseCh
 	}
 }
 
@@ -551,6 +645,22 @@ func (s *Service) setKeyUpdateResponseCh(msgID string, keyUpdateCh chan *Keylist
 	}
 }
 
+func (s *Service) savePendingGrant(msgID, connectionID string) {
+	if err := s.routeStore.Put(pendingGrantDataKey(msgID), []byte(connectionID)); err != nil {
+		logger.Warnf("route request %s can't be resumed after a restart : %s", msgID, err)
+	}
+}
+
+func (s *Service) deletePendingGrant(msgID string) {
+	if err := s.routeStore.Delete(pendingGrantDataKey(msgID)); err != nil {
+		logger.Warnf("failed to delete pending route request %s : %s", msgID, err)
+	}
+}
+
+func pendingGrantDataKey(msgID string) string {
+	return routePendingGrantDataKey + msgID
+}
+
 func (s *Service) getRouterConnectionID() (string, error) {
 	id, err := s.routeStore.Get(routeConnIDDataKey)
 	if err != nil {
@@ -567,6 +677,7 @@ func (s *Service) saveRouterConnectionID(id string) error {
 type config struct {
 	RouterEndpoint string
 	RoutingKeys    []string
+	LeaseExpires   *time.Time `json:",omitempty"`
 }
 
 func (s *Service) getRouterConfig() (*Config, error) {
@@ -582,7 +693,33 @@ func (s *Service) getRouterConfig() (*Config, error) {
 		return nil, fmt.Errorf("unmarshal router config data : %w", err)
 	}
 
-	return NewConfig(conf.RouterEndpoint, conf.RoutingKeys), nil
+	routerConfig := NewConfig(conf.RouterEndpoint, conf.RoutingKeys)
+
+	if conf.LeaseExpires != nil {
+		routerConfig.leaseExpires = *conf.LeaseExpires
+	}
+
+	return routerConfig, nil
+}
+
+func (s *Service) saveGrant(grant *Grant) error {
+	// routing keys can be either raw keys or did:key identifiers, keep the raw form for packing
+	routingKeys, err := ResolveRoutingKeys(grant.RoutingKeys)
+	if err != nil {
+		return fmt.Errorf("resolve routing keys : %w", err)
+	}
+
+	conf := &config{
+		RouterEndpoint: grant.Endpoint,
+		RoutingKeys:    routingKeys,
+		LeaseExpires:   grant.LeaseExpires,
+	}
+
+	if err := s.saveRouterConfig(conf); err != nil {
+		return fmt.Errorf("save route config : %w", err)
+	}
+
+	return nil
 }
 
 func (s *Service) saveRouterConfig(conf *config) error {
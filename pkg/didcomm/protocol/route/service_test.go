@@ -7,11 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package route
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
@@ -25,6 +28,7 @@ import (
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
 	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
 )
 
@@ -67,6 +71,7 @@ func TestServiceAccept(t *testing.T) {
 	require.Equal(t, true, s.Accept(KeylistUpdateMsgType))
 	require.Equal(t, true, s.Accept(KeylistUpdateResponseMsgType))
 	require.Equal(t, true, s.Accept(service.ForwardMsgType))
+	require.Equal(t, true, s.Accept(ProblemReportMsgType))
 	require.Equal(t, false, s.Accept("unsupported msg type"))
 }
 
@@ -161,6 +166,47 @@ func TestServiceRequestMsg(t *testing.T) {
 		err = svc.handleRequest(generateRequestMsgPayload(t, msgID), MYDID, THEIRDID)
 		require.NoError(t, err)
 	})
+	t.Run("test service handle request msg - routing keys as did:key", func(t *testing.T) {
+		routingKey := base58.Encode(make([]byte, ed25519.PublicKeySize))
+
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{CreateSigningKeyValue: routingKey},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					grant, ok := msg.(*Grant)
+					require.True(t, ok)
+					require.Len(t, grant.RoutingKeys, 1)
+					require.True(t, strings.HasPrefix(grant.RoutingKeys[0], "did:key:"))
+
+					key, err := ResolveRoutingKey(grant.RoutingKeys[0])
+					require.NoError(t, err)
+					require.Equal(t, routingKey, key)
+
+					return nil
+				},
+			},
+		}, WithDIDKeyRoutingKeys())
+		require.NoError(t, err)
+
+		err = svc.handleRequest(generateRequestMsgPayload(t, randomID()), MYDID, THEIRDID)
+		require.NoError(t, err)
+	})
+
+	t.Run("test service handle request msg - invalid routing key for did:key", func(t *testing.T) {
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          mockstore.NewMockStoreProvider(),
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{CreateSigningKeyValue: "invalid"},
+			OutboundDispatcherValue:       &mockdispatcher.MockOutbound{},
+		}, WithDIDKeyRoutingKeys())
+		require.NoError(t, err)
+
+		err = svc.handleRequest(generateRequestMsgPayload(t, randomID()), MYDID, THEIRDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "convert routing key to did:key")
+	})
 }
 
 func TestServiceGrantMsg(t *testing.T) {
@@ -232,7 +278,7 @@ func TestServiceUpdateKeyListMsg(t *testing.T) {
 	t.Run("test service handle request msg - verify outbound message", func(t *testing.T) {
 		update := make(map[string]updateResult)
 		update["ABC"] = updateResult{action: add, result: success}
-		update["XYZ"] = updateResult{action: remove, result: serverError}
+		update["XYZ"] = updateResult{action: remove, result: noChange}
 		update[""] = updateResult{action: add, result: success}
 
 		svc, err := New(&mockprovider.Provider{
@@ -492,6 +538,137 @@ func TestRegister(t *testing.T) {
 		require.Contains(t, err.Error(), "save route config")
 	})
 
+	t.Run("test register route - did:key routing keys", func(t *testing.T) {
+		msgID := make(chan string)
+		routingKey := base58.Encode(make([]byte, ed25519.PublicKeySize))
+
+		didKey, err := RoutingKeyToDIDKey(routingKey)
+		require.NoError(t, err)
+
+		s := make(map[string][]byte)
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          &mockstore.MockStoreProvider{Store: &mockstore.MockStore{Store: s}},
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					request, ok := msg.(*Request)
+					require.True(t, ok)
+
+					msgID <- request.ID
+					return nil
+				}}})
+		require.NoError(t, err)
+
+		connRec := &connection.Record{
+			ConnectionID: "conn1", MyDID: MYDID, TheirDID: THEIRDID, State: "complete"}
+		connBytes, err := json.Marshal(connRec)
+		require.NoError(t, err)
+		s["conn_conn1"] = connBytes
+
+		go func() {
+			id := <-msgID
+			require.NoError(t, svc.handleGrant(generateGrantMsgPayloadWithKeys(t, id, didKey)))
+		}()
+
+		err = svc.Register("conn1")
+		require.NoError(t, err)
+
+		conf, err := svc.Config()
+		require.NoError(t, err)
+		require.Equal(t, []string{routingKey}, conf.Keys())
+	})
+
+	t.Run("test register route - invalid did:key routing keys", func(t *testing.T) {
+		msgID := make(chan string)
+
+		s := make(map[string][]byte)
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:          &mockstore.MockStoreProvider{Store: &mockstore.MockStore{Store: s}},
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					request, ok := msg.(*Request)
+					require.True(t, ok)
+
+					msgID <- request.ID
+					return nil
+				}}})
+		require.NoError(t, err)
+
+		connRec := &connection.Record{
+			ConnectionID: "conn1", MyDID: MYDID, TheirDID: THEIRDID, State: "complete"}
+		connBytes, err := json.Marshal(connRec)
+		require.NoError(t, err)
+		s["conn_conn1"] = connBytes
+
+		go func() {
+			id := <-msgID
+			require.NoError(t, svc.handleGrant(generateGrantMsgPayloadWithKeys(t, id, "did:key:invalid")))
+		}()
+
+		err = svc.Register("conn1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolve routing keys")
+	})
+
+	t.Run("test register route - grant received after restart", func(t *testing.T) {
+		msgID := make(chan string)
+
+		s := make(map[string][]byte)
+		prov := &mockprovider.Provider{
+			StorageProviderValue:          &mockstore.MockStoreProvider{Store: &mockstore.MockStore{Store: s}},
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+			KMSValue:                      &mockkms.CloseableKMS{},
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					request, ok := msg.(*Request)
+					require.True(t, ok)
+
+					msgID <- request.ID
+					return nil
+				}}}
+
+		svc, err := New(prov)
+		require.NoError(t, err)
+
+		connRec := &connection.Record{
+			ConnectionID: "conn1", MyDID: MYDID, TheirDID: THEIRDID, State: "complete"}
+		connBytes, err := json.Marshal(connRec)
+		require.NoError(t, err)
+		s["conn_conn1"] = connBytes
+
+		// the agent stops while waiting for the grant
+		go func() {
+			_ = svc.Register("conn1") // nolint: errcheck
+		}()
+
+		id := <-msgID
+
+		// the agent is restarted and receives the grant
+		restarted, err := New(prov)
+		require.NoError(t, err)
+
+		pendingConnID, err := restarted.routeStore.Get(pendingGrantDataKey(id))
+		require.NoError(t, err)
+		require.Equal(t, "conn1", string(pendingConnID))
+
+		routingKey := base58.Encode([]byte("routing-key"))
+		require.NoError(t, restarted.handleGrant(generateGrantMsgPayloadWithKeys(t, id, routingKey)))
+
+		connID, err := restarted.GetConnection()
+		require.NoError(t, err)
+		require.Equal(t, "conn1", connID)
+
+		conf, err := restarted.Config()
+		require.NoError(t, err)
+		require.Equal(t, []string{routingKey}, conf.Keys())
+
+		_, err = restarted.routeStore.Get(pendingGrantDataKey(id))
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
 	t.Run("test register route - timeout error", func(t *testing.T) {
 		s := make(map[string][]byte)
 		svc, err := New(&mockprovider.Provider{
@@ -510,6 +687,10 @@ func TestRegister(t *testing.T) {
 		err = svc.Register("conn2")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "timeout waiting for grant from the router")
+
+		for k := range s {
+			require.NotContains(t, k, routePendingGrantDataKey)
+		}
 	})
 
 	t.Run("test register route - router connection not found", func(t *testing.T) {
@@ -748,6 +929,141 @@ func TestKeylistUpdate(t *testing.T) {
 	})
 }
 
+// newAgentAndRouter returns an agent registered with a router, the keylist messages are delivered
+// to the other service.
+func newAgentAndRouter(t *testing.T) (*Service, *Service, map[string][]byte) {
+	var agent, router *Service
+
+	routerStore := make(map[string][]byte)
+
+	router, err := New(&mockprovider.Provider{
+		StorageProviderValue:          &mockstore.MockStoreProvider{Store: &mockstore.MockStore{Store: routerStore}},
+		TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+		KMSValue:                      &mockkms.CloseableKMS{},
+		OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+			ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+				resp, ok := msg.(*KeylistUpdateResponse)
+				require.True(t, ok)
+
+				go func() {
+					require.NoError(t, agent.handleKeylistUpdateResponse(
+						generateKeylistUpdateResponseMsgPayload(t, resp.ID, resp.Updated)))
+				}()
+
+				return nil
+			}}})
+	require.NoError(t, err)
+
+	agentStore := make(map[string][]byte)
+
+	agent, err = New(&mockprovider.Provider{
+		StorageProviderValue:          &mockstore.MockStoreProvider{Store: &mockstore.MockStore{Store: agentStore}},
+		TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+		KMSValue:                      &mockkms.CloseableKMS{},
+		OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+			ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+				update, ok := msg.(*KeylistUpdate)
+				require.True(t, ok)
+
+				return router.handleKeylistUpdate(
+					generateKeyUpdateListMsgPayload(t, update.ID, update.Updates), theirDID, myDID)
+			}}})
+	require.NoError(t, err)
+
+	connBytes, err := json.Marshal(&connection.Record{
+		ConnectionID: "conn1", MyDID: MYDID, TheirDID: THEIRDID, State: "complete"})
+	require.NoError(t, err)
+
+	agentStore["conn_conn1"] = connBytes
+	require.NoError(t, agent.saveRouterConnectionID("conn1"))
+
+	return agent, router, routerStore
+}
+
+func TestRemoveKeys(t *testing.T) {
+	t.Run("test remove keys - bulk revoke registered keys", func(t *testing.T) {
+		agent, router, routerStore := newAgentAndRouter(t)
+
+		recKeys := []string{"key1", "key2", "key3"}
+
+		for _, recKey := range recKeys {
+			require.NoError(t, agent.AddKey(recKey))
+			require.Equal(t, MYDID, string(routerStore[dataKey(recKey)]))
+		}
+
+		// a key of another agent is not removed
+		require.Equal(t, success, router.addRouteKey("other", "", "otherDID"))
+
+		keys, err := agent.Keys("conn1")
+		require.NoError(t, err)
+		require.ElementsMatch(t, recKeys, keys)
+
+		require.NoError(t, agent.RemoveKeys("conn1"))
+
+		keys, err = agent.Keys("conn1")
+		require.NoError(t, err)
+		require.Empty(t, keys)
+
+		for _, recKey := range recKeys {
+			require.NotContains(t, routerStore, dataKey(recKey))
+		}
+
+		require.Contains(t, routerStore, dataKey("other"))
+	})
+
+	t.Run("test remove keys - empty keylist", func(t *testing.T) {
+		agent, _, _ := newAgentAndRouter(t)
+
+		require.NoError(t, agent.RemoveKeys("conn1"))
+
+		keys, err := agent.Keys("conn1")
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+
+	t.Run("test remove keys - keys already removed by the router", func(t *testing.T) {
+		agent, _, routerStore := newAgentAndRouter(t)
+
+		require.NoError(t, agent.AddKey("key1"))
+		delete(routerStore, dataKey("key1"))
+
+		require.NoError(t, agent.RemoveKeys("conn1"))
+
+		keys, err := agent.Keys("conn1")
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+
+	t.Run("test remove keys - router rejects the removal", func(t *testing.T) {
+		agent, router, _ := newAgentAndRouter(t)
+
+		require.NoError(t, agent.AddKey("key1"))
+		require.Equal(t, success, router.addRouteKey("key1", "", "otherDID"))
+
+		err := agent.RemoveKeys("conn1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to remove the recipient keys [key1] from the router")
+
+		keys, err := agent.Keys("conn1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"key1"}, keys)
+	})
+
+	t.Run("test remove keys - connection not found", func(t *testing.T) {
+		agent, _, _ := newAgentAndRouter(t)
+
+		require.NoError(t, agent.AddKey("key1"))
+
+		err := agent.RemoveKeys("conn2")
+		require.NoError(t, err)
+
+		require.NoError(t, agent.saveRegisteredKey("conn2", "key1", ""))
+
+		err = agent.RemoveKeys("conn2")
+		require.True(t, errors.Is(err, ErrConnectionNotFound))
+	})
+}
+
 func TestConfig(t *testing.T) {
 	var routingKeys = []string{"abc", "xyz"}
 
@@ -932,9 +1248,14 @@ func generateRequestMsgPayload(t *testing.T, id string) service.DIDCommMsg {
 }
 
 func generateGrantMsgPayload(t *testing.T, id string) service.DIDCommMsg {
+	return generateGrantMsgPayloadWithKeys(t, id)
+}
+
+func generateGrantMsgPayloadWithKeys(t *testing.T, id string, routingKeys ...string) service.DIDCommMsg {
 	grantBytes, err := json.Marshal(&Grant{
-		Type: GrantMsgType,
-		ID:   id,
+		Type:        GrantMsgType,
+		ID:          id,
+		RoutingKeys: routingKeys,
 	})
 	require.NoError(t, err)
 
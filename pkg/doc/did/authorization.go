@@ -0,0 +1,101 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// VerificationRelationship is the relationship between a DID subject and a verification method.
+type VerificationRelationship int
+
+const (
+	// Authentication relationship: the verification method is used to authenticate as the DID subject.
+	Authentication VerificationRelationship = iota
+	// CapabilityInvocation relationship: the verification method is used to invoke capabilities as the DID subject.
+	CapabilityInvocation
+)
+
+// String returns the DID document property name of the verification relationship.
+func (r VerificationRelationship) String() string {
+	switch r {
+	case Authentication:
+		return "authentication"
+	case CapabilityInvocation:
+		return "capabilityInvocation"
+	default:
+		return fmt.Sprintf("VerificationRelationship(%d)", int(r))
+	}
+}
+
+// ErrNotAuthorized is returned when a verification method is not authorized for a relationship.
+var ErrNotAuthorized = errors.New("verification method not authorized")
+
+// ResolveFunc resolves the DID document of the given DID.
+type ResolveFunc func(did string) (*Doc, error)
+
+// AuthorizeVerificationMethod checks that the verification method with the given id is authorized for the relationship
+// on the given DID document. If the controller of the verification method is not the DID subject, the controller DID
+// is resolved and it must hold the same verification method, following its own controller references.
+func AuthorizeVerificationMethod(doc *Doc, methodID string, rel VerificationRelationship, resolve ResolveFunc) error {
+	methods, err := doc.verificationMethods(rel)
+	if err != nil {
+		return err
+	}
+
+	for i := range methods {
+		if methods[i].PublicKey.ID == methodID {
+			return authorizeController(doc.ID, &methods[i].PublicKey, resolve, map[string]bool{doc.ID: true})
+		}
+	}
+
+	return fmt.Errorf("%w: %s is not a verification method of %s for %s", ErrNotAuthorized, methodID, doc.ID, rel)
+}
+
+func (doc *Doc) verificationMethods(rel VerificationRelationship) ([]VerificationMethod, error) {
+	switch rel {
+	case Authentication:
+		return doc.Authentication, nil
+	case CapabilityInvocation:
+		return doc.CapabilityInvocation, nil
+	default:
+		return nil, fmt.Errorf("unsupported verification relationship: %d", rel)
+	}
+}
+
+func authorizeController(subject string, pk *PublicKey, resolve ResolveFunc, visited map[string]bool) error {
+	controller := pk.Controller
+
+	// a relative controller (or none) refers to the DID subject
+	if controller == "" || controller == subject || strings.HasPrefix(controller, "#") {
+		return nil
+	}
+
+	if visited[controller] {
+		return fmt.Errorf("%w: controller cycle detected at %s", ErrNotAuthorized, controller)
+	}
+
+	visited[controller] = true
+
+	if resolve == nil {
+		return fmt.Errorf("%w: no resolver to resolve controller %s of %s", ErrNotAuthorized, controller, pk.ID)
+	}
+
+	controllerDoc, err := resolve(controller)
+	if err != nil {
+		return fmt.Errorf("resolve controller %s of %s : %w", controller, pk.ID, err)
+	}
+
+	controllerKey, ok := LookupPublicKey(pk.ID, controllerDoc)
+	if !ok || !bytes.Equal(controllerKey.Value, pk.Value) {
+		return fmt.Errorf("%w: controller %s does not hold %s", ErrNotAuthorized, controller, pk.ID)
+	}
+
+	return authorizeController(controllerDoc.ID, controllerKey, resolve, visited)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+)
+
+const (
+	subjectDID    = "did:example:subject"
+	controllerDID = "did:example:controller"
+	delegatedKey  = controllerDID + "#key-1"
+)
+
+func TestAuthorizeVerificationMethod(t *testing.T) {
+	delegated := PublicKey{
+		ID:         delegatedKey,
+		Type:       "Ed25519VerificationKey2018",
+		Controller: controllerDID,
+		Value:      []byte("delegated-key"),
+	}
+
+	subjectDoc := &Doc{
+		ID: subjectDID,
+		Authentication: []VerificationMethod{
+			{PublicKey: PublicKey{ID: subjectDID + "#key-1", Controller: subjectDID, Value: []byte("subject-key")}},
+			{PublicKey: delegated},
+		},
+	}
+
+	resolver := func(docs ...*Doc) ResolveFunc {
+		return func(did string) (*Doc, error) {
+			for _, doc := range docs {
+				if doc.ID == did {
+					return doc, nil
+				}
+			}
+
+			return nil, errors.New("DID not found")
+		}
+	}
+
+	t.Run("test method controlled by the subject is authorized", func(t *testing.T) {
+		err := AuthorizeVerificationMethod(subjectDoc, subjectDID+"#key-1", Authentication, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("test method controlled by another DID holding the key is authorized", func(t *testing.T) {
+		controllerDoc := &Doc{ID: controllerDID, PublicKey: []PublicKey{delegated}}
+
+		err := AuthorizeVerificationMethod(subjectDoc, delegatedKey, Authentication, resolver(controllerDoc))
+		require.NoError(t, err)
+	})
+
+	t.Run("test method controlled by another DID not holding the key is not authorized", func(t *testing.T) {
+		other := delegated
+		other.Value = []byte("other-key")
+		controllerDoc := &Doc{ID: controllerDID, PublicKey: []PublicKey{other}}
+
+		err := AuthorizeVerificationMethod(subjectDoc, delegatedKey, Authentication, resolver(controllerDoc))
+		require.True(t, errors.Is(err, ErrNotAuthorized))
+		require.Contains(t, err.Error(), "does not hold")
+
+		err = AuthorizeVerificationMethod(subjectDoc, delegatedKey, Authentication, resolver(&Doc{ID: controllerDID}))
+		require.True(t, errors.Is(err, ErrNotAuthorized))
+	})
+
+	t.Run("test controller references are followed", func(t *testing.T) {
+		const rootDID = "did:example:root"
+
+		chained := delegated
+		chained.Controller = rootDID
+
+		controllerDoc := &Doc{ID: controllerDID, PublicKey: []PublicKey{chained}}
+
+		err := AuthorizeVerificationMethod(subjectDoc, delegatedKey, Authentication, resolver(controllerDoc))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolve controller "+rootDID)
+
+		rootDoc := &Doc{ID: rootDID, PublicKey: []PublicKey{chained}}
+
+		err = AuthorizeVerificationMethod(subjectDoc, delegatedKey, Authentication, resolver(controllerDoc, rootDoc))
+		require.NoError(t, err)
+	})
+
+	t.Run("test controller cycle is not authorized", func(t *testing.T) {
+		cyclic := delegated
+		cyclic.Controller = subjectDID
+
+		// the controller document claims the key is controlled by the subject which is not the key holder
+		controllerDoc := &Doc{ID: controllerDID, PublicKey: []PublicKey{cyclic}}
+		subjectAsController := &Doc{ID: subjectDID, PublicKey: []PublicKey{cyclic}}
+
+		err := AuthorizeVerificationMethod(subjectDoc, delegatedKey, Authentication,
+			resolver(controllerDoc, subjectAsController))
+		require.True(t, errors.Is(err, ErrNotAuthorized))
+		require.Contains(t, err.Error(), "cycle")
+	})
+
+	t.Run("test method not in relationship is not authorized", func(t *testing.T) {
+		doc := &Doc{ID: subjectDID, PublicKey: []PublicKey{delegated}}
+
+		err := AuthorizeVerificationMethod(doc, delegatedKey, Authentication, resolver())
+		require.True(t, errors.Is(err, ErrNotAuthorized))
+	})
+
+	t.Run("test no resolver for delegated method", func(t *testing.T) {
+		err := AuthorizeVerificationMethod(subjectDoc, delegatedKey, Authentication, nil)
+		require.True(t, errors.Is(err, ErrNotAuthorized))
+		require.Contains(t, err.Error(), "no resolver")
+	})
+
+	t.Run("test unsupported relationship", func(t *testing.T) {
+		err := AuthorizeVerificationMethod(subjectDoc, delegatedKey, VerificationRelationship(-1), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported verification relationship")
+	})
+}
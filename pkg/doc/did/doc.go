@@ -34,6 +34,8 @@ const (
 	jsonldServicePoint  = "serviceEndpoint"
 	jsonldRecipientKeys = "recipientKeys"
 	jsonldRoutingKeys   = "routingKeys"
+	jsonldURI           = "uri"
+	jsonldAccept        = "accept"
 	jsonldPriority      = "priority"
 	jsonldController    = "controller"
 	jsonldOwner         = "owner"
@@ -44,6 +46,10 @@ const (
 	jsonldSignatureValue = "signatureValue"
 	jsonldDomain         = "domain"
 	jsonldNonce          = "nonce"
+	jsonldProofPurpose   = "proofPurpose"
+	jsonldJWS            = "jws"
+
+	jsonldVerificationMethod = "verificationMethod"
 
 	// various public key encodings
 	jsonldPublicKeyBase58 = "publicKeyBase58"
@@ -102,9 +108,14 @@ type Doc struct {
 	PublicKey      []PublicKey
 	Service        []Service
 	Authentication []VerificationMethod
-	Created        *time.Time
-	Updated        *time.Time
-	Proof          []Proof
+	// CapabilityInvocation are the verification methods used to invoke capabilities as the DID subject
+	// (omitted when empty, to keep the hash of the documents without it, e.g. peer DIDs, unchanged)
+	CapabilityInvocation []VerificationMethod `json:",omitempty"`
+	// KeyAgreement are the keys used to establish encrypted communication with the DID subject, e.g. X25519 keys
+	KeyAgreement []VerificationMethod `json:",omitempty"`
+	Created      *time.Time
+	Updated      *time.Time
+	Proof        []Proof
 }
 
 // PublicKey DID doc public key
@@ -118,6 +129,11 @@ type PublicKey struct {
 	jsonWebKey *jose.JWK
 }
 
+// JSONWebKey returns the JSON Web Key of the public key, nil if the key is not a JSON Web Key.
+func (pk *PublicKey) JSONWebKey() *jose.JWK {
+	return pk.jsonWebKey
+}
+
 // Service DID doc service
 type Service struct {
 	ID              string
@@ -126,7 +142,17 @@ type Service struct {
 	RecipientKeys   []string
 	RoutingKeys     []string
 	ServiceEndpoint string
-	Properties      map[string]interface{}
+	// StructuredEndpoints are set (instead of ServiceEndpoint) if the serviceEndpoint is an object
+	// or an array, as in DIDComm v2 services
+	StructuredEndpoints []StructuredEndpoint
+	Properties          map[string]interface{}
+}
+
+// StructuredEndpoint is a serviceEndpoint object with its uri, accepted media types and routing keys
+type StructuredEndpoint struct {
+	URI         string
+	Accept      []string
+	RoutingKeys []string
 }
 
 // VerificationMethod authentication verification method
@@ -135,24 +161,29 @@ type VerificationMethod struct {
 }
 
 type rawDoc struct {
-	Context        interface{}              `json:"@context,omitempty"`
-	ID             string                   `json:"id,omitempty"`
-	PublicKey      []map[string]interface{} `json:"publicKey,omitempty"`
-	Service        []map[string]interface{} `json:"service,omitempty"`
-	Authentication []interface{}            `json:"authentication,omitempty"`
-	Created        *time.Time               `json:"created,omitempty"`
-	Updated        *time.Time               `json:"updated,omitempty"`
-	Proof          []interface{}            `json:"proof,omitempty"`
+	Context              interface{}              `json:"@context,omitempty"`
+	ID                   string                   `json:"id,omitempty"`
+	PublicKey            []map[string]interface{} `json:"publicKey,omitempty"`
+	Service              []map[string]interface{} `json:"service,omitempty"`
+	Authentication       []interface{}            `json:"authentication,omitempty"`
+	CapabilityInvocation []interface{}            `json:"capabilityInvocation,omitempty"`
+	KeyAgreement         []interface{}            `json:"keyAgreement,omitempty"`
+	Created              *time.Time               `json:"created,omitempty"`
+	Updated              *time.Time               `json:"updated,omitempty"`
+	Proof                []interface{}            `json:"proof,omitempty"`
 }
 
 // Proof is cryptographic proof of the integrity of the DID Document
 type Proof struct {
-	Type       string
-	Created    *time.Time
-	Creator    string
-	ProofValue []byte
-	Domain     string
-	Nonce      []byte
+	Type               string
+	Created            *time.Time
+	Creator            string
+	VerificationMethod string
+	ProofValue         []byte
+	JWS                string
+	Domain             string
+	Nonce              []byte
+	ProofPurpose       string
 }
 
 // ParseDocument creates an instance of DIDDocument by reading a JSON document from bytes
@@ -183,19 +214,31 @@ func ParseDocument(data []byte) (*Doc, error) {
 		return nil, fmt.Errorf("populate authentications failed: %w", err)
 	}
 
+	capabilityInvocations, err := populateAuthentications(context[0], raw.CapabilityInvocation, publicKeys)
+	if err != nil {
+		return nil, fmt.Errorf("populate capability invocations failed: %w", err)
+	}
+
+	keyAgreements, err := populateAuthentications(context[0], raw.KeyAgreement, publicKeys)
+	if err != nil {
+		return nil, fmt.Errorf("populate key agreements failed: %w", err)
+	}
+
 	proofs, err := populateProofs(context[0], raw.Proof)
 	if err != nil {
 		return nil, fmt.Errorf("populate proofs failed: %w", err)
 	}
 
 	return &Doc{Context: context,
-		ID:             raw.ID,
-		PublicKey:      publicKeys,
-		Service:        populateServices(raw.Service),
-		Authentication: authPKs,
-		Created:        raw.Created,
-		Updated:        raw.Updated,
-		Proof:          proofs,
+		ID:                   raw.ID,
+		PublicKey:            publicKeys,
+		Service:              populateServices(raw.Service),
+		Authentication:       authPKs,
+		CapabilityInvocation: capabilityInvocations,
+		KeyAgreement:         keyAgreements,
+		Created:              raw.Created,
+		Updated:              raw.Updated,
+		Proof:                proofs,
 	}, nil
 }
 
@@ -232,12 +275,15 @@ func populateProofs(context string, rawProofs []interface{}) ([]Proof, error) {
 		}
 
 		proof := Proof{
-			Type:       stringEntry(emap[jsonldType]),
-			Created:    &timeValue,
-			Creator:    stringEntry(emap[jsonldCreator]),
-			ProofValue: proofValue,
-			Domain:     stringEntry(emap[jsonldDomain]),
-			Nonce:      nonce,
+			Type:               stringEntry(emap[jsonldType]),
+			Created:            &timeValue,
+			Creator:            stringEntry(emap[jsonldCreator]),
+			VerificationMethod: stringEntry(emap[jsonldVerificationMethod]),
+			ProofValue:         proofValue,
+			JWS:                stringEntry(emap[jsonldJWS]),
+			Domain:             stringEntry(emap[jsonldDomain]),
+			Nonce:              nonce,
+			ProofPurpose:       stringEntry(emap[jsonldProofPurpose]),
 		}
 
 		proofs = append(proofs, proof)
@@ -250,12 +296,15 @@ func populateServices(rawServices []map[string]interface{}) []Service {
 	services := make([]Service, 0, len(rawServices))
 
 	for _, rawService := range rawServices {
+		serviceEndpoint, structuredEndpoints := serviceEndpointEntry(rawService[jsonldServicePoint])
+
 		service := Service{ID: stringEntry(rawService[jsonldID]),
-			Type:            stringEntry(rawService[jsonldType]),
-			ServiceEndpoint: stringEntry(rawService[jsonldServicePoint]),
-			RecipientKeys:   stringArray(rawService[jsonldRecipientKeys]),
-			RoutingKeys:     stringArray(rawService[jsonldRoutingKeys]),
-			Priority:        uintEntry(rawService[jsonldPriority])}
+			Type:                stringEntry(rawService[jsonldType]),
+			ServiceEndpoint:     serviceEndpoint,
+			StructuredEndpoints: structuredEndpoints,
+			RecipientKeys:       stringArray(rawService[jsonldRecipientKeys]),
+			RoutingKeys:         stringArray(rawService[jsonldRoutingKeys]),
+			Priority:            uintEntry(rawService[jsonldPriority])}
 
 		delete(rawService, jsonldID)
 		delete(rawService, jsonldType)
@@ -271,6 +320,39 @@ func populateServices(rawServices []map[string]interface{}) []Service {
 	return services
 }
 
+// serviceEndpointEntry returns the serviceEndpoint uri, or the structured endpoints if it is an object or an array
+func serviceEndpointEntry(entry interface{}) (string, []StructuredEndpoint) {
+	switch e := entry.(type) {
+	case string:
+		return e, nil
+	case map[string]interface{}:
+		return "", []StructuredEndpoint{structuredEndpointEntry(e)}
+	case []interface{}:
+		var endpoints []StructuredEndpoint
+
+		for _, v := range e {
+			switch endpoint := v.(type) {
+			case string:
+				endpoints = append(endpoints, StructuredEndpoint{URI: endpoint})
+			case map[string]interface{}:
+				endpoints = append(endpoints, structuredEndpointEntry(endpoint))
+			}
+		}
+
+		return "", endpoints
+	}
+
+	return "", nil
+}
+
+func structuredEndpointEntry(entry map[string]interface{}) StructuredEndpoint {
+	return StructuredEndpoint{
+		URI:         stringEntry(entry[jsonldURI]),
+		Accept:      stringArray(entry[jsonldAccept]),
+		RoutingKeys: stringArray(entry[jsonldRoutingKeys]),
+	}
+}
+
 func populateAuthentications(context string, rawAuthentications []interface{},
 	pks []PublicKey) ([]VerificationMethod, error) {
 	var vms []VerificationMethod
@@ -556,14 +638,16 @@ func (doc *Doc) JSONBytes() ([]byte, error) {
 	}
 
 	raw := &rawDoc{
-		Context:        doc.Context,
-		ID:             doc.ID,
-		PublicKey:      populateRawPublicKeys(context, doc.PublicKey),
-		Authentication: populateRawAuthentications(context, doc.Authentication),
-		Service:        populateRawServices(doc.Service),
-		Created:        doc.Created,
-		Proof:          populateRawProofs(context, doc.Proof),
-		Updated:        doc.Updated,
+		Context:              doc.Context,
+		ID:                   doc.ID,
+		PublicKey:            populateRawPublicKeys(context, doc.PublicKey),
+		Authentication:       populateRawAuthentications(context, doc.Authentication),
+		CapabilityInvocation: populateRawAuthentications(context, doc.CapabilityInvocation),
+		KeyAgreement:         populateRawAuthentications(context, doc.KeyAgreement),
+		Service:              populateRawServices(doc.Service),
+		Created:              doc.Created,
+		Proof:                populateRawProofs(context, doc.Proof),
+		Updated:              doc.Updated,
 	}
 
 	byteDoc, err := json.Marshal(raw)
@@ -646,7 +730,7 @@ func populateRawServices(services []Service) []map[string]interface{} {
 
 		rawService[jsonldID] = service.ID
 		rawService[jsonldType] = service.Type
-		rawService[jsonldServicePoint] = service.ServiceEndpoint
+		rawService[jsonldServicePoint] = rawServiceEndpoint(&service)
 		rawService[jsonldRecipientKeys] = service.RecipientKeys
 		rawService[jsonldRoutingKeys] = service.RoutingKeys
 		rawService[jsonldPriority] = service.Priority
@@ -657,6 +741,35 @@ func populateRawServices(services []Service) []map[string]interface{} {
 	return rawServices
 }
 
+// rawServiceEndpoint returns the uri, or the structured endpoints as an object (single endpoint) or an array
+func rawServiceEndpoint(service *Service) interface{} {
+	if len(service.StructuredEndpoints) == 0 {
+		return service.ServiceEndpoint
+	}
+
+	rawEndpoints := make([]interface{}, len(service.StructuredEndpoints))
+
+	for i, endpoint := range service.StructuredEndpoints {
+		rawEndpoint := map[string]interface{}{jsonldURI: endpoint.URI}
+
+		if len(endpoint.Accept) != 0 {
+			rawEndpoint[jsonldAccept] = endpoint.Accept
+		}
+
+		if len(endpoint.RoutingKeys) != 0 {
+			rawEndpoint[jsonldRoutingKeys] = endpoint.RoutingKeys
+		}
+
+		rawEndpoints[i] = rawEndpoint
+	}
+
+	if len(rawEndpoints) == 1 {
+		return rawEndpoints[0]
+	}
+
+	return rawEndpoints
+}
+
 func populateRawPublicKeys(context string, pks []PublicKey) []map[string]interface{} {
 	var rawPKs []map[string]interface{}
 	for i := range pks {
@@ -704,14 +817,32 @@ func populateRawProofs(context string, proofs []Proof) []interface{} {
 	}
 
 	for _, p := range proofs {
-		rawProofs = append(rawProofs, map[string]interface{}{
+		rawProof := map[string]interface{}{
 			jsonldType:    p.Type,
 			jsonldCreated: p.Created,
 			jsonldCreator: p.Creator,
-			k:             base64.RawURLEncoding.EncodeToString(p.ProofValue),
 			jsonldDomain:  p.Domain,
 			jsonldNonce:   base64.RawURLEncoding.EncodeToString(p.Nonce),
-		})
+		}
+
+		// the signature of a proof is either a proof value or a detached JWS
+		if p.JWS == "" || len(p.ProofValue) > 0 {
+			rawProof[k] = base64.RawURLEncoding.EncodeToString(p.ProofValue)
+		}
+
+		if p.JWS != "" {
+			rawProof[jsonldJWS] = p.JWS
+		}
+
+		if p.VerificationMethod != "" {
+			rawProof[jsonldVerificationMethod] = p.VerificationMethod
+		}
+
+		if p.ProofPurpose != "" {
+			rawProof[jsonldProofPurpose] = p.ProofPurpose
+		}
+
+		rawProofs = append(rawProofs, rawProof)
 	}
 
 	return rawProofs
@@ -734,6 +865,20 @@ func WithAuthentication(auth []VerificationMethod) DocOption {
 	}
 }
 
+// WithCapabilityInvocation DID doc CapabilityInvocation.
+func WithCapabilityInvocation(capabilityInvocation []VerificationMethod) DocOption {
+	return func(opts *Doc) {
+		opts.CapabilityInvocation = capabilityInvocation
+	}
+}
+
+// WithKeyAgreement DID doc KeyAgreement.
+func WithKeyAgreement(keyAgreement []VerificationMethod) DocOption {
+	return func(opts *Doc) {
+		opts.KeyAgreement = keyAgreement
+	}
+}
+
 // WithService DID doc services.
 func WithService(svc []Service) DocOption {
 	return func(opts *Doc) {
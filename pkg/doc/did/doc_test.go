@@ -260,6 +260,42 @@ func TestValidWithProof(t *testing.T) {
 	}
 }
 
+func TestValidWithJWSProof(t *testing.T) {
+	doc, err := ParseDocument([]byte(validDocWithJWSProof))
+	require.NoError(t, err)
+	require.Len(t, doc.Proof, 1)
+	require.Equal(t, "did:method:abc#key-1", doc.Proof[0].VerificationMethod)
+	require.Equal(t, "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..c2lnbmF0dXJl", doc.Proof[0].JWS)
+	require.Empty(t, doc.Proof[0].Creator)
+	require.Empty(t, doc.Proof[0].ProofValue)
+
+	// the verification method and the JWS are kept
+	byteDoc, err := doc.JSONBytes()
+	require.NoError(t, err)
+
+	raw := &rawDoc{}
+	require.NoError(t, json.Unmarshal(byteDoc, raw))
+	require.Len(t, raw.Proof, 1)
+
+	rawProof, ok := raw.Proof[0].(map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, doc.Proof[0].VerificationMethod, rawProof["verificationMethod"])
+	require.Equal(t, doc.Proof[0].JWS, rawProof["jws"])
+	require.NotContains(t, rawProof, "proofValue")
+
+	parsed, err := ParseDocument(byteDoc)
+	require.NoError(t, err)
+	require.Equal(t, doc.Proof, parsed.Proof)
+
+	// a proof has a creator or a verification method, and a proof value or a JWS
+	for _, field := range []string{`"verificationMethod"`, `"jws"`} {
+		invalid := strings.Replace(validDocWithJWSProof, field, `"other"`, 1)
+
+		_, err = ParseDocument([]byte(invalid))
+		require.Error(t, err)
+	}
+}
+
 func TestInvalidEncodingInProof(t *testing.T) {
 	proofKey := []string{jsonldProofValue, jsonldSignatureValue}
 	for _, v := range proofKey {
@@ -614,6 +650,79 @@ func TestValidateDidDocService(t *testing.T) {
 	})
 }
 
+func TestStructuredServiceEndpoints(t *testing.T) {
+	t.Run("test serviceEndpoint object and array", func(t *testing.T) {
+		docs := []string{validDoc, validDocV011}
+		for _, d := range docs {
+			raw := &rawDoc{}
+			require.NoError(t, json.Unmarshal([]byte(d), &raw))
+
+			raw.Service[0][jsonldServicePoint] = map[string]interface{}{
+				"uri":         "https://agent.example.com/v2",
+				"accept":      []string{"didcomm/v2"},
+				"routingKeys": []string{"did:example:mediator#key-1"},
+			}
+			raw.Service[1][jsonldServicePoint] = []interface{}{
+				map[string]interface{}{"uri": "https://agent.example.com/v2"},
+				"wss://agent.example.com/ws",
+			}
+
+			bytes, err := json.Marshal(raw)
+			require.NoError(t, err)
+
+			doc, err := ParseDocument(bytes)
+			require.NoError(t, err)
+
+			require.Empty(t, doc.Service[0].ServiceEndpoint)
+			require.Equal(t, []StructuredEndpoint{{
+				URI:         "https://agent.example.com/v2",
+				Accept:      []string{"didcomm/v2"},
+				RoutingKeys: []string{"did:example:mediator#key-1"},
+			}}, doc.Service[0].StructuredEndpoints)
+			require.NotContains(t, doc.Service[0].Properties, jsonldServicePoint)
+
+			require.Empty(t, doc.Service[1].ServiceEndpoint)
+			require.Equal(t, []StructuredEndpoint{
+				{URI: "https://agent.example.com/v2"},
+				{URI: "wss://agent.example.com/ws"},
+			}, doc.Service[1].StructuredEndpoints)
+
+			// serialized back as an object for one endpoint and an array for many
+			docBytes, err := doc.JSONBytes()
+			require.NoError(t, err)
+
+			doc2, err := ParseDocument(docBytes)
+			require.NoError(t, err)
+			require.Equal(t, doc.Service, doc2.Service)
+
+			raw2 := &rawDoc{}
+			require.NoError(t, json.Unmarshal(docBytes, &raw2))
+			require.IsType(t, map[string]interface{}{}, raw2.Service[0][jsonldServicePoint])
+			require.IsType(t, []interface{}{}, raw2.Service[1][jsonldServicePoint])
+		}
+	})
+
+	t.Run("test serviceEndpoint object without uri", func(t *testing.T) {
+		raw := &rawDoc{}
+		require.NoError(t, json.Unmarshal([]byte(validDoc), &raw))
+
+		raw.Service[0][jsonldServicePoint] = map[string]interface{}{"accept": []string{"didcomm/v2"}}
+
+		bytes, err := json.Marshal(raw)
+		require.NoError(t, err)
+
+		_, err = ParseDocument(bytes)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "serviceEndpoint")
+	})
+
+	t.Run("test serviceEndpoint of unexpected type", func(t *testing.T) {
+		serviceEndpoint, structuredEndpoints := serviceEndpointEntry(1.0)
+		require.Empty(t, serviceEndpoint)
+		require.Empty(t, structuredEndpoints)
+	})
+}
+
 func TestValidateDidDocCreated(t *testing.T) {
 	t.Run("test did doc with empty created", func(t *testing.T) {
 		docs := []string{validDoc, validDocV011}
@@ -804,6 +913,45 @@ func TestJSONConversion(t *testing.T) {
 	}
 }
 
+func TestKeyAgreement(t *testing.T) {
+	keyAgreementKey := PublicKey{
+		ID:         "did:method:abc#key-agreement-1",
+		Type:       "X25519KeyAgreementKey2019",
+		Controller: "did:method:abc",
+		Value:      base58.Decode("JhNWeSVLMYccCk7iopQW4guaSJTojqpMEELgSLhKwRr"),
+	}
+
+	doc := BuildDoc(WithPublicKey([]PublicKey{keyAgreementKey}),
+		WithKeyAgreement([]VerificationMethod{{PublicKey: keyAgreementKey}}))
+	doc.Context = []string{Context}
+	doc.ID = "did:method:abc"
+
+	byteDoc, err := doc.JSONBytes()
+	require.NoError(t, err)
+	require.Contains(t, string(byteDoc), `"keyAgreement":[{`)
+
+	parsed, err := ParseDocument(byteDoc)
+	require.NoError(t, err)
+	require.Equal(t, doc.KeyAgreement, parsed.KeyAgreement)
+
+	// key agreement keys referenced by id
+	raw := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(byteDoc, &raw))
+	raw["keyAgreement"] = []interface{}{keyAgreementKey.ID}
+
+	byteDoc, err = json.Marshal(raw)
+	require.NoError(t, err)
+
+	parsed, err = ParseDocument(byteDoc)
+	require.NoError(t, err)
+	require.Equal(t, doc.KeyAgreement, parsed.KeyAgreement)
+
+	// omitted when empty
+	byteDoc, err = BuildDoc(WithPublicKey([]PublicKey{keyAgreementKey})).JSONBytes()
+	require.NoError(t, err)
+	require.NotContains(t, string(byteDoc), "keyAgreement")
+}
+
 func TestVerifyProof(t *testing.T) {
 	docs := []string{validDoc, validDocV011}
 	for _, d := range docs {
@@ -1282,3 +1430,20 @@ const docV011WithInvalidUpdated = `{
 	"updated": "2019-9-23T14:16:59.261024-04:00",
 	"id": "did:method:abc"
 }`
+
+const validDocWithJWSProof = `{
+	"@context": ["https://w3id.org/did/v1"],
+	"id": "did:method:abc",
+	"proof": [{
+		"created": "2019-09-23T14:16:59.484733-04:00",
+		"verificationMethod": "did:method:abc#key-1",
+		"jws": "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..c2lnbmF0dXJl",
+		"type": "Ed25519Signature2018"
+	}],
+	"publicKey": [{
+		"controller": "did:method:abc",
+		"id": "did:method:abc#key-1",
+		"publicKeyBase58": "GY4GunSXBPBfhLCzDL7iGmP5dR3sBDCJZkkaGK8VgYQf",
+		"type": "Ed25519VerificationKey2018"
+	}]
+}`
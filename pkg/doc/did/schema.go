@@ -68,9 +68,47 @@ const (
     }
   },
   "definitions": {
+    "serviceEndpoint": {
+      "type": "object",
+      "required": [
+        "uri"
+      ],
+      "properties": {
+        "uri": {
+          "type": "string",
+          "format": "uri"
+        },
+        "accept": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "routingKeys": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      }
+    },
 	"proof": {
       "type": "object",
-      "required": [ "type", "creator", "created", "proofValue"],
+      "required": [ "type", "created"],
+      "allOf": [
+        {
+          "anyOf": [
+            { "required": [ "creator" ] },
+            { "required": [ "verificationMethod" ] }
+          ]
+        },
+        {
+          "anyOf": [
+            { "required": [ "proofValue" ] },
+            { "required": [ "jws" ] }
+          ]
+        }
+      ],
       "properties": {
         "type": {
           "type": "string",
@@ -80,12 +118,19 @@ const (
           "type": "string",
           "format": "uri-reference"
         },
+        "verificationMethod": {
+          "type": "string",
+          "format": "uri-reference"
+        },
         "created": {
           "type": "string"
         },
         "proofValue": {
           "type": "string"
         },
+        "jws": {
+          "type": "string"
+        },
         "domain": {
           "type": "string"
         },
@@ -129,8 +174,29 @@ const (
           "type": "string"
         },
         "serviceEndpoint": {
-          "type": "string",
-          "format": "uri"
+          "oneOf": [
+            {
+              "type": "string",
+              "format": "uri"
+            },
+            {
+              "$ref": "#/definitions/serviceEndpoint"
+            },
+            {
+              "type": "array",
+              "items": {
+                "oneOf": [
+                  {
+                    "type": "string",
+                    "format": "uri"
+                  },
+                  {
+                    "$ref": "#/definitions/serviceEndpoint"
+                  }
+                ]
+              }
+            }
+          ]
         }
       }
     }
@@ -201,6 +267,30 @@ const (
     }
   },
   "definitions": {
+    "serviceEndpoint": {
+      "type": "object",
+      "required": [
+        "uri"
+      ],
+      "properties": {
+        "uri": {
+          "type": "string",
+          "format": "uri"
+        },
+        "accept": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "routingKeys": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      }
+    },
 	"proof": {
       "type": "object",
       "required": [ "type", "creator", "created", "signatureValue"],
@@ -278,8 +368,29 @@ const (
           "type": "string"
         },
         "serviceEndpoint": {
-          "type": "string",
-          "format": "uri"
+          "oneOf": [
+            {
+              "type": "string",
+              "format": "uri"
+            },
+            {
+              "$ref": "#/definitions/serviceEndpoint"
+            },
+            {
+              "type": "array",
+              "items": {
+                "oneOf": [
+                  {
+                    "type": "string",
+                    "format": "uri"
+                  },
+                  {
+                    "$ref": "#/definitions/serviceEndpoint"
+                  }
+                ]
+              }
+            }
+          ]
         }
       }
     }
@@ -338,9 +449,47 @@ const (
     }
   },
   "definitions": {
+    "serviceEndpoint": {
+      "type": "object",
+      "required": [
+        "uri"
+      ],
+      "properties": {
+        "uri": {
+          "type": "string",
+          "format": "uri"
+        },
+        "accept": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "routingKeys": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      }
+    },
 	"proof": {
       "type": "object",
-      "required": [ "type", "creator", "created", "proofValue"],
+      "required": [ "type", "created"],
+      "allOf": [
+        {
+          "anyOf": [
+            { "required": [ "creator" ] },
+            { "required": [ "verificationMethod" ] }
+          ]
+        },
+        {
+          "anyOf": [
+            { "required": [ "proofValue" ] },
+            { "required": [ "jws" ] }
+          ]
+        }
+      ],
       "properties": {
         "type": {
           "type": "string",
@@ -350,12 +499,19 @@ const (
           "type": "string",
           "format": "uri-reference"
         },
+        "verificationMethod": {
+          "type": "string",
+          "format": "uri-reference"
+        },
         "created": {
           "type": "string"
         },
         "proofValue": {
           "type": "string"
         },
+        "jws": {
+          "type": "string"
+        },
         "domain": {
           "type": "string"
         },
@@ -397,8 +553,29 @@ const (
           "type": "string"
         },
         "serviceEndpoint": {
-          "type": "string",
-          "format": "uri"
+          "oneOf": [
+            {
+              "type": "string",
+              "format": "uri"
+            },
+            {
+              "$ref": "#/definitions/serviceEndpoint"
+            },
+            {
+              "type": "array",
+              "items": {
+                "oneOf": [
+                  {
+                    "type": "string",
+                    "format": "uri"
+                  },
+                  {
+                    "$ref": "#/definitions/serviceEndpoint"
+                  }
+                ]
+              }
+            }
+          ]
         }
       }
     }
@@ -0,0 +1,86 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+)
+
+// ErrThresholdNotMet is returned when fewer verification methods than the threshold made valid signatures.
+var ErrThresholdNotMet = errors.New("signature threshold not met")
+
+// MethodSignature is a signature made with the key of a verification method.
+type MethodSignature struct {
+	// MethodID is the id of the verification method
+	MethodID string
+	// Signature is the signature value
+	Signature []byte
+}
+
+// signatureVerifier verifies a signature with a public key (see verifier.PublicKeyVerifier)
+type signatureVerifier interface {
+	Verify(pubKey *verifier.PublicKey, msg, signature []byte) error
+}
+
+// VerifyThreshold checks that at least threshold distinct capabilityInvocation verification methods of doc
+// made a valid signature of msg. Signatures of methods not in the capabilityInvocation relationship and
+// invalid signatures are not counted, and a method signing several times is counted once.
+// It returns the IDs of the methods with a valid signature, along with an error wrapping ErrThresholdNotMet if
+// there are fewer of them than the threshold.
+func VerifyThreshold(doc *Doc, msg []byte, signatures []MethodSignature, threshold int,
+	v signatureVerifier) ([]string, error) {
+	if threshold < 1 {
+		return nil, fmt.Errorf("verify threshold: invalid threshold %d", threshold)
+	}
+
+	methods, err := doc.verificationMethods(CapabilityInvocation)
+	if err != nil {
+		return nil, fmt.Errorf("verify threshold: %w", err)
+	}
+
+	var satisfied []string
+
+	counted := make(map[string]bool)
+
+	for _, sig := range signatures {
+		if counted[sig.MethodID] {
+			continue
+		}
+
+		pk, ok := lookupVerificationMethod(methods, sig.MethodID)
+		if !ok {
+			continue
+		}
+
+		err = v.Verify(&verifier.PublicKey{Type: pk.Type, Value: pk.Value, JWK: pk.jsonWebKey}, msg, sig.Signature)
+		if err != nil {
+			continue
+		}
+
+		counted[sig.MethodID] = true
+
+		satisfied = append(satisfied, sig.MethodID)
+	}
+
+	if len(satisfied) < threshold {
+		return satisfied, fmt.Errorf("%w: %d of %d valid signatures", ErrThresholdNotMet, len(satisfied), threshold)
+	}
+
+	return satisfied, nil
+}
+
+func lookupVerificationMethod(methods []VerificationMethod, id string) (*PublicKey, bool) {
+	for i := range methods {
+		if methods[i].PublicKey.ID == id {
+			return &methods[i].PublicKey, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,112 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+)
+
+func TestVerifyThreshold(t *testing.T) {
+	const (
+		msg      = "high value operation"
+		keyCount = 3
+	)
+
+	privKeys := make([]ed25519.PrivateKey, keyCount)
+	rawPKs := ""
+
+	for i := range privKeys {
+		pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		privKeys[i] = privKey
+
+		if i > 0 {
+			rawPKs += ","
+		}
+
+		rawPKs += fmt.Sprintf(`{"id": "%s#key-%d", "type": "Ed25519VerificationKey2018",
+			"controller": "%s", "publicKeyBase58": "%s"}`, subjectDID, i+1, subjectDID, base58.Encode(pubKey))
+	}
+
+	// key-3 is not a capabilityInvocation method
+	doc, err := ParseDocument([]byte(fmt.Sprintf(`{
+		"@context": ["https://w3id.org/did/v1"],
+		"id": "%s",
+		"publicKey": [%s],
+		"capabilityInvocation": ["%s#key-1", "%s#key-2"]
+	}`, subjectDID, rawPKs, subjectDID, subjectDID)))
+	require.NoError(t, err)
+	require.Len(t, doc.CapabilityInvocation, 2)
+
+	sign := func(i int) MethodSignature {
+		return MethodSignature{
+			MethodID:  fmt.Sprintf("%s#key-%d", subjectDID, i+1),
+			Signature: ed25519.Sign(privKeys[i], []byte(msg)),
+		}
+	}
+
+	v := verifier.NewPublicKeyVerifier(verifier.NewEd25519SignatureVerifier())
+
+	t.Run("test exactly threshold", func(t *testing.T) {
+		satisfied, err := VerifyThreshold(doc, []byte(msg), []MethodSignature{sign(0), sign(1)}, 2, v)
+		require.NoError(t, err)
+		require.Equal(t, []string{subjectDID + "#key-1", subjectDID + "#key-2"}, satisfied)
+	})
+
+	t.Run("test above threshold", func(t *testing.T) {
+		satisfied, err := VerifyThreshold(doc, []byte(msg), []MethodSignature{sign(1), sign(0)}, 1, v)
+		require.NoError(t, err)
+		require.Equal(t, []string{subjectDID + "#key-2", subjectDID + "#key-1"}, satisfied)
+	})
+
+	t.Run("test below threshold", func(t *testing.T) {
+		invalid := sign(1)
+		invalid.Signature = ed25519.Sign(privKeys[1], []byte("other operation"))
+
+		// key-3 signature is valid but key-3 is not a capabilityInvocation method
+		satisfied, err := VerifyThreshold(doc, []byte(msg), []MethodSignature{sign(0), invalid, sign(2)}, 2, v)
+		require.True(t, errors.Is(err, ErrThresholdNotMet))
+		require.EqualError(t, err, "signature threshold not met: 1 of 2 valid signatures")
+		require.Equal(t, []string{subjectDID + "#key-1"}, satisfied)
+	})
+
+	t.Run("test duplicate signatures of a method are counted once", func(t *testing.T) {
+		satisfied, err := VerifyThreshold(doc, []byte(msg), []MethodSignature{sign(0), sign(0)}, 2, v)
+		require.True(t, errors.Is(err, ErrThresholdNotMet))
+		require.Equal(t, []string{subjectDID + "#key-1"}, satisfied)
+	})
+
+	t.Run("test invalid threshold", func(t *testing.T) {
+		_, err := VerifyThreshold(doc, []byte(msg), []MethodSignature{sign(0)}, 0, v)
+		require.EqualError(t, err, "verify threshold: invalid threshold 0")
+	})
+
+	t.Run("test capabilityInvocation round trip", func(t *testing.T) {
+		docBytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		parsed, err := ParseDocument(docBytes)
+		require.NoError(t, err)
+		require.Equal(t, doc.CapabilityInvocation, parsed.CapabilityInvocation)
+
+		err = AuthorizeVerificationMethod(parsed, subjectDID+"#key-1", CapabilityInvocation, nil)
+		require.NoError(t, err)
+
+		err = AuthorizeVerificationMethod(parsed, subjectDID+"#key-3", CapabilityInvocation, nil)
+		require.True(t, errors.Is(err, ErrNotAuthorized))
+	})
+}
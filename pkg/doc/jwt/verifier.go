@@ -12,6 +12,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/square/go-jose/v3/json"
 	"golang.org/x/crypto/ed25519"
@@ -30,6 +31,25 @@ const (
 
 const issuerClaim = "iss"
 
+// key families used to match signature algorithm against public key type
+const (
+	keyFamilyEd25519 = "Ed25519"
+	keyFamilyRSA     = "RSA"
+	keyFamilyECDSA   = "ECDSA"
+)
+
+// AlgMismatchError is returned when the signature algorithm (e.g. from "alg" JOSE header)
+// doesn't match the type of the resolved public key.
+type AlgMismatchError struct {
+	Alg     string
+	KeyType string
+}
+
+// Error returns the error message.
+func (e *AlgMismatchError) Error() string {
+	return fmt.Sprintf("signature algorithm '%s' doesn't match public key type '%s'", e.Alg, e.KeyType)
+}
+
 // KeyResolver resolves public key based on what and kid.
 type KeyResolver interface {
 
@@ -98,9 +118,103 @@ func verifySignature(resolver KeyResolver, signatureVerifier signatureVerifier,
 		return err
 	}
 
+	alg, _ := joseHeaders.Algorithm()
+
+	err = CheckAlgorithmMatchesKey(alg, pubKey)
+	if err != nil {
+		return err
+	}
+
 	return signatureVerifier(pubKey, signingInput, signature)
 }
 
+// CheckAlgorithmMatchesKey checks that the signature algorithm is applicable to the public key, so a signature
+// cannot be verified with a key of another type (algorithm confusion), nor an ECDSA signature with a key of another
+// curve. AlgMismatchError is returned in case of mismatch, as well as if the family of the algorithm or of the public
+// key is unknown (eg: a X25519 key or a JwsVerificationKey2020 without JWK).
+func CheckAlgorithmMatchesKey(alg string, pubKey *verifier.PublicKey) error {
+	algFamily := algorithmKeyFamily(alg)
+	pubKeyFamily, curve := publicKeyFamily(pubKey)
+
+	if algFamily != "" && algFamily == pubKeyFamily && (algFamily != keyFamilyECDSA || algorithmCurves[alg] == curve) {
+		return nil
+	}
+
+	keyType := pubKey.Type
+	if pubKey.JWK != nil && pubKey.JWK.Kty != "" {
+		keyType = strings.TrimSpace(pubKey.JWK.Kty + " " + pubKey.JWK.Crv)
+	}
+
+	return &AlgMismatchError{Alg: alg, KeyType: keyType}
+}
+
+// algorithmCurves are the curves of the keys of the ECDSA signature algorithms.
+var algorithmCurves = map[string]string{ //nolint:gochecknoglobals
+	"ES256":  "P-256",
+	"ES384":  "P-384",
+	"ES512":  "P-521",
+	"ES256K": "secp256k1",
+}
+
+func algorithmKeyFamily(alg string) string {
+	switch {
+	case alg == signatureEdDSA:
+		return keyFamilyEd25519
+	case strings.HasPrefix(alg, "RS"), strings.HasPrefix(alg, "PS"):
+		return keyFamilyRSA
+	case algorithmCurves[alg] != "":
+		return keyFamilyECDSA
+	default:
+		return ""
+	}
+}
+
+// publicKeyFamily returns the family of the public key and, for the ECDSA keys, its curve if known.
+func publicKeyFamily(pubKey *verifier.PublicKey) (string, string) {
+	if pubKey.JWK != nil && pubKey.JWK.Kty != "" {
+		switch pubKey.JWK.Kty {
+		case "OKP":
+			if pubKey.JWK.Crv == "Ed25519" {
+				return keyFamilyEd25519, ""
+			}
+
+			return "", ""
+		case "EC":
+			return keyFamilyECDSA, pubKey.JWK.Crv
+		case "RSA":
+			return keyFamilyRSA, ""
+		default:
+			return "", ""
+		}
+	}
+
+	keyType := strings.ToLower(pubKey.Type)
+
+	switch {
+	case strings.Contains(keyType, "ed25519"):
+		return keyFamilyEd25519, ""
+	case strings.Contains(keyType, "rsa"):
+		return keyFamilyRSA, ""
+	case strings.Contains(keyType, "secp256k1"):
+		return keyFamilyECDSA, "secp256k1"
+	case strings.Contains(keyType, "ecdsa"):
+		return keyFamilyECDSA, ecdsaCurve(keyType)
+	default:
+		return "", ""
+	}
+}
+
+// ecdsaCurve returns the curve of the ECDSA key type keyType (eg: kms.ECDSAP256), empty if unknown.
+func ecdsaCurve(keyType string) string {
+	for _, curve := range []string{"P-256", "P-384", "P-521"} {
+		if strings.Contains(keyType, strings.ToLower(strings.ReplaceAll(curve, "-", ""))) {
+			return curve
+		}
+	}
+
+	return ""
+}
+
 // Verify verifies JSON Web Token. Public key is fetched using Issuer Claim and Key ID JOSE Header.
 func (v BasicVerifier) Verify(joseHeaders jose.Headers, payload, signingInput, signature []byte) error {
 	return v.compositeVerifier.Verify(joseHeaders, payload, signingInput, signature)
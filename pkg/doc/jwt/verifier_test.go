@@ -182,3 +182,106 @@ func TestVerifyRS256(t *testing.T) {
 	}, []byte("test message"), signature)
 	r.Error(err)
 }
+
+func TestBasicVerifier_AlgMismatch(t *testing.T) {
+	r := require.New(t)
+
+	_, privKey, err := ed25519.GenerateKey(rand.Reader)
+	r.NoError(err)
+
+	token, err := NewSigned(&Claims{Issuer: "Mike"}, nil, newEd25519Signer(privKey))
+	r.NoError(err)
+	jws, err := token.Serialize(false)
+	r.NoError(err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	r.NoError(err)
+
+	// "EdDSA" JWS verified against the resolved RSA key
+	v := NewVerifier(getTestKeyResolver(&verifier.PublicKey{
+		Type:  "RsaVerificationKey2018",
+		Value: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey),
+	}, nil))
+
+	_, err = jose.ParseJWS(jws, v)
+	r.Error(err)
+
+	var algErr *AlgMismatchError
+	r.True(errors.As(err, &algErr))
+	r.Equal("EdDSA", algErr.Alg)
+	r.Equal("RsaVerificationKey2018", algErr.KeyType)
+}
+
+func TestCheckAlgorithmMatchesKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		alg      string
+		pubKey   *verifier.PublicKey
+		mismatch bool
+	}{
+		{name: "EdDSA with Ed25519 key", alg: "EdDSA",
+			pubKey: &verifier.PublicKey{Type: "Ed25519VerificationKey2018"}},
+		{name: "EdDSA with kms Ed25519 key", alg: "EdDSA", pubKey: &verifier.PublicKey{Type: kms.ED25519}},
+		{name: "EdDSA with Ed25519 JWK", alg: "EdDSA",
+			pubKey: &verifier.PublicKey{Type: "JwsVerificationKey2020", JWK: &jose.JWK{Kty: "OKP", Crv: "Ed25519"}}},
+		{name: "RS256 with RSA key", alg: "RS256", pubKey: &verifier.PublicKey{Type: kms.RSA}},
+		{name: "ES256K with secp256k1 key", alg: "ES256K",
+			pubKey: &verifier.PublicKey{Type: "EcdsaSecp256k1VerificationKey2019"}},
+		{name: "ES256 with EC JWK", alg: "ES256",
+			pubKey: &verifier.PublicKey{Type: "JwsVerificationKey2020", JWK: &jose.JWK{Kty: "EC", Crv: "P-256"}}},
+		{name: "ES256 with kms ECDSA P-256 key", alg: "ES256", pubKey: &verifier.PublicKey{Type: kms.ECDSAP256}},
+		{name: "ES384 with kms ECDSA P-384 key", alg: "ES384", pubKey: &verifier.PublicKey{Type: kms.ECDSAP384}},
+		{name: "ES512 with P-521 JWK", alg: "ES512",
+			pubKey: &verifier.PublicKey{Type: "JwsVerificationKey2020", JWK: &jose.JWK{Kty: "EC", Crv: "P-521"}}},
+		{name: "ES256K with secp256k1 JWK", alg: "ES256K",
+			pubKey: &verifier.PublicKey{Type: "JwsVerificationKey2020", JWK: &jose.JWK{Kty: "EC", Crv: "secp256k1"}}},
+		{name: "unknown key type", alg: "EdDSA", pubKey: &verifier.PublicKey{Type: "CustomKey"}, mismatch: true},
+		{name: "unknown algorithm", alg: "custom", pubKey: &verifier.PublicKey{Type: kms.RSA}, mismatch: true},
+		{name: "JwsVerificationKey2020 without JWK", alg: "EdDSA",
+			pubKey: &verifier.PublicKey{Type: "JwsVerificationKey2020"}, mismatch: true},
+		{name: "ES256 with P-384 JWK", alg: "ES256",
+			pubKey:   &verifier.PublicKey{Type: "JwsVerificationKey2020", JWK: &jose.JWK{Kty: "EC", Crv: "P-384"}},
+			mismatch: true},
+		{name: "ES256 with secp256k1 key", alg: "ES256",
+			pubKey: &verifier.PublicKey{Type: "EcdsaSecp256k1VerificationKey2019"}, mismatch: true},
+		{name: "ES256K with P-256 JWK", alg: "ES256K",
+			pubKey:   &verifier.PublicKey{Type: "JwsVerificationKey2020", JWK: &jose.JWK{Kty: "EC", Crv: "P-256"}},
+			mismatch: true},
+		{name: "ES384 with kms ECDSA P-256 key", alg: "ES384", pubKey: &verifier.PublicKey{Type: kms.ECDSAP256},
+			mismatch: true},
+		{name: "ES256 with ECDSA key of unknown curve", alg: "ES256",
+			pubKey: &verifier.PublicKey{Type: "EcdsaVerificationKey"}, mismatch: true},
+		{name: "EdDSA with RSA key", alg: "EdDSA", pubKey: &verifier.PublicKey{Type: kms.RSA}, mismatch: true},
+		{name: "EdDSA with ECDSA key", alg: "EdDSA",
+			pubKey: &verifier.PublicKey{Type: "Secp256k1VerificationKey2018"}, mismatch: true},
+		{name: "EdDSA with EC JWK", alg: "EdDSA",
+			pubKey:   &verifier.PublicKey{Type: "JwsVerificationKey2020", JWK: &jose.JWK{Kty: "EC", Crv: "P-256"}},
+			mismatch: true},
+		{name: "EdDSA with X25519 JWK", alg: "EdDSA",
+			pubKey: &verifier.PublicKey{Type: kms.ED25519, JWK: &jose.JWK{Kty: "OKP", Crv: "X25519"}}, mismatch: true},
+		{name: "RS256 with Ed25519 key", alg: "RS256", pubKey: &verifier.PublicKey{Type: kms.ED25519}, mismatch: true},
+		{name: "RS256 with ECDSA key", alg: "RS256", pubKey: &verifier.PublicKey{Type: kms.ECDSAP256}, mismatch: true},
+		{name: "PS256 with Ed25519 JWK", alg: "PS256",
+			pubKey: &verifier.PublicKey{JWK: &jose.JWK{Kty: "OKP", Crv: "Ed25519"}}, mismatch: true},
+		{name: "ES256 with Ed25519 key", alg: "ES256",
+			pubKey: &verifier.PublicKey{Type: "Ed25519VerificationKey2018"}, mismatch: true},
+		{name: "ES256K with RSA JWK", alg: "ES256K",
+			pubKey: &verifier.PublicKey{Type: "JwsVerificationKey2020", JWK: &jose.JWK{Kty: "RSA"}}, mismatch: true},
+	}
+
+	for _, test := range tests {
+		tc := test
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckAlgorithmMatchesKey(tc.alg, tc.pubKey)
+			if !tc.mismatch {
+				require.NoError(t, err)
+				return
+			}
+
+			var algErr *AlgMismatchError
+			require.True(t, errors.As(err, &algErr))
+			require.Equal(t, tc.alg, algErr.Alg)
+			require.Contains(t, err.Error(), "doesn't match public key type")
+		})
+	}
+}
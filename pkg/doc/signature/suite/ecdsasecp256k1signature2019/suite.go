@@ -50,6 +50,10 @@ func (s *Suite) GetCanonicalDocument(doc map[string]interface{}) ([]byte, error)
 	options.ProduceGeneralizedRdf = true
 	options.Algorithm = algorithm
 
+	if err := s.CheckJSONLDTerms(doc, options); err != nil {
+		return nil, err
+	}
+
 	canonicalDoc, err := proc.Normalize(doc, options)
 	if err != nil {
 		return nil, err
@@ -47,6 +47,10 @@ func (s *Suite) GetCanonicalDocument(doc map[string]interface{}) ([]byte, error)
 	options.Format = format
 	options.ProduceGeneralizedRdf = true
 
+	if err := s.CheckJSONLDTerms(doc, options); err != nil {
+		return nil, err
+	}
+
 	canonicalDoc, err := proc.Normalize(doc, options)
 	if err != nil {
 		return nil, err
@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
 )
 
 func TestSignatureSuite_GetCanonicalDocument(t *testing.T) {
@@ -18,6 +20,29 @@ func TestSignatureSuite_GetCanonicalDocument(t *testing.T) {
 	require.Equal(t, test28Result, string(doc))
 }
 
+func TestSignatureSuite_GetCanonicalDocument_StrictJSONLD(t *testing.T) {
+	docWithUndefinedTerm := getDefaultDoc()
+	docWithUndefinedTerm["undefinedTerm"] = "value"
+
+	t.Run("strict", func(t *testing.T) {
+		doc, err := New(suite.WithStrictJSONLD()).GetCanonicalDocument(getDefaultDoc())
+		require.NoError(t, err)
+		require.Equal(t, test28Result, string(doc))
+
+		doc, err = New(suite.WithStrictJSONLD()).GetCanonicalDocument(docWithUndefinedTerm)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "undefinedTerm")
+		require.Empty(t, doc)
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		// undefined term is dropped
+		doc, err := New().GetCanonicalDocument(docWithUndefinedTerm)
+		require.NoError(t, err)
+		require.Equal(t, test28Result, string(doc))
+	})
+}
+
 func TestSignatureSuite_GetDigest(t *testing.T) {
 	digest := New().GetDigest([]byte("test doc"))
 	require.NotNil(t, digest)
@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package suite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+// UndefinedTermsError is returned in strict JSON-LD processing mode when the document contains terms which are not
+// defined in its context.
+type UndefinedTermsError struct {
+	Terms []string
+}
+
+// Error returns the error message.
+func (e *UndefinedTermsError) Error() string {
+	return fmt.Sprintf("JSON-LD terms are not defined in the context: %s", strings.Join(e.Terms, ", "))
+}
+
+// CheckJSONLDTerms checks that all the terms of the document are defined in its context if strict JSON-LD
+// processing is enabled (see WithStrictJSONLD). Otherwise the undefined terms are dropped by JSON-LD expansion.
+func (s *SignatureSuite) CheckJSONLDTerms(doc map[string]interface{}, options *ld.JsonLdOptions) error {
+	if !s.StrictJSONLD {
+		return nil
+	}
+
+	proc := ld.NewJsonLdProcessor()
+
+	expandedDoc, err := proc.Expand(doc, options)
+	if err != nil {
+		return fmt.Errorf("expand JSON-LD document: %w", err)
+	}
+
+	context := map[string]interface{}{}
+	if docContext, ok := doc["@context"]; ok {
+		context["@context"] = docContext
+	}
+
+	// the terms dropped by expansion are missing after the compaction of the expanded document
+	compactedDoc, err := proc.Compact(expandedDoc, context, options)
+	if err != nil {
+		return fmt.Errorf("compact JSON-LD document: %w", err)
+	}
+
+	undefined := make(map[string]bool)
+	collectUndefinedTerms(doc, compactedDoc, "", undefined)
+
+	if len(undefined) == 0 {
+		return nil
+	}
+
+	terms := make([]string, 0, len(undefined))
+	for term := range undefined {
+		terms = append(terms, term)
+	}
+
+	sort.Strings(terms)
+
+	return &UndefinedTermsError{Terms: terms}
+}
+
+func collectUndefinedTerms(original, compacted interface{}, path string, undefined map[string]bool) {
+	switch originalValue := original.(type) {
+	case map[string]interface{}:
+		compactedMap, ok := compacted.(map[string]interface{})
+		if !ok {
+			compactedMap = map[string]interface{}{}
+		}
+
+		collectUndefinedMapTerms(originalValue, compactedMap, path, undefined)
+	case []interface{}:
+		compactedArray, ok := compacted.([]interface{})
+		if !ok {
+			// compaction turns a single element array into the element
+			compactedArray = []interface{}{compacted}
+		}
+
+		if len(originalValue) != len(compactedArray) {
+			return
+		}
+
+		for i := range originalValue {
+			collectUndefinedTerms(originalValue[i], compactedArray[i], path, undefined)
+		}
+	}
+}
+
+func collectUndefinedMapTerms(original, compacted map[string]interface{}, path string, undefined map[string]bool) {
+	for term, value := range original {
+		if strings.HasPrefix(term, "@") {
+			continue
+		}
+
+		termPath := term
+		if path != "" {
+			termPath = path + "." + term
+		}
+
+		compactedValue, ok := compacted[term]
+		if !ok {
+			undefined[termPath] = true
+
+			continue
+		}
+
+		collectUndefinedTerms(value, compactedValue, termPath, undefined)
+	}
+}
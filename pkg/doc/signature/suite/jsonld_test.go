@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package suite
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureSuite_CheckJSONLDTerms(t *testing.T) {
+	options := ld.NewJsonLdOptions("")
+	options.ProcessingMode = ld.JsonLd_1_1
+
+	t.Run("strict mode rejects undefined terms", func(t *testing.T) {
+		ss := InitSuiteOptions(&SignatureSuite{}, WithStrictJSONLD())
+
+		err := ss.CheckJSONLDTerms(credentialWithUndefinedTerm(), options)
+		require.Error(t, err)
+
+		var undefinedErr *UndefinedTermsError
+		require.True(t, errors.As(err, &undefinedErr))
+		require.Equal(t, []string{"credentialSubject.favouriteColour", "undefinedTerm"}, undefinedErr.Terms)
+		require.Contains(t, err.Error(), "JSON-LD terms are not defined in the context")
+	})
+
+	t.Run("strict mode accepts defined terms", func(t *testing.T) {
+		ss := InitSuiteOptions(&SignatureSuite{}, WithStrictJSONLD())
+
+		doc := credentialWithUndefinedTerm()
+		delete(doc, "undefinedTerm")
+		delete(doc["credentialSubject"].(map[string]interface{}), "favouriteColour")
+
+		require.NoError(t, ss.CheckJSONLDTerms(doc, options))
+	})
+
+	t.Run("lenient mode ignores undefined terms", func(t *testing.T) {
+		ss := InitSuiteOptions(&SignatureSuite{})
+
+		require.NoError(t, ss.CheckJSONLDTerms(credentialWithUndefinedTerm(), options))
+	})
+
+	t.Run("strict mode without context", func(t *testing.T) {
+		ss := InitSuiteOptions(&SignatureSuite{}, WithStrictJSONLD())
+
+		err := ss.CheckJSONLDTerms(map[string]interface{}{"name": "Alice"}, options)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "name")
+	})
+
+	t.Run("strict mode with invalid context", func(t *testing.T) {
+		ss := InitSuiteOptions(&SignatureSuite{}, WithStrictJSONLD())
+
+		err := ss.CheckJSONLDTerms(map[string]interface{}{
+			"@context": map[string]interface{}{"name": 1},
+			"name":     "Alice",
+		}, options)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expand JSON-LD document")
+	})
+}
+
+func credentialWithUndefinedTerm() map[string]interface{} {
+	return map[string]interface{}{
+		"@context": map[string]interface{}{
+			"id":                   "@id",
+			"type":                 "@type",
+			"cred":                 "https://www.w3.org/2018/credentials#",
+			"schema":               "http://schema.org/",
+			"VerifiableCredential": "cred:VerifiableCredential",
+			"issuer":               map[string]interface{}{"@id": "cred:issuer", "@type": "@id"},
+			"credentialSubject":    map[string]interface{}{"@id": "cred:credentialSubject", "@type": "@id"},
+			"name":                 "schema:name",
+			"degree":               "schema:degree",
+		},
+		"id":     "http://example.edu/credentials/1872",
+		"type":   []interface{}{"VerifiableCredential"},
+		"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+		"credentialSubject": map[string]interface{}{
+			"id":              "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			"name":            "Jayden Doe",
+			"degree":          "MIT",
+			"favouriteColour": "blue",
+		},
+		"undefinedTerm": "value",
+	}
+}
@@ -16,6 +16,7 @@ type SignatureSuite struct {
 	Signer         signer
 	Verifier       verifier
 	CompactedProof bool
+	StrictJSONLD   bool
 }
 
 type signer interface {
@@ -52,6 +53,14 @@ func WithCompactProof() Opt {
 	}
 }
 
+// WithStrictJSONLD makes the JSON-LD processing strict: the documents with terms which are not defined in
+// the context are rejected. By default (lenient processing) the undefined terms are dropped.
+func WithStrictJSONLD() Opt {
+	return func(opts *SignatureSuite) {
+		opts.StrictJSONLD = true
+	}
+}
+
 // InitSuiteOptions initializes signature suite with options.
 func InitSuiteOptions(suite *SignatureSuite, opts ...Opt) *SignatureSuite {
 	for _, opt := range opts {
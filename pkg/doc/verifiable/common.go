@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/xeipuuv/gojsonschema"
 
@@ -78,7 +79,11 @@ func NewDIDKeyResolver(vdriRegistry vdri.Registry) *DIDKeyResolver {
 }
 
 func (r *DIDKeyResolver) resolvePublicKey(issuerDID, keyID string) (*verifier.PublicKey, error) {
-	doc, err := r.vdriRegistry.Resolve(issuerDID)
+	return r.resolveKey(issuerDID, keyID)
+}
+
+func (r *DIDKeyResolver) resolveKey(issuerDID, keyID string, opts ...vdri.ResolveOpts) (*verifier.PublicKey, error) {
+	doc, err := r.vdriRegistry.Resolve(issuerDID, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("resolve DID %s: %w", issuerDID, err)
 	}
@@ -114,6 +119,15 @@ func (r *DIDKeyResolver) PublicKeyFetcher() PublicKeyFetcher {
 	return r.resolvePublicKey
 }
 
+// PublicKeyFetcherAt returns Public Key Fetcher resolving the DID document version which was current
+// at the given time (e.g. the issuance date of a historical credential), so the keys rotated since then
+// are still found. Resolution fails if no version of the DID document covers that time.
+func (r *DIDKeyResolver) PublicKeyFetcherAt(versionTime time.Time) PublicKeyFetcher {
+	return func(issuerID, keyID string) (*verifier.PublicKey, error) {
+		return r.resolveKey(issuerID, keyID, vdri.WithVersionTime(versionTime))
+	}
+}
+
 // Proof defines embedded proof of Verifiable Credential
 type Proof map[string]interface{}
 
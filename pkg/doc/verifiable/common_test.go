@@ -18,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/vdri"
@@ -272,6 +273,96 @@ func TestDIDKeyResolver_Resolve(t *testing.T) {
 	r.Nil(pubKey)
 }
 
+func TestDIDKeyResolver_PublicKeyFetcherAt(t *testing.T) {
+	r := require.New(t)
+
+	const issuerDID = "did:example:76e12ec712ebc6f1c221ebfeb1f"
+
+	oldPubKey, oldPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	r.NoError(err)
+
+	newPubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	r.NoError(err)
+
+	versionDoc := func(pubKey []byte) *did.Doc {
+		return &did.Doc{
+			ID: issuerDID,
+			PublicKey: []did.PublicKey{{
+				ID:    issuerDID + "#keys-" + keyID,
+				Type:  "Ed25519VerificationKey2018",
+				Value: pubKey,
+			}},
+		}
+	}
+
+	created := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	rotated := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// the key was rotated after the issuance of the credential
+	v := &mockvdri.MockVDRIRegistry{
+		ResolveFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+			resolveOpts := &vdriapi.ResolveDIDOpts{}
+			for _, opt := range opts {
+				opt(resolveOpts)
+			}
+
+			if resolveOpts.VersionTime == "" {
+				return versionDoc(newPubKey), nil
+			}
+
+			versionTime, err := time.Parse(time.RFC3339, resolveOpts.VersionTime)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case versionTime.Before(created):
+				return nil, vdriapi.ErrVersionNotFound
+			case versionTime.Before(rotated):
+				return versionDoc(oldPubKey), nil
+			default:
+				return versionDoc(newPubKey), nil
+			}
+		},
+	}
+
+	issued := time.Date(2010, 1, 1, 19, 23, 24, 0, time.UTC)
+	vc := &Credential{
+		Context: []string{baseContext},
+		Types:   []string{vcType},
+		Subject: "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		Issuer:  Issuer{ID: issuerDID},
+		Issued:  &issued,
+	}
+
+	jwtClaims, err := vc.JWTClaims(false)
+	r.NoError(err)
+
+	vcJWS, err := jwtClaims.MarshalJWS(EdDSA, getEd25519TestSigner(oldPrivKey), issuerDID+"#keys-"+keyID)
+	r.NoError(err)
+
+	resolver := NewDIDKeyResolver(v)
+
+	t.Run("verify against the document version current at issuance", func(t *testing.T) {
+		_, _, err = NewCredential([]byte(vcJWS), WithBaseContextValidation(),
+			WithPublicKeyFetcher(resolver.PublicKeyFetcherAt(issued)))
+		require.NoError(t, err)
+	})
+
+	t.Run("verify against the latest document version fails", func(t *testing.T) {
+		_, _, err = NewCredential([]byte(vcJWS), WithBaseContextValidation(),
+			WithPublicKeyFetcher(resolver.PublicKeyFetcher()))
+		require.Error(t, err)
+	})
+
+	t.Run("no document version at the given time", func(t *testing.T) {
+		_, _, err = NewCredential([]byte(vcJWS), WithBaseContextValidation(),
+			WithPublicKeyFetcher(resolver.PublicKeyFetcherAt(created.Add(-time.Hour))))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, vdriapi.ErrVersionNotFound))
+	})
+}
+
 func createDIDDoc() *did.Doc {
 	return createDIDDocWithKey()
 }
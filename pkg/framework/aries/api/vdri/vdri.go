@@ -8,6 +8,7 @@ package vdri
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"time"
 
@@ -17,9 +18,60 @@ import (
 // ErrNotFound is returned when a DID resolver does not find the DID.
 var ErrNotFound = errors.New("DID not found")
 
+// ErrInvalidProof is returned when the proof of a resolved DID document fails verification (see WithVerifyProof).
+var ErrInvalidProof = errors.New("DID document proof is invalid")
+
+// ErrResolutionTimeout is returned when a DID resolution does not complete within its timeout (see WithTimeout).
+var ErrResolutionTimeout = errors.New("DID resolution timed out")
+
+// PartialResultError is returned, along with the DID document resolved so far, when a DID resolution times out
+// and partial results are accepted (see WithPartialResult). It wraps ErrResolutionTimeout.
+type PartialResultError struct {
+	// Doc is the partially resolved DID document, eg: a DID document whose proof is not verified yet.
+	Doc *did.Doc
+	// ProofVerified is set if the proof of Doc is verified (see WithVerifyProof). A DID document whose proof
+	// verification did not complete must not be trusted.
+	ProofVerified bool
+	Err           error
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("partial DID resolution result : %s", e.Err)
+}
+
+// Unwrap returns the error which interrupted the resolution (ErrResolutionTimeout).
+func (e *PartialResultError) Unwrap() error {
+	return e.Err
+}
+
+// ErrVersionNotFound is returned when no version of the DID document matches the requested version id or time.
+var ErrVersionNotFound = errors.New("DID document version not found")
+
 // DIDCommServiceType default DID Communication service endpoint type
 const DIDCommServiceType = "did-communication"
 
+// X25519KeyAgreementKeyType is the public key type of the X25519 key agreement keys of the created DID documents
+const X25519KeyAgreementKeyType = "X25519KeyAgreementKey2019"
+
+// TrustStatus is the trust status of a resolved DID, consulted from a trust registry (see WithTrustRegistry).
+type TrustStatus string
+
+const (
+	// TrustStatusTrusted is the status of the DIDs trusted by the trust registry.
+	TrustStatusTrusted TrustStatus = "trusted"
+	// TrustStatusUntrusted is the status of the DIDs not trusted by the trust registry.
+	TrustStatusUntrusted TrustStatus = "untrusted"
+	// TrustStatusUnknown is the status of the DIDs whose trust could not be consulted, eg: the trust registry is
+	// unreachable.
+	TrustStatusUnknown TrustStatus = "unknown"
+)
+
+// TrustRegistry tells whether DIDs are trusted, eg: an allowlist of trusted issuer DIDs or a client of a trust
+// registry endpoint.
+type TrustRegistry interface {
+	IsTrusted(did string) (bool, error)
+}
+
 // Registry vdri registry
 type Registry interface {
 	Resolve(did string, opts ...ResolveOpts) (*did.Doc, error)
@@ -37,6 +89,12 @@ type VDRI interface {
 	Close() error
 }
 
+// DocBuilder is implemented by the VDRIs able to build the DID document they would create with Build, without
+// creating it, eg: without submitting it to a ledger, to validate the creation of a DID.
+type DocBuilder interface {
+	BuildDoc(pubKey *PubKey, opts ...DocOpts) (*did.Doc, error)
+}
+
 // ResultType input option can be used to request a certain type of result.
 type ResultType int
 
@@ -53,6 +111,13 @@ type ResolveDIDOpts struct {
 	VersionID   interface{}
 	VersionTime string
 	NoCache     bool
+	VerifyProof bool
+	// Timeout is the maximum duration of the resolution, unlimited if zero.
+	Timeout time.Duration
+	// PartialResult is set if the DID document resolved so far is returned when the resolution times out.
+	PartialResult bool
+	// TrustRegistry is consulted for the trust status of the resolved DID, if set.
+	TrustRegistry TrustRegistry
 }
 
 // ResolveOpts is a did resolve option
@@ -86,6 +151,42 @@ func WithNoCache(noCache bool) ResolveOpts {
 	}
 }
 
+// WithVerifyProof the verify proof input option can be used to verify the proof of the resolved DID document, if it
+// has one, against the key of its creator. The DID document is rejected with ErrInvalidProof if the proof fails.
+func WithVerifyProof(verifyProof bool) ResolveOpts {
+	return func(opts *ResolveDIDOpts) {
+		opts.VerifyProof = verifyProof
+	}
+}
+
+// WithTimeout the timeout input option can be used to limit the duration of the resolution, eg: when it involves
+// several backend calls. A resolution timing out fails with ErrResolutionTimeout, unless partial results are accepted
+// (see WithPartialResult).
+func WithTimeout(timeout time.Duration) ResolveOpts {
+	return func(opts *ResolveDIDOpts) {
+		opts.Timeout = timeout
+	}
+}
+
+// WithPartialResult the partial result input option can be used to get the DID document resolved so far when the
+// resolution times out (see WithTimeout), eg: a DID document whose proof verification did not complete. The DID
+// document is then returned with a *PartialResultError, flagging whether its proof is verified. A resolution timing out before any DID document is resolved
+// fails with ErrResolutionTimeout.
+func WithPartialResult(partialResult bool) ResolveOpts {
+	return func(opts *ResolveDIDOpts) {
+		opts.PartialResult = partialResult
+	}
+}
+
+// WithTrustRegistry the trust registry input option can be used to consult trustRegistry for the trust status of the
+// resolved DID, eg: to flag the credentials of untrusted issuers. Untrusted DIDs still resolve, the trust status is
+// returned along with the DID document by the resolutions annotating their results (eg: vdri.Registry.ResolveBatch).
+func WithTrustRegistry(trustRegistry TrustRegistry) ResolveOpts {
+	return func(opts *ResolveDIDOpts) {
+		opts.TrustRegistry = trustRegistry
+	}
+}
+
 // CreateDIDOpts holds the options for creating DID
 type CreateDIDOpts struct {
 	ServiceType     string
@@ -93,6 +194,11 @@ type CreateDIDOpts struct {
 	ServiceEndpoint string
 	RoutingKeys     []string
 	RequestBuilder  func([]byte) (io.Reader, error)
+	KeyAgreement    bool
+	// KeyAgreementKey is the base58 encoded X25519 key agreement key, a new key is created if it is not set
+	KeyAgreementKey string
+	// SigningKey is the base58 encoded signing key of the KMS, a new key is created if it is not set
+	SigningKey string
 }
 
 // DocOpts is a create DID option
@@ -134,6 +240,31 @@ func WithRequestBuilder(builder func(payload []byte) (io.Reader, error)) DocOpts
 	}
 }
 
+// WithKeyAgreement allows for adding an X25519 key agreement key to the DID document, created with the KMS
+// unless a key is supplied with WithKeyAgreementKey
+func WithKeyAgreement(keyAgreement bool) DocOpts {
+	return func(opts *CreateDIDOpts) {
+		opts.KeyAgreement = keyAgreement
+	}
+}
+
+// WithKeyAgreementKey allows for reusing an existing base58 encoded X25519 key as the key agreement key
+// of the DID document
+func WithKeyAgreementKey(base58PubKey string) DocOpts {
+	return func(opts *CreateDIDOpts) {
+		opts.KeyAgreement = true
+		opts.KeyAgreementKey = base58PubKey
+	}
+}
+
+// WithSigningKey allows for creating the DID document with an existing base58 encoded signing key of the KMS instead
+// of a new one, eg: the recipient key allocated to a connection by the router (see route.Service.AllocateKey)
+func WithSigningKey(base58PubKey string) DocOpts {
+	return func(opts *CreateDIDOpts) {
+		opts.SigningKey = base58PubKey
+	}
+}
+
 // PubKey contains public key type and value
 type PubKey struct {
 	Value string // base58 encoded
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package aries
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packager"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
 	jwe "github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/authcrypt"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jws"
 	legacy "github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/legacy/authcrypt"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/introduce"
@@ -54,8 +56,8 @@ func defFrameworkOpts(frameworkOpts *Aries) error {
 
 	// order is important as DIDExchange service depends on Route service and Introduce depends on DIDExchange
 	frameworkOpts.protocolSvcCreators = append(frameworkOpts.protocolSvcCreators,
-		newRouteSvc(), newExchangeSvc(), newIntroduceSvc(),
-		newIssueCredentialSvc(), newOutOfBandSvc(), newPresentProofSvc(),
+		newRouteSvc(frameworkOpts.routeOpts...), newExchangeSvc(frameworkOpts.didExchangeOpts...), newIntroduceSvc(),
+		newIssueCredentialSvc(), newOutOfBandSvc(frameworkOpts.outOfBandOpts...), newPresentProofSvc(),
 	)
 
 	if frameworkOpts.secretLock == nil && frameworkOpts.kmsCreator == nil {
@@ -74,9 +76,9 @@ func defFrameworkOpts(frameworkOpts *Aries) error {
 	return setAdditionalDefaultOpts(frameworkOpts)
 }
 
-func newExchangeSvc() api.ProtocolSvcCreator {
+func newExchangeSvc(opts ...didexchange.ServiceOption) api.ProtocolSvcCreator {
 	return func(prv api.Provider) (dispatcher.ProtocolService, error) {
-		return didexchange.New(prv)
+		return didexchange.New(prv, opts...)
 	}
 }
 
@@ -98,15 +100,26 @@ func newPresentProofSvc() api.ProtocolSvcCreator {
 	}
 }
 
-func newRouteSvc() api.ProtocolSvcCreator {
+func newRouteSvc(opts ...route.ServiceOption) api.ProtocolSvcCreator {
 	return func(prv api.Provider) (dispatcher.ProtocolService, error) {
-		return route.New(prv)
+		return route.New(prv, opts...)
 	}
 }
 
-func newOutOfBandSvc() api.ProtocolSvcCreator {
+func newOutOfBandSvc(opts ...outofband.ServiceOption) api.ProtocolSvcCreator {
 	return func(prv api.Provider) (dispatcher.ProtocolService, error) {
-		return outofband.New(prv)
+		return outofband.New(prv, opts...)
+	}
+}
+
+func newSignedPacker() packer.Creator {
+	return func(provider packer.Provider) (packer.Packer, error) {
+		signerProvider, ok := provider.(jws.Provider)
+		if !ok {
+			return nil, errors.New("signed messages packer requires a signer")
+		}
+
+		return jws.New(signerProvider), nil
 	}
 }
 
@@ -139,6 +152,7 @@ func setAdditionalDefaultOpts(frameworkOpts *Aries) error {
 			func(provider packer.Provider) (packer.Packer, error) {
 				return jwe.New(provider, jwe.XC20P)
 			},
+			newSignedPacker(),
 		}
 	}
 
@@ -8,12 +8,14 @@ package aries
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messenger"
 
 	"github.com/google/uuid"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/idgen"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
@@ -21,6 +23,9 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packager"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
@@ -28,6 +33,9 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/codec"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/migration"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
 	"github.com/hyperledger/aries-framework-go/pkg/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
 )
@@ -44,13 +52,20 @@ type Aries struct {
 	storeProvider storage.Provider
 	// TODO Rename transient store to protocol state store https://github.com/hyperledger/aries-framework-go/issues/835
 	transientStoreProvider storage.Provider
+	storageCodec           codec.Codec
+	idGenerator            idgen.IDGenerator
+	storageMigrations      []migration.Step
 	protocolSvcCreators    []api.ProtocolSvcCreator
+	didExchangeOpts        []didexchange.ServiceOption
+	routeOpts              []route.ServiceOption
+	outOfBandOpts          []outofband.ServiceOption
 	services               []dispatcher.ProtocolService
 	msgSvcProvider         api.MessageServiceProvider
 	outboundDispatcher     dispatcher.Outbound
 	messenger              service.MessengerHandler
 	outboundTransports     []transport.OutboundTransport
 	inboundTransports      []transport.InboundTransport
+	inboundQueue           *dispatcher.InboundQueue
 	legacyKMSCreator       api.KMSCreator
 	legacyKMS              api.CloseableKMS
 	kms                    kms.KeyManager
@@ -95,13 +110,46 @@ func New(opts ...Option) (*Aries, error) {
 		return nil, fmt.Errorf("default option initialization failed: %w", err)
 	}
 
+	// migrate the stored data before any service reads it
+	err = runStorageMigrations(frameworkOpts)
+	if err != nil {
+		return nil, err
+	}
+
 	// TODO: https://github.com/hyperledger/aries-framework-go/issues/212
 	//  Define clear relationship between framework and context.
 	//  Details - The code creates context without protocolServices. The protocolServicesCreators are dependent
 	//  on the context. The inbound transports require ctx.InboundMessageHandler(), which in-turn depends on
 	//  protocolServices. At the moment, there is a looping issue among these.
 
-	return initializeServices(frameworkOpts)
+	framework, err := initializeServices(frameworkOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	// the inbound queue workers are started once the framework is initialized, they are stopped by Close
+	if framework.inboundQueue != nil {
+		framework.inboundQueue.Start()
+	}
+
+	return framework, nil
+}
+
+func runStorageMigrations(frameworkOpts *Aries) error {
+	if len(frameworkOpts.storageMigrations) == 0 {
+		return nil
+	}
+
+	runner, err := migration.New(frameworkOpts.storageMigrations...)
+	if err != nil {
+		return fmt.Errorf("storage migrations registration failed: %w", err)
+	}
+
+	if _, err := runner.Run(frameworkOpts.storeProvider); err != nil {
+		return fmt.Errorf("storage migrations failed: %w", err)
+	}
+
+	return nil
 }
 
 func initializeServices(frameworkOpts *Aries) (*Aries, error) {
@@ -172,6 +220,26 @@ func WithInboundTransport(inboundTransport ...transport.InboundTransport) Option
 	}
 }
 
+// WithInboundQueue option handles the messages of the inbound transports through a queue of size messages, handled
+// by a pool of workers workers. Bursts of inbound messages are then handled with bounded concurrency, and the inbound
+// transports block while the queue is full. The queued messages are handled asynchronously: the inbound transports
+// don't get the handling errors, which are passed to the error handler of the queue if set (see
+// dispatcher.WithInboundErrorHandler), logged otherwise. The messages dispatched internally (eg: the attachments of
+// out-of-band requests) aren't queued. The workers are started once the framework is initialized, and stopped by
+// Aries.Close. See Aries.InboundQueueStats for the queue metrics.
+func WithInboundQueue(workers, size int, queueOpts ...dispatcher.InboundQueueOption) Option {
+	return func(opts *Aries) error {
+		q, err := dispatcher.NewInboundQueue(workers, size, queueOpts...)
+		if err != nil {
+			return err
+		}
+
+		opts.inboundQueue = q
+
+		return nil
+	}
+}
+
 // WithTransportReturnRoute injects transport return route option to the Aries framework. Acceptable values - "none",
 // "all" or "thread". RFC - https://github.com/hyperledger/aries-rfcs/tree/master/features/0092-transport-return-route.
 // Currently, framework supports "all" and "none" option with WebSocket transport ("thread" is not supported).
@@ -205,6 +273,36 @@ func WithTransientStoreProvider(prov storage.Provider) Option {
 	}
 }
 
+// WithStorageCodec sets the codec (codec.JSON, codec.CBOR or codec.Protobuf) of the records kept by the record stores
+// wrapped in a codec.Store, JSON by default: the connection records and invitations of the connection store. The other
+// record stores always use JSON. Records already stored with another codec can't be read anymore.
+func WithStorageCodec(c codec.Codec) Option {
+	return func(opts *Aries) error {
+		opts.storageCodec = c
+		return nil
+	}
+}
+
+// WithIDGenerator sets the generator of the IDs of the keysets stored by the default KMS and of the connection
+// records, eg: idgen.KSUID for time sortable IDs. By default, keyset IDs are random and connection IDs are UUIDs.
+// The generated IDs already used by a stored keyset or connection record are skipped, eg: the IDs of an
+// idgen.Sequential generator after a restart.
+func WithIDGenerator(g idgen.IDGenerator) Option {
+	return func(opts *Aries) error {
+		opts.idGenerator = g
+		return nil
+	}
+}
+
+// WithStorageMigrations registers storage migration steps, the steps not applied yet to the data kept by the
+// storage provider are run in version order when the framework starts.
+func WithStorageMigrations(steps ...migration.Step) Option {
+	return func(opts *Aries) error {
+		opts.storageMigrations = append(opts.storageMigrations, steps...)
+		return nil
+	}
+}
+
 // WithProtocols injects a protocol service to the Aries framework.
 func WithProtocols(protocolSvcCreator ...api.ProtocolSvcCreator) Option {
 	return func(opts *Aries) error {
@@ -213,6 +311,31 @@ func WithProtocols(protocolSvcCreator ...api.ProtocolSvcCreator) Option {
 	}
 }
 
+// WithDIDExchangeOptions configures the default DID exchange service, eg: didexchange.WithKeyAgreement.
+func WithDIDExchangeOptions(didExchangeOpts ...didexchange.ServiceOption) Option {
+	return func(opts *Aries) error {
+		opts.didExchangeOpts = append(opts.didExchangeOpts, didExchangeOpts...)
+		return nil
+	}
+}
+
+// WithRouteOptions configures the default route service, eg: route.WithDeliveryReceipts or route.WithGrantLease.
+func WithRouteOptions(routeOpts ...route.ServiceOption) Option {
+	return func(opts *Aries) error {
+		opts.routeOpts = append(opts.routeOpts, routeOpts...)
+		return nil
+	}
+}
+
+// WithOutOfBandOptions configures the default out-of-band service, eg: outofband.WithUnsignedRequests(false) to
+// reject the requests without signature.
+func WithOutOfBandOptions(outOfBandOpts ...outofband.ServiceOption) Option {
+	return func(opts *Aries) error {
+		opts.outOfBandOpts = append(opts.outOfBandOpts, outOfBandOpts...)
+		return nil
+	}
+}
+
 // WithLegacyKMS injects a LegacyKMS service to the Aries framework.
 func WithLegacyKMS(k api.KMSCreator) Option {
 	return func(opts *Aries) error {
@@ -281,6 +404,7 @@ func (a *Aries) Context() (*context.Provider, error) {
 		context.WithOutboundDispatcher(a.outboundDispatcher),
 		context.WithMessengerHandler(a.messenger),
 		context.WithOutboundTransports(a.outboundTransports...),
+		context.WithInboundTransports(a.inboundTransports...),
 		context.WithProtocolServices(a.services...),
 		context.WithLegacyKMS(a.legacyKMS),
 		context.WithSecretLock(a.secretLock),
@@ -289,6 +413,8 @@ func (a *Aries) Context() (*context.Provider, error) {
 		context.WithRouterEndpoint(routingEndpoint(a)),
 		context.WithStorageProvider(a.storeProvider),
 		context.WithTransientStorageProvider(a.transientStoreProvider),
+		context.WithStorageCodec(a.storageCodec),
+		context.WithIDGenerator(a.idGenerator),
 		context.WithPacker(a.primaryPacker, a.packers...),
 		context.WithPackager(a.packager),
 		context.WithVDRIRegistry(a.vdriRegistry),
@@ -298,6 +424,16 @@ func (a *Aries) Context() (*context.Provider, error) {
 	)
 }
 
+// InboundQueueStats returns the metrics of the inbound queue, nil if the inbound messages aren't queued (see
+// WithInboundQueue).
+func (a *Aries) InboundQueueStats() *dispatcher.InboundQueueStats {
+	if a.inboundQueue == nil {
+		return nil
+	}
+
+	return a.inboundQueue.Stats()
+}
+
 // Messenger returns messenger for sending messages through this agent framework
 // TODO should use dedicated messenger interface instead of Outbound dispatcher [Issue #1058]
 func (a *Aries) Messenger() service.Messenger {
@@ -306,6 +442,19 @@ func (a *Aries) Messenger() service.Messenger {
 
 // Close frees resources being maintained by the framework.
 func (a *Aries) Close() error {
+	// the queued inbound messages are handled before the stores are closed
+	if a.inboundQueue != nil {
+		a.inboundQueue.Close()
+	}
+
+	for _, svc := range a.services {
+		if closer, ok := svc.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				return fmt.Errorf("failed to close the %s service: %w", svc.Name(), err)
+			}
+		}
+	}
+
 	if a.legacyKMS != nil {
 		err := a.legacyKMS.Close()
 		if err != nil {
@@ -366,6 +515,7 @@ func createKMS(frameworkOpts *Aries) error {
 	ctx, err := context.New(
 		context.WithStorageProvider(frameworkOpts.storeProvider),
 		context.WithSecretLock(frameworkOpts.secretLock),
+		context.WithIDGenerator(frameworkOpts.idGenerator),
 	)
 	if err != nil {
 		return fmt.Errorf("create context failed: %w", err)
@@ -437,17 +587,27 @@ func createOutboundDispatcher(frameworkOpts *Aries) error {
 		context.WithPackager(frameworkOpts.packager),
 		context.WithTransportReturnRoute(frameworkOpts.transportReturnRoute),
 		context.WithVDRIRegistry(frameworkOpts.vdriRegistry),
+		context.WithStorageProvider(frameworkOpts.storeProvider),
+		context.WithTransientStorageProvider(frameworkOpts.transientStoreProvider),
+		context.WithStorageCodec(frameworkOpts.storageCodec),
 	)
 	if err != nil {
 		return fmt.Errorf("context creation failed: %w", err)
 	}
 
-	frameworkOpts.outboundDispatcher = dispatcher.NewOutbound(ctx)
+	connectionLookup, err := connection.NewLookup(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create connection lookup: %w", err)
+	}
+
+	frameworkOpts.outboundDispatcher = dispatcher.NewOutbound(ctx, dispatcher.WithDIDCommVersionLookup(connectionLookup))
 
 	return nil
 }
 
 func startTransports(frameworkOpts *Aries) error {
+	// only the messages of the inbound transports are queued, the messages dispatched internally (eg: by the
+	// out-of-band service) are handled synchronously so their callers get the handling errors
 	ctx, err := context.New(
 		context.WithLegacyKMS(frameworkOpts.legacyKMS),
 		context.WithCrypto(frameworkOpts.crypto),
@@ -456,6 +616,7 @@ func startTransports(frameworkOpts *Aries) error {
 		context.WithAriesFrameworkID(frameworkOpts.id),
 		context.WithMessageServiceProvider(frameworkOpts.msgSvcProvider),
 		context.WithMessengerHandler(frameworkOpts.messenger),
+		context.WithInboundQueue(frameworkOpts.inboundQueue),
 	)
 	if err != nil {
 		return fmt.Errorf("context creation failed: %w", err)
@@ -484,6 +645,8 @@ func loadServices(frameworkOpts *Aries) error {
 		context.WithMessengerHandler(frameworkOpts.messenger),
 		context.WithStorageProvider(frameworkOpts.storeProvider),
 		context.WithTransientStorageProvider(frameworkOpts.transientStoreProvider),
+		context.WithStorageCodec(frameworkOpts.storageCodec),
+		context.WithIDGenerator(frameworkOpts.idGenerator),
 		context.WithLegacyKMS(frameworkOpts.legacyKMS),
 		context.WithCrypto(frameworkOpts.crypto),
 		context.WithPackager(frameworkOpts.packager),
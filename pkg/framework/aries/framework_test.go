@@ -20,6 +20,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -29,6 +30,8 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api"
@@ -48,7 +51,10 @@ import (
 	locallock "github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/hkdf"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	storageapi "github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/codec"
 	"github.com/hyperledger/aries-framework-go/pkg/storage/leveldb"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/migration"
 	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
 )
 
@@ -85,6 +91,56 @@ func TestFramework(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("test framework new - with inbound queue", func(t *testing.T) {
+		path, cleanup := generateTempDir(t)
+		defer cleanup()
+		dbPath = path
+
+		_, err := New(WithInboundQueue(0, 10))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid number of inbound queue workers 0")
+
+		aries, err := New(WithInboundTransport(&mockInboundTransport{}))
+		require.NoError(t, err)
+		require.Nil(t, aries.InboundQueueStats())
+		require.NoError(t, aries.Close())
+
+		aries, err = New(WithInboundQueue(4, 10), WithInboundTransport(&mockInboundTransport{}))
+		require.NoError(t, err)
+		require.Equal(t, &dispatcher.InboundQueueStats{Workers: 4, Capacity: 10}, aries.InboundQueueStats())
+
+		// the internal callers aren't queued, they get the handling errors
+		ctx, err := aries.Context()
+		require.NoError(t, err)
+		require.Error(t, ctx.InboundMessageHandler()([]byte("invalid message"), "", ""))
+		require.Equal(t, &dispatcher.InboundQueueStats{Workers: 4, Capacity: 10}, aries.InboundQueueStats())
+
+		require.NoError(t, aries.Close())
+
+		// the errors of the queued messages are passed to the error handler
+		handleErrs := make(chan error, 1)
+		inbound := &mockInboundTransport{}
+
+		aries, err = New(WithInboundQueue(1, 10, dispatcher.WithInboundErrorHandler(
+			func(err error, message []byte, myDID, theirDID string) {
+				require.Equal(t, []byte("invalid message"), message)
+				handleErrs <- err
+			})), WithInboundTransport(inbound))
+		require.NoError(t, err)
+
+		require.NoError(t, inbound.prov.InboundMessageHandler()([]byte("invalid message"), "", ""))
+
+		select {
+		case err := <-handleErrs:
+			require.Error(t, err)
+		case <-time.After(time.Second):
+			require.FailNow(t, "inbound message handling error not passed to the error handler")
+		}
+
+		require.NoError(t, aries.Close())
+		require.EqualValues(t, 1, aries.InboundQueueStats().Failed)
+	})
+
 	t.Run("test framework new - with default outbound dispatcher", func(t *testing.T) {
 		path, cleanup := generateTempDir(t)
 		defer cleanup()
@@ -211,6 +267,71 @@ func TestFramework(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("test protocol svc - with DID exchange options", func(t *testing.T) {
+		var configured *didexchange.Service
+
+		aries, err := New(WithInboundTransport(&mockInboundTransport{}),
+			WithDIDExchangeOptions(didexchange.WithKeyAgreement(true), func(svc *didexchange.Service) {
+				configured = svc
+			}))
+		require.NoError(t, err)
+
+		ctx, err := aries.Context()
+		require.NoError(t, err)
+
+		svc, err := ctx.Service(didexchange.DIDExchange)
+		require.NoError(t, err)
+		require.Equal(t, svc, configured)
+
+		require.NoError(t, aries.Close())
+	})
+
+	t.Run("test protocol svc - with route options", func(t *testing.T) {
+		var configured *route.Service
+
+		aries, err := New(WithInboundTransport(&mockInboundTransport{}),
+			WithRouteOptions(route.WithGrantLease(time.Hour), func(svc *route.Service) {
+				configured = svc
+			}))
+		require.NoError(t, err)
+
+		ctx, err := aries.Context()
+		require.NoError(t, err)
+
+		svc, err := ctx.Service(route.Coordination)
+		require.NoError(t, err)
+		require.Equal(t, svc, configured)
+
+		require.NoError(t, aries.Close())
+	})
+
+	t.Run("test protocol svc - with out-of-band options", func(t *testing.T) {
+		var configured *outofband.Service
+
+		aries, err := New(WithInboundTransport(&mockInboundTransport{}),
+			WithOutOfBandOptions(outofband.WithUnsignedRequests(false), func(svc *outofband.Service) {
+				configured = svc
+			}))
+		require.NoError(t, err)
+
+		ctx, err := aries.Context()
+		require.NoError(t, err)
+
+		svc, err := ctx.Service(outofband.Name)
+		require.NoError(t, err)
+		require.Equal(t, svc, configured)
+
+		// unsigned requests are rejected
+		_, err = configured.AcceptRequest(&outofband.Request{
+			ID:      "request",
+			Type:    outofband.RequestMsgType,
+			Service: []interface{}{"did:example:inviter"},
+		})
+		require.True(t, errors.Is(err, outofband.ErrInvalidSignature))
+
+		require.NoError(t, aries.Close())
+	})
+
 	t.Run("test protocol svc - with user provided protocol", func(t *testing.T) {
 		newMockSvc := func(prv api.Provider) (dispatcher.ProtocolService, error) {
 			return &mockdidexchange.MockDIDExchangeSvc{
@@ -555,6 +676,51 @@ func TestFramework(t *testing.T) {
 		require.Equal(t, s, aries.transientStoreProvider)
 	})
 
+	t.Run("test storage codec - with user provided codec", func(t *testing.T) {
+		path, cleanup := generateTempDir(t)
+		defer cleanup()
+		dbPath = path
+
+		aries, err := New(WithInboundTransport(&mockInboundTransport{}), WithStorageCodec(codec.CBOR{}))
+		require.NoError(t, err)
+		require.Equal(t, codec.CBOR{}, aries.storageCodec)
+
+		ctx, err := aries.Context()
+		require.NoError(t, err)
+		require.Equal(t, codec.CBOR{}, ctx.StorageCodec())
+		require.NoError(t, aries.Close())
+	})
+
+	t.Run("test storage migrations", func(t *testing.T) {
+		storeProvider := storage.NewMockStoreProvider()
+		runs := 0
+		step := migration.Step{Version: 1, Migrate: func(storageapi.Provider) error {
+			runs++
+			return nil
+		}}
+
+		for i := 0; i < 2; i++ {
+			aries, err := New(WithInboundTransport(&mockInboundTransport{}), WithStoreProvider(storeProvider),
+				WithStorageMigrations(step))
+			require.NoError(t, err)
+			require.NoError(t, aries.Close())
+		}
+
+		// the migration is applied when the framework first starts
+		require.Equal(t, 1, runs)
+
+		_, err := New(WithInboundTransport(&mockInboundTransport{}), WithStoreProvider(storeProvider),
+			WithStorageMigrations(migration.Step{Version: 2, Migrate: func(storageapi.Provider) error {
+				return errors.New("test")
+			}}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "storage migrations failed: migration 2 failed: test")
+
+		_, err = New(WithInboundTransport(&mockInboundTransport{}), WithStorageMigrations(step, step))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "storage migrations registration failed")
+	})
+
 	t.Run("test new with outbound transport service", func(t *testing.T) {
 		path, cleanup := generateTempDir(t)
 		defer cleanup()
@@ -723,6 +889,7 @@ func getServerPort(server net.Listener) int {
 type mockInboundTransport struct {
 	startError error
 	stopError  error
+	prov       transport.Provider
 }
 
 func (m *mockInboundTransport) Start(prov transport.Provider) error {
@@ -730,6 +897,8 @@ func (m *mockInboundTransport) Start(prov transport.Provider) error {
 		return m.startError
 	}
 
+	m.prov = prov
+
 	return nil
 }
 
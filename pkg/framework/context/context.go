@@ -9,10 +9,12 @@ package context
 import (
 	"fmt"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/idgen"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messenger"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api"
@@ -21,6 +23,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/codec"
 )
 
 // Provider supplies the framework configuration to client objects.
@@ -29,6 +32,8 @@ type Provider struct {
 	msgSvcProvider         api.MessageServiceProvider
 	storeProvider          storage.Provider
 	transientStoreProvider storage.Provider
+	storageCodec           codec.Codec
+	idGenerator            idgen.IDGenerator
 	legacyKMS              legacykms.KMS
 	kms                    kms.KeyManager
 	secretLock             secretlock.Service
@@ -41,9 +46,11 @@ type Provider struct {
 	outboundDispatcher     dispatcher.Outbound
 	messenger              service.MessengerHandler
 	outboundTransports     []transport.OutboundTransport
+	inboundTransports      []transport.InboundTransport
 	vdriRegistry           vdriapi.Registry
 	transportReturnRoute   string
 	frameworkID            string
+	inboundQueue           *dispatcher.InboundQueue
 }
 
 // New instantiates a new context provider.
@@ -70,6 +77,11 @@ func (p *Provider) OutboundTransports() []transport.OutboundTransport {
 	return p.outboundTransports
 }
 
+// InboundTransports returns the inbound transports.
+func (p *Provider) InboundTransports() []transport.InboundTransport {
+	return p.inboundTransports
+}
+
 // Service return protocol service
 func (p *Provider) Service(id string) (interface{}, error) {
 	for _, v := range p.services {
@@ -140,24 +152,58 @@ func (p *Provider) RouterEndpoint() string {
 	return p.routerEndpoint
 }
 
+// receiptHandler is implemented by the messengers supporting delivery receipts (see messenger.SendWithReceipt).
+type receiptHandler interface {
+	HandleAck(msg service.DIDCommMsgMap, myDID, theirDID string) (bool, error)
+	AckIfRequested(msg service.DIDCommMsgMap, myDID, theirDID string) error
+}
+
 func (p *Provider) tryToHandle(svc service.InboundHandler, msg service.DIDCommMsgMap, myDID, theirDID string) error {
 	if err := p.messenger.HandleInbound(msg, myDID, theirDID); err != nil {
 		return fmt.Errorf("messenger HandleInbound: %w", err)
 	}
 
 	_, err := svc.HandleInbound(msg, myDID, theirDID)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// the message is processed, acknowledge it if requested
+	if rh, ok := p.messenger.(receiptHandler); ok {
+		err = rh.AckIfRequested(msg, myDID, theirDID)
+		if err != nil {
+			return fmt.Errorf("messenger ack: %w", err)
+		}
+	}
+
+	return nil
 }
 
-// InboundMessageHandler return an inbound message handler.
+// InboundMessageHandler return an inbound message handler. The messages are handled through the inbound queue,
+// if one is set.
 func (p *Provider) InboundMessageHandler() transport.InboundMessageHandler {
+	if p.inboundQueue != nil {
+		return p.inboundQueue.Handler(p.handleInbound())
+	}
+
+	return p.handleInbound()
+}
+
+func (p *Provider) handleInbound() transport.InboundMessageHandler {
 	return func(message []byte, myDID, theirDID string) error {
 		msg, err := service.ParseDIDCommMsgMap(message)
 		if err != nil {
 			return err
 		}
 
+		// the acks awaited by the messenger (see messenger.SendWithReceipt) are consumed, the others are dispatched
+		if rh, ok := p.messenger.(receiptHandler); ok && msg.Type() == messenger.AckMsgType {
+			consumed, err := rh.HandleAck(msg, myDID, theirDID)
+			if err != nil || consumed {
+				return err
+			}
+		}
+
 		// find the service which accepts the message type
 		for _, svc := range p.services {
 			if svc.Accept(msg.Type()) {
@@ -196,6 +242,16 @@ func (p *Provider) TransientStorageProvider() storage.Provider {
 	return p.transientStoreProvider
 }
 
+// StorageCodec returns the codec of the connection records, nil if the default (JSON) is used.
+func (p *Provider) StorageCodec() codec.Codec {
+	return p.storageCodec
+}
+
+// IDGenerator returns the generator of the IDs of the keys and records, nil if the default IDs are used.
+func (p *Provider) IDGenerator() idgen.IDGenerator {
+	return p.idGenerator
+}
+
 // VDRIRegistry returns a vdri registry
 func (p *Provider) VDRIRegistry() vdriapi.Registry {
 	return p.vdriRegistry
@@ -214,6 +270,14 @@ func (p *Provider) AriesFrameworkID() string {
 // ProviderOption configures the framework.
 type ProviderOption func(opts *Provider) error
 
+// WithInboundTransports injects the inbound transports into the context.
+func WithInboundTransports(transports ...transport.InboundTransport) ProviderOption {
+	return func(opts *Provider) error {
+		opts.inboundTransports = transports
+		return nil
+	}
+}
+
 // WithOutboundTransports injects an outbound transports into the context.
 func WithOutboundTransports(transports ...transport.OutboundTransport) ProviderOption {
 	return func(opts *Provider) error {
@@ -326,6 +390,22 @@ func WithTransientStorageProvider(s storage.Provider) ProviderOption {
 	}
 }
 
+// WithStorageCodec injects the codec of the connection records into the context.
+func WithStorageCodec(c codec.Codec) ProviderOption {
+	return func(opts *Provider) error {
+		opts.storageCodec = c
+		return nil
+	}
+}
+
+// WithIDGenerator injects the generator of the IDs of the keys and records into the context.
+func WithIDGenerator(g idgen.IDGenerator) ProviderOption {
+	return func(opts *Provider) error {
+		opts.idGenerator = g
+		return nil
+	}
+}
+
 // WithPackager injects a packager into the context.
 func WithPackager(p commontransport.Packager) ProviderOption {
 	return func(opts *Provider) error {
@@ -363,3 +443,13 @@ func WithMessageServiceProvider(msv api.MessageServiceProvider) ProviderOption {
 		return nil
 	}
 }
+
+// WithInboundQueue injects the queue the inbound messages are handled through into the context. It is meant for the
+// context of the inbound transports only: InboundMessageHandler then returns once the message is queued, without
+// the handling error, so the contexts of the internal callers must not set it.
+func WithInboundQueue(q *dispatcher.InboundQueue) ProviderOption {
+	return func(opts *Provider) error {
+		opts.inboundQueue = q
+		return nil
+	}
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	serviceMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/common/service"
 	mockdidcomm "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm"
 	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
@@ -31,6 +32,7 @@ import (
 	mocklegacykms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
 	"github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/codec"
 )
 
 func TestNewProvider(t *testing.T) {
@@ -172,6 +174,103 @@ func TestNewProvider(t *testing.T) {
 		require.EqualError(t, errors.Unwrap(err), errTest.Error())
 	})
 
+	t.Run("test inbound message handler with inbound queue", func(t *testing.T) {
+		errTest := errors.New("test")
+
+		messengerHandler := serviceMocks.NewMockMessengerHandler(ctrl)
+		messengerHandler.EXPECT().
+			HandleInbound(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(errTest).
+			Times(1)
+
+		q, err := dispatcher.NewInboundQueue(1, 1)
+		require.NoError(t, err)
+
+		q.Start()
+
+		ctx, err := New(
+			WithProtocolServices(&mockdidexchange.MockDIDExchangeSvc{}),
+			WithMessageServiceProvider(msghandler.NewMockMsgServiceProvider()),
+			WithMessengerHandler(messengerHandler),
+			WithInboundQueue(q),
+		)
+		require.NoError(t, err)
+
+		inboundHandler := ctx.InboundMessageHandler()
+
+		// the message is queued, its handling error is not returned
+		err = inboundHandler([]byte(`
+		{
+			"@frameworkID": "5678876542345",
+			"@type": "valid-message-type"
+		}`), "", "")
+		require.NoError(t, err)
+
+		q.Close()
+		require.EqualValues(t, 1, q.Stats().Failed)
+	})
+
+	t.Run("test inbound acks and ack requests handled by the messenger", func(t *testing.T) {
+		messengerHandler := serviceMocks.NewMockMessengerHandler(ctrl)
+		messengerHandler.EXPECT().HandleInbound(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		receipts := &receiptMessenger{MockMessengerHandler: messengerHandler}
+
+		var dispatched []string
+
+		ctx, err := New(WithProtocolServices(&mockdidexchange.MockDIDExchangeSvc{
+			AcceptFunc: func(msgType string) bool {
+				return msgType == "valid-message-type" || msgType == "https://didcomm.org/notification/1.0/ack"
+			},
+			HandleFunc: func(msg service.DIDCommMsg) (string, error) {
+				dispatched = append(dispatched, msg.ID())
+
+				return "", nil
+			},
+		}), WithMessageServiceProvider(msghandler.NewMockMsgServiceProvider()), WithMessengerHandler(receipts))
+		require.NoError(t, err)
+
+		inboundHandler := ctx.InboundMessageHandler()
+
+		// the awaited ack is consumed by the messenger, not handled by the services
+		err = inboundHandler([]byte(`{
+			"@id": "ack-id",
+			"@type": "https://didcomm.org/notification/1.0/ack",
+			"~thread": {"thid": "awaited-msg-id"}
+		}`), "did1", "did2")
+		require.NoError(t, err)
+		require.Equal(t, []string{"ack-id"}, receipts.acks)
+		require.Empty(t, dispatched)
+
+		// the other acks are handled by the services
+		messengerHandler.EXPECT().HandleInbound(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		err = inboundHandler([]byte(`{
+			"@id": "other-ack-id",
+			"@type": "https://didcomm.org/notification/1.0/ack",
+			"~thread": {"thid": "msg-id"}
+		}`), "did1", "did2")
+		require.NoError(t, err)
+		require.Equal(t, []string{"ack-id"}, receipts.acks)
+		require.Equal(t, []string{"other-ack-id"}, dispatched)
+
+		// the message is acknowledged once handled by the service
+		err = inboundHandler([]byte(`{
+			"@id": "msg-id",
+			"@type": "valid-message-type",
+			"~please_ack": {}
+		}`), "did1", "did2")
+		require.NoError(t, err)
+		require.Equal(t, []string{"msg-id"}, receipts.acked)
+
+		receipts.ackErr = errors.New("ack error")
+
+		messengerHandler.EXPECT().HandleInbound(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		err = inboundHandler([]byte(`{"@id": "msg-id", "@type": "valid-message-type"}`), "did1", "did2")
+		require.EqualError(t, err, "messenger ack: ack error")
+	})
+
 	t.Run("test new with message service", func(t *testing.T) {
 		const sampleMsgType = "generic-msg-type-2.0"
 
@@ -215,6 +314,82 @@ func TestNewProvider(t *testing.T) {
 		}
 	})
 
+	t.Run("test DIDComm v2 messages routed to the service handlers", func(t *testing.T) {
+		const (
+			routeMsgType = "https://didcomm.org/routecoordination/1.0/keylist_update"
+			basicMsgType = "https://didcomm.org/basicmessage/1.0/message"
+		)
+
+		messenger := serviceMocks.NewMockMessengerHandler(ctrl)
+		messenger.EXPECT().HandleInbound(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		routed := make(chan service.DIDCommMsg, 1)
+		basic := make(chan service.DIDCommMsg, 1)
+
+		mockMsgHandler := msghandler.NewMockMsgServiceProvider()
+		prov, err := New(WithProtocolServices(&mockdidexchange.MockDIDExchangeSvc{
+			ProtocolName: "routecoordination",
+			AcceptFunc: func(msgType string) bool {
+				return msgType == routeMsgType
+			},
+			HandleFunc: func(msg service.DIDCommMsg) (string, error) {
+				routed <- msg
+				return "", nil
+			},
+		}), WithMessageServiceProvider(mockMsgHandler), WithMessengerHandler(messenger))
+		require.NoError(t, err)
+
+		require.NoError(t, mockMsgHandler.Register(&generic.MockMessageSvc{
+			HandleFunc: func(msg *service.DIDCommMsg) (string, error) {
+				basic <- *msg
+				return "", nil
+			},
+			AcceptFunc: func(msgType string, purpose []string) bool {
+				return msgType == basicMsgType
+			},
+		}))
+
+		inboundHandler := prov.InboundMessageHandler()
+
+		err = inboundHandler([]byte(fmt.Sprintf(`{
+			"id": "route-msg-id",
+			"type": "%s",
+			"from": "did2",
+			"to": ["did1"],
+			"body": {"updates": [{"recipient_key": "key", "action": "add"}]}
+		}`, routeMsgType)), "did1", "did2")
+		require.NoError(t, err)
+
+		select {
+		case msg := <-routed:
+			require.Equal(t, "route-msg-id", msg.ID())
+			require.Equal(t, routeMsgType, msg.Type())
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "route service handler not called")
+		}
+
+		err = inboundHandler([]byte(fmt.Sprintf(`{
+			"id": "basic-msg-id",
+			"type": "%s",
+			"body": {"content": "hello"}
+		}`, basicMsgType)), "did1", "did2")
+		require.NoError(t, err)
+
+		select {
+		case msg := <-basic:
+			payload := struct {
+				Content string `json:"content"`
+			}{}
+			require.NoError(t, msg.Decode(&payload))
+			require.Equal(t, "basic-msg-id", msg.ID())
+			require.Equal(t, "hello", payload.Content)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "basic message service handler not called")
+		}
+
+		require.Empty(t, routed)
+	})
+
 	t.Run("test new with legacyKMS and packager service", func(t *testing.T) {
 		prov, err := New(
 			WithLegacyKMS(&mocklegacykms.CloseableKMS{SignMessageValue: []byte("mockValue")}),
@@ -301,6 +476,16 @@ func TestNewProvider(t *testing.T) {
 		require.Equal(t, s, prov.TransientStorageProvider())
 	})
 
+	t.Run("test new with storage codec", func(t *testing.T) {
+		prov, err := New()
+		require.NoError(t, err)
+		require.Nil(t, prov.StorageCodec())
+
+		prov, err = New(WithStorageCodec(codec.CBOR{}))
+		require.NoError(t, err)
+		require.Equal(t, codec.CBOR{}, prov.StorageCodec())
+	})
+
 	t.Run("test new with vdri", func(t *testing.T) {
 		r := &mockvdri.MockVDRIRegistry{}
 		prov, err := New(WithVDRIRegistry(r))
@@ -343,3 +528,41 @@ func TestNewProvider(t *testing.T) {
 		require.Empty(t, prov)
 	})
 }
+
+// receiptMessenger is a messenger supporting delivery receipts.
+type receiptMessenger struct {
+	*serviceMocks.MockMessengerHandler
+	acks   []string
+	acked  []string
+	ackErr error
+}
+
+func (m *receiptMessenger) HandleAck(msg service.DIDCommMsgMap, myDID, theirDID string) (bool, error) {
+	thID, err := msg.ThreadID()
+	if err != nil {
+		return false, err
+	}
+
+	// only the acks of the awaited messages are consumed
+	if thID != "awaited-msg-id" {
+		return false, nil
+	}
+
+	m.acks = append(m.acks, msg.ID())
+
+	return true, nil
+}
+
+func (m *receiptMessenger) AckIfRequested(msg service.DIDCommMsgMap, myDID, theirDID string) error {
+	if m.ackErr != nil {
+		return m.ackErr
+	}
+
+	if _, ok := msg["~please_ack"]; !ok {
+		return nil
+	}
+
+	m.acked = append(m.acked, msg.ID())
+
+	return nil
+}
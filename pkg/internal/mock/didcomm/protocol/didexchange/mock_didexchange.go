@@ -38,6 +38,8 @@ type MockDIDExchangeSvc struct {
 	ImplicitInvitationErr    error
 	RespondToFunc            func(*didexchange.OOBInvitation) (string, error)
 	SaveFunc                 func(invitation *didexchange.OOBInvitation) error
+	UpdateRoutingFunc        func(connectionID string, routingKeys []string, endpoint string) error
+	SetRequestClaimerFunc    func(claimer didexchange.RequestClaimer)
 }
 
 // HandleInbound msg
@@ -121,6 +123,15 @@ func (m *MockDIDExchangeSvc) AcceptExchangeRequest(connectionID, publicDID, labe
 	return nil
 }
 
+// UpdateConnectionRouting updates the routing of the connection.
+func (m *MockDIDExchangeSvc) UpdateConnectionRouting(connectionID string, routingKeys []string, endpoint string) error {
+	if m.UpdateRoutingFunc != nil {
+		return m.UpdateRoutingFunc(connectionID, routingKeys, endpoint)
+	}
+
+	return nil
+}
+
 // AcceptInvitation accepts/approves exchange invitation.
 func (m *MockDIDExchangeSvc) AcceptInvitation(connectionID, publicDID, label string) error {
 	if m.AcceptError != nil {
@@ -157,6 +168,13 @@ func (m *MockDIDExchangeSvc) SaveInvitation(i *didexchange.OOBInvitation) error
 	return nil
 }
 
+// SetRequestClaimer sets the claimer of the out-of-band requests.
+func (m *MockDIDExchangeSvc) SetRequestClaimer(claimer didexchange.RequestClaimer) {
+	if m.SetRequestClaimerFunc != nil {
+		m.SetRequestClaimerFunc(claimer)
+	}
+}
+
 // MockProvider is provider for DIDExchange Service
 type MockProvider struct {
 	StoreProvider          *mockstore.MockStoreProvider
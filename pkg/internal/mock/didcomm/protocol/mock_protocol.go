@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package protocol
 
 import (
+	"github.com/hyperledger/aries-framework-go/pkg/common/idgen"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
@@ -18,6 +19,7 @@ import (
 	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/codec"
 )
 
 // MockProvider is provider for DIDExchange Service
@@ -31,6 +33,8 @@ type MockProvider struct {
 	ServiceErr             error
 	ServiceMap             map[string]interface{}
 	InboundMsgHandler      transport.InboundMessageHandler
+	CustomCodec            codec.Codec
+	CustomIDGenerator      idgen.IDGenerator
 }
 
 // OutboundDispatcher is mock outbound dispatcher for DID exchange service
@@ -60,6 +64,16 @@ func (p *MockProvider) TransientStorageProvider() storage.Provider {
 	return mockstore.NewMockStoreProvider()
 }
 
+// StorageCodec is mock codec of the stored records, nil if not set
+func (p *MockProvider) StorageCodec() codec.Codec {
+	return p.CustomCodec
+}
+
+// IDGenerator is mock generator of the record IDs, nil if not set
+func (p *MockProvider) IDGenerator() idgen.IDGenerator {
+	return p.CustomIDGenerator
+}
+
 // Signer is mock signer for DID exchange service
 func (p *MockProvider) Signer() legacykms.Signer {
 	return &mockkms.CloseableKMS{}
@@ -25,8 +25,14 @@ type MockRouteSvc struct {
 	ConfigErr          error
 	AddKeyErr          error
 	UnregisterErr      error
+	RenewErr           error
 	ConnectionID       string
 	GetConnectionIDErr error
+	KeysValue          []string
+	KeysErr            error
+	RemoveKeysErr      error
+	AllocateKeyFunc    func(connectionID string) (string, error)
+	ReleaseKeyFunc     func(connectionID string, deleteKey bool) error
 }
 
 // HandleInbound msg
@@ -79,11 +85,26 @@ func (m *MockRouteSvc) Unregister() error {
 	return m.UnregisterErr
 }
 
+// Renew renews the route lease with the router
+func (m *MockRouteSvc) Renew() error {
+	return m.RenewErr
+}
+
 // AddKey adds agents recKey to the router
 func (m *MockRouteSvc) AddKey(recKey string) error {
 	return m.AddKeyErr
 }
 
+// Keys returns the recipient keys registered with the router
+func (m *MockRouteSvc) Keys(connectionID string) ([]string, error) {
+	return m.KeysValue, m.KeysErr
+}
+
+// RemoveKeys removes all the recipient keys registered with the router
+func (m *MockRouteSvc) RemoveKeys(connectionID string) error {
+	return m.RemoveKeysErr
+}
+
 // Config gives back the router configuration
 func (m *MockRouteSvc) Config() (*route.Config, error) {
 	if m.ConfigErr != nil {
@@ -106,3 +127,21 @@ func (m *MockRouteSvc) GetConnection() (string, error) {
 
 	return m.ConnectionID, nil
 }
+
+// AllocateKey allocates a recipient key to the connection, the router is not registered by default
+func (m *MockRouteSvc) AllocateKey(connectionID string) (string, error) {
+	if m.AllocateKeyFunc != nil {
+		return m.AllocateKeyFunc(connectionID)
+	}
+
+	return "", route.ErrRouterNotRegistered
+}
+
+// ReleaseKey releases the recipient key allocated to the connection
+func (m *MockRouteSvc) ReleaseKey(connectionID string, deleteKey bool) error {
+	if m.ReleaseKeyFunc != nil {
+		return m.ReleaseKeyFunc(connectionID, deleteKey)
+	}
+
+	return nil
+}
@@ -8,7 +8,9 @@ package provider
 import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -19,6 +21,8 @@ type Provider struct {
 	ServiceErr                    error
 	ServiceMap                    map[string]interface{}
 	KMSValue                      legacykms.KeyManager
+	SignerValue                   legacykms.Signer
+	CustomKMS                     kms.KeyManager
 	ServiceEndpointValue          string
 	StorageProviderValue          storage.Provider
 	TransientStorageProviderValue storage.Provider
@@ -26,6 +30,8 @@ type Provider struct {
 	PackerValue                   packer.Packer
 	OutboundDispatcherValue       dispatcher.Outbound
 	VDRIRegistryValue             vdriapi.Registry
+	OutboundTransportsValue       []transport.OutboundTransport
+	InboundTransportsValue        []transport.InboundTransport
 }
 
 // Service return service
@@ -46,6 +52,16 @@ func (p *Provider) LegacyKMS() legacykms.KeyManager {
 	return p.KMSValue
 }
 
+// Signer returns a legacyKMS signing service
+func (p *Provider) Signer() legacykms.Signer {
+	return p.SignerValue
+}
+
+// KMS returns a KMS instance
+func (p *Provider) KMS() kms.KeyManager {
+	return p.CustomKMS
+}
+
 // ServiceEndpoint returns the service endpoint
 func (p *Provider) ServiceEndpoint() string {
 	return p.ServiceEndpointValue
@@ -85,3 +101,13 @@ func (p *Provider) OutboundDispatcher() dispatcher.Outbound {
 func (p *Provider) VDRIRegistry() vdriapi.Registry {
 	return p.VDRIRegistryValue
 }
+
+// OutboundTransports returns the outbound transports
+func (p *Provider) OutboundTransports() []transport.OutboundTransport {
+	return p.OutboundTransportsValue
+}
+
+// InboundTransports returns the inbound transports
+func (p *Provider) InboundTransports() []transport.InboundTransport {
+	return p.InboundTransportsValue
+}
@@ -7,6 +7,8 @@
 package kms
 
 import (
+	"crypto"
+
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -22,6 +24,96 @@ type KeyManager interface {
 	Rotate(kt KeyType, keyID string) (string, interface{}, error)
 }
 
+// KeyCreatorWithOptions is implemented by the key managers supporting key options (eg: LocalKMS). It isn't part of
+// KeyManager so the existing key managers don't have to support them: callers passing key options type-assert the
+// key manager to KeyCreatorWithOptions.
+type KeyCreatorWithOptions interface {
+	// CreateWithOptions creates a new key/keyset/key handle for the type kt with the key options opts
+	CreateWithOptions(kt KeyType, opts ...KeyOption) (string, interface{}, error)
+	// RotateWithOptions rotates the key referenced by keyID to a new key with type kt and the key options opts
+	RotateWithOptions(kt KeyType, keyID string, opts ...KeyOption) (string, interface{}, error)
+}
+
+// KeyOpts holds the options of a key creation or rotation.
+type KeyOpts struct {
+	externalRef     string
+	retainPrevious  bool
+	signatureHash   crypto.Hash
+	signatureDomain string
+}
+
+// ExternalRef returns the external reference id to tag the key with.
+func (k *KeyOpts) ExternalRef() string {
+	return k.externalRef
+}
+
+// RetainPrevious returns true if the previous keyset of a rotated key must be kept.
+func (k *KeyOpts) RetainPrevious() bool {
+	return k.retainPrevious
+}
+
+// SignatureHash returns the hash function the created signing key must hash messages with, 0 for the default of
+// the key type.
+func (k *KeyOpts) SignatureHash() crypto.Hash {
+	return k.signatureHash
+}
+
+// SignatureDomain returns the signature domain the created signing key is bound to, empty if the key is bound to
+// the domain of its first signature made in a domain.
+func (k *KeyOpts) SignatureDomain() string {
+	return k.signatureDomain
+}
+
+// KeyOption configures a key creation or rotation.
+type KeyOption func(opts *KeyOpts)
+
+// WithRetainPrevious option keeps the keyset of a rotated key under its previous ID, so the signatures and
+// ciphertexts of the previous key can still be verified and decrypted using the previous ID.
+func WithRetainPrevious() KeyOption {
+	return func(opts *KeyOpts) {
+		opts.retainPrevious = true
+	}
+}
+
+// WithSignatureHash option creates an ECDSA signing key hashing the messages with hash (crypto.SHA256, crypto.SHA384
+// or crypto.SHA512) instead of the default hash function of its curve.
+func WithSignatureHash(hash crypto.Hash) KeyOption {
+	return func(opts *KeyOpts) {
+		opts.signatureHash = hash
+	}
+}
+
+// WithSignatureDomain option creates a signing key bound to the signature domain domain: the key signs and verifies
+// only in this domain, so its signatures can't be reused in another domain, nor made without domain.
+func WithSignatureDomain(domain string) KeyOption {
+	return func(opts *KeyOpts) {
+		opts.signatureDomain = domain
+	}
+}
+
+// WithExternalRef option tags the created key with ref, the id of the key in an external system.
+// The external reference must be unique.
+func WithExternalRef(ref string) KeyOption {
+	return func(opts *KeyOpts) {
+		opts.externalRef = ref
+	}
+}
+
+// PublicKey is an exported public key, with the algorithms it is intended for.
+type PublicKey struct {
+	// ID of the key in the KMS
+	ID string `json:"id"`
+	// KeyType of the key
+	KeyType KeyType `json:"keyType"`
+	// Bytes of the public key, as exported by ExportPubKeyBytes
+	Bytes []byte `json:"bytes"`
+	// JWA is the JWS algorithm of the key (RFC 7518, eg: "ES256"), empty if the key matches none
+	JWA string `json:"jwa,omitempty"`
+	// LDSuite is the linked data signature suite of the key (eg: "Ed25519Signature2018"), empty if the key matches
+	// none
+	LDSuite string `json:"ldSuite,omitempty"`
+}
+
 // Provider for KeyManager builder/constructor
 type Provider interface {
 	StorageProvider() storage.Provider
@@ -52,6 +144,18 @@ const (
 	ED25519 = "ED25519"
 	// RSA key type value
 	RSA = "RSA"
+	// ECIESHKDFAES128GCM key type value (hybrid encryption: ECDH over NIST P-256, HKDF-SHA256 and AES128-GCM)
+	ECIESHKDFAES128GCM = "ECIESHKDFAES128GCM"
+	// AES128GCMHKDF4KB key type value (streaming AEAD with 4KB ciphertext segments)
+	AES128GCMHKDF4KB = "AES128GCMHKDF4KB"
+	// AES256GCMHKDF4KB key type value (streaming AEAD with 4KB ciphertext segments)
+	AES256GCMHKDF4KB = "AES256GCMHKDF4KB"
+	// AES256GCMHKDF1MB key type value (streaming AEAD with 1MB ciphertext segments)
+	AES256GCMHKDF1MB = "AES256GCMHKDF1MB"
+	// ECDefault key class value (the default elliptic curve signing key type of the KMS)
+	ECDefault = "ECDefault"
+	// AEADDefault key class value (the default AEAD key type of the KMS)
+	AEADDefault = "AEADDefault"
 )
 
 // KeyType represents a key type supported by the KMS
@@ -80,4 +184,16 @@ const (
 	RSAType = KeyType(RSA)
 	// HMACSHA256Tag256Type key type value
 	HMACSHA256Tag256Type = KeyType("HMACSHA256Tag256")
+	// ECIESHKDFAES128GCMType key type value
+	ECIESHKDFAES128GCMType = KeyType(ECIESHKDFAES128GCM)
+	// AES128GCMHKDF4KBType key type value
+	AES128GCMHKDF4KBType = KeyType(AES128GCMHKDF4KB)
+	// AES256GCMHKDF4KBType key type value
+	AES256GCMHKDF4KBType = KeyType(AES256GCMHKDF4KB)
+	// AES256GCMHKDF1MBType key type value
+	AES256GCMHKDF1MBType = KeyType(AES256GCMHKDF1MB)
+	// ECDefaultType abstract key type value, resolved by the KMS to its configured default EC key type
+	ECDefaultType = KeyType(ECDefault)
+	// AEADDefaultType abstract key type value, resolved by the KMS to its configured default AEAD key type
+	AEADDefaultType = KeyType(AEADDefault)
 )
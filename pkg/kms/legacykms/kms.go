@@ -86,6 +86,23 @@ func (w *BaseKMS) CreateKeySet() (string, string, error) {
 	return encBase58Pub, sigBase58Pub, nil
 }
 
+// DeleteKeySet deletes the key pairs set of the signature or encryption public key verKey (base58 encoded).
+func (w *BaseKMS) DeleteKeySet(verKey string) error {
+	kpCombo, err := w.getKeyPairSet(verKey)
+	if err != nil {
+		return fmt.Errorf("delete key set: %w", err)
+	}
+
+	// the keypair combo is stored for both its encryption and signature public keys (see CreateKeySet)
+	for _, pubKey := range [][]byte{kpCombo.EncKeyPair.Pub, kpCombo.SigKeyPair.Pub} {
+		if err := w.keystore.Delete(base58.Encode(pubKey)); err != nil {
+			return fmt.Errorf("delete key set: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // createEncKeyPair will convert sigKp into an encKeyPair - for now it's a key conversion operation.
 // it can be modified to be generated independently from sigKp - this has implications on
 // the LegacyKMS store and the Packager/Packer as they use Signature keys as arguments and use the converted
@@ -9,6 +9,7 @@ package legacykms
 import (
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -99,6 +100,41 @@ func TestBaseKMS_CreateKey(t *testing.T) {
 	})
 }
 
+func TestBaseKMS_DeleteKeySet(t *testing.T) {
+	t.Run("test delete key set - success", func(t *testing.T) {
+		k, err := New(newMockKMSProvider(mockstorage.NewMockStoreProvider()))
+		require.NoError(t, err)
+
+		encKey, verKey, err := k.CreateKeySet()
+		require.NoError(t, err)
+
+		require.NoError(t, k.DeleteKeySet(verKey))
+
+		for _, key := range []string{encKey, verKey} {
+			_, err = k.getKeyPairSet(key)
+			require.True(t, errors.Is(err, cryptoutil.ErrKeyNotFound))
+		}
+
+		err = k.DeleteKeySet(verKey)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, cryptoutil.ErrKeyNotFound))
+	})
+
+	t.Run("test delete key set - store error", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: make(map[string][]byte), ErrDelete: fmt.Errorf("delete error")}
+
+		k, err := New(newMockKMSProvider(&mockstorage.MockStoreProvider{Store: store}))
+		require.NoError(t, err)
+
+		_, verKey, err := k.CreateKeySet()
+		require.NoError(t, err)
+
+		err = k.DeleteKeySet(verKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "delete error")
+	})
+}
+
 func TestBaseKMS_Close(t *testing.T) {
 	t.Run("test success", func(t *testing.T) {
 		k, err := New(newMockKMSProvider(&mockstorage.MockStoreProvider{}))
@@ -0,0 +1,173 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// archiveType is the type of the archives produced by ExportArchive.
+const archiveType = "LocalKMSArchive/1.0"
+
+// archiveHeader is the first entry of an archive.
+type archiveHeader struct {
+	Type         string `json:"type"`
+	MasterKeyURI string `json:"masterKeyURI"`
+}
+
+// archiveRecord is a keystore record of an archive.
+type archiveRecord struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// ExportArchive writes to w an archive of the keystore: the keysets, encrypted with the master key of the KMS like
+// in the keystore, their metadata and external references, the rotation markers and the pooled keys. The archive is
+// written as it is read from the keystore, one JSON entry per record. It can be imported with ImportArchive by a KMS
+// using the same master key.
+func (l *LocalKMS) ExportArchive(w io.Writer) error {
+	err := l.exportArchive(w)
+	l.audit(&AuditRecord{Operation: AuditOpExportArchive}, err)
+
+	return err
+}
+
+func (l *LocalKMS) exportArchive(w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	err := enc.Encode(&archiveHeader{Type: archiveType, MasterKeyURI: l.masterKeyURI})
+	if err != nil {
+		return fmt.Errorf("export archive: %w", err)
+	}
+
+	for _, prefix := range l.archivePrefixes() {
+		err = l.exportRecords(enc, prefix)
+		if err != nil {
+			return fmt.Errorf("export archive: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (l *LocalKMS) exportRecords(enc *json.Encoder, prefix string) error {
+	itr := l.store.Iterator(prefix, prefix+"~")
+	defer itr.Release()
+
+	for itr.Next() {
+		err := enc.Encode(&archiveRecord{Key: string(itr.Key()), Value: itr.Value()})
+		if err != nil {
+			return err
+		}
+	}
+
+	return itr.Error()
+}
+
+// ImportArchive imports the archive r exported by ExportArchive into the keystore, and returns the number of
+// imported keysets. The archive must be exported by a KMS using the same master key, and its records must not
+// exist in the keystore (eg: the archive is restored to an empty keystore).
+func (l *LocalKMS) ImportArchive(r io.Reader) (int, error) {
+	imported, err := l.importArchive(r)
+	l.audit(&AuditRecord{Operation: AuditOpImportArchive}, err)
+
+	return imported, err
+}
+
+func (l *LocalKMS) importArchive(r io.Reader) (int, error) {
+	done, err := l.beginWrite()
+	if err != nil {
+		return 0, fmt.Errorf("import archive: %w", err)
+	}
+
+	defer done()
+
+	dec := json.NewDecoder(r)
+
+	header := &archiveHeader{}
+
+	err = dec.Decode(header)
+	if err != nil {
+		return 0, fmt.Errorf("import archive: invalid header: %w", err)
+	}
+
+	if header.Type != archiveType {
+		return 0, fmt.Errorf("import archive: unsupported archive type '%s'", header.Type)
+	}
+
+	if header.MasterKeyURI != l.masterKeyURI {
+		return 0, fmt.Errorf("import archive: archive master key '%s' does not match the kms master key",
+			header.MasterKeyURI)
+	}
+
+	imported := 0
+
+	for {
+		record := &archiveRecord{}
+
+		err = dec.Decode(record)
+		if errors.Is(err, io.EOF) {
+			return imported, nil
+		}
+
+		if err != nil {
+			return imported, fmt.Errorf("import archive: invalid record: %w", err)
+		}
+
+		err = l.importRecord(record)
+		if err != nil {
+			return imported, fmt.Errorf("import archive: %w", err)
+		}
+
+		if strings.HasPrefix(record.Key, l.keySetIDPrefix()) {
+			imported++
+		}
+	}
+}
+
+func (l *LocalKMS) importRecord(record *archiveRecord) error {
+	if !l.isArchived(record.Key) {
+		return fmt.Errorf("record '%s' is not a keystore record", record.Key)
+	}
+
+	_, err := l.store.Get(record.Key)
+	if err == nil {
+		return fmt.Errorf("record '%s' already exists", record.Key)
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return err
+	}
+
+	return l.store.Put(record.Key, record.Value)
+}
+
+// archivePrefixes returns the key prefixes of the keystore records in the archives: the keysets, their metadata,
+// external references and links, the markers of the keysets retained by a rotation and the records of the pooled
+// keys.
+func (l *LocalKMS) archivePrefixes() []string {
+	return []string{
+		l.keySetIDPrefix(), metadataKeyPrefix, externalRefKeyPrefix, linkedKeySetPrefix, rotatedKeySetPrefix,
+		keyPoolPrefix,
+	}
+}
+
+func (l *LocalKMS) isArchived(key string) bool {
+	for _, prefix := range l.archivePrefixes() {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,148 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_Archive(t *testing.T) {
+	secretLock := createMasterKeyAndSecretLock(t)
+
+	newKMS := func(t *testing.T, store *mockstorage.MockStore) *LocalKMS {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		})
+		require.NoError(t, err)
+
+		return k
+	}
+
+	msg := []byte("message")
+
+	source := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+	signingKeyID, _, err := source.CreateWithOptions(kms.ED25519Type, kms.WithExternalRef("ext-1"))
+	require.NoError(t, err)
+
+	aeadKeyID, _, err := source.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	sig, err := source.SignWithKey(signingKeyID, msg)
+	require.NoError(t, err)
+
+	archive := &bytes.Buffer{}
+	require.NoError(t, source.ExportArchive(archive))
+
+	t.Run("test import archive into an empty keystore", func(t *testing.T) {
+		target := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		imported, err := target.ImportArchive(bytes.NewReader(archive.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, 2, imported)
+
+		_, err = target.Get(aeadKeyID)
+		require.NoError(t, err)
+
+		require.NoError(t, target.VerifyWithKey(signingKeyID, sig, msg))
+
+		found, err := target.GetByExternalRef("ext-1")
+		require.NoError(t, err)
+		require.Equal(t, signingKeyID, found)
+	})
+
+	t.Run("test import archive of rotated and pooled keys", func(t *testing.T) {
+		source := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		keyID, _, err := source.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		rotatedID, _, err := source.RotateWithOptions(kms.ED25519Type, keyID, kms.WithRetainPrevious())
+		require.NoError(t, err)
+
+		pooledKey, err := source.createPooledKey(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		archive := &bytes.Buffer{}
+		require.NoError(t, source.ExportArchive(archive))
+
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		target := newKMS(t, store)
+
+		imported, err := target.ImportArchive(archive)
+		require.NoError(t, err)
+		require.Equal(t, 3, imported)
+
+		history, err := target.RotationHistory(rotatedID)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		require.Equal(t, keyID, history[0].KeyID)
+
+		// the retained keyset is still marked as rotated
+		_, _, err = target.RotateWithOptions(kms.ED25519Type, keyID, kms.WithRetainPrevious())
+		require.Error(t, err)
+
+		conflictErr := &ConflictError{}
+		require.True(t, errors.As(err, &conflictErr))
+
+		// the pooled key is in the pool of a LocalKMS using the imported keystore
+		pooled, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		}, WithKeyPool(kms.AES256GCMType, KeyPoolConfig{Size: 1}))
+		require.NoError(t, err)
+		require.Equal(t, 1, pooled.KeyPoolAvailable(kms.AES256GCMType))
+
+		handedOut, _, err := pooled.CreateFromPool(kms.AES256GCMType)
+		require.NoError(t, err)
+		require.Equal(t, pooledKey.keyID, handedOut)
+	})
+
+	t.Run("test import archive with existing records", func(t *testing.T) {
+		_, err := source.ImportArchive(bytes.NewReader(archive.Bytes()))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "already exists")
+	})
+
+	t.Run("test import invalid archives", func(t *testing.T) {
+		target := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		_, err := target.ImportArchive(strings.NewReader("not json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid header")
+
+		_, err = target.ImportArchive(strings.NewReader(`{"type":"other"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported archive type")
+
+		_, err = target.ImportArchive(strings.NewReader(`{"type":"` + archiveType + `","masterKeyURI":"other"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match the kms master key")
+
+		_, err = target.ImportArchive(strings.NewReader(`{"type":"` + archiveType + `","masterKeyURI":"` +
+			testMasterKeyURI + `"}` + "\n" + `{"key":"other","value":""}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not a keystore record")
+	})
+
+	t.Run("test export archive with iterator error", func(t *testing.T) {
+		target := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}, ErrItr: errors.New("itr error")})
+
+		err := target.ExportArchive(&bytes.Buffer{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "itr error")
+	})
+}
@@ -0,0 +1,144 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// LocalKMS operations recorded in the audit records.
+const (
+	AuditOpCreate              = "create"
+	AuditOpGet                 = "get"
+	AuditOpRotate              = "rotate"
+	AuditOpExportPubKeyBytes   = "export_pub_key_bytes"
+	AuditOpExportPubKeyPEM     = "export_pub_key_pem"
+	AuditOpExportPubKey        = "export_pub_key"
+	AuditOpPubKeyBytesToHandle = "pub_key_bytes_to_handle"
+	AuditOpPubKeyPEMToHandle   = "pub_key_pem_to_handle"
+	AuditOpSealKey             = "seal_key"
+	AuditOpUnsealKey           = "unseal_key"
+	AuditOpVerify              = "verify"
+	AuditOpStats               = "stats"
+	AuditOpHealthCheck         = "health_check"
+	AuditOpEncryptStream       = "encrypt_stream"
+	AuditOpDecryptStream       = "decrypt_stream"
+	AuditOpDeriveSigningKey    = "derive_signing_key"
+	AuditOpDeriveAndStore      = "derive_and_store"
+	AuditOpSign                = "sign"
+	AuditOpVerifySignature     = "verify_signature"
+	AuditOpVerifyBatch         = "verify_batch"
+	AuditOpCanUnwrap           = "can_unwrap"
+	AuditOpWrapKey             = "wrap_key"
+	AuditOpUnwrapKey           = "unwrap_key"
+	AuditOpExportArchive       = "export_archive"
+	AuditOpImportArchive       = "import_archive"
+	AuditOpCreateLinkedKeySet  = "create_linked_key_set"
+	AuditOpCreateFromPool      = "create_from_pool"
+	AuditOpRefillKeyPool       = "refill_key_pool"
+	AuditOpKeyFingerprint      = "key_fingerprint"
+	AuditOpPause               = "pause"
+	AuditOpResume              = "resume"
+	AuditOpRewrapAll           = "rewrap_all"
+	AuditOpExportPubKeySet     = "export_pub_key_set"
+	AuditOpImportPublicKeySet  = "import_public_key_set"
+	AuditOpKeyThumbprint       = "key_thumbprint"
+	AuditOpEscrowKey           = "escrow_key"
+	AuditOpRecoverKey          = "recover_key"
+)
+
+// Outcomes of LocalKMS operations recorded in the audit records.
+const (
+	AuditOutcomeSuccess  = "success"
+	AuditOutcomeNotFound = "not_found"
+	AuditOutcomeFailure  = "failure"
+)
+
+// AuditRecord is the audit record of a LocalKMS operation. It never includes key material.
+type AuditRecord struct {
+	Caller    string      `json:"caller,omitempty"`
+	Operation string      `json:"operation"`
+	KeyID     string      `json:"keyID,omitempty"`
+	NewKeyID  string      `json:"newKeyID,omitempty"`
+	KeyType   kms.KeyType `json:"keyType,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+	Outcome   string      `json:"outcome"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// AuditLogger records every LocalKMS operation, on success and on failure. The context is the one the LocalKMS
+// is bound to with LocalKMS.WithContext, it carries the caller identity (see WithCallerIdentity).
+type AuditLogger interface {
+	Log(ctx context.Context, record *AuditRecord)
+}
+
+type callerIdentityKey struct{}
+
+// WithCallerIdentity returns a copy of ctx carrying the caller identity to be recorded in the audit records.
+func WithCallerIdentity(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerIdentityKey{}, caller)
+}
+
+// CallerIdentity returns the caller identity carried by ctx or an empty string if there is none.
+func CallerIdentity(ctx context.Context) string {
+	caller, _ := ctx.Value(callerIdentityKey{}).(string)
+
+	return caller
+}
+
+// WithAuditLogger option is for setting the logger recording the LocalKMS operations for auditing.
+func WithAuditLogger(auditLogger AuditLogger) Option {
+	return func(opts *LocalKMS) {
+		opts.auditLogger = auditLogger
+	}
+}
+
+// WithContext returns a copy of the LocalKMS passing ctx to the audit logger, ctx is expected to carry the caller
+// identity. In multi-tenant mode, the copy is bound to the master key URI of the tenant of ctx (see
+// WithMasterKeyURIResolver).
+func (l *LocalKMS) WithContext(ctx context.Context) *LocalKMS {
+	c := *l
+	c.ctx = ctx
+
+	if c.keyURIResolver != nil {
+		c.bindTenant()
+	}
+
+	return &c
+}
+
+func (l *LocalKMS) audit(record *AuditRecord, err error) {
+	if l.auditLogger == nil {
+		return
+	}
+
+	record.Caller = CallerIdentity(l.ctx)
+	record.Timestamp = time.Now().UTC()
+	record.Outcome = auditOutcome(err)
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	l.auditLogger.Log(l.ctx, record)
+}
+
+func auditOutcome(err error) string {
+	switch {
+	case err == nil:
+		return AuditOutcomeSuccess
+	case errors.Is(err, storage.ErrDataNotFound):
+		return AuditOutcomeNotFound
+	default:
+		return AuditOutcomeFailure
+	}
+}
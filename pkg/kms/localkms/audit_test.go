@@ -0,0 +1,124 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+type recordingAuditLogger struct {
+	mu      sync.Mutex
+	records []*AuditRecord
+	callers []string
+}
+
+func (r *recordingAuditLogger) Log(ctx context.Context, record *AuditRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = append(r.records, record)
+	r.callers = append(r.callers, CallerIdentity(ctx))
+}
+
+func newAuditedKMS(t *testing.T, auditLogger AuditLogger) *LocalKMS {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	}, WithAuditLogger(auditLogger))
+	require.NoError(t, err)
+
+	return kmsService
+}
+
+func TestLocalKMS_AuditLogger(t *testing.T) {
+	t.Run("test failed Get() is audited with not found outcome", func(t *testing.T) {
+		auditLogger := &recordingAuditLogger{}
+		kmsService := newAuditedKMS(t, auditLogger).WithContext(
+			WithCallerIdentity(context.Background(), "alice"))
+
+		_, err := kmsService.Get("unknown")
+		require.Error(t, err)
+
+		require.Len(t, auditLogger.records, 1)
+		record := auditLogger.records[0]
+		require.Equal(t, "alice", record.Caller)
+		require.Equal(t, "alice", auditLogger.callers[0])
+		require.Equal(t, AuditOpGet, record.Operation)
+		require.Equal(t, "unknown", record.KeyID)
+		require.Equal(t, AuditOutcomeNotFound, record.Outcome)
+		require.NotEmpty(t, record.Error)
+		require.False(t, record.Timestamp.IsZero())
+	})
+
+	t.Run("test successful operations are audited without key material", func(t *testing.T) {
+		auditLogger := &recordingAuditLogger{}
+		kmsService := newAuditedKMS(t, auditLogger).WithContext(
+			WithCallerIdentity(context.Background(), "bob"))
+
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = kmsService.Get(keyID)
+		require.NoError(t, err)
+
+		pubKey, err := kmsService.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		_, err = kmsService.PubKeyBytesToHandle(pubKey, kms.ED25519Type)
+		require.NoError(t, err)
+
+		newKeyID, _, err := kmsService.Rotate(kms.ED25519Type, keyID)
+		require.NoError(t, err)
+
+		operations := []string{AuditOpCreate, AuditOpGet, AuditOpExportPubKeyBytes, AuditOpPubKeyBytesToHandle,
+			AuditOpRotate}
+		require.Len(t, auditLogger.records, len(operations))
+
+		for i, record := range auditLogger.records {
+			require.Equal(t, operations[i], record.Operation)
+			require.Equal(t, AuditOutcomeSuccess, record.Outcome)
+			require.Equal(t, "bob", record.Caller)
+			require.Empty(t, record.Error)
+
+			recordBytes, err := json.Marshal(record)
+			require.NoError(t, err)
+			require.NotContains(t, string(recordBytes), string(pubKey))
+		}
+
+		require.Equal(t, keyID, auditLogger.records[0].KeyID)
+		require.Equal(t, kms.ED25519Type, auditLogger.records[0].KeyType)
+		require.Equal(t, keyID, auditLogger.records[4].KeyID)
+		require.Equal(t, newKeyID, auditLogger.records[4].NewKeyID)
+	})
+
+	t.Run("test failed Create() is audited with failure outcome", func(t *testing.T) {
+		auditLogger := &recordingAuditLogger{}
+		kmsService := newAuditedKMS(t, auditLogger)
+
+		_, _, err := kmsService.Create("unsupported")
+		require.Error(t, err)
+
+		require.Len(t, auditLogger.records, 1)
+		require.Equal(t, AuditOpCreate, auditLogger.records[0].Operation)
+		require.Equal(t, AuditOutcomeFailure, auditLogger.records[0].Outcome)
+		require.Empty(t, auditLogger.records[0].Caller)
+	})
+
+	t.Run("test audit outcome", func(t *testing.T) {
+		require.Equal(t, AuditOutcomeSuccess, auditOutcome(nil))
+		require.Equal(t, AuditOutcomeFailure, auditOutcome(errors.New("failure")))
+	})
+}
@@ -0,0 +1,83 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/tink"
+)
+
+// WrapKey encrypts dataKey, a symmetric data key generated by the application for envelope encryption, with the
+// AEAD key kekID used as the key-encryption key (one of the AES128GCM, AES256GCMNoPrefix, AES256GCM,
+// ChaCha20Poly1305 or XChaCha20Poly1305 key types). Only the wrapped data key should be kept along with the data it
+// encrypts, it is unwrapped with UnwrapKey. Like with cloud KMSs, the data key never needs to be stored in clear and
+// the key-encryption key can be rotated: the rotated key (see Rotate) still unwraps the data keys wrapped before the
+// rotation.
+func (l *LocalKMS) WrapKey(kekID string, dataKey []byte) ([]byte, error) {
+	wrapped, err := l.wrapKey(kekID, dataKey)
+	l.audit(&AuditRecord{Operation: AuditOpWrapKey, KeyID: kekID}, err)
+
+	return wrapped, err
+}
+
+func (l *LocalKMS) wrapKey(kekID string, dataKey []byte) ([]byte, error) {
+	if len(dataKey) == 0 {
+		return nil, errors.New("wrap key: data key is empty")
+	}
+
+	kek, err := l.keyEncryptionKey(kekID)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := kek.Encrypt(dataKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("wrap key: %w", err)
+	}
+
+	return wrapped, nil
+}
+
+// UnwrapKey decrypts the data key wrapped by WrapKey with the key-encryption key kekID.
+func (l *LocalKMS) UnwrapKey(kekID string, wrapped []byte) ([]byte, error) {
+	dataKey, err := l.unwrapKey(kekID, wrapped)
+	l.audit(&AuditRecord{Operation: AuditOpUnwrapKey, KeyID: kekID}, err)
+
+	return dataKey, err
+}
+
+func (l *LocalKMS) unwrapKey(kekID string, wrapped []byte) ([]byte, error) {
+	kek, err := l.keyEncryptionKey(kekID)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := kek.Decrypt(wrapped, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap key: %w", err)
+	}
+
+	return dataKey, nil
+}
+
+// keyEncryptionKey returns the AEAD primitive of the key kekID.
+func (l *LocalKMS) keyEncryptionKey(kekID string) (tink.AEAD, error) {
+	kh, err := l.getKeySet(kekID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := aead.New(kh)
+	if err != nil {
+		return nil, fmt.Errorf("key %s is not an AEAD key-encryption key: %w", kekID, err)
+	}
+
+	return p, nil
+}
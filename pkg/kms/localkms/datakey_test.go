@@ -0,0 +1,108 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_WrapKey(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	dataKey := make([]byte, 32)
+	_, err = rand.Read(dataKey)
+	require.NoError(t, err)
+
+	for _, kt := range []kms.KeyType{
+		kms.AES128GCMType, kms.AES256GCMNoPrefixType, kms.AES256GCMType,
+		kms.ChaCha20Poly1305Type, kms.XChaCha20Poly1305Type,
+	} {
+		kt := kt
+
+		t.Run("test wrap and unwrap a random data key with "+string(kt), func(t *testing.T) {
+			kekID, _, err := kmsService.Create(kt)
+			require.NoError(t, err)
+
+			wrapped, err := kmsService.WrapKey(kekID, dataKey)
+			require.NoError(t, err)
+			require.NotContains(t, string(wrapped), string(dataKey))
+
+			unwrapped, err := kmsService.UnwrapKey(kekID, wrapped)
+			require.NoError(t, err)
+			require.Equal(t, dataKey, unwrapped)
+		})
+	}
+
+	t.Run("test unwrap a data key after the key-encryption key is rotated", func(t *testing.T) {
+		kekID, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		wrapped, err := kmsService.WrapKey(kekID, dataKey)
+		require.NoError(t, err)
+
+		rotatedID, _, err := kmsService.Rotate(kms.AES256GCMType, kekID)
+		require.NoError(t, err)
+
+		unwrapped, err := kmsService.UnwrapKey(rotatedID, wrapped)
+		require.NoError(t, err)
+		require.Equal(t, dataKey, unwrapped)
+	})
+
+	t.Run("test unwrap with another key-encryption key or a tampered wrapped key fails", func(t *testing.T) {
+		kekID, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		otherKEKID, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		wrapped, err := kmsService.WrapKey(kekID, dataKey)
+		require.NoError(t, err)
+
+		_, err = kmsService.UnwrapKey(otherKEKID, wrapped)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unwrap key")
+
+		wrapped[len(wrapped)-1] ^= 0x01
+		_, err = kmsService.UnwrapKey(kekID, wrapped)
+		require.Error(t, err)
+	})
+
+	t.Run("test wrap errors", func(t *testing.T) {
+		kekID, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		_, err = kmsService.WrapKey(kekID, nil)
+		require.EqualError(t, err, "wrap key: data key is empty")
+
+		sigKeyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = kmsService.WrapKey(sigKeyID, dataKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not an AEAD key-encryption key")
+
+		_, err = kmsService.UnwrapKey(sigKeyID, dataKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not an AEAD key-encryption key")
+
+		_, err = kmsService.WrapKey("unknown", dataKey)
+		require.Error(t, err)
+
+		_, err = kmsService.UnwrapKey("unknown", dataKey)
+		require.Error(t, err)
+	})
+}
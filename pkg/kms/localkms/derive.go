@@ -0,0 +1,351 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	aesgcmpb "github.com/google/tink/go/proto/aes_gcm_go_proto"
+	chacha20poly1305pb "github.com/google/tink/go/proto/chacha20_poly1305_go_proto"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	ed25519pb "github.com/google/tink/go/proto/ed25519_go_proto"
+	hmacpb "github.com/google/tink/go/proto/hmac_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	xchacha20poly1305pb "github.com/google/tink/go/proto/xchacha20_poly1305_go_proto"
+	"github.com/google/tink/go/signature"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// derivedSigningKeyInfo binds the derived keys to their purpose so they differ from any other key derived from the
+// same base key.
+const derivedSigningKeyInfo = "aries-framework-go/localkms/derived-signing-key"
+
+// derivedKeyInfo prefixes the info of the keys derived by DeriveAndStore, followed by their key type.
+const derivedKeyInfo = "aries-framework-go/localkms/derived-key/"
+
+// keyIDSize is the size in bytes of the keyset key IDs.
+const keyIDSize = 4
+
+// DeriveOption configures the derivation of a signing key.
+type DeriveOption func(opts *deriveOpts)
+
+type deriveOpts struct {
+	store bool
+}
+
+// WithDerivedKeyStored option stores the derived key in the keystore, its ID is returned by DeriveSigningKey.
+// By default derived keys are not stored: they are discarded once used and derived again when needed.
+func WithDerivedKeyStored() DeriveOption {
+	return func(opts *deriveOpts) {
+		opts.store = true
+	}
+}
+
+// DeriveSigningKey derives from the base key keyID an Ed25519 signing key specific to the verifier identified by
+// salt (eg: the verifier's DID), so the holder signs each presentation to a different verifier with a different key.
+// The base key must be a secret key (eg: an HMACSHA256Tag256 key), its key material is never exposed.
+//
+// The derivation is deterministic: the same key is derived for the same base key and salt, so the holder can
+// recompute the key of a verifier instead of storing it. Verifiers can't link the presentations signed for different
+// verifiers to the same holder through the signing keys, while the holder (or anyone holding the base key) can
+// recompute the key of any verifier and link them. Rotating the base key changes all its derived keys.
+//
+// The derived key is not stored unless WithDerivedKeyStored is set, in which case its ID is returned.
+func (l *LocalKMS) DeriveSigningKey(keyID string, salt []byte, opts ...DeriveOption) (string, *keyset.Handle, error) {
+	derivedID, kh, err := l.deriveSigningKey(keyID, salt, opts...)
+	l.audit(&AuditRecord{Operation: AuditOpDeriveSigningKey, KeyID: keyID, NewKeyID: derivedID}, err)
+
+	return derivedID, kh, err
+}
+
+// SignWithDerivedKey signs msg with the signing key derived from the base key keyID for the verifier identified by
+// salt (see DeriveSigningKey) and discards the derived key. It returns the signature and the raw public key the
+// verifier verifies it with.
+func (l *LocalKMS) SignWithDerivedKey(keyID string, salt, msg []byte) ([]byte, []byte, error) {
+	_, kh, err := l.DeriveSigningKey(keyID, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signer, err := signature.NewSigner(kh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sign with derived key: %w", err)
+	}
+
+	sig, err := signer.Sign(msg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sign with derived key: %w", err)
+	}
+
+	pubKey, err := publicKeyBytes(kh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sign with derived key: %w", err)
+	}
+
+	return sig, pubKey, nil
+}
+
+func (l *LocalKMS) deriveSigningKey(keyID string, salt []byte, opts ...DeriveOption) (string, *keyset.Handle, error) {
+	options := &deriveOpts{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if len(salt) == 0 {
+		return "", nil, errors.New("derive signing key: salt is mandatory")
+	}
+
+	if options.store {
+		done, err := l.beginWrite()
+		if err != nil {
+			return "", nil, fmt.Errorf("derive signing key: %w", err)
+		}
+
+		defer done()
+	}
+
+	baseKH, err := l.getKeySet(keyID)
+	if err != nil {
+		return "", nil, fmt.Errorf("derive signing key: %w", err)
+	}
+
+	secret, err := secretKeyMaterial(baseKH)
+	if err != nil {
+		return "", nil, fmt.Errorf("derive signing key: %w", err)
+	}
+
+	seed := make([]byte, ed25519.SeedSize)
+
+	_, err = io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(derivedSigningKeyInfo)), seed)
+	if err != nil {
+		return "", nil, fmt.Errorf("derive signing key: %w", err)
+	}
+
+	kh, err := ed25519KeySetFromSeed(seed)
+	if err != nil {
+		return "", nil, fmt.Errorf("derive signing key: %w", err)
+	}
+
+	if !options.store {
+		return "", kh, nil
+	}
+
+	derivedID, err := l.storeKeySet(kh)
+	if err != nil {
+		return "", nil, fmt.Errorf("store derived signing key: %w", err)
+	}
+
+	err = l.saveMetadata(derivedID, &keyMetadata{KeyType: kms.ED25519Type})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return derivedID, kh, nil
+}
+
+// secretKeyMaterial returns the serialized key material of the primary key of kh, it must be a secret key.
+func secretKeyMaterial(kh *keyset.Handle) ([]byte, error) {
+	ks := insecurecleartextkeyset.KeysetMaterial(kh)
+
+	for _, key := range ks.Key {
+		if key.KeyId != ks.PrimaryKeyId {
+			continue
+		}
+
+		switch key.KeyData.KeyMaterialType {
+		case tinkpb.KeyData_SYMMETRIC, tinkpb.KeyData_ASYMMETRIC_PRIVATE:
+			return key.KeyData.Value, nil
+		default:
+			return nil, errors.New("base key is not a secret key")
+		}
+	}
+
+	return nil, errors.New("base key has no primary key")
+}
+
+// ed25519KeySetFromSeed returns a keyset with the Ed25519 private key generated from seed. The key has the RAW
+// output prefix so its signatures are plain Ed25519 signatures.
+func ed25519KeySetFromSeed(seed []byte) (*keyset.Handle, error) {
+	privKey := ed25519.NewKeyFromSeed(seed)
+
+	keyValue, err := proto.Marshal(&ed25519pb.Ed25519PrivateKey{
+		PublicKey: &ed25519pb.Ed25519PublicKey{KeyValue: privKey.Public().(ed25519.PublicKey)},
+		KeyValue:  seed,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &tinkpb.Keyset{
+		Key: []*tinkpb.Keyset_Key{{
+			KeyData: &tinkpb.KeyData{
+				TypeUrl:         ed25519SignerTypeURL,
+				Value:           keyValue,
+				KeyMaterialType: tinkpb.KeyData_ASYMMETRIC_PRIVATE,
+			},
+			Status:           tinkpb.KeyStatusType_ENABLED,
+			KeyId:            1,
+			OutputPrefixType: tinkpb.OutputPrefixType_RAW,
+		}},
+		PrimaryKeyId: 1,
+	}
+
+	return insecurecleartextkeyset.Read(&keyset.MemReaderWriter{Keyset: ks})
+}
+
+// DeriveAndStore derives from the parent symmetric key parentKeyID the key material of a new key of key type kt with
+// HKDF-SHA256, salt and info, and stores it as a new managed key. It returns the ID of the new key. The derivation is
+// deterministic for the same parent key, salt, info and key type; kt is bound to the derivation so the keys of
+// different key types derived with the same salt and info are unrelated. Supported key types are kms.AES128GCMType,
+// kms.AES256GCMType, kms.ChaCha20Poly1305Type, kms.XChaCha20Poly1305Type and kms.HMACSHA256Tag256Type.
+func (l *LocalKMS) DeriveAndStore(parentKeyID string, salt, info []byte, kt kms.KeyType) (string, error) {
+	kt = l.resolveKeyType(kt)
+
+	derivedID, err := l.deriveAndStore(parentKeyID, salt, info, kt)
+	l.audit(&AuditRecord{Operation: AuditOpDeriveAndStore, KeyID: parentKeyID, NewKeyID: derivedID, KeyType: kt}, err)
+
+	return derivedID, err
+}
+
+func (l *LocalKMS) deriveAndStore(parentKeyID string, salt, info []byte, kt kms.KeyType) (string, error) {
+	done, err := l.beginWrite()
+	if err != nil {
+		return "", fmt.Errorf("derive and store: %w", err)
+	}
+
+	defer done()
+
+	keySize, err := derivedKeySize(kt)
+	if err != nil {
+		return "", fmt.Errorf("derive and store: %w", err)
+	}
+
+	parentKH, err := l.getKeySet(parentKeyID)
+	if err != nil {
+		return "", fmt.Errorf("derive and store: %w", err)
+	}
+
+	secret, err := symmetricKeyMaterial(parentKH)
+	if err != nil {
+		return "", fmt.Errorf("derive and store: %w", err)
+	}
+
+	keyInfo := append([]byte(derivedKeyInfo+string(kt)+"/"), info...)
+
+	// the keyset key ID is derived along with the key value, so the same key derived again has the same ID
+	material := make([]byte, keySize+keyIDSize)
+
+	_, err = io.ReadFull(hkdf.New(sha256.New, secret, salt, keyInfo), material)
+	if err != nil {
+		return "", fmt.Errorf("derive and store: %w", err)
+	}
+
+	kh, err := symmetricKeySet(kt, material[:keySize], binary.BigEndian.Uint32(material[keySize:]))
+	if err != nil {
+		return "", fmt.Errorf("derive and store: %w", err)
+	}
+
+	derivedID, err := l.storeKeySet(kh)
+	if err != nil {
+		return "", fmt.Errorf("store derived key: %w", err)
+	}
+
+	err = l.saveMetadata(derivedID, &keyMetadata{KeyType: kt})
+	if err != nil {
+		return "", err
+	}
+
+	return derivedID, nil
+}
+
+// derivedKeySize returns the key size in bytes of the keys of key type kt that can be derived.
+func derivedKeySize(kt kms.KeyType) (int, error) {
+	switch kt {
+	case kms.AES128GCMType:
+		return aes128KeySize, nil
+	case kms.AES256GCMType, kms.ChaCha20Poly1305Type, kms.XChaCha20Poly1305Type, kms.HMACSHA256Tag256Type:
+		return aes256KeySize, nil
+	default:
+		return 0, fmt.Errorf("key type %s can't be derived", kt)
+	}
+}
+
+// symmetricKeyMaterial returns the serialized key material of the primary key of kh, it must be a symmetric key.
+func symmetricKeyMaterial(kh *keyset.Handle) ([]byte, error) {
+	ks := insecurecleartextkeyset.KeysetMaterial(kh)
+
+	for _, key := range ks.Key {
+		if key.KeyId != ks.PrimaryKeyId {
+			continue
+		}
+
+		if key.KeyData.KeyMaterialType != tinkpb.KeyData_SYMMETRIC {
+			return nil, errors.New("parent key is not a symmetric key")
+		}
+
+		return key.KeyData.Value, nil
+	}
+
+	return nil, errors.New("parent key has no primary key")
+}
+
+// symmetricKeySet returns a keyset with the key keyID of key type kt and key value keyValue, with the TINK output
+// prefix of the keys created by the KMS.
+func symmetricKeySet(kt kms.KeyType, keyValue []byte, keyID uint32) (*keyset.Handle, error) {
+	var (
+		typeURL string
+		key     proto.Message
+	)
+
+	switch kt {
+	case kms.AES128GCMType, kms.AES256GCMType:
+		typeURL, key = aesGCMTypeURL, &aesgcmpb.AesGcmKey{KeyValue: keyValue}
+	case kms.ChaCha20Poly1305Type:
+		typeURL, key = chaCha20Poly1305TypeURL, &chacha20poly1305pb.ChaCha20Poly1305Key{KeyValue: keyValue}
+	case kms.XChaCha20Poly1305Type:
+		typeURL, key = xChaCha20Poly1305TypeURL, &xchacha20poly1305pb.XChaCha20Poly1305Key{KeyValue: keyValue}
+	case kms.HMACSHA256Tag256Type:
+		typeURL, key = hmacTypeURL, &hmacpb.HmacKey{
+			Params:   &hmacpb.HmacParams{Hash: commonpb.HashType_SHA256, TagSize: hmacSHA256TagSize},
+			KeyValue: keyValue,
+		}
+	default:
+		return nil, fmt.Errorf("key type %s can't be derived", kt)
+	}
+
+	value, err := proto.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &tinkpb.Keyset{
+		Key: []*tinkpb.Keyset_Key{{
+			KeyData: &tinkpb.KeyData{
+				TypeUrl:         typeURL,
+				Value:           value,
+				KeyMaterialType: tinkpb.KeyData_SYMMETRIC,
+			},
+			Status:           tinkpb.KeyStatusType_ENABLED,
+			KeyId:            keyID,
+			OutputPrefixType: tinkpb.OutputPrefixType_TINK,
+		}},
+		PrimaryKeyId: keyID,
+	}
+
+	return insecurecleartextkeyset.Read(&keyset.MemReaderWriter{Keyset: ks})
+}
@@ -0,0 +1,228 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/mac"
+	"github.com/google/tink/go/signature"
+	"github.com/google/tink/go/tink"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_DeriveSigningKey(t *testing.T) {
+	const (
+		verifier1 = "did:example:verifier1"
+		verifier2 = "did:example:verifier2"
+	)
+
+	msg := []byte("presentation")
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	baseKeyID, _, err := kmsService.Create(kms.HMACSHA256Tag256Type)
+	require.NoError(t, err)
+
+	t.Run("test derivation is deterministic", func(t *testing.T) {
+		sig1, pubKey1, err := kmsService.SignWithDerivedKey(baseKeyID, []byte(verifier1), msg)
+		require.NoError(t, err)
+		require.True(t, ed25519.Verify(pubKey1, msg, sig1))
+
+		sig2, pubKey2, err := kmsService.SignWithDerivedKey(baseKeyID, []byte(verifier1), msg)
+		require.NoError(t, err)
+		require.Equal(t, pubKey1, pubKey2)
+		require.Equal(t, sig1, sig2)
+	})
+
+	t.Run("test keys of different verifiers are distinct", func(t *testing.T) {
+		_, pubKey1, err := kmsService.SignWithDerivedKey(baseKeyID, []byte(verifier1), msg)
+		require.NoError(t, err)
+
+		sig2, pubKey2, err := kmsService.SignWithDerivedKey(baseKeyID, []byte(verifier2), msg)
+		require.NoError(t, err)
+		require.NotEqual(t, pubKey1, pubKey2)
+		require.False(t, ed25519.Verify(pubKey1, msg, sig2))
+
+		otherBaseKeyID, _, err := kmsService.Create(kms.HMACSHA256Tag256Type)
+		require.NoError(t, err)
+
+		_, pubKey3, err := kmsService.SignWithDerivedKey(otherBaseKeyID, []byte(verifier1), msg)
+		require.NoError(t, err)
+		require.NotEqual(t, pubKey1, pubKey3)
+	})
+
+	t.Run("test derived key is discarded unless stored", func(t *testing.T) {
+		keyIDs, err := kmsService.keySetIDs()
+		require.NoError(t, err)
+
+		derivedID, kh, err := kmsService.DeriveSigningKey(baseKeyID, []byte(verifier1))
+		require.NoError(t, err)
+		require.Empty(t, derivedID)
+		require.NotNil(t, kh)
+
+		afterIDs, err := kmsService.keySetIDs()
+		require.NoError(t, err)
+		require.ElementsMatch(t, keyIDs, afterIDs)
+
+		derivedID, _, err = kmsService.DeriveSigningKey(baseKeyID, []byte(verifier1), WithDerivedKeyStored())
+		require.NoError(t, err)
+		require.NotEmpty(t, derivedID)
+
+		storedPubKey, err := kmsService.ExportPubKeyBytes(derivedID)
+		require.NoError(t, err)
+
+		sig, pubKey, err := kmsService.SignWithDerivedKey(baseKeyID, []byte(verifier1), msg)
+		require.NoError(t, err)
+		require.Equal(t, pubKey, storedPubKey)
+
+		stored, err := kmsService.Get(derivedID)
+		require.NoError(t, err)
+
+		signer, err := signature.NewSigner(stored.(*keyset.Handle))
+		require.NoError(t, err)
+
+		storedSig, err := signer.Sign(msg)
+		require.NoError(t, err)
+		require.Equal(t, sig, storedSig)
+	})
+
+	t.Run("test derivation errors", func(t *testing.T) {
+		_, _, err := kmsService.DeriveSigningKey(baseKeyID, nil)
+		require.EqualError(t, err, "derive signing key: salt is mandatory")
+
+		_, _, err = kmsService.DeriveSigningKey("unknown", []byte(verifier1))
+		require.Error(t, err)
+
+		pubKeyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		pubKeyBytes, err := kmsService.ExportPubKeyBytes(pubKeyID)
+		require.NoError(t, err)
+
+		pubKH, err := kmsService.PubKeyBytesToHandle(pubKeyBytes, kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = secretKeyMaterial(pubKH)
+		require.EqualError(t, err, "base key is not a secret key")
+	})
+}
+
+func TestLocalKMS_DeriveAndStore(t *testing.T) {
+	salt, info := []byte("salt"), []byte("payload encryption")
+	plaintext, aad := []byte("secret message"), []byte("aad")
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	parentKeyID, _, err := kmsService.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	getAEAD := func(t *testing.T, keyID string) tink.AEAD {
+		kh, err := kmsService.Get(keyID)
+		require.NoError(t, err)
+
+		a, err := aead.New(kh.(*keyset.Handle))
+		require.NoError(t, err)
+
+		return a
+	}
+
+	t.Run("test derived keys are stored and used for AEAD", func(t *testing.T) {
+		for _, kt := range []kms.KeyType{
+			kms.AES128GCMType, kms.AES256GCMType, kms.ChaCha20Poly1305Type, kms.XChaCha20Poly1305Type,
+		} {
+			childKeyID, err := kmsService.DeriveAndStore(parentKeyID, salt, info, kt)
+			require.NoError(t, err)
+			require.NotEqual(t, parentKeyID, childKeyID)
+
+			metadata, err := kmsService.getMetadata(childKeyID)
+			require.NoError(t, err)
+			require.Equal(t, kt, metadata.KeyType)
+
+			child := getAEAD(t, childKeyID)
+
+			ct, err := child.Encrypt(plaintext, aad)
+			require.NoError(t, err)
+
+			pt, err := child.Decrypt(ct, aad)
+			require.NoError(t, err)
+			require.Equal(t, plaintext, pt)
+
+			// the child key is not the parent key
+			_, err = getAEAD(t, parentKeyID).Decrypt(ct, aad)
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("test derivation is deterministic", func(t *testing.T) {
+		childKeyID1, err := kmsService.DeriveAndStore(parentKeyID, salt, info, kms.AES256GCMType)
+		require.NoError(t, err)
+
+		childKeyID2, err := kmsService.DeriveAndStore(parentKeyID, salt, info, kms.AES256GCMType)
+		require.NoError(t, err)
+		require.NotEqual(t, childKeyID1, childKeyID2)
+
+		ct, err := getAEAD(t, childKeyID1).Encrypt(plaintext, aad)
+		require.NoError(t, err)
+
+		pt, err := getAEAD(t, childKeyID2).Decrypt(ct, aad)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, pt)
+
+		otherKeyID, err := kmsService.DeriveAndStore(parentKeyID, salt, []byte("other"), kms.AES256GCMType)
+		require.NoError(t, err)
+
+		_, err = getAEAD(t, otherKeyID).Decrypt(ct, aad)
+		require.Error(t, err)
+	})
+
+	t.Run("test derived HMAC key", func(t *testing.T) {
+		hmacParentKeyID, _, err := kmsService.Create(kms.HMACSHA256Tag256Type)
+		require.NoError(t, err)
+
+		childKeyID, err := kmsService.DeriveAndStore(hmacParentKeyID, salt, info, kms.HMACSHA256Tag256Type)
+		require.NoError(t, err)
+
+		kh, err := kmsService.Get(childKeyID)
+		require.NoError(t, err)
+
+		m, err := mac.New(kh.(*keyset.Handle))
+		require.NoError(t, err)
+
+		tag, err := m.ComputeMAC(plaintext)
+		require.NoError(t, err)
+		require.NoError(t, m.VerifyMAC(tag, plaintext))
+	})
+
+	t.Run("test derive and store errors", func(t *testing.T) {
+		_, err := kmsService.DeriveAndStore(parentKeyID, salt, info, kms.ED25519Type)
+		require.EqualError(t, err, "derive and store: key type ED25519 can't be derived")
+
+		_, err = kmsService.DeriveAndStore("unknown", salt, info, kms.AES256GCMType)
+		require.Error(t, err)
+
+		signingKeyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = kmsService.DeriveAndStore(signingKeyID, salt, info, kms.AES256GCMType)
+		require.EqualError(t, err, "derive and store: parent key is not a symmetric key")
+	})
+}
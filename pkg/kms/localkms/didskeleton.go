@@ -0,0 +1,100 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// DIDSkeletonID is the placeholder id of the DID document skeletons, it is replaced by the DID method when
+// the DID is registered.
+const DIDSkeletonID = "did:placeholder:skeleton"
+
+const (
+	ed25519VerificationKeyType = "Ed25519VerificationKey2018"
+	p256VerificationKeyType    = "EcdsaSecp256r1VerificationKey2019"
+)
+
+// DIDDocSkeleton exports the public keys of keyIDs as a minimal DID document with the placeholder id
+// DIDSkeletonID, ready to be registered. Every key is a public key of the document, referenced by the
+// authentication relationship, with the id fragment set to its KMS key ID. Only ED25519 and ECDSAP256 signing keys
+// are supported.
+func (l *LocalKMS) DIDDocSkeleton(keyIDs ...string) (*did.Doc, error) {
+	if len(keyIDs) == 0 {
+		return nil, fmt.Errorf("did doc skeleton: missing key IDs")
+	}
+
+	pubKeys := make([]did.PublicKey, 0, len(keyIDs))
+	auth := make([]did.VerificationMethod, 0, len(keyIDs))
+
+	for _, keyID := range keyIDs {
+		pubKey, err := l.skeletonPublicKey(keyID)
+		if err != nil {
+			return nil, fmt.Errorf("did doc skeleton: %w", err)
+		}
+
+		pubKeys = append(pubKeys, *pubKey)
+		auth = append(auth, did.VerificationMethod{PublicKey: *pubKey})
+	}
+
+	doc := did.BuildDoc(did.WithPublicKey(pubKeys), did.WithAuthentication(auth))
+	doc.ID = DIDSkeletonID
+
+	return doc, nil
+}
+
+func (l *LocalKMS) skeletonPublicKey(keyID string) (*did.PublicKey, error) {
+	kt, err := l.keyType(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkType string
+
+	switch kt {
+	case kms.ED25519Type:
+		pkType = ed25519VerificationKeyType
+	case kms.ECDSAP256Type:
+		pkType = p256VerificationKeyType
+	default:
+		return nil, fmt.Errorf("key %s: key type %s not supported in a DID document", keyID, kt)
+	}
+
+	pubKeyBytes, err := l.ExportPubKeyBytes(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: export public key: %w", keyID, err)
+	}
+
+	return &did.PublicKey{
+		ID:         DIDSkeletonID + "#" + keyID,
+		Type:       pkType,
+		Controller: DIDSkeletonID,
+		Value:      pubKeyBytes,
+	}, nil
+}
+
+// keyType returns the key type of the keyset keyID, from its metadata if any or else from the keyset itself.
+func (l *LocalKMS) keyType(keyID string) (kms.KeyType, error) {
+	metadata, err := l.getMetadata(keyID)
+	if err != nil {
+		return "", fmt.Errorf("key %s: %w", keyID, err)
+	}
+
+	if metadata != nil {
+		return metadata.KeyType, nil
+	}
+
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return "", fmt.Errorf("key %s: %w", keyID, err)
+	}
+
+	return keyTypeOf(kh), nil
+}
@@ -0,0 +1,99 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_DIDDocSkeleton(t *testing.T) {
+	store := &mockstorage.MockStore{Store: map[string][]byte{}}
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewCustomMockStoreProvider(store),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	edKeyID, _, err := kmsService.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	p256KeyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+	require.NoError(t, err)
+
+	t.Run("test success", func(t *testing.T) {
+		doc, err := kmsService.DIDDocSkeleton(edKeyID, p256KeyID)
+		require.NoError(t, err)
+		require.Equal(t, DIDSkeletonID, doc.ID)
+		require.Len(t, doc.PublicKey, 2)
+		require.Len(t, doc.Authentication, 2)
+
+		require.Equal(t, DIDSkeletonID+"#"+edKeyID, doc.PublicKey[0].ID)
+		require.Equal(t, ed25519VerificationKeyType, doc.PublicKey[0].Type)
+		require.Equal(t, DIDSkeletonID, doc.PublicKey[0].Controller)
+		require.Len(t, doc.PublicKey[0].Value, ed25519.PublicKeySize)
+		require.Equal(t, DIDSkeletonID+"#"+p256KeyID, doc.PublicKey[1].ID)
+		require.Equal(t, p256VerificationKeyType, doc.PublicKey[1].Type)
+		require.Equal(t, doc.PublicKey[1], doc.Authentication[1].PublicKey)
+
+		// the skeleton is a valid DID document
+		docBytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		parsed, err := did.ParseDocument(docBytes)
+		require.NoError(t, err)
+		require.Equal(t, doc.PublicKey, parsed.PublicKey)
+		require.Equal(t, doc.Authentication, parsed.Authentication)
+	})
+
+	t.Run("test key without metadata", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+		delete(store.Store, metadataKeyPrefix+keyID)
+
+		doc, err := kmsService.DIDDocSkeleton(keyID)
+		require.NoError(t, err)
+		require.Equal(t, ed25519VerificationKeyType, doc.PublicKey[0].Type)
+	})
+
+	t.Run("test missing key IDs", func(t *testing.T) {
+		_, err := kmsService.DIDDocSkeleton()
+		require.EqualError(t, err, "did doc skeleton: missing key IDs")
+	})
+
+	t.Run("test unsupported key type", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		_, err = kmsService.DIDDocSkeleton(edKeyID, keyID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key type AES256GCM not supported in a DID document")
+	})
+
+	t.Run("test key not found", func(t *testing.T) {
+		_, err := kmsService.DIDDocSkeleton("unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key unknown")
+	})
+
+	t.Run("test invalid key metadata", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+		store.Store[metadataKeyPrefix+keyID] = []byte("invalid")
+
+		_, err = kmsService.DIDDocSkeleton(keyID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal key metadata")
+	})
+}
@@ -0,0 +1,166 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_SignatureDomain(t *testing.T) {
+	msg := []byte("message")
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	t.Run("test sign and verify in a domain", func(t *testing.T) {
+		for _, kt := range []kms.KeyType{kms.ED25519Type, kms.ECDSAP256Type} {
+			keyID, _, err := kmsService.Create(kt)
+			require.NoError(t, err)
+
+			sig, err := kmsService.SignWithKey(keyID, msg, WithDomain("didcomm/v1"))
+			require.NoError(t, err)
+
+			require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg, WithDomain("didcomm/v1")))
+
+			// the signature is not valid in another domain, nor without domain
+			err = kmsService.VerifyWithKey(keyID, sig, msg, WithDomain("jwt"))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "verify with key")
+
+			require.Error(t, kmsService.VerifyWithKey(keyID, sig, msg))
+
+			// the key is bound to its domain: it no longer signs without domain nor in another domain
+			_, err = kmsService.SignWithKey(keyID, msg)
+			require.True(t, errors.Is(err, ErrSignatureDomain))
+
+			_, err = kmsService.SignWithKey(keyID, msg, WithDomain("jwt"))
+			require.True(t, errors.Is(err, ErrSignatureDomain))
+		}
+	})
+
+	t.Run("test domain with ECDSA hash options", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+
+		sig, err := kmsService.SignWithKey(keyID, msg, WithDomain("a"), WithDeterministicSignature())
+		require.NoError(t, err)
+		require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg, WithDomain("a")))
+		require.Error(t, kmsService.VerifyWithKey(keyID, sig, msg, WithDomain("b")))
+	})
+
+	t.Run("test domain and message boundary", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		sig, err := kmsService.SignWithKey(keyID, []byte("bc"), WithDomain("a"))
+		require.NoError(t, err)
+		require.Error(t, kmsService.VerifyWithKey(keyID, sig, []byte("c"), WithDomain("ab")))
+	})
+
+	t.Run("test signature without domain over a domain separated message", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = kmsService.SignWithKey(keyID, msg, WithDomain("a"))
+		require.NoError(t, err)
+
+		// the signature without domain of "a\x00msg" would be a valid signature of msg in the domain "a"
+		_, err = kmsService.SignWithKey(keyID, append([]byte("a\x00"), msg...))
+		require.True(t, errors.Is(err, ErrSignatureDomain))
+	})
+
+	t.Run("test pooled domain of a key is invalidated when the key is bound", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		// the key is not bound to a domain yet
+		sig, err := kmsService.SignWithKey(keyID, msg)
+		require.NoError(t, err)
+		require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg))
+
+		_, err = kmsService.SignWithKey(keyID, msg, WithDomain("a"))
+		require.NoError(t, err)
+
+		_, err = kmsService.SignWithKey(keyID, msg)
+		require.True(t, errors.Is(err, ErrSignatureDomain))
+
+		err = kmsService.VerifyWithKey(keyID, sig, msg)
+		require.True(t, errors.Is(err, ErrSignatureDomain))
+	})
+
+	t.Run("test key created with a signature domain", func(t *testing.T) {
+		keyID, _, err := kmsService.CreateWithOptions(kms.ED25519Type, kms.WithSignatureDomain("a"))
+		require.NoError(t, err)
+
+		_, err = kmsService.SignWithKey(keyID, append([]byte("a\x00"), msg...))
+		require.True(t, errors.Is(err, ErrSignatureDomain))
+
+		sig, err := kmsService.SignWithKey(keyID, msg, WithDomain("a"))
+		require.NoError(t, err)
+		require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg, WithDomain("a")))
+
+		err = kmsService.VerifyWithKey(keyID, sig, msg)
+		require.True(t, errors.Is(err, ErrSignatureDomain))
+
+		// the rotated key is bound to the same domain
+		rotatedID, _, err := kmsService.Rotate(kms.ED25519Type, keyID)
+		require.NoError(t, err)
+
+		_, err = kmsService.SignWithKey(rotatedID, msg, WithDomain("b"))
+		require.True(t, errors.Is(err, ErrSignatureDomain))
+	})
+
+	t.Run("test key without metadata is bound", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+		require.NoError(t, kmsService.deleteMetadata(keyID))
+
+		_, err = kmsService.SignWithKey(keyID, msg, WithDomain("a"))
+		require.NoError(t, err)
+
+		_, err = kmsService.SignWithKey(keyID, msg)
+		require.True(t, errors.Is(err, ErrSignatureDomain))
+
+		kt, err := kmsService.keyType(keyID)
+		require.NoError(t, err)
+		require.Equal(t, kms.ED25519Type, kt)
+	})
+
+	t.Run("test the domain is not bound while paused", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		kmsService.Pause()
+		defer kmsService.Resume()
+
+		_, err = kmsService.SignWithKey(keyID, msg, WithDomain("a"))
+		require.True(t, errors.Is(err, ErrPaused))
+
+		_, err = kmsService.SignWithKey(keyID, msg)
+		require.NoError(t, err)
+	})
+
+	t.Run("test domain with a null byte", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = kmsService.SignWithKey(keyID, msg, WithDomain("a\x00b"))
+		require.EqualError(t, err, "sign with key: signature domain contains a null byte")
+
+		err = kmsService.VerifyWithKey(keyID, []byte("sig"), msg, WithDomain("a\x00b"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,280 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// SignOption configures the signing or verification of a message with a signing key.
+type SignOption func(opts *signOpts)
+
+type signOpts struct {
+	hash          crypto.Hash
+	deterministic bool
+	domain        string
+}
+
+// WithHash option signs or verifies a message with an ECDSA key hashing the message with hash (crypto.SHA256,
+// crypto.SHA384 or crypto.SHA512) instead of the hash function of the key. The hash function must be at least as
+// strong as the default hash function of the curve: P-256 keys accept the three of them, P-384 keys SHA-384 and
+// SHA-512, and P-521 keys SHA-512 only. The signatures are DER encoded, they must be verified with the same hash
+// function.
+func WithHash(hash crypto.Hash) SignOption {
+	return func(opts *signOpts) {
+		opts.hash = hash
+	}
+}
+
+// WithDeterministicSignature option signs a message with an ECDSA key deterministically, as specified by RFC 6979:
+// the signature nonce is derived from the private key and the message digest instead of being random, so signing
+// the same message with the same key (and hash function, see WithHash) always produces the same signature, eg: for
+// test vectors. The signatures are DER encoded and are verified like the randomized ones.
+func WithDeterministicSignature() SignOption {
+	return func(opts *signOpts) {
+		opts.deterministic = true
+	}
+}
+
+// ecdsaCurveHashes are the hash functions accepted by the curves of the ECDSA key types, the first one is the
+// default of the curve.
+var ecdsaCurveHashes = map[kms.KeyType][]crypto.Hash{ //nolint:gochecknoglobals
+	kms.ECDSAP256Type: {crypto.SHA256, crypto.SHA384, crypto.SHA512},
+	kms.ECDSAP384Type: {crypto.SHA384, crypto.SHA512},
+	kms.ECDSAP521Type: {crypto.SHA512},
+}
+
+// validateECDSAHash checks that the ECDSA keys of key type kt can hash messages with hash.
+func validateECDSAHash(kt kms.KeyType, hash crypto.Hash) error {
+	hashes, ok := ecdsaCurveHashes[kt]
+	if !ok {
+		return fmt.Errorf("key type %s does not support a hash function", kt)
+	}
+
+	for _, h := range hashes {
+		if h == hash {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("hash function %s is not supported by key type %s", hash, kt)
+}
+
+// ecdsaKeyTemplate returns the template of the ECDSA keys of key type kt hashing messages with hash. Tink supports
+// P-256 keys hashing with SHA-256 only, create the key with the default hash and sign with WithHash instead.
+func ecdsaKeyTemplate(kt kms.KeyType, hash crypto.Hash) (*tinkpb.KeyTemplate, error) {
+	if err := validateECDSAHash(kt, hash); err != nil {
+		return nil, err
+	}
+
+	if kt == kms.ECDSAP256Type && hash != crypto.SHA256 {
+		return nil, fmt.Errorf("key type %s keys hash with SHA-256 only, sign with the WithHash option instead", kt)
+	}
+
+	hashType, curve := tinkHashType(hash), tinkCurve(kt)
+
+	format, err := proto.Marshal(&ecdsapb.EcdsaKeyFormat{Params: &ecdsapb.EcdsaParams{
+		HashType: hashType,
+		Curve:    curve,
+		Encoding: ecdsapb.EcdsaSignatureEncoding_DER,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tinkpb.KeyTemplate{
+		TypeUrl:          ecdsaSignerTypeURL,
+		Value:            format,
+		OutputPrefixType: tinkpb.OutputPrefixType_RAW,
+	}, nil
+}
+
+// ecdsaRotationKeyTemplate returns the template of the ECDSA keys of key type kt with the parameters (hash function
+// and signature encoding) of key, keyTemplate if key is not a key of the curve of kt.
+func ecdsaRotationKeyTemplate(kt kms.KeyType, key *tinkpb.Keyset_Key,
+	keyTemplate *tinkpb.KeyTemplate) (*tinkpb.KeyTemplate, error) {
+	ecdsaKey := new(ecdsapb.EcdsaPrivateKey)
+
+	if err := proto.Unmarshal(key.KeyData.Value, ecdsaKey); err != nil {
+		return nil, fmt.Errorf("rotation key template: %w", err)
+	}
+
+	params := ecdsaKey.GetPublicKey().GetParams()
+	if params.GetCurve() != tinkCurve(kt) {
+		return keyTemplate, nil
+	}
+
+	format, err := proto.Marshal(&ecdsapb.EcdsaKeyFormat{Params: params})
+	if err != nil {
+		return nil, fmt.Errorf("rotation key template: %w", err)
+	}
+
+	return &tinkpb.KeyTemplate{
+		TypeUrl:          ecdsaSignerTypeURL,
+		Value:            format,
+		OutputPrefixType: key.OutputPrefixType,
+	}, nil
+}
+
+func tinkHashType(hash crypto.Hash) commonpb.HashType {
+	switch hash {
+	case crypto.SHA256:
+		return commonpb.HashType_SHA256
+	case crypto.SHA384:
+		return commonpb.HashType_SHA384
+	default:
+		return commonpb.HashType_SHA512
+	}
+}
+
+func cryptoHash(hashType commonpb.HashType) crypto.Hash {
+	switch hashType {
+	case commonpb.HashType_SHA256:
+		return crypto.SHA256
+	case commonpb.HashType_SHA384:
+		return crypto.SHA384
+	case commonpb.HashType_SHA512:
+		return crypto.SHA512
+	default:
+		return 0
+	}
+}
+
+func tinkCurve(kt kms.KeyType) commonpb.EllipticCurveType {
+	switch kt {
+	case kms.ECDSAP256Type:
+		return commonpb.EllipticCurveType_NIST_P256
+	case kms.ECDSAP384Type:
+		return commonpb.EllipticCurveType_NIST_P384
+	default:
+		return commonpb.EllipticCurveType_NIST_P521
+	}
+}
+
+// signECDSA signs msg with the primary ECDSA key of kh hashing msg with the hash function of the options, or of the
+// key if none is set. The signature is DER encoded like the signatures of the ECDSA keys created by the KMS, and is
+// deterministic (RFC 6979) with the WithDeterministicSignature option.
+func signECDSA(kh *keyset.Handle, options *signOpts, msg []byte) ([]byte, error) {
+	privKey, hash, err := ecdsaPrivateKey(kh, options.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var r, s *big.Int
+
+	if options.deterministic {
+		r, s = signRFC6979(privKey, hash, digest(hash, msg))
+	} else {
+		r, s, err = ecdsa.Sign(rand.Reader, privKey, digest(hash, msg))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return asn1.Marshal(ecdsaSignature{R: r, S: s})
+}
+
+// verifyWithHash verifies the DER encoded signature sig of msg with the primary ECDSA key of kh hashing msg with
+// hash.
+func verifyWithHash(kh *keyset.Handle, hash crypto.Hash, sig, msg []byte) error {
+	privKey, _, err := ecdsaPrivateKey(kh, hash)
+	if err != nil {
+		return err
+	}
+
+	signature := ecdsaSignature{}
+
+	if _, err = asn1.Unmarshal(sig, &signature); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	if !ecdsa.Verify(&privKey.PublicKey, digest(hash, msg), signature.R, signature.S) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+func digest(hash crypto.Hash, msg []byte) []byte {
+	h := hash.New()
+	h.Write(msg) // nolint:errcheck // hash writes never fail
+
+	return h.Sum(nil)
+}
+
+// ecdsaPrivateKey returns the primary ECDSA private key of kh, if it can hash messages with hash, and the hash
+// function: hash or the hash function of the key if hash is not set.
+func ecdsaPrivateKey(kh *keyset.Handle, hash crypto.Hash) (*ecdsa.PrivateKey, crypto.Hash, error) {
+	ks := insecurecleartextkeyset.KeysetMaterial(kh)
+
+	for _, key := range ks.Key {
+		if key.KeyId != ks.PrimaryKeyId {
+			continue
+		}
+
+		if key.KeyData.TypeUrl != ecdsaSignerTypeURL {
+			return nil, 0, errors.New("not an ECDSA key")
+		}
+
+		kt := ecdsaKeyType(key)
+		ecdsaKey := new(ecdsapb.EcdsaPrivateKey)
+
+		if err := proto.Unmarshal(key.KeyData.Value, ecdsaKey); err != nil {
+			return nil, 0, err
+		}
+
+		if hash == 0 {
+			hash = cryptoHash(ecdsaKey.GetPublicKey().GetParams().GetHashType())
+		}
+
+		if err := validateECDSAHash(kt, hash); err != nil {
+			return nil, 0, err
+		}
+
+		return &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: ellipticCurve(kt),
+				X:     new(big.Int).SetBytes(ecdsaKey.PublicKey.X),
+				Y:     new(big.Int).SetBytes(ecdsaKey.PublicKey.Y),
+			},
+			D: new(big.Int).SetBytes(ecdsaKey.KeyValue),
+		}, hash, nil
+	}
+
+	return nil, 0, errors.New("key has no primary key")
+}
+
+func ellipticCurve(kt kms.KeyType) elliptic.Curve {
+	switch kt {
+	case kms.ECDSAP256Type:
+		return elliptic.P256()
+	case kms.ECDSAP384Type:
+		return elliptic.P384()
+	default:
+		return elliptic.P521()
+	}
+}
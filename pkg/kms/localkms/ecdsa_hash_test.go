@@ -0,0 +1,111 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_ECDSAHash(t *testing.T) {
+	msg := []byte("message")
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	t.Run("test sign P-256 with SHA-384", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+
+		sig, err := kmsService.SignWithKey(keyID, msg, WithHash(crypto.SHA384))
+		require.NoError(t, err)
+
+		require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg, WithHash(crypto.SHA384)))
+
+		err = kmsService.VerifyWithKey(keyID, sig, msg, WithHash(crypto.SHA256))
+		require.EqualError(t, err, "verify with key: invalid signature")
+
+		// the key hashes with SHA-256 by default
+		require.Error(t, kmsService.VerifyWithKey(keyID, sig, msg))
+
+		sig, err = kmsService.SignWithKey(keyID, msg)
+		require.NoError(t, err)
+		require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg, WithHash(crypto.SHA256)))
+	})
+
+	t.Run("test create P-384 key hashing with SHA-384", func(t *testing.T) {
+		keyID, _, err := kmsService.CreateWithOptions(kms.ECDSAP384Type, kms.WithSignatureHash(crypto.SHA384))
+		require.NoError(t, err)
+
+		sig, err := kmsService.SignWithKey(keyID, msg)
+		require.NoError(t, err)
+
+		require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg))
+		require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg, WithHash(crypto.SHA384)))
+		require.Error(t, kmsService.VerifyWithKey(keyID, sig, msg, WithHash(crypto.SHA512)))
+
+		// the signatures are DER encoded, they match no JWS algorithm
+		pubKey, err := kmsService.ExportPubKey(keyID)
+		require.NoError(t, err)
+		require.Empty(t, pubKey.JWA)
+	})
+
+	t.Run("test P-384 key hashing with SHA-384 rotated with its hash function", func(t *testing.T) {
+		keyID, _, err := kmsService.CreateWithOptions(kms.ECDSAP384Type, kms.WithSignatureHash(crypto.SHA384))
+		require.NoError(t, err)
+
+		newKeyID, _, err := kmsService.Rotate(kms.ECDSAP384Type, keyID)
+		require.NoError(t, err)
+
+		sig, err := kmsService.SignWithKey(newKeyID, msg)
+		require.NoError(t, err)
+
+		require.NoError(t, kmsService.VerifyWithKey(newKeyID, sig, msg, WithHash(crypto.SHA384)))
+		require.Error(t, kmsService.VerifyWithKey(newKeyID, sig, msg, WithHash(crypto.SHA512)))
+	})
+
+	t.Run("test invalid hash and curve combinations", func(t *testing.T) {
+		_, _, err := kmsService.CreateWithOptions(kms.ECDSAP521Type, kms.WithSignatureHash(crypto.SHA256))
+		require.EqualError(t, err, "hash function SHA-256 is not supported by key type ECDSAP521")
+
+		_, _, err = kmsService.CreateWithOptions(kms.ECDSAP256Type, kms.WithSignatureHash(crypto.SHA384))
+		require.EqualError(t, err, "key type ECDSAP256 keys hash with SHA-256 only, sign with the WithHash option instead")
+
+		_, _, err = kmsService.CreateWithOptions(kms.ED25519Type, kms.WithSignatureHash(crypto.SHA256))
+		require.EqualError(t, err, "key type ED25519 does not support a hash function")
+
+		keyID, _, err := kmsService.Create(kms.ECDSAP384Type)
+		require.NoError(t, err)
+
+		_, err = kmsService.SignWithKey(keyID, msg, WithHash(crypto.SHA256))
+		require.EqualError(t, err, "sign with key: hash function SHA-256 is not supported by key type ECDSAP384")
+
+		err = kmsService.VerifyWithKey(keyID, []byte("sig"), msg, WithHash(crypto.SHA384))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verify with key: invalid signature")
+
+		keyID, _, err = kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = kmsService.SignWithKey(keyID, msg, WithHash(crypto.SHA256))
+		require.EqualError(t, err, "sign with key: not an ECDSA key")
+
+		_, err = kmsService.SignWithKey("unknown", msg, WithHash(crypto.SHA256))
+		require.Error(t, err)
+
+		err = kmsService.VerifyWithKey("unknown", []byte("sig"), msg, WithHash(crypto.SHA256))
+		require.Error(t, err)
+	})
+}
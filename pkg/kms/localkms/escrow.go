@@ -0,0 +1,183 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+)
+
+const (
+	// escrowShareType is the type of the shares produced by EscrowKey.
+	escrowShareType = "LocalKMSEscrowShare/1.0"
+
+	// size of the random IDs telling the shares of an escrow from the shares of other escrows
+	escrowIDSize = 16
+)
+
+// escrowShare is a share of a keyset escrowed with EscrowKey.
+type escrowShare struct {
+	Type      string `json:"type"`
+	EscrowID  string `json:"escrowID"`
+	Threshold int    `json:"threshold"`
+	Index     byte   `json:"index"`
+	Value     []byte `json:"value"`
+}
+
+// EscrowKey splits the keyset referenced by keyID into n shares with Shamir's secret sharing scheme, for key
+// recovery by split knowledge: any k of the shares recover the keyset with RecoverKey, fewer than k shares reveal
+// nothing about it. The shares hold the keyset in clear once combined, they must be handed to distinct custodians.
+func (l *LocalKMS) EscrowKey(keyID string, n, k int) ([][]byte, error) {
+	shares, err := l.escrowKey(keyID, n, k)
+	l.audit(&AuditRecord{Operation: AuditOpEscrowKey, KeyID: keyID}, err)
+
+	return shares, err
+}
+
+func (l *LocalKMS) escrowKey(keyID string, n, k int) ([][]byte, error) {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := proto.Marshal(insecurecleartextkeyset.KeysetMaterial(kh))
+	if err != nil {
+		return nil, fmt.Errorf("escrow key: %w", err)
+	}
+
+	defer zeroize(secret)
+
+	values, err := splitSecret(secret, n, k)
+	if err != nil {
+		return nil, fmt.Errorf("escrow key: %w", err)
+	}
+
+	escrowID := make([]byte, escrowIDSize)
+
+	_, err = rand.Read(escrowID)
+	if err != nil {
+		return nil, fmt.Errorf("escrow key: %w", err)
+	}
+
+	shares := make([][]byte, len(values))
+
+	for i, value := range values {
+		shares[i], err = json.Marshal(&escrowShare{
+			Type:      escrowShareType,
+			EscrowID:  base64.RawURLEncoding.EncodeToString(escrowID),
+			Threshold: k,
+			Index:     byte(i + 1),
+			Value:     value,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("escrow key: %w", err)
+		}
+	}
+
+	return shares, nil
+}
+
+// RecoverKey recovers the keyset escrowed with EscrowKey from at least k of its shares, stores it and returns its
+// new key ID.
+func (l *LocalKMS) RecoverKey(shares [][]byte) (string, error) {
+	kID, err := l.recoverKey(shares)
+	l.audit(&AuditRecord{Operation: AuditOpRecoverKey, NewKeyID: kID}, err)
+
+	return kID, err
+}
+
+func (l *LocalKMS) recoverKey(shares [][]byte) (string, error) {
+	done, err := l.beginWrite()
+	if err != nil {
+		return "", fmt.Errorf("recover key: %w", err)
+	}
+
+	defer done()
+
+	kh, err := combineEscrowShares(shares)
+	if err != nil {
+		return "", fmt.Errorf("recover key: %w", err)
+	}
+
+	kID, err := l.storeKeySet(kh)
+	if err != nil {
+		return "", err
+	}
+
+	err = l.saveMetadata(kID, &keyMetadata{KeyType: keyTypeOf(kh)})
+	if err != nil {
+		return "", err
+	}
+
+	return kID, nil
+}
+
+// combineEscrowShares returns the keyset escrowed in shares, they must be at least the threshold of the escrow.
+func combineEscrowShares(shares [][]byte) (*keyset.Handle, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares")
+	}
+
+	xs := make([]byte, len(shares))
+	ys := make([][]byte, len(shares))
+
+	var first *escrowShare
+
+	for i, data := range shares {
+		share := &escrowShare{}
+
+		err := json.Unmarshal(data, share)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+
+		if share.Type != escrowShareType {
+			return nil, fmt.Errorf("share %d: unsupported share type '%s'", i, share.Type)
+		}
+
+		if first == nil {
+			first = share
+		} else if share.EscrowID != first.EscrowID {
+			return nil, fmt.Errorf("share %d: not a share of escrow %s", i, first.EscrowID)
+		}
+
+		xs[i], ys[i] = share.Index, share.Value
+	}
+
+	if len(shares) < first.Threshold {
+		return nil, fmt.Errorf("%d shares, the escrow requires %d", len(shares), first.Threshold)
+	}
+
+	secret, err := combineShares(xs, ys)
+	if err != nil {
+		return nil, err
+	}
+
+	defer zeroize(secret)
+
+	ks := &tinkpb.Keyset{}
+
+	err = proto.Unmarshal(secret, ks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid escrowed keyset: %w", err)
+	}
+
+	kh, err := insecurecleartextkeyset.Read(&keyset.MemReaderWriter{Keyset: ks})
+	if err != nil {
+		return nil, fmt.Errorf("invalid escrowed keyset: %w", err)
+	}
+
+	return kh, nil
+}
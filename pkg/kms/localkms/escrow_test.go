@@ -0,0 +1,142 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_EscrowKey(t *testing.T) {
+	msg := []byte("message")
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	keyID, _, err := kmsService.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	sig, err := kmsService.SignWithKey(keyID, msg)
+	require.NoError(t, err)
+
+	shares, err := kmsService.EscrowKey(keyID, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	t.Run("test recover from k shares", func(t *testing.T) {
+		for _, subset := range [][]int{{0, 1, 2}, {1, 3, 4}, {4, 0, 2}, {0, 1, 2, 3, 4}} {
+			var picked [][]byte
+
+			for _, i := range subset {
+				picked = append(picked, shares[i])
+			}
+
+			recoveredID, err := kmsService.RecoverKey(picked)
+			require.NoError(t, err)
+			require.NotEqual(t, keyID, recoveredID)
+
+			// the recovered key is the escrowed key
+			require.NoError(t, kmsService.VerifyWithKey(recoveredID, sig, msg))
+
+			pubKey, err := kmsService.ExportPubKeyBytes(keyID)
+			require.NoError(t, err)
+
+			recoveredPubKey, err := kmsService.ExportPubKeyBytes(recoveredID)
+			require.NoError(t, err)
+			require.Equal(t, pubKey, recoveredPubKey)
+
+			require.Equal(t, kms.ED25519Type, kmsService.storedKeyType(recoveredID))
+		}
+	})
+
+	t.Run("test k-1 shares fail", func(t *testing.T) {
+		_, err := kmsService.RecoverKey(shares[1:3])
+		require.EqualError(t, err, "recover key: 2 shares, the escrow requires 3")
+
+		// the threshold is not only enforced by the share headers: k-1 share values don't combine to the keyset
+		xs := []byte{1, 2}
+		ys := [][]byte{shareValue(t, shares[0]), shareValue(t, shares[1])}
+
+		combined, err := combineShares(xs, ys)
+		require.NoError(t, err)
+
+		full, err := combineShares(append(xs, 3), append(ys, shareValue(t, shares[2])))
+		require.NoError(t, err)
+		require.NotEqual(t, full, combined)
+	})
+
+	t.Run("test shares of different escrows fail", func(t *testing.T) {
+		otherShares, err := kmsService.EscrowKey(keyID, 5, 3)
+		require.NoError(t, err)
+
+		// the shares are random, each escrow of the key has different shares
+		require.NotEqual(t, shareValue(t, shares[0]), shareValue(t, otherShares[0]))
+
+		_, err = kmsService.RecoverKey([][]byte{shares[0], shares[1], otherShares[2]})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "share 2: not a share of escrow")
+	})
+
+	t.Run("test invalid shares", func(t *testing.T) {
+		_, err := kmsService.RecoverKey(nil)
+		require.EqualError(t, err, "recover key: no shares")
+
+		_, err = kmsService.RecoverKey([][]byte{shares[0], shares[0], shares[1]})
+		require.EqualError(t, err, "recover key: duplicate share 1")
+
+		_, err = kmsService.RecoverKey([][]byte{[]byte("{")})
+		require.Error(t, err)
+
+		_, err = kmsService.RecoverKey([][]byte{[]byte(`{"type":"other"}`)})
+		require.EqualError(t, err, "recover key: share 0: unsupported share type 'other'")
+	})
+
+	t.Run("test invalid escrow parameters", func(t *testing.T) {
+		for _, nk := range [][2]int{{5, 1}, {3, 4}, {256, 2}} {
+			_, err := kmsService.EscrowKey(keyID, nk[0], nk[1])
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "escrow key: invalid")
+		}
+
+		_, err := kmsService.EscrowKey("unknown", 5, 3)
+		require.Error(t, err)
+	})
+}
+
+func TestSplitSecret(t *testing.T) {
+	secret := []byte("a secret of some bytes")
+
+	shares, err := splitSecret(secret, maxShares, 2)
+	require.NoError(t, err)
+
+	for i := 1; i < len(shares); i++ {
+		recovered, err := combineShares([]byte{1, byte(i + 1)}, [][]byte{shares[0], shares[i]})
+		require.NoError(t, err)
+		require.Equal(t, secret, recovered)
+	}
+
+	for a := 1; a < 256; a++ {
+		require.Equal(t, byte(1), gfMul(byte(a), gfInv(byte(a))))
+	}
+}
+
+func shareValue(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	share := &escrowShare{}
+	require.NoError(t, json.Unmarshal(data, share))
+
+	return share.Value
+}
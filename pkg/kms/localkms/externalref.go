@@ -0,0 +1,55 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// externalRefKeyPrefix is the prefix of the keys of the external references index in the keystore
+const externalRefKeyPrefix = "externalref_"
+
+// ErrExternalRefInUse is returned when creating or importing a key with an external reference already tagging
+// another key.
+var ErrExternalRefInUse = errors.New("external reference already in use")
+
+// GetByExternalRef returns the ID of the key tagged with the external reference ref (see kms.WithExternalRef).
+// It returns an error wrapping storage.ErrDataNotFound if no key is tagged with ref.
+func (l *LocalKMS) GetByExternalRef(ref string) (string, error) {
+	keyID, err := l.store.Get(externalRefKeyPrefix + ref)
+	if err != nil {
+		return "", fmt.Errorf("get key by external ref: %w", err)
+	}
+
+	return string(keyID), nil
+}
+
+// checkExternalRef checks the external reference ref does not tag a key yet.
+func (l *LocalKMS) checkExternalRef(ref string) error {
+	_, err := l.store.Get(externalRefKeyPrefix + ref)
+	if err == nil {
+		return fmt.Errorf("external ref '%s': %w", ref, ErrExternalRefInUse)
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("check external ref: %w", err)
+	}
+
+	return nil
+}
+
+func (l *LocalKMS) saveExternalRef(ref, keyID string) error {
+	err := l.store.Put(externalRefKeyPrefix+ref, []byte(keyID))
+	if err != nil {
+		return fmt.Errorf("save external ref: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestLocalKMS_ExternalRef(t *testing.T) {
+	newKMS := func(t *testing.T, store *mockstorage.MockStore) *LocalKMS {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: createMasterKeyAndSecretLock(t),
+		})
+		require.NoError(t, err)
+
+		return k
+	}
+
+	t.Run("test create with external ref and get by it", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		keyID, _, err := kmsService.CreateWithOptions(kms.ED25519Type, kms.WithExternalRef("ext-1"))
+		require.NoError(t, err)
+
+		otherKeyID, _, err := kmsService.CreateWithOptions(kms.ED25519Type, kms.WithExternalRef("ext-2"))
+		require.NoError(t, err)
+
+		found, err := kmsService.GetByExternalRef("ext-1")
+		require.NoError(t, err)
+		require.Equal(t, keyID, found)
+
+		found, err = kmsService.GetByExternalRef("ext-2")
+		require.NoError(t, err)
+		require.Equal(t, otherKeyID, found)
+
+		metadata, err := kmsService.getMetadata(keyID)
+		require.NoError(t, err)
+		require.Equal(t, "ext-1", metadata.ExternalRef)
+	})
+
+	t.Run("test external ref collision", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		kmsService := newKMS(t, store)
+
+		keyID, _, err := kmsService.CreateWithOptions(kms.ED25519Type, kms.WithExternalRef("ext-1"))
+		require.NoError(t, err)
+
+		keysCount := len(store.Store)
+
+		_, _, err = kmsService.CreateWithOptions(kms.AES256GCMType, kms.WithExternalRef("ext-1"))
+		require.True(t, errors.Is(err, ErrExternalRefInUse))
+
+		// the existing key is still referenced and no key is created
+		found, err := kmsService.GetByExternalRef("ext-1")
+		require.NoError(t, err)
+		require.Equal(t, keyID, found)
+		require.Len(t, store.Store, keysCount)
+	})
+
+	t.Run("test external ref not found", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		_, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = kmsService.GetByExternalRef("unknown")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test rotated key keeps its external ref", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		keyID, _, err := kmsService.CreateWithOptions(kms.ED25519Type, kms.WithExternalRef("ext-1"))
+		require.NoError(t, err)
+
+		newKeyID, _, err := kmsService.Rotate(kms.ED25519Type, keyID)
+		require.NoError(t, err)
+
+		found, err := kmsService.GetByExternalRef("ext-1")
+		require.NoError(t, err)
+		require.Equal(t, newKeyID, found)
+	})
+
+	t.Run("test unseal with external ref", func(t *testing.T) {
+		source := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+		destination := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		transportKeyID, _, err := destination.CreateWithOptions(kms.ECIESHKDFAES128GCMType, kms.WithExternalRef("transport"))
+		require.NoError(t, err)
+
+		transportPubKey, err := destination.ExportPubKeyBytes(transportKeyID)
+		require.NoError(t, err)
+
+		keyID, _, err := source.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		sealed, err := source.SealKey(keyID, transportPubKey)
+		require.NoError(t, err)
+
+		unsealedKeyID, err := destination.UnsealKey(sealed, transportKeyID, kms.WithExternalRef("ext-1"))
+		require.NoError(t, err)
+
+		found, err := destination.GetByExternalRef("ext-1")
+		require.NoError(t, err)
+		require.Equal(t, unsealedKeyID, found)
+
+		_, err = destination.UnsealKey(sealed, transportKeyID, kms.WithExternalRef("transport"))
+		require.True(t, errors.Is(err, ErrExternalRefInUse))
+	})
+
+	t.Run("test store errors", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		kmsService := newKMS(t, store)
+
+		store.ErrGet = errors.New("get error")
+
+		_, _, err := kmsService.CreateWithOptions(kms.ED25519Type, kms.WithExternalRef("ext-1"))
+		require.EqualError(t, err, "check external ref: get error")
+
+		_, err = kmsService.GetByExternalRef("ext-1")
+		require.EqualError(t, err, "get key by external ref: get error")
+	})
+}
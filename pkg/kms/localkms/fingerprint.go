@@ -0,0 +1,92 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// keyCommitmentLabel is the message of the commitments of the symmetric keys fingerprinted by KeyFingerprint
+const keyCommitmentLabel = "aries-framework-go/localkms/key-commitment"
+
+// KeyFingerprint returns the fingerprint of the key keyID: the unpadded base32 encoding of the SHA-256 hash of its
+// key type and of the raw bytes of its public key. The fingerprint of a symmetric key covers a commitment to the key
+// (an HMAC-SHA256 keyed with the key) instead of the key. Fingerprints do not reveal key material, they are stable
+// and identical for the same key held by different agents, eg: to reconcile key inventories.
+func (l *LocalKMS) KeyFingerprint(keyID string) (string, error) {
+	fingerprint, err := l.keyFingerprint(keyID)
+	l.audit(&AuditRecord{Operation: AuditOpKeyFingerprint, KeyID: keyID}, err)
+
+	if err != nil {
+		return "", fmt.Errorf("key fingerprint: %w", err)
+	}
+
+	return fingerprint, nil
+}
+
+func (l *LocalKMS) keyFingerprint(keyID string) (string, error) {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	kt := keyTypeOf(kh)
+	if kt == "" {
+		return "", errors.New("unsupported key type")
+	}
+
+	var material []byte
+
+	if isAsymmetricKeyType(kt) {
+		material, err = publicKeyBytes(kh)
+	} else {
+		material, err = keyCommitment(kh)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	// the key type is separated from the key material, key types do not include null bytes
+	h := sha256.Sum256(append(append([]byte(kt), 0), material...))
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(h[:]), nil
+}
+
+// keyCommitment returns an HMAC-SHA256, keyed with the primary key of kh, of a fixed label.
+func keyCommitment(kh *keyset.Handle) ([]byte, error) {
+	ks := insecurecleartextkeyset.KeysetMaterial(kh)
+
+	for _, key := range ks.Key {
+		if key.KeyId == ks.PrimaryKeyId {
+			mac := hmac.New(sha256.New, key.KeyData.Value)
+			mac.Write([]byte(keyCommitmentLabel)) // nolint:errcheck // hash writes never fail
+
+			return mac.Sum(nil), nil
+		}
+	}
+
+	return nil, errors.New("keyset has no primary key")
+}
+
+func isAsymmetricKeyType(kt kms.KeyType) bool {
+	switch kt {
+	case kms.ECDSAP256Type, kms.ECDSAP384Type, kms.ECDSAP521Type, kms.ED25519Type, kms.ECIESHKDFAES128GCMType:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+	"testing"
+
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_KeyFingerprint(t *testing.T) {
+	newKMS := func(t *testing.T) *LocalKMS {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: createMasterKeyAndSecretLock(t),
+		})
+		require.NoError(t, err)
+
+		return k
+	}
+
+	// the two KMS use different master keys
+	source := newKMS(t)
+	destination := newKMS(t)
+
+	transportKeyID, _, err := destination.Create(kms.ECIESHKDFAES128GCMType)
+	require.NoError(t, err)
+
+	transportPubKey, err := destination.ExportPubKeyBytes(transportKeyID)
+	require.NoError(t, err)
+
+	keyTypes := []kms.KeyType{
+		kms.ED25519Type, kms.ECDSAP256Type, kms.ECIESHKDFAES128GCMType, kms.AES256GCMType, kms.HMACSHA256Tag256Type,
+	}
+
+	t.Run("test same key held by two KMS has the same fingerprint", func(t *testing.T) {
+		for _, kt := range keyTypes {
+			keyID, _, err := source.Create(kt)
+			require.NoError(t, err, kt)
+
+			sealed, err := source.SealKey(keyID, transportPubKey)
+			require.NoError(t, err, kt)
+
+			copyID, err := destination.UnsealKey(sealed, transportKeyID)
+			require.NoError(t, err, kt)
+
+			expected, err := source.KeyFingerprint(keyID)
+			require.NoError(t, err, kt)
+			require.Len(t, expected, 52, kt)
+			require.NotContains(t, expected, "=", kt)
+
+			actual, err := destination.KeyFingerprint(copyID)
+			require.NoError(t, err, kt)
+			require.Equal(t, expected, actual, kt)
+
+			// stable
+			actual, err = source.KeyFingerprint(keyID)
+			require.NoError(t, err, kt)
+			require.Equal(t, expected, actual, kt)
+		}
+	})
+
+	t.Run("test different keys have different fingerprints", func(t *testing.T) {
+		fingerprints := map[string]bool{}
+
+		for _, kt := range keyTypes {
+			for i := 0; i < 2; i++ {
+				keyID, _, err := source.Create(kt)
+				require.NoError(t, err, kt)
+
+				fingerprint, err := source.KeyFingerprint(keyID)
+				require.NoError(t, err, kt)
+				require.False(t, fingerprints[fingerprint], kt)
+
+				fingerprints[fingerprint] = true
+			}
+		}
+	})
+
+	t.Run("test fingerprint covers the public key and the key type", func(t *testing.T) {
+		keyID, _, err := source.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		pubKey, err := source.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		h := sha256.Sum256(append([]byte("ED25519\x00"), pubKey...))
+
+		fingerprint, err := source.KeyFingerprint(keyID)
+		require.NoError(t, err)
+		require.Equal(t, strings.TrimRight(base32.StdEncoding.EncodeToString(h[:]), "="), fingerprint)
+	})
+
+	t.Run("test fingerprint of a symmetric key covers a commitment to the key", func(t *testing.T) {
+		keyID, kh, err := source.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		ks := insecurecleartextkeyset.KeysetMaterial(kh.(*keyset.Handle))
+		mac := hmac.New(sha256.New, ks.Key[0].KeyData.Value)
+		_, err = mac.Write([]byte(keyCommitmentLabel))
+		require.NoError(t, err)
+
+		h := sha256.Sum256(append([]byte("AES256GCM\x00"), mac.Sum(nil)...))
+
+		fingerprint, err := source.KeyFingerprint(keyID)
+		require.NoError(t, err)
+		require.Equal(t, strings.TrimRight(base32.StdEncoding.EncodeToString(h[:]), "="), fingerprint)
+	})
+
+	t.Run("test fingerprint of unknown key", func(t *testing.T) {
+		_, err := source.KeyFingerprint("unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key fingerprint")
+	})
+}
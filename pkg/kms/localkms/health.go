@@ -0,0 +1,45 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// healthCheckProbe is the data encrypted and decrypted with the master key by HealthCheck.
+const healthCheckProbe = "localkms health check"
+
+// HealthCheck checks the keystore is reachable and the master key can be used through the secret lock to wrap and
+// unwrap keys. It does not read any stored keyset.
+func (l *LocalKMS) HealthCheck() error {
+	err := l.healthCheck()
+	l.audit(&AuditRecord{Operation: AuditOpHealthCheck}, err)
+
+	return err
+}
+
+func (l *LocalKMS) healthCheck() error {
+	_, err := l.store.Get(metadataKeyPrefix + "healthcheck")
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("kms health check: keystore: %w", err)
+	}
+
+	ct, err := l.masterKeyEnvAEAD.Encrypt([]byte(healthCheckProbe), nil)
+	if err != nil {
+		return fmt.Errorf("kms health check: master key: %w", err)
+	}
+
+	_, err = l.masterKeyEnvAEAD.Decrypt(ct, nil)
+	if err != nil {
+		return fmt.Errorf("kms health check: master key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mocksecretlock "github.com/hyperledger/aries-framework-go/pkg/mock/secretlock"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_HealthCheck(t *testing.T) {
+	t.Run("test healthy kms", func(t *testing.T) {
+		kmsService, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: createMasterKeyAndSecretLock(t),
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, kmsService.HealthCheck())
+	})
+
+	t.Run("test keystore failure", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+
+		kmsService, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: createMasterKeyAndSecretLock(t),
+		})
+		require.NoError(t, err)
+
+		store.ErrGet = errors.New("store unreachable")
+
+		err = kmsService.HealthCheck()
+		require.EqualError(t, err, "kms health check: keystore: store unreachable")
+	})
+
+	t.Run("test secret lock failure", func(t *testing.T) {
+		kmsService, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: &mocksecretlock.MockSecretLock{ErrEncrypt: errors.New("secret lock unavailable")},
+		})
+		require.NoError(t, err)
+
+		err = kmsService.HealthCheck()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "kms health check: master key")
+		require.Contains(t, err.Error(), "secret lock unavailable")
+	})
+}
@@ -0,0 +1,142 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package keywrapper
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/google/tink/go/tink"
+)
+
+// CachedAEAD wraps a key wrapper AEAD and keeps the unwrapped (decrypted) keys in memory for a limited time
+// to avoid calling the secret lock each time the same wrapped key is unwrapped. Cached keys are zeroized
+// when they expire.
+type CachedAEAD struct {
+	aead    tink.AEAD
+	ttl     time.Duration
+	mutex   sync.Mutex
+	entries map[[sha256.Size]byte]*cacheEntry
+}
+
+type cacheEntry struct {
+	plaintext []byte
+	timer     *time.Timer
+}
+
+// NewCachedAEAD creates a new key wrapper caching the keys unwrapped by aead for ttl.
+func NewCachedAEAD(aead tink.AEAD, ttl time.Duration) *CachedAEAD {
+	return &CachedAEAD{
+		aead:    aead,
+		ttl:     ttl,
+		entries: make(map[[sha256.Size]byte]*cacheEntry),
+	}
+}
+
+// Encrypt wraps plaintext with the underlying AEAD and caches it for the wrapped key.
+func (c *CachedAEAD) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	ct, err := c.aead.Encrypt(plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(cacheKey(ct, additionalData), plaintext)
+
+	return ct, nil
+}
+
+// Decrypt returns the cached unwrapped key of ciphertext if it did not expire, it unwraps it with the
+// underlying AEAD otherwise.
+func (c *CachedAEAD) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	k := cacheKey(ciphertext, additionalData)
+
+	if pt, ok := c.get(k); ok {
+		return pt, nil
+	}
+
+	pt, err := c.aead.Decrypt(ciphertext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(k, pt)
+
+	return pt, nil
+}
+
+// Purge zeroizes and removes all the cached keys.
+func (c *CachedAEAD) Purge() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for k := range c.entries {
+		c.evict(k)
+	}
+}
+
+func (c *CachedAEAD) get(k [sha256.Size]byte) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.entries[k]
+	if !ok {
+		return nil, false
+	}
+
+	// return a copy, the cached key is zeroized on expiry
+	return append([]byte(nil), e.plaintext...), true
+}
+
+func (c *CachedAEAD) put(k [sha256.Size]byte, plaintext []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.entries[k]; ok {
+		c.evict(k)
+	}
+
+	c.entries[k] = &cacheEntry{
+		plaintext: append([]byte(nil), plaintext...),
+		timer: time.AfterFunc(c.ttl, func() {
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+
+			c.evict(k)
+		}),
+	}
+}
+
+// evict zeroizes and removes the cached key k, the mutex must be held by the caller.
+func (c *CachedAEAD) evict(k [sha256.Size]byte) {
+	e, ok := c.entries[k]
+	if !ok {
+		return
+	}
+
+	e.timer.Stop()
+
+	for i := range e.plaintext {
+		e.plaintext[i] = 0
+	}
+
+	delete(c.entries, k)
+}
+
+func cacheKey(ciphertext, additionalData []byte) [sha256.Size]byte {
+	const lenSize = 8
+
+	// length prefix the ciphertext so that (ciphertext, additionalData) pairs can't collide
+	data := make([]byte, lenSize, lenSize+len(ciphertext)+len(additionalData))
+	binary.BigEndian.PutUint64(data, uint64(len(ciphertext)))
+
+	data = append(data, ciphertext...)
+	data = append(data, additionalData...)
+
+	return sha256.Sum256(data)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package keywrapper
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingAEAD is a reversible (not secure) AEAD counting its calls.
+type countingAEAD struct {
+	mutex      sync.Mutex
+	encryptCnt int
+	decryptCnt int
+	err        error
+}
+
+func (a *countingAEAD) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.encryptCnt++
+
+	return append([]byte("ct:"), plaintext...), a.err
+}
+
+func (a *countingAEAD) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.decryptCnt++
+
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	return ciphertext[len("ct:"):], nil
+}
+
+func (a *countingAEAD) decryptCount() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.decryptCnt
+}
+
+func TestCachedAEAD(t *testing.T) {
+	t.Run("test unwrapped keys are cached within the TTL", func(t *testing.T) {
+		wrapper := &countingAEAD{}
+		cached := NewCachedAEAD(wrapper, time.Hour)
+
+		for i := 0; i < 3; i++ {
+			pt, err := cached.Decrypt([]byte("ct:key"), []byte("aad"))
+			require.NoError(t, err)
+			require.Equal(t, []byte("key"), pt)
+		}
+
+		require.Equal(t, 1, wrapper.decryptCount())
+
+		// a different additional data is another cache entry
+		_, err := cached.Decrypt([]byte("ct:key"), []byte("other aad"))
+		require.NoError(t, err)
+		require.Equal(t, 2, wrapper.decryptCount())
+	})
+
+	t.Run("test wrapped keys are cached", func(t *testing.T) {
+		wrapper := &countingAEAD{}
+		cached := NewCachedAEAD(wrapper, time.Hour)
+
+		ct, err := cached.Encrypt([]byte("key"), nil)
+		require.NoError(t, err)
+
+		pt, err := cached.Decrypt(ct, nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("key"), pt)
+		require.Equal(t, 0, wrapper.decryptCount())
+	})
+
+	t.Run("test cached keys are zeroized on expiry", func(t *testing.T) {
+		wrapper := &countingAEAD{}
+		cached := NewCachedAEAD(wrapper, 10*time.Millisecond)
+
+		pt, err := cached.Decrypt([]byte("ct:key"), nil)
+		require.NoError(t, err)
+
+		cached.mutex.Lock()
+		require.Len(t, cached.entries, 1)
+		cachedKey := cached.entries[cacheKey([]byte("ct:key"), nil)].plaintext
+		cached.mutex.Unlock()
+
+		require.Eventually(t, func() bool {
+			cached.mutex.Lock()
+			defer cached.mutex.Unlock()
+
+			return len(cached.entries) == 0
+		}, time.Second, 5*time.Millisecond)
+
+		require.Equal(t, []byte{0, 0, 0}, cachedKey)
+		// keys returned to the caller are copies, they are not zeroized
+		require.Equal(t, []byte("key"), pt)
+
+		_, err = cached.Decrypt([]byte("ct:key"), nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, wrapper.decryptCount())
+	})
+
+	t.Run("test purge", func(t *testing.T) {
+		wrapper := &countingAEAD{}
+		cached := NewCachedAEAD(wrapper, time.Hour)
+
+		_, err := cached.Decrypt([]byte("ct:key"), nil)
+		require.NoError(t, err)
+
+		cached.Purge()
+		require.Empty(t, cached.entries)
+
+		_, err = cached.Decrypt([]byte("ct:key"), nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, wrapper.decryptCount())
+	})
+
+	t.Run("test errors are not cached", func(t *testing.T) {
+		wrapper := &countingAEAD{err: errors.New("secret lock error")}
+		cached := NewCachedAEAD(wrapper, time.Hour)
+
+		_, err := cached.Encrypt([]byte("key"), nil)
+		require.EqualError(t, err, "secret lock error")
+
+		_, err = cached.Decrypt([]byte("ct:key"), nil)
+		require.EqualError(t, err, "secret lock error")
+
+		_, err = cached.Decrypt([]byte("ct:key"), nil)
+		require.EqualError(t, err, "secret lock error")
+		require.Equal(t, 2, wrapper.decryptCount())
+	})
+}
@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package primitivepool
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/signature"
+	"github.com/google/tink/go/tink"
+)
+
+// Loader loads the keyset handle of a key, it is called when the primitive of the key is not pooled.
+type Loader func() (*keyset.Handle, error)
+
+// DomainLoader loads the signature domain a key is bound to, it is called when the domain of the key is not pooled.
+type DomainLoader func() (string, error)
+
+// Pool keeps the signer and verifier primitives of keys by key ID, to avoid extracting them from the keyset
+// handles on every signature, and the signature domains the keys are bound to, to avoid reading the key metadata.
+// The primitives and the domain of a key must be invalidated when its keyset changes (eg: rotation) or when it is
+// bound to a domain. Pool is safe for concurrent use.
+type Pool struct {
+	mutex     sync.RWMutex
+	signers   map[string]tink.Signer
+	verifiers map[string]tink.Verifier
+	domains   map[string]string
+	// generation is incremented on each invalidation, a primitive loaded before an invalidation is not pooled
+	generation uint64
+}
+
+// New creates an empty pool.
+func New() *Pool {
+	return &Pool{
+		signers:   make(map[string]tink.Signer),
+		verifiers: make(map[string]tink.Verifier),
+		domains:   make(map[string]string),
+	}
+}
+
+// Signer returns the pooled signer of keyID, it creates it from the keyset handle returned by load otherwise.
+func (p *Pool) Signer(keyID string, load Loader) (tink.Signer, error) {
+	p.mutex.RLock()
+	signer, ok := p.signers[keyID]
+	generation := p.generation
+	p.mutex.RUnlock()
+
+	if ok {
+		return signer, nil
+	}
+
+	kh, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err = signature.NewSigner(kh)
+	if err != nil {
+		return nil, fmt.Errorf("create new signer: %w", err)
+	}
+
+	p.mutex.Lock()
+	if generation == p.generation {
+		p.signers[keyID] = signer
+	}
+	p.mutex.Unlock()
+
+	return signer, nil
+}
+
+// Verifier returns the pooled verifier of keyID, it creates it from the keyset handle returned by load otherwise.
+// The keyset handle can be a private or a public keyset handle.
+func (p *Pool) Verifier(keyID string, load Loader) (tink.Verifier, error) {
+	p.mutex.RLock()
+	verifier, ok := p.verifiers[keyID]
+	generation := p.generation
+	p.mutex.RUnlock()
+
+	if ok {
+		return verifier, nil
+	}
+
+	verifier, err := newVerifier(load)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	if generation == p.generation {
+		p.verifiers[keyID] = verifier
+	}
+	p.mutex.Unlock()
+
+	return verifier, nil
+}
+
+func newVerifier(load Loader) (tink.Verifier, error) {
+	kh, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	// signature.NewVerifier requires a public keyset handle
+	if pubKH, e := kh.Public(); e == nil {
+		kh = pubKH
+	}
+
+	verifier, err := signature.NewVerifier(kh)
+	if err != nil {
+		return nil, fmt.Errorf("create new verifier: %w", err)
+	}
+
+	return verifier, nil
+}
+
+// Domain returns the pooled signature domain of keyID, empty if the key is not bound to a domain, it loads it with
+// load otherwise.
+func (p *Pool) Domain(keyID string, load DomainLoader) (string, error) {
+	p.mutex.RLock()
+	domain, ok := p.domains[keyID]
+	generation := p.generation
+	p.mutex.RUnlock()
+
+	if ok {
+		return domain, nil
+	}
+
+	domain, err := load()
+	if err != nil {
+		return "", err
+	}
+
+	p.mutex.Lock()
+	if generation == p.generation {
+		p.domains[keyID] = domain
+	}
+	p.mutex.Unlock()
+
+	return domain, nil
+}
+
+// Invalidate removes the pooled primitives and signature domain of keyID.
+func (p *Pool) Invalidate(keyID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.signers, keyID)
+	delete(p.verifiers, keyID)
+	delete(p.domains, keyID)
+	p.generation++
+}
@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package primitivepool
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	msg := []byte("message")
+
+	kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+	require.NoError(t, err)
+
+	loads := 0
+	load := func() (*keyset.Handle, error) {
+		loads++
+		return kh, nil
+	}
+
+	t.Run("test primitives are pooled until invalidated", func(t *testing.T) {
+		pool := New()
+
+		signer, err := pool.Signer("key1", load)
+		require.NoError(t, err)
+
+		verifier, err := pool.Verifier("key1", load)
+		require.NoError(t, err)
+		require.Equal(t, 2, loads)
+
+		for i := 0; i < 3; i++ {
+			pooledSigner, err := pool.Signer("key1", load)
+			require.NoError(t, err)
+			require.True(t, signer == pooledSigner)
+
+			pooledVerifier, err := pool.Verifier("key1", load)
+			require.NoError(t, err)
+			require.True(t, verifier == pooledVerifier)
+		}
+
+		require.Equal(t, 2, loads)
+
+		sig, err := signer.Sign(msg)
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(sig, msg))
+
+		pool.Invalidate("key1")
+
+		_, err = pool.Signer("key1", load)
+		require.NoError(t, err)
+
+		_, err = pool.Verifier("key1", load)
+		require.NoError(t, err)
+		require.Equal(t, 4, loads)
+	})
+
+	t.Run("test verifier of a public keyset handle", func(t *testing.T) {
+		pubKH, err := kh.Public()
+		require.NoError(t, err)
+
+		verifier, err := New().Verifier("key1", func() (*keyset.Handle, error) { return pubKH, nil })
+		require.NoError(t, err)
+		require.NotNil(t, verifier)
+	})
+
+	t.Run("test primitive loaded during an invalidation is not pooled", func(t *testing.T) {
+		pool := New()
+
+		signer, err := pool.Signer("key1", func() (*keyset.Handle, error) {
+			pool.Invalidate("key1")
+			return kh, nil
+		})
+		require.NoError(t, err)
+		require.NotNil(t, signer)
+		require.Empty(t, pool.signers)
+
+		verifier, err := pool.Verifier("key1", func() (*keyset.Handle, error) {
+			pool.Invalidate("key1")
+			return kh, nil
+		})
+		require.NoError(t, err)
+		require.NotNil(t, verifier)
+		require.Empty(t, pool.verifiers)
+	})
+
+	t.Run("test signature domains are pooled until invalidated", func(t *testing.T) {
+		pool := New()
+		domainLoads := 0
+		loadDomain := func() (string, error) {
+			domainLoads++
+			return "domain", nil
+		}
+
+		for i := 0; i < 3; i++ {
+			domain, err := pool.Domain("key1", loadDomain)
+			require.NoError(t, err)
+			require.Equal(t, "domain", domain)
+		}
+
+		require.Equal(t, 1, domainLoads)
+
+		pool.Invalidate("key1")
+
+		_, err := pool.Domain("key1", loadDomain)
+		require.NoError(t, err)
+		require.Equal(t, 2, domainLoads)
+
+		domain, err := pool.Domain("key2", func() (string, error) {
+			pool.Invalidate("key2")
+			return "", nil
+		})
+		require.NoError(t, err)
+		require.Empty(t, domain)
+		require.NotContains(t, pool.domains, "key2")
+
+		loadErr := errors.New("load error")
+
+		_, err = pool.Domain("key3", func() (string, error) { return "", loadErr })
+		require.True(t, errors.Is(err, loadErr))
+		require.NotContains(t, pool.domains, "key3")
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		pool := New()
+		loadErr := errors.New("load error")
+
+		_, err := pool.Signer("key1", func() (*keyset.Handle, error) { return nil, loadErr })
+		require.True(t, errors.Is(err, loadErr))
+
+		_, err = pool.Verifier("key1", func() (*keyset.Handle, error) { return nil, loadErr })
+		require.True(t, errors.Is(err, loadErr))
+
+		aeadKH, err := keyset.NewHandle(aead.AES128GCMKeyTemplate())
+		require.NoError(t, err)
+
+		_, err = pool.Signer("key2", func() (*keyset.Handle, error) { return aeadKH, nil })
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create new signer")
+
+		_, err = pool.Verifier("key2", func() (*keyset.Handle, error) { return aeadKH, nil })
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create new verifier")
+
+		require.Empty(t, pool.signers)
+		require.Empty(t, pool.verifiers)
+	})
+
+	t.Run("test concurrent use", func(t *testing.T) {
+		pool := New()
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				signer, err := pool.Signer("key1", func() (*keyset.Handle, error) { return kh, nil })
+				require.NoError(t, err)
+
+				sig, err := signer.Sign(msg)
+				require.NoError(t, err)
+
+				verifier, err := pool.Verifier("key1", func() (*keyset.Handle, error) { return kh, nil })
+				require.NoError(t, err)
+				require.NoError(t, verifier.Verify(sig, msg))
+
+				pool.Invalidate("key1")
+			}()
+		}
+
+		wg.Wait()
+	})
+}
@@ -0,0 +1,46 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// WithKeyTypeAliases option is for creating and rotating keys with aliases of key types (eg "signing-default")
+// mapped to the concrete key types of the deployment (eg kms.ED25519Type). This allows changing centrally the
+// algorithm of the keys created through an alias. The keys are stored with the concrete key type.
+// Aliases must map to concrete key types, New fails otherwise.
+func WithKeyTypeAliases(aliases map[kms.KeyType]kms.KeyType) Option {
+	return func(opts *LocalKMS) {
+		opts.keyTypeAliases = aliases
+	}
+}
+
+// resolveKeyType returns the concrete key type of kt if it is an alias or a key class, or kt otherwise.
+func (l *LocalKMS) resolveKeyType(kt kms.KeyType) kms.KeyType {
+	if concrete, ok := l.keyTypeAliases[kt]; ok {
+		return concrete
+	}
+
+	if concrete, ok := l.keyClassDefaults[kt]; ok {
+		return concrete
+	}
+
+	return kt
+}
+
+func (l *LocalKMS) checkKeyTypeAliases() error {
+	for alias, kt := range l.keyTypeAliases {
+		if _, err := getKeyTemplate(kt); err != nil {
+			return fmt.Errorf("invalid key type alias %s to %s: %w", alias, kt, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_KeyTypeAliases(t *testing.T) {
+	const (
+		signingAlias    = kms.KeyType("signing-default")
+		encryptionAlias = kms.KeyType("encryption-default")
+	)
+
+	aliases := map[kms.KeyType]kms.KeyType{
+		signingAlias:    kms.ED25519Type,
+		encryptionAlias: kms.AES256GCMType,
+	}
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	}, WithKeyTypeAliases(aliases))
+	require.NoError(t, err)
+
+	t.Run("test create keys through aliases", func(t *testing.T) {
+		for alias, kt := range aliases {
+			keyID, kh, err := kmsService.Create(alias)
+			require.NoError(t, err)
+			require.NotNil(t, kh)
+
+			metadata, err := kmsService.getMetadata(keyID)
+			require.NoError(t, err)
+			require.Equal(t, kt, metadata.KeyType)
+
+			kh, err = kmsService.Get(keyID)
+			require.NoError(t, err)
+			require.Equal(t, kt, keyTypeOf(kh.(*keyset.Handle)))
+		}
+	})
+
+	t.Run("test concrete key types are still supported", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+
+		metadata, err := kmsService.getMetadata(keyID)
+		require.NoError(t, err)
+		require.Equal(t, kms.ECDSAP256Type, metadata.KeyType)
+	})
+
+	t.Run("test rotate through alias", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(signingAlias)
+		require.NoError(t, err)
+
+		newKeyID, _, err := kmsService.Rotate(signingAlias, keyID)
+		require.NoError(t, err)
+
+		metadata, err := kmsService.getMetadata(newKeyID)
+		require.NoError(t, err)
+		require.Equal(t, kms.ED25519Type, metadata.KeyType)
+	})
+
+	t.Run("test unknown alias", func(t *testing.T) {
+		_, _, err := kmsService.Create("unknown-default")
+		require.EqualError(t, err, "key type unrecognized")
+	})
+
+	t.Run("test alias to unknown key type", func(t *testing.T) {
+		_, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: createMasterKeyAndSecretLock(t),
+		}, WithKeyTypeAliases(map[kms.KeyType]kms.KeyType{signingAlias: "unknown"}))
+		require.EqualError(t, err, "failed to create local kms: invalid key type alias signing-default to unknown: "+
+			"key type unrecognized")
+	})
+}
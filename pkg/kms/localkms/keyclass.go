@@ -0,0 +1,59 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// keyClasses are the concrete key types each abstract key class can be configured to.
+var keyClasses = map[kms.KeyType][]kms.KeyType{ //nolint:gochecknoglobals
+	kms.ECDefaultType: {kms.ECDSAP256Type, kms.ECDSAP384Type, kms.ECDSAP521Type},
+	kms.AEADDefaultType: {
+		kms.AES128GCMType, kms.AES256GCMNoPrefixType, kms.AES256GCMType,
+		kms.ChaCha20Poly1305Type, kms.XChaCha20Poly1305Type,
+	},
+}
+
+// WithKeyClassDefaults option configures the concrete key types the abstract key classes (kms.ECDefaultType and
+// kms.AEADDefaultType) resolve to, eg kms.ECDefaultType to kms.ECDSAP384Type. Keys created or rotated with a key
+// class are stored with the concrete key type. The key classes not configured keep their defaults: kms.ECDSAP256Type
+// and kms.AES256GCMType. A key class must map to a concrete key type of the class, New fails otherwise.
+func WithKeyClassDefaults(defaults map[kms.KeyType]kms.KeyType) Option {
+	return func(opts *LocalKMS) {
+		for class, kt := range defaults {
+			opts.keyClassDefaults[class] = kt
+		}
+	}
+}
+
+func (l *LocalKMS) checkKeyClassDefaults() error {
+	for class, kt := range l.keyClassDefaults {
+		types, ok := keyClasses[class]
+		if !ok {
+			return fmt.Errorf("unknown key class %s", class)
+		}
+
+		if !containsKeyType(types, kt) {
+			return fmt.Errorf("invalid default key type %s for key class %s", kt, class)
+		}
+	}
+
+	return nil
+}
+
+func containsKeyType(types []kms.KeyType, kt kms.KeyType) bool {
+	for _, t := range types {
+		if t == kt {
+			return true
+		}
+	}
+
+	return false
+}
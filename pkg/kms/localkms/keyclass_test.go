@@ -0,0 +1,97 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_KeyClassDefaults(t *testing.T) {
+	newKMS := func(t *testing.T, opts ...Option) (*LocalKMS, error) {
+		return New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: createMasterKeyAndSecretLock(t),
+		}, opts...)
+	}
+
+	requireKeyType := func(t *testing.T, l *LocalKMS, class, expected kms.KeyType) {
+		keyID, kh, err := l.Create(class)
+		require.NoError(t, err)
+		require.Equal(t, expected, keyTypeOf(kh.(*keyset.Handle)))
+
+		metadata, err := l.getMetadata(keyID)
+		require.NoError(t, err)
+		require.Equal(t, expected, metadata.KeyType)
+	}
+
+	t.Run("test key classes resolve to the built-in defaults", func(t *testing.T) {
+		kmsService, err := newKMS(t)
+		require.NoError(t, err)
+
+		requireKeyType(t, kmsService, kms.ECDefaultType, kms.ECDSAP256Type)
+		requireKeyType(t, kmsService, kms.AEADDefaultType, kms.AES256GCMType)
+	})
+
+	t.Run("test key classes resolve to the configured key types", func(t *testing.T) {
+		kmsService, err := newKMS(t, WithKeyClassDefaults(map[kms.KeyType]kms.KeyType{
+			kms.ECDefaultType:   kms.ECDSAP384Type,
+			kms.AEADDefaultType: kms.XChaCha20Poly1305Type,
+		}))
+		require.NoError(t, err)
+
+		requireKeyType(t, kmsService, kms.ECDefaultType, kms.ECDSAP384Type)
+		requireKeyType(t, kmsService, kms.AEADDefaultType, kms.XChaCha20Poly1305Type)
+	})
+
+	t.Run("test unconfigured key class keeps its default", func(t *testing.T) {
+		kmsService, err := newKMS(t, WithKeyClassDefaults(map[kms.KeyType]kms.KeyType{
+			kms.ECDefaultType: kms.ECDSAP521Type,
+		}))
+		require.NoError(t, err)
+
+		requireKeyType(t, kmsService, kms.ECDefaultType, kms.ECDSAP521Type)
+		requireKeyType(t, kmsService, kms.AEADDefaultType, kms.AES256GCMType)
+	})
+
+	t.Run("test rotate with a key class", func(t *testing.T) {
+		kmsService, err := newKMS(t, WithKeyClassDefaults(map[kms.KeyType]kms.KeyType{
+			kms.ECDefaultType: kms.ECDSAP384Type,
+		}))
+		require.NoError(t, err)
+
+		keyID, _, err := kmsService.Create(kms.ECDefaultType)
+		require.NoError(t, err)
+
+		newKeyID, _, err := kmsService.Rotate(kms.ECDefaultType, keyID)
+		require.NoError(t, err)
+
+		metadata, err := kmsService.getMetadata(newKeyID)
+		require.NoError(t, err)
+		require.Equal(t, kms.ECDSAP384Type, metadata.KeyType)
+	})
+
+	t.Run("test key class configured to a key type of another class", func(t *testing.T) {
+		_, err := newKMS(t, WithKeyClassDefaults(map[kms.KeyType]kms.KeyType{
+			kms.ECDefaultType: kms.AES256GCMType,
+		}))
+		require.EqualError(t, err, "failed to create local kms: invalid default key type AES256GCM for key class "+
+			"ECDefault")
+	})
+
+	t.Run("test unknown key class", func(t *testing.T) {
+		_, err := newKMS(t, WithKeyClassDefaults(map[kms.KeyType]kms.KeyType{
+			"RSADefault": kms.RSAType,
+		}))
+		require.EqualError(t, err, "failed to create local kms: unknown key class RSADefault")
+	})
+}
@@ -0,0 +1,211 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	aesgcmpb "github.com/google/tink/go/proto/aes_gcm_go_proto"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	hmacpb "github.com/google/tink/go/proto/hmac_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+const (
+	aes256KeySize = 32
+
+	hmacMinKeySize     = 16
+	hmacDefaultKeySize = 32
+	hmacMinTagSize     = 10
+	hmacSHA256TagSize  = 32
+)
+
+// KeyParams are the parameters of the keys created with CreateWithParams. Zero values select the default of the
+// key type.
+type KeyParams struct {
+	// KeySize is the size of the key in bytes.
+	KeySize uint32
+	// TagSize is the size of the MAC tags in bytes, HMAC keys only.
+	TagSize uint32
+}
+
+// CreateWithParams creates a new key of the key type kt with the parameters params, stores it and returns its stored
+// ID and key handle. AES-GCM keys support the KeySize of their key type only, 16 bytes for kms.AES128GCMType and 32
+// bytes for kms.AES256GCMType, which is the default. HMAC keys (kms.HMACSHA256Tag256Type) support a KeySize of at
+// least 16 bytes and a TagSize between 10 and 32 bytes, 32 bytes by default for both. AES-192 (24 bytes) keys are not
+// supported, the vendored Tink only accepts 16 or 32 bytes AES-GCM keys. Other key types and parameter combinations
+// are rejected. Rotating the key creates a key with the same parameters.
+func (l *LocalKMS) CreateWithParams(kt kms.KeyType, params KeyParams, opts ...kms.KeyOption) (string, interface{},
+	error) {
+	keyOpts := &kms.KeyOpts{}
+
+	for _, opt := range opts {
+		opt(keyOpts)
+	}
+
+	kID, kh, err := l.createWithParams(l.resolveKeyType(kt), params, keyOpts.ExternalRef())
+	l.audit(&AuditRecord{Operation: AuditOpCreate, KeyID: kID, KeyType: kt}, err)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return kID, kh, nil
+}
+
+func (l *LocalKMS) createWithParams(kt kms.KeyType, params KeyParams, externalRef string) (string, interface{},
+	error) {
+	var (
+		keyTemplate *tinkpb.KeyTemplate
+		err         error
+	)
+
+	switch kt {
+	case kms.AES128GCMType, kms.AES256GCMType:
+		keyTemplate, err = aesGCMKeyTemplate(kt, params)
+	case kms.HMACSHA256Tag256Type:
+		keyTemplate, err = hmacSHA256KeyTemplate(params)
+	default:
+		err = fmt.Errorf("key type %s does not support parameters", kt)
+	}
+
+	if err != nil {
+		return "", nil, fmt.Errorf("create with params: %w", err)
+	}
+
+	return l.createFromTemplate(keyTemplate, &keyMetadata{KeyType: kt, ExternalRef: externalRef})
+}
+
+// aesGCMKeyTemplate returns the AES-GCM key template of the key type kt for params.
+func aesGCMKeyTemplate(kt kms.KeyType, params KeyParams) (*tinkpb.KeyTemplate, error) {
+	if params.TagSize != 0 {
+		return nil, fmt.Errorf("tag size is not supported by AES-GCM keys")
+	}
+
+	var keySize uint32 = aes256KeySize
+
+	if kt == kms.AES128GCMType {
+		keySize = aes128KeySize
+	}
+
+	if params.KeySize != 0 && params.KeySize != keySize {
+		return nil, fmt.Errorf("AES-GCM key size %d bytes does not match key type %s, want %d", params.KeySize,
+			kt, keySize)
+	}
+
+	format, err := proto.Marshal(&aesgcmpb.AesGcmKeyFormat{KeySize: keySize})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tinkpb.KeyTemplate{
+		TypeUrl:          aesGCMTypeURL,
+		Value:            format,
+		OutputPrefixType: tinkpb.OutputPrefixType_TINK,
+	}, nil
+}
+
+// hmacSHA256KeyTemplate returns the HMAC-SHA256 key template for params.
+func hmacSHA256KeyTemplate(params KeyParams) (*tinkpb.KeyTemplate, error) {
+	keySize, tagSize := params.KeySize, params.TagSize
+
+	if keySize == 0 {
+		keySize = hmacDefaultKeySize
+	}
+
+	if tagSize == 0 {
+		tagSize = hmacSHA256TagSize
+	}
+
+	if keySize < hmacMinKeySize {
+		return nil, fmt.Errorf("HMAC key size %d bytes is too small, want at least %d", keySize, hmacMinKeySize)
+	}
+
+	if tagSize < hmacMinTagSize || tagSize > hmacSHA256TagSize {
+		return nil, fmt.Errorf("unsupported HMAC-SHA256 tag size %d bytes, want between %d and %d", tagSize,
+			hmacMinTagSize, hmacSHA256TagSize)
+	}
+
+	format, err := proto.Marshal(&hmacpb.HmacKeyFormat{
+		Params:  &hmacpb.HmacParams{Hash: commonpb.HashType_SHA256, TagSize: tagSize},
+		KeySize: keySize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tinkpb.KeyTemplate{
+		TypeUrl:          hmacTypeURL,
+		Value:            format,
+		OutputPrefixType: tinkpb.OutputPrefixType_TINK,
+	}, nil
+}
+
+// rotationKeyTemplate returns the template of the key rotating the keyset kh of key type kt: the template of kt, with
+// the parameters of the primary key of kh if it is a key of the same type (eg: the tag size of HMAC keys created with
+// CreateWithParams or the hash function of ECDSA keys created with kms.WithSignatureHash).
+func rotationKeyTemplate(kt kms.KeyType, kh *keyset.Handle) (*tinkpb.KeyTemplate, error) {
+	keyTemplate, err := getKeyTemplate(kt)
+	if err != nil {
+		return nil, err
+	}
+
+	primary := primaryKey(kh)
+	if primary == nil || primary.KeyData.TypeUrl != keyTemplate.TypeUrl {
+		return keyTemplate, nil
+	}
+
+	switch primary.KeyData.TypeUrl {
+	case hmacTypeURL:
+		return hmacRotationKeyTemplate(primary)
+	case ecdsaSignerTypeURL:
+		return ecdsaRotationKeyTemplate(kt, primary, keyTemplate)
+	default:
+		return keyTemplate, nil
+	}
+}
+
+// hmacRotationKeyTemplate returns the template of the HMAC keys with the key size and parameters of key.
+func hmacRotationKeyTemplate(key *tinkpb.Keyset_Key) (*tinkpb.KeyTemplate, error) {
+	hmacKey := new(hmacpb.HmacKey)
+
+	if err := proto.Unmarshal(key.KeyData.Value, hmacKey); err != nil {
+		return nil, fmt.Errorf("rotation key template: %w", err)
+	}
+
+	format, err := proto.Marshal(&hmacpb.HmacKeyFormat{
+		Params:  hmacKey.Params,
+		KeySize: uint32(len(hmacKey.KeyValue)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rotation key template: %w", err)
+	}
+
+	return &tinkpb.KeyTemplate{
+		TypeUrl:          hmacTypeURL,
+		Value:            format,
+		OutputPrefixType: key.OutputPrefixType,
+	}, nil
+}
+
+// primaryKey returns the primary key of kh, nil if it has none.
+func primaryKey(kh *keyset.Handle) *tinkpb.Keyset_Key {
+	ks := insecurecleartextkeyset.KeysetMaterial(kh)
+
+	for _, key := range ks.Key {
+		if key.KeyId == ks.PrimaryKeyId {
+			return key
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/mac"
+	aesgcmpb "github.com/google/tink/go/proto/aes_gcm_go_proto"
+	hmacpb "github.com/google/tink/go/proto/hmac_go_proto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_CreateWithParams(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	t.Run("test AES-GCM key of the requested size", func(t *testing.T) {
+		keyID, kh, err := kmsService.CreateWithParams(kms.AES128GCMType, KeyParams{KeySize: 16})
+		require.NoError(t, err)
+		require.Equal(t, kms.AES128GCMType, keyTypeOf(kh.(*keyset.Handle)))
+
+		metadata, err := kmsService.getMetadata(keyID)
+		require.NoError(t, err)
+		require.Equal(t, kms.AES128GCMType, metadata.KeyType)
+
+		// the stored key is usable
+		kh, err = kmsService.Get(keyID)
+		require.NoError(t, err)
+
+		p, err := aead.New(kh.(*keyset.Handle))
+		require.NoError(t, err)
+
+		ct, err := p.Encrypt([]byte("plaintext"), []byte("aad"))
+		require.NoError(t, err)
+
+		pt, err := p.Decrypt(ct, []byte("aad"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("plaintext"), pt)
+	})
+
+	t.Run("test AES-GCM keys default to the size of their key type", func(t *testing.T) {
+		for kt, size := range map[kms.KeyType]int{kms.AES128GCMType: 16, kms.AES256GCMType: 32} {
+			_, kh, err := kmsService.CreateWithParams(kt, KeyParams{})
+			require.NoError(t, err)
+			require.Equal(t, kt, keyTypeOf(kh.(*keyset.Handle)))
+
+			aesKey := new(aesgcmpb.AesGcmKey)
+			require.NoError(t, proto.Unmarshal(primaryKey(kh.(*keyset.Handle)).KeyData.Value, aesKey))
+			require.Len(t, aesKey.KeyValue, size)
+		}
+	})
+
+	t.Run("test AES-GCM key sizes contradicting the key type are rejected", func(t *testing.T) {
+		_, _, err := kmsService.CreateWithParams(kms.AES256GCMType, KeyParams{KeySize: 16})
+		require.EqualError(t, err, "create with params: AES-GCM key size 16 bytes does not match key type "+
+			"AES256GCM, want 32")
+
+		_, _, err = kmsService.CreateWithParams(kms.AES128GCMType, KeyParams{KeySize: 32})
+		require.EqualError(t, err, "create with params: AES-GCM key size 32 bytes does not match key type "+
+			"AES128GCM, want 16")
+	})
+
+	t.Run("test AES-192 keys are rejected", func(t *testing.T) {
+		_, _, err := kmsService.CreateWithParams(kms.AES256GCMType, KeyParams{KeySize: 24})
+		require.EqualError(t, err, "create with params: AES-GCM key size 24 bytes does not match key type "+
+			"AES256GCM, want 32")
+	})
+
+	t.Run("test HMAC key with a custom tag size", func(t *testing.T) {
+		keyID, _, err := kmsService.CreateWithParams(kms.HMACSHA256Tag256Type, KeyParams{TagSize: 16})
+		require.NoError(t, err)
+
+		kh, err := kmsService.Get(keyID)
+		require.NoError(t, err)
+
+		m, err := mac.New(kh.(*keyset.Handle))
+		require.NoError(t, err)
+
+		tag, err := m.ComputeMAC([]byte("data"))
+		require.NoError(t, err)
+		// the tag is prefixed with the Tink output prefix
+		require.Len(t, tag, 5+16)
+		require.NoError(t, m.VerifyMAC(tag, []byte("data")))
+		require.Error(t, m.VerifyMAC(tag, []byte("other")))
+	})
+
+	t.Run("test HMAC key rotated with its parameters", func(t *testing.T) {
+		keyID, _, err := kmsService.CreateWithParams(kms.HMACSHA256Tag256Type, KeyParams{KeySize: 48, TagSize: 16})
+		require.NoError(t, err)
+
+		_, kh, err := kmsService.Rotate(kms.HMACSHA256Tag256Type, keyID)
+		require.NoError(t, err)
+
+		ks := insecurecleartextkeyset.KeysetMaterial(kh.(*keyset.Handle))
+		require.Len(t, ks.Key, 2)
+
+		for _, key := range ks.Key {
+			hmacKey := new(hmacpb.HmacKey)
+			require.NoError(t, proto.Unmarshal(key.KeyData.Value, hmacKey))
+			require.Len(t, hmacKey.KeyValue, 48)
+			require.EqualValues(t, 16, hmacKey.Params.TagSize)
+		}
+	})
+
+	t.Run("test key classes and aliases are resolved", func(t *testing.T) {
+		_, kh, err := kmsService.CreateWithParams(kms.AEADDefaultType, KeyParams{KeySize: 32})
+		require.NoError(t, err)
+		require.Equal(t, kms.AES256GCMType, keyTypeOf(kh.(*keyset.Handle)))
+	})
+
+	t.Run("test invalid parameters are rejected", func(t *testing.T) {
+		for _, tc := range []struct {
+			kt     kms.KeyType
+			params KeyParams
+			err    string
+		}{
+			{
+				kt:     kms.AES128GCMType,
+				params: KeyParams{KeySize: 16, TagSize: 16},
+				err:    "create with params: tag size is not supported by AES-GCM keys",
+			},
+			{
+				kt:     kms.HMACSHA256Tag256Type,
+				params: KeyParams{KeySize: 8},
+				err:    "create with params: HMAC key size 8 bytes is too small, want at least 16",
+			},
+			{
+				kt:     kms.HMACSHA256Tag256Type,
+				params: KeyParams{TagSize: 8},
+				err:    "create with params: unsupported HMAC-SHA256 tag size 8 bytes, want between 10 and 32",
+			},
+			{
+				kt:     kms.HMACSHA256Tag256Type,
+				params: KeyParams{TagSize: 64},
+				err:    "create with params: unsupported HMAC-SHA256 tag size 64 bytes, want between 10 and 32",
+			},
+			{
+				kt:     kms.ED25519Type,
+				params: KeyParams{KeySize: 32},
+				err:    "create with params: key type ED25519 does not support parameters",
+			},
+		} {
+			_, _, err := kmsService.CreateWithParams(tc.kt, tc.params)
+			require.EqualError(t, err, tc.err)
+		}
+	})
+}
@@ -0,0 +1,438 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// keyPoolPrefix is the prefix of the keys of the records of the pooled keys in the keystore, followed by the key type
+const keyPoolPrefix = "keypool_"
+
+// claimedPooledKey is the value of the record of a pooled key handed out, until the record is deleted. It records
+// the key as no longer pooled even if the record could not be deleted.
+const claimedPooledKey = "claimed"
+
+// ErrNoKeyPool is returned by CreateFromPool for key types without a key pool (see WithKeyPool).
+var ErrNoKeyPool = errors.New("no key pool for key type")
+
+// KeyPoolConfig configures the pool of pre-generated keys of a key type.
+type KeyPoolConfig struct {
+	// Size is the number of keys the pool is filled up to.
+	Size int
+	// RefillThreshold is the number of remaining keys below which the pool is refilled, it defaults to Size.
+	RefillThreshold int
+}
+
+// WithKeyPool option sets a pool of pre-generated keys of type kt, which can be an alias or a key class (see
+// WithKeyTypeAliases and WithKeyClassDefaults). The keys are generated in the background and handed out by
+// CreateFromPool, which avoids generating keys on the hot path, eg: when establishing many connections. The pooled
+// keys are stored in the keystore: the keys not handed out before a restart are in the pool of the next LocalKMS,
+// and the LocalKMS instances sharing a keystore hand out each pooled key only once. Until they are handed out, the
+// pooled keys are not listed by ListByKeyType nor counted by Stats, and can't be rotated. The background refills are
+// stopped by StopKeyPools. The key pools are not supported in multi-tenant mode (see WithMasterKeyURIResolver).
+func WithKeyPool(kt kms.KeyType, config KeyPoolConfig) Option {
+	return func(opts *LocalKMS) {
+		if opts.keyPoolConfigs == nil {
+			opts.keyPoolConfigs = map[kms.KeyType]KeyPoolConfig{}
+		}
+
+		opts.keyPoolConfigs[kt] = config
+	}
+}
+
+// pooledKey is a key of a pool, its handle is nil if the key was loaded from the keystore.
+type pooledKey struct {
+	keyID string
+	kh    *keyset.Handle
+}
+
+// keyPool is the pool of pre-generated keys of a key type, shared by the copies of the LocalKMS (see WithContext).
+type keyPool struct {
+	keyType   kms.KeyType
+	config    KeyPoolConfig
+	mu        sync.Mutex
+	keys      []pooledKey
+	refilling bool
+	stopped   bool
+	refills   sync.WaitGroup
+}
+
+// take removes the oldest key of the pool, it returns false if the pool is empty.
+func (p *keyPool) take() (pooledKey, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.keys) == 0 {
+		return pooledKey{}, false
+	}
+
+	k := p.keys[0]
+	p.keys = p.keys[1:]
+
+	return k, true
+}
+
+func (p *keyPool) add(k pooledKey) {
+	p.mu.Lock()
+	p.keys = append(p.keys, k)
+	p.mu.Unlock()
+}
+
+// startRefill returns true if the pool is below its refill threshold and is neither already being refilled nor
+// stopped, the caller must then refill it and call endRefill.
+func (p *keyPool) startRefill() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.refilling || p.stopped || len(p.keys) >= p.config.RefillThreshold {
+		return false
+	}
+
+	p.refilling = true
+	p.refills.Add(1)
+
+	return true
+}
+
+func (p *keyPool) endRefill() {
+	p.mu.Lock()
+	p.refilling = false
+	p.mu.Unlock()
+
+	p.refills.Done()
+}
+
+// needsKey returns true if the pool being refilled is neither full nor stopped.
+func (p *keyPool) needsKey() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return !p.stopped && len(p.keys) < p.config.Size
+}
+
+// stop stops refilling the pool, it waits for the refill in progress, if any, to end.
+func (p *keyPool) stop() {
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
+
+	p.refills.Wait()
+}
+
+func (p *keyPool) available() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.keys)
+}
+
+// startKeyPools loads the pooled keys stored in the keystore and starts filling the key pools.
+func (l *LocalKMS) startKeyPools() error {
+	l.keyPools = map[kms.KeyType]*keyPool{}
+
+	// the pools are shared by the copies of the LocalKMS bound to the tenants
+	if len(l.keyPoolConfigs) > 0 && l.keyURIResolver != nil {
+		return errors.New("key pools are not supported in multi-tenant mode")
+	}
+
+	for kt, config := range l.keyPoolConfigs {
+		kt = l.resolveKeyType(kt)
+
+		if config.Size <= 0 {
+			return fmt.Errorf("invalid size %d of the key pool of %s", config.Size, kt)
+		}
+
+		if config.RefillThreshold <= 0 || config.RefillThreshold > config.Size {
+			config.RefillThreshold = config.Size
+		}
+
+		if _, err := getKeyTemplate(kt); err != nil {
+			return fmt.Errorf("key pool of %s: %w", kt, err)
+		}
+
+		keyIDs, err := l.pooledKeyIDs(kt)
+		if err != nil {
+			return fmt.Errorf("load the key pool of %s: %w", kt, err)
+		}
+
+		p := &keyPool{keyType: kt, config: config}
+
+		for _, keyID := range keyIDs {
+			p.keys = append(p.keys, pooledKey{keyID: keyID})
+		}
+
+		l.keyPools[kt] = p
+
+		l.refillKeyPool(p)
+	}
+
+	return nil
+}
+
+// CreateFromPool hands out a key of type kt from its pool of pre-generated keys (see WithKeyPool) and returns its
+// stored ID and key handle, as Create. The key is created on demand if the pool is empty. The pool is refilled in the
+// background once it is below its refill threshold. It returns an error wrapping ErrNoKeyPool if kt has no key pool.
+func (l *LocalKMS) CreateFromPool(kt kms.KeyType) (string, interface{}, error) {
+	kID, kh, err := l.createFromPool(kt)
+	l.audit(&AuditRecord{Operation: AuditOpCreateFromPool, KeyID: kID, KeyType: kt}, err)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return kID, kh, nil
+}
+
+func (l *LocalKMS) createFromPool(kt kms.KeyType) (string, *keyset.Handle, error) {
+	done, err := l.beginWrite()
+	if err != nil {
+		return "", nil, fmt.Errorf("create from pool: %w", err)
+	}
+
+	defer done()
+
+	p, ok := l.keyPools[l.resolveKeyType(kt)]
+	if !ok {
+		return "", nil, fmt.Errorf("create from pool: %w: %s", ErrNoKeyPool, kt)
+	}
+
+	defer l.refillKeyPool(p)
+
+	k, err := l.claimPooledKey(p)
+	if err != nil {
+		return "", nil, fmt.Errorf("create from pool: %w", err)
+	}
+
+	if k == nil {
+		return l.create(p.keyType, &kms.KeyOpts{})
+	}
+
+	if k.kh != nil {
+		return k.keyID, k.kh, nil
+	}
+
+	kh, err := l.getKeySet(k.keyID)
+	if err != nil {
+		return "", nil, fmt.Errorf("create from pool: read pooled key: %w", err)
+	}
+
+	return k.keyID, kh, nil
+}
+
+// claimPooledKey takes the oldest key of p which is still pooled in the keystore and records it as handed out. The
+// keys handed out by another LocalKMS sharing the keystore are skipped. It returns nil if the pool is empty.
+func (l *LocalKMS) claimPooledKey(p *keyPool) (*pooledKey, error) {
+	if _, ok := l.store.(storage.VersionedStore); !ok {
+		logger.Warnf("keystore does not support compare-and-swap, a pooled key of %s may be handed out twice "+
+			"by LocalKMS instances sharing the keystore", p.keyType)
+	}
+
+	for k, ok := p.take(); ok; k, ok = p.take() {
+		if err := l.checkTenantKeySet(k.keyID); err != nil {
+			p.add(k)
+
+			return nil, fmt.Errorf("pooled key %s: %w", k.keyID, err)
+		}
+
+		record := pooledKeyRecord(p.keyType, k.keyID)
+
+		err := storage.PutIfMatch(l.store, record, []byte(claimedPooledKey), storage.Version([]byte(k.keyID)))
+		if errors.Is(err, storage.ErrVersionConflict) {
+			continue
+		}
+
+		if err != nil {
+			p.add(k)
+
+			return nil, fmt.Errorf("claim pooled key: %w", err)
+		}
+
+		// the key is handed out even if its record is left, as claimed
+		if err = l.store.Delete(record); err != nil {
+			logger.Warnf("failed to delete the record of pooled key %s: %v", k.keyID, err)
+		}
+
+		return &k, nil
+	}
+
+	return nil, nil
+}
+
+// StopKeyPools stops refilling the key pools in the background, it returns once the refills in progress end.
+// CreateFromPool still hands out the remaining pooled keys, the keys are then created on demand.
+func (l *LocalKMS) StopKeyPools() {
+	for _, p := range l.keyPools {
+		p.stop()
+	}
+}
+
+// KeyPoolAvailable returns the number of pre-generated keys of type kt available in its pool.
+func (l *LocalKMS) KeyPoolAvailable(kt kms.KeyType) int {
+	p, ok := l.keyPools[l.resolveKeyType(kt)]
+	if !ok {
+		return 0
+	}
+
+	return p.available()
+}
+
+// DrainKeyPool deletes the pre-generated keys of type kt not handed out yet, including the keys stored by a previous
+// LocalKMS whose key pool is no longer configured. The pool is refilled with new keys if it is configured.
+func (l *LocalKMS) DrainKeyPool(kt kms.KeyType) error {
+	done, err := l.beginWrite()
+	if err != nil {
+		return fmt.Errorf("drain key pool: %w", err)
+	}
+
+	defer done()
+
+	kt = l.resolveKeyType(kt)
+
+	p, ok := l.keyPools[kt]
+	if ok {
+		for k, found := p.take(); found; k, found = p.take() {
+			err = l.deletePooledKey(kt, k.keyID)
+			if err != nil {
+				return fmt.Errorf("drain key pool: %w", err)
+			}
+		}
+	}
+
+	keyIDs, err := l.pooledKeyIDs(kt)
+	if err != nil {
+		return fmt.Errorf("drain key pool: %w", err)
+	}
+
+	for _, keyID := range keyIDs {
+		err = l.deletePooledKey(kt, keyID)
+		if err != nil {
+			return fmt.Errorf("drain key pool: %w", err)
+		}
+	}
+
+	if ok {
+		l.refillKeyPool(p)
+	}
+
+	return nil
+}
+
+// refillKeyPool starts refilling p in the background if it is below its refill threshold.
+func (l *LocalKMS) refillKeyPool(p *keyPool) {
+	if !p.startRefill() {
+		return
+	}
+
+	go func() {
+		defer p.endRefill()
+
+		for p.needsKey() {
+			k, err := l.createPooledKey(p.keyType)
+			if err != nil {
+				// refilling is retried when the next key is handed out
+				l.audit(&AuditRecord{Operation: AuditOpRefillKeyPool, KeyType: p.keyType}, err)
+
+				return
+			}
+
+			p.add(k)
+		}
+	}()
+}
+
+// createPooledKey creates a new key of type kt and records it as pooled, it is deleted if it cannot be recorded.
+func (l *LocalKMS) createPooledKey(kt kms.KeyType) (pooledKey, error) {
+	kID, kh, err := l.create(kt, &kms.KeyOpts{})
+	if err != nil {
+		return pooledKey{}, err
+	}
+
+	err = l.store.Put(pooledKeyRecord(kt, kID), []byte(kID))
+	if err != nil {
+		_ = l.deleteKeySet(kID) //nolint:errcheck // the key is unusable anyway
+
+		return pooledKey{}, fmt.Errorf("save pooled key: %w", err)
+	}
+
+	return pooledKey{keyID: kID, kh: kh}, nil
+}
+
+// deletePooledKey deletes the pooled key keyID, unless it was handed out by another LocalKMS sharing the keystore.
+func (l *LocalKMS) deletePooledKey(kt kms.KeyType, keyID string) error {
+	record := pooledKeyRecord(kt, keyID)
+
+	err := storage.PutIfMatch(l.store, record, []byte(claimedPooledKey), storage.Version([]byte(keyID)))
+	if errors.Is(err, storage.ErrVersionConflict) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = l.store.Delete(record)
+	if err != nil {
+		return err
+	}
+
+	return l.deleteKeySet(keyID)
+}
+
+// pooledKeyIDs returns the IDs of the pooled keys of type kt stored in the keystore.
+func (l *LocalKMS) pooledKeyIDs(kt kms.KeyType) ([]string, error) {
+	return l.iteratePooledKeyIDs(pooledKeyRecord(kt, ""))
+}
+
+// allPooledKeyIDs returns the set of the IDs of the pooled keys of any type stored in the keystore.
+func (l *LocalKMS) allPooledKeyIDs() (map[string]bool, error) {
+	keyIDs, err := l.iteratePooledKeyIDs(keyPoolPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	pooled := make(map[string]bool, len(keyIDs))
+
+	for _, keyID := range keyIDs {
+		pooled[keyID] = true
+	}
+
+	return pooled, nil
+}
+
+// iteratePooledKeyIDs returns the IDs of the pooled keys whose records start with prefix, the keys handed out are
+// skipped.
+func (l *LocalKMS) iteratePooledKeyIDs(prefix string) ([]string, error) {
+	itr := l.store.Iterator(prefix, prefix+"~")
+	defer itr.Release()
+
+	var keyIDs []string
+
+	for itr.Next() {
+		// the value of the record is the key ID (see createPooledKey)
+		if keyID := string(itr.Value()); keyID != claimedPooledKey {
+			keyIDs = append(keyIDs, keyID)
+		}
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, err
+	}
+
+	return keyIDs, nil
+}
+
+func pooledKeyRecord(kt kms.KeyType, keyID string) string {
+	return keyPoolPrefix + string(kt) + "_" + keyID
+}
@@ -0,0 +1,286 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestLocalKMS_CreateFromPool(t *testing.T) {
+	const (
+		poolSize = 3
+		waitFor  = 5 * time.Second
+		tick     = 10 * time.Millisecond
+	)
+
+	secretLock := createMasterKeyAndSecretLock(t)
+
+	newKMS := func(t *testing.T, store storage.Store, opts ...Option) *LocalKMS {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		}, opts...)
+		require.NoError(t, err)
+
+		return k
+	}
+
+	withPool := WithKeyPool(kms.ED25519Type, KeyPoolConfig{Size: poolSize})
+
+	t.Run("test keys are handed out from the pool and the pool is refilled", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}}, withPool)
+
+		require.Eventually(t, func() bool { return kmsService.KeyPoolAvailable(kms.ED25519Type) == poolSize },
+			waitFor, tick)
+
+		kID, kh, err := kmsService.CreateFromPool(kms.ED25519Type)
+		require.NoError(t, err)
+
+		// the key is a regular key of the KMS
+		stored, err := kmsService.Get(kID)
+		require.NoError(t, err)
+		require.Equal(t, kh.(*keyset.Handle).String(), stored.(*keyset.Handle).String())
+
+		_, err = signature.NewSigner(kh.(*keyset.Handle))
+		require.NoError(t, err)
+
+		pooled, err := kmsService.pooledKeyIDs(kms.ED25519Type)
+		require.NoError(t, err)
+		require.NotContains(t, pooled, kID)
+
+		require.Eventually(t, func() bool { return kmsService.KeyPoolAvailable(kms.ED25519Type) == poolSize },
+			waitFor, tick)
+	})
+
+	t.Run("test pooled keys survive a restart", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		kmsService := newKMS(t, store, withPool)
+
+		require.Eventually(t, func() bool { return kmsService.KeyPoolAvailable(kms.ED25519Type) == poolSize },
+			waitFor, tick)
+
+		pooled, err := kmsService.pooledKeyIDs(kms.ED25519Type)
+		require.NoError(t, err)
+		require.Len(t, pooled, poolSize)
+
+		// the pool of the new KMS is filled with the keys stored by the previous one
+		kmsService = newKMS(t, store, withPool)
+		require.Equal(t, poolSize, kmsService.KeyPoolAvailable(kms.ED25519Type))
+
+		kID, kh, err := kmsService.CreateFromPool(kms.ED25519Type)
+		require.NoError(t, err)
+		require.Contains(t, pooled, kID)
+		require.NotNil(t, kh)
+	})
+
+	t.Run("test key created on demand if the pool cannot be filled", func(t *testing.T) {
+		auditLogger := &recordingAuditLogger{}
+		store := &failingPutStore{
+			Store:   &mockstorage.MockStore{Store: map[string][]byte{}},
+			failPut: func(k string) bool { return strings.HasPrefix(k, keyPoolPrefix) },
+		}
+		kmsService := newKMS(t, store, withPool, WithAuditLogger(auditLogger))
+
+		kID, kh, err := kmsService.CreateFromPool(kms.ED25519Type)
+		require.NoError(t, err)
+		require.NotNil(t, kh)
+		require.Equal(t, 0, kmsService.KeyPoolAvailable(kms.ED25519Type))
+
+		// the keys which could not be pooled are deleted
+		require.Eventually(t, func() bool {
+			keyIDs, err := kmsService.keySetIDs()
+			require.NoError(t, err)
+
+			return len(keyIDs) == 1 && keyIDs[0] == kID
+		}, waitFor, tick)
+
+		require.Eventually(t, func() bool {
+			auditLogger.mu.Lock()
+			defer auditLogger.mu.Unlock()
+
+			for _, record := range auditLogger.records {
+				if record.Operation == AuditOpRefillKeyPool && record.Outcome == AuditOutcomeFailure {
+					return true
+				}
+			}
+
+			return false
+		}, waitFor, tick)
+	})
+
+	t.Run("test drain key pool", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		kmsService := newKMS(t, store, withPool)
+
+		require.Eventually(t, func() bool { return kmsService.KeyPoolAvailable(kms.ED25519Type) == poolSize },
+			waitFor, tick)
+
+		// the pooled keys of a pool no longer configured are deleted
+		kmsService = newKMS(t, store)
+		require.Equal(t, 0, kmsService.KeyPoolAvailable(kms.ED25519Type))
+
+		require.NoError(t, kmsService.DrainKeyPool(kms.ED25519Type))
+
+		pooled, err := kmsService.pooledKeyIDs(kms.ED25519Type)
+		require.NoError(t, err)
+		require.Empty(t, pooled)
+
+		keyIDs, err := kmsService.keySetIDs()
+		require.NoError(t, err)
+		require.Empty(t, keyIDs)
+	})
+
+	t.Run("test pooled keys are not listed, counted or rotated", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}}, withPool)
+
+		require.Eventually(t, func() bool { return kmsService.KeyPoolAvailable(kms.ED25519Type) == poolSize },
+			waitFor, tick)
+
+		keyIDs, err := kmsService.ListByKeyType(kms.ED25519Type)
+		require.NoError(t, err)
+		require.Empty(t, keyIDs)
+
+		stats, err := kmsService.Stats()
+		require.NoError(t, err)
+		require.Equal(t, 0, stats.TotalKeys)
+
+		pooled, err := kmsService.pooledKeyIDs(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, _, err = kmsService.Rotate(kms.ED25519Type, pooled[0])
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is pooled")
+
+		// the key is a regular key once handed out
+		kID, _, err := kmsService.CreateFromPool(kms.ED25519Type)
+		require.NoError(t, err)
+
+		keyIDs, err = kmsService.ListByKeyType(kms.ED25519Type)
+		require.NoError(t, err)
+		require.Equal(t, []string{kID}, keyIDs)
+
+		stats, err = kmsService.Stats()
+		require.NoError(t, err)
+		require.Equal(t, 1, stats.TotalKeys)
+
+		_, _, err = kmsService.Rotate(kms.ED25519Type, kID)
+		require.NoError(t, err)
+	})
+
+	t.Run("test pooled keys are handed out once by the KMS sharing a keystore", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		kms1 := newKMS(t, store, withPool)
+
+		require.Eventually(t, func() bool { return kms1.KeyPoolAvailable(kms.ED25519Type) == poolSize },
+			waitFor, tick)
+
+		kms1.StopKeyPools()
+
+		// the second KMS loads the same pooled keys
+		kms2 := newKMS(t, store, withPool)
+		kms2.StopKeyPools()
+
+		handedOut := map[string]bool{}
+
+		for i := 0; i < poolSize; i++ {
+			for _, k := range []*LocalKMS{kms1, kms2} {
+				kID, _, err := k.CreateFromPool(kms.ED25519Type)
+				require.NoError(t, err)
+				require.False(t, handedOut[kID], "key %s handed out twice", kID)
+
+				handedOut[kID] = true
+			}
+		}
+
+		pooled, err := kms1.pooledKeyIDs(kms.ED25519Type)
+		require.NoError(t, err)
+		require.Empty(t, pooled)
+	})
+
+	t.Run("test stop key pools", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}}, withPool)
+		kmsService.StopKeyPools()
+
+		available := kmsService.KeyPoolAvailable(kms.ED25519Type)
+
+		for i := 0; i <= available; i++ {
+			_, _, err := kmsService.CreateFromPool(kms.ED25519Type)
+			require.NoError(t, err)
+		}
+
+		// the pool is not refilled
+		time.Sleep(10 * tick)
+		require.Equal(t, 0, kmsService.KeyPoolAvailable(kms.ED25519Type))
+	})
+
+	t.Run("test key type without pool", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}}, withPool)
+
+		_, _, err := kmsService.CreateFromPool(kms.ECDSAP256Type)
+		require.True(t, errors.Is(err, ErrNoKeyPool))
+	})
+
+	t.Run("test invalid key pools", func(t *testing.T) {
+		_, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: secretLock,
+		}, WithKeyPool(kms.ED25519Type, KeyPoolConfig{}))
+		require.EqualError(t, err, "failed to create local kms: invalid size 0 of the key pool of ED25519")
+
+		_, err = New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: secretLock,
+		}, WithKeyPool("unknown", KeyPoolConfig{Size: poolSize}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key pool of unknown")
+	})
+}
+
+func BenchmarkLocalKMS_CreateFromPool(b *testing.B) {
+	const poolSize = 10000
+
+	kmsService, err := New("local-lock://custom/master/key/", &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: &noop.NoLock{},
+	}, WithKeyPool(kms.ECDSAP256Type, KeyPoolConfig{Size: poolSize, RefillThreshold: 1}))
+	require.NoError(b, err)
+
+	for kmsService.KeyPoolAvailable(kms.ECDSAP256Type) < poolSize {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	b.Run("on demand", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			_, _, err := kmsService.Create(kms.ECDSAP256Type)
+			require.NoError(b, err)
+		}
+	})
+
+	// the keys are created on demand once the pool is empty
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			_, _, err := kmsService.CreateFromPool(kms.ECDSAP256Type)
+			require.NoError(b, err)
+		}
+	})
+}
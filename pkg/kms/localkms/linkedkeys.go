@@ -0,0 +1,226 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+const (
+	// linkedKeySetPrefix is the prefix of the keys of the linked key sets in the keystore
+	linkedKeySetPrefix = "linkedkeys_"
+	// linkedKeySetDIDPrefix is the prefix of the keys of the index of the linked key sets by DID in the keystore
+	linkedKeySetDIDPrefix = "linkedkeys_did_"
+)
+
+// LinkedKeySet is a signing key and a key agreement key created together for a DID (see CreateLinkedKeySet), its ID
+// is the ID of the signing key.
+type LinkedKeySet struct {
+	ID                  string      `json:"-"`
+	DID                 string      `json:"did,omitempty"`
+	SigningKeyID        string      `json:"signingKeyID"`
+	SigningKeyType      kms.KeyType `json:"signingKeyType"`
+	SigningPubKey       []byte      `json:"-"`
+	KeyAgreementKeyID   string      `json:"keyAgreementKeyID"`
+	KeyAgreementKeyType kms.KeyType `json:"keyAgreementKeyType"`
+	KeyAgreementPubKey  []byte      `json:"-"`
+}
+
+// CreateLinkedKeySet creates a signing key of type signingKT (ED25519 or ECDSA) and a key agreement key of type
+// keyAgreementKT (ECIESHKDFAES128GCM), the pair of keys a DID document needs, and records them as a linked set
+// associated with did, which is optional. The key types can be aliases or key classes (see WithKeyTypeAliases and
+// WithKeyClassDefaults). The keys are created atomically: if the creation of either key or of the linked set
+// fails, none is persisted.
+func (l *LocalKMS) CreateLinkedKeySet(signingKT, keyAgreementKT kms.KeyType, did string) (*LinkedKeySet, error) {
+	set, err := l.createLinkedKeySet(l.resolveKeyType(signingKT), l.resolveKeyType(keyAgreementKT), did)
+	if err != nil {
+		l.audit(&AuditRecord{Operation: AuditOpCreateLinkedKeySet, KeyType: signingKT}, err)
+
+		return nil, fmt.Errorf("create linked key set: %w", err)
+	}
+
+	l.audit(&AuditRecord{Operation: AuditOpCreateLinkedKeySet, KeyID: set.SigningKeyID,
+		NewKeyID: set.KeyAgreementKeyID, KeyType: signingKT}, nil)
+
+	return set, nil
+}
+
+func (l *LocalKMS) createLinkedKeySet(signingKT, keyAgreementKT kms.KeyType, did string) (*LinkedKeySet, error) {
+	done, err := l.beginWrite()
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	if !isSigningKeyType(signingKT) {
+		return nil, fmt.Errorf("key type %s is not a signing key type", signingKT)
+	}
+
+	if keyAgreementKT != kms.ECIESHKDFAES128GCMType {
+		return nil, fmt.Errorf("key type %s is not a key agreement key type", keyAgreementKT)
+	}
+
+	if did != "" {
+		if _, e := l.GetLinkedKeySetByDID(did); e == nil {
+			return nil, fmt.Errorf("a linked key set is already associated with %s", did)
+		}
+	}
+
+	set := &LinkedKeySet{DID: did, SigningKeyType: signingKT, KeyAgreementKeyType: keyAgreementKT}
+
+	err = l.createLinkedKeys(set)
+	if err != nil {
+		// roll back the keys created before the failure, the original error is returned
+		l.deleteLinkedKeys(set)
+
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// createLinkedKeys creates the keys of set, with their public keys, and saves set.
+func (l *LocalKMS) createLinkedKeys(set *LinkedKeySet) error {
+	var err error
+
+	set.SigningKeyID, set.SigningPubKey, err = l.createAsymmetricKey(set.SigningKeyType)
+	if err != nil {
+		return fmt.Errorf("signing key: %w", err)
+	}
+
+	set.KeyAgreementKeyID, set.KeyAgreementPubKey, err = l.createAsymmetricKey(set.KeyAgreementKeyType)
+	if err != nil {
+		return fmt.Errorf("key agreement key: %w", err)
+	}
+
+	set.ID = set.SigningKeyID
+
+	return l.saveLinkedKeySet(set)
+}
+
+// createAsymmetricKey creates a key of type kt and returns its ID and public key bytes. The ID of the stored keyset
+// is returned on failure too, to be deleted.
+func (l *LocalKMS) createAsymmetricKey(kt kms.KeyType) (string, []byte, error) {
+	keyTemplate, err := getKeyTemplate(kt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	kh, err := keyset.NewHandle(keyTemplate)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pubKeyBytes, err := publicKeyBytes(kh)
+	if err != nil {
+		return "", nil, fmt.Errorf("export public key: %w", err)
+	}
+
+	kID, err := l.storeKeySet(kh)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = l.saveMetadata(kID, &keyMetadata{KeyType: kt})
+	if err != nil {
+		return kID, nil, err
+	}
+
+	return kID, pubKeyBytes, nil
+}
+
+func (l *LocalKMS) saveLinkedKeySet(set *LinkedKeySet) error {
+	bytes, err := json.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("marshal linked key set: %w", err)
+	}
+
+	err = l.store.Put(linkedKeySetPrefix+set.ID, bytes)
+	if err != nil {
+		return fmt.Errorf("save linked key set: %w", err)
+	}
+
+	if set.DID != "" {
+		err = l.store.Put(linkedKeySetDIDPrefix+set.DID, []byte(set.ID))
+		if err != nil {
+			return fmt.Errorf("save linked key set DID: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteLinkedKeys deletes, best effort, the keys of set and the linked key set records.
+func (l *LocalKMS) deleteLinkedKeys(set *LinkedKeySet) {
+	for _, keyID := range []string{set.SigningKeyID, set.KeyAgreementKeyID} {
+		if keyID != "" {
+			_ = l.deleteKeySet(keyID) //nolint:errcheck // the keyset may not have been stored
+		}
+	}
+
+	if set.SigningKeyID != "" {
+		_ = l.store.Delete(linkedKeySetPrefix + set.SigningKeyID) //nolint:errcheck // may not have been stored
+	}
+
+	if set.DID != "" {
+		_ = l.store.Delete(linkedKeySetDIDPrefix + set.DID) //nolint:errcheck // may not have been stored
+	}
+}
+
+// GetLinkedKeySet returns the linked key set id (the ID of its signing key) with its public keys. It returns an
+// error wrapping storage.ErrDataNotFound if there is no such linked key set.
+func (l *LocalKMS) GetLinkedKeySet(id string) (*LinkedKeySet, error) {
+	bytes, err := l.store.Get(linkedKeySetPrefix + id)
+	if err != nil {
+		return nil, fmt.Errorf("get linked key set: %w", err)
+	}
+
+	set := &LinkedKeySet{ID: id}
+
+	err = json.Unmarshal(bytes, set)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal linked key set: %w", err)
+	}
+
+	set.SigningPubKey, err = l.exportPubKeyBytes(set.SigningKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("get linked key set: signing key: %w", err)
+	}
+
+	set.KeyAgreementPubKey, err = l.exportPubKeyBytes(set.KeyAgreementKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("get linked key set: key agreement key: %w", err)
+	}
+
+	return set, nil
+}
+
+// GetLinkedKeySetByDID returns the linked key set associated with did. It returns an error wrapping
+// storage.ErrDataNotFound if no linked key set is associated with did.
+func (l *LocalKMS) GetLinkedKeySetByDID(did string) (*LinkedKeySet, error) {
+	id, err := l.store.Get(linkedKeySetDIDPrefix + did)
+	if err != nil {
+		return nil, fmt.Errorf("get linked key set by DID: %w", err)
+	}
+
+	return l.GetLinkedKeySet(string(id))
+}
+
+func isSigningKeyType(kt kms.KeyType) bool {
+	switch kt {
+	case kms.ED25519Type, kms.ECDSAP256Type, kms.ECDSAP384Type, kms.ECDSAP521Type:
+		return true
+	default:
+		return false
+	}
+}
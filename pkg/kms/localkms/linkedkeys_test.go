@@ -0,0 +1,142 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestLocalKMS_CreateLinkedKeySet(t *testing.T) {
+	secretLock := createMasterKeyAndSecretLock(t)
+
+	newKMS := func(t *testing.T, store storage.Store) *LocalKMS {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		})
+		require.NoError(t, err)
+
+		return k
+	}
+
+	t.Run("test create linked key set for a DID", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		set, err := kmsService.CreateLinkedKeySet(kms.ED25519Type, kms.ECIESHKDFAES128GCMType, "did:example:123")
+		require.NoError(t, err)
+		require.Equal(t, set.SigningKeyID, set.ID)
+		require.NotEqual(t, set.SigningKeyID, set.KeyAgreementKeyID)
+		require.NotEmpty(t, set.KeyAgreementPubKey)
+
+		pubKey, err := kmsService.ExportPubKeyBytes(set.SigningKeyID)
+		require.NoError(t, err)
+		require.Equal(t, pubKey, set.SigningPubKey)
+
+		pubKey, err = kmsService.ExportPubKeyBytes(set.KeyAgreementKeyID)
+		require.NoError(t, err)
+		require.Equal(t, pubKey, set.KeyAgreementPubKey)
+
+		found, err := kmsService.GetLinkedKeySet(set.ID)
+		require.NoError(t, err)
+		require.Equal(t, set, found)
+
+		found, err = kmsService.GetLinkedKeySetByDID("did:example:123")
+		require.NoError(t, err)
+		require.Equal(t, set, found)
+
+		_, err = kmsService.CreateLinkedKeySet(kms.ED25519Type, kms.ECIESHKDFAES128GCMType, "did:example:123")
+		require.EqualError(t, err, "create linked key set: a linked key set is already associated with "+
+			"did:example:123")
+	})
+
+	t.Run("test create linked key set without DID", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		set, err := kmsService.CreateLinkedKeySet(kms.ECDefaultType, kms.ECIESHKDFAES128GCMType, "")
+		require.NoError(t, err)
+		require.Equal(t, kms.ECDSAP256Type, set.SigningKeyType)
+		require.Empty(t, set.DID)
+
+		found, err := kmsService.GetLinkedKeySet(set.ID)
+		require.NoError(t, err)
+		require.Equal(t, set, found)
+	})
+
+	t.Run("test invalid key types", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		_, err := kmsService.CreateLinkedKeySet(kms.AES256GCMType, kms.ECIESHKDFAES128GCMType, "")
+		require.EqualError(t, err, "create linked key set: key type AES256GCM is not a signing key type")
+
+		_, err = kmsService.CreateLinkedKeySet(kms.ED25519Type, kms.ED25519Type, "")
+		require.EqualError(t, err, "create linked key set: key type ED25519 is not a key agreement key type")
+	})
+
+	t.Run("test no key is persisted if the second key fails to be stored", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		keySets := 0
+
+		kmsService := newKMS(t, &failingPutStore{Store: store, failPut: func(k string) bool {
+			if !strings.HasPrefix(k, testMasterKeyURI) {
+				return false
+			}
+
+			keySets++
+
+			return keySets == 2
+		}})
+
+		_, err := kmsService.CreateLinkedKeySet(kms.ED25519Type, kms.ECIESHKDFAES128GCMType, "did:example:123")
+		require.EqualError(t, err, "create linked key set: key agreement key: put error")
+		require.Equal(t, 2, keySets)
+		require.Empty(t, store.Store)
+
+		_, err = kmsService.GetLinkedKeySetByDID("did:example:123")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test no key is persisted if the linked key set fails to be stored", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+
+		kmsService := newKMS(t, &failingPutStore{Store: store, failPut: func(k string) bool {
+			return strings.HasPrefix(k, linkedKeySetDIDPrefix)
+		}})
+
+		_, err := kmsService.CreateLinkedKeySet(kms.ED25519Type, kms.ECIESHKDFAES128GCMType, "did:example:123")
+		require.EqualError(t, err, "create linked key set: save linked key set DID: put error")
+		require.Empty(t, store.Store)
+	})
+
+	t.Run("test get unknown linked key set", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}})
+
+		_, err := kmsService.GetLinkedKeySet("unknown")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+}
+
+// failingPutStore fails the Put of the keys matching failPut.
+type failingPutStore struct {
+	storage.Store
+	failPut func(k string) bool
+}
+
+func (s *failingPutStore) Put(k string, v []byte) error {
+	if s.failPut(k) {
+		return errors.New("put error")
+	}
+
+	return s.Store.Put(k, v)
+}
@@ -8,16 +8,23 @@ package localkms
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"time"
 
 	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/hybrid"
 	"github.com/google/tink/go/keyset"
 	"github.com/google/tink/go/mac"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/signature"
+	"github.com/google/tink/go/streamingaead"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/idgen"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
-	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/primitivepool"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -31,14 +38,50 @@ const (
 // It uses an underlying secret lock service (default local secretLock) to wrap (encrypt) keys
 // prior to storing them.
 type LocalKMS struct {
-	secretLock       secretlock.Service
-	masterKeyURI     string
-	store            storage.Store
-	masterKeyEnvAEAD *aead.KMSEnvelopeAEAD
+	secretLock        secretlock.Service
+	masterKeyURI      string
+	store             storage.Store
+	masterKeyEnvAEAD  *aead.KMSEnvelopeAEAD
+	keyWrap           KeyWrap
+	previousKeyWraps  []KeyWrap
+	keyWrapAEADs      map[KeyWrap]*aead.KMSEnvelopeAEAD
+	envelopeTemplate  *tinkpb.KeyTemplate
+	auditLogger       AuditLogger
+	masterKeyCacheTTL time.Duration
+	keyTypeAliases    map[kms.KeyType]kms.KeyType
+	keyClassDefaults  map[kms.KeyType]kms.KeyType
+	ctx               context.Context
+	primitives        *primitivepool.Pool
+	keyWrappers       map[string]KeyWrapperFactory
+	keyPoolConfigs    map[kms.KeyType]KeyPoolConfig
+	keyPools          map[kms.KeyType]*keyPool
+	writes            *writeGate
+	idGenerator       idgen.IDGenerator
+	keyURIResolver    MasterKeyURIResolver
+	tenants           *tenantKeyWraps
+	tenantErr         error
 }
 
+// idGeneratorProvider is implemented by the providers configuring the generator of the keyset IDs, the IDs are
+// random otherwise. The generated IDs are appended to the master key URI, they must be unique and must not contain
+// characters sorting after '~'.
+type idGeneratorProvider interface {
+	IDGenerator() idgen.IDGenerator
+}
+
+func providedIDGenerator(p kms.Provider) idgen.IDGenerator {
+	if gp, ok := p.(idGeneratorProvider); ok {
+		return gp.IDGenerator()
+	}
+
+	return nil
+}
+
+// Option configures the LocalKMS.
+type Option func(opts *LocalKMS)
+
 // New will create a new (local) KMS service
-func New(masterKeyURI string, p kms.Provider) (*LocalKMS, error) {
+func New(masterKeyURI string, p kms.Provider, opts ...Option) (*LocalKMS, error) {
 	store, err := p.StorageProvider().OpenStore(Namespace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to ceate local kms: %w", err)
@@ -46,33 +89,139 @@ func New(masterKeyURI string, p kms.Provider) (*LocalKMS, error) {
 
 	secretLock := p.SecretLock()
 
-	kw, err := keywrapper.New(secretLock, masterKeyURI)
+	l := &LocalKMS{
+		store:        store,
+		secretLock:   secretLock,
+		masterKeyURI: masterKeyURI,
+		ctx:          context.Background(),
+		primitives:   primitivepool.New(),
+		keyWrappers:  defaultKeyWrappers(),
+		writes:       &writeGate{},
+		idGenerator:  providedIDGenerator(p),
+		keyClassDefaults: map[kms.KeyType]kms.KeyType{
+			kms.ECDefaultType:   kms.ECDSAP256Type,
+			kms.AEADDefaultType: kms.AES256GCMType,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	// validate the master key URI, the key wraps are created once the key wrapper schemes are registered
+	_, err = l.newKeyWrapper(masterKeyURI)
 	if err != nil {
 		return nil, err
 	}
 
-	// create a KMSEnvelopeAEAD instance to wrap/unwrap keys managed by LocalKMS
-	masterKeyEnvAEAD := aead.NewKMSEnvelopeAEAD(*aead.AES256GCMKeyTemplate(), kw)
+	err = l.checkKeyTypeAliases()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local kms: %w", err)
+	}
+
+	err = l.checkKeyClassDefaults()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local kms: %w", err)
+	}
+
+	// create the KMSEnvelopeAEAD instances to wrap/unwrap keys managed by LocalKMS
+	err = l.createKeyWrapAEADs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local kms: %w", err)
+	}
+
+	l.masterKeyEnvAEAD = l.keyWrapAEADs[l.keyWrap]
+
+	err = l.startKeyPools()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local kms: %w", err)
+	}
 
-	return &LocalKMS{
-			store:            store,
-			secretLock:       secretLock,
-			masterKeyURI:     masterKeyURI,
-			masterKeyEnvAEAD: masterKeyEnvAEAD},
-		nil
+	return l, nil
 }
 
-// Create a new key/keyset for key type kt, store it and return its stored ID and key handle
+// WithMasterKeyCache option is for caching in memory, for ttl, the keys unwrapped by the secret lock when reading
+// keysets. This avoids calling the secret lock on every keyset read, which is expensive for remote secret locks.
+// Cached keys are zeroized on expiry. Caching is disabled by default.
+func WithMasterKeyCache(ttl time.Duration) Option {
+	return func(opts *LocalKMS) {
+		opts.masterKeyCacheTTL = ttl
+	}
+}
+
+// Create a new key/keyset for key type kt, store it and return its stored ID and key handle.
+// kt can be an alias of a key type (see WithKeyTypeAliases) or an abstract key class (see WithKeyClassDefaults).
 func (l *LocalKMS) Create(kt kms.KeyType) (string, interface{}, error) {
+	return l.CreateWithOptions(kt)
+}
+
+// CreateWithOptions creates a new key/keyset for key type kt like Create, with the key options opts.
+// The key can be tagged with a unique external reference (see kms.WithExternalRef and GetByExternalRef).
+// ECDSA keys can hash messages with another hash function than the default of their curve (see
+// kms.WithSignatureHash), rotating them creates a key with the same hash function.
+func (l *LocalKMS) CreateWithOptions(kt kms.KeyType, opts ...kms.KeyOption) (string, interface{}, error) {
+	keyOpts := &kms.KeyOpts{}
+
+	for _, opt := range opts {
+		opt(keyOpts)
+	}
+
+	kID, kh, err := l.create(kt, keyOpts)
+	l.audit(&AuditRecord{Operation: AuditOpCreate, KeyID: kID, KeyType: kt}, err)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return kID, kh, nil
+}
+
+func (l *LocalKMS) create(kt kms.KeyType, keyOpts *kms.KeyOpts) (string, *keyset.Handle, error) {
 	if kt == "" {
 		return "", nil, fmt.Errorf("failed to create new key, missing key type")
 	}
 
-	keyTemplate, err := getKeyTemplate(kt)
+	kt = l.resolveKeyType(kt)
+
+	var (
+		keyTemplate *tinkpb.KeyTemplate
+		err         error
+	)
+
+	if keyOpts.SignatureHash() != 0 {
+		keyTemplate, err = ecdsaKeyTemplate(kt, keyOpts.SignatureHash())
+	} else {
+		keyTemplate, err = getKeyTemplate(kt)
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return l.createFromTemplate(keyTemplate, &keyMetadata{
+		KeyType:         kt,
+		ExternalRef:     keyOpts.ExternalRef(),
+		SignatureDomain: keyOpts.SignatureDomain(),
+	})
+}
+
+// createFromTemplate creates a new keyset from keyTemplate, stores it with metadata and returns its stored ID.
+func (l *LocalKMS) createFromTemplate(keyTemplate *tinkpb.KeyTemplate, metadata *keyMetadata) (string,
+	*keyset.Handle, error) {
+	done, err := l.beginWrite()
 	if err != nil {
 		return "", nil, err
 	}
 
+	defer done()
+
+	if metadata.ExternalRef != "" {
+		err = l.checkExternalRef(metadata.ExternalRef)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
 	kh, err := keyset.NewHandle(keyTemplate)
 	if err != nil {
 		return "", nil, err
@@ -83,40 +232,86 @@ func (l *LocalKMS) Create(kt kms.KeyType) (string, interface{}, error) {
 		return "", nil, err
 	}
 
+	err = l.saveMetadata(kID, metadata)
+	if err != nil {
+		return "", nil, err
+	}
+
 	return kID, kh, nil
 }
 
 // Get key handle for the given keyID
 func (l *LocalKMS) Get(keyID string) (interface{}, error) {
-	return l.getKeySet(keyID)
+	kh, err := l.getKeySet(keyID)
+	l.audit(&AuditRecord{Operation: AuditOpGet, KeyID: keyID}, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return kh, nil
 }
 
-// Rotate a key referenced by keyID and return its updated handle
+// Rotate a key referenced by keyID and return its updated handle.
+// kt can be an alias of a key type (see WithKeyTypeAliases).
+// It returns a *ConflictError if keyID was rotated concurrently, eg: by another node sharing the keystore.
+// keyID is recorded in the rotation history of the rotated key (see RotationHistory). The keyset keyID is removed,
+// a retained key (see RotateWithOptions) can't be rotated again. Pooled keys not handed out yet (see WithKeyPool)
+// can't be rotated.
 func (l *LocalKMS) Rotate(kt kms.KeyType, keyID string) (string, interface{}, error) {
-	kh, err := l.getKeySet(keyID)
+	return l.RotateWithOptions(kt, keyID)
+}
+
+// RotateWithOptions rotates the key keyID like Rotate, with the key options opts: the keyset keyID is kept if
+// kms.WithRetainPrevious is set.
+func (l *LocalKMS) RotateWithOptions(kt kms.KeyType, keyID string, opts ...kms.KeyOption) (string, interface{}, error) {
+	keyOpts := &kms.KeyOpts{}
+
+	for _, opt := range opts {
+		opt(keyOpts)
+	}
+
+	newID, kh, err := l.rotate(kt, keyID, keyOpts.RetainPrevious())
+	l.audit(&AuditRecord{Operation: AuditOpRotate, KeyID: keyID, NewKeyID: newID, KeyType: kt}, err)
+
 	if err != nil {
 		return "", nil, err
 	}
 
-	keyTemplate, err := getKeyTemplate(kt)
+	return newID, kh, nil
+}
+
+func (l *LocalKMS) rotate(kt kms.KeyType, keyID string, retain bool) (string, *keyset.Handle, error) {
+	done, err := l.beginWrite()
 	if err != nil {
 		return "", nil, err
 	}
 
-	km := keyset.NewManagerFromHandle(kh)
+	defer done()
 
-	err = km.Rotate(keyTemplate)
+	pooled, err := l.allPooledKeyIDs()
 	if err != nil {
 		return "", nil, err
 	}
 
-	updatedKH, err := km.Handle()
+	if pooled[keyID] {
+		return "", nil, fmt.Errorf("rotate: key %s is pooled and not handed out yet", keyID)
+	}
 
+	version, err := l.keySetVersion(keyID)
 	if err != nil {
 		return "", nil, err
 	}
 
-	err = l.store.Delete(keyID)
+	kt = l.resolveKeyType(kt)
+
+	updatedKH, err := l.rotateKeySet(kt, keyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the external reference, if any, and the rotation history are moved to the rotated keyset
+	metadata, err := l.rotatedMetadata(keyID, kt)
 	if err != nil {
 		return "", nil, err
 	}
@@ -126,9 +321,49 @@ func (l *LocalKMS) Rotate(kt kms.KeyType, keyID string) (string, interface{}, er
 		return "", nil, err
 	}
 
+	err = l.claimRotation(keyID, newID, version, retain)
+	if err != nil {
+		return "", nil, err
+	}
+
+	l.primitives.Invalidate(keyID)
+
+	if !retain {
+		err = l.deleteKeySet(keyID)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	err = l.saveMetadata(newID, metadata)
+	if err != nil {
+		return "", nil, err
+	}
+
 	return newID, updatedKH, nil
 }
 
+func (l *LocalKMS) rotateKeySet(kt kms.KeyType, keyID string) (*keyset.Handle, error) {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	keyTemplate, err := rotationKeyTemplate(kt, kh)
+	if err != nil {
+		return nil, err
+	}
+
+	km := keyset.NewManagerFromHandle(kh)
+
+	err = km.Rotate(keyTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return km.Handle()
+}
+
 // nolint:gocyclo
 func getKeyTemplate(keyType kms.KeyType) (*tinkpb.KeyTemplate, error) {
 	switch keyType {
@@ -153,24 +388,39 @@ func getKeyTemplate(keyType kms.KeyType) (*tinkpb.KeyTemplate, error) {
 		return signature.ED25519KeyWithoutPrefixTemplate(), nil
 	case kms.HMACSHA256Tag256Type:
 		return mac.HMACSHA256Tag256KeyTemplate(), nil
+	case kms.ECIESHKDFAES128GCMType:
+		return eciesKeyWithoutPrefixTemplate(), nil
+	case kms.AES128GCMHKDF4KBType:
+		return streamingaead.AES128GCMHKDF4KBKeyTemplate(), nil
+	case kms.AES256GCMHKDF4KBType:
+		return streamingaead.AES256GCMHKDF4KBKeyTemplate(), nil
+	case kms.AES256GCMHKDF1MBType:
+		return streamingaead.AES256GCMHKDF1MBKeyTemplate(), nil
 	default:
 		return nil, fmt.Errorf("key type unrecognized")
 	}
 }
 
+// eciesKeyWithoutPrefixTemplate is the ECIES template with RAW output prefix so the ciphertexts can be decrypted
+// with keys imported from raw public key bytes.
+func eciesKeyWithoutPrefixTemplate() *tinkpb.KeyTemplate {
+	template := hybrid.ECIESHKDFAES128GCMKeyTemplate()
+	template.OutputPrefixType = tinkpb.OutputPrefixType_RAW
+
+	return template
+}
+
 func (l *LocalKMS) storeKeySet(kh *keyset.Handle) (string, error) {
 	w := newWriter(l.store, l.masterKeyURI)
+	w.idGenerator = l.idGenerator
 
-	buf := new(bytes.Buffer)
-	jsonKeysetWriter := keyset.NewJSONWriter(buf)
-
-	err := kh.Write(jsonKeysetWriter, l.masterKeyEnvAEAD)
+	data, err := l.wrapKeySet(kh)
 	if err != nil {
 		return "", err
 	}
 
 	// write buffer to localstorage
-	_, err = w.Write(buf.Bytes())
+	_, err = w.Write(data)
 	if err != nil {
 		return "", err
 	}
@@ -178,13 +428,41 @@ func (l *LocalKMS) storeKeySet(kh *keyset.Handle) (string, error) {
 	return w.KeysetID, nil
 }
 
+// wrapKeySet returns the stored form of kh, wrapped with the key wrap of the KMS.
+func (l *LocalKMS) wrapKeySet(kh *keyset.Handle) ([]byte, error) {
+	if err := l.checkTenantKeyWrap(&l.keyWrap); err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	jsonKeysetWriter := keyset.NewJSONWriter(buf)
+
+	err := kh.Write(jsonKeysetWriter, l.masterKeyEnvAEAD)
+	if err != nil {
+		return nil, err
+	}
+
+	// the key wrap is stored with the keyset to unwrap it with the same key wrap when it is read
+	return json.Marshal(&wrappedKeySet{Wrap: &l.keyWrap, KeySet: buf.Bytes()})
+}
+
 func (l *LocalKMS) getKeySet(id string) (*keyset.Handle, error) {
-	localDBReader := newReader(l.store, id)
-	jsonKeysetReader := keyset.NewJSONReader(localDBReader)
+	if err := l.checkTenantKeySet(id); err != nil {
+		return nil, fmt.Errorf("keyset %s: %w", id, err)
+	}
 
-	// Read reads the encrypted keyset handle back from the io.reader implementation
-	// and decrypts it using masterKeyEnvAEAD.
-	kh, err := keyset.Read(jsonKeysetReader, l.masterKeyEnvAEAD)
+	data, err := ioutil.ReadAll(newReader(l.store, id))
+	if err != nil {
+		return nil, err
+	}
+
+	envAEAD, wrappedKeySet, err := l.unwrapHeader(data)
+	if err != nil {
+		return nil, fmt.Errorf("keyset %s: %w", id, err)
+	}
+
+	// Read reads the encrypted keyset handle back and decrypts it using the AEAD of its key wrap.
+	kh, err := keyset.Read(keyset.NewJSONReader(bytes.NewReader(wrappedKeySet)), envAEAD)
 	if err != nil {
 		return nil, err
 	}
@@ -197,11 +475,23 @@ func (l *LocalKMS) getKeySet(id string) (*keyset.Handle, error) {
 // The key must be an asymmetric key
 // it returns an error if it fails to export the public key bytes
 func (l *LocalKMS) ExportPubKeyBytes(id string) ([]byte, error) {
+	pubKeyBytes, err := l.exportPubKeyBytes(id)
+	l.audit(&AuditRecord{Operation: AuditOpExportPubKeyBytes, KeyID: id}, err)
+
+	return pubKeyBytes, err
+}
+
+func (l *LocalKMS) exportPubKeyBytes(id string) ([]byte, error) {
 	kh, err := l.getKeySet(id)
 	if err != nil {
 		return nil, err
 	}
 
+	return publicKeyBytes(kh)
+}
+
+// publicKeyBytes returns the raw public key bytes of kh.
+func publicKeyBytes(kh *keyset.Handle) ([]byte, error) {
 	// kh must be a private asymmetric key in order to extract its public key
 	pubKH, err := kh.Public()
 	if err != nil {
@@ -224,5 +514,8 @@ func (l *LocalKMS) ExportPubKeyBytes(id string) ([]byte, error) {
 // Note: The key handle created is not stored in the KMS, it's only useful to execute the crypto primitive
 // associated with it.
 func (l *LocalKMS) PubKeyBytesToHandle(pubKey []byte, kt kms.KeyType) (*keyset.Handle, error) {
-	return publicKeyBytesToHandle(pubKey, kt)
+	kh, err := publicKeyBytesToHandle(pubKey, kt)
+	l.audit(&AuditRecord{Operation: AuditOpPubKeyBytesToHandle, KeyType: kt}, err)
+
+	return kh, err
 }
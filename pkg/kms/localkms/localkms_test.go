@@ -14,6 +14,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/tink/go/keyset"
 	"github.com/google/tink/go/subtle/random"
@@ -340,3 +341,47 @@ func (m *mockProvider) StorageProvider() storage.Provider {
 func (m *mockProvider) SecretLock() secretlock.Service {
 	return m.secretLock
 }
+
+// countingSecretLock counts the calls to the secret lock it wraps.
+type countingSecretLock struct {
+	secretlock.Service
+	decryptCount int
+}
+
+func (s *countingSecretLock) Decrypt(keyURI string, req *secretlock.DecryptRequest) (*secretlock.DecryptResponse, error) {
+	s.decryptCount++
+
+	return s.Service.Decrypt(keyURI, req)
+}
+
+func TestLocalKMS_WithMasterKeyCache(t *testing.T) {
+	t.Run("test secret lock is invoked once within the TTL", func(t *testing.T) {
+		sl := &countingSecretLock{Service: createMasterKeyAndSecretLock(t)}
+		storeProvider := mockstorage.NewMockStoreProvider()
+
+		kmsService, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: sl})
+		require.NoError(t, err)
+
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		cachedKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: sl},
+			WithMasterKeyCache(time.Hour))
+		require.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			_, err = cachedKMS.Get(keyID)
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, 1, sl.decryptCount)
+
+		// without cache, the secret lock is invoked on every read
+		for i := 0; i < 5; i++ {
+			_, err = kmsService.Get(keyID)
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, 6, sl.decryptCount)
+	})
+}
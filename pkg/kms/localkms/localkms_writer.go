@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/tink/go/subtle/random"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/idgen"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
@@ -33,6 +34,8 @@ func newWriter(kmsStore storage.Store, masterKeyURI string) *storeWriter {
 type storeWriter struct {
 	storage      storage.Store
 	masterKeyURI string
+	// idGenerator generates the keyset IDs, they are random if nil
+	idGenerator idgen.IDGenerator
 	// KeysetID is set when Write() is called
 	KeysetID string
 }
@@ -48,9 +51,17 @@ func (l *storeWriter) Write(p []byte) (int, error) {
 	baseID := l.masterKeyURI
 	ksID := ""
 
+	newID := func() string {
+		return base64.URLEncoding.EncodeToString(random.GetRandomBytes(keySetIDLength))
+	}
+
+	if l.idGenerator != nil {
+		newID = l.idGenerator.NewID
+	}
+
 	for {
-		// generate random ID prefixed with masterKeyURI
-		ksID = baseID + base64.URLEncoding.EncodeToString(random.GetRandomBytes(keySetIDLength))
+		// generate ID prefixed with masterKeyURI
+		ksID = baseID + newID()
 
 		// ensure ksID is not already used
 		_, e := l.storage.Get(ksID)
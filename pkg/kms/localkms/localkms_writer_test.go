@@ -12,6 +12,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/idgen"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 )
 
@@ -77,4 +79,50 @@ func TestLocalKMSWriter(t *testing.T) {
 		require.EqualError(t, err, getError.Error())
 		require.Equal(t, 0, n)
 	})
+	t.Run("success case - keyset IDs from an ID generator, skipping the IDs in use", func(t *testing.T) {
+		storeMap := map[string][]byte{masterKeyURI + "/key-1": []byte("existing")}
+		mockStore := &mockstorage.MockStore{Store: storeMap}
+
+		l := newWriter(mockStore, masterKeyURI)
+		l.idGenerator = idgen.NewSequential("key-")
+
+		_, err := l.Write([]byte("someKeyData"))
+		require.NoError(t, err)
+		require.Equal(t, masterKeyURI+"/key-2", l.KeysetID)
+		require.Equal(t, []byte("existing"), storeMap[masterKeyURI+"/key-1"])
+	})
+}
+
+// idGenMockProvider is a mockProvider configuring the generator of the keyset IDs.
+type idGenMockProvider struct {
+	mockProvider
+	idGenerator idgen.IDGenerator
+}
+
+func (m *idGenMockProvider) IDGenerator() idgen.IDGenerator {
+	return m.idGenerator
+}
+
+func TestLocalKMS_IDGenerator(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &idGenMockProvider{
+		mockProvider: mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: createMasterKeyAndSecretLock(t),
+		},
+		idGenerator: idgen.NewSequential("key-"),
+	})
+	require.NoError(t, err)
+
+	for _, expected := range []string{"key-1", "key-2"} {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+		require.Equal(t, testMasterKeyURI+"/"+expected, keyID)
+
+		_, err = kmsService.Get(keyID)
+		require.NoError(t, err)
+	}
+
+	newID, _, err := kmsService.Rotate(kms.ED25519Type, testMasterKeyURI+"/key-1")
+	require.NoError(t, err)
+	require.Equal(t, testMasterKeyURI+"/key-3", newID)
 }
@@ -0,0 +1,68 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/tink/go/tink"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+)
+
+// LocalKeyURIScheme is the scheme of the master key URIs of keys protected by the secret lock of the KMS
+// (eg: local-lock://custom/master/key/), it is registered by default.
+const LocalKeyURIScheme = "local-lock"
+
+// ErrUnsupportedMasterKeyURI is returned when no key wrapper is registered for the scheme of a master key URI.
+var ErrUnsupportedMasterKeyURI = errors.New("unsupported master key URI")
+
+// KeyWrapperFactory creates the key wrapper of the master key keyURI: the AEAD wrapping (encrypting) the keys stored
+// by the KMS. secretLock is the secret lock of the KMS provider, remote key wrappers (eg: AWS or GCP KMS) may ignore
+// it.
+type KeyWrapperFactory func(secretLock secretlock.Service, keyURI string) (tink.AEAD, error)
+
+// WithKeyWrapperScheme option registers factory to create the key wrappers of the master key URIs with scheme (eg:
+// "aws-kms" for the URI aws-kms://arn:aws:kms:...). Master key URIs are matched case-insensitively against the
+// registered schemes, the local-lock scheme can be overridden.
+func WithKeyWrapperScheme(scheme string, factory KeyWrapperFactory) Option {
+	return func(opts *LocalKMS) {
+		opts.keyWrappers[strings.ToLower(scheme)] = factory
+	}
+}
+
+// newKeyWrapper creates the key wrapper of the master key keyURI with the factory registered for its scheme.
+func (l *LocalKMS) newKeyWrapper(keyURI string) (tink.AEAD, error) {
+	scheme := masterKeyURIScheme(keyURI)
+
+	factory, ok := l.keyWrappers[scheme]
+	if !ok {
+		return nil, fmt.Errorf("%w: no key wrapper registered for scheme '%s' of '%s'", ErrUnsupportedMasterKeyURI,
+			scheme, keyURI)
+	}
+
+	return factory(l.secretLock, keyURI)
+}
+
+func defaultKeyWrappers() map[string]KeyWrapperFactory {
+	return map[string]KeyWrapperFactory{
+		LocalKeyURIScheme: keywrapper.New,
+	}
+}
+
+// masterKeyURIScheme returns the lower case scheme of keyURI, or an empty string if keyURI has no scheme.
+func masterKeyURIScheme(keyURI string) string {
+	i := strings.Index(keyURI, "://")
+	if i < 0 {
+		return ""
+	}
+
+	return strings.ToLower(keyURI[:i])
+}
@@ -0,0 +1,97 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/tink"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+)
+
+func TestLocalKMS_WithKeyWrapperScheme(t *testing.T) {
+	const fakeKeyURI = "fake-kms://projects/test/keys/master"
+
+	// the fake remote key wrapper is a tink AEAD, ignoring the secret lock
+	kh, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	fakeAEAD, err := aead.New(kh)
+	require.NoError(t, err)
+
+	var keyURIs []string
+
+	fakeFactory := func(_ secretlock.Service, keyURI string) (tink.AEAD, error) {
+		keyURIs = append(keyURIs, keyURI)
+
+		return fakeAEAD, nil
+	}
+
+	t.Run("test create and read keys wrapped by a registered scheme", func(t *testing.T) {
+		keyURIs = nil
+		storeProvider := mockstorage.NewMockStoreProvider()
+
+		l, err := New(fakeKeyURI, &mockProvider{storage: storeProvider},
+			WithKeyWrapperScheme("fake-kms", fakeFactory))
+		require.NoError(t, err)
+		require.NotEmpty(t, keyURIs)
+
+		for _, uri := range keyURIs {
+			require.Equal(t, fakeKeyURI, uri)
+		}
+
+		kID, _, err := l.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		// another KMS instance using the same fake key wrapper reads the key
+		l, err = New(fakeKeyURI, &mockProvider{storage: storeProvider},
+			WithKeyWrapperScheme("FAKE-KMS", fakeFactory))
+		require.NoError(t, err)
+
+		_, err = l.Get(kID)
+		require.NoError(t, err)
+	})
+
+	t.Run("test unregistered master key URI scheme", func(t *testing.T) {
+		_, err := New(fakeKeyURI, &mockProvider{storage: mockstorage.NewMockStoreProvider()})
+		require.True(t, errors.Is(err, ErrUnsupportedMasterKeyURI))
+		require.Contains(t, err.Error(), "scheme 'fake-kms'")
+
+		_, err = New("no-scheme", &mockProvider{storage: mockstorage.NewMockStoreProvider()},
+			WithKeyWrapperScheme("fake-kms", fakeFactory))
+		require.True(t, errors.Is(err, ErrUnsupportedMasterKeyURI))
+	})
+
+	t.Run("test key wrapper factory error", func(t *testing.T) {
+		_, err := New(fakeKeyURI, &mockProvider{storage: mockstorage.NewMockStoreProvider()},
+			WithKeyWrapperScheme("fake-kms", func(secretlock.Service, string) (tink.AEAD, error) {
+				return nil, errors.New("factory error")
+			}))
+		require.EqualError(t, err, "factory error")
+	})
+
+	t.Run("test local-lock scheme is registered by default", func(t *testing.T) {
+		_, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: createMasterKeyAndSecretLock(t),
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestMasterKeyURIScheme(t *testing.T) {
+	require.Equal(t, LocalKeyURIScheme, masterKeyURIScheme(testMasterKeyURI))
+	require.Equal(t, "aws-kms", masterKeyURIScheme("AWS-KMS://arn:aws:kms:us-east-1:1234:key/abc"))
+	require.Empty(t, masterKeyURIScheme("test/key/uri"))
+}
@@ -0,0 +1,198 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	aesgcmpb "github.com/google/tink/go/proto/aes_gcm_go_proto"
+	gcmhkdfpb "github.com/google/tink/go/proto/aes_gcm_hkdf_streaming_go_proto"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// metadataKeyPrefix is the prefix of the keys of the keysets metadata in the keystore
+	metadataKeyPrefix = "metadata_"
+
+	aesGCMTypeURL            = "type.googleapis.com/google.crypto.tink.AesGcmKey"
+	chaCha20Poly1305TypeURL  = "type.googleapis.com/google.crypto.tink.ChaCha20Poly1305Key"
+	xChaCha20Poly1305TypeURL = "type.googleapis.com/google.crypto.tink.XChaCha20Poly1305Key"
+	ecdsaSignerTypeURL       = "type.googleapis.com/google.crypto.tink.EcdsaPrivateKey"
+	ed25519SignerTypeURL     = "type.googleapis.com/google.crypto.tink.Ed25519PrivateKey"
+	hmacTypeURL              = "type.googleapis.com/google.crypto.tink.HmacKey"
+	eciesPrivateKeyTypeURL   = "type.googleapis.com/google.crypto.tink.EciesAeadHkdfPrivateKey"
+	aesGCMHKDFTypeURL        = "type.googleapis.com/google.crypto.tink.AesGcmHkdfStreamingKey"
+
+	aes128KeySize = 16
+	mbSegmentSize = 1 << 20
+)
+
+// keyMetadata is the metadata of a stored keyset. It does not include key material.
+type keyMetadata struct {
+	KeyType     kms.KeyType      `json:"keyType"`
+	Created     time.Time        `json:"created"`
+	ExternalRef string           `json:"externalRef,omitempty"`
+	Rotations   []RotationRecord `json:"rotations,omitempty"`
+	// SignatureDomain is the signature domain the key is bound to, if any (see WithDomain).
+	SignatureDomain string `json:"signatureDomain,omitempty"`
+}
+
+// saveMetadata saves the metadata of the keyset keyID, created now unless its creation time is set, and indexes its
+// external reference, if any.
+func (l *LocalKMS) saveMetadata(keyID string, metadata *keyMetadata) error {
+	if metadata.Created.IsZero() {
+		metadata.Created = time.Now().UTC()
+	}
+
+	bytes, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("marshal key metadata: %w", err)
+	}
+
+	err = l.store.Put(metadataKeyPrefix+keyID, bytes)
+	if err != nil {
+		return fmt.Errorf("save key metadata: %w", err)
+	}
+
+	if metadata.ExternalRef != "" {
+		return l.saveExternalRef(metadata.ExternalRef, keyID)
+	}
+
+	return nil
+}
+
+// getMetadata returns the metadata of the keyset keyID or nil if the keyset has no metadata (eg: keysets created
+// before metadata were recorded).
+func (l *LocalKMS) getMetadata(keyID string) (*keyMetadata, error) {
+	bytes, err := l.store.Get(metadataKeyPrefix + keyID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get key metadata: %w", err)
+	}
+
+	metadata := &keyMetadata{}
+
+	err = json.Unmarshal(bytes, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal key metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+func (l *LocalKMS) deleteMetadata(keyID string) error {
+	err := l.store.Delete(metadataKeyPrefix + keyID)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("delete key metadata: %w", err)
+	}
+
+	return nil
+}
+
+// keyTypeOf returns the key type of the primary key of kh. It returns an empty key type if the key is not one of the
+// key types supported by LocalKMS.
+func keyTypeOf(kh *keyset.Handle) kms.KeyType {
+	ks := insecurecleartextkeyset.KeysetMaterial(kh)
+
+	for _, key := range ks.Key {
+		if key.KeyId == ks.PrimaryKeyId {
+			return keyTypeOfKey(key)
+		}
+	}
+
+	return ""
+}
+
+func keyTypeOfKey(key *tinkpb.Keyset_Key) kms.KeyType { // nolint:gocyclo // one case per key type
+	switch key.KeyData.TypeUrl {
+	case aesGCMTypeURL:
+		return aesGCMKeyType(key)
+	case chaCha20Poly1305TypeURL:
+		return kms.ChaCha20Poly1305Type
+	case xChaCha20Poly1305TypeURL:
+		return kms.XChaCha20Poly1305Type
+	case ecdsaSignerTypeURL:
+		return ecdsaKeyType(key)
+	case ed25519SignerTypeURL:
+		return kms.ED25519Type
+	case hmacTypeURL:
+		return kms.HMACSHA256Tag256Type
+	case eciesPrivateKeyTypeURL:
+		return kms.ECIESHKDFAES128GCMType
+	case aesGCMHKDFTypeURL:
+		return aesGCMHKDFKeyType(key)
+	default:
+		return ""
+	}
+}
+
+func aesGCMKeyType(key *tinkpb.Keyset_Key) kms.KeyType {
+	aesKey := new(aesgcmpb.AesGcmKey)
+
+	if err := proto.Unmarshal(key.KeyData.Value, aesKey); err != nil {
+		return ""
+	}
+
+	switch {
+	case len(aesKey.KeyValue) == aes128KeySize:
+		return kms.AES128GCMType
+	case key.OutputPrefixType == tinkpb.OutputPrefixType_RAW:
+		return kms.AES256GCMNoPrefixType
+	default:
+		return kms.AES256GCMType
+	}
+}
+
+func aesGCMHKDFKeyType(key *tinkpb.Keyset_Key) kms.KeyType {
+	streamingKey := new(gcmhkdfpb.AesGcmHkdfStreamingKey)
+
+	if err := proto.Unmarshal(key.KeyData.Value, streamingKey); err != nil {
+		return ""
+	}
+
+	switch {
+	case len(streamingKey.KeyValue) == aes128KeySize:
+		return kms.AES128GCMHKDF4KBType
+	case streamingKey.GetParams().GetCiphertextSegmentSize() == mbSegmentSize:
+		return kms.AES256GCMHKDF1MBType
+	default:
+		return kms.AES256GCMHKDF4KBType
+	}
+}
+
+func ecdsaKeyType(key *tinkpb.Keyset_Key) kms.KeyType {
+	ecdsaKey := new(ecdsapb.EcdsaPrivateKey)
+
+	if err := proto.Unmarshal(key.KeyData.Value, ecdsaKey); err != nil {
+		return ""
+	}
+
+	switch ecdsaKey.GetPublicKey().GetParams().GetCurve() {
+	case commonpb.EllipticCurveType_NIST_P256:
+		return kms.ECDSAP256Type
+	case commonpb.EllipticCurveType_NIST_P384:
+		return kms.ECDSAP384Type
+	case commonpb.EllipticCurveType_NIST_P521:
+		return kms.ECDSAP521Type
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrPaused is returned by the operations writing to the keystore while the LocalKMS is paused (see Pause).
+var ErrPaused = errors.New("kms is paused")
+
+// writeGate tracks the writes to the keystore in progress, it is shared by the copies of the LocalKMS (see
+// WithContext).
+type writeGate struct {
+	mu       sync.Mutex
+	paused   bool
+	inflight sync.WaitGroup
+}
+
+// Pause quiesces the writes to the keystore, eg: during a backup or a master key rotation. It returns once the writes
+// in progress are completed. Until Resume is called, the operations creating, rotating, importing or deleting keys
+// (Create, CreateWithParams, CreateFromPool, CreateLinkedKeySet, Rotate, DeriveAndStore, DeriveSigningKey with
+// WithDerivedKeyStored, UnsealKey, ImportArchive, DrainKeyPool and RewrapAll) return an error wrapping ErrPaused, the
+// key pools are not refilled. Reads continue, as do signatures, except the first signature of a key in a signature
+// domain (see WithDomain).
+func (l *LocalKMS) Pause() {
+	l.writes.mu.Lock()
+	l.writes.paused = true
+	l.writes.mu.Unlock()
+
+	l.writes.inflight.Wait()
+
+	l.audit(&AuditRecord{Operation: AuditOpPause}, nil)
+}
+
+// Resume resumes the writes to the keystore paused by Pause.
+func (l *LocalKMS) Resume() {
+	l.writes.mu.Lock()
+	l.writes.paused = false
+	l.writes.mu.Unlock()
+
+	l.audit(&AuditRecord{Operation: AuditOpResume}, nil)
+
+	// the key pools may have been drained while paused
+	for _, p := range l.keyPools {
+		l.refillKeyPool(p)
+	}
+}
+
+// Paused returns true if the writes to the keystore are paused (see Pause).
+func (l *LocalKMS) Paused() bool {
+	l.writes.mu.Lock()
+	defer l.writes.mu.Unlock()
+
+	return l.writes.paused
+}
+
+// beginWrite starts a write to the keystore, it returns ErrPaused if the LocalKMS is paused. The returned function
+// must be called once the write is completed.
+func (l *LocalKMS) beginWrite() (func(), error) {
+	l.writes.mu.Lock()
+	defer l.writes.mu.Unlock()
+
+	if l.writes.paused {
+		return nil, ErrPaused
+	}
+
+	l.writes.inflight.Add(1)
+
+	return l.writes.inflight.Done, nil
+}
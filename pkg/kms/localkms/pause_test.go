@@ -0,0 +1,171 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_Pause(t *testing.T) {
+	newKMS := func(t *testing.T, opts ...Option) *LocalKMS {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: createMasterKeyAndSecretLock(t),
+		}, opts...)
+		require.NoError(t, err)
+
+		return k
+	}
+
+	t.Run("test writes are rejected while paused and succeed after resume", func(t *testing.T) {
+		kmsService := newKMS(t)
+
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		baseKeyID, _, err := kmsService.Create(kms.HMACSHA256Tag256Type)
+		require.NoError(t, err)
+
+		kmsService.Pause()
+		require.True(t, kmsService.Paused())
+
+		_, _, err = kmsService.Create(kms.ED25519Type)
+		require.True(t, errors.Is(err, ErrPaused))
+
+		_, _, err = kmsService.CreateWithParams(kms.AES256GCMType, KeyParams{KeySize: 32})
+		require.True(t, errors.Is(err, ErrPaused))
+
+		_, _, err = kmsService.Rotate(kms.ED25519Type, keyID)
+		require.True(t, errors.Is(err, ErrPaused))
+
+		_, err = kmsService.CreateLinkedKeySet(kms.ED25519Type, kms.ECIESHKDFAES128GCMType, "")
+		require.True(t, errors.Is(err, ErrPaused))
+
+		_, _, err = kmsService.DeriveSigningKey(baseKeyID, []byte("did:example:verifier"), WithDerivedKeyStored())
+		require.True(t, errors.Is(err, ErrPaused))
+
+		// reads continue
+		_, _, err = kmsService.DeriveSigningKey(baseKeyID, []byte("did:example:verifier"))
+		require.NoError(t, err)
+
+		_, err = kmsService.Get(keyID)
+		require.NoError(t, err)
+
+		_, err = kmsService.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		sig, err := kmsService.SignWithKey(keyID, []byte("message"))
+		require.NoError(t, err)
+		require.NoError(t, kmsService.VerifyWithKey(keyID, sig, []byte("message")))
+
+		kmsService.Resume()
+		require.False(t, kmsService.Paused())
+
+		_, _, err = kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		newID, _, err := kmsService.Rotate(kms.ED25519Type, keyID)
+		require.NoError(t, err)
+		require.NotEqual(t, keyID, newID)
+	})
+
+	t.Run("test pause is shared by the copies bound to a context", func(t *testing.T) {
+		kmsService := newKMS(t)
+		callerKMS := kmsService.WithContext(WithCallerIdentity(context.Background(), "caller"))
+
+		kmsService.Pause()
+
+		_, _, err := callerKMS.Create(kms.ED25519Type)
+		require.True(t, errors.Is(err, ErrPaused))
+
+		callerKMS.Resume()
+		require.False(t, kmsService.Paused())
+
+		_, _, err = kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+	})
+
+	t.Run("test pause waits for the writes in progress", func(t *testing.T) {
+		kmsService := newKMS(t)
+
+		done, err := kmsService.beginWrite()
+		require.NoError(t, err)
+
+		paused := make(chan struct{})
+
+		go func() {
+			kmsService.Pause()
+			close(paused)
+		}()
+
+		select {
+		case <-paused:
+			require.Fail(t, "pause returned before the write in progress completed")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		done()
+
+		select {
+		case <-paused:
+		case <-time.After(time.Second):
+			require.Fail(t, "pause did not return once the write in progress completed")
+		}
+
+		_, _, err = kmsService.Create(kms.ED25519Type)
+		require.True(t, errors.Is(err, ErrPaused))
+	})
+
+	t.Run("test key pools are not refilled while paused", func(t *testing.T) {
+		kmsService := newKMS(t, WithKeyPool(kms.ED25519Type, KeyPoolConfig{Size: 2}))
+
+		require.Eventually(t, func() bool {
+			return kmsService.KeyPoolAvailable(kms.ED25519Type) == 2
+		}, time.Second, 10*time.Millisecond)
+
+		kmsService.Pause()
+
+		_, _, err := kmsService.CreateFromPool(kms.ED25519Type)
+		require.True(t, errors.Is(err, ErrPaused))
+
+		err = kmsService.DrainKeyPool(kms.ED25519Type)
+		require.True(t, errors.Is(err, ErrPaused))
+		require.Equal(t, 2, kmsService.KeyPoolAvailable(kms.ED25519Type))
+
+		kmsService.Resume()
+
+		_, _, err = kmsService.CreateFromPool(kms.ED25519Type)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return kmsService.KeyPoolAvailable(kms.ED25519Type) == 2
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("test pause and resume are audited", func(t *testing.T) {
+		logger := &recordingAuditLogger{}
+		kmsService := newKMS(t, WithAuditLogger(logger))
+
+		kmsService.Pause()
+		kmsService.Resume()
+
+		logger.mu.Lock()
+		defer logger.mu.Unlock()
+
+		require.Len(t, logger.records, 2)
+		require.Equal(t, AuditOpPause, logger.records[0].Operation)
+		require.Equal(t, AuditOpResume, logger.records[1].Operation)
+	})
+}
@@ -0,0 +1,70 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// BatchError is returned by PubKeyBytesToHandleBatch when some of the public keys could not be converted,
+// it holds the conversion error of each of these keys.
+type BatchError struct {
+	Errors map[string]error
+}
+
+// Error lists the keys which could not be converted, sorted by ID.
+func (e *BatchError) Error() string {
+	ids := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = fmt.Sprintf("%s: %s", id, e.Errors[id])
+	}
+
+	return fmt.Sprintf("failed to create key handles for %d keys: %s", len(ids), strings.Join(msgs, "; "))
+}
+
+// PubKeyBytesToHandleBatch creates key handles for the public keys of type kt, keys maps an ID chosen by the caller
+// (eg: a verification method ID) to the public key bytes. The handles are returned mapped to the same IDs.
+// A key which can't be converted does not abort the batch: the handles of the other keys are returned along with
+// a *BatchError holding the error of each failed key.
+// Note: like with PubKeyBytesToHandle, the key handles created are not stored in the KMS.
+func (l *LocalKMS) PubKeyBytesToHandleBatch(keys map[string][]byte, kt kms.KeyType) (map[string]*keyset.Handle, error) {
+	handles := make(map[string]*keyset.Handle, len(keys))
+	batchErr := &BatchError{Errors: map[string]error{}}
+
+	for id, pubKey := range keys {
+		kh, err := publicKeyBytesToHandle(pubKey, kt)
+		if err != nil {
+			batchErr.Errors[id] = err
+
+			continue
+		}
+
+		handles[id] = kh
+	}
+
+	var err error
+	if len(batchErr.Errors) > 0 {
+		err = batchErr
+	}
+
+	l.audit(&AuditRecord{Operation: AuditOpPubKeyBytesToHandle, KeyType: kt}, err)
+
+	return handles, err
+}
@@ -0,0 +1,92 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_PubKeyBytesToHandleBatch(t *testing.T) {
+	msg := []byte("message")
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	pubKeys := map[string][]byte{}
+	signatures := map[string][]byte{}
+
+	for i := 0; i < 3; i++ {
+		keyID, kh, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		peerID := fmt.Sprintf("did:example:peer%d#key-1", i)
+
+		pubKeys[peerID], err = kmsService.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		signer, err := signature.NewSigner(kh.(*keyset.Handle))
+		require.NoError(t, err)
+
+		signatures[peerID], err = signer.Sign(msg)
+		require.NoError(t, err)
+	}
+
+	t.Run("test convert valid keys", func(t *testing.T) {
+		handles, err := kmsService.PubKeyBytesToHandleBatch(pubKeys, kms.ED25519Type)
+		require.NoError(t, err)
+		require.Len(t, handles, len(pubKeys))
+
+		for peerID, kh := range handles {
+			verifier, err := signature.NewVerifier(kh)
+			require.NoError(t, err)
+			require.NoError(t, verifier.Verify(signatures[peerID], msg))
+		}
+	})
+
+	t.Run("test convert valid keys and a malformed key", func(t *testing.T) {
+		keys := map[string][]byte{"did:example:malformed#key-1": []byte("malformed")}
+		for peerID, pubKey := range pubKeys {
+			keys[peerID] = pubKey
+		}
+
+		handles, err := kmsService.PubKeyBytesToHandleBatch(keys, kms.ECDSAP256Type)
+		require.Error(t, err)
+		require.Empty(t, handles)
+
+		handles, err = kmsService.PubKeyBytesToHandleBatch(keys, kms.ED25519Type)
+		require.Len(t, handles, len(pubKeys))
+		require.NotContains(t, handles, "did:example:malformed#key-1")
+
+		batchErr := &BatchError{}
+		require.True(t, errors.As(err, &batchErr))
+		require.Len(t, batchErr.Errors, 1)
+		require.Error(t, batchErr.Errors["did:example:malformed#key-1"])
+		require.Contains(t, err.Error(), "failed to create key handles for 1 keys: did:example:malformed#key-1: ")
+	})
+
+	t.Run("test convert empty keys", func(t *testing.T) {
+		handles, err := kmsService.PubKeyBytesToHandleBatch(map[string][]byte{"empty": nil}, kms.ED25519Type)
+		require.EqualError(t, err, "failed to create key handles for 1 keys: empty: pubKey is empty")
+		require.Empty(t, handles)
+
+		handles, err = kmsService.PubKeyBytesToHandleBatch(nil, kms.ED25519Type)
+		require.NoError(t, err)
+		require.Empty(t, handles)
+	})
+}
@@ -0,0 +1,111 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// Algorithms of the exported public keys.
+const (
+	jwaEdDSA = "EdDSA"
+	jwaES256 = "ES256"
+	jwaES384 = "ES384"
+	jwaES512 = "ES512"
+
+	ldSuiteEd25519Signature2018 = "Ed25519Signature2018"
+	ldSuiteJSONWebSignature2020 = "JsonWebSignature2020"
+)
+
+// ExportPubKey fetches the asymmetric key referenced by id and returns its public key, with its key type and the
+// JWS algorithm and linked data signature suite it is intended for. ECDSA keys match a JWS algorithm only if they
+// hash with the hash function of their curve's algorithm (eg: the P-384 keys of the ECDSAP384 key type hash with
+// SHA-512, they match no JWS algorithm) and encode their signatures as IEEE P1363 (R || S) like JWS does: the DER
+// encoded signatures of the ECDSA keys created by Create match no JWS algorithm. Keys that are not signing keys have
+// no algorithm.
+// Use ExportPubKeyBytes for the public key bytes only.
+func (l *LocalKMS) ExportPubKey(id string) (*kms.PublicKey, error) {
+	pubKey, err := l.exportPubKey(id)
+	l.audit(&AuditRecord{Operation: AuditOpExportPubKey, KeyID: id}, err)
+
+	return pubKey, err
+}
+
+func (l *LocalKMS) exportPubKey(id string) (*kms.PublicKey, error) {
+	kh, err := l.getKeySet(id)
+	if err != nil {
+		return nil, fmt.Errorf("export public key: %w", err)
+	}
+
+	pubKeyBytes, err := publicKeyBytes(kh)
+	if err != nil {
+		return nil, fmt.Errorf("export public key: %w", err)
+	}
+
+	pubKey := &kms.PublicKey{
+		ID:      id,
+		KeyType: keyTypeOf(kh),
+		Bytes:   pubKeyBytes,
+	}
+
+	switch pubKey.KeyType {
+	case kms.ED25519Type:
+		pubKey.JWA, pubKey.LDSuite = jwaEdDSA, ldSuiteEd25519Signature2018
+	case kms.ECDSAP256Type, kms.ECDSAP384Type, kms.ECDSAP521Type:
+		pubKey.JWA = ecdsaJWA(kh)
+		if pubKey.JWA != "" {
+			pubKey.LDSuite = ldSuiteJSONWebSignature2020
+		}
+	}
+
+	return pubKey, nil
+}
+
+// ecdsaJWA returns the JWS algorithm of the primary ECDSA key of kh, empty if its curve and hash function match
+// none or if it doesn't encode its signatures as IEEE P1363.
+func ecdsaJWA(kh *keyset.Handle) string {
+	ks := insecurecleartextkeyset.KeysetMaterial(kh)
+
+	for _, key := range ks.Key {
+		if key.KeyId != ks.PrimaryKeyId {
+			continue
+		}
+
+		ecdsaKey := new(ecdsapb.EcdsaPrivateKey)
+
+		if err := proto.Unmarshal(key.KeyData.Value, ecdsaKey); err != nil {
+			return ""
+		}
+
+		params := ecdsaKey.GetPublicKey().GetParams()
+		if params.GetEncoding() != ecdsapb.EcdsaSignatureEncoding_IEEE_P1363 {
+			return ""
+		}
+
+		switch {
+		case params.GetCurve() == commonpb.EllipticCurveType_NIST_P256 &&
+			params.GetHashType() == commonpb.HashType_SHA256:
+			return jwaES256
+		case params.GetCurve() == commonpb.EllipticCurveType_NIST_P384 &&
+			params.GetHashType() == commonpb.HashType_SHA384:
+			return jwaES384
+		case params.GetCurve() == commonpb.EllipticCurveType_NIST_P521 &&
+			params.GetHashType() == commonpb.HashType_SHA512:
+			return jwaES512
+		}
+	}
+
+	return ""
+}
@@ -10,7 +10,6 @@ import (
 	"bytes"
 	"testing"
 
-	"github.com/google/tink/go/hybrid"
 	"github.com/google/tink/go/keyset"
 	commonpb "github.com/google/tink/go/proto/common_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
@@ -51,12 +50,17 @@ func TestPubKeyExportAndRead(t *testing.T) {
 			keyTemplate: signature.ED25519KeyWithoutPrefixTemplate(),
 			doSign:      true,
 		},
+		{
+			tcName:      "export then read ECIESHKDFAES128GCM public key",
+			keyType:     kms.ECIESHKDFAES128GCMType,
+			keyTemplate: eciesKeyWithoutPrefixTemplate(),
+		},
 	}
 
 	// nolint:scopelint
 	for _, tt := range flagTests {
 		t.Run(tt.tcName, func(t *testing.T) {
-			exportedKeyBytes, origKH := exportRawPublicKeyBytes(t, tt.keyTemplate, false)
+			exportedKeyBytes, origKH := exportRawPublicKeyBytes(t, tt.keyTemplate)
 
 			kh, err := publicKeyBytesToHandle(exportedKeyBytes, tt.keyType)
 			require.NoError(t, err)
@@ -84,7 +88,7 @@ func TestPubKeyExportAndRead(t *testing.T) {
 	}
 }
 
-func exportRawPublicKeyBytes(t *testing.T, keyTemplate *tinkpb.KeyTemplate, expectError bool) ([]byte, *keyset.Handle) {
+func exportRawPublicKeyBytes(t *testing.T, keyTemplate *tinkpb.KeyTemplate) ([]byte, *keyset.Handle) {
 	t.Helper()
 
 	kh, err := keyset.NewHandle(keyTemplate)
@@ -100,12 +104,6 @@ func exportRawPublicKeyBytes(t *testing.T, keyTemplate *tinkpb.KeyTemplate, expe
 	require.NotEmpty(t, pubKeyWriter)
 
 	err = pubKH.WriteWithNoSecrets(pubKeyWriter)
-
-	if expectError {
-		require.Error(t, err)
-		return nil, kh
-	}
-
 	require.NoError(t, err)
 	require.NotEmpty(t, buf.Bytes())
 
@@ -152,8 +150,16 @@ func TestNegativeCases(t *testing.T) {
 		require.Empty(t, kh)
 	})
 
-	t.Run("test exportRawPublicKeyBytes with an unsupported key template", func(t *testing.T) {
-		exportedKeyBytes, _ := exportRawPublicKeyBytes(t, hybrid.ECIESHKDFAES128GCMKeyTemplate(), true)
-		require.Empty(t, exportedKeyBytes)
+	t.Run("test write with an unsupported key type", func(t *testing.T) {
+		err := write(new(bytes.Buffer), &tinkpb.Keyset{
+			PrimaryKeyId: 1,
+			Key: []*tinkpb.Keyset_Key{{
+				KeyId:   1,
+				Status:  tinkpb.KeyStatusType_ENABLED,
+				KeyData: &tinkpb.KeyData{TypeUrl: "type.googleapis.com/google.crypto.tink.UnknownPublicKey"},
+			}},
+		})
+		require.EqualError(t, err, "key type not supported for writing raw key bytes: "+
+			"type.googleapis.com/google.crypto.tink.UnknownPublicKey")
 	})
 }
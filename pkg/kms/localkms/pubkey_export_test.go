@@ -0,0 +1,148 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_ExportPubKey(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	t.Run("test algorithms of the asymmetric key types", func(t *testing.T) {
+		tests := []struct {
+			keyType kms.KeyType
+			jwa     string
+			ldSuite string
+		}{
+			{keyType: kms.ED25519Type, jwa: "EdDSA", ldSuite: "Ed25519Signature2018"},
+			// the ECDSA keys encode their signatures as DER, JWS encodes them as IEEE P1363
+			{keyType: kms.ECDSAP256Type},
+			{keyType: kms.ECDSAP384Type},
+			{keyType: kms.ECDSAP521Type},
+			{keyType: kms.ECIESHKDFAES128GCMType},
+		}
+
+		for _, tc := range tests {
+			keyID, _, err := kmsService.Create(tc.keyType)
+			require.NoError(t, err)
+
+			pubKey, err := kmsService.ExportPubKey(keyID)
+			require.NoError(t, err, tc.keyType)
+
+			pubKeyBytes, err := kmsService.ExportPubKeyBytes(keyID)
+			require.NoError(t, err)
+
+			require.Equal(t, &kms.PublicKey{
+				ID:      keyID,
+				KeyType: tc.keyType,
+				Bytes:   pubKeyBytes,
+				JWA:     tc.jwa,
+				LDSuite: tc.ldSuite,
+			}, pubKey, tc.keyType)
+		}
+	})
+
+	t.Run("test algorithms of the ECDSA keys encoding signatures as IEEE P1363", func(t *testing.T) {
+		tests := []struct {
+			keyType  kms.KeyType
+			hashType commonpb.HashType
+			curve    commonpb.EllipticCurveType
+			encoding ecdsapb.EcdsaSignatureEncoding
+			jwa      string
+		}{
+			{
+				keyType:  kms.ECDSAP256Type,
+				hashType: commonpb.HashType_SHA256,
+				curve:    commonpb.EllipticCurveType_NIST_P256,
+				encoding: ecdsapb.EcdsaSignatureEncoding_IEEE_P1363,
+				jwa:      "ES256",
+			},
+			{
+				keyType:  kms.ECDSAP384Type,
+				hashType: commonpb.HashType_SHA384,
+				curve:    commonpb.EllipticCurveType_NIST_P384,
+				encoding: ecdsapb.EcdsaSignatureEncoding_IEEE_P1363,
+				jwa:      "ES384",
+			},
+			{
+				keyType:  kms.ECDSAP521Type,
+				hashType: commonpb.HashType_SHA512,
+				curve:    commonpb.EllipticCurveType_NIST_P521,
+				encoding: ecdsapb.EcdsaSignatureEncoding_IEEE_P1363,
+				jwa:      "ES512",
+			},
+			// ES384 hashes with SHA-384
+			{
+				keyType:  kms.ECDSAP384Type,
+				hashType: commonpb.HashType_SHA512,
+				curve:    commonpb.EllipticCurveType_NIST_P384,
+				encoding: ecdsapb.EcdsaSignatureEncoding_IEEE_P1363,
+			},
+			// JWS encodes the signatures as IEEE P1363
+			{
+				keyType:  kms.ECDSAP384Type,
+				hashType: commonpb.HashType_SHA384,
+				curve:    commonpb.EllipticCurveType_NIST_P384,
+				encoding: ecdsapb.EcdsaSignatureEncoding_DER,
+			},
+		}
+
+		for _, tc := range tests {
+			format, err := proto.Marshal(&ecdsapb.EcdsaKeyFormat{Params: &ecdsapb.EcdsaParams{
+				HashType: tc.hashType,
+				Curve:    tc.curve,
+				Encoding: tc.encoding,
+			}})
+			require.NoError(t, err)
+
+			keyID, _, err := kmsService.createFromTemplate(&tinkpb.KeyTemplate{
+				TypeUrl:          ecdsaSignerTypeURL,
+				Value:            format,
+				OutputPrefixType: tinkpb.OutputPrefixType_TINK,
+			}, &keyMetadata{KeyType: tc.keyType})
+			require.NoError(t, err)
+
+			pubKey, err := kmsService.ExportPubKey(keyID)
+			require.NoError(t, err)
+			require.Equal(t, tc.keyType, pubKey.KeyType)
+			require.Equal(t, tc.jwa, pubKey.JWA, tc)
+
+			if tc.jwa != "" {
+				require.Equal(t, "JsonWebSignature2020", pubKey.LDSuite)
+			} else {
+				require.Empty(t, pubKey.LDSuite)
+			}
+		}
+	})
+
+	t.Run("test export errors", func(t *testing.T) {
+		_, err := kmsService.ExportPubKey("unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "export public key")
+
+		keyID, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		_, err = kmsService.ExportPubKey(keyID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "export public key")
+	})
+}
@@ -0,0 +1,158 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// pemPublicKeyType is the PEM block type of SubjectPublicKeyInfo public keys (RFC 7468).
+const pemPublicKeyType = "PUBLIC KEY"
+
+// ExportPubKeyPEM exports the public key of the key keyID as a PEM-encoded DER SubjectPublicKeyInfo ("PUBLIC KEY"
+// block), as expected by TLS and PKI tooling. The key must be an ECDSAP256, ECDSAP384, ECDSAP521 or ED25519 key,
+// symmetric keys are rejected. The PEM block can be parsed with crypto/x509.ParsePKIXPublicKey.
+func (l *LocalKMS) ExportPubKeyPEM(keyID string) ([]byte, error) {
+	pemBytes, err := l.exportPubKeyPEM(keyID)
+	l.audit(&AuditRecord{Operation: AuditOpExportPubKeyPEM, KeyID: keyID}, err)
+
+	return pemBytes, err
+}
+
+func (l *LocalKMS) exportPubKeyPEM(keyID string) ([]byte, error) {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	kt := keyTypeOf(kh)
+
+	var curve elliptic.Curve
+
+	switch kt {
+	case kms.ECDSAP256Type:
+		curve = elliptic.P256()
+	case kms.ECDSAP384Type:
+		curve = elliptic.P384()
+	case kms.ECDSAP521Type:
+		curve = elliptic.P521()
+	case kms.ED25519Type:
+	default:
+		return nil, fmt.Errorf("export public key PEM: unsupported key type '%s'", kt)
+	}
+
+	pubKeyBytes, err := publicKeyBytes(kh)
+	if err != nil {
+		return nil, fmt.Errorf("export public key PEM: %w", err)
+	}
+
+	pubKey, err := cryptoPublicKey(pubKeyBytes, curve)
+	if err != nil {
+		return nil, fmt.Errorf("export public key PEM: %w", err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("export public key PEM: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKeyType, Bytes: der}), nil
+}
+
+// cryptoPublicKey returns the crypto public key of the raw public key bytes: an Ed25519 key if curve is nil, an
+// ECDSA key of curve otherwise.
+func cryptoPublicKey(pubKeyBytes []byte, curve elliptic.Curve) (interface{}, error) {
+	if curve == nil {
+		if len(pubKeyBytes) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 public key")
+		}
+
+		return ed25519.PublicKey(pubKeyBytes), nil
+	}
+
+	x, y := elliptic.Unmarshal(curve, pubKeyBytes)
+	if x == nil {
+		return nil, errors.New("invalid ecdsa public key")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// PubKeyPEMToHandle creates a verification key handle (with no secrets) from pemBytes, a PEM-encoded DER
+// SubjectPublicKeyInfo ("PUBLIC KEY" block) such as the ones exported by ExportPubKeyPEM, and returns it along with
+// the detected key type. Only Ed25519 and ECDSA P-256, P-384 and P-521 public keys are supported.
+// Note: like with PubKeyBytesToHandle, the key handle created is not stored in the KMS.
+func (l *LocalKMS) PubKeyPEMToHandle(pemBytes []byte) (*keyset.Handle, kms.KeyType, error) {
+	kh, kt, err := pubKeyPEMToHandle(pemBytes)
+	l.audit(&AuditRecord{Operation: AuditOpPubKeyPEMToHandle, KeyType: kt}, err)
+
+	return kh, kt, err
+}
+
+func pubKeyPEMToHandle(pemBytes []byte) (*keyset.Handle, kms.KeyType, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, "", errors.New("public key PEM to handle: no PEM block found")
+	}
+
+	if block.Type != pemPublicKeyType {
+		return nil, "", fmt.Errorf("public key PEM to handle: unsupported PEM block type '%s'", block.Type)
+	}
+
+	pubKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("public key PEM to handle: %w", err)
+	}
+
+	pubKeyBytes, kt, err := rawPublicKey(pubKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("public key PEM to handle: %w", err)
+	}
+
+	kh, err := publicKeyBytesToHandle(pubKeyBytes, kt)
+	if err != nil {
+		return nil, "", fmt.Errorf("public key PEM to handle: %w", err)
+	}
+
+	return kh, kt, nil
+}
+
+// rawPublicKey returns the raw bytes of the crypto public key pubKey, as expected by publicKeyBytesToHandle, and
+// its key type.
+func rawPublicKey(pubKey interface{}) ([]byte, kms.KeyType, error) {
+	switch k := pubKey.(type) {
+	case ed25519.PublicKey:
+		return k, kms.ED25519Type, nil
+	case *ecdsa.PublicKey:
+		var kt kms.KeyType
+
+		switch k.Curve {
+		case elliptic.P256():
+			kt = kms.ECDSAP256Type
+		case elliptic.P384():
+			kt = kms.ECDSAP384Type
+		case elliptic.P521():
+			kt = kms.ECDSAP521Type
+		default:
+			return nil, "", fmt.Errorf("unsupported ECDSA curve '%s'", k.Curve.Params().Name)
+		}
+
+		return elliptic.Marshal(k.Curve, k.X, k.Y), kt, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported public key type %T", pubKey)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	"github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_ExportPubKeyPEM(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		kt    kms.KeyType
+		curve elliptic.Curve
+	}{
+		{kt: kms.ECDSAP256Type, curve: elliptic.P256()},
+		{kt: kms.ECDSAP384Type, curve: elliptic.P384()},
+		{kt: kms.ECDSAP521Type, curve: elliptic.P521()},
+		{kt: kms.ED25519Type},
+	} {
+		tc := tc
+
+		t.Run("test PEM round trips through x509 for "+string(tc.kt), func(t *testing.T) {
+			keyID, _, err := kmsService.Create(tc.kt)
+			require.NoError(t, err)
+
+			pemBytes, err := kmsService.ExportPubKeyPEM(keyID)
+			require.NoError(t, err)
+
+			block, rest := pem.Decode(pemBytes)
+			require.NotNil(t, block)
+			require.Empty(t, rest)
+			require.Equal(t, "PUBLIC KEY", block.Type)
+
+			pubKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+			require.NoError(t, err)
+
+			pubKeyBytes, err := kmsService.ExportPubKeyBytes(keyID)
+			require.NoError(t, err)
+
+			if tc.curve == nil {
+				edPubKey, ok := pubKey.(ed25519.PublicKey)
+				require.True(t, ok)
+				require.Equal(t, pubKeyBytes, []byte(edPubKey))
+
+				return
+			}
+
+			ecPubKey, ok := pubKey.(*ecdsa.PublicKey)
+			require.True(t, ok)
+			require.Equal(t, tc.curve.Params().Name, ecPubKey.Curve.Params().Name)
+			require.Equal(t, pubKeyBytes, elliptic.Marshal(ecPubKey.Curve, ecPubKey.X, ecPubKey.Y))
+		})
+	}
+
+	t.Run("test symmetric and unsupported keys are rejected", func(t *testing.T) {
+		for _, kt := range []kms.KeyType{kms.AES256GCMType, kms.ChaCha20Poly1305Type, kms.HMACSHA256Tag256Type} {
+			keyID, _, err := kmsService.Create(kt)
+			require.NoError(t, err)
+
+			_, err = kmsService.ExportPubKeyPEM(keyID)
+			require.EqualError(t, err, "export public key PEM: unsupported key type '"+string(kt)+"'")
+		}
+
+		_, err := kmsService.ExportPubKeyPEM("unknown")
+		require.Error(t, err)
+	})
+
+	t.Run("test invalid public key bytes", func(t *testing.T) {
+		_, err := cryptoPublicKey([]byte("invalid"), nil)
+		require.EqualError(t, err, "invalid ed25519 public key")
+
+		_, err = cryptoPublicKey([]byte("invalid"), elliptic.P256())
+		require.EqualError(t, err, "invalid ecdsa public key")
+	})
+}
+
+func TestLocalKMS_PubKeyPEMToHandle(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	msg := []byte("test message")
+
+	t.Run("test Ed25519 PEM key verifies signatures", func(t *testing.T) {
+		pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		kh, kt, err := kmsService.PubKeyPEMToHandle(pemPublicKey(t, pubKey))
+		require.NoError(t, err)
+		require.Equal(t, kms.ED25519Type, kt)
+
+		verifier, err := signature.NewVerifier(kh)
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(ed25519.Sign(privKey, msg), msg))
+		require.Error(t, verifier.Verify(ed25519.Sign(privKey, []byte("other")), msg))
+	})
+
+	for _, tc := range []struct {
+		kt    kms.KeyType
+		curve elliptic.Curve
+		hash  crypto.Hash
+	}{
+		// the hash functions are the ones of the ECDSA keys created by LocalKMS
+		{kt: kms.ECDSAP256Type, curve: elliptic.P256(), hash: crypto.SHA256},
+		{kt: kms.ECDSAP384Type, curve: elliptic.P384(), hash: crypto.SHA512},
+		{kt: kms.ECDSAP521Type, curve: elliptic.P521(), hash: crypto.SHA512},
+	} {
+		tc := tc
+
+		t.Run("test ECDSA PEM key verifies signatures for "+string(tc.kt), func(t *testing.T) {
+			privKey, err := ecdsa.GenerateKey(tc.curve, rand.Reader)
+			require.NoError(t, err)
+
+			kh, kt, err := kmsService.PubKeyPEMToHandle(pemPublicKey(t, &privKey.PublicKey))
+			require.NoError(t, err)
+			require.Equal(t, tc.kt, kt)
+
+			h := tc.hash.New()
+			_, err = h.Write(msg)
+			require.NoError(t, err)
+
+			r, s, err := ecdsa.Sign(rand.Reader, privKey, h.Sum(nil))
+			require.NoError(t, err)
+
+			sig, err := asn1.Marshal(struct{ R, S *big.Int }{R: r, S: s})
+			require.NoError(t, err)
+
+			verifier, err := signature.NewVerifier(kh)
+			require.NoError(t, err)
+			require.NoError(t, verifier.Verify(sig, msg))
+			require.Error(t, verifier.Verify(sig, []byte("other")))
+		})
+	}
+
+	t.Run("test round trip of an exported PEM key", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+
+		pemBytes, err := kmsService.ExportPubKeyPEM(keyID)
+		require.NoError(t, err)
+
+		_, kt, err := kmsService.PubKeyPEMToHandle(pemBytes)
+		require.NoError(t, err)
+		require.Equal(t, kms.ECDSAP256Type, kt)
+	})
+
+	t.Run("test unsupported curves and key types are rejected", func(t *testing.T) {
+		p224Key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+		require.NoError(t, err)
+
+		_, _, err = kmsService.PubKeyPEMToHandle(pemPublicKey(t, &p224Key.PublicKey))
+		require.EqualError(t, err, "public key PEM to handle: unsupported ECDSA curve 'P-224'")
+
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		_, _, err = kmsService.PubKeyPEMToHandle(pemPublicKey(t, &rsaKey.PublicKey))
+		require.EqualError(t, err, "public key PEM to handle: unsupported public key type *rsa.PublicKey")
+	})
+
+	t.Run("test invalid PEM data", func(t *testing.T) {
+		_, _, err := kmsService.PubKeyPEMToHandle([]byte("not PEM"))
+		require.EqualError(t, err, "public key PEM to handle: no PEM block found")
+
+		_, _, err = kmsService.PubKeyPEMToHandle(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1}}))
+		require.EqualError(t, err, "public key PEM to handle: unsupported PEM block type 'PRIVATE KEY'")
+
+		_, _, err = kmsService.PubKeyPEMToHandle(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{1}}))
+		require.Error(t, err)
+	})
+}
+
+func pemPublicKey(t *testing.T, pubKey interface{}) []byte {
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
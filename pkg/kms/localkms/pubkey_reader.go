@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package localkms
 
 import (
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"fmt"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/google/tink/go/keyset"
 	commonpb "github.com/google/tink/go/proto/common_go_proto"
 	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	eciespb "github.com/google/tink/go/proto/ecies_aead_hkdf_go_proto"
 	ed25519pb "github.com/google/tink/go/proto/ed25519_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/subtle"
@@ -103,12 +105,15 @@ func getMarshalledProtoKeyAndKeyURL(pubKey []byte, kt kms.KeyType) ([]byte, stri
 		}
 	case kms.ED25519Type:
 		tURL = ed25519VerifierTypeURL
-		pubKeyProto := new(ed25519pb.Ed25519PublicKey)
-		pubKeyProto.Version = 0
-		pubKeyProto.KeyValue = make([]byte, len(pubKey))
-		copy(pubKeyProto.KeyValue, pubKey)
 
-		keyValue, err = proto.Marshal(pubKeyProto)
+		keyValue, err = getMarshalledED25519Key(pubKey)
+		if err != nil {
+			return nil, "", err
+		}
+	case kms.ECIESHKDFAES128GCMType:
+		tURL = eciesPublicKeyTypeURL
+
+		keyValue, err = getMarshalledECIESKey(pubKey)
 		if err != nil {
 			return nil, "", err
 		}
@@ -144,3 +149,38 @@ func getMarshalledECDSAKey(pubKey []byte, curveName string, c commonpb.EllipticC
 
 	return proto.Marshal(pubKeyProto)
 }
+
+func getMarshalledED25519Key(pubKey []byte) ([]byte, error) {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid key")
+	}
+
+	pubKeyProto := new(ed25519pb.Ed25519PublicKey)
+	pubKeyProto.Version = 0
+	pubKeyProto.KeyValue = make([]byte, len(pubKey))
+	copy(pubKeyProto.KeyValue, pubKey)
+
+	return proto.Marshal(pubKeyProto)
+}
+
+func getMarshalledECIESKey(pubKey []byte) ([]byte, error) {
+	// the key parameters are the ones of the keys created by LocalKMS
+	keyFormat := new(eciespb.EciesAeadHkdfKeyFormat)
+
+	err := proto.Unmarshal(eciesKeyWithoutPrefixTemplate().Value, keyFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	x, y := elliptic.Unmarshal(elliptic.P256(), pubKey)
+	if x == nil || y == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+
+	return proto.Marshal(&eciespb.EciesAeadHkdfPublicKey{
+		Version: 0,
+		Params:  keyFormat.Params,
+		X:       x.Bytes(),
+		Y:       y.Bytes(),
+	})
+}
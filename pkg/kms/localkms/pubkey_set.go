@@ -0,0 +1,59 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/google/tink/go/keyset"
+)
+
+// ExportPubKeySet fetches the asymmetric key referenced by keyID and returns its public keyset, with no secrets, as
+// JSON. Unlike ExportPubKeyBytes, the export keeps the key type and all the keys of the keyset, so signatures made
+// before a rotation still verify. The export can be read without the master key by ImportPublicKeySet.
+func (l *LocalKMS) ExportPubKeySet(keyID string) ([]byte, error) {
+	blob, err := l.exportPubKeySet(keyID)
+	l.audit(&AuditRecord{Operation: AuditOpExportPubKeySet, KeyID: keyID}, err)
+
+	return blob, err
+}
+
+func (l *LocalKMS) exportPubKeySet(keyID string) ([]byte, error) {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("export public keyset: %w", err)
+	}
+
+	pubKH, err := kh.Public()
+	if err != nil {
+		return nil, fmt.Errorf("export public keyset: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = pubKH.WriteWithNoSecrets(keyset.NewJSONWriter(buf))
+	if err != nil {
+		return nil, fmt.Errorf("export public keyset: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ImportPublicKeySet reads the public keyset blob exported by ExportPubKeySet, possibly by another agent with another
+// master key, into a verify-only key handle. The blob is not decrypted, keysets holding secret key material are
+// rejected. Like with PubKeyBytesToHandle, the key handle is not stored in the KMS.
+func (l *LocalKMS) ImportPublicKeySet(blob []byte) (*keyset.Handle, error) {
+	kh, err := keyset.ReadWithNoSecrets(keyset.NewJSONReader(bytes.NewReader(blob)))
+	if err != nil {
+		err = fmt.Errorf("import public keyset: %w", err)
+	}
+
+	l.audit(&AuditRecord{Operation: AuditOpImportPublicKeySet}, err)
+
+	return kh, err
+}
@@ -0,0 +1,125 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_ImportPublicKeySet(t *testing.T) {
+	newKMS := func(t *testing.T) *LocalKMS {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: createMasterKeyAndSecretLock(t),
+		})
+		require.NoError(t, err)
+
+		return k
+	}
+
+	// the signer and the verifier have different master keys
+	signer := newKMS(t)
+	verifier := newKMS(t)
+
+	msg := []byte("message")
+
+	for _, kt := range []kms.KeyType{kms.ED25519Type, kms.ECDSAP256Type, kms.ECDSAP384Type, kms.ECDSAP521Type} {
+		kt := kt
+
+		t.Run("test imported public keyset verifies signatures for "+string(kt), func(t *testing.T) {
+			keyID, _, err := signer.Create(kt)
+			require.NoError(t, err)
+
+			sig, err := signer.SignWithKey(keyID, msg)
+			require.NoError(t, err)
+
+			blob, err := signer.ExportPubKeySet(keyID)
+			require.NoError(t, err)
+
+			kh, err := verifier.ImportPublicKeySet(blob)
+			require.NoError(t, err)
+
+			v, err := signature.NewVerifier(kh)
+			require.NoError(t, err)
+			require.NoError(t, v.Verify(sig, msg))
+			require.Error(t, v.Verify(sig, []byte("other message")))
+
+			// the handle is verify-only
+			_, err = signature.NewSigner(kh)
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("test imported public keyset verifies signatures made before a rotation", func(t *testing.T) {
+		keyID, _, err := signer.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		sig, err := signer.SignWithKey(keyID, msg)
+		require.NoError(t, err)
+
+		rotatedKeyID, _, err := signer.Rotate(kms.ED25519Type, keyID)
+		require.NoError(t, err)
+
+		rotatedSig, err := signer.SignWithKey(rotatedKeyID, msg)
+		require.NoError(t, err)
+
+		blob, err := signer.ExportPubKeySet(rotatedKeyID)
+		require.NoError(t, err)
+
+		kh, err := verifier.ImportPublicKeySet(blob)
+		require.NoError(t, err)
+
+		v, err := signature.NewVerifier(kh)
+		require.NoError(t, err)
+		require.NoError(t, v.Verify(sig, msg))
+		require.NoError(t, v.Verify(rotatedSig, msg))
+	})
+
+	t.Run("test import rejects keysets with secrets and raw public keys", func(t *testing.T) {
+		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+		require.NoError(t, err)
+
+		buf := new(bytes.Buffer)
+		require.NoError(t, insecurecleartextkeyset.Write(kh, keyset.NewJSONWriter(buf)))
+
+		_, err = verifier.ImportPublicKeySet(buf.Bytes())
+		require.EqualError(t, err, "import public keyset: importing unencrypted secret key material is forbidden")
+
+		// ExportPubKeyBytes exports the raw public key, see PubKeyBytesToHandle
+		keyID, _, err := signer.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		pubKeyBytes, err := signer.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		_, err = verifier.ImportPublicKeySet(pubKeyBytes)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "import public keyset")
+	})
+
+	t.Run("test export errors", func(t *testing.T) {
+		_, err := signer.ExportPubKeySet("unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "export public keyset")
+
+		keyID, _, err := signer.Create(kms.AES128GCMType)
+		require.NoError(t, err)
+
+		_, err = signer.ExportPubKeySet(keyID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "export public keyset")
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	commonpb "github.com/google/tink/go/proto/common_go_proto"
 	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	eciespb "github.com/google/tink/go/proto/ecies_aead_hkdf_go_proto"
 	ed25519pb "github.com/google/tink/go/proto/ed25519_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/subtle"
@@ -24,6 +25,7 @@ import (
 const (
 	ecdsaVerifierTypeURL   = "type.googleapis.com/google.crypto.tink.EcdsaPublicKey"
 	ed25519VerifierTypeURL = "type.googleapis.com/google.crypto.tink.Ed25519PublicKey"
+	eciesPublicKeyTypeURL  = "type.googleapis.com/google.crypto.tink.EciesAeadHkdfPublicKey"
 )
 
 // PubKeyWriter will write the raw bytes of a Tink KeySet's primary public key
@@ -59,7 +61,7 @@ func write(w io.Writer, msg *tinkpb.Keyset) error {
 	for _, key := range ks {
 		if key.KeyId == primaryKID && key.Status == tinkpb.KeyStatusType_ENABLED {
 			switch key.KeyData.TypeUrl {
-			case ecdsaVerifierTypeURL, ed25519VerifierTypeURL:
+			case ecdsaVerifierTypeURL, ed25519VerifierTypeURL, eciesPublicKeyTypeURL:
 				created, err = writePubKey(w, key)
 				if err != nil {
 					return err
@@ -118,6 +120,23 @@ func writePubKey(w io.Writer, key *tinkpb.Keyset_Key) (bool, error) {
 
 		marshaledPubKey = make([]byte, len(pubKeyProto.KeyValue))
 		copy(marshaledPubKey, pubKeyProto.KeyValue)
+	case eciesPublicKeyTypeURL:
+		pubKeyProto := new(eciespb.EciesAeadHkdfPublicKey)
+
+		err := proto.Unmarshal(key.KeyData.Value, pubKeyProto)
+		if err != nil {
+			return false, err
+		}
+
+		curveName := commonpb.EllipticCurveType_name[int32(pubKeyProto.Params.KemParams.CurveType)]
+
+		curve := subtle.GetCurve(curveName)
+		if curve == nil {
+			return false, fmt.Errorf("undefined curve")
+		}
+
+		marshaledPubKey = elliptic.Marshal(curve, new(big.Int).SetBytes(pubKeyProto.X),
+			new(big.Int).SetBytes(pubKeyProto.Y))
 	default:
 		return false, fmt.Errorf("can't export key with keyURL:%s", key.KeyData.TypeUrl)
 	}
@@ -0,0 +1,218 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// rewrapMigrationKey is the key of the record of the progress of RewrapAll in the keystore
+const rewrapMigrationKey = "rewrap_migration"
+
+// rewrapMigration is the checkpoint of RewrapAll: the last keyset processed when migrating to a key wrap.
+type rewrapMigration struct {
+	Wrap      KeyWrap `json:"wrap"`
+	LastKeyID string  `json:"lastKeyID"`
+}
+
+// RewrapOption is an option of RewrapAll.
+type RewrapOption func(opts *rewrapOpts)
+
+type rewrapOpts struct {
+	dryRun bool
+}
+
+// WithDryRun option makes RewrapAll count the keysets remaining to rewrap without rewrapping them.
+func WithDryRun() RewrapOption {
+	return func(opts *rewrapOpts) {
+		opts.dryRun = true
+	}
+}
+
+// RewrapResult is the result of RewrapAll.
+type RewrapResult struct {
+	// Rewrapped is the number of keysets rewrapped.
+	Rewrapped int
+	// Remaining is the number of keysets remaining to rewrap, counted by a dry run (see WithDryRun).
+	Remaining int
+	// Resumed is set if RewrapAll resumed from the checkpoint of an interrupted RewrapAll.
+	Resumed bool
+}
+
+// RewrapAll rewraps the keysets stored with a previous key wrap with the key wrap of the KMS (see WithKeyWrap), to
+// complete a migration of the wrapping algorithm or of the master key. The key IDs are unchanged. The progress is
+// checkpointed in the keystore after each keyset, so a RewrapAll interrupted, eg: by a crash, resumes from the last
+// keyset processed when it is called again with the same key wrap. Keysets already wrapped with the key wrap of the
+// KMS are skipped. The checkpoint is removed once all the keysets are rewrapped.
+func (l *LocalKMS) RewrapAll(opts ...RewrapOption) (*RewrapResult, error) {
+	options := &rewrapOpts{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	result, err := l.rewrapAll(options.dryRun)
+	l.audit(&AuditRecord{Operation: AuditOpRewrapAll}, err)
+
+	if err != nil {
+		return nil, fmt.Errorf("rewrap all: %w", err)
+	}
+
+	return result, nil
+}
+
+func (l *LocalKMS) rewrapAll(dryRun bool) (*RewrapResult, error) {
+	if !dryRun {
+		done, err := l.beginWrite()
+		if err != nil {
+			return nil, err
+		}
+
+		defer done()
+	}
+
+	checkpoint, err := l.rewrapCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	keyIDs, err := l.keySetIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	// the keysets are processed in order, the checkpoint is the last keyset processed
+	sort.Strings(keyIDs)
+
+	result := &RewrapResult{Resumed: checkpoint != ""}
+
+	for _, keyID := range keyIDs {
+		if keyID <= checkpoint {
+			continue
+		}
+
+		rewrapped, e := l.rewrapKeySet(keyID, dryRun)
+		if e != nil {
+			return nil, e
+		}
+
+		if dryRun {
+			if rewrapped {
+				result.Remaining++
+			}
+
+			continue
+		}
+
+		if rewrapped {
+			result.Rewrapped++
+		}
+
+		e = l.saveRewrapCheckpoint(keyID)
+		if e != nil {
+			return nil, e
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	err = l.store.Delete(rewrapMigrationKey)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return nil, fmt.Errorf("remove checkpoint: %w", err)
+	}
+
+	return result, nil
+}
+
+// rewrapKeySet rewraps the keyset keyID with the key wrap of the KMS, it returns false if the keyset is already
+// wrapped with it. The keyset is only checked on a dry run.
+func (l *LocalKMS) rewrapKeySet(keyID string, dryRun bool) (bool, error) {
+	data, err := l.store.Get(keyID)
+	if err != nil {
+		return false, fmt.Errorf("keyset %s: %w", keyID, err)
+	}
+
+	envAEAD, wrapped, err := l.unwrapHeader(data)
+	if err != nil {
+		return false, fmt.Errorf("keyset %s: %w", keyID, err)
+	}
+
+	if envAEAD == l.masterKeyEnvAEAD {
+		return false, nil
+	}
+
+	if dryRun {
+		return true, nil
+	}
+
+	kh, err := keyset.Read(keyset.NewJSONReader(bytes.NewReader(wrapped)), envAEAD)
+	if err != nil {
+		return false, fmt.Errorf("keyset %s: %w", keyID, err)
+	}
+
+	data, err = l.wrapKeySet(kh)
+	if err != nil {
+		return false, fmt.Errorf("keyset %s: %w", keyID, err)
+	}
+
+	err = l.store.Put(keyID, data)
+	if err != nil {
+		return false, fmt.Errorf("keyset %s: %w", keyID, err)
+	}
+
+	return true, nil
+}
+
+// rewrapCheckpoint returns the last keyset processed by an interrupted RewrapAll migrating to the key wrap of the
+// KMS, empty if there is none. The checkpoint of a migration to another key wrap is ignored.
+func (l *LocalKMS) rewrapCheckpoint() (string, error) {
+	data, err := l.store.Get(rewrapMigrationKey)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	migration := &rewrapMigration{}
+
+	err = json.Unmarshal(data, migration)
+	if err != nil {
+		return "", fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	if migration.Wrap != l.keyWrap {
+		return "", nil
+	}
+
+	return migration.LastKeyID, nil
+}
+
+func (l *LocalKMS) saveRewrapCheckpoint(keyID string) error {
+	data, err := json.Marshal(&rewrapMigration{Wrap: l.keyWrap, LastKeyID: keyID})
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+
+	err = l.store.Put(rewrapMigrationKey, data)
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+
+	return nil
+}
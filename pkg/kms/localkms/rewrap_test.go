@@ -0,0 +1,178 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestLocalKMS_RewrapAll(t *testing.T) {
+	const keyCount = 10
+
+	secretLock := createMasterKeyAndSecretLock(t)
+	newWrap := KeyWrap{Algorithm: KeyWrapXChaCha20Poly1305, MasterKeyURI: testMasterKeyURI, Version: "2"}
+
+	newKMS := func(t *testing.T, store storage.Store, opts ...Option) *LocalKMS {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		}, opts...)
+		require.NoError(t, err)
+
+		return k
+	}
+
+	// newStore returns a store with keyCount keysets wrapped with the default key wrap
+	newStore := func(t *testing.T) (*mockstorage.MockStore, []string) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		kmsService := newKMS(t, store)
+
+		var keyIDs []string
+
+		for i := 0; i < keyCount; i++ {
+			keyID, _, err := kmsService.Create(kms.ED25519Type)
+			require.NoError(t, err)
+
+			keyIDs = append(keyIDs, keyID)
+		}
+
+		return store, keyIDs
+	}
+
+	storedKeyWrap := func(t *testing.T, store *mockstorage.MockStore, keyID string) *KeyWrap {
+		var wrapped wrappedKeySet
+		require.NoError(t, json.Unmarshal(store.Store[keyID], &wrapped))
+
+		return wrapped.Wrap
+	}
+
+	t.Run("test interrupted rewrap resumes and rewraps every keyset exactly once", func(t *testing.T) {
+		store, keyIDs := newStore(t)
+
+		puts := map[string]int{}
+		failAfter := 4
+
+		countingStore := &failingPutStore{Store: store, failPut: func(k string) bool {
+			if !strings.HasPrefix(k, testMasterKeyURI) {
+				return false
+			}
+
+			if failAfter == 0 {
+				return true
+			}
+
+			failAfter--
+			puts[k]++
+
+			return false
+		}}
+
+		kmsService := newKMS(t, countingStore, WithKeyWrap(newWrap))
+
+		result, err := kmsService.RewrapAll(WithDryRun())
+		require.NoError(t, err)
+		require.Equal(t, &RewrapResult{Remaining: keyCount}, result)
+
+		// crash after 4 keysets are rewrapped
+		_, err = kmsService.RewrapAll()
+		require.EqualError(t, err, "rewrap all: keyset "+sortedIDs(keyIDs)[4]+": put error")
+		require.Len(t, puts, 4)
+
+		result, err = kmsService.RewrapAll(WithDryRun())
+		require.NoError(t, err)
+		require.Equal(t, &RewrapResult{Remaining: keyCount - 4, Resumed: true}, result)
+
+		// restart
+		failAfter = -1
+		kmsService = newKMS(t, countingStore, WithKeyWrap(newWrap))
+
+		result, err = kmsService.RewrapAll()
+		require.NoError(t, err)
+		require.Equal(t, &RewrapResult{Rewrapped: keyCount - 4, Resumed: true}, result)
+
+		require.Len(t, puts, keyCount)
+
+		for _, keyID := range keyIDs {
+			require.Equal(t, 1, puts[keyID], keyID)
+			require.Equal(t, &newWrap, storedKeyWrap(t, store, keyID))
+
+			sig, err := kmsService.SignWithKey(keyID, []byte("message"))
+			require.NoError(t, err)
+			require.NoError(t, kmsService.VerifyWithKey(keyID, sig, []byte("message")))
+		}
+
+		// the checkpoint is removed, there is nothing left to rewrap
+		require.NotContains(t, store.Store, rewrapMigrationKey)
+
+		result, err = kmsService.RewrapAll()
+		require.NoError(t, err)
+		require.Equal(t, &RewrapResult{}, result)
+		require.Len(t, puts, keyCount)
+	})
+
+	t.Run("test checkpoint of a migration to another key wrap is ignored", func(t *testing.T) {
+		store, keyIDs := newStore(t)
+
+		otherWrap := KeyWrap{Algorithm: KeyWrapAES128GCM, MasterKeyURI: testMasterKeyURI, Version: "3"}
+		checkpoint, err := json.Marshal(&rewrapMigration{Wrap: otherWrap, LastKeyID: sortedIDs(keyIDs)[5]})
+		require.NoError(t, err)
+
+		store.Store[rewrapMigrationKey] = checkpoint
+
+		result, err := newKMS(t, store, WithKeyWrap(newWrap)).RewrapAll()
+		require.NoError(t, err)
+		require.Equal(t, &RewrapResult{Rewrapped: keyCount}, result)
+	})
+
+	t.Run("test rewrap errors", func(t *testing.T) {
+		store, _ := newStore(t)
+		kmsService := newKMS(t, store, WithKeyWrap(newWrap))
+
+		kmsService.Pause()
+
+		_, err := kmsService.RewrapAll()
+		require.True(t, errors.Is(err, ErrPaused))
+
+		// a dry run does not write
+		result, err := kmsService.RewrapAll(WithDryRun())
+		require.NoError(t, err)
+		require.Equal(t, keyCount, result.Remaining)
+
+		kmsService.Resume()
+
+		store.Store[rewrapMigrationKey] = []byte("{")
+
+		_, err = kmsService.RewrapAll()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "rewrap all: read checkpoint")
+
+		delete(store.Store, rewrapMigrationKey)
+
+		_, err = newKMS(t, &failingPutStore{Store: store, failPut: func(k string) bool {
+			return k == rewrapMigrationKey
+		}}, WithKeyWrap(newWrap)).RewrapAll()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "rewrap all: save checkpoint: put error")
+	})
+}
+
+func sortedIDs(keyIDs []string) []string {
+	sorted := append([]string(nil), keyIDs...)
+	sort.Strings(sorted)
+
+	return sorted
+}
@@ -0,0 +1,140 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"math/big"
+)
+
+// signRFC6979 signs the digest of a message hashed with hash with privKey, with the nonce generated
+// deterministically from the private key and the digest as specified by RFC 6979 section 3.2.
+func signRFC6979(privKey *ecdsa.PrivateKey, hash crypto.Hash, digest []byte) (r, s *big.Int) {
+	curve := privKey.Curve
+	n := curve.Params().N
+	e := bits2int(digest, n)
+
+	nonces := newRFC6979Nonces(privKey.D, n, hash, digest)
+
+	for {
+		k := nonces.next()
+
+		x, _ := curve.ScalarBaseMult(k.Bytes())
+
+		r = new(big.Int).Mod(x, n)
+		if r.Sign() == 0 {
+			continue
+		}
+
+		// s = k^-1 (e + r d) mod n
+		s = new(big.Int).Mul(r, privKey.D)
+		s.Add(s, e)
+		s.Mul(s, new(big.Int).ModInverse(k, n))
+		s.Mod(s, n)
+
+		if s.Sign() != 0 {
+			return r, s
+		}
+	}
+}
+
+// rfc6979Nonces generates the candidate nonces of RFC 6979 section 3.2 with HMAC_DRBG.
+type rfc6979Nonces struct {
+	n    *big.Int
+	hash crypto.Hash
+	k, v []byte
+	// started is set once the first nonce is generated, the state is updated before generating the next ones
+	started bool
+}
+
+func newRFC6979Nonces(x, n *big.Int, hash crypto.Hash, digest []byte) *rfc6979Nonces {
+	g := &rfc6979Nonces{n: n, hash: hash}
+
+	size := hash.Size()
+	g.v = make([]byte, size)
+	g.k = make([]byte, size)
+
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+
+	privKey := int2octets(x, n)
+	h1 := bits2octets(digest, n)
+
+	// steps d. to g.
+	for _, b := range []byte{0x00, 0x01} {
+		g.k = g.mac(g.k, g.v, []byte{b}, privKey, h1)
+		g.v = g.mac(g.k, g.v)
+	}
+
+	return g
+}
+
+// next returns the next nonce in [1, n-1], step h.
+func (g *rfc6979Nonces) next() *big.Int {
+	for {
+		if g.started {
+			g.k = g.mac(g.k, g.v, []byte{0x00})
+			g.v = g.mac(g.k, g.v)
+		}
+
+		g.started = true
+
+		var t []byte
+
+		for len(t)*8 < g.n.BitLen() {
+			g.v = g.mac(g.k, g.v)
+			t = append(t, g.v...)
+		}
+
+		k := bits2int(t, g.n)
+		if k.Sign() > 0 && k.Cmp(g.n) < 0 {
+			return k
+		}
+	}
+}
+
+func (g *rfc6979Nonces) mac(key []byte, data ...[]byte) []byte {
+	m := hmac.New(g.hash.New, key)
+
+	for _, d := range data {
+		m.Write(d) // nolint:errcheck // hash writes never fail
+	}
+
+	return m.Sum(nil)
+}
+
+// bits2int converts the leftmost bits of b, as many as the bit length of n, to an integer (RFC 6979 section 2.3.2).
+func bits2int(b []byte, n *big.Int) *big.Int {
+	i := new(big.Int).SetBytes(b)
+
+	if excess := len(b)*8 - n.BitLen(); excess > 0 {
+		i.Rsh(i, uint(excess))
+	}
+
+	return i
+}
+
+// int2octets converts x to a big endian byte string of the byte length of n (RFC 6979 section 2.3.3).
+func int2octets(x, n *big.Int) []byte {
+	out := make([]byte, (n.BitLen()+7)/8)
+	b := x.Bytes()
+
+	return append(out[:len(out)-len(b)], b...)
+}
+
+// bits2octets converts b to an integer modulo n, as a byte string (RFC 6979 section 2.3.4).
+func bits2octets(b []byte, n *big.Int) []byte {
+	z := bits2int(b, n)
+	if z.Cmp(n) >= 0 {
+		z.Sub(z, n)
+	}
+
+	return int2octets(z, n)
+}
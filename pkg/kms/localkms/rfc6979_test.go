@@ -0,0 +1,139 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestSignRFC6979(t *testing.T) {
+	hexInt := func(t *testing.T, s string) *big.Int {
+		b, err := hex.DecodeString(s)
+		require.NoError(t, err)
+
+		return new(big.Int).SetBytes(b)
+	}
+
+	// RFC 6979 appendix A.2.5, ECDSA 256 bits (prime field) with SHA-256
+	privKey := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     hexInt(t, "60FED4BA255A9D31C961EB74C6356D68C049B8923B61FA6CE669622E60F29FB6"),
+			Y:     hexInt(t, "7903FE1008B8BC99A41AE9E95628BC64F2F1B20C2D7E9F5177A3C294D4462299"),
+		},
+		D: hexInt(t, "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721"),
+	}
+
+	tests := []struct {
+		msg  string
+		k    string
+		r, s string
+	}{
+		{
+			msg: "sample",
+			k:   "A6E3C57DD01ABE90086538398355DD4C3B17AA873382B0F24D6129493D8AAD60",
+			r:   "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716",
+			s:   "F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8",
+		},
+		{
+			msg: "test",
+			k:   "D16B6AE827F17175E040871A1C7EC3500192C4C92677336EC2537ACAEE0008E0",
+			r:   "F1ABB023518351CD71D881567B1EA663ED3EFCF6C5132B354F28D3B0B7D38367",
+			s:   "019F4113742A2B14BD25926B49C649155F267E60D3814B4C0CC84250E46F0083",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.msg, func(t *testing.T) {
+			h := digest(crypto.SHA256, []byte(tc.msg))
+
+			k := newRFC6979Nonces(privKey.D, privKey.Curve.Params().N, crypto.SHA256, h).next()
+			require.Equal(t, hexInt(t, tc.k), k)
+
+			r, s := signRFC6979(privKey, crypto.SHA256, h)
+			require.Equal(t, hexInt(t, tc.r), r)
+			require.Equal(t, hexInt(t, tc.s), s)
+			require.True(t, ecdsa.Verify(&privKey.PublicKey, h, r, s))
+		})
+	}
+}
+
+func TestLocalKMS_DeterministicSignature(t *testing.T) {
+	msg := []byte("message")
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	for _, kt := range []kms.KeyType{kms.ECDSAP256Type, kms.ECDSAP384Type, kms.ECDSAP521Type} {
+		kt := kt
+
+		t.Run(string(kt), func(t *testing.T) {
+			keyID, _, err := kmsService.Create(kt)
+			require.NoError(t, err)
+
+			sig, err := kmsService.SignWithKey(keyID, msg, WithDeterministicSignature())
+			require.NoError(t, err)
+
+			again, err := kmsService.SignWithKey(keyID, msg, WithDeterministicSignature())
+			require.NoError(t, err)
+			require.Equal(t, sig, again)
+
+			// deterministic signatures are verified like the randomized ones
+			require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg))
+
+			other, err := kmsService.SignWithKey(keyID, []byte("other message"), WithDeterministicSignature())
+			require.NoError(t, err)
+			require.NotEqual(t, sig, other)
+
+			randomized, err := kmsService.SignWithKey(keyID, msg)
+			require.NoError(t, err)
+			require.NotEqual(t, sig, randomized)
+		})
+	}
+
+	t.Run("test deterministic signature with another hash function", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+
+		sig, err := kmsService.SignWithKey(keyID, msg, WithDeterministicSignature(), WithHash(crypto.SHA384))
+		require.NoError(t, err)
+
+		again, err := kmsService.SignWithKey(keyID, msg, WithHash(crypto.SHA384), WithDeterministicSignature())
+		require.NoError(t, err)
+		require.Equal(t, sig, again)
+
+		require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg, WithHash(crypto.SHA384)))
+
+		signature := ecdsaSignature{}
+		_, err = asn1.Unmarshal(sig, &signature)
+		require.NoError(t, err)
+	})
+
+	t.Run("test deterministic signature with a non ECDSA key", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = kmsService.SignWithKey(keyID, msg, WithDeterministicSignature())
+		require.EqualError(t, err, "sign with key: not an ECDSA key")
+	})
+}
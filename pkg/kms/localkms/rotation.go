@@ -0,0 +1,145 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var logger = log.New("aries-framework/kms/localkms")
+
+// rotatedKeySetPrefix prefixes the record replacing a rotated keyset until it is deleted, and the key of the record
+// marking a retained keyset as rotated.
+const rotatedKeySetPrefix = "rotated:"
+
+// ConflictError is returned by Rotate when the keyset was rotated concurrently (eg: by another node sharing
+// the keystore) after it was read. The keyset is not rotated again, it must be read again before retrying.
+type ConflictError struct {
+	KeyID string
+	Err   error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("keyset %s was rotated concurrently: %v", e.KeyID, e.Err)
+}
+
+// Unwrap returns the underlying storage.ErrVersionConflict error.
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// keySetVersion returns the version of the stored keyset keyID, to be checked by claimRotation.
+func (l *LocalKMS) keySetVersion(keyID string) ([]byte, error) {
+	bytes, err := l.store.Get(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.Version(bytes), nil
+}
+
+// claimRotation marks the keyset keyID as rotated into newID if it is still at version, before it is deleted.
+// A retained keyset is kept as is, it is marked as rotated by a separate record which must not exist yet.
+// If another rotation of keyID won, the newID keyset is deleted and a ConflictError is returned.
+func (l *LocalKMS) claimRotation(keyID, newID string, version []byte, retain bool) error {
+	if _, ok := l.store.(storage.VersionedStore); !ok {
+		logger.Warnf("keystore does not support compare-and-swap, concurrent rotations of keyset %s "+
+			"may not be detected", keyID)
+	}
+
+	k, expectedVersion := keyID, version
+	if retain {
+		k, expectedVersion = rotatedKeySetPrefix+keyID, nil
+	}
+
+	err := storage.PutIfMatch(l.store, k, []byte(rotatedKeySetPrefix+newID), expectedVersion)
+	if err == nil {
+		return nil
+	}
+
+	if e := l.store.Delete(newID); e != nil {
+		logger.Warnf("failed to delete keyset %s after a failed rotation: %v", newID, e)
+	}
+
+	if errors.Is(err, storage.ErrVersionConflict) {
+		return &ConflictError{KeyID: keyID, Err: err}
+	}
+
+	return err
+}
+
+// deleteKeySet deletes the keyset keyID and its metadata.
+func (l *LocalKMS) deleteKeySet(keyID string) error {
+	err := l.store.Delete(keyID)
+	if err != nil {
+		return err
+	}
+
+	return l.deleteMetadata(keyID)
+}
+
+// isRotated checks if the keyset keyID was retained by a rotation (see kms.WithRetainPrevious).
+func (l *LocalKMS) isRotated(keyID string) (bool, error) {
+	_, err := l.store.Get(rotatedKeySetPrefix + keyID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// RotationRecord is an entry of the rotation history of a key: a prior ID of the key and when it was rotated.
+type RotationRecord struct {
+	KeyID   string    `json:"keyID"`
+	Rotated time.Time `json:"rotated"`
+}
+
+// RotationHistory returns the rotation lineage of the key keyID: the IDs the key had before its rotations, from
+// the oldest to the most recent, along with their rotation times. The history is empty if the key was never rotated.
+func (l *LocalKMS) RotationHistory(keyID string) ([]RotationRecord, error) {
+	if _, err := l.store.Get(keyID); err != nil {
+		return nil, fmt.Errorf("get rotation history: %w", err)
+	}
+
+	metadata, err := l.getMetadata(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata == nil {
+		return nil, nil
+	}
+
+	return metadata.Rotations, nil
+}
+
+// rotatedMetadata returns the metadata of the key rotated from keyID as a kt key: it has the external reference
+// of keyID, if any, and its rotation history is the one of keyID followed by keyID.
+func (l *LocalKMS) rotatedMetadata(keyID string, kt kms.KeyType) (*keyMetadata, error) {
+	previous, err := l.getMetadata(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &keyMetadata{KeyType: kt}
+
+	if previous != nil {
+		metadata.ExternalRef = previous.ExternalRef
+		metadata.SignatureDomain = previous.SignatureDomain
+		metadata.Rotations = append(metadata.Rotations, previous.Rotations...)
+	}
+
+	metadata.Rotations = append(metadata.Rotations, RotationRecord{KeyID: keyID, Rotated: time.Now().UTC()})
+
+	return metadata, nil
+}
@@ -0,0 +1,203 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestLocalKMS_RotateConflict(t *testing.T) {
+	secretLock := createMasterKeyAndSecretLock(t)
+
+	// newNodes returns two LocalKMS sharing a keystore. The keystore of the first one calls onPut once on its next
+	// Put, that is when the first one stores the rotated keyset.
+	newNodes := func(t *testing.T, versioned bool) (*LocalKMS, *LocalKMS, *hookStore) {
+		shared := mockstorage.NewMockStoreProvider()
+		hooked := &hookStore{Store: shared.Store}
+
+		var store storage.Store = hooked
+		if versioned {
+			store = &versionedHookStore{hookStore: hooked}
+		}
+
+		node1, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		})
+		require.NoError(t, err)
+
+		node2, err := New(testMasterKeyURI, &mockProvider{storage: shared, secretLock: secretLock})
+		require.NoError(t, err)
+
+		return node1, node2, hooked
+	}
+
+	for _, versioned := range []bool{true, false} {
+		versioned := versioned
+
+		t.Run(fmt.Sprintf("test concurrent rotation fails with ConflictError (versioned store: %t)", versioned),
+			func(t *testing.T) {
+				node1, node2, hooked := newNodes(t, versioned)
+
+				keyID, _, err := node1.Create(kms.ED25519Type)
+				require.NoError(t, err)
+
+				var node2ID string
+
+				hooked.onPut = func() {
+					node2ID, _, err = node2.Rotate(kms.ED25519Type, keyID)
+					require.NoError(t, err)
+				}
+
+				_, _, err = node1.Rotate(kms.ED25519Type, keyID)
+				require.Error(t, err)
+
+				var conflictErr *ConflictError
+				require.True(t, errors.As(err, &conflictErr))
+				require.Equal(t, keyID, conflictErr.KeyID)
+				require.True(t, errors.Is(err, storage.ErrVersionConflict))
+
+				// the rotation of the second node is kept and the keyset stored by the first one is removed
+				_, err = node1.Get(node2ID)
+				require.NoError(t, err)
+
+				var keySets []string
+
+				for k := range hooked.Store.(*mockstorage.MockStore).Store {
+					if strings.HasPrefix(k, testMasterKeyURI) {
+						keySets = append(keySets, k)
+					}
+				}
+
+				require.Equal(t, []string{node2ID}, keySets)
+			})
+	}
+
+	t.Run("test rotation without conflict", func(t *testing.T) {
+		node1, _, _ := newNodes(t, true)
+
+		keyID, _, err := node1.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		newID, _, err := node1.Rotate(kms.ED25519Type, keyID)
+		require.NoError(t, err)
+
+		_, err = node1.Get(keyID)
+		require.Error(t, err)
+
+		_, err = node1.Get(newID)
+		require.NoError(t, err)
+	})
+}
+
+// hookStore calls onPut once on the next Put.
+type hookStore struct {
+	storage.Store
+	onPut func()
+}
+
+func (s *hookStore) Put(k string, v []byte) error {
+	if onPut := s.onPut; onPut != nil {
+		s.onPut = nil
+
+		onPut()
+	}
+
+	return s.Store.Put(k, v)
+}
+
+// versionedHookStore is a hookStore supporting compare-and-swap.
+type versionedHookStore struct {
+	*hookStore
+}
+
+func (s *versionedHookStore) PutIfMatch(k string, v, expectedVersion []byte) error {
+	return s.Store.(storage.VersionedStore).PutIfMatch(k, v, expectedVersion)
+}
+
+func TestLocalKMS_RotationHistory(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	t.Run("test history of a key rotated twice", func(t *testing.T) {
+		keyID, _, err := kmsService.CreateWithOptions(kms.ED25519Type, kms.WithExternalRef("rotated-twice"))
+		require.NoError(t, err)
+
+		history, err := kmsService.RotationHistory(keyID)
+		require.NoError(t, err)
+		require.Empty(t, history)
+
+		secondID, _, err := kmsService.Rotate(kms.ED25519Type, keyID)
+		require.NoError(t, err)
+
+		thirdID, _, err := kmsService.Rotate(kms.ED25519Type, secondID)
+		require.NoError(t, err)
+
+		history, err = kmsService.RotationHistory(thirdID)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, keyID, history[0].KeyID)
+		require.Equal(t, secondID, history[1].KeyID)
+		require.False(t, history[1].Rotated.Before(history[0].Rotated))
+
+		// the external reference follows the rotations too
+		refID, err := kmsService.GetByExternalRef("rotated-twice")
+		require.NoError(t, err)
+		require.Equal(t, thirdID, refID)
+	})
+
+	t.Run("test history of an unknown key", func(t *testing.T) {
+		_, err := kmsService.RotationHistory("unknown")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+}
+
+func TestLocalKMS_RotateRetainPrevious(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	keyID, _, err := kmsService.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	pubKey, err := kmsService.ExportPubKeyBytes(keyID)
+	require.NoError(t, err)
+
+	newID, _, err := kmsService.RotateWithOptions(kms.ED25519Type, keyID, kms.WithRetainPrevious())
+	require.NoError(t, err)
+
+	// the previous key is still available under its ID
+	retainedPubKey, err := kmsService.ExportPubKeyBytes(keyID)
+	require.NoError(t, err)
+	require.Equal(t, pubKey, retainedPubKey)
+
+	history, err := kmsService.RotationHistory(newID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, keyID, history[0].KeyID)
+
+	// a retained key is rotated once
+	_, _, err = kmsService.RotateWithOptions(kms.ED25519Type, keyID, kms.WithRetainPrevious())
+	require.True(t, errors.Is(err, storage.ErrVersionConflict))
+
+	var conflictErr *ConflictError
+	require.True(t, errors.As(err, &conflictErr))
+}
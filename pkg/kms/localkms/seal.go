@@ -0,0 +1,156 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/tink/go/hybrid"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/tink"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// sealedKeyType is the type of the sealed key format produced by SealKey.
+const sealedKeyType = "LocalKMSSealedKey/1.0"
+
+// sealedKey is the offline transport format of a keyset sealed to a target public key.
+type sealedKey struct {
+	Type   string          `json:"type"`
+	Keyset json.RawMessage `json:"keyset"`
+}
+
+// SealKey re-encrypts the keyset referenced by keyID to toPublicKey, the raw bytes of an
+// ECIESHKDFAES128GCMType public key of the target KMS (see ExportPubKeyBytes), for offline transport.
+// The returned data can only be imported by the KMS holding the matching private key using UnsealKey.
+func (l *LocalKMS) SealKey(keyID string, toPublicKey []byte) ([]byte, error) {
+	sealed, err := l.sealKey(keyID, toPublicKey)
+	l.audit(&AuditRecord{Operation: AuditOpSealKey, KeyID: keyID}, err)
+
+	return sealed, err
+}
+
+func (l *LocalKMS) sealKey(keyID string, toPublicKey []byte) ([]byte, error) {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKH, err := publicKeyBytesToHandle(toPublicKey, kms.ECIESHKDFAES128GCMType)
+	if err != nil {
+		return nil, fmt.Errorf("seal key: %w", err)
+	}
+
+	enc, err := hybrid.NewHybridEncrypt(pubKH)
+	if err != nil {
+		return nil, fmt.Errorf("seal key: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = kh.Write(keyset.NewJSONWriter(buf), &hybridAEAD{enc: enc})
+	if err != nil {
+		return nil, fmt.Errorf("seal key: %w", err)
+	}
+
+	return json.Marshal(&sealedKey{Type: sealedKeyType, Keyset: buf.Bytes()})
+}
+
+// UnsealKey decrypts data sealed by SealKey using the private key referenced by usingKeyID, stores
+// the transported keyset and returns its new key ID. The imported key can be tagged with a unique external
+// reference (see kms.WithExternalRef).
+func (l *LocalKMS) UnsealKey(data []byte, usingKeyID string, opts ...kms.KeyOption) (string, error) {
+	keyOpts := &kms.KeyOpts{}
+
+	for _, opt := range opts {
+		opt(keyOpts)
+	}
+
+	kID, err := l.unsealKey(data, usingKeyID, keyOpts.ExternalRef())
+	l.audit(&AuditRecord{Operation: AuditOpUnsealKey, KeyID: usingKeyID, NewKeyID: kID}, err)
+
+	return kID, err
+}
+
+func (l *LocalKMS) unsealKey(data []byte, usingKeyID, externalRef string) (string, error) {
+	done, err := l.beginWrite()
+	if err != nil {
+		return "", fmt.Errorf("unseal key: %w", err)
+	}
+
+	defer done()
+
+	sealed := &sealedKey{}
+
+	err = json.Unmarshal(data, sealed)
+	if err != nil {
+		return "", fmt.Errorf("unseal key: %w", err)
+	}
+
+	if sealed.Type != sealedKeyType {
+		return "", fmt.Errorf("unseal key: unsupported sealed key type '%s'", sealed.Type)
+	}
+
+	kh, err := l.getKeySet(usingKeyID)
+	if err != nil {
+		return "", err
+	}
+
+	dec, err := hybrid.NewHybridDecrypt(kh)
+	if err != nil {
+		return "", fmt.Errorf("unseal key: %w", err)
+	}
+
+	transported, err := keyset.Read(keyset.NewJSONReader(bytes.NewReader(sealed.Keyset)), &hybridAEAD{dec: dec})
+	if err != nil {
+		return "", fmt.Errorf("unseal key: %w", err)
+	}
+
+	if externalRef != "" {
+		err = l.checkExternalRef(externalRef)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	kID, err := l.storeKeySet(transported)
+	if err != nil {
+		return "", err
+	}
+
+	err = l.saveMetadata(kID, &keyMetadata{KeyType: keyTypeOf(transported), ExternalRef: externalRef})
+	if err != nil {
+		return "", err
+	}
+
+	return kID, nil
+}
+
+// hybridAEAD adapts hybrid encryption primitives to tink.AEAD to encrypt and decrypt keysets.
+type hybridAEAD struct {
+	enc tink.HybridEncrypt
+	dec tink.HybridDecrypt
+}
+
+func (h *hybridAEAD) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	if h.enc == nil {
+		return nil, fmt.Errorf("encryption is not supported")
+	}
+
+	return h.enc.Encrypt(plaintext, additionalData)
+}
+
+func (h *hybridAEAD) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	if h.dec == nil {
+		return nil, fmt.Errorf("decryption is not supported")
+	}
+
+	return h.dec.Decrypt(ciphertext, additionalData)
+}
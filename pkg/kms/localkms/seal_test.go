@@ -0,0 +1,90 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_SealUnsealKey(t *testing.T) {
+	newKMS := func(t *testing.T) *LocalKMS {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: createMasterKeyAndSecretLock(t),
+		})
+		require.NoError(t, err)
+
+		return k
+	}
+
+	// source and destination KMS use different master keys
+	source := newKMS(t)
+	destination := newKMS(t)
+
+	transportKeyID, _, err := destination.Create(kms.ECIESHKDFAES128GCMType)
+	require.NoError(t, err)
+
+	transportPubKey, err := destination.ExportPubKeyBytes(transportKeyID)
+	require.NoError(t, err)
+
+	keyID, _, err := source.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	t.Run("test seal on one KMS and unseal on another", func(t *testing.T) {
+		sealed, err := source.SealKey(keyID, transportPubKey)
+		require.NoError(t, err)
+		require.NotEmpty(t, sealed)
+
+		unsealedKeyID, err := destination.UnsealKey(sealed, transportKeyID)
+		require.NoError(t, err)
+		require.NotEmpty(t, unsealedKeyID)
+
+		expected, err := source.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		actual, err := destination.ExportPubKeyBytes(unsealedKeyID)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("test unseal with another key fails", func(t *testing.T) {
+		sealed, err := source.SealKey(keyID, transportPubKey)
+		require.NoError(t, err)
+
+		otherKeyID, _, err := destination.Create(kms.ECIESHKDFAES128GCMType)
+		require.NoError(t, err)
+
+		_, err = destination.UnsealKey(sealed, otherKeyID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unseal key")
+	})
+
+	t.Run("test seal failures", func(t *testing.T) {
+		_, err := source.SealKey("unknown", transportPubKey)
+		require.Error(t, err)
+
+		_, err = source.SealKey(keyID, []byte("invalid public key"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "seal key")
+	})
+
+	t.Run("test unseal invalid data", func(t *testing.T) {
+		_, err := destination.UnsealKey([]byte("{"), transportKeyID)
+		require.Error(t, err)
+
+		_, err = destination.UnsealKey([]byte(`{"type":"other"}`), transportKeyID)
+		require.EqualError(t, err, "unseal key: unsupported sealed key type 'other'")
+
+		_, err = destination.UnsealKey([]byte(`{"type":"`+sealedKeyType+`","keyset":{}}`), transportKeyID)
+		require.Error(t, err)
+	})
+}
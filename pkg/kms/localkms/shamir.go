@@ -0,0 +1,137 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// maxShares is the maximum number of shares of a secret: the shares are the points x = 1..255 of GF(2^8).
+const maxShares = 255
+
+// splitSecret splits secret into n shares with Shamir's secret sharing scheme over GF(2^8), any k of which
+// reconstruct secret with combineShares. Each byte of secret is the constant term of a random polynomial of degree
+// k-1, share i holds the values of these polynomials at x = i+1, so fewer than k shares reveal nothing about secret.
+func splitSecret(secret []byte, n, k int) ([][]byte, error) {
+	if k < 2 || k > n || n > maxShares {
+		return nil, fmt.Errorf("invalid %d of %d shares, 2 <= k <= n <= %d", k, n, maxShares)
+	}
+
+	if len(secret) == 0 {
+		return nil, errors.New("empty secret")
+	}
+
+	coefficients := make([]byte, len(secret)*(k-1))
+
+	_, err := rand.Read(coefficients)
+	if err != nil {
+		return nil, err
+	}
+
+	defer zeroize(coefficients)
+
+	shares := make([][]byte, n)
+
+	for i := range shares {
+		x := byte(i + 1)
+		shares[i] = make([]byte, len(secret))
+
+		for b := range secret {
+			poly := coefficients[b*(k-1) : (b+1)*(k-1)]
+
+			// Horner's method, from the highest degree coefficient down to the secret byte
+			var y byte
+
+			for d := len(poly) - 1; d >= 0; d-- {
+				y = gfMul(y, x) ^ poly[d]
+			}
+
+			shares[i][b] = gfMul(y, x) ^ secret[b]
+		}
+	}
+
+	return shares, nil
+}
+
+// combineShares reconstructs the secret from the shares values ys at the distinct non zero points xs, by Lagrange
+// interpolation of the polynomials at x = 0. The result is only the secret if at least k shares are combined.
+func combineShares(xs []byte, ys [][]byte) ([]byte, error) {
+	if len(xs) != len(ys) || len(xs) == 0 {
+		return nil, errors.New("no shares")
+	}
+
+	for i := range xs {
+		if xs[i] == 0 {
+			return nil, errors.New("invalid share index 0")
+		}
+
+		if len(ys[i]) != len(ys[0]) {
+			return nil, errors.New("shares of different lengths")
+		}
+
+		for j := 0; j < i; j++ {
+			if xs[i] == xs[j] {
+				return nil, fmt.Errorf("duplicate share %d", xs[i])
+			}
+		}
+	}
+
+	secret := make([]byte, len(ys[0]))
+
+	for i := range xs {
+		// the Lagrange basis polynomial of share i at x = 0: prod xj / (xj - xi), subtraction is XOR in GF(2^8)
+		basis := byte(1)
+
+		for j := range xs {
+			if j != i {
+				basis = gfMul(basis, gfMul(xs[j], gfInv(xs[j]^xs[i])))
+			}
+		}
+
+		for b := range secret {
+			secret[b] ^= gfMul(ys[i][b], basis)
+		}
+	}
+
+	return secret, nil
+}
+
+// gfMul multiplies a and b in GF(2^8) with the AES reduction polynomial x^8 + x^4 + x^3 + x + 1, without branching
+// on the operands.
+func gfMul(a, b byte) byte {
+	var p byte
+
+	for i := 0; i < 8; i++ {
+		p ^= -(b & 1) & a
+		a = (a << 1) ^ (-(a >> 7) & 0x1b)
+		b >>= 1
+	}
+
+	return p
+}
+
+// gfInv returns the multiplicative inverse of a != 0 in GF(2^8), a^254.
+func gfInv(a byte) byte {
+	// a^254 = a^2 * a^4 * ... * a^128
+	inv := byte(1)
+	sq := a
+
+	for i := 0; i < 7; i++ {
+		sq = gfMul(sq, sq)
+		inv = gfMul(inv, sq)
+	}
+
+	return inv
+}
+
+func zeroize(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
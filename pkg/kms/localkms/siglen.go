@@ -0,0 +1,90 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// ErrInvalidSignatureLength is returned when verifying a signature whose length is invalid for the type of the key,
+// eg: a truncated signature.
+var ErrInvalidSignatureLength = errors.New("invalid signature length")
+
+// minECDSASignatureLength is the length of the shortest DER encoded ECDSA signature: a sequence of two one byte
+// integers
+const minECDSASignatureLength = 8
+
+// signatureLengthRange returns the minimum and maximum lengths of the signatures of the keys of type kt, false if kt
+// is not a signing key type. ECDSA signatures are either DER encoded, up to 72, 104 and 139 bytes for the P-256, P-384
+// and P-521 curves, or IEEE P1363 encoded (64, 96 and 132 bytes).
+func signatureLengthRange(kt kms.KeyType) (int, int, bool) {
+	const (
+		ed25519SignatureLength = 64
+		maxECDSAP256Length     = 72
+		maxECDSAP384Length     = 104
+		maxECDSAP521Length     = 139
+	)
+
+	switch kt {
+	case kms.ED25519Type:
+		return ed25519SignatureLength, ed25519SignatureLength, true
+	case kms.ECDSAP256Type:
+		return minECDSASignatureLength, maxECDSAP256Length, true
+	case kms.ECDSAP384Type:
+		return minECDSASignatureLength, maxECDSAP384Length, true
+	case kms.ECDSAP521Type:
+		return minECDSASignatureLength, maxECDSAP521Length, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// signatureLengthCheck returns the function checking the length of the signatures verified with the key keyID,
+// before they are verified by Tink, according to the key type stored in its metadata. Signatures of keys without
+// metadata are not checked.
+func (l *LocalKMS) signatureLengthCheck(keyID string) (func(sig []byte) error, error) {
+	metadata, err := l.getMetadata(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata == nil {
+		return func([]byte) error { return nil }, nil
+	}
+
+	minLength, maxLength, ok := signatureLengthRange(metadata.KeyType)
+	if !ok {
+		return func([]byte) error { return nil }, nil
+	}
+
+	return func(sig []byte) error {
+		if len(sig) < minLength || len(sig) > maxLength {
+			if minLength == maxLength {
+				return fmt.Errorf("%w: %d bytes, %s signatures are %d bytes", ErrInvalidSignatureLength, len(sig),
+					metadata.KeyType, minLength)
+			}
+
+			return fmt.Errorf("%w: %d bytes, %s signatures are %d to %d bytes", ErrInvalidSignatureLength, len(sig),
+				metadata.KeyType, minLength, maxLength)
+		}
+
+		return nil
+	}, nil
+}
+
+// checkSignatureLength checks the length of the signature sig verified with the key keyID (see signatureLengthCheck).
+func (l *LocalKMS) checkSignatureLength(keyID string, sig []byte) error {
+	check, err := l.signatureLengthCheck(keyID)
+	if err != nil {
+		return err
+	}
+
+	return check(sig)
+}
@@ -0,0 +1,116 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_VerifySignatureLength(t *testing.T) {
+	msg := []byte("message")
+
+	store := &mockstorage.MockStore{Store: map[string][]byte{}}
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewCustomMockStoreProvider(store),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	sign := func(t *testing.T, kt kms.KeyType) (string, []byte) {
+		keyID, _, err := kmsService.Create(kt)
+		require.NoError(t, err)
+
+		sig, err := kmsService.SignWithKey(keyID, msg)
+		require.NoError(t, err)
+
+		return keyID, sig
+	}
+
+	t.Run("test truncated signatures are rejected before verification", func(t *testing.T) {
+		for _, test := range []struct {
+			keyType kms.KeyType
+			err     string
+		}{
+			{kms.ED25519Type, "invalid signature length: 32 bytes, ED25519 signatures are 64 bytes"},
+			{kms.ECDSAP256Type, "invalid signature length: 4 bytes, ECDSAP256 signatures are 8 to 72 bytes"},
+			{kms.ECDSAP384Type, "invalid signature length: 4 bytes, ECDSAP384 signatures are 8 to 104 bytes"},
+		} {
+			keyID, sig := sign(t, test.keyType)
+
+			require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg), test.keyType)
+
+			truncated := sig[:len(sig)/2]
+			if test.keyType != kms.ED25519Type {
+				truncated = sig[:4]
+			}
+
+			err := kmsService.VerifyWithKey(keyID, truncated, msg)
+			require.True(t, errors.Is(err, ErrInvalidSignatureLength), test.keyType)
+			require.EqualError(t, err, "verify with key: "+test.err, test.keyType)
+		}
+	})
+
+	t.Run("test signatures too long are rejected", func(t *testing.T) {
+		keyID, sig := sign(t, kms.ECDSAP256Type)
+
+		err := kmsService.VerifyWithKey(keyID, append(sig, make([]byte, 80)...), msg)
+		require.True(t, errors.Is(err, ErrInvalidSignatureLength))
+
+		err = kmsService.VerifyWithKey(keyID, sig[:10], msg, WithHash(crypto.SHA384))
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrInvalidSignatureLength))
+
+		err = kmsService.VerifyWithKey(keyID, sig[:4], msg, WithHash(crypto.SHA384))
+		require.True(t, errors.Is(err, ErrInvalidSignatureLength))
+	})
+
+	t.Run("test truncated signatures in a batch", func(t *testing.T) {
+		keyID, sig := sign(t, kms.ED25519Type)
+
+		results, err := kmsService.VerifyBatch(keyID, []VerifyItem{
+			{Sig: sig, Msg: msg},
+			{Sig: sig[:63], Msg: msg},
+		})
+		require.NoError(t, err)
+		require.NoError(t, results[0])
+		require.True(t, errors.Is(results[1], ErrInvalidSignatureLength))
+		require.EqualError(t, results[1], "verify batch item 1: invalid signature length: 63 bytes, ED25519 "+
+			"signatures are 64 bytes")
+	})
+
+	t.Run("test signatures of keys without metadata are verified by Tink only", func(t *testing.T) {
+		keyID, sig := sign(t, kms.ED25519Type)
+
+		require.NoError(t, store.Delete(metadataKeyPrefix+keyID))
+
+		err := kmsService.VerifyWithKey(keyID, sig[:32], msg)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrInvalidSignatureLength))
+	})
+
+	t.Run("test metadata read error", func(t *testing.T) {
+		keyID, sig := sign(t, kms.ED25519Type)
+
+		store.Store[metadataKeyPrefix+keyID] = []byte("{")
+
+		err := kmsService.VerifyWithKey(keyID, sig, msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal key metadata")
+
+		_, err = kmsService.VerifyBatch(keyID, []VerifyItem{{Sig: sig, Msg: msg}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal key metadata")
+	})
+}
@@ -0,0 +1,287 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// ErrSignatureDomain is returned when signing or verifying with a key bound to another signature domain (see
+// WithDomain).
+var ErrSignatureDomain = errors.New("key bound to another signature domain")
+
+// SignWithKey signs msg with the signing key keyID.
+// The signer primitive of the key is pooled, so signing repeatedly with the same key does not read the keyset from
+// the store and extract the primitive each time. Pooled primitives are invalidated when their key is rotated.
+// ECDSA keys can sign with another hash function than their own (see WithHash) or deterministically (see
+// WithDeterministicSignature), the primitive is then not pooled.
+func (l *LocalKMS) SignWithKey(keyID string, msg []byte, opts ...SignOption) ([]byte, error) {
+	sig, err := l.signWithKey(keyID, msg, opts...)
+	l.audit(&AuditRecord{Operation: AuditOpSign, KeyID: keyID}, err)
+
+	return sig, err
+}
+
+func (l *LocalKMS) signWithKey(keyID string, msg []byte, opts ...SignOption) ([]byte, error) {
+	options := newSignOpts(opts)
+
+	msg, err := options.domainSeparated(msg)
+	if err != nil {
+		return nil, fmt.Errorf("sign with key: %w", err)
+	}
+
+	if err = l.bindSignatureDomain(keyID, options.domain); err != nil {
+		return nil, fmt.Errorf("sign with key: %w", err)
+	}
+
+	if options.hash != 0 || options.deterministic {
+		kh, err := l.getKeySet(keyID)
+		if err != nil {
+			return nil, fmt.Errorf("sign with key: %w", err)
+		}
+
+		sig, err := signECDSA(kh, options, msg)
+		if err != nil {
+			return nil, fmt.Errorf("sign with key: %w", err)
+		}
+
+		return sig, nil
+	}
+
+	signer, err := l.primitives.Signer(keyID, l.keySetLoader(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("sign with key: %w", err)
+	}
+
+	sig, err := signer.Sign(msg)
+	if err != nil {
+		return nil, fmt.Errorf("sign with key: %w", err)
+	}
+
+	return sig, nil
+}
+
+// VerifyWithKey verifies the signature sig of msg with the signing key keyID, it returns an error if the signature
+// is invalid. Like with SignWithKey, the verifier primitive of the key is pooled. Signatures made with the WithHash
+// or WithDomain options must be verified with the same options.
+func (l *LocalKMS) VerifyWithKey(keyID string, sig, msg []byte, opts ...SignOption) error {
+	err := l.verifyWithKey(keyID, sig, msg, opts...)
+	l.audit(&AuditRecord{Operation: AuditOpVerifySignature, KeyID: keyID}, err)
+
+	return err
+}
+
+func (l *LocalKMS) verifyWithKey(keyID string, sig, msg []byte, opts ...SignOption) error {
+	options := newSignOpts(opts)
+
+	if err := l.checkSignatureLength(keyID, sig); err != nil {
+		return fmt.Errorf("verify with key: %w", err)
+	}
+
+	msg, err := options.domainSeparated(msg)
+	if err != nil {
+		return fmt.Errorf("verify with key: %w", err)
+	}
+
+	if err = l.checkSignatureDomain(keyID, options.domain); err != nil {
+		return fmt.Errorf("verify with key: %w", err)
+	}
+
+	if options.hash != 0 {
+		kh, err := l.getKeySet(keyID)
+		if err != nil {
+			return fmt.Errorf("verify with key: %w", err)
+		}
+
+		err = verifyWithHash(kh, options.hash, sig, msg)
+		if err != nil {
+			return fmt.Errorf("verify with key: %w", err)
+		}
+
+		return nil
+	}
+
+	verifier, err := l.primitives.Verifier(keyID, l.keySetLoader(keyID))
+	if err != nil {
+		return fmt.Errorf("verify with key: %w", err)
+	}
+
+	err = verifier.Verify(sig, msg)
+	if err != nil {
+		return fmt.Errorf("verify with key: %w", err)
+	}
+
+	return nil
+}
+
+func (l *LocalKMS) keySetLoader(keyID string) func() (*keyset.Handle, error) {
+	return func() (*keyset.Handle, error) {
+		return l.getKeySet(keyID)
+	}
+}
+
+// WithDomain option signs or verifies a message in the signature domain domain, eg: the name of the protocol the
+// signature is made for, so a signature made for a protocol can't be reused for another one. The message is signed
+// prefixed with the domain and a null byte, the domain must not contain null bytes. Signatures made in a domain must
+// be verified in the same domain.
+// Messages signed without a domain are signed as is, so a signature made without a domain over "domain\x00msg" would
+// also be a valid signature of msg in the domain. The key is therefore bound to the domain of its first signature
+// made in a domain, or to the domain it was created with (see kms.WithSignatureDomain): it then signs and verifies
+// only in this domain, other domains and signatures without domain are rejected with ErrSignatureDomain. Binding the
+// key writes its metadata, the first signature in a domain fails with ErrPaused while the LocalKMS is paused.
+// Signatures made without a domain before the key is bound can't be rejected, keys signing in a domain should be
+// created with kms.WithSignatureDomain.
+func WithDomain(domain string) SignOption {
+	return func(opts *signOpts) {
+		opts.domain = domain
+	}
+}
+
+// bindSignatureDomain checks that the key keyID can sign in the signature domain domain, and binds the key to domain
+// if it is not bound to a domain yet. The key is bound atomically, a key can't be bound to two domains.
+func (l *LocalKMS) bindSignatureDomain(keyID, domain string) error {
+	if err := l.checkSignatureDomain(keyID, domain); err != nil || domain == "" {
+		return err
+	}
+
+	done, err := l.beginWrite()
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	for {
+		bytes, err := l.store.Get(metadataKeyPrefix + keyID)
+		if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+			return fmt.Errorf("get key metadata: %w", err)
+		}
+
+		metadata, err := l.unboundMetadata(keyID, bytes)
+		if err != nil {
+			return err
+		}
+
+		if metadata.SignatureDomain != "" {
+			return matchSignatureDomain(keyID, metadata.SignatureDomain, domain)
+		}
+
+		metadata.SignatureDomain = domain
+
+		bound, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("marshal key metadata: %w", err)
+		}
+
+		var expectedVersion []byte
+
+		if bytes != nil {
+			expectedVersion = storage.Version(bytes)
+		}
+
+		err = storage.PutIfMatch(l.store, metadataKeyPrefix+keyID, bound, expectedVersion)
+		if err == nil {
+			// the pooled domain of the key is stale
+			l.primitives.Invalidate(keyID)
+		}
+
+		if !errors.Is(err, storage.ErrVersionConflict) {
+			return err
+		}
+
+		// the metadata were updated concurrently, eg: the key was bound to another domain
+	}
+}
+
+// unboundMetadata returns the stored metadata bytes of the key keyID, or new metadata if the key has none (eg: keys
+// created before metadata were recorded).
+func (l *LocalKMS) unboundMetadata(keyID string, bytes []byte) (*keyMetadata, error) {
+	metadata := &keyMetadata{}
+
+	if bytes != nil {
+		if err := json.Unmarshal(bytes, metadata); err != nil {
+			return nil, fmt.Errorf("unmarshal key metadata: %w", err)
+		}
+
+		return metadata, nil
+	}
+
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.KeyType = keyTypeOf(kh)
+	metadata.Created = time.Now().UTC()
+
+	return metadata, nil
+}
+
+// checkSignatureDomain checks that the key keyID signs and verifies in the signature domain domain, if the key is
+// bound to a domain. The domain of the key is pooled with its primitives, so the metadata are not read on every
+// signature.
+func (l *LocalKMS) checkSignatureDomain(keyID, domain string) error {
+	bound, err := l.primitives.Domain(keyID, func() (string, error) {
+		metadata, err := l.getMetadata(keyID)
+		if err != nil || metadata == nil {
+			return "", err
+		}
+
+		return metadata.SignatureDomain, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if bound == "" {
+		return nil
+	}
+
+	return matchSignatureDomain(keyID, bound, domain)
+}
+
+func matchSignatureDomain(keyID, bound, domain string) error {
+	if domain != bound {
+		return fmt.Errorf("%w: key %s is bound to domain %q, not %q", ErrSignatureDomain, keyID, bound, domain)
+	}
+
+	return nil
+}
+
+// domainSeparated returns msg prefixed with the signature domain, if any.
+func (o *signOpts) domainSeparated(msg []byte) ([]byte, error) {
+	if o.domain == "" {
+		return msg, nil
+	}
+
+	if strings.IndexByte(o.domain, 0) >= 0 {
+		return nil, errors.New("signature domain contains a null byte")
+	}
+
+	separated := make([]byte, 0, len(o.domain)+1+len(msg))
+	separated = append(append(append(separated, o.domain...), 0), msg...)
+
+	return separated, nil
+}
+
+func newSignOpts(opts []SignOption) *signOpts {
+	options := &signOpts{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
@@ -0,0 +1,132 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestLocalKMS_SignWithKey(t *testing.T) {
+	msg := []byte("message")
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	t.Run("test sign and verify", func(t *testing.T) {
+		for _, kt := range []kms.KeyType{kms.ED25519Type, kms.ECDSAP256Type} {
+			keyID, kh, err := kmsService.Create(kt)
+			require.NoError(t, err)
+
+			sig, err := kmsService.SignWithKey(keyID, msg)
+			require.NoError(t, err)
+
+			require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg))
+
+			err = kmsService.VerifyWithKey(keyID, sig, []byte("other message"))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "verify with key")
+
+			// the signature is the one of the stored key
+			pubKH, err := kh.(*keyset.Handle).Public()
+			require.NoError(t, err)
+
+			verifier, err := signature.NewVerifier(pubKH)
+			require.NoError(t, err)
+			require.NoError(t, verifier.Verify(sig, msg))
+		}
+	})
+
+	t.Run("test rotation invalidates the pooled primitives", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		sig, err := kmsService.SignWithKey(keyID, msg)
+		require.NoError(t, err)
+		require.NoError(t, kmsService.VerifyWithKey(keyID, sig, msg))
+
+		newKeyID, _, err := kmsService.Rotate(kms.ED25519Type, keyID)
+		require.NoError(t, err)
+
+		// the rotated key was deleted, its pooled primitives must not be used anymore
+		_, err = kmsService.SignWithKey(keyID, msg)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		err = kmsService.VerifyWithKey(keyID, sig, msg)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		newSig, err := kmsService.SignWithKey(newKeyID, msg)
+		require.NoError(t, err)
+		require.NoError(t, kmsService.VerifyWithKey(newKeyID, newSig, msg))
+
+		// the rotated keyset still holds the previous key, signatures made before the rotation remain valid
+		require.NoError(t, kmsService.VerifyWithKey(newKeyID, sig, msg))
+	})
+
+	t.Run("test sign with a key which is not a signing key", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.AES128GCMType)
+		require.NoError(t, err)
+
+		_, err = kmsService.SignWithKey(keyID, msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create new signer")
+
+		err = kmsService.VerifyWithKey(keyID, []byte("sig"), msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create new verifier")
+	})
+}
+
+func BenchmarkLocalKMS_Sign(b *testing.B) {
+	msg := []byte("message")
+
+	kmsService, err := New("local-lock://custom/master/key/", &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: &noop.NoLock{},
+	})
+	require.NoError(b, err)
+
+	keyID, _, err := kmsService.Create(kms.ED25519Type)
+	require.NoError(b, err)
+
+	// signing with the key handle extracts the signer primitive on every signature
+	b.Run("key handle", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			kh, err := kmsService.Get(keyID)
+			require.NoError(b, err)
+
+			signer, err := signature.NewSigner(kh.(*keyset.Handle))
+			require.NoError(b, err)
+
+			_, err = signer.Sign(msg)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("pooled primitive", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			_, err := kmsService.SignWithKey(keyID, msg)
+			require.NoError(b, err)
+		}
+	})
+}
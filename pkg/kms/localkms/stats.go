@@ -0,0 +1,168 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// UnknownKeyType is the key type reported in the KMS statistics for keysets of unknown type (keysets without
+// metadata that could not be read).
+const UnknownKeyType = kms.KeyType("unknown")
+
+// KMSStats are the statistics of the keys stored by LocalKMS. They never include key material.
+type KMSStats struct {
+	// TotalKeys is the number of stored keysets
+	TotalKeys int `json:"totalKeys"`
+	// KeyTypes is the number of stored keysets per key type
+	KeyTypes map[kms.KeyType]int `json:"keyTypes"`
+	// Oldest is the creation time of the oldest keyset, if known
+	Oldest *time.Time `json:"oldest,omitempty"`
+	// Newest is the creation time of the newest keyset, if known
+	Newest *time.Time `json:"newest,omitempty"`
+	// FailedKeyIDs are the IDs of the keysets failing to be unwrapped
+	FailedKeyIDs []string `json:"failedKeyIDs,omitempty"`
+}
+
+// Stats returns the statistics of the keys stored by this KMS. Key types and creation times are read from the keys
+// metadata, for keysets without metadata the key type is found by reading the keyset and the creation time is
+// unknown. Every keyset is unwrapped to report the ones failing. The pooled keys not handed out yet are not counted
+// (see KeyPoolAvailable).
+func (l *LocalKMS) Stats() (*KMSStats, error) {
+	stats, err := l.stats()
+	l.audit(&AuditRecord{Operation: AuditOpStats}, err)
+
+	return stats, err
+}
+
+func (l *LocalKMS) stats() (*KMSStats, error) {
+	keyIDs, err := l.issuedKeySetIDs()
+	if err != nil {
+		return nil, fmt.Errorf("kms stats: %w", err)
+	}
+
+	stats := &KMSStats{
+		TotalKeys: len(keyIDs),
+		KeyTypes:  make(map[kms.KeyType]int),
+	}
+
+	for _, keyID := range keyIDs {
+		// metadata errors are not fatal, the key type is then read from the keyset
+		metadata, _ := l.getMetadata(keyID) // nolint:errcheck
+
+		kh, err := l.getKeySet(keyID)
+		if err != nil {
+			stats.FailedKeyIDs = append(stats.FailedKeyIDs, keyID)
+		}
+
+		kt := UnknownKeyType
+
+		switch {
+		case metadata != nil:
+			kt = metadata.KeyType
+
+			stats.addCreated(metadata.Created)
+		case kh != nil:
+			if t := keyTypeOf(kh); t != "" {
+				kt = t
+			}
+		}
+
+		stats.KeyTypes[kt]++
+	}
+
+	return stats, nil
+}
+
+// ListByKeyType returns the IDs of the current keys of type kt stored by this KMS, kt can be an alias of a key type
+// (see WithKeyTypeAliases). Keysets retained by a rotation (see kms.WithRetainPrevious) and pooled keys not handed out
+// yet (see WithKeyPool) are not listed.
+func (l *LocalKMS) ListByKeyType(kt kms.KeyType) ([]string, error) {
+	keyIDs, err := l.issuedKeySetIDs()
+	if err != nil {
+		return nil, fmt.Errorf("list keys by type: %w", err)
+	}
+
+	kt = l.resolveKeyType(kt)
+
+	var matching []string
+
+	for _, keyID := range keyIDs {
+		if l.storedKeyType(keyID) != kt {
+			continue
+		}
+
+		rotated, err := l.isRotated(keyID)
+		if err != nil {
+			return nil, fmt.Errorf("list keys by type: %w", err)
+		}
+
+		if !rotated {
+			matching = append(matching, keyID)
+		}
+	}
+
+	return matching, nil
+}
+
+// issuedKeySetIDs returns the IDs of the keysets stored under the master key of this KMS, except the pooled keys not
+// handed out yet.
+func (l *LocalKMS) issuedKeySetIDs() ([]string, error) {
+	keyIDs, err := l.keySetIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	pooled, err := l.allPooledKeyIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	issued := keyIDs[:0]
+
+	for _, keyID := range keyIDs {
+		if !pooled[keyID] {
+			issued = append(issued, keyID)
+		}
+	}
+
+	return issued, nil
+}
+
+// storedKeyType returns the key type of the keyset keyID from its metadata, or from the keyset if it has no
+// metadata. It returns UnknownKeyType if the key type can't be found.
+func (l *LocalKMS) storedKeyType(keyID string) kms.KeyType {
+	// metadata errors are not fatal, the key type is then read from the keyset
+	metadata, _ := l.getMetadata(keyID) // nolint:errcheck
+	if metadata != nil {
+		return metadata.KeyType
+	}
+
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return UnknownKeyType
+	}
+
+	if kt := keyTypeOf(kh); kt != "" {
+		return kt
+	}
+
+	return UnknownKeyType
+}
+
+func (s *KMSStats) addCreated(created time.Time) {
+	if s.Oldest == nil || created.Before(*s.Oldest) {
+		s.Oldest = &created
+	}
+
+	if s.Newest == nil || created.After(*s.Newest) {
+		s.Newest = &created
+	}
+}
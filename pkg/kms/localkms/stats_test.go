@@ -0,0 +1,159 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_Stats(t *testing.T) {
+	newKMS := func(t *testing.T, store *mockstorage.MockStore) *LocalKMS {
+		kmsService, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: createMasterKeyAndSecretLock(t),
+		})
+		require.NoError(t, err)
+
+		return kmsService
+	}
+
+	t.Run("test stats of a mixed store", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		kmsService := newKMS(t, store)
+
+		for _, kt := range []kms.KeyType{kms.ED25519Type, kms.ED25519Type, kms.AES256GCMType, kms.AES128GCMType,
+			kms.ECDSAP256Type, kms.HMACSHA256Tag256Type} {
+			_, _, err := kmsService.Create(kt)
+			require.NoError(t, err)
+		}
+
+		// rotated keys are counted once
+		keyID, _, err := kmsService.Create(kms.ChaCha20Poly1305Type)
+		require.NoError(t, err)
+
+		_, _, err = kmsService.Rotate(kms.ChaCha20Poly1305Type, keyID)
+		require.NoError(t, err)
+
+		// keysets without metadata have their type read from the keyset
+		keyID, _, err = kmsService.Create(kms.ECDSAP384Type)
+		require.NoError(t, err)
+		delete(store.Store, metadataKeyPrefix+keyID)
+
+		keyID, _, err = kmsService.Create(kms.AES256GCMNoPrefixType)
+		require.NoError(t, err)
+		delete(store.Store, metadataKeyPrefix+keyID)
+
+		// corrupted keysets are reported, with their type if known
+		corruptedKeyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+		store.Store[corruptedKeyID] = []byte("corrupted keyset")
+
+		unknownKeyID := testMasterKeyURI + "/unknown"
+		store.Store[unknownKeyID] = []byte("corrupted keyset without metadata")
+
+		stats, err := kmsService.Stats()
+		require.NoError(t, err)
+
+		require.Equal(t, 11, stats.TotalKeys)
+		require.Equal(t, map[kms.KeyType]int{
+			kms.ED25519Type:           3,
+			kms.AES256GCMType:         1,
+			kms.AES128GCMType:         1,
+			kms.ECDSAP256Type:         1,
+			kms.HMACSHA256Tag256Type:  1,
+			kms.ChaCha20Poly1305Type:  1,
+			kms.ECDSAP384Type:         1,
+			kms.AES256GCMNoPrefixType: 1,
+			UnknownKeyType:            1,
+		}, stats.KeyTypes)
+		require.ElementsMatch(t, []string{corruptedKeyID, unknownKeyID}, stats.FailedKeyIDs)
+
+		require.NotNil(t, stats.Oldest)
+		require.NotNil(t, stats.Newest)
+		require.False(t, stats.Newest.Before(*stats.Oldest))
+	})
+
+	t.Run("test stats of an empty store", func(t *testing.T) {
+		stats, err := newKMS(t, &mockstorage.MockStore{Store: map[string][]byte{}}).Stats()
+		require.NoError(t, err)
+		require.Zero(t, stats.TotalKeys)
+		require.Empty(t, stats.KeyTypes)
+		require.Nil(t, stats.Oldest)
+		require.Nil(t, stats.Newest)
+		require.Empty(t, stats.FailedKeyIDs)
+	})
+
+	t.Run("test stats with invalid metadata", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		kmsService := newKMS(t, store)
+
+		keyID, _, err := kmsService.Create(kms.ECDSAP521Type)
+		require.NoError(t, err)
+		store.Store[metadataKeyPrefix+keyID] = []byte("invalid")
+
+		stats, err := kmsService.Stats()
+		require.NoError(t, err)
+		require.Equal(t, map[kms.KeyType]int{kms.ECDSAP521Type: 1}, stats.KeyTypes)
+		require.Nil(t, stats.Oldest)
+	})
+
+	t.Run("test store iterator error", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{
+			Store:  map[string][]byte{},
+			ErrItr: errors.New("iterator error"),
+		})
+
+		stats, err := kmsService.Stats()
+		require.EqualError(t, err, "kms stats: iterator error")
+		require.Nil(t, stats)
+	})
+}
+
+func TestLocalKMS_ListByKeyType(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	}, WithKeyTypeAliases(map[kms.KeyType]kms.KeyType{"signing": kms.ED25519Type}))
+	require.NoError(t, err)
+
+	var ed25519IDs []string
+
+	for _, kt := range []kms.KeyType{kms.ED25519Type, kms.AES256GCMType, kms.ED25519Type, kms.ECDSAP256Type} {
+		keyID, _, err := kmsService.Create(kt)
+		require.NoError(t, err)
+
+		if kt == kms.ED25519Type {
+			ed25519IDs = append(ed25519IDs, keyID)
+		}
+	}
+
+	keyIDs, err := kmsService.ListByKeyType(kms.ED25519Type)
+	require.NoError(t, err)
+	require.ElementsMatch(t, ed25519IDs, keyIDs)
+
+	keyIDs, err = kmsService.ListByKeyType("signing")
+	require.NoError(t, err)
+	require.ElementsMatch(t, ed25519IDs, keyIDs)
+
+	keyIDs, err = kmsService.ListByKeyType(kms.ChaCha20Poly1305Type)
+	require.NoError(t, err)
+	require.Empty(t, keyIDs)
+
+	// a retained key is not listed anymore, the key rotated from it is
+	newID, _, err := kmsService.RotateWithOptions(kms.ED25519Type, ed25519IDs[0], kms.WithRetainPrevious())
+	require.NoError(t, err)
+
+	keyIDs, err = kmsService.ListByKeyType(kms.ED25519Type)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{newID, ed25519IDs[1]}, keyIDs)
+}
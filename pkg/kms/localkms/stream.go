@@ -0,0 +1,78 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/tink/go/streamingaead"
+	"github.com/google/tink/go/tink"
+)
+
+// EncryptStream returns a writer encrypting everything written to it into w, using the streaming AEAD key keyID
+// (one of the AES128GCMHKDF4KB, AES256GCMHKDF4KB or AES256GCMHKDF1MB key types) and aad as associated data.
+// The plaintext is encrypted segment by segment so payloads of any size are encrypted with bounded memory.
+// The returned writer must be closed to write the last segment.
+func (l *LocalKMS) EncryptStream(keyID string, w io.Writer, aad []byte) (io.WriteCloser, error) {
+	encWriter, err := l.encryptStream(keyID, w, aad)
+	l.audit(&AuditRecord{Operation: AuditOpEncryptStream, KeyID: keyID}, err)
+
+	return encWriter, err
+}
+
+func (l *LocalKMS) encryptStream(keyID string, w io.Writer, aad []byte) (io.WriteCloser, error) {
+	p, err := l.streamingAEAD(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	encWriter, err := p.NewEncryptingWriter(w, aad)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt stream: %w", err)
+	}
+
+	return encWriter, nil
+}
+
+// DecryptStream returns a reader decrypting the ciphertext read from r, encrypted by EncryptStream with the
+// streaming AEAD key keyID and the associated data aad. The ciphertext is decrypted and authenticated segment by
+// segment, reads fail as soon as a segment is not authentic.
+func (l *LocalKMS) DecryptStream(keyID string, r io.Reader, aad []byte) (io.Reader, error) {
+	decReader, err := l.decryptStream(keyID, r, aad)
+	l.audit(&AuditRecord{Operation: AuditOpDecryptStream, KeyID: keyID}, err)
+
+	return decReader, err
+}
+
+func (l *LocalKMS) decryptStream(keyID string, r io.Reader, aad []byte) (io.Reader, error) {
+	p, err := l.streamingAEAD(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	decReader, err := p.NewDecryptingReader(r, aad)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt stream: %w", err)
+	}
+
+	return decReader, nil
+}
+
+func (l *LocalKMS) streamingAEAD(keyID string) (tink.StreamingAEAD, error) {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := streamingaead.New(kh)
+	if err != nil {
+		return nil, fmt.Errorf("key %s does not support streaming AEAD: %w", keyID, err)
+	}
+
+	return p, nil
+}
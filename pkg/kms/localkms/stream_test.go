@@ -0,0 +1,119 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_Stream(t *testing.T) {
+	const payloadSize = 8 << 20
+
+	aad := []byte("stream aad")
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	for _, kt := range []kms.KeyType{kms.AES128GCMHKDF4KBType, kms.AES256GCMHKDF4KBType, kms.AES256GCMHKDF1MBType} {
+		kt := kt
+
+		t.Run("test encrypt and decrypt large payload through pipes with "+string(kt), func(t *testing.T) {
+			keyID, _, err := kmsService.Create(kt)
+			require.NoError(t, err)
+
+			metadata, err := kmsService.getMetadata(keyID)
+			require.NoError(t, err)
+			require.Equal(t, kt, metadata.KeyType)
+
+			plainHash := sha256.New()
+			plaintext := io.TeeReader(io.LimitReader(rand.New(rand.NewSource(1)), payloadSize), plainHash)
+
+			ciphertextReader, ciphertextWriter := io.Pipe()
+
+			go func() {
+				encWriter, e := kmsService.EncryptStream(keyID, ciphertextWriter, aad)
+				if e != nil {
+					ciphertextWriter.CloseWithError(e)
+
+					return
+				}
+
+				if _, e = io.Copy(encWriter, plaintext); e != nil {
+					ciphertextWriter.CloseWithError(e)
+
+					return
+				}
+
+				ciphertextWriter.CloseWithError(encWriter.Close())
+			}()
+
+			decReader, err := kmsService.DecryptStream(keyID, ciphertextReader, aad)
+			require.NoError(t, err)
+
+			decryptedHash := sha256.New()
+
+			n, err := io.Copy(decryptedHash, decReader)
+			require.NoError(t, err)
+			require.EqualValues(t, payloadSize, n)
+			require.Equal(t, plainHash.Sum(nil), decryptedHash.Sum(nil))
+		})
+	}
+
+	t.Run("test decrypt with wrong aad fails", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.AES128GCMHKDF4KBType)
+		require.NoError(t, err)
+
+		ciphertext := &bytes.Buffer{}
+
+		encWriter, err := kmsService.EncryptStream(keyID, ciphertext, aad)
+		require.NoError(t, err)
+
+		_, err = encWriter.Write([]byte("secret payload"))
+		require.NoError(t, err)
+		require.NoError(t, encWriter.Close())
+
+		decReader, err := kmsService.DecryptStream(keyID, ciphertext, []byte("other aad"))
+		require.NoError(t, err)
+
+		_, err = ioutil.ReadAll(decReader)
+		require.Error(t, err)
+	})
+
+	t.Run("test key not supporting streaming AEAD", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		_, err = kmsService.EncryptStream(keyID, &bytes.Buffer{}, aad)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not support streaming AEAD")
+
+		_, err = kmsService.DecryptStream(keyID, &bytes.Buffer{}, aad)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not support streaming AEAD")
+	})
+
+	t.Run("test unknown key", func(t *testing.T) {
+		_, err := kmsService.EncryptStream("unknown", &bytes.Buffer{}, aad)
+		require.Error(t, err)
+
+		_, err = kmsService.DecryptStream("unknown", &bytes.Buffer{}, aad)
+		require.Error(t, err)
+	})
+}
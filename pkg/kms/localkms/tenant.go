@@ -0,0 +1,119 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/tink/go/aead"
+)
+
+// ErrOtherTenant is returned when reading a keyset stored with the master key of another tenant.
+var ErrOtherTenant = errors.New("keyset of another tenant")
+
+// MasterKeyURIResolver resolves the master key URI of the tenant of ctx, the context of a request.
+type MasterKeyURIResolver func(ctx context.Context) (string, error)
+
+// WithMasterKeyURIResolver option enables the multi-tenant mode of the KMS: the master key URI, and thus the key wrap
+// of the keysets, is resolved with resolver from the context of the request passed to WithContext. The keysets
+// stored by a tenant are prefixed with, and wrapped by, its master key URI; the keysets of the other tenants can't
+// be read. The LocalKMS not bound to a request context with WithContext uses the master key URI given to New.
+// The key pools (see WithKeyPool) are not supported in multi-tenant mode.
+func WithMasterKeyURIResolver(resolver MasterKeyURIResolver) Option {
+	return func(opts *LocalKMS) {
+		opts.keyURIResolver = resolver
+		opts.tenants = &tenantKeyWraps{envAEADs: make(map[string]map[KeyWrap]*aead.KMSEnvelopeAEAD)}
+	}
+}
+
+// tenantKeyWraps caches the AEADs of the key wraps of the tenants, shared by the copies of the LocalKMS.
+type tenantKeyWraps struct {
+	mutex    sync.Mutex
+	envAEADs map[string]map[KeyWrap]*aead.KMSEnvelopeAEAD
+}
+
+// bindTenant binds the KMS to the master key URI of the tenant of its context, the resolution error is returned by
+// the operations reading or storing keysets.
+func (l *LocalKMS) bindTenant() {
+	keyURI, err := l.keyURIResolver(l.ctx)
+	if err != nil {
+		l.tenantErr = fmt.Errorf("resolve master key URI: %w", err)
+		return
+	}
+
+	wrap := KeyWrap{Algorithm: l.keyWrap.Algorithm, MasterKeyURI: keyURI}
+
+	envAEADs, err := l.tenantKeyWrapAEADs(wrap)
+	if err != nil {
+		l.tenantErr = fmt.Errorf("tenant master key URI: %w", err)
+		return
+	}
+
+	l.masterKeyURI = keyURI
+	l.keyWrap = wrap
+	l.keyWrapAEADs = envAEADs
+	l.masterKeyEnvAEAD = envAEADs[wrap]
+	l.tenantErr = nil
+}
+
+func (l *LocalKMS) tenantKeyWrapAEADs(wrap KeyWrap) (map[KeyWrap]*aead.KMSEnvelopeAEAD, error) {
+	l.tenants.mutex.Lock()
+	defer l.tenants.mutex.Unlock()
+
+	if envAEADs, ok := l.tenants.envAEADs[wrap.MasterKeyURI]; ok {
+		return envAEADs, nil
+	}
+
+	// the previous key wraps are not shared with the tenants
+	envAEADs, err := l.newKeyWrapAEADs(masterKeyWraps(wrap, wrap.MasterKeyURI))
+	if err != nil {
+		return nil, err
+	}
+
+	l.tenants.envAEADs[wrap.MasterKeyURI] = envAEADs
+
+	return envAEADs, nil
+}
+
+// checkTenantKeySet checks that the keyset keyID, prefixed with the master key URI it was stored with, is a keyset
+// of the tenant the KMS is bound to.
+func (l *LocalKMS) checkTenantKeySet(keyID string) error {
+	if l.keyURIResolver == nil {
+		return nil
+	}
+
+	if l.tenantErr != nil {
+		return l.tenantErr
+	}
+
+	if !strings.HasPrefix(keyID, l.keySetIDPrefix()) {
+		return ErrOtherTenant
+	}
+
+	return nil
+}
+
+// checkTenantKeyWrap checks that the keysets wrapped with wrap are keysets of the tenant the KMS is bound to.
+func (l *LocalKMS) checkTenantKeyWrap(wrap *KeyWrap) error {
+	if l.keyURIResolver == nil {
+		return nil
+	}
+
+	if l.tenantErr != nil {
+		return l.tenantErr
+	}
+
+	if wrap.MasterKeyURI != l.keyWrap.MasterKeyURI {
+		return fmt.Errorf("%w: wrapped with %s", ErrOtherTenant, wrap.MasterKeyURI)
+	}
+
+	return nil
+}
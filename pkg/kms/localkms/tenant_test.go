@@ -0,0 +1,144 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+type tenantKey struct{}
+
+func TestLocalKMS_MasterKeyURIResolver(t *testing.T) {
+	const (
+		tenantA = keywrapper.LocalKeyURIPrefix + "tenant/a/"
+		tenantB = keywrapper.LocalKeyURIPrefix + "tenant/b/"
+	)
+
+	store := &mockstorage.MockStore{Store: map[string][]byte{}}
+	secretLock := &keyURIRecorder{Service: createMasterKeyAndSecretLock(t)}
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewCustomMockStoreProvider(store),
+		secretLock: secretLock,
+	}, WithMasterKeyURIResolver(func(ctx context.Context) (string, error) {
+		tenant, ok := ctx.Value(tenantKey{}).(string)
+		if !ok {
+			return "", errors.New("no tenant")
+		}
+
+		return tenant, nil
+	}))
+	require.NoError(t, err)
+
+	forTenant := func(tenant string) *LocalKMS {
+		return kmsService.WithContext(context.WithValue(context.Background(), tenantKey{}, tenant))
+	}
+
+	keyIDA, _, err := forTenant(tenantA).Create(kms.ED25519Type)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(keyIDA, tenantA))
+
+	var wrapped wrappedKeySet
+	require.NoError(t, json.Unmarshal(store.Store[keyIDA], &wrapped))
+	require.Equal(t, &KeyWrap{Algorithm: KeyWrapAES256GCM, MasterKeyURI: tenantA}, wrapped.Wrap)
+
+	keyIDB, _, err := forTenant(tenantB).Create(kms.ED25519Type)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(keyIDB, tenantB))
+
+	t.Run("test tenants read their keysets", func(t *testing.T) {
+		secretLock.reset()
+
+		_, err := forTenant(tenantA).Get(keyIDA)
+		require.NoError(t, err)
+		require.Equal(t, []string{"tenant/a/"}, secretLock.reset())
+
+		_, err = forTenant(tenantB).Get(keyIDB)
+		require.NoError(t, err)
+		require.Equal(t, []string{"tenant/b/"}, secretLock.reset())
+
+		// the key wraps of a tenant are created once
+		require.Len(t, kmsService.tenants.envAEADs, 2)
+	})
+
+	t.Run("test tenants can't read the keysets of the other tenants", func(t *testing.T) {
+		_, err := forTenant(tenantB).Get(keyIDA)
+		require.True(t, errors.Is(err, ErrOtherTenant))
+
+		_, err = forTenant(tenantA).Get(keyIDB)
+		require.True(t, errors.Is(err, ErrOtherTenant))
+
+		_, err = kmsService.Get(keyIDA)
+		require.True(t, errors.Is(err, ErrOtherTenant))
+
+		// a keyset of tenant A copied under an ID of tenant B
+		copyID := tenantB + "copy"
+		store.Store[copyID] = store.Store[keyIDA]
+
+		_, err = forTenant(tenantB).Get(copyID)
+		require.True(t, errors.Is(err, ErrOtherTenant))
+		require.Contains(t, err.Error(), "wrapped with "+tenantA)
+	})
+
+	t.Run("test KMS not bound to a tenant uses its master key URI", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(keyID, testMasterKeyURI))
+
+		_, err = kmsService.Get(keyID)
+		require.NoError(t, err)
+
+		_, err = forTenant(tenantA).Get(keyID)
+		require.True(t, errors.Is(err, ErrOtherTenant))
+	})
+
+	t.Run("test master key URI resolution errors", func(t *testing.T) {
+		noTenant := kmsService.WithContext(context.Background())
+
+		_, _, err := noTenant.Create(kms.ED25519Type)
+		require.EqualError(t, err, "resolve master key URI: no tenant")
+
+		_, err = noTenant.Get(keyIDA)
+		require.EqualError(t, err, "keyset "+keyIDA+": resolve master key URI: no tenant")
+
+		_, _, err = forTenant("remote://tenant/c").Create(kms.ED25519Type)
+		require.True(t, errors.Is(err, ErrUnsupportedMasterKeyURI))
+	})
+
+	t.Run("test tenant URIs prefixing other tenant URIs", func(t *testing.T) {
+		const (
+			tenant1  = keywrapper.LocalKeyURIPrefix + "tenant1"
+			tenant10 = keywrapper.LocalKeyURIPrefix + "tenant10"
+		)
+
+		keyID10, _, err := forTenant(tenant10).Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		require.True(t, errors.Is(forTenant(tenant1).checkTenantKeySet(keyID10), ErrOtherTenant))
+		require.NoError(t, forTenant(tenant10).checkTenantKeySet(keyID10))
+	})
+
+	t.Run("test key pools are not supported", func(t *testing.T) {
+		_, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: secretLock,
+		}, WithMasterKeyURIResolver(func(ctx context.Context) (string, error) {
+			return tenantA, nil
+		}), WithKeyPool(kms.ED25519Type, KeyPoolConfig{Size: 1}))
+		require.EqualError(t, err, "failed to create local kms: key pools are not supported in multi-tenant mode")
+	})
+}
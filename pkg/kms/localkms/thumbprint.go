@@ -0,0 +1,84 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// KeyThumbprint returns the JWK thumbprint (RFC 7638) of the public key of the asymmetric key keyID: the unpadded
+// base64url encoding of the SHA-256 hash of its JWK required members. Ed25519 keys are OKP JWKs (RFC 8037), ECDSA and
+// ECIES keys are EC JWKs. Thumbprints are commonly used as DIDComm key IDs, eg: in the recipientKeys of a DID service.
+func (l *LocalKMS) KeyThumbprint(keyID string) (string, error) {
+	thumbprint, err := l.keyThumbprint(keyID)
+	l.audit(&AuditRecord{Operation: AuditOpKeyThumbprint, KeyID: keyID}, err)
+
+	if err != nil {
+		return "", fmt.Errorf("key thumbprint: %w", err)
+	}
+
+	return thumbprint, nil
+}
+
+func (l *LocalKMS) keyThumbprint(keyID string) (string, error) {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	kt := keyTypeOf(kh)
+	if !isAsymmetricKeyType(kt) {
+		return "", errors.New("not an asymmetric key")
+	}
+
+	pubKeyBytes, err := publicKeyBytes(kh)
+	if err != nil {
+		return "", err
+	}
+
+	return jwkThumbprint(kt, pubKeyBytes)
+}
+
+// jwkThumbprint returns the JWK thumbprint of the public key pubKeyBytes, as exported by ExportPubKeyBytes, of key
+// type kt.
+func jwkThumbprint(kt kms.KeyType, pubKeyBytes []byte) (string, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	var jwk string
+
+	// the required members of the JWK, in lexicographic order and without whitespace
+	switch kt {
+	case kms.ED25519Type:
+		jwk = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, b64(pubKeyBytes))
+	case kms.ECDSAP256Type, kms.ECDSAP384Type, kms.ECDSAP521Type, kms.ECIESHKDFAES128GCMType:
+		curve := elliptic.P256()
+		if kt != kms.ECIESHKDFAES128GCMType {
+			curve = ellipticCurve(kt)
+		}
+
+		// the coordinates of the uncompressed point are padded to the byte size of the curve, as required in JWKs
+		size := (curve.Params().BitSize + 7) / 8
+		if len(pubKeyBytes) != 1+2*size {
+			return "", fmt.Errorf("invalid %s public key", kt)
+		}
+
+		jwk = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, curve.Params().Name,
+			b64(pubKeyBytes[1:1+size]), b64(pubKeyBytes[1+size:]))
+	default:
+		return "", fmt.Errorf("key type %s has no JWK thumbprint", kt)
+	}
+
+	h := sha256.Sum256([]byte(jwk))
+
+	return b64(h[:]), nil
+}
@@ -0,0 +1,107 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestJWKThumbprint(t *testing.T) {
+	// RFC 8037 appendix A.3
+	pubKey, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	require.NoError(t, err)
+
+	thumbprint, err := jwkThumbprint(kms.ED25519Type, pubKey)
+	require.NoError(t, err)
+	require.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", thumbprint)
+
+	_, err = jwkThumbprint(kms.ECDSAP256Type, pubKey)
+	require.EqualError(t, err, "invalid ECDSAP256 public key")
+
+	_, err = jwkThumbprint(kms.AES256GCMType, pubKey)
+	require.EqualError(t, err, "key type AES256GCM has no JWK thumbprint")
+}
+
+func TestLocalKMS_KeyThumbprint(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	// thumbprint of the JWK members, json.Marshal sorts the members and adds no whitespace
+	expectedThumbprint := func(t *testing.T, jwk map[string]string) string {
+		b, err := json.Marshal(jwk)
+		require.NoError(t, err)
+
+		h := sha256.Sum256(b)
+
+		return base64.RawURLEncoding.EncodeToString(h[:])
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	t.Run("test Ed25519 key thumbprint", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		pubKey, err := kmsService.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		thumbprint, err := kmsService.KeyThumbprint(keyID)
+		require.NoError(t, err)
+		require.Equal(t, expectedThumbprint(t, map[string]string{"kty": "OKP", "crv": "Ed25519", "x": b64(pubKey)}),
+			thumbprint)
+	})
+
+	t.Run("test EC key thumbprints", func(t *testing.T) {
+		curves := map[kms.KeyType]string{
+			kms.ECDSAP256Type:          "P-256",
+			kms.ECDSAP384Type:          "P-384",
+			kms.ECDSAP521Type:          "P-521",
+			kms.ECIESHKDFAES128GCMType: "P-256",
+		}
+
+		for kt, crv := range curves {
+			keyID, _, err := kmsService.Create(kt)
+			require.NoError(t, err, kt)
+
+			pubKey, err := kmsService.ExportPubKeyBytes(keyID)
+			require.NoError(t, err, kt)
+
+			size := (len(pubKey) - 1) / 2
+
+			thumbprint, err := kmsService.KeyThumbprint(keyID)
+			require.NoError(t, err, kt)
+			require.Equal(t, expectedThumbprint(t, map[string]string{
+				"kty": "EC",
+				"crv": crv,
+				"x":   b64(pubKey[1 : 1+size]),
+				"y":   b64(pubKey[1+size:]),
+			}), thumbprint, kt)
+		}
+	})
+
+	t.Run("test key thumbprint errors", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		_, err = kmsService.KeyThumbprint(keyID)
+		require.EqualError(t, err, "key thumbprint: not an asymmetric key")
+
+		_, err = kmsService.KeyThumbprint("unknown")
+		require.Error(t, err)
+	})
+}
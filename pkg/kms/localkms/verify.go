@@ -0,0 +1,92 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verify checks the integrity of the keystore: every keyset stored under the master key of this KMS is read,
+// decrypted and its primitives are constructed. It returns the IDs of the keysets that failed these checks,
+// without aborting on the first failure.
+// It returns an error only if the keystore could not be iterated.
+func (l *LocalKMS) Verify() ([]string, error) {
+	failed, err := l.verify()
+	l.audit(&AuditRecord{Operation: AuditOpVerify}, err)
+
+	return failed, err
+}
+
+func (l *LocalKMS) verify() ([]string, error) {
+	keyIDs, err := l.keySetIDs()
+	if err != nil {
+		return nil, fmt.Errorf("verify keystore: %w", err)
+	}
+
+	var failed []string
+
+	for _, keyID := range keyIDs {
+		if err := l.verifyKeySet(keyID); err != nil {
+			failed = append(failed, keyID)
+		}
+	}
+
+	return failed, nil
+}
+
+// CanUnwrap checks that the keyset keyID can be read from the store and unwrapped with its key wrap (see
+// WithKeyWrap), eg: to verify keysets after a storage migration. The keyset handle is discarded, it returns the
+// storage or decryption error if the keyset can't be unwrapped.
+func (l *LocalKMS) CanUnwrap(keyID string) error {
+	_, err := l.getKeySet(keyID)
+	l.audit(&AuditRecord{Operation: AuditOpCanUnwrap, KeyID: keyID}, err)
+
+	return err
+}
+
+func (l *LocalKMS) verifyKeySet(keyID string) error {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return err
+	}
+
+	_, err = kh.Primitives()
+
+	return err
+}
+
+// keySetIDs returns the IDs of all the keysets stored under the master key of this KMS.
+func (l *LocalKMS) keySetIDs() ([]string, error) {
+	prefix := l.keySetIDPrefix()
+
+	itr := l.store.Iterator(prefix, prefix+"~")
+	defer itr.Release()
+
+	var keyIDs []string
+
+	for itr.Next() {
+		keyIDs = append(keyIDs, string(itr.Key()))
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, err
+	}
+
+	return keyIDs, nil
+}
+
+// keySetIDPrefix returns the prefix of the IDs of the keysets stored under the master key of this KMS.
+func (l *LocalKMS) keySetIDPrefix() string {
+	// keyset IDs are prefixed with the master key URI (see storeWriter)
+	prefix := l.masterKeyURI
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return prefix
+}
@@ -0,0 +1,94 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"fmt"
+)
+
+// VerifyItem is a signature and the message it signs, verified by VerifyBatch.
+type VerifyItem struct {
+	Sig []byte
+	Msg []byte
+}
+
+// VerifyBatch verifies the signatures of items with the signing key keyID and returns the result of each item: the
+// error of item i is nil if its signature is valid. The keyset is read and the verifier primitive is extracted once
+// for the batch. An error is returned, and no item is verified, if the key can't be used to verify signatures.
+// Like with VerifyWithKey, signatures made with the WithHash or WithDomain options must be verified with the same
+// options. The Go standard library has no Ed25519 batch verification, the signatures are verified one by one.
+func (l *LocalKMS) VerifyBatch(keyID string, items []VerifyItem, opts ...SignOption) ([]error, error) {
+	results, err := l.verifyBatch(keyID, items, opts...)
+	l.audit(&AuditRecord{Operation: AuditOpVerifyBatch, KeyID: keyID}, err)
+
+	return results, err
+}
+
+func (l *LocalKMS) verifyBatch(keyID string, items []VerifyItem, opts ...SignOption) ([]error, error) {
+	options := newSignOpts(opts)
+
+	// the domain prefix of the messages, if any
+	prefix, err := options.domainSeparated(nil)
+	if err != nil {
+		return nil, fmt.Errorf("verify batch: %w", err)
+	}
+
+	if err = l.checkSignatureDomain(keyID, options.domain); err != nil {
+		return nil, fmt.Errorf("verify batch: %w", err)
+	}
+
+	verify, err := l.batchVerifier(keyID, options)
+	if err != nil {
+		return nil, fmt.Errorf("verify batch: %w", err)
+	}
+
+	checkLength, err := l.signatureLengthCheck(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("verify batch: %w", err)
+	}
+
+	results := make([]error, len(items))
+
+	for i, item := range items {
+		err = checkLength(item.Sig)
+		if err == nil {
+			msg := item.Msg
+			if len(prefix) > 0 {
+				msg = append(prefix[:len(prefix):len(prefix)], item.Msg...)
+			}
+
+			err = verify(item.Sig, msg)
+		}
+
+		if err != nil {
+			results[i] = fmt.Errorf("verify batch item %d: %w", i, err)
+		}
+	}
+
+	return results, nil
+}
+
+// batchVerifier returns the function verifying the signatures of the batch with the key keyID.
+func (l *LocalKMS) batchVerifier(keyID string, options *signOpts) (func(sig, msg []byte) error, error) {
+	if options.hash != 0 {
+		kh, err := l.getKeySet(keyID)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(sig, msg []byte) error {
+			return verifyWithHash(kh, options.hash, sig, msg)
+		}, nil
+	}
+
+	verifier, err := l.primitives.Verifier(keyID, l.keySetLoader(keyID))
+	if err != nil {
+		return nil, err
+	}
+
+	return verifier.Verify, nil
+}
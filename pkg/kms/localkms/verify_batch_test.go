@@ -0,0 +1,172 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+func TestLocalKMS_VerifyBatch(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	signItems := func(t *testing.T, keyID string, n int, opts ...SignOption) []VerifyItem {
+		items := make([]VerifyItem, n)
+
+		for i := range items {
+			items[i].Msg = []byte(fmt.Sprintf("message %d", i))
+
+			items[i].Sig, err = kmsService.SignWithKey(keyID, items[i].Msg, opts...)
+			require.NoError(t, err)
+		}
+
+		return items
+	}
+
+	t.Run("test batch with one bad signature", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		items := signItems(t, keyID, 5)
+		items[2].Msg = []byte("tampered message")
+
+		results, err := kmsService.VerifyBatch(keyID, items)
+		require.NoError(t, err)
+		require.Len(t, results, len(items))
+
+		for i, result := range results {
+			if i == 2 {
+				require.Error(t, result)
+				require.Contains(t, result.Error(), "verify batch item 2")
+
+				continue
+			}
+
+			require.NoError(t, result, i)
+		}
+	})
+
+	t.Run("test batch of signatures in a domain", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		items := signItems(t, keyID, 3, WithDomain("didcomm/v1"))
+
+		results, err := kmsService.VerifyBatch(keyID, items, WithDomain("didcomm/v1"))
+		require.NoError(t, err)
+		require.Equal(t, []error{nil, nil, nil}, results)
+
+		// the key is bound to its domain
+		_, err = kmsService.VerifyBatch(keyID, items)
+		require.True(t, errors.Is(err, ErrSignatureDomain))
+
+		_, err = kmsService.VerifyBatch(keyID, items, WithDomain("jwt"))
+		require.True(t, errors.Is(err, ErrSignatureDomain))
+
+		_, err = kmsService.VerifyBatch(keyID, items, WithDomain("a\x00b"))
+		require.EqualError(t, err, "verify batch: signature domain contains a null byte")
+	})
+
+	t.Run("test batch of ECDSA signatures with hash", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+
+		items := signItems(t, keyID, 3, WithHash(crypto.SHA384))
+
+		results, err := kmsService.VerifyBatch(keyID, items, WithHash(crypto.SHA384))
+		require.NoError(t, err)
+		require.Equal(t, []error{nil, nil, nil}, results)
+
+		// the signatures made with another hash function are invalid
+		results, err = kmsService.VerifyBatch(keyID, items)
+		require.NoError(t, err)
+
+		for _, result := range results {
+			require.Error(t, result)
+		}
+	})
+
+	t.Run("test empty batch", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		results, err := kmsService.VerifyBatch(keyID, nil)
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+
+	t.Run("test batch with a key unable to verify signatures", func(t *testing.T) {
+		_, err := kmsService.VerifyBatch("unknown", []VerifyItem{{}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verify batch")
+
+		_, err = kmsService.VerifyBatch("unknown", []VerifyItem{{}}, WithHash(crypto.SHA256))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verify batch")
+
+		keyID, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		_, err = kmsService.VerifyBatch(keyID, []VerifyItem{{}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verify batch")
+	})
+}
+
+func BenchmarkLocalKMS_VerifyBatch(b *testing.B) {
+	const batchSize = 100
+
+	kmsService, err := New("local-lock://custom/master/key/", &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: &noop.NoLock{},
+	})
+	require.NoError(b, err)
+
+	keyID, _, err := kmsService.Create(kms.ED25519Type)
+	require.NoError(b, err)
+
+	items := make([]VerifyItem, batchSize)
+
+	for i := range items {
+		items[i].Msg = []byte(fmt.Sprintf("message %d", i))
+
+		items[i].Sig, err = kmsService.SignWithKey(keyID, items[i].Msg)
+		require.NoError(b, err)
+	}
+
+	b.Run("one by one", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			for _, item := range items {
+				err := kmsService.VerifyWithKey(keyID, item.Sig, item.Msg)
+				require.NoError(b, err)
+			}
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			_, err := kmsService.VerifyBatch(keyID, items)
+			require.NoError(b, err)
+		}
+	})
+}
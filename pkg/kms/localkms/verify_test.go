@@ -0,0 +1,123 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestLocalKMS_Verify(t *testing.T) {
+	newKMS := func(t *testing.T, store *mockstorage.MockStore) *LocalKMS {
+		kmsService, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: createMasterKeyAndSecretLock(t),
+		})
+		require.NoError(t, err)
+
+		return kmsService
+	}
+
+	t.Run("test corrupted keysets are reported", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		kmsService := newKMS(t, store)
+
+		validKeyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		corruptedKeyID, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		tamperedKeyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+
+		failed, err := kmsService.Verify()
+		require.NoError(t, err)
+		require.Empty(t, failed)
+
+		// seed a corrupted blob and a tampered (still well formed) encrypted keyset
+		store.Store[corruptedKeyID] = []byte("corrupted keyset")
+
+		tampered := string(store.Store[tamperedKeyID])
+		i := strings.Index(tampered, `"encryptedKeyset":"`) + len(`"encryptedKeyset":"`) + 10
+		store.Store[tamperedKeyID] = []byte(tampered[:i] + flipBase64Char(tampered[i]) + tampered[i+1:])
+
+		// data stored outside of the KMS master key is not verified
+		store.Store["other-data"] = []byte("not a keyset")
+
+		failed, err = kmsService.Verify()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{corruptedKeyID, tamperedKeyID}, failed)
+		require.NotContains(t, failed, validKeyID)
+	})
+
+	t.Run("test keysets encrypted with another master key are reported", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+
+		keyID, _, err := newKMS(t, store).Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		failed, err := newKMS(t, store).Verify()
+		require.NoError(t, err)
+		require.Equal(t, []string{keyID}, failed)
+	})
+
+	t.Run("test store iterator error", func(t *testing.T) {
+		kmsService := newKMS(t, &mockstorage.MockStore{
+			Store:  map[string][]byte{},
+			ErrItr: errors.New("iterator error"),
+		})
+
+		failed, err := kmsService.Verify()
+		require.EqualError(t, err, "verify keystore: iterator error")
+		require.Empty(t, failed)
+	})
+}
+
+func flipBase64Char(c byte) string {
+	if c == 'A' {
+		return "B"
+	}
+
+	return "A"
+}
+
+func TestLocalKMS_CanUnwrap(t *testing.T) {
+	store := &mockstorage.MockStore{Store: map[string][]byte{}}
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewCustomMockStoreProvider(store),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	goodKeyID, _, err := kmsService.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	corruptedKeyID, _, err := kmsService.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	corrupted := string(store.Store[corruptedKeyID])
+	i := strings.Index(corrupted, `"encryptedKeyset":"`) + len(`"encryptedKeyset":"`) + 10
+	store.Store[corruptedKeyID] = []byte(corrupted[:i] + flipBase64Char(corrupted[i]) + corrupted[i+1:])
+
+	require.NoError(t, kmsService.CanUnwrap(goodKeyID))
+
+	err = kmsService.CanUnwrap(corruptedKeyID)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, storage.ErrDataNotFound))
+
+	err = kmsService.CanUnwrap(testMasterKeyURI + "/missing")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+}
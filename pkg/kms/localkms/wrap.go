@@ -0,0 +1,206 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/aead"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
+)
+
+// Key wrapping algorithms: the AEAD of the data encryption key wrapping the key material of a keyset, the data
+// encryption key itself is wrapped by the master key.
+const (
+	KeyWrapAES256GCM         = "AES256GCM"
+	KeyWrapAES128GCM         = "AES128GCM"
+	KeyWrapXChaCha20Poly1305 = "XChaCha20Poly1305"
+)
+
+// keyWrapAlgorithms are the supported key wrapping algorithms.
+// nolint:gochecknoglobals
+var keyWrapAlgorithms = []string{KeyWrapAES256GCM, KeyWrapAES128GCM, KeyWrapXChaCha20Poly1305}
+
+// ErrUnknownKeyWrap is returned when reading a keyset wrapped with a key wrap which is not configured.
+var ErrUnknownKeyWrap = errors.New("unknown key wrap")
+
+// KeyWrap identifies how a keyset is wrapped: its wrapping algorithm, and the master key (URI and version)
+// wrapping its data encryption key. It is recorded in a header stored with each keyset, so a keyset is unwrapped
+// with the key wrap it was stored with (see WithKeyWrap).
+type KeyWrap struct {
+	Algorithm    string `json:"alg"`
+	MasterKeyURI string `json:"masterKeyURI"`
+	// Version is the version of the master key, set by the operator when the master key behind the URI changes
+	Version string `json:"version,omitempty"`
+}
+
+// wrappedKeySet is the stored form of a keyset: the keyset wrapped with the key wrap of its header.
+// Keysets stored before key wraps were recorded have no header, they are wrapped with the default key wrap.
+type wrappedKeySet struct {
+	Wrap   *KeyWrap        `json:"wrap"`
+	KeySet json.RawMessage `json:"keyset"`
+}
+
+// WithKeyWrap option sets the key wrap of the keysets stored by the KMS, previous are the key wraps of the keysets
+// stored before, which can still be read. This allows migrating the wrapping algorithm or the master key in
+// stages: new and rotated keysets are wrapped with wrap while existing keysets are read with their own key wrap.
+// By default keysets are wrapped with AES256GCM and the master key of the KMS, keysets stored without a key wrap
+// header are always read with this default key wrap.
+func WithKeyWrap(wrap KeyWrap, previous ...KeyWrap) Option {
+	return func(opts *LocalKMS) {
+		opts.keyWrap = wrap
+		opts.previousKeyWraps = previous
+	}
+}
+
+// WithEnvelopeAEADTemplate option sets the template of the envelope AEAD wrapping the keysets stored by the KMS with
+// its master key: aead.AES256GCMKeyTemplate() (the default), aead.AES128GCMKeyTemplate() or
+// aead.XChaCha20Poly1305KeyTemplate(). Keysets stored with the master key of the KMS are read whatever their
+// envelope AEAD, so the template can be changed without migrating the stored keysets. WithKeyWrap takes precedence.
+func WithEnvelopeAEADTemplate(template *tinkpb.KeyTemplate) Option {
+	return func(opts *LocalKMS) {
+		opts.envelopeTemplate = template
+	}
+}
+
+// keyWrapAlgorithm returns the key wrapping algorithm of the envelope AEAD template.
+func keyWrapAlgorithm(template *tinkpb.KeyTemplate) (string, error) {
+	for _, algorithm := range keyWrapAlgorithms {
+		dekTemplate, err := dekKeyTemplate(algorithm)
+		if err != nil {
+			return "", err
+		}
+
+		if proto.Equal(template, dekTemplate) {
+			return algorithm, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported envelope AEAD template '%s'", template.TypeUrl)
+}
+
+// defaultKeyWrap is the key wrap of keysets stored without a key wrap header.
+func (l *LocalKMS) defaultKeyWrap() KeyWrap {
+	return KeyWrap{Algorithm: KeyWrapAES256GCM, MasterKeyURI: l.masterKeyURI}
+}
+
+// createKeyWrapAEADs creates the AEADs wrapping and unwrapping the keysets of each configured key wrap.
+func (l *LocalKMS) createKeyWrapAEADs() error {
+	if l.keyWrap == (KeyWrap{}) {
+		l.keyWrap = l.defaultKeyWrap()
+
+		if l.envelopeTemplate != nil {
+			algorithm, err := keyWrapAlgorithm(l.envelopeTemplate)
+			if err != nil {
+				return err
+			}
+
+			l.keyWrap.Algorithm = algorithm
+		}
+	}
+
+	envAEADs, err := l.newKeyWrapAEADs(append(masterKeyWraps(l.keyWrap, l.masterKeyURI), l.previousKeyWraps...))
+	if err != nil {
+		return err
+	}
+
+	l.keyWrapAEADs = envAEADs
+
+	return nil
+}
+
+// masterKeyWraps returns wrap, and the key wraps of the master key keyURI with each supported algorithm: keysets
+// stored with a master key are read whatever the algorithm they were wrapped with.
+func masterKeyWraps(wrap KeyWrap, keyURI string) []KeyWrap {
+	wraps := []KeyWrap{wrap}
+
+	for _, algorithm := range keyWrapAlgorithms {
+		wraps = append(wraps, KeyWrap{Algorithm: algorithm, MasterKeyURI: keyURI})
+	}
+
+	return wraps
+}
+
+// newKeyWrapAEADs creates the AEADs wrapping and unwrapping the keysets of the key wraps.
+func (l *LocalKMS) newKeyWrapAEADs(wraps []KeyWrap) (map[KeyWrap]*aead.KMSEnvelopeAEAD, error) {
+	envAEADs := make(map[KeyWrap]*aead.KMSEnvelopeAEAD)
+
+	for _, wrap := range wraps {
+		if _, ok := envAEADs[wrap]; ok {
+			continue
+		}
+
+		envAEAD, err := l.newKeyWrapAEAD(wrap)
+		if err != nil {
+			return nil, fmt.Errorf("key wrap %s %s: %w", wrap.Algorithm, wrap.MasterKeyURI, err)
+		}
+
+		envAEADs[wrap] = envAEAD
+	}
+
+	return envAEADs, nil
+}
+
+func (l *LocalKMS) newKeyWrapAEAD(wrap KeyWrap) (*aead.KMSEnvelopeAEAD, error) {
+	dekTemplate, err := dekKeyTemplate(wrap.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	kw, err := l.newKeyWrapper(wrap.MasterKeyURI)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.masterKeyCacheTTL > 0 {
+		kw = keywrapper.NewCachedAEAD(kw, l.masterKeyCacheTTL)
+	}
+
+	return aead.NewKMSEnvelopeAEAD(*dekTemplate, kw), nil
+}
+
+// dekKeyTemplate returns the template of the data encryption keys of the key wrapping algorithm.
+func dekKeyTemplate(algorithm string) (*tinkpb.KeyTemplate, error) {
+	switch algorithm {
+	case KeyWrapAES256GCM:
+		return aead.AES256GCMKeyTemplate(), nil
+	case KeyWrapAES128GCM:
+		return aead.AES128GCMKeyTemplate(), nil
+	case KeyWrapXChaCha20Poly1305:
+		return aead.XChaCha20Poly1305KeyTemplate(), nil
+	default:
+		return nil, fmt.Errorf("unsupported key wrap algorithm '%s'", algorithm)
+	}
+}
+
+// unwrapHeader returns the AEAD of the key wrap of the stored keyset data, and the wrapped keyset.
+func (l *LocalKMS) unwrapHeader(data []byte) (*aead.KMSEnvelopeAEAD, []byte, error) {
+	var wrapped wrappedKeySet
+
+	// keysets stored without header are tink JSON keysets, with no "wrap" field
+	if err := json.Unmarshal(data, &wrapped); err != nil || wrapped.Wrap == nil {
+		defaultWrap := l.defaultKeyWrap()
+		wrapped = wrappedKeySet{Wrap: &defaultWrap, KeySet: data}
+	}
+
+	if err := l.checkTenantKeyWrap(wrapped.Wrap); err != nil {
+		return nil, nil, err
+	}
+
+	envAEAD, ok := l.keyWrapAEADs[*wrapped.Wrap]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s %s version '%s'", ErrUnknownKeyWrap, wrapped.Wrap.Algorithm,
+			wrapped.Wrap.MasterKeyURI, wrapped.Wrap.Version)
+	}
+
+	return envAEAD, wrapped.KeySet, nil
+}
@@ -0,0 +1,241 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/tink/go/aead"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+)
+
+// keyURIRecorder records the master key URIs unwrapping keys.
+type keyURIRecorder struct {
+	secretlock.Service
+	mutex   sync.Mutex
+	keyURIs []string
+}
+
+func (r *keyURIRecorder) Decrypt(keyURI string, req *secretlock.DecryptRequest) (*secretlock.DecryptResponse, error) {
+	r.mutex.Lock()
+	r.keyURIs = append(r.keyURIs, keyURI)
+	r.mutex.Unlock()
+
+	return r.Service.Decrypt(keyURI, req)
+}
+
+func (r *keyURIRecorder) reset() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	keyURIs := r.keyURIs
+	r.keyURIs = nil
+
+	return keyURIs
+}
+
+func TestLocalKMS_KeyWrap(t *testing.T) {
+	store := &mockstorage.MockStore{Store: map[string][]byte{}}
+	secretLock := &keyURIRecorder{Service: createMasterKeyAndSecretLock(t)}
+
+	newKMS := func(t *testing.T, opts ...Option) *LocalKMS {
+		kmsService, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		}, opts...)
+		require.NoError(t, err)
+
+		return kmsService
+	}
+
+	storedKeyWrap := func(t *testing.T, keyID string) *KeyWrap {
+		var wrapped wrappedKeySet
+		require.NoError(t, json.Unmarshal(store.Store[keyID], &wrapped))
+
+		return wrapped.Wrap
+	}
+
+	wrapA := KeyWrap{Algorithm: KeyWrapAES128GCM, MasterKeyURI: "local-lock://wrap/a", Version: "1"}
+	wrapB := KeyWrap{Algorithm: KeyWrapXChaCha20Poly1305, MasterKeyURI: "local-lock://wrap/b", Version: "2"}
+
+	kmsA := newKMS(t, WithKeyWrap(wrapA))
+
+	keyIDA, _, err := kmsA.Create(kms.ED25519Type)
+	require.NoError(t, err)
+	require.Equal(t, &wrapA, storedKeyWrap(t, keyIDA))
+
+	// migration: new keysets are wrapped with wrapB, keysets wrapped with wrapA can still be read
+	kmsB := newKMS(t, WithKeyWrap(wrapB, wrapA))
+
+	keyIDB, _, err := kmsB.Create(kms.ED25519Type)
+	require.NoError(t, err)
+	require.Equal(t, &wrapB, storedKeyWrap(t, keyIDB))
+
+	t.Run("test keysets are unwrapped with the key wrap of their header", func(t *testing.T) {
+		secretLock.reset()
+
+		_, err := kmsB.Get(keyIDA)
+		require.NoError(t, err)
+		require.Equal(t, []string{"wrap/a"}, secretLock.reset())
+
+		_, err = kmsB.Get(keyIDB)
+		require.NoError(t, err)
+		require.Equal(t, []string{"wrap/b"}, secretLock.reset())
+	})
+
+	t.Run("test keysets of a key wrap which is not configured can't be read", func(t *testing.T) {
+		kmsService := newKMS(t, WithKeyWrap(wrapB))
+
+		_, err := kmsService.Get(keyIDB)
+		require.NoError(t, err)
+
+		_, err = kmsService.Get(keyIDA)
+		require.True(t, errors.Is(err, ErrUnknownKeyWrap))
+		require.Contains(t, err.Error(), "AES128GCM local-lock://wrap/a version '1'")
+
+		// same algorithm and master key, another master key version
+		_, err = newKMS(t, WithKeyWrap(KeyWrap{
+			Algorithm:    wrapA.Algorithm,
+			MasterKeyURI: wrapA.MasterKeyURI,
+			Version:      "2",
+		})).Get(keyIDA)
+		require.True(t, errors.Is(err, ErrUnknownKeyWrap))
+	})
+
+	t.Run("test rotated keysets are wrapped with the current key wrap", func(t *testing.T) {
+		keyID, _, err := kmsA.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		newKeyID, _, err := kmsB.Rotate(kms.AES256GCMType, keyID)
+		require.NoError(t, err)
+		require.Equal(t, &wrapB, storedKeyWrap(t, newKeyID))
+	})
+
+	t.Run("test keysets stored without header are read with the default key wrap", func(t *testing.T) {
+		kmsService := newKMS(t)
+
+		keyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+		require.Equal(t, &KeyWrap{Algorithm: KeyWrapAES256GCM, MasterKeyURI: testMasterKeyURI},
+			storedKeyWrap(t, keyID))
+
+		var wrapped wrappedKeySet
+		require.NoError(t, json.Unmarshal(store.Store[keyID], &wrapped))
+		store.Store[keyID] = wrapped.KeySet
+
+		_, err = kmsService.Get(keyID)
+		require.NoError(t, err)
+
+		_, err = kmsB.Get(keyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("test invalid key wraps", func(t *testing.T) {
+		_, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		}, WithKeyWrap(KeyWrap{Algorithm: "DES", MasterKeyURI: "local-lock://wrap/a"}))
+		require.EqualError(t, err, "failed to create local kms: key wrap DES local-lock://wrap/a: "+
+			"unsupported key wrap algorithm 'DES'")
+
+		_, err = New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		}, WithKeyWrap(wrapB, KeyWrap{Algorithm: KeyWrapAES256GCM, MasterKeyURI: "remote://wrap/a"}))
+		require.True(t, errors.Is(err, ErrUnsupportedMasterKeyURI))
+	})
+}
+
+func TestLocalKMS_EnvelopeAEADTemplate(t *testing.T) {
+	store := &mockstorage.MockStore{Store: map[string][]byte{}}
+	secretLock := createMasterKeyAndSecretLock(t)
+
+	newKMS := func(t *testing.T, opts ...Option) *LocalKMS {
+		kmsService, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		}, opts...)
+		require.NoError(t, err)
+
+		return kmsService
+	}
+
+	storedKeyWrap := func(t *testing.T, keyID string) *KeyWrap {
+		var wrapped wrappedKeySet
+		require.NoError(t, json.Unmarshal(store.Store[keyID], &wrapped))
+
+		return wrapped.Wrap
+	}
+
+	chachaKMS := newKMS(t, WithEnvelopeAEADTemplate(aead.XChaCha20Poly1305KeyTemplate()))
+
+	chachaKeyID, _, err := chachaKMS.Create(kms.ED25519Type)
+	require.NoError(t, err)
+	require.Equal(t, &KeyWrap{Algorithm: KeyWrapXChaCha20Poly1305, MasterKeyURI: testMasterKeyURI},
+		storedKeyWrap(t, chachaKeyID))
+
+	aes128KMS := newKMS(t, WithEnvelopeAEADTemplate(aead.AES128GCMKeyTemplate()))
+
+	aes128KeyID, _, err := aes128KMS.Create(kms.ECDSAP256Type)
+	require.NoError(t, err)
+	require.Equal(t, &KeyWrap{Algorithm: KeyWrapAES128GCM, MasterKeyURI: testMasterKeyURI},
+		storedKeyWrap(t, aes128KeyID))
+
+	t.Run("test keysets are read back whatever their envelope AEAD", func(t *testing.T) {
+		defaultKMS := newKMS(t)
+
+		defaultKeyID, _, err := defaultKMS.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+		require.Equal(t, &KeyWrap{Algorithm: KeyWrapAES256GCM, MasterKeyURI: testMasterKeyURI},
+			storedKeyWrap(t, defaultKeyID))
+
+		for _, kmsService := range []*LocalKMS{chachaKMS, aes128KMS, defaultKMS} {
+			for _, keyID := range []string{chachaKeyID, aes128KeyID, defaultKeyID} {
+				_, err = kmsService.Get(keyID)
+				require.NoError(t, err)
+			}
+		}
+	})
+
+	t.Run("test keysets stored without header are read with a non-default envelope AEAD", func(t *testing.T) {
+		keyID, _, err := newKMS(t).Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		var wrapped wrappedKeySet
+		require.NoError(t, json.Unmarshal(store.Store[keyID], &wrapped))
+		store.Store[keyID] = wrapped.KeySet
+
+		_, err = chachaKMS.Get(keyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("test key wrap takes precedence", func(t *testing.T) {
+		wrap := KeyWrap{Algorithm: KeyWrapAES128GCM, MasterKeyURI: "local-lock://wrap/a"}
+
+		kmsService := newKMS(t, WithKeyWrap(wrap), WithEnvelopeAEADTemplate(aead.XChaCha20Poly1305KeyTemplate()))
+
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+		require.Equal(t, &wrap, storedKeyWrap(t, keyID))
+	})
+
+	t.Run("test unsupported envelope AEAD template", func(t *testing.T) {
+		_, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(store),
+			secretLock: secretLock,
+		}, WithEnvelopeAEADTemplate(aead.ChaCha20Poly1305KeyTemplate()))
+		require.EqualError(t, err, "failed to create local kms: unsupported envelope AEAD template "+
+			"'type.googleapis.com/google.crypto.tink.ChaCha20Poly1305Key'")
+	})
+}
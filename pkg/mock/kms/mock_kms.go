@@ -15,6 +15,7 @@ import (
 	"github.com/google/tink/go/testutil"
 
 	kmsservice "github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -29,10 +30,23 @@ type KeyManager struct {
 	RotateKeyID    string
 	RotateKeyValue *keyset.Handle
 	RotateKeyErr   error
+	VerifyValue    []string
+	VerifyErr      error
+	StatsValue     *localkms.KMSStats
+	StatsErr       error
+	HealthCheckErr error
+	ListValue      []string
+	ListErr        error
 }
 
 // Create a new mock ey/keyset/key handle for the type kt
 func (k *KeyManager) Create(kt kmsservice.KeyType) (string, interface{}, error) {
+	return k.CreateWithOptions(kt)
+}
+
+// CreateWithOptions a new mock key/keyset/key handle for the type kt, the key options are ignored
+func (k *KeyManager) CreateWithOptions(kt kmsservice.KeyType,
+	opts ...kmsservice.KeyOption) (string, interface{}, error) {
 	if k.CreateKeyErr != nil {
 		return "", nil, k.CreateKeyErr
 	}
@@ -51,6 +65,12 @@ func (k *KeyManager) Get(keyID string) (interface{}, error) {
 
 // Rotate returns a mocked rotated keyset handle and its ID
 func (k *KeyManager) Rotate(kt kmsservice.KeyType, keyID string) (string, interface{}, error) {
+	return k.RotateWithOptions(kt, keyID)
+}
+
+// RotateWithOptions returns a mocked rotated keyset handle and its ID, the key options are ignored
+func (k *KeyManager) RotateWithOptions(kt kmsservice.KeyType, keyID string,
+	opts ...kmsservice.KeyOption) (string, interface{}, error) {
 	if k.RotateKeyErr != nil {
 		return "", nil, k.RotateKeyErr
 	}
@@ -58,6 +78,33 @@ func (k *KeyManager) Rotate(kt kmsservice.KeyType, keyID string) (string, interf
 	return k.RotateKeyID, k.RotateKeyValue, nil
 }
 
+// ListByKeyType returns the mocked IDs of the keys of type kt
+func (k *KeyManager) ListByKeyType(kt kmsservice.KeyType) ([]string, error) {
+	if k.ListErr != nil {
+		return nil, k.ListErr
+	}
+
+	return k.ListValue, nil
+}
+
+// Verify returns the mocked IDs of the keysets that failed verification
+func (k *KeyManager) Verify() ([]string, error) {
+	if k.VerifyErr != nil {
+		return nil, k.VerifyErr
+	}
+
+	return k.VerifyValue, nil
+}
+
+// Stats returns the mocked KMS statistics
+func (k *KeyManager) Stats() (*localkms.KMSStats, error) {
+	if k.StatsErr != nil {
+		return nil, k.StatsErr
+	}
+
+	return k.StatsValue, nil
+}
+
 // CreateMockKeyHandle is a utility function that returns a mock key (for tests only. ie: not registered in Tink)
 func CreateMockKeyHandle() (*keyset.Handle, error) {
 	ks := testutil.NewTestAESGCMKeyset(tinkpb.OutputPrefixType_TINK)
@@ -93,3 +140,8 @@ func NewProvider(storeProvider storage.Provider, secretLock secretlock.Service)
 		secretLock:    secretLock,
 	}
 }
+
+// HealthCheck returns the mocked health check error
+func (k *KeyManager) HealthCheck() error {
+	return k.HealthCheckErr
+}
@@ -85,6 +85,28 @@ func (s *MockStore) Put(k string, v []byte) error {
 	return s.ErrPut
 }
 
+// PutIfMatch stores the key and the record if the version of the current record matches expectedVersion
+func (s *MockStore) PutIfMatch(k string, v, expectedVersion []byte) error {
+	if k == "" {
+		return errors.New("key is mandatory")
+	}
+
+	if s.ErrPut != nil {
+		return s.ErrPut
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := storage.MatchVersion(s.Store[k], expectedVersion); err != nil {
+		return err
+	}
+
+	s.Store[k] = v
+
+	return nil
+}
+
 // Get fetches the record based on key
 func (s *MockStore) Get(k string) ([]byte, error) {
 	if s.ErrGet != nil {
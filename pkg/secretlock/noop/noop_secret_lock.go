@@ -6,13 +6,26 @@ SPDX-License-Identifier: Apache-2.0
 package noop
 
 import (
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 )
 
+var logger = log.New("aries-framework/secretlock/noop")
+
 // NoLock is a secret lock service that does no key wrapping (keys are not encrypted)
 type NoLock struct {
 }
 
+// NewUnsafePassthrough returns a secret lock service passing the keys through as is: the KMS keys are stored in
+// plaintext. It is only meant for storage already protecting the keys (eg: storage encrypted by an HSM), to avoid
+// wrapping them twice. It is UNSAFE with any other storage, a warning is logged when it is created.
+func NewUnsafePassthrough() *NoLock {
+	logger.Warnf("UNSAFE secret lock: keys are stored unencrypted, the storage must protect them (eg: HSM-backed " +
+		"storage encryption)")
+
+	return &NoLock{}
+}
+
 // Encrypt a key in req using master key in the local secret lock service
 // Noop implementation returns the key as is with no encryption
 // (keyURI is used for remote locks, it is ignored by this implementation)
@@ -6,10 +6,12 @@ SPDX-License-Identifier: Apache-2.0
 package noop
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 )
 
@@ -28,3 +30,54 @@ func TestNoLock(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, pt.Plaintext, "testKey")
 }
+
+func TestNewUnsafePassthrough(t *testing.T) {
+	// the warning logger must be set before the first log output
+	warnings := &warningLogger{}
+	log.Initialize(warnings)
+
+	lock := NewUnsafePassthrough()
+	require.Len(t, warnings.msgs, 1)
+	require.Contains(t, warnings.msgs[0], "UNSAFE secret lock")
+
+	var _ secretlock.Service = lock
+
+	for _, key := range []string{"", "testKey", "\x00\x01binary\xff"} {
+		ct, err := lock.Encrypt("local-lock://test/master/key/", &secretlock.EncryptRequest{
+			Plaintext:                   key,
+			AdditionalAuthenticatedData: "aad",
+		})
+		require.NoError(t, err)
+		require.Equal(t, key, ct.Ciphertext)
+
+		pt, err := lock.Decrypt("local-lock://test/master/key/", &secretlock.DecryptRequest{
+			Ciphertext:                  ct.Ciphertext,
+			AdditionalAuthenticatedData: "aad",
+		})
+		require.NoError(t, err)
+		require.Equal(t, key, pt.Plaintext)
+	}
+}
+
+// warningLogger is a logger provider recording the warnings.
+type warningLogger struct {
+	msgs []string
+}
+
+func (w *warningLogger) GetLogger(string) log.Logger {
+	return w
+}
+
+func (w *warningLogger) Warnf(msg string, args ...interface{}) {
+	w.msgs = append(w.msgs, fmt.Sprintf(msg, args...))
+}
+
+func (w *warningLogger) Fatalf(string, ...interface{}) {}
+
+func (w *warningLogger) Panicf(string, ...interface{}) {}
+
+func (w *warningLogger) Debugf(string, ...interface{}) {}
+
+func (w *warningLogger) Infof(string, ...interface{}) {}
+
+func (w *warningLogger) Errorf(string, ...interface{}) {}
@@ -0,0 +1,174 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// Codec encodes the records kept by record stores into the bytes saved in a storage.Store, and decodes them back
+// (see Store). The records already saved are not migrated when the codec of a store is changed, they can't be read
+// anymore.
+type Codec interface {
+	// Marshal encodes v.
+	Marshal(v interface{}) ([]byte, error)
+
+	// Unmarshal decodes data into v, v must be a pointer.
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSON encodes records as JSON, it is the default codec.
+type JSON struct{}
+
+// Marshal encodes v as JSON.
+func (JSON) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON data into v.
+func (JSON) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// CBOR encodes records as CBOR (RFC 7049), a more compact format than JSON. The `json` field tags of the
+// records are honored, and times are encoded as RFC 3339 strings like in JSON to keep their location.
+type CBOR struct{}
+
+// Marshal encodes v as CBOR.
+func (CBOR) Marshal(v interface{}) ([]byte, error) {
+	em, err := cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+	if err != nil {
+		return nil, fmt.Errorf("cbor encoding mode: %w", err)
+	}
+
+	return em.Marshal(v)
+}
+
+// Unmarshal decodes CBOR data into v.
+func (CBOR) Unmarshal(data []byte, v interface{}) error {
+	return cbor.Unmarshal(data, v)
+}
+
+// Protobuf encodes records in the protobuf wire format. Protobuf messages are encoded as is, the other records are
+// encoded as a google.protobuf.Value holding their JSON representation, so the `json` field tags of the records are
+// honored and their numbers are encoded as doubles: integers above 2^53 lose precision. Map fields are encoded in
+// their key order, a record is always encoded in the same bytes.
+type Protobuf struct{}
+
+// Marshal encodes v in the protobuf wire format.
+func (Protobuf) Marshal(v interface{}) ([]byte, error) {
+	if msg, ok := v.(proto.Message); ok {
+		return marshalDeterministic(msg)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf marshal %T: %w", v, err)
+	}
+
+	value := &structpb.Value{}
+
+	err = jsonpb.Unmarshal(bytes.NewReader(data), value)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf marshal %T: %w", v, err)
+	}
+
+	return marshalDeterministic(value)
+}
+
+func marshalDeterministic(msg proto.Message) ([]byte, error) {
+	buf := proto.NewBuffer(nil)
+	buf.SetDeterministic(true)
+
+	if err := buf.Marshal(msg); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes protobuf data into v.
+func (Protobuf) Unmarshal(data []byte, v interface{}) error {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, msg)
+	}
+
+	value := &structpb.Value{}
+
+	err := proto.Unmarshal(data, value)
+	if err != nil {
+		return fmt.Errorf("protobuf unmarshal %T: %w", v, err)
+	}
+
+	var buf bytes.Buffer
+
+	err = (&jsonpb.Marshaler{}).Marshal(&buf, value)
+	if err != nil {
+		return fmt.Errorf("protobuf unmarshal %T: %w", v, err)
+	}
+
+	return json.Unmarshal(buf.Bytes(), v)
+}
+
+// Store wraps a storage.Store to save and read records encoded with a codec.
+type Store struct {
+	storage.Store
+	codec Codec
+}
+
+// NewStore returns a store saving the records in store, encoded with c (JSON if c is nil).
+func NewStore(store storage.Store, c Codec) *Store {
+	if c == nil {
+		c = JSON{}
+	}
+
+	return &Store{Store: store, codec: c}
+}
+
+// Codec returns the codec of the store.
+func (s *Store) Codec() Codec {
+	return s.codec
+}
+
+// PutRecord encodes and stores the record v under the key k.
+func (s *Store) PutRecord(k string, v interface{}) error {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("put record: %w", err)
+	}
+
+	return s.Put(k, data)
+}
+
+// PutIfMatch stores the key and the record in the wrapped store if the version of the current record matches
+// expectedVersion, atomically if the wrapped store implements storage.VersionedStore (see storage.PutIfMatch).
+func (s *Store) PutIfMatch(k string, v, expectedVersion []byte) error {
+	return storage.PutIfMatch(s.Store, k, v, expectedVersion)
+}
+
+// GetRecord fetches the record stored under the key k and decodes it into v.
+func (s *Store) GetRecord(k string, v interface{}) error {
+	data, err := s.Get(k)
+	if err != nil {
+		return err
+	}
+
+	if err := s.codec.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("get record: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package codec
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+type record struct {
+	ID       string            `json:"id"`
+	Count    int               `json:"count,omitempty"`
+	Keys     []string          `json:"keys,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Created  time.Time         `json:"created"`
+	Version  []byte            `json:"-"`
+	Implicit bool
+}
+
+func testRecord() *record {
+	return &record{
+		ID:       "conn-1",
+		Count:    3,
+		Keys:     []string{"key-1", "key-2"},
+		Labels:   map[string]string{"label": "alice"},
+		Created:  time.Date(2020, time.May, 1, 10, 0, 0, 0, time.UTC),
+		Version:  []byte("not stored"),
+		Implicit: true,
+	}
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		codec Codec
+	}{
+		{name: "JSON", codec: JSON{}},
+		{name: "CBOR", codec: CBOR{}},
+		{name: "Protobuf", codec: Protobuf{}},
+	} {
+		tc := tc
+
+		t.Run("test "+tc.name+" round trip", func(t *testing.T) {
+			data, err := tc.codec.Marshal(testRecord())
+			require.NoError(t, err)
+
+			var decoded record
+			require.NoError(t, tc.codec.Unmarshal(data, &decoded))
+
+			expected := testRecord()
+			expected.Version = nil
+			require.Equal(t, expected, &decoded)
+		})
+
+		t.Run("test "+tc.name+" invalid data", func(t *testing.T) {
+			var decoded record
+			require.Error(t, tc.codec.Unmarshal([]byte{0xff, 0x01}, &decoded))
+		})
+	}
+
+	t.Run("test CBOR is more compact than JSON", func(t *testing.T) {
+		jsonData, err := JSON{}.Marshal(testRecord())
+		require.NoError(t, err)
+
+		cborData, err := CBOR{}.Marshal(testRecord())
+		require.NoError(t, err)
+		require.Less(t, len(cborData), len(jsonData))
+	})
+	t.Run("test Protobuf round trip of a protobuf message", func(t *testing.T) {
+		msg := &tinkpb.KeyData{
+			TypeUrl:         "type.googleapis.com/google.crypto.tink.Ed25519PrivateKey",
+			Value:           []byte("key value"),
+			KeyMaterialType: tinkpb.KeyData_ASYMMETRIC_PRIVATE,
+		}
+
+		data, err := Protobuf{}.Marshal(msg)
+		require.NoError(t, err)
+
+		// the message is encoded as is
+		expected, err := proto.Marshal(msg)
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+
+		decoded := &tinkpb.KeyData{}
+		require.NoError(t, Protobuf{}.Unmarshal(data, decoded))
+		require.True(t, proto.Equal(msg, decoded))
+	})
+
+	t.Run("test Protobuf with a record which can't be encoded", func(t *testing.T) {
+		_, err := Protobuf{}.Marshal(make(chan int))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "protobuf marshal")
+	})
+}
+
+func TestStore(t *testing.T) {
+	for _, c := range []Codec{JSON{}, CBOR{}, Protobuf{}} {
+		mockStore := &mockstorage.MockStore{Store: make(map[string][]byte)}
+		store := NewStore(mockStore, c)
+		require.Equal(t, c, store.Codec())
+
+		require.NoError(t, store.PutRecord("conn-1", testRecord()))
+
+		// the record is stored encoded with the codec of the store
+		expected, err := c.Marshal(testRecord())
+		require.NoError(t, err)
+		require.Equal(t, expected, mockStore.Store["conn-1"])
+
+		var decoded record
+		require.NoError(t, store.GetRecord("conn-1", &decoded))
+		require.Equal(t, "conn-1", decoded.ID)
+		require.Equal(t, []string{"key-1", "key-2"}, decoded.Keys)
+	}
+
+	t.Run("test default codec", func(t *testing.T) {
+		require.Equal(t, JSON{}, NewStore(&mockstorage.MockStore{}, nil).Codec())
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		mockStore := &mockstorage.MockStore{Store: map[string][]byte{"invalid": []byte("invalid")}}
+		store := NewStore(mockStore, CBOR{})
+
+		err := store.PutRecord("k", make(chan int))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "put record")
+
+		err = store.GetRecord("missing", &record{})
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		err = store.GetRecord("invalid", &record{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get record")
+	})
+
+	t.Run("test put if match is done by the versioned store", func(t *testing.T) {
+		versioned := &versionedStore{MockStore: &mockstorage.MockStore{Store: make(map[string][]byte)}}
+
+		var store storage.Store = NewStore(versioned, nil)
+
+		_, ok := store.(storage.VersionedStore)
+		require.True(t, ok)
+
+		require.NoError(t, storage.PutIfMatch(store, "k", []byte("v1"), nil))
+		require.NoError(t, storage.PutIfMatch(store, "k", []byte("v2"), storage.Version([]byte("v1"))))
+
+		err := storage.PutIfMatch(store, "k", []byte("v3"), storage.Version([]byte("v1")))
+		require.True(t, errors.Is(err, storage.ErrVersionConflict))
+		require.Equal(t, 3, versioned.putIfMatchCalls)
+
+		// the version is checked by reading the current record if the wrapped store isn't versioned
+		store = NewStore(versioned.MockStore, nil)
+
+		err = storage.PutIfMatch(store, "k", []byte("v3"), storage.Version([]byte("v1")))
+		require.True(t, errors.Is(err, storage.ErrVersionConflict))
+		require.NoError(t, storage.PutIfMatch(store, "k", []byte("v3"), storage.Version([]byte("v2"))))
+	})
+}
+
+type versionedStore struct {
+	*mockstorage.MockStore
+	putIfMatchCalls int
+}
+
+func (s *versionedStore) PutIfMatch(k string, v, expectedVersion []byte) error {
+	s.putIfMatchCalls++
+
+	current, err := s.Get(k)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return err
+	}
+
+	if err = storage.MatchVersion(current, expectedVersion); err != nil {
+		return err
+	}
+
+	return s.Put(k, v)
+}
@@ -123,6 +123,39 @@ func (s *leveldbStore) Put(k string, v []byte) error {
 	return s.db.Put([]byte(k), v, nil)
 }
 
+// PutIfMatch stores the key and the record if the version of the current record matches expectedVersion.
+// The version check and the update are done in a transaction.
+func (s *leveldbStore) PutIfMatch(k string, v, expectedVersion []byte) error {
+	if k == "" || v == nil {
+		return errors.New("key and value are mandatory")
+	}
+
+	tr, err := s.db.OpenTransaction()
+	if err != nil {
+		return err
+	}
+
+	current, err := tr.Get([]byte(k), nil)
+	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		tr.Discard()
+
+		return err
+	}
+
+	err = storage.MatchVersion(current, expectedVersion)
+	if err == nil {
+		err = tr.Put([]byte(k), v, nil)
+	}
+
+	if err != nil {
+		tr.Discard()
+
+		return err
+	}
+
+	return tr.Commit()
+}
+
 // Get fetches the record based on key
 func (s *leveldbStore) Get(k string) ([]byte, error) {
 	if k == "" {
@@ -9,6 +9,7 @@ SPDX-License-Identifier: Apache-2.0
 package leveldb
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -291,3 +292,43 @@ func TestLevelDBStoreDelete(t *testing.T) {
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
 }
+
+func TestLevelDBStorePutIfMatch(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	const key = "did:example:1"
+
+	prov := NewProvider(path)
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	versioned, ok := store.(storage.VersionedStore)
+	require.True(t, ok)
+
+	err = versioned.PutIfMatch(key, []byte("value1"), nil)
+	require.NoError(t, err)
+
+	// the record exists
+	err = versioned.PutIfMatch(key, []byte("value2"), nil)
+	require.True(t, errors.Is(err, storage.ErrVersionConflict))
+
+	err = versioned.PutIfMatch(key, []byte("value2"), storage.Version([]byte("value1")))
+	require.NoError(t, err)
+
+	// stale version
+	err = versioned.PutIfMatch(key, []byte("value3"), storage.Version([]byte("value1")))
+	require.True(t, errors.Is(err, storage.ErrVersionConflict))
+
+	doc, err := store.Get(key)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), doc)
+
+	err = versioned.PutIfMatch("", []byte("value"), nil)
+	require.EqualError(t, err, "key and value are mandatory")
+
+	require.NoError(t, prov.Close())
+
+	err = versioned.PutIfMatch(key, []byte("value3"), storage.Version([]byte("value2")))
+	require.Error(t, err)
+}
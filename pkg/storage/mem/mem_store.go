@@ -108,6 +108,24 @@ func (s *memStore) Put(k string, v []byte) error {
 	return nil
 }
 
+// PutIfMatch stores the key and the record if the version of the current record matches expectedVersion
+func (s *memStore) PutIfMatch(k string, v, expectedVersion []byte) error {
+	if k == "" || v == nil {
+		return errors.New("key and value are mandatory")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if err := storage.MatchVersion(s.db[k], expectedVersion); err != nil {
+		return err
+	}
+
+	s.db[k] = v
+
+	return nil
+}
+
 // Get fetches the record based on key
 func (s *memStore) Get(k string) ([]byte, error) {
 	if k == "" {
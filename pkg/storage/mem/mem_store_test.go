@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package mem
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -245,3 +248,77 @@ func TestMemStoreDelete(t *testing.T) {
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
 }
+
+func TestMemStorePutIfMatch(t *testing.T) {
+	const key = "did:example:1"
+
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	versioned, ok := store.(storage.VersionedStore)
+	require.True(t, ok)
+
+	t.Run("Test mem store put if match", func(t *testing.T) {
+		err = versioned.PutIfMatch(key, []byte("value1"), nil)
+		require.NoError(t, err)
+
+		// the record exists
+		err = versioned.PutIfMatch(key, []byte("value2"), nil)
+		require.True(t, errors.Is(err, storage.ErrVersionConflict))
+
+		err = versioned.PutIfMatch(key, []byte("value2"), storage.Version([]byte("value1")))
+		require.NoError(t, err)
+
+		// stale version
+		err = versioned.PutIfMatch(key, []byte("value3"), storage.Version([]byte("value1")))
+		require.True(t, errors.Is(err, storage.ErrVersionConflict))
+
+		doc, err := store.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), doc)
+
+		// the record doesn't exist
+		err = versioned.PutIfMatch("did:example:2", []byte("value"), storage.Version([]byte("value")))
+		require.True(t, errors.Is(err, storage.ErrVersionConflict))
+
+		err = versioned.PutIfMatch("", []byte("value"), nil)
+		require.EqualError(t, err, "key and value are mandatory")
+	})
+
+	t.Run("Test mem store concurrent put if match", func(t *testing.T) {
+		const writers = 10
+
+		version := storage.Version([]byte("value2"))
+		errs := make(chan error, writers)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				errs <- versioned.PutIfMatch(key, []byte(fmt.Sprintf("writer %d", i)), version)
+			}(i)
+		}
+
+		wg.Wait()
+		close(errs)
+
+		updated := 0
+
+		for err := range errs {
+			if err == nil {
+				updated++
+
+				continue
+			}
+
+			require.True(t, errors.Is(err, storage.ErrVersionConflict))
+		}
+
+		require.Equal(t, 1, updated)
+	})
+}
@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// Namespace is the store namespace in which the applied migrations are tracked.
+const Namespace = "migrations"
+
+const (
+	keyPrefix = "migration_"
+	// limitPattern with `~` at the end for lte of given prefix (less than or equal)
+	limitPattern = "%s~"
+)
+
+var logger = log.New("aries-framework/storage/migration")
+
+// Func migrates the data kept in the stores of provider.
+type Func func(provider storage.Provider) error
+
+// Step is a migration of the stored data, identified by its version. Steps are run in increasing version order,
+// and each step is run once.
+type Step struct {
+	Version     uint64
+	Description string
+	Migrate     Func
+}
+
+// Record is the record of an applied migration.
+type Record struct {
+	Version     uint64    `json:"version"`
+	Description string    `json:"description,omitempty"`
+	AppliedAt   time.Time `json:"appliedAt"`
+}
+
+// Runner runs the registered migration steps which were not applied yet.
+type Runner struct {
+	steps map[uint64]Step
+}
+
+// New returns a migration runner with the steps registered.
+func New(steps ...Step) (*Runner, error) {
+	r := &Runner{steps: map[uint64]Step{}}
+
+	if err := r.Register(steps...); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Register registers migration steps. Versions must be greater than 0 and unique.
+func (r *Runner) Register(steps ...Step) error {
+	for _, step := range steps {
+		if step.Version == 0 {
+			return errors.New("migration version must be greater than 0")
+		}
+
+		if step.Migrate == nil {
+			return fmt.Errorf("migration %d has no migrate function", step.Version)
+		}
+
+		if _, found := r.steps[step.Version]; found {
+			return fmt.Errorf("migration %d is already registered", step.Version)
+		}
+
+		r.steps[step.Version] = step
+	}
+
+	return nil
+}
+
+// Run runs, in version order, the registered steps which were not applied yet to the data kept in provider, and
+// returns the versions applied. Running stops at the first step failing, the steps applied before are recorded
+// so they are not run again.
+func (r *Runner) Run(provider storage.Provider) ([]uint64, error) {
+	store, err := provider.OpenStore(Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the migrations store: %w", err)
+	}
+
+	var applied []uint64
+
+	for _, version := range r.versions() {
+		done, err := isApplied(store, version)
+		if err != nil {
+			return applied, err
+		}
+
+		if done {
+			continue
+		}
+
+		step := r.steps[version]
+
+		logger.Infof("applying storage migration %d: %s", step.Version, step.Description)
+
+		if err := step.Migrate(provider); err != nil {
+			return applied, fmt.Errorf("migration %d failed: %w", step.Version, err)
+		}
+
+		if err := saveRecord(store, &Record{
+			Version:     step.Version,
+			Description: step.Description,
+			AppliedAt:   time.Now().UTC(),
+		}); err != nil {
+			return applied, err
+		}
+
+		applied = append(applied, version)
+	}
+
+	return applied, nil
+}
+
+// Applied returns the records of the migrations applied to the data kept in provider.
+func Applied(provider storage.Provider) ([]*Record, error) {
+	store, err := provider.OpenStore(Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the migrations store: %w", err)
+	}
+
+	itr := store.Iterator(keyPrefix, fmt.Sprintf(limitPattern, keyPrefix))
+	defer itr.Release()
+
+	var records []*Record
+
+	for itr.Next() {
+		record := &Record{}
+		if err := json.Unmarshal(itr.Value(), record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal migration record: %w", err)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate migration records: %w", err)
+	}
+
+	return records, nil
+}
+
+func (r *Runner) versions() []uint64 {
+	versions := make([]uint64, 0, len(r.steps))
+	for version := range r.steps {
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	return versions
+}
+
+func isApplied(store storage.Store, version uint64) (bool, error) {
+	_, err := store.Get(key(version))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to get migration %d record: %w", version, err)
+	}
+
+	return true, nil
+}
+
+func saveRecord(store storage.Store, record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal migration %d record: %w", record.Version, err)
+	}
+
+	if err := store.Put(key(record.Version), data); err != nil {
+		return fmt.Errorf("failed to save migration %d record: %w", record.Version, err)
+	}
+
+	return nil
+}
+
+// key is zero padded so the records are iterated in version order.
+func key(version uint64) string {
+	return fmt.Sprintf("%s%020d", keyPrefix, version)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestRunner_Run(t *testing.T) {
+	t.Run("test run pending migrations in order once", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		var ran []string
+
+		r, err := New(
+			Step{Version: 2, Description: "rename connection label", Migrate: func(p storage.Provider) error {
+				store, err := p.OpenStore("connections")
+				require.NoError(t, err)
+
+				label, err := store.Get("label")
+				require.NoError(t, err)
+				require.Equal(t, "alice", string(label))
+
+				ran = append(ran, "rename")
+
+				return store.Put("label", []byte("Alice"))
+			}},
+			Step{Version: 1, Description: "add connection label", Migrate: func(p storage.Provider) error {
+				store, err := p.OpenStore("connections")
+				require.NoError(t, err)
+
+				ran = append(ran, "add")
+
+				return store.Put("label", []byte("alice"))
+			}},
+		)
+		require.NoError(t, err)
+
+		applied, err := r.Run(provider)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{1, 2}, applied)
+		require.Equal(t, []string{"add", "rename"}, ran)
+
+		// a second run applies no migration
+		applied, err = r.Run(provider)
+		require.NoError(t, err)
+		require.Empty(t, applied)
+		require.Equal(t, []string{"add", "rename"}, ran)
+
+		store, err := provider.OpenStore("connections")
+		require.NoError(t, err)
+
+		label, err := store.Get("label")
+		require.NoError(t, err)
+		require.Equal(t, "Alice", string(label))
+
+		records, err := Applied(provider)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		require.Equal(t, uint64(1), records[0].Version)
+		require.Equal(t, "add connection label", records[0].Description)
+		require.Equal(t, uint64(2), records[1].Version)
+		require.False(t, records[1].AppliedAt.IsZero())
+	})
+
+	t.Run("test run migrations registered after a run", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		r, err := New(Step{Version: 1, Migrate: func(storage.Provider) error { return nil }})
+		require.NoError(t, err)
+
+		applied, err := r.Run(provider)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{1}, applied)
+
+		require.NoError(t, r.Register(Step{Version: 2, Migrate: func(storage.Provider) error { return nil }}))
+
+		applied, err = r.Run(provider)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{2}, applied)
+	})
+
+	t.Run("test run stops at a failed migration", func(t *testing.T) {
+		provider := mem.NewProvider()
+		expected := errors.New("test")
+		fail := true
+
+		r, err := New(
+			Step{Version: 1, Migrate: func(storage.Provider) error { return nil }},
+			Step{Version: 2, Migrate: func(storage.Provider) error {
+				if fail {
+					return expected
+				}
+
+				return nil
+			}},
+			Step{Version: 3, Migrate: func(storage.Provider) error { return nil }},
+		)
+		require.NoError(t, err)
+
+		applied, err := r.Run(provider)
+		require.True(t, errors.Is(err, expected))
+		require.Contains(t, err.Error(), "migration 2 failed")
+		require.Equal(t, []uint64{1}, applied)
+
+		fail = false
+
+		applied, err = r.Run(provider)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{2, 3}, applied)
+	})
+
+	t.Run("test store errors", func(t *testing.T) {
+		expected := errors.New("test")
+		r, err := New(Step{Version: 1, Migrate: func(storage.Provider) error { return nil }})
+		require.NoError(t, err)
+
+		_, err = r.Run(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: expected})
+		require.True(t, errors.Is(err, expected))
+
+		_, err = Applied(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: expected})
+		require.True(t, errors.Is(err, expected))
+
+		_, err = r.Run(mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store:  map[string][]byte{},
+			ErrGet: expected,
+		}))
+		require.True(t, errors.Is(err, expected))
+
+		_, err = r.Run(mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store:  map[string][]byte{},
+			ErrPut: expected,
+		}))
+		require.True(t, errors.Is(err, expected))
+		require.Contains(t, err.Error(), "failed to save migration 1 record")
+	})
+}
+
+func TestRunner_Register(t *testing.T) {
+	noop := func(storage.Provider) error { return nil }
+
+	_, err := New(Step{Version: 0, Migrate: noop})
+	require.EqualError(t, err, "migration version must be greater than 0")
+
+	_, err = New(Step{Version: 1})
+	require.EqualError(t, err, "migration 1 has no migrate function")
+
+	_, err = New(Step{Version: 1, Migrate: noop}, Step{Version: 1, Migrate: noop})
+	require.EqualError(t, err, "migration 1 is already registered")
+}
@@ -6,11 +6,19 @@ SPDX-License-Identifier: Apache-2.0
 
 package storage
 
-import "errors"
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
 
 // ErrDataNotFound is returned when data not found
 var ErrDataNotFound = errors.New("data not found")
 
+// ErrVersionConflict is returned when a record is not stored because its current version is not the expected one
+var ErrVersionConflict = errors.New("version conflict")
+
 // Provider storage provider interface
 type Provider interface {
 	// OpenStore opens a store with given name space and returns the handle
@@ -48,6 +56,58 @@ type Store interface {
 	Delete(k string) error
 }
 
+// VersionedStore is a store supporting optimistic concurrency: a record is updated only if it was not
+// updated since it was read. The version of a record is computed from its value (see Version).
+// Implementing VersionedStore is optional for stores (see PutIfMatch).
+type VersionedStore interface {
+	Store
+
+	// PutIfMatch stores the key and the record only if the version of the current record matches expectedVersion,
+	// or if there is no current record and expectedVersion is nil. It returns ErrVersionConflict otherwise.
+	PutIfMatch(k string, v, expectedVersion []byte) error
+}
+
+// Version returns the version of the record value v.
+func Version(v []byte) []byte {
+	version := sha256.Sum256(v)
+
+	return version[:]
+}
+
+// MatchVersion checks that current, the current record value or nil if there is none, matches expectedVersion.
+// It returns an error wrapping ErrVersionConflict if it doesn't.
+func MatchVersion(current, expectedVersion []byte) error {
+	if current == nil && expectedVersion == nil {
+		return nil
+	}
+
+	if current == nil || !bytes.Equal(Version(current), expectedVersion) {
+		return fmt.Errorf("%w: record was updated or removed concurrently", ErrVersionConflict)
+	}
+
+	return nil
+}
+
+// PutIfMatch stores the key and the record in store if the version of the current record matches expectedVersion
+// (see VersionedStore). The version check is atomic only if store implements VersionedStore, it is done by
+// reading the current record otherwise.
+func PutIfMatch(store Store, k string, v, expectedVersion []byte) error {
+	if versioned, ok := store.(VersionedStore); ok {
+		return versioned.PutIfMatch(k, v, expectedVersion)
+	}
+
+	current, err := store.Get(k)
+	if err != nil && !errors.Is(err, ErrDataNotFound) {
+		return err
+	}
+
+	if err = MatchVersion(current, expectedVersion); err != nil {
+		return err
+	}
+
+	return store.Put(k, v)
+}
+
 // StoreIterator is the iterator for the latest snapshot of the underlying store.
 type StoreIterator interface {
 	// Next moves the iterator to the next key/value pair.
@@ -10,12 +10,12 @@
 package connection
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/codec"
 )
 
 const (
@@ -41,6 +41,12 @@ type provider interface {
 	StorageProvider() storage.Provider
 }
 
+// codecProvider is implemented by the providers configuring the codec of the connection records,
+// they are encoded as JSON otherwise.
+type codecProvider interface {
+	StorageCodec() codec.Codec
+}
+
 // Record contain info about did exchange connection
 type Record struct {
 	ConnectionID    string
@@ -57,6 +63,12 @@ type Record struct {
 	InvitationDID   string
 	Implicit        bool
 	Namespace       string
+	// DIDCommVersion is the DIDComm plaintext message structure ("v1" or "v2") used for outbound messages,
+	// v1 is used if empty
+	DIDCommVersion string
+	// Version is the version of the record read from the store, the record is saved only if it was not updated
+	// since it was read (see Recorder.SaveConnectionRecord). It is nil for new records.
+	Version []byte `json:"-"`
 }
 
 // NewLookup returns new connection lookup instance.
@@ -72,23 +84,30 @@ func NewLookup(p provider) (*Lookup, error) {
 		return nil, fmt.Errorf("failed to open transient store to create new connection recorder: %w", err)
 	}
 
-	return &Lookup{transientStore: transientStore, store: store}, nil
+	var recordCodec codec.Codec
+
+	if cp, ok := p.(codecProvider); ok {
+		recordCodec = cp.StorageCodec()
+	}
+
+	return &Lookup{
+		transientStore: codec.NewStore(transientStore, recordCodec),
+		store:          codec.NewStore(store, recordCodec),
+	}, nil
 }
 
 // Lookup takes care of connection related persistence features
 type Lookup struct {
-	transientStore storage.Store
-	store          storage.Store
+	transientStore *codec.Store
+	store          *codec.Store
 }
 
 // GetConnectionRecord return connection record based on the connection ID
 func (c *Lookup) GetConnectionRecord(connectionID string) (*Record, error) {
-	var rec Record
-
-	err := getAndUnmarshal(getConnectionKeyPrefix()(connectionID), &rec, c.store)
+	rec, err := getRecord(getConnectionKeyPrefix()(connectionID), c.store)
 	if err != nil {
 		if errors.Is(err, storage.ErrDataNotFound) {
-			err = getAndUnmarshal(getConnectionKeyPrefix()(connectionID), &rec, c.transientStore)
+			rec, err = getRecord(getConnectionKeyPrefix()(connectionID), c.transientStore)
 			if err != nil {
 				return nil, err
 			}
@@ -97,7 +116,7 @@ func (c *Lookup) GetConnectionRecord(connectionID string) (*Record, error) {
 		}
 	}
 
-	return &rec, nil
+	return rec, nil
 }
 
 // QueryConnectionRecords returns connection records found in underlying store
@@ -116,11 +135,13 @@ func (c *Lookup) QueryConnectionRecords() ([]*Record, error) {
 	for itr.Next() {
 		var record Record
 
-		err := json.Unmarshal(itr.Value(), &record)
+		err := c.store.Codec().Unmarshal(itr.Value(), &record)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query connection records, %w", err)
 		}
 
+		record.Version = storage.Version(itr.Value())
+
 		keys[string(itr.Key())] = struct{}{}
 
 		records = append(records, &record)
@@ -137,30 +158,54 @@ func (c *Lookup) QueryConnectionRecords() ([]*Record, error) {
 
 		var record Record
 
-		if err := json.Unmarshal(transientItr.Value(), &record); err != nil {
+		if err := c.transientStore.Codec().Unmarshal(transientItr.Value(), &record); err != nil {
 			return nil, fmt.Errorf("query connection records from transient store : %w", err)
 		}
 
+		record.Version = storage.Version(transientItr.Value())
+
 		records = append(records, &record)
 	}
 
 	return records, nil
 }
 
+// FindConnectionsByEndpoint returns the connection records whose service endpoint of the other party starts with
+// endpoint, an endpoint matches itself and the endpoints it is a prefix of (eg: "http://agent.example.com" matches
+// "http://agent.example.com:8080/inbound").
+func (c *Lookup) FindConnectionsByEndpoint(endpoint string) ([]*Record, error) {
+	if endpoint == "" {
+		return nil, errors.New("endpoint can't be empty")
+	}
+
+	records, err := c.QueryConnectionRecords()
+	if err != nil {
+		return nil, fmt.Errorf("find connections by endpoint : %w", err)
+	}
+
+	var found []*Record
+
+	for _, record := range records {
+		if strings.HasPrefix(record.ServiceEndPoint, endpoint) {
+			found = append(found, record)
+		}
+	}
+
+	return found, nil
+}
+
 // GetConnectionRecordAtState return connection record based on the connection ID and state.
 func (c *Lookup) GetConnectionRecordAtState(connectionID, stateID string) (*Record, error) {
 	if stateID == "" {
 		return nil, errors.New(stateIDEmptyErr)
 	}
 
-	var rec Record
-
-	err := getAndUnmarshal(getConnectionStateKeyPrefix()(connectionID, stateID), &rec, c.transientStore)
+	rec, err := getRecord(getConnectionStateKeyPrefix()(connectionID, stateID), c.transientStore)
 	if err != nil {
 		return nil, fmt.Errorf("faild to get connection record by state : %s, cause : %w", stateID, err)
 	}
 
-	return &rec, nil
+	return rec, nil
 }
 
 // GetConnectionRecordByNSThreadID return connection record via namespaced threadID
@@ -170,14 +215,12 @@ func (c *Lookup) GetConnectionRecordByNSThreadID(nsThreadID string) (*Record, er
 		return nil, fmt.Errorf("get connectionID by namespaced threadID: %w", err)
 	}
 
-	var rec Record
-
-	err = getAndUnmarshal(getConnectionKeyPrefix()(string(connectionIDBytes)), &rec, c.transientStore)
+	rec, err := getRecord(getConnectionKeyPrefix()(string(connectionIDBytes)), c.transientStore)
 	if err != nil {
 		return nil, fmt.Errorf("faild to get connection record by NS thread ID : %s, cause : %w", nsThreadID, err)
 	}
 
-	return &rec, nil
+	return rec, nil
 }
 
 // GetConnectionIDByDIDs return connection id based on dids (my or their did) metadata.
@@ -190,6 +233,21 @@ func (c *Lookup) GetConnectionIDByDIDs(myDID, theirDID string) (string, error) {
 	return string(connectionIDBytes), nil
 }
 
+// GetDIDCommVersionByDIDs returns the DIDComm version selected for the connection between the dids.
+func (c *Lookup) GetDIDCommVersionByDIDs(myDID, theirDID string) (string, error) {
+	connectionID, err := c.GetConnectionIDByDIDs(myDID, theirDID)
+	if err != nil {
+		return "", err
+	}
+
+	record, err := c.GetConnectionRecord(connectionID)
+	if err != nil {
+		return "", fmt.Errorf("get connection record : %w", err)
+	}
+
+	return record.DIDCommVersion, nil
+}
+
 // GetInvitation finds and parses stored invitation to target type
 // TODO should avoid using target of type `interface{}` [Issue #1030]
 func (c *Lookup) GetInvitation(id string, target interface{}) error {
@@ -197,7 +255,7 @@ func (c *Lookup) GetInvitation(id string, target interface{}) error {
 		return fmt.Errorf(errMsgInvalidKey)
 	}
 
-	return getAndUnmarshal(getInvitationKeyPrefix()(id), target, c.store)
+	return c.store.GetRecord(getInvitationKeyPrefix()(id), target)
 }
 
 // GetEvent returns persisted event data for given connection ID
@@ -210,18 +268,23 @@ func (c *Recorder) GetEvent(connectionID string) ([]byte, error) {
 	return c.transientStore.Get(getEventDataKeyPrefix()(connectionID))
 }
 
-func getAndUnmarshal(key string, target interface{}, store storage.Store) error {
+// getRecord gets the connection record stored under key, along with its version
+func getRecord(key string, store *codec.Store) (*Record, error) {
 	bytes, err := store.Get(key)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = json.Unmarshal(bytes, target)
+	var rec Record
+
+	err = store.Codec().Unmarshal(bytes, &rec)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	rec.Version = storage.Version(bytes)
+
+	return &rec, nil
 }
 
 // getConnectionKeyPrefix key prefix for connection record persisted
@@ -11,6 +11,7 @@ package connection
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -344,6 +345,82 @@ func TestConnectionRecorder_QueryConnectionRecord(t *testing.T) {
 	})
 }
 
+func TestConnectionReader_FindConnectionsByEndpoint(t *testing.T) {
+	endpoints := map[string]string{
+		"conn1": "http://alice.example.com:8080/inbound",
+		"conn2": "http://alice.example.com:9090",
+		"conn3": "https://bob.example.com/didcomm",
+	}
+
+	saveInStore := func(t *testing.T, store storage.Store) {
+		for connectionID, endpoint := range endpoints {
+			connRecBytes, err := json.Marshal(&Record{ConnectionID: connectionID, ServiceEndPoint: endpoint})
+			require.NoError(t, err)
+
+			require.NoError(t, store.Put(getConnectionKeyPrefix()(connectionID), connRecBytes))
+		}
+	}
+
+	connectionIDs := func(records []*Record) []string {
+		ids := make([]string, 0, len(records))
+
+		for _, record := range records {
+			ids = append(ids, record.ConnectionID)
+		}
+
+		return ids
+	}
+
+	t.Run("find connections by exact and prefix endpoint", func(t *testing.T) {
+		lookup, err := NewLookup(&mockProvider{})
+		require.NoError(t, err)
+
+		saveInStore(t, lookup.store)
+
+		records, err := lookup.FindConnectionsByEndpoint("https://bob.example.com/didcomm")
+		require.NoError(t, err)
+		require.Equal(t, []string{"conn3"}, connectionIDs(records))
+
+		records, err = lookup.FindConnectionsByEndpoint("http://alice.example.com")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"conn1", "conn2"}, connectionIDs(records))
+
+		records, err = lookup.FindConnectionsByEndpoint("http://")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"conn1", "conn2"}, connectionIDs(records))
+
+		records, err = lookup.FindConnectionsByEndpoint("http://carol.example.com")
+		require.NoError(t, err)
+		require.Empty(t, records)
+	})
+
+	t.Run("find connections in transient store", func(t *testing.T) {
+		lookup, err := NewLookup(&mockProvider{})
+		require.NoError(t, err)
+
+		saveInStore(t, lookup.transientStore)
+
+		records, err := lookup.FindConnectionsByEndpoint("https://bob.example.com")
+		require.NoError(t, err)
+		require.Equal(t, []string{"conn3"}, connectionIDs(records))
+	})
+
+	t.Run("find connections by endpoint - error", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: make(map[string][]byte)}
+		require.NoError(t, store.Put(getConnectionKeyPrefix()("conn1"), []byte("-----")))
+
+		lookup, err := NewLookup(&mockProvider{store: store})
+		require.NoError(t, err)
+
+		_, err = lookup.FindConnectionsByEndpoint("http://alice.example.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "find connections by endpoint")
+
+		_, err = lookup.FindConnectionsByEndpoint("")
+		require.EqualError(t, err, "endpoint can't be empty")
+	})
+}
+
 func TestGetConnectionIDByDIDs(t *testing.T) {
 	myDID := "did:mydid:123"
 	theirDID := "did:theirdid:789"
@@ -380,6 +457,54 @@ func TestGetConnectionIDByDIDs(t *testing.T) {
 	})
 }
 
+func TestGetDIDCommVersionByDIDs(t *testing.T) {
+	myDID := "did:mydid:123"
+	theirDID := "did:theirdid:789"
+
+	t.Run("get DIDComm version by did - success", func(t *testing.T) {
+		recorder, err := NewRecorder(&protocol.MockProvider{})
+		require.NoError(t, err)
+
+		connRec := &Record{
+			ThreadID:       threadIDValue,
+			ConnectionID:   sampleConnID,
+			State:          stateNameCompleted,
+			Namespace:      myNSPrefix,
+			MyDID:          myDID,
+			TheirDID:       theirDID,
+			DIDCommVersion: "v2",
+		}
+		require.NoError(t, recorder.SaveConnectionRecord(connRec))
+
+		version, err := recorder.GetDIDCommVersionByDIDs(myDID, theirDID)
+		require.NoError(t, err)
+		require.Equal(t, "v2", version)
+	})
+
+	t.Run("get DIDComm version by did - no mapping found", func(t *testing.T) {
+		recorder, err := NewRecorder(&protocol.MockProvider{})
+		require.NoError(t, err)
+
+		version, err := recorder.GetDIDCommVersionByDIDs(myDID, theirDID)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+		require.Empty(t, version)
+	})
+
+	t.Run("get DIDComm version by did - connection record not found", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{
+			getDIDConnMapKeyPrefix()(myDID, theirDID): []byte(sampleConnID),
+		}}
+
+		lookup, err := NewLookup(&mockProvider{store: store})
+		require.NoError(t, err)
+
+		version, err := lookup.GetDIDCommVersionByDIDs(myDID, theirDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get connection record")
+		require.Empty(t, version)
+	})
+}
+
 // mockProvider for connection recorder
 type mockProvider struct {
 	transientStoreError error
@@ -11,11 +11,11 @@ package connection
 
 import (
 	"crypto"
-	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/codec"
 )
 
 const (
@@ -54,37 +54,83 @@ func (c *Recorder) SaveInvitation(id string, invitation interface{}) error {
 	return marshalAndSave(getInvitationKeyPrefix()(id), invitation, c.store)
 }
 
-// SaveConnectionRecord saves given connection records in underlying store
+// RemoveInvitation removes the invitation saved with SaveInvitation for given key
+func (c *Recorder) RemoveInvitation(id string) error {
+	if id == "" {
+		return fmt.Errorf(errMsgInvalidKey)
+	}
+
+	return c.store.Delete(getInvitationKeyPrefix()(id))
+}
+
+// SaveConnectionRecord saves given connection records in underlying store.
+// If the record was read from the store (record.Version is set), it is saved only if it was not updated since,
+// an error wrapping storage.ErrVersionConflict is returned otherwise. record.Version is set to the saved version.
 func (c *Recorder) SaveConnectionRecord(record *Record) error {
-	if err := marshalAndSave(getConnectionKeyPrefix()(record.ConnectionID),
-		record, c.transientStore); err != nil {
-		return fmt.Errorf("save connection record in transient store: %w", err)
+	bytes, err := c.store.Codec().Marshal(record)
+	if err != nil {
+		return fmt.Errorf("save connection record: %w", err)
+	}
+
+	key := getConnectionKeyPrefix()(record.ConnectionID)
+
+	updated, err := c.putIfMatch(key, bytes, record.Version)
+	if err != nil {
+		return fmt.Errorf("save connection record: %w", err)
+	}
+
+	if updated != c.transientStore {
+		if err = c.transientStore.Put(key, bytes); err != nil {
+			return fmt.Errorf("save connection record in transient store: %w", err)
+		}
 	}
 
 	if record.State != "" {
-		err := marshalAndSave(getConnectionStateKeyPrefix()(record.ConnectionID, record.State),
-			record, c.transientStore)
+		err = c.transientStore.Put(getConnectionStateKeyPrefix()(record.ConnectionID, record.State), bytes)
 		if err != nil {
 			return fmt.Errorf("save connection record with state in transient store: %w", err)
 		}
 	}
 
 	if record.State == stateNameCompleted {
-		if err := marshalAndSave(getConnectionKeyPrefix()(record.ConnectionID),
-			record, c.store); err != nil {
-			return fmt.Errorf("save connection record in permanent store: %w", err)
+		if updated != c.store {
+			if err = c.store.Put(key, bytes); err != nil {
+				return fmt.Errorf("save connection record in permanent store: %w", err)
+			}
 		}
 
 		// create map between DIDs and ConnectionID
-		if err := c.store.Put(getDIDConnMapKeyPrefix()(record.MyDID, record.TheirDID),
+		if err = c.store.Put(getDIDConnMapKeyPrefix()(record.MyDID, record.TheirDID),
 			[]byte(record.ConnectionID)); err != nil {
 			return fmt.Errorf("save did and connection map in store: %w", err)
 		}
 	}
 
+	record.Version = storage.Version(bytes)
+
 	return nil
 }
 
+// putIfMatch saves the record value v under key k, if version is set, in the store holding the record read with
+// this version (see GetConnectionRecord): the permanent store if the record is there, the transient store otherwise.
+// It returns the updated store, or nil if version is not set.
+func (c *Recorder) putIfMatch(k string, v, version []byte) (storage.Store, error) {
+	if version == nil {
+		return nil, nil
+	}
+
+	target := c.transientStore
+
+	_, err := c.store.Get(k)
+	if err == nil {
+		target = c.store
+	} else if !errors.Is(err, storage.ErrDataNotFound) {
+		return nil, err
+	}
+
+	return target, storage.PutIfMatch(target, k, v, version)
+}
+
 // SaveConnectionRecordWithMappings saves newly created connection record against the connection id in the store
 // and it creates mapping from namespaced ThreadID to connection ID
 func (c *Recorder) SaveConnectionRecordWithMappings(record *Record) error {
@@ -169,13 +215,12 @@ func (c *Recorder) RemoveConnection(connectionID string) error {
 	return nil
 }
 
-func marshalAndSave(k string, v interface{}, store storage.Store) error {
-	bytes, err := json.Marshal(v)
-	if err != nil {
+func marshalAndSave(k string, v interface{}, store *codec.Store) error {
+	if err := store.PutRecord(k, v); err != nil {
 		return fmt.Errorf("save connection record: %w", err)
 	}
 
-	return store.Put(k, bytes)
+	return nil
 }
 
 // isValidConnection validates connection record
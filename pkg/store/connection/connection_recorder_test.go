@@ -10,7 +10,11 @@
 package connection
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
 	"testing"
 
 	"github.com/google/uuid"
@@ -20,6 +24,9 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol"
 	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/codec"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/leveldb"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
 )
 
 const (
@@ -134,7 +141,7 @@ func Test_RemoveConnectionRecordsForStates(t *testing.T) {
 		}
 
 		err := marshalAndSave(getConnectionStateKeyPrefix()(record.ConnectionID, record.State),
-			record, store)
+			record, codec.NewStore(store, nil))
 		require.NoError(t, err)
 
 		recorder, err := NewRecorder(&protocol.MockProvider{
@@ -181,7 +188,7 @@ func Test_RemoveConnectionRecordsForStates(t *testing.T) {
 		}
 
 		err := marshalAndSave(getConnectionStateKeyPrefix()(record.ConnectionID, record.State),
-			record, store)
+			record, codec.NewStore(store, nil))
 		require.NoError(t, err)
 
 		recorder, err := NewRecorder(&protocol.MockProvider{
@@ -225,12 +232,12 @@ func TestConnectionStore_SaveInvitation(t *testing.T) {
 		require.NotEmpty(t, v)
 
 		var v1 mockInvitation
-		err = getAndUnmarshal(k, &v1, recorder.store)
+		err = recorder.store.GetRecord(k, &v1)
 		require.NoError(t, err)
 		require.Equal(t, value, &v1)
 
 		var v2 mockInvitation
-		err = getAndUnmarshal(k, &v2, recorder.transientStore)
+		err = recorder.transientStore.GetRecord(k, &v2)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "data not found")
 	})
@@ -252,6 +259,31 @@ func TestConnectionStore_SaveInvitation(t *testing.T) {
 	})
 }
 
+func TestConnectionStore_RemoveInvitation(t *testing.T) {
+	store := &mockstorage.MockStore{Store: make(map[string][]byte)}
+	recorder, err := NewRecorder(&protocol.MockProvider{
+		StoreProvider: mockstorage.NewCustomMockStoreProvider(store),
+	})
+	require.NoError(t, err)
+
+	value := &mockInvitation{
+		ID:    "sample-inv-id",
+		Label: "sample-label1",
+	}
+
+	require.NoError(t, recorder.SaveInvitation(value.ID, value))
+	require.NoError(t, recorder.GetInvitation(value.ID, &mockInvitation{}))
+
+	require.NoError(t, recorder.RemoveInvitation(value.ID))
+
+	err = recorder.GetInvitation(value.ID, &mockInvitation{})
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	err = recorder.RemoveInvitation("")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid key")
+}
+
 func TestConnectionStore_GetInvitation(t *testing.T) {
 	t.Run("test get invitation - success", func(t *testing.T) {
 		recorder, err := NewRecorder(&protocol.MockProvider{})
@@ -385,15 +417,13 @@ func TestConnectionRecorder_SaveConnectionRecord(t *testing.T) {
 		require.Equal(t, record, recordFound)
 
 		// make sure it exists only in transient store
-		var r1 Record
-		err = getAndUnmarshal(getConnectionKeyPrefix()(record.ConnectionID), &r1, recorder.store)
+		_, err = getRecord(getConnectionKeyPrefix()(record.ConnectionID), recorder.store)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "data not found")
 
-		var r2 Record
-		err = getAndUnmarshal(getConnectionKeyPrefix()(record.ConnectionID), &r2, recorder.transientStore)
+		r2, err := getRecord(getConnectionKeyPrefix()(record.ConnectionID), recorder.transientStore)
 		require.NoError(t, err)
-		require.Equal(t, record, &r2)
+		require.Equal(t, record, r2)
 	})
 
 	t.Run("save connection record with invited state - completed", func(t *testing.T) {
@@ -418,15 +448,13 @@ func TestConnectionRecorder_SaveConnectionRecord(t *testing.T) {
 		require.Equal(t, record, recordFound)
 
 		// make sure it exists only in both permanent and transient store
-		var r1 Record
-		err = getAndUnmarshal(getConnectionKeyPrefix()(record.ConnectionID), &r1, recorder.transientStore)
+		r1, err := getRecord(getConnectionKeyPrefix()(record.ConnectionID), recorder.transientStore)
 		require.NoError(t, err)
-		require.Equal(t, record, &r1)
+		require.Equal(t, record, r1)
 
-		var r2 Record
-		err = getAndUnmarshal(getConnectionKeyPrefix()(record.ConnectionID), &r2, recorder.store)
+		r2, err := getRecord(getConnectionKeyPrefix()(record.ConnectionID), recorder.store)
 		require.NoError(t, err)
-		require.Equal(t, record, &r2)
+		require.Equal(t, record, r2)
 	})
 
 	t.Run("save connection record error scenario 1", func(t *testing.T) {
@@ -486,13 +514,11 @@ func TestConnectionRecorder_RemoveConnection(t *testing.T) {
 		require.NoError(t, err)
 
 		// make sure no records exist in both permanent and transient store
-		var r1 Record
-		err = getAndUnmarshal(getConnectionKeyPrefix()(record.ConnectionID), &r1, recorder.transientStore)
+		_, err = getRecord(getConnectionKeyPrefix()(record.ConnectionID), recorder.transientStore)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "data not found")
 
-		var r2 Record
-		err = getAndUnmarshal(getConnectionKeyPrefix()(record.ConnectionID), &r2, recorder.store)
+		_, err = getRecord(getConnectionKeyPrefix()(record.ConnectionID), recorder.store)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "data not found")
 
@@ -507,7 +533,7 @@ func TestConnectionRecorder_RemoveConnection(t *testing.T) {
 		}
 
 		var r3 Record
-		err = getAndUnmarshal(getDIDConnMapKeyPrefix()(record.MyDID, record.TheirDID), &r3, recorder.store)
+		err = recorder.store.GetRecord(getDIDConnMapKeyPrefix()(record.MyDID, record.TheirDID), &r3)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "data not found")
 	})
@@ -753,13 +779,13 @@ func TestConnectionRecorder_SaveAndGet(t *testing.T) {
 		store := &mockstorage.MockStore{Store: make(map[string][]byte)}
 
 		for _, record := range records {
-			err := marshalAndSave(record.ID, record, store)
+			err := marshalAndSave(record.ID, record, codec.NewStore(store, nil))
 			require.NoError(t, err)
 		}
 
 		for _, record := range records {
 			var recordFound1 mockInvitation
-			err := getAndUnmarshal(record.ID, &recordFound1, store)
+			err := codec.NewStore(store, nil).GetRecord(record.ID, &recordFound1)
 			require.NoError(t, err)
 			require.Equal(t, record, &recordFound1)
 		}
@@ -777,21 +803,21 @@ func TestConnectionRecorder_SaveAndGet(t *testing.T) {
 		require.NotEmpty(t, records)
 
 		for _, record := range records {
-			err := marshalAndSave(record.ID, record, store)
+			err := marshalAndSave(record.ID, record, codec.NewStore(store, nil))
 			require.Error(t, err)
 			require.Contains(t, err.Error(), errMsg)
 		}
 
 		for _, record := range records {
 			var recordFound1 mockInvitation
-			err := getAndUnmarshal(record.ID, &recordFound1, store)
+			err := codec.NewStore(store, nil).GetRecord(record.ID, &recordFound1)
 			require.Error(t, err)
 			require.Contains(t, err.Error(), errMsg)
 		}
 	})
 
 	t.Run("save and get in store - failure", func(t *testing.T) {
-		store := &mockstorage.MockStore{Store: make(map[string][]byte)}
+		store := codec.NewStore(&mockstorage.MockStore{Store: make(map[string][]byte)}, nil)
 
 		err := marshalAndSave("sample-id", make(chan int), store)
 		require.Error(t, err)
@@ -799,7 +825,7 @@ func TestConnectionRecorder_SaveAndGet(t *testing.T) {
 		err = marshalAndSave("sample-id", []byte("XYZ"), store)
 		require.NoError(t, err)
 
-		err = getAndUnmarshal("sample-id", make(chan int), store)
+		err = store.GetRecord("sample-id", make(chan int))
 		require.Error(t, err)
 	})
 }
@@ -815,3 +841,231 @@ type mockInvitation struct {
 	Type            string            `json:"@type,omitempty"`
 	Thread          *decorator.Thread `json:"~thread,omitempty"`
 }
+
+func TestConnectionRecorder_ConcurrentUpdate(t *testing.T) {
+	t.Run("test concurrent update of a connection record in progress", func(t *testing.T) {
+		recorder, err := NewRecorder(&protocol.MockProvider{})
+		require.NoError(t, err)
+
+		record := &Record{ThreadID: threadIDValue,
+			ConnectionID: uuid.New().String(), State: stateNameInvited, Namespace: theirNSPrefix}
+		require.NoError(t, recorder.SaveConnectionRecord(record))
+
+		first, err := recorder.GetConnectionRecord(record.ConnectionID)
+		require.NoError(t, err)
+
+		second, err := recorder.GetConnectionRecord(record.ConnectionID)
+		require.NoError(t, err)
+
+		first.State = "requested"
+		require.NoError(t, recorder.SaveConnectionRecord(first))
+
+		second.State = "responded"
+		err = recorder.SaveConnectionRecord(second)
+		require.True(t, errors.Is(err, storage.ErrVersionConflict))
+
+		stored, err := recorder.GetConnectionRecord(record.ConnectionID)
+		require.NoError(t, err)
+		require.Equal(t, "requested", stored.State)
+
+		// the record saved by the first writer can be updated again
+		first.TheirLabel = "label"
+		require.NoError(t, recorder.SaveConnectionRecord(first))
+	})
+
+	t.Run("test concurrent update of a completed connection record", func(t *testing.T) {
+		recorder, err := NewRecorder(&protocol.MockProvider{})
+		require.NoError(t, err)
+
+		record := &Record{ThreadID: threadIDValue,
+			ConnectionID: uuid.New().String(), State: stateNameCompleted, Namespace: theirNSPrefix}
+		require.NoError(t, recorder.SaveConnectionRecord(record))
+
+		// the transient store is not persistent
+		require.NoError(t, recorder.transientStore.Delete(getConnectionKeyPrefix()(record.ConnectionID)))
+
+		first, err := recorder.GetConnectionRecord(record.ConnectionID)
+		require.NoError(t, err)
+
+		second, err := recorder.GetConnectionRecord(record.ConnectionID)
+		require.NoError(t, err)
+
+		first.DIDCommVersion = "v2"
+		require.NoError(t, recorder.SaveConnectionRecord(first))
+
+		second.TheirLabel = "label"
+		err = recorder.SaveConnectionRecord(second)
+		require.True(t, errors.Is(err, storage.ErrVersionConflict))
+
+		stored, err := recorder.GetConnectionRecord(record.ConnectionID)
+		require.NoError(t, err)
+		require.Equal(t, "v2", stored.DIDCommVersion)
+		require.Empty(t, stored.TheirLabel)
+	})
+
+	t.Run("test store error", func(t *testing.T) {
+		recorder, err := NewRecorder(&protocol.MockProvider{
+			StoreProvider: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+				Store:  make(map[string][]byte),
+				ErrGet: fmt.Errorf("get error"),
+			}),
+		})
+		require.NoError(t, err)
+
+		record := &Record{ThreadID: threadIDValue, ConnectionID: uuid.New().String(),
+			State: stateNameInvited, Namespace: theirNSPrefix, Version: storage.Version([]byte("record"))}
+		err = recorder.SaveConnectionRecord(record)
+		require.EqualError(t, err, "save connection record: get error")
+	})
+}
+
+func TestConnectionRecorder_ConcurrentWriters(t *testing.T) {
+	const writers = 100
+
+	dbPath, err := ioutil.TempDir("", "connection")
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, os.RemoveAll(dbPath))
+	}()
+
+	leveldbProvider := leveldb.NewProvider(dbPath)
+
+	defer func() {
+		require.NoError(t, leveldbProvider.Close())
+	}()
+
+	providers := map[string]storage.Provider{
+		"leveldb": leveldbProvider,
+		"mem":     mem.NewProvider(),
+	}
+
+	for name, storeProvider := range providers {
+		for _, state := range []string{stateNameInvited, stateNameCompleted} {
+			storeProvider, state := storeProvider, state
+
+			t.Run(fmt.Sprintf("test concurrent writers of a %s connection record on %s", state, name), func(t *testing.T) {
+				recorder, err := NewRecorder(&storageProviders{store: storeProvider, transientStore: mem.NewProvider()})
+				require.NoError(t, err)
+
+				record := &Record{ThreadID: threadIDValue, ConnectionID: uuid.New().String(), State: state,
+					Namespace: theirNSPrefix, MyDID: uuid.New().String(), TheirDID: uuid.New().String()}
+				require.NoError(t, recorder.SaveConnectionRecord(record))
+
+				// every writer updates the record read with the same version
+				records := make([]*Record, writers)
+
+				for i := range records {
+					records[i], err = recorder.GetConnectionRecord(record.ConnectionID)
+					require.NoError(t, err)
+
+					records[i].TheirLabel = fmt.Sprintf("writer-%d", i)
+				}
+
+				var (
+					wg    sync.WaitGroup
+					start = make(chan struct{})
+					errs  = make(chan error, writers)
+				)
+
+				wg.Add(writers)
+
+				for i := range records {
+					go func(record *Record) {
+						defer wg.Done()
+
+						<-start
+
+						errs <- recorder.SaveConnectionRecord(record)
+					}(records[i])
+				}
+
+				close(start)
+				wg.Wait()
+				close(errs)
+
+				saved := 0
+
+				for err := range errs {
+					if err == nil {
+						saved++
+
+						continue
+					}
+
+					require.True(t, errors.Is(err, storage.ErrVersionConflict), err)
+				}
+
+				require.Equal(t, 1, saved)
+			})
+		}
+	}
+}
+
+type storageProviders struct {
+	store          storage.Provider
+	transientStore storage.Provider
+}
+
+func (p *storageProviders) StorageProvider() storage.Provider {
+	return p.store
+}
+
+func (p *storageProviders) TransientStorageProvider() storage.Provider {
+	return p.transientStore
+}
+
+func TestConnectionRecorder_StorageCodec(t *testing.T) {
+	for _, c := range []codec.Codec{codec.CBOR{}, codec.Protobuf{}} {
+		c := c
+
+		t.Run(fmt.Sprintf("test records are encoded with the %T codec of the provider", c), func(t *testing.T) {
+			store := mockstorage.NewMockStoreProvider()
+			transientStore := mockstorage.NewMockStoreProvider()
+
+			recorder, err := NewRecorder(&protocol.MockProvider{
+				StoreProvider:          store,
+				TransientStoreProvider: transientStore,
+				CustomCodec:            c,
+			})
+			require.NoError(t, err)
+
+			record := &Record{ThreadID: threadIDValue, ConnectionID: uuid.New().String(), State: stateNameCompleted,
+				Namespace: myNSPrefix, MyDID: "did:mydid:123", TheirDID: "did:theirdid:123",
+				RecipientKeys: []string{"key-1"}}
+			require.NoError(t, recorder.SaveConnectionRecord(record))
+
+			stored := store.Store.Store[getConnectionKeyPrefix()(record.ConnectionID)]
+
+			var decoded Record
+			require.NoError(t, c.Unmarshal(stored, &decoded))
+			require.Equal(t, record.ConnectionID, decoded.ConnectionID)
+			require.Error(t, codec.JSON{}.Unmarshal(stored, &decoded))
+
+			recordFound, err := recorder.GetConnectionRecord(record.ConnectionID)
+			require.NoError(t, err)
+			require.Equal(t, record, recordFound)
+
+			records, err := recorder.QueryConnectionRecords()
+			require.NoError(t, err)
+			require.Equal(t, []*Record{record}, records)
+
+			require.NoError(t, recorder.SaveInvitation("inv-1", &mockInvitation{ID: "inv-1"}))
+
+			var invitation mockInvitation
+			require.NoError(t, recorder.GetInvitation("inv-1", &invitation))
+			require.Equal(t, "inv-1", invitation.ID)
+		})
+	}
+
+	t.Run("test records are encoded as JSON by default", func(t *testing.T) {
+		store := mockstorage.NewMockStoreProvider()
+
+		recorder, err := NewRecorder(&protocol.MockProvider{StoreProvider: store})
+		require.NoError(t, err)
+		require.Equal(t, codec.JSON{}, recorder.store.Codec())
+
+		require.NoError(t, recorder.SaveInvitation("inv-1", &mockInvitation{ID: "inv-1"}))
+		require.JSONEq(t, `{"@id":"inv-1"}`, string(store.Store.Store[getInvitationKeyPrefix()("inv-1")]))
+	})
+}
@@ -0,0 +1,41 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+)
+
+// Serializer converts the DID documents saved in the DID store from and to the stored format.
+// Implementations may map the document fields to columns or use a different encoding, but Deserialize must
+// restore the exact document given to Serialize.
+type Serializer interface {
+	Serialize(doc *did.Doc) ([]byte, error)
+	Deserialize(data []byte) (*did.Doc, error)
+}
+
+// JSONSerializer is the default Serializer, storing DID documents in their JSON-LD representation.
+type JSONSerializer struct{}
+
+// Serialize returns the JSON-LD representation of the DID document.
+func (JSONSerializer) Serialize(doc *did.Doc) ([]byte, error) {
+	return doc.JSONBytes()
+}
+
+// Deserialize parses the JSON-LD representation of the DID document.
+func (JSONSerializer) Deserialize(data []byte) (*did.Doc, error) {
+	return did.ParseDocument(data)
+}
+
+// Option configures the DID store.
+type Option func(opts *Store)
+
+// WithSerializer option is for setting the serializer of the DID documents, JSONSerializer is used by default.
+func WithSerializer(serializer Serializer) Option {
+	return func(opts *Store) {
+		opts.serializer = serializer
+	}
+}
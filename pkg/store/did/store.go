@@ -6,7 +6,6 @@ SPDX-License-Identifier: Apache-2.0
 package did
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -30,7 +29,8 @@ var ErrDIDNotFound = errors.New("did not found under given key")
 
 // Store stores did doc
 type Store struct {
-	store storage.Store
+	store      storage.Store
+	serializer Serializer
 }
 
 type provider interface {
@@ -38,13 +38,19 @@ type provider interface {
 }
 
 // New returns a new did store
-func New(ctx provider) (*Store, error) {
+func New(ctx provider, opts ...Option) (*Store, error) {
 	store, err := ctx.StorageProvider().OpenStore(NameSpace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open did store: %w", err)
 	}
 
-	return &Store{store: store}, nil
+	s := &Store{store: store, serializer: JSONSerializer{}}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
 }
 
 // SaveDID saves a did doc.
@@ -62,7 +68,7 @@ func (s *Store) SaveDID(name string, didDoc *did.Doc) error {
 		return errors.New("did name already exists")
 	}
 
-	docBytes, err := didDoc.JSONBytes()
+	docBytes, err := s.serializer.Serialize(didDoc)
 	if err != nil {
 		return fmt.Errorf("failed to marshal didDoc: %w", err)
 	}
@@ -85,9 +91,7 @@ func (s *Store) GetDID(id string) (*did.Doc, error) {
 		return nil, fmt.Errorf("failed to get did doc: %w", err)
 	}
 
-	didDoc := &did.Doc{}
-	err = json.Unmarshal(docBytes, didDoc)
-
+	didDoc, err := s.serializer.Deserialize(docBytes)
 	if err != nil {
 		return nil, fmt.Errorf("umarshalling didDoc failed: %w", err)
 	}
@@ -6,8 +6,10 @@ SPDX-License-Identifier: Apache-2.0
 package did
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/gob"
 	"fmt"
 	"strconv"
 
@@ -215,6 +217,79 @@ func TestGetCredentials(t *testing.T) {
 	})
 }
 
+// gobSerializer is a custom serializer storing the DID documents in gob encoding.
+type gobSerializer struct{}
+
+func (gobSerializer) Serialize(doc *did.Doc) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := gob.NewEncoder(&buf).Encode(doc)
+
+	return buf.Bytes(), err
+}
+
+func (gobSerializer) Deserialize(data []byte) (*did.Doc, error) {
+	doc := &did.Doc{}
+
+	return doc, gob.NewDecoder(bytes.NewReader(data)).Decode(doc)
+}
+
+func TestSerializer(t *testing.T) {
+	t.Run("test custom serializer round-trips a full did doc", func(t *testing.T) {
+		store := make(map[string][]byte)
+		s, err := New(&mockprovider.Provider{
+			StorageProviderValue: &mockstore.MockStoreProvider{Store: &mockstore.MockStore{Store: store}},
+		}, WithSerializer(gobSerializer{}))
+		require.NoError(t, err)
+
+		didDoc := createDIDDoc()
+		// strip the monotonic clock reading which is not serialized
+		created := didDoc.Created.Round(0)
+		didDoc.Created = &created
+		didDoc.Updated = &created
+		didDoc.Authentication = []did.VerificationMethod{{PublicKey: didDoc.PublicKey[0]}}
+		didDoc.Proof = []did.Proof{{
+			Type:       "Ed25519Signature2018",
+			Created:    &created,
+			Creator:    didDoc.PublicKey[0].ID,
+			ProofValue: []byte("proof"),
+			Domain:     "example.com",
+			Nonce:      []byte("nonce"),
+		}}
+
+		require.NoError(t, s.SaveDID(sampleDIDName, didDoc))
+
+		expected, err := gobSerializer{}.Serialize(didDoc)
+		require.NoError(t, err)
+		require.Equal(t, expected, store[didDoc.ID])
+
+		doc, err := s.GetDID(didDoc.ID)
+		require.NoError(t, err)
+		require.Equal(t, didDoc, doc)
+	})
+
+	t.Run("test default json serializer", func(t *testing.T) {
+		store := make(map[string][]byte)
+		s, err := New(&mockprovider.Provider{
+			StorageProviderValue: &mockstore.MockStoreProvider{Store: &mockstore.MockStore{Store: store}},
+		})
+		require.NoError(t, err)
+
+		didDoc := createDIDDoc()
+		require.NoError(t, s.SaveDID(sampleDIDName, didDoc))
+
+		expected, err := didDoc.JSONBytes()
+		require.NoError(t, err)
+		require.Equal(t, expected, store[didDoc.ID])
+
+		doc, err := s.GetDID(didDoc.ID)
+		require.NoError(t, err)
+		require.Equal(t, didDoc.ID, doc.ID)
+		require.Equal(t, didDoc.PublicKey[0].Value, doc.PublicKey[0].Value)
+		require.Equal(t, didDoc.Service[0].ServiceEndpoint, doc.Service[0].ServiceEndpoint)
+	})
+}
+
 func createDIDDoc() *did.Doc {
 	pubKey, _ := generateKeyPair()
 	return createDIDDocWithKey(pubKey)
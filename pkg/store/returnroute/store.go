@@ -0,0 +1,187 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package returnroute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// NameSpace for the return route store
+	NameSpace = "returnroute"
+
+	// DefaultTTL is the default time to live of the reply destinations.
+	DefaultTTL = 10 * time.Minute
+
+	routeKeyPrefix = "route_"
+)
+
+// ErrNotFound signals that there is no reply destination for the thread, or that it expired.
+var ErrNotFound = errors.New("reply destination not found")
+
+// Store stores the reply destinations of connectionless messages (see the ~service decorator) by thread ID, until
+// the reply is sent.
+type Store interface {
+	// Put saves the reply destination of the thread threadID.
+	Put(threadID string, destination *service.Destination) error
+
+	// Get returns the reply destination of the thread threadID, or an error wrapping ErrNotFound.
+	Get(threadID string) (*service.Destination, error)
+
+	// Delete removes the reply destination of the thread threadID.
+	Delete(threadID string) error
+}
+
+// route is a stored reply destination.
+type route struct {
+	Destination *service.Destination `json:"destination"`
+	Expires     time.Time            `json:"expires"`
+}
+
+// TTLStore is a Store of short-lived reply destinations, kept in the transient storage: the destinations expire
+// after the TTL of the store.
+type TTLStore struct {
+	store storage.Store
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// Option configures the TTLStore.
+type Option func(opts *TTLStore)
+
+// WithTTL option sets the time to live of the reply destinations, DefaultTTL by default.
+func WithTTL(ttl time.Duration) Option {
+	return func(opts *TTLStore) {
+		opts.ttl = ttl
+	}
+}
+
+type provider interface {
+	TransientStorageProvider() storage.Provider
+}
+
+// New returns a new TTLStore.
+func New(ctx provider, opts ...Option) (*TTLStore, error) {
+	store, err := ctx.TransientStorageProvider().OpenStore(NameSpace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open return route store: %w", err)
+	}
+
+	s := &TTLStore{store: store, ttl: DefaultTTL, now: time.Now}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// Put saves the reply destination of the thread threadID, it expires after the TTL of the store.
+func (s *TTLStore) Put(threadID string, destination *service.Destination) error {
+	if threadID == "" {
+		return errors.New("thread ID is mandatory")
+	}
+
+	bytes, err := json.Marshal(&route{Destination: destination, Expires: s.now().Add(s.ttl).UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal reply destination: %w", err)
+	}
+
+	if err := s.store.Put(routeKeyPrefix+threadID, bytes); err != nil {
+		return fmt.Errorf("failed to save reply destination: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the reply destination of the thread threadID, or an error wrapping ErrNotFound if there is none or
+// if it expired. Expired destinations are removed.
+func (s *TTLStore) Get(threadID string) (*service.Destination, error) {
+	bytes, err := s.store.Get(routeKeyPrefix + threadID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return nil, fmt.Errorf("%w: thread %s", ErrNotFound, threadID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reply destination: %w", err)
+	}
+
+	r := &route{}
+
+	if err := json.Unmarshal(bytes, r); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reply destination: %w", err)
+	}
+
+	if !s.now().Before(r.Expires) {
+		if err := s.Delete(threadID); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: thread %s expired at %s", ErrNotFound, threadID, r.Expires.Format(time.RFC3339))
+	}
+
+	return r.Destination, nil
+}
+
+// Delete removes the reply destination of the thread threadID, eg: once the reply is sent.
+func (s *TTLStore) Delete(threadID string) error {
+	if err := s.store.Delete(routeKeyPrefix + threadID); err != nil {
+		return fmt.Errorf("failed to delete reply destination: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveExpired removes the expired reply destinations and returns their thread IDs. Expired destinations are
+// otherwise removed when they are read.
+func (s *TTLStore) RemoveExpired() ([]string, error) {
+	itr := s.store.Iterator(routeKeyPrefix, routeKeyPrefix+"~")
+	defer itr.Release()
+
+	var expired []string
+
+	for itr.Next() {
+		r := &route{}
+
+		if err := json.Unmarshal(itr.Value(), r); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal reply destination: %w", err)
+		}
+
+		if !s.now().Before(r.Expires) {
+			expired = append(expired, strings.TrimPrefix(string(itr.Key()), routeKeyPrefix))
+		}
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate reply destinations: %w", err)
+	}
+
+	for _, threadID := range expired {
+		if err := s.Delete(threadID); err != nil {
+			return nil, err
+		}
+	}
+
+	return expired, nil
+}
+
+// Destination returns the reply destination of the ~service decorator svc of a connectionless message.
+func Destination(svc *decorator.Service) *service.Destination {
+	return &service.Destination{
+		RecipientKeys:   svc.RecipientKeys,
+		RoutingKeys:     svc.RoutingKeys,
+		ServiceEndpoint: svc.ServiceEndpoint,
+	}
+}
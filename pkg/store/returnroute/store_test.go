@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package returnroute
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func newStore(t *testing.T, opts ...Option) (*TTLStore, *fakeClock) {
+	s, err := New(&mockprovider.Provider{
+		TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+	}, opts...)
+	require.NoError(t, err)
+
+	clock := &fakeClock{t: time.Now()}
+	s.now = clock.now
+
+	return s, clock
+}
+
+func replyDestination() *service.Destination {
+	return Destination(&decorator.Service{
+		RecipientKeys:   []string{"recipient-key"},
+		RoutingKeys:     []string{"routing-key"},
+		ServiceEndpoint: "https://example.com/endpoint",
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("test new store", func(t *testing.T) {
+		s, err := New(&mockprovider.Provider{
+			TransientStorageProviderValue: mockstore.NewMockStoreProvider(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, DefaultTTL, s.ttl)
+
+		var _ Store = s
+	})
+
+	t.Run("test error from open store", func(t *testing.T) {
+		s, err := New(&mockprovider.Provider{
+			TransientStorageProviderValue: &mockstore.MockStoreProvider{
+				ErrOpenStoreHandle: fmt.Errorf("open error")},
+		})
+		require.EqualError(t, err, "failed to open return route store: open error")
+		require.Nil(t, s)
+	})
+}
+
+func TestTTLStore(t *testing.T) {
+	t.Run("stores the reply destination of a thread", func(t *testing.T) {
+		s, _ := newStore(t)
+
+		require.NoError(t, s.Put("thread-1", replyDestination()))
+
+		dest, err := s.Get("thread-1")
+		require.NoError(t, err)
+		require.Equal(t, replyDestination(), dest)
+
+		_, err = s.Get("thread-2")
+		require.True(t, errors.Is(err, ErrNotFound))
+
+		// once replied
+		require.NoError(t, s.Delete("thread-1"))
+
+		_, err = s.Get("thread-1")
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("reply destinations expire", func(t *testing.T) {
+		s, clock := newStore(t, WithTTL(time.Minute))
+
+		require.NoError(t, s.Put("thread", replyDestination()))
+
+		clock.t = clock.t.Add(59 * time.Second)
+
+		_, err := s.Get("thread")
+		require.NoError(t, err)
+
+		clock.t = clock.t.Add(time.Second)
+
+		_, err = s.Get("thread")
+		require.True(t, errors.Is(err, ErrNotFound))
+		require.Contains(t, err.Error(), "expired")
+
+		// the expired destination is removed
+		_, err = s.store.Get(routeKeyPrefix + "thread")
+		require.Error(t, err)
+	})
+
+	t.Run("removes the expired reply destinations", func(t *testing.T) {
+		s, clock := newStore(t, WithTTL(time.Minute))
+
+		require.NoError(t, s.Put("expired", replyDestination()))
+
+		clock.t = clock.t.Add(30 * time.Second)
+		require.NoError(t, s.Put("valid", replyDestination()))
+
+		clock.t = clock.t.Add(30 * time.Second)
+
+		removed, err := s.RemoveExpired()
+		require.NoError(t, err)
+		require.Equal(t, []string{"expired"}, removed)
+
+		_, err = s.Get("valid")
+		require.NoError(t, err)
+	})
+
+	t.Run("put errors", func(t *testing.T) {
+		s, _ := newStore(t)
+		require.EqualError(t, s.Put("", replyDestination()), "thread ID is mandatory")
+
+		s.store = &mockstore.MockStore{Store: map[string][]byte{}, ErrPut: errors.New("put error")}
+		require.EqualError(t, s.Put("thread", replyDestination()), "failed to save reply destination: put error")
+	})
+
+	t.Run("get errors", func(t *testing.T) {
+		s, _ := newStore(t)
+
+		s.store = &mockstore.MockStore{Store: map[string][]byte{}, ErrGet: errors.New("get error")}
+		_, err := s.Get("thread")
+		require.EqualError(t, err, "failed to get reply destination: get error")
+
+		s.store = &mockstore.MockStore{Store: map[string][]byte{routeKeyPrefix + "thread": []byte("invalid")}}
+		_, err = s.Get("thread")
+		require.Contains(t, err.Error(), "failed to unmarshal reply destination")
+
+		_, err = s.RemoveExpired()
+		require.Contains(t, err.Error(), "failed to unmarshal reply destination")
+	})
+}
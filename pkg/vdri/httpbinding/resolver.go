@@ -7,6 +7,7 @@ package httpbinding
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -20,13 +21,66 @@ import (
 
 const (
 	didLDJson = "application/did+ld+json"
+	// didResolutionLDJson is the content type of the DID resolution results returned by universal resolvers.
+	didResolutionLDJson = `application/ld+json;profile="https://w3id.org/did-resolution"`
+
+	resolutionErrNotFound           = "notFound"
+	resolutionErrMethodNotSupported = "methodNotSupported"
 )
 
+// ErrMethodNotSupported is returned when the DID resolver does not support the method of the resolved DID.
+var ErrMethodNotSupported = errors.New("DID method not supported by the DID resolver")
+
 type didResolution struct {
-	Context          interface{}            `json:"@context"`
-	DIDDocument      map[string]interface{} `json:"didDocument"`
-	ResolverMetadata map[string]interface{} `json:"resolverMetadata"`
-	MethodMetadata   map[string]interface{} `json:"methodMetadata"`
+	Context               interface{}            `json:"@context"`
+	DIDDocument           map[string]interface{} `json:"didDocument"`
+	ResolverMetadata      map[string]interface{} `json:"resolverMetadata"`
+	MethodMetadata        map[string]interface{} `json:"methodMetadata"`
+	DIDResolutionMetadata map[string]interface{} `json:"didResolutionMetadata"`
+}
+
+// resolutionError returns the error reported in the metadata of a DID resolution result, if any.
+func (r *didResolution) resolutionError() string {
+	for _, metadata := range []map[string]interface{}{r.DIDResolutionMetadata, r.ResolverMetadata} {
+		if e, ok := metadata["error"].(string); ok {
+			return e
+		}
+	}
+
+	return ""
+}
+
+// isSupportedContentType checks if the content type is one of a DID document or a DID resolution result.
+func isSupportedContentType(contentType string) bool {
+	for _, t := range []string{didLDJson, "application/ld+json", "application/json"} {
+		if strings.Contains(contentType, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolutionResultError maps the error of a DID resolution result sent by the resolver, if any.
+func resolutionResultError(body []byte, uri string) error {
+	var r didResolution
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil
+	}
+
+	return r.toError(uri)
+}
+
+// toError maps the error reported in the metadata of the DID resolution result to the vdri errors.
+func (r *didResolution) toError(uri string) error {
+	switch r.resolutionError() {
+	case resolutionErrNotFound:
+		return fmt.Errorf("%w: DID does not exist for request: %s", vdriapi.ErrNotFound, uri)
+	case resolutionErrMethodNotSupported:
+		return fmt.Errorf("%w: %s", ErrMethodNotSupported, uri)
+	default:
+		return nil
+	}
 }
 
 // resolveDID makes DID resolution via HTTP
@@ -36,7 +90,7 @@ func (v *VDRI) resolveDID(uri string) ([]byte, error) {
 		return nil, fmt.Errorf("HTTP create get request failed: %w", err)
 	}
 
-	req.Header.Add("Accept", didLDJson)
+	req.Header.Add("Accept", didLDJson+", "+didResolutionLDJson)
 
 	resp, err := v.client.Do(req)
 	if err != nil {
@@ -52,24 +106,55 @@ func (v *VDRI) resolveDID(uri string) ([]byte, error) {
 		return nil, fmt.Errorf("reading response body failed: %w", err)
 	}
 
-	if resp.StatusCode == http.StatusOK && strings.Contains(resp.Header.Get("Content-type"), didLDJson) {
-		return gotBody, nil
-	} else if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("DID does not exist for request: %s", uri)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if isSupportedContentType(resp.Header.Get("Content-type")) {
+			return gotBody, nil
+		}
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: DID does not exist for request: %s", vdriapi.ErrNotFound, uri)
+	case http.StatusNotImplemented:
+		return nil, fmt.Errorf("%w: %s", ErrMethodNotSupported, uri)
+	default:
+		if err := resolutionResultError(gotBody, uri); err != nil {
+			return nil, err
+		}
 	}
 
 	return nil, fmt.Errorf("unsupported response from DID resolver [%v] header [%s] body [%s]",
 		resp.StatusCode, resp.Header.Get("Content-type"), gotBody)
 }
 
+func versionQuery(opts ...vdriapi.ResolveOpts) url.Values {
+	resolveOpts := &vdriapi.ResolveDIDOpts{}
+
+	for _, opt := range opts {
+		opt(resolveOpts)
+	}
+
+	query := url.Values{}
+
+	if resolveOpts.VersionID != nil {
+		query.Set("versionId", fmt.Sprint(resolveOpts.VersionID))
+	}
+
+	if resolveOpts.VersionTime != "" {
+		query.Set("versionTime", resolveOpts.VersionTime)
+	}
+
+	return query
+}
+
 // Read implements didresolver.DidMethod.Read interface (https://w3c-ccg.github.io/did-resolution/#resolving-input)
-func (v *VDRI) Read(didID string, _ ...vdriapi.ResolveOpts) (*did.Doc, error) {
+// The versionId and versionTime resolve options are passed to the resolver as query parameters.
+func (v *VDRI) Read(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
 	reqURL, err := url.ParseRequestURI(v.endpointURL)
 	if err != nil {
 		return nil, fmt.Errorf("url parse request uri failed: %w", err)
 	}
 
 	reqURL.Path = path.Join(reqURL.Path, didID)
+	reqURL.RawQuery = versionQuery(opts...).Encode()
 
 	data, err := v.resolveDID(reqURL.String())
 	if err != nil {
@@ -85,6 +170,10 @@ func (v *VDRI) Read(didID string, _ ...vdriapi.ResolveOpts) (*did.Doc, error) {
 		return nil, fmt.Errorf("unmarshal data return from http binding resolver %w", err)
 	}
 
+	if err := r.toError(reqURL.String()); err != nil {
+		return nil, err
+	}
+
 	didDocBytes := data
 	// check if data is did resolution
 	if len(r.DIDDocument) != 0 {
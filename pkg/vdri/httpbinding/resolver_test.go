@@ -149,6 +149,25 @@ func TestRead_DIDDoc(t *testing.T) {
 		require.Equal(t, didDoc.ID, gotDocument.ID)
 	})
 
+	t.Run("test success return did doc version", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			require.Equal(t, "/did:example:334455?versionId=2&versionTime=2020-01-01T00%3A00%3A00Z", req.URL.String())
+			res.Header().Add("Content-type", "application/did+ld+json")
+			res.WriteHeader(http.StatusOK)
+			_, err := res.Write([]byte(doc))
+			require.NoError(t, err)
+		}))
+
+		defer func() { testServer.Close() }()
+
+		resolver, err := New(testServer.URL)
+		require.NoError(t, err)
+		gotDocument, err := resolver.Read("did:example:334455", vdriapi.WithVersionID(2),
+			vdriapi.WithVersionTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+		require.NoError(t, err)
+		require.Equal(t, "did:peer:21tDAKCERh95uGgKbJNHYp", gotDocument.ID)
+	})
+
 	t.Run("test success return did resolution", func(t *testing.T) {
 		testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 			require.Equal(t, "/did:example:334455", req.URL.String())
@@ -241,6 +260,7 @@ func TestRead_DIDDocNotFound(t *testing.T) {
 	require.NoError(t, err)
 	_, err = resolver.Read("did:example:334455")
 	require.Error(t, err)
+	require.True(t, errors.Is(err, vdriapi.ErrNotFound))
 	require.Contains(t, err.Error(), "DID does not exist")
 }
 
@@ -258,6 +278,91 @@ func TestRead_UnsupportedStatus(t *testing.T) {
 	require.Contains(t, err.Error(), "unsupported response from DID resolver")
 }
 
+func TestRead_UniversalResolver(t *testing.T) {
+	newUniversalResolver := func(status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			require.Equal(t, "/1.0/identifiers/did:example:334455", req.URL.String())
+			require.Contains(t, req.Header.Get("Accept"), didResolutionLDJson)
+			res.Header().Add("Content-type", didResolutionLDJson)
+			res.WriteHeader(status)
+			_, err := res.Write([]byte(body))
+			require.NoError(t, err)
+		}))
+	}
+
+	t.Run("test success return did resolution", func(t *testing.T) {
+		testServer := newUniversalResolver(http.StatusOK, didResolutionData)
+		defer testServer.Close()
+
+		resolver, err := New(testServer.URL+"/1.0/identifiers", WithTimeout(time.Second))
+		require.NoError(t, err)
+		gotDocument, err := resolver.Read("did:example:334455")
+		require.NoError(t, err)
+		require.Equal(t, "did:peer:21tDAKCERh95uGgKbJNHYp", gotDocument.ID)
+	})
+
+	t.Run("test did not found", func(t *testing.T) {
+		for _, status := range []int{http.StatusOK, http.StatusNotFound, http.StatusInternalServerError} {
+			testServer := newUniversalResolver(status, `{"didResolutionMetadata":{"error":"notFound"}}`)
+
+			resolver, err := New(testServer.URL + "/1.0/identifiers")
+			require.NoError(t, err)
+			_, err = resolver.Read("did:example:334455")
+			require.True(t, errors.Is(err, vdriapi.ErrNotFound))
+			require.Contains(t, err.Error(), "DID does not exist")
+
+			testServer.Close()
+		}
+	})
+
+	t.Run("test method not supported", func(t *testing.T) {
+		for _, tc := range []struct {
+			status int
+			body   string
+		}{
+			{status: http.StatusNotImplemented},
+			{status: http.StatusBadRequest, body: `{"didResolutionMetadata":{"error":"methodNotSupported"}}`},
+			{status: http.StatusInternalServerError, body: `{"resolverMetadata":{"error":"methodNotSupported"}}`},
+			{status: http.StatusOK, body: `{"didResolutionMetadata":{"error":"methodNotSupported"}}`},
+		} {
+			testServer := newUniversalResolver(tc.status, tc.body)
+
+			resolver, err := New(testServer.URL + "/1.0/identifiers")
+			require.NoError(t, err)
+			_, err = resolver.Read("did:example:334455")
+			require.True(t, errors.Is(err, ErrMethodNotSupported), "status %d", tc.status)
+
+			testServer.Close()
+		}
+	})
+
+	t.Run("test resolver error", func(t *testing.T) {
+		testServer := newUniversalResolver(http.StatusInternalServerError, "driver failure")
+		defer testServer.Close()
+
+		resolver, err := New(testServer.URL + "/1.0/identifiers")
+		require.NoError(t, err)
+		_, err = resolver.Read("did:example:334455")
+		require.Error(t, err)
+		require.False(t, errors.Is(err, vdriapi.ErrNotFound))
+		require.Contains(t, err.Error(), "unsupported response from DID resolver [500]")
+		require.Contains(t, err.Error(), "driver failure")
+	})
+
+	t.Run("test timeout", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer testServer.Close()
+
+		resolver, err := New(testServer.URL, WithTimeout(10*time.Millisecond))
+		require.NoError(t, err)
+		_, err = resolver.Read("did:example:334455")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "HTTP Get request failed")
+	})
+}
+
 func TestRead_HTTPGetFailed(t *testing.T) {
 	// HTTP GET failed
 	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
@@ -35,6 +35,8 @@ type VDRI struct {
 	endpointURL string
 	client      *http.Client
 	accept      Accept
+	proxyURL    string
+	proxyUser   *url.Userinfo
 }
 
 // Accept is method to accept did method
@@ -56,9 +58,54 @@ func New(endpointURL string, opts ...Option) (*VDRI, error) {
 
 	vdri.endpointURL = endpointURL
 
+	err = vdri.configureProxy()
+	if err != nil {
+		return nil, err
+	}
+
 	return vdri, nil
 }
 
+// configureProxy sets the proxy of the HTTP transport of the client if one is configured.
+func (v *VDRI) configureProxy() error {
+	if v.proxyURL == "" {
+		return nil
+	}
+
+	proxy, err := url.Parse(v.proxyURL)
+	if err != nil {
+		return fmt.Errorf("proxy URL invalid: %w", err)
+	}
+
+	switch proxy.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("proxy URL invalid: unsupported scheme '%s'", proxy.Scheme)
+	}
+
+	if v.proxyUser != nil {
+		proxy.User = v.proxyUser
+	}
+
+	// requests to https endpoints are tunneled through the proxy, TLS is still negotiated with
+	// (and verified against) the endpoint using the TLS config of the transport
+	httpTransport(v).Proxy = http.ProxyURL(proxy)
+
+	return nil
+}
+
+// httpTransport returns the HTTP transport of the client, it is set if the client uses the default one.
+func httpTransport(v *VDRI) *http.Transport {
+	if transport, ok := v.client.Transport.(*http.Transport); ok {
+		return transport
+	}
+
+	transport := &http.Transport{}
+	v.client.Transport = transport
+
+	return transport
+}
+
 // Accept did method - attempt to resolve any method
 func (v *VDRI) Accept(method string) bool {
 	return v.accept(method)
@@ -80,6 +127,58 @@ func (v *VDRI) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*did.Doc,
 		opt(docOpts)
 	}
 
+	reqBody, err := createRequest(buildDoc(pubKey, docOpts), docOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	resDoc, err := v.sendCreateRequest(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send create DID request: %s", err)
+	}
+
+	return resDoc, nil
+}
+
+// BuildDoc builds the did doc Build submits to the endpoint, and its create request, without sending the request.
+// The did doc has no ID, the ID is assigned by the endpoint.
+func (v *VDRI) BuildDoc(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*did.Doc, error) {
+	docOpts := &vdriapi.CreateDIDOpts{}
+
+	for _, opt := range opts {
+		opt(docOpts)
+	}
+
+	didDoc := buildDoc(pubKey, docOpts)
+
+	_, err := createRequest(didDoc, docOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return didDoc, nil
+}
+
+// createRequest returns the body of the request creating didDoc.
+func createRequest(didDoc *did.Doc, docOpts *vdriapi.CreateDIDOpts) (io.Reader, error) {
+	docBytes, err := didDoc.JSONBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document bytes : %s", err)
+	}
+
+	if docOpts.RequestBuilder == nil {
+		return bytes.NewReader(docBytes), nil
+	}
+
+	reqBody, err := docOpts.RequestBuilder(docBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request : %s", err)
+	}
+
+	return reqBody, nil
+}
+
+func buildDoc(pubKey *vdriapi.PubKey, docOpts *vdriapi.CreateDIDOpts) *did.Doc {
 	publicKey := did.PublicKey{
 		ID:   pubKeyIndex1,
 		Type: pubKey.Type,
@@ -112,27 +211,7 @@ func (v *VDRI) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*did.Doc,
 		didDoc.Service = []did.Service{s}
 	}
 
-	docBytes, err := didDoc.JSONBytes()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get document bytes : %s", err)
-	}
-
-	var reqBody io.Reader
-	if docOpts.RequestBuilder != nil {
-		reqBody, err = docOpts.RequestBuilder(docBytes)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build request : %s", err)
-		}
-	} else {
-		reqBody = bytes.NewReader(docBytes)
-	}
-
-	resDoc, err := v.sendCreateRequest(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send create DID request: %s", err)
-	}
-
-	return resDoc, nil
+	return didDoc
 }
 
 // TODO add timeouts on external calls [Issue: #855]
@@ -186,9 +265,23 @@ func WithTimeout(timeout time.Duration) Option {
 // WithTLSConfig option is for definition of secured HTTP transport using a tls.Config instance
 func WithTLSConfig(tlsConfig *tls.Config) Option {
 	return func(opts *VDRI) {
-		opts.client.Transport = &http.Transport{
-			TLSClientConfig: tlsConfig,
-		}
+		httpTransport(opts).TLSClientConfig = tlsConfig
+	}
+}
+
+// WithProxy option is for sending the HTTP(s) requests through a proxy, proxyURL schemes supported
+// are http, https and socks5 (eg: "socks5://proxy.example.com:1080").
+func WithProxy(proxyURL string) Option {
+	return func(opts *VDRI) {
+		opts.proxyURL = proxyURL
+	}
+}
+
+// WithProxyAuth option is for the credentials authenticating the requests to the proxy (see WithProxy),
+// they are sent with basic authentication to HTTP proxies.
+func WithProxyAuth(username, password string) Option {
+	return func(opts *VDRI) {
+		opts.proxyUser = url.UserPassword(username, password)
 	}
 }
 
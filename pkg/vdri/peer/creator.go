@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package peer
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,6 +17,9 @@ import (
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 )
 
+// x25519KeySize is the size of the X25519 key agreement keys
+const x25519KeySize = 32
+
 // Build builds new DID Document
 func (v *VDRI) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*did.Doc, error) {
 	docOpts := &vdriapi.CreateDIDOpts{}
@@ -32,6 +36,11 @@ func (v *VDRI) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*did.Doc,
 	return didDoc, nil
 }
 
+// BuildDoc builds the DID Document Build builds, peer DIDs are not published.
+func (v *VDRI) BuildDoc(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*did.Doc, error) {
+	return v.Build(pubKey, opts...)
+}
+
 func build(pubKey *vdriapi.PubKey, docOpts *vdriapi.CreateDIDOpts) (*did.Doc, error) {
 	publicKey := did.PublicKey{
 		ID:         pubKey.Value[0:7],
@@ -60,15 +69,37 @@ func build(pubKey *vdriapi.PubKey, docOpts *vdriapi.CreateDIDOpts) (*did.Doc, er
 		service = append(service, s)
 	}
 
+	publicKeys := []did.PublicKey{publicKey}
+
+	var keyAgreement []did.VerificationMethod
+
+	if docOpts.KeyAgreementKey != "" {
+		keyValue := base58.Decode(docOpts.KeyAgreementKey)
+		if len(keyValue) != x25519KeySize {
+			return nil, errors.New("invalid key agreement key: not a base58 X25519 key")
+		}
+
+		keyAgreementKey := did.PublicKey{
+			ID:         docOpts.KeyAgreementKey[0:7],
+			Type:       vdriapi.X25519KeyAgreementKeyType,
+			Controller: "#id",
+			Value:      keyValue,
+		}
+
+		publicKeys = append(publicKeys, keyAgreementKey)
+		keyAgreement = append(keyAgreement, did.VerificationMethod{PublicKey: keyAgreementKey})
+	}
+
 	// Created/Updated time
 	t := time.Now()
 
 	return NewDoc(
-		[]did.PublicKey{publicKey},
+		publicKeys,
 		[]did.VerificationMethod{
 			{PublicKey: publicKey},
 		},
 		did.WithService(service),
+		did.WithKeyAgreement(keyAgreement),
 		did.WithCreatedTime(t),
 		did.WithUpdatedTime(t),
 	)
@@ -58,6 +58,38 @@ func TestDIDCreator(t *testing.T) {
 		require.Equal(t, routingKeys, didDoc.Service[0].RoutingKeys)
 	})
 
+	t.Run("test key agreement key", func(t *testing.T) {
+		c, err := New(&storage.MockStoreProvider{})
+		require.NoError(t, err)
+
+		keyAgreementKey := getSigningKey().Value
+
+		didDoc, err := c.Build(getSigningKey(), api.WithKeyAgreementKey(keyAgreementKey))
+		require.NoError(t, err)
+		require.Len(t, didDoc.PublicKey, 2)
+		require.Len(t, didDoc.KeyAgreement, 1)
+		require.Equal(t, didDoc.PublicKey[1], didDoc.KeyAgreement[0].PublicKey)
+		require.Equal(t, api.X25519KeyAgreementKeyType, didDoc.KeyAgreement[0].PublicKey.Type)
+		require.Equal(t, base58.Decode(keyAgreementKey), didDoc.KeyAgreement[0].PublicKey.Value)
+
+		// the signing key is the only authentication key
+		require.Len(t, didDoc.Authentication, 1)
+		require.Equal(t, didDoc.PublicKey[0], didDoc.Authentication[0].PublicKey)
+
+		// no key agreement key by default
+		didDoc, err = c.Build(getSigningKey())
+		require.NoError(t, err)
+		require.Len(t, didDoc.PublicKey, 1)
+		require.Empty(t, didDoc.KeyAgreement)
+
+		// the key agreement key must be a base58 X25519 key
+		for _, invalidKey := range []string{"abc", "0OIl", base58.Encode([]byte("too short"))} {
+			_, err = c.Build(getSigningKey(), api.WithKeyAgreementKey(invalidKey))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid key agreement key")
+		}
+	})
+
 	t.Run("test accept", func(t *testing.T) {
 		c, err := New(&storage.MockStoreProvider{})
 		require.NoError(t, err)
@@ -14,9 +14,16 @@ import (
 )
 
 // Read implements didresolver.DidMethod.Read interface (https://w3c-ccg.github.io/did-resolution/#resolving-input)
-func (v *VDRI) Read(didID string, _ ...vdriapi.ResolveOpts) (*did.Doc, error) {
+// The versionId and versionTime resolve options select a previous version of the document.
+func (v *VDRI) Read(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+	resolveOpts := &vdriapi.ResolveDIDOpts{}
+
+	for _, opt := range opts {
+		opt(resolveOpts)
+	}
+
 	// get the document from the store
-	doc, err := v.Get(didID)
+	doc, err := v.GetVersion(didID, resolveOpts)
 	if err != nil {
 		return nil, fmt.Errorf("fetching data from store failed: %w", err)
 	}
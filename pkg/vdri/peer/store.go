@@ -11,10 +11,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
 type docDelta struct {
@@ -29,16 +31,26 @@ func (v *VDRI) Store(doc *did.Doc, by *[]vdriapi.ModifiedBy) error {
 		return errors.New("DID and document are mandatory")
 	}
 
-	var deltas []docDelta
+	deltas, err := v.getDeltas(doc.ID)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("delta data fetch from store failed: %w", err)
+	}
 
-	// For now, assume the doc is a genesis document
+	// For now, each delta holds the full document: the previous ones are kept as the version history
 	jsonDoc, err := doc.JSONBytes()
 	if err != nil {
 		return fmt.Errorf("JSON marshalling of document failed: %w", err)
 	}
 
+	change := base64.URLEncoding.EncodeToString(jsonDoc)
+
+	// storing the current version again doesn't create a new version
+	if len(deltas) != 0 && deltas[len(deltas)-1].Change == change {
+		return nil
+	}
+
 	docDelta := &docDelta{
-		Change:     base64.URLEncoding.EncodeToString(jsonDoc),
+		Change:     change,
 		ModifiedBy: by,
 		ModifiedAt: time.Now(),
 	}
@@ -53,8 +65,15 @@ func (v *VDRI) Store(doc *did.Doc, by *[]vdriapi.ModifiedBy) error {
 	return v.store.Put(doc.ID, val)
 }
 
-// Get returns Peer DID Document
+// Get returns the latest version of Peer DID Document
 func (v *VDRI) Get(id string) (*did.Doc, error) {
+	return v.GetVersion(id, &vdriapi.ResolveDIDOpts{})
+}
+
+// GetVersion returns the version of Peer DID Document selected by the version id (the 1-based index of the
+// version) or by the version time (the version which was current at that time) of the resolve options. The latest
+// version is returned if none of them is set.
+func (v *VDRI) GetVersion(id string, opts *vdriapi.ResolveDIDOpts) (*did.Doc, error) {
 	if id == "" {
 		return nil, errors.New("ID is mandatory")
 	}
@@ -64,8 +83,10 @@ func (v *VDRI) Get(id string) (*did.Doc, error) {
 		return nil, fmt.Errorf("delta data fetch from store failed: %w", err)
 	}
 
-	// For now, assume storage contains only one delta(genesis document)
-	delta := deltas[0]
+	delta, err := selectVersion(deltas, opts)
+	if err != nil {
+		return nil, fmt.Errorf("select version of %s : %w", id, err)
+	}
 
 	doc, err := base64.URLEncoding.DecodeString(delta.Change)
 	if err != nil {
@@ -85,6 +106,38 @@ func (v *VDRI) Close() error {
 	return nil
 }
 
+func selectVersion(deltas []docDelta, opts *vdriapi.ResolveDIDOpts) (*docDelta, error) {
+	if len(deltas) == 0 {
+		return nil, vdriapi.ErrVersionNotFound
+	}
+
+	switch {
+	case opts.VersionID != nil:
+		versionID, err := strconv.Atoi(fmt.Sprint(opts.VersionID))
+		if err != nil || versionID < 1 || versionID > len(deltas) {
+			return nil, fmt.Errorf("%w: version id %v", vdriapi.ErrVersionNotFound, opts.VersionID)
+		}
+
+		return &deltas[versionID-1], nil
+	case opts.VersionTime != "":
+		versionTime, err := time.Parse(time.RFC3339, opts.VersionTime)
+		if err != nil {
+			return nil, fmt.Errorf("parse version time : %w", err)
+		}
+
+		// the deltas are stored in chronological order
+		for i := len(deltas) - 1; i >= 0; i-- {
+			if !deltas[i].ModifiedAt.After(versionTime) {
+				return &deltas[i], nil
+			}
+		}
+
+		return nil, fmt.Errorf("%w: no version at %s", vdriapi.ErrVersionNotFound, opts.VersionTime)
+	default:
+		return &deltas[len(deltas)-1], nil
+	}
+}
+
 func (v *VDRI) getDeltas(id string) ([]docDelta, error) {
 	val, err := v.store.Get(id)
 	if err != nil {
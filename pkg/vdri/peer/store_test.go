@@ -7,12 +7,17 @@ SPDX-License-Identifier: Apache-2.0
 package peer
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 )
 
@@ -77,3 +82,99 @@ func TestVDRI_Close(t *testing.T) {
 		require.NoError(t, v.Close())
 	})
 }
+
+func TestPeerDIDStore_Versions(t *testing.T) {
+	context := []string{"https://w3id.org/did/v1"}
+	created := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	rotated := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newVersion := func(keyValue string, modifiedAt time.Time) docDelta {
+		jsonDoc, err := (&did.Doc{Context: context, ID: peerDID, PublicKey: []did.PublicKey{{
+			ID:         peerDID + "#key-1",
+			Type:       "Ed25519VerificationKey2018",
+			Controller: peerDID,
+			Value:      []byte(keyValue),
+		}}}).JSONBytes()
+		require.NoError(t, err)
+
+		return docDelta{Change: base64.URLEncoding.EncodeToString(jsonDoc), ModifiedAt: modifiedAt}
+	}
+
+	prov := storage.NewMockStoreProvider()
+	dbstore, err := prov.OpenStore(StoreNamespace)
+	require.NoError(t, err)
+
+	deltas, err := json.Marshal([]docDelta{newVersion("old-key", created), newVersion("new-key", rotated)})
+	require.NoError(t, err)
+	require.NoError(t, dbstore.Put(peerDID, deltas))
+
+	v, err := New(prov)
+	require.NoError(t, err)
+
+	t.Run("test latest version", func(t *testing.T) {
+		doc, err := v.Read(peerDID)
+		require.NoError(t, err)
+		require.Equal(t, []byte("new-key"), doc.PublicKey[0].Value)
+	})
+
+	t.Run("test version selected by version time", func(t *testing.T) {
+		doc, err := v.Read(peerDID, vdriapi.WithVersionTime(created.Add(time.Hour)))
+		require.NoError(t, err)
+		require.Equal(t, []byte("old-key"), doc.PublicKey[0].Value)
+
+		doc, err = v.Read(peerDID, vdriapi.WithVersionTime(rotated))
+		require.NoError(t, err)
+		require.Equal(t, []byte("new-key"), doc.PublicKey[0].Value)
+	})
+
+	t.Run("test version selected by version id", func(t *testing.T) {
+		doc, err := v.Read(peerDID, vdriapi.WithVersionID(1))
+		require.NoError(t, err)
+		require.Equal(t, []byte("old-key"), doc.PublicKey[0].Value)
+
+		doc, err = v.Read(peerDID, vdriapi.WithVersionID("2"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("new-key"), doc.PublicKey[0].Value)
+
+		_, err = v.Read(peerDID, vdriapi.WithVersionID(3))
+		require.True(t, errors.Is(err, vdriapi.ErrVersionNotFound))
+	})
+
+	t.Run("test no version covers the version time", func(t *testing.T) {
+		_, err := v.Read(peerDID, vdriapi.WithVersionTime(created.Add(-time.Hour)))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, vdriapi.ErrVersionNotFound))
+	})
+
+	t.Run("test invalid version time", func(t *testing.T) {
+		_, err := v.GetVersion(peerDID, &vdriapi.ResolveDIDOpts{VersionTime: "invalid"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse version time")
+	})
+
+	t.Run("test store appends a new version", func(t *testing.T) {
+		s, err := New(storage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		doc := &did.Doc{Context: context, ID: peerDID}
+		require.NoError(t, s.Store(doc, nil))
+		// storing the same document doesn't create a new version
+		require.NoError(t, s.Store(doc, nil))
+
+		doc.PublicKey = []did.PublicKey{{ID: peerDID + "#key-1", Type: "Ed25519VerificationKey2018",
+			Controller: peerDID, Value: []byte("key")}}
+		require.NoError(t, s.Store(doc, nil))
+
+		deltas, err := s.getDeltas(peerDID)
+		require.NoError(t, err)
+		require.Len(t, deltas, 2)
+
+		latest, err := s.Get(peerDID)
+		require.NoError(t, err)
+		require.Len(t, latest.PublicKey, 1)
+
+		first, err := s.Read(peerDID, vdriapi.WithVersionID(1))
+		require.NoError(t, err)
+		require.Empty(t, first.PublicKey)
+	})
+}
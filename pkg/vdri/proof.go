@@ -0,0 +1,130 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"fmt"
+	"strings"
+
+	diddoc "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ecdsasecp256k1signature2019"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/jsonwebsignature2020"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+)
+
+// verifyProof verifies the proofs of the DID document doc, if it has any. The key of the creator of a proof is looked
+// up in doc or, if the creator is another DID, in its resolved DID document: the creator must be the DID subject or
+// a controller of a key of doc. If doc has capability invocation verification methods, the creator key must be one
+// of them (see did.AuthorizeVerificationMethod).
+func (r *Registry) verifyProof(doc *diddoc.Doc) error {
+	if len(doc.Proof) == 0 {
+		return nil
+	}
+
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return fmt.Errorf("%w : %s", vdriapi.ErrInvalidProof, err)
+	}
+
+	suites := r.proofSuites
+	if len(suites) == 0 {
+		suites = defaultProofSuites()
+	}
+
+	v := verifier.New(&proofKeyResolver{doc: doc, registry: r}, suites[0], suites[1:]...)
+
+	if err := v.Verify(docBytes); err != nil {
+		return fmt.Errorf("%w : %s", vdriapi.ErrInvalidProof, err)
+	}
+
+	return nil
+}
+
+func defaultProofSuites() []verifier.SignatureSuite {
+	return []verifier.SignatureSuite{
+		ed25519signature2018.New(suite.WithVerifier(ed25519signature2018.NewPublicKeyVerifier())),
+		jsonwebsignature2020.New(suite.WithVerifier(jsonwebsignature2020.NewPublicKeyVerifier())),
+		ecdsasecp256k1signature2019.New(suite.WithVerifier(ecdsasecp256k1signature2019.NewPublicKeyVerifier())),
+	}
+}
+
+// proofKeyResolver resolves the keys of the creators of the proofs of a DID document, which must be authorized to
+// act for the DID subject.
+type proofKeyResolver struct {
+	doc      *diddoc.Doc
+	registry *Registry
+}
+
+func (r *proofKeyResolver) Resolve(id string) (*verifier.PublicKey, error) {
+	// relative key IDs (eg: #key-1) are keys of the DID document
+	if strings.HasPrefix(id, "#") {
+		id = r.doc.ID + id
+	}
+
+	did := strings.SplitN(id, "#", 2)[0]
+
+	doc := r.doc
+
+	if did != r.doc.ID {
+		if !r.isController(did) {
+			return nil, fmt.Errorf("%w: proof creator %s is neither %s nor a controller of its keys",
+				diddoc.ErrNotAuthorized, did, r.doc.ID)
+		}
+
+		controllerDoc, err := r.registry.Resolve(did)
+		if err != nil {
+			return nil, fmt.Errorf("resolve proof creator %s : %w", did, err)
+		}
+
+		doc = controllerDoc
+	}
+
+	for i := range doc.PublicKey {
+		key := &doc.PublicKey[i]
+
+		if key.ID == id || doc.ID+key.ID == id {
+			if err := r.authorize(key.ID); err != nil {
+				return nil, err
+			}
+
+			return &verifier.PublicKey{
+				Type:  key.Type,
+				Value: key.Value,
+				JWK:   key.JSONWebKey(),
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("proof creator %s : %w", id, diddoc.ErrKeyNotFound)
+}
+
+// isController returns whether did controls a key of the DID document.
+func (r *proofKeyResolver) isController(did string) bool {
+	for i := range r.doc.PublicKey {
+		if r.doc.PublicKey[i].Controller == did {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authorize checks that the key keyID is a capability invocation verification method of the DID document, if the
+// document has any: the document then restricts the keys acting for the DID subject.
+func (r *proofKeyResolver) authorize(keyID string) error {
+	if len(r.doc.CapabilityInvocation) == 0 {
+		return nil
+	}
+
+	return diddoc.AuthorizeVerificationMethod(r.doc, keyID, diddoc.CapabilityInvocation,
+		func(did string) (*diddoc.Doc, error) {
+			return r.registry.Resolve(did)
+		})
+}
@@ -0,0 +1,315 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/proof"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/signer"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+)
+
+func TestRegistry_ResolveVerifyProof(t *testing.T) {
+	const (
+		subjectDID    = "did:example:subject"
+		controllerDID = "did:example:controller"
+	)
+
+	subjectPubKey, subjectPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	controllerPubKey, controllerPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	controllerDoc := newDoc(controllerDID, controllerPubKey)
+
+	newRegistry := func(docs ...*did.Doc) *Registry {
+		return New(&mockprovider.Provider{}, WithProofSignatureSuites(&offlineSuite{}), WithVDRI(&mockvdri.MockVDRI{
+			AcceptValue: true,
+			ReadFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+				for _, doc := range docs {
+					if doc.ID == didID {
+						return doc, nil
+					}
+				}
+
+				return nil, vdriapi.ErrNotFound
+			},
+		}))
+	}
+
+	t.Run("test self-signed document with a valid proof", func(t *testing.T) {
+		doc := signDoc(t, newDoc(subjectDID, subjectPubKey), subjectDID+"#key-1", subjectPrivKey)
+
+		result, err := newRegistry(doc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.NoError(t, err)
+		require.Equal(t, doc, result)
+	})
+
+	t.Run("test document signed by its controller with a valid proof", func(t *testing.T) {
+		doc := signDoc(t, newControlledDoc(subjectDID, controllerDID, subjectPubKey), controllerDID+"#key-1",
+			controllerPrivKey)
+
+		result, err := newRegistry(doc, controllerDoc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.NoError(t, err)
+		require.Equal(t, doc, result)
+	})
+
+	t.Run("test self-signed document with a proof of a verification method", func(t *testing.T) {
+		for _, representation := range []proof.SignatureRepresentation{proof.SignatureProofValue, proof.SignatureJWS} {
+			// the verification method takes precedence over the creator
+			doc := signDocWithContext(t, newDoc(subjectDID, subjectPubKey), &signer.Context{
+				Creator:                 controllerDID + "#key-1",
+				VerificationMethod:      subjectDID + "#key-1",
+				SignatureType:           "Ed25519Signature2018",
+				SignatureRepresentation: representation,
+			}, subjectPrivKey)
+			require.Equal(t, subjectDID+"#key-1", doc.Proof[0].VerificationMethod)
+
+			result, err := newRegistry(doc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+			require.NoError(t, err, representation)
+			require.Equal(t, doc, result)
+		}
+	})
+
+	t.Run("test self-signed document with a JWS proof", func(t *testing.T) {
+		doc := signDocWithContext(t, newDoc(subjectDID, subjectPubKey), &signer.Context{
+			Creator:                 subjectDID + "#key-1",
+			SignatureType:           "Ed25519Signature2018",
+			SignatureRepresentation: proof.SignatureJWS,
+		}, subjectPrivKey)
+		require.NotEmpty(t, doc.Proof[0].JWS)
+		require.Empty(t, doc.Proof[0].ProofValue)
+
+		registry := newRegistry(doc)
+
+		_, err := registry.Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.NoError(t, err)
+
+		doc.Proof[0].JWS += "A"
+
+		_, err = registry.Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, vdriapi.ErrInvalidProof))
+	})
+
+	t.Run("test document with a tampered proof", func(t *testing.T) {
+		doc := signDoc(t, newDoc(subjectDID, subjectPubKey), subjectDID+"#key-1", subjectPrivKey)
+		doc.Proof[0].ProofValue[0] ^= 0xff
+
+		registry := newRegistry(doc)
+
+		_, err := registry.Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, vdriapi.ErrInvalidProof))
+
+		// the proof is not verified unless requested
+		result, err := registry.Resolve(subjectDID)
+		require.NoError(t, err)
+		require.Equal(t, doc, result)
+	})
+
+	t.Run("test document signed with another key than its creator", func(t *testing.T) {
+		doc := signDoc(t, newControlledDoc(subjectDID, controllerDID, subjectPubKey), controllerDID+"#key-1",
+			subjectPrivKey)
+
+		_, err := newRegistry(doc, controllerDoc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, vdriapi.ErrInvalidProof))
+	})
+
+	t.Run("test document signed by an unrelated DID", func(t *testing.T) {
+		// the controller DID resolves, but controls no key of the document
+		doc := signDoc(t, newDoc(subjectDID, subjectPubKey), controllerDID+"#key-1", controllerPrivKey)
+
+		_, err := newRegistry(doc, controllerDoc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, vdriapi.ErrInvalidProof))
+		require.Contains(t, err.Error(), "verification method not authorized")
+	})
+
+	t.Run("test document signed with a key which is not a capability invocation method", func(t *testing.T) {
+		subject := newDoc(subjectDID, subjectPubKey)
+		subject.PublicKey = append(subject.PublicKey, did.PublicKey{
+			ID: subjectDID + "#key-2", Type: "Ed25519VerificationKey2018", Controller: subjectDID, Value: subjectPubKey,
+		})
+		subject.CapabilityInvocation = []did.VerificationMethod{{PublicKey: subject.PublicKey[0]}}
+
+		doc := signDoc(t, subject, subjectDID+"#key-1", subjectPrivKey)
+
+		_, err := newRegistry(doc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.NoError(t, err)
+
+		doc = signDoc(t, subject, subjectDID+"#key-2", subjectPrivKey)
+
+		_, err = newRegistry(doc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, vdriapi.ErrInvalidProof))
+		require.Contains(t, err.Error(), "verification method not authorized")
+	})
+
+	t.Run("test document signed by its controller with a capability invocation method", func(t *testing.T) {
+		subject := newControlledDoc(subjectDID, controllerDID, subjectPubKey)
+		subject.CapabilityInvocation = []did.VerificationMethod{{PublicKey: controllerDoc.PublicKey[0]}}
+
+		doc := signDoc(t, subject, controllerDID+"#key-1", controllerPrivKey)
+
+		_, err := newRegistry(doc, controllerDoc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.NoError(t, err)
+	})
+
+	t.Run("test document with an unknown proof creator", func(t *testing.T) {
+		doc := signDoc(t, newControlledDoc(subjectDID, controllerDID, subjectPubKey), controllerDID+"#key-1",
+			controllerPrivKey)
+
+		_, err := newRegistry(doc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, vdriapi.ErrInvalidProof))
+		require.Contains(t, err.Error(), "resolve proof creator")
+
+		doc = signDoc(t, newDoc(subjectDID, subjectPubKey), subjectDID+"#key-2", subjectPrivKey)
+
+		_, err = newRegistry(doc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, vdriapi.ErrInvalidProof))
+		require.Contains(t, err.Error(), "key not found")
+	})
+
+	t.Run("test document without proof", func(t *testing.T) {
+		doc := newDoc(subjectDID, subjectPubKey)
+
+		result, err := newRegistry(doc).Resolve(subjectDID, vdriapi.WithVerifyProof(true))
+		require.NoError(t, err)
+		require.Equal(t, doc, result)
+	})
+}
+
+func TestProofKeyResolver_Resolve(t *testing.T) {
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	doc := newDoc("did:example:subject", pubKey)
+	resolver := &proofKeyResolver{doc: doc, registry: New(&mockprovider.Provider{})}
+
+	for _, id := range []string{"#key-1", "did:example:subject#key-1"} {
+		key, err := resolver.Resolve(id)
+		require.NoError(t, err)
+		require.Equal(t, []byte(pubKey), key.Value)
+		require.Equal(t, "Ed25519VerificationKey2018", key.Type)
+	}
+}
+
+func TestDefaultProofSuites(t *testing.T) {
+	suites := defaultProofSuites()
+
+	for _, signatureType := range []string{"Ed25519Signature2018", "JsonWebSignature2020",
+		"EcdsaSecp256k1Signature2019"} {
+		accepted := false
+
+		for _, s := range suites {
+			accepted = accepted || s.Accept(signatureType)
+		}
+
+		require.True(t, accepted, signatureType)
+	}
+}
+
+func newDoc(id string, pubKey ed25519.PublicKey) *did.Doc {
+	return &did.Doc{
+		Context: []string{"https://w3id.org/did/v1"},
+		ID:      id,
+		PublicKey: []did.PublicKey{{
+			ID:         id + "#key-1",
+			Type:       "Ed25519VerificationKey2018",
+			Controller: id,
+			Value:      pubKey,
+		}},
+	}
+}
+
+// newControlledDoc returns the DID document id with a key controlled by the DID controller.
+func newControlledDoc(id, controller string, pubKey ed25519.PublicKey) *did.Doc {
+	doc := newDoc(id, pubKey)
+	doc.PublicKey[0].Controller = controller
+
+	return doc
+}
+
+// signDoc returns the DID document doc with a proof of creator, signed with privKey.
+func signDoc(t *testing.T, doc *did.Doc, creator string, privKey ed25519.PrivateKey) *did.Doc {
+	t.Helper()
+
+	return signDocWithContext(t, doc, &signer.Context{Creator: creator, SignatureType: "Ed25519Signature2018"}, privKey)
+}
+
+// signDocWithContext returns the DID document doc with a proof of the signing context, signed with privKey.
+func signDocWithContext(t *testing.T, doc *did.Doc, context *signer.Context, privKey ed25519.PrivateKey) *did.Doc {
+	t.Helper()
+
+	docBytes, err := doc.JSONBytes()
+	require.NoError(t, err)
+
+	s := signer.New(&offlineSuite{privKey: privKey})
+
+	signedBytes, err := s.Sign(context, docBytes)
+	require.NoError(t, err)
+
+	signedDoc, err := did.ParseDocument(signedBytes)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(signedDoc.Proof[0].Creator, "did:example:"))
+
+	return signedDoc
+}
+
+// offlineSuite is an Ed25519Signature2018 signature suite canonicalizing the documents as JSON, unlike the JSON-LD
+// suites it does not load the remote JSON-LD contexts.
+type offlineSuite struct {
+	privKey ed25519.PrivateKey
+}
+
+func (s *offlineSuite) GetCanonicalDocument(doc map[string]interface{}) ([]byte, error) {
+	return json.Marshal(doc)
+}
+
+func (s *offlineSuite) GetDigest(doc []byte) []byte {
+	digest := sha256.Sum256(doc)
+
+	return digest[:]
+}
+
+func (s *offlineSuite) Sign(doc []byte) ([]byte, error) {
+	return ed25519.Sign(s.privKey, doc), nil
+}
+
+func (s *offlineSuite) Verify(pubKey *verifier.PublicKey, doc, signature []byte) error {
+	if !ed25519.Verify(pubKey.Value, doc, signature) {
+		return errors.New("ed25519: invalid signature")
+	}
+
+	return nil
+}
+
+func (s *offlineSuite) Accept(signatureType string) bool {
+	return signatureType == "Ed25519Signature2018"
+}
+
+func (s *offlineSuite) CompactProof() bool {
+	return false
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	diddoc "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 )
@@ -23,6 +24,19 @@ const (
 // Option is a vdri instance option
 type Option func(opts *Registry)
 
+// ResolvePolicy is the policy of DID resolution when several VDRIs accept the DID method. The VDRIs are tried
+// in the order they are added to the registry (see WithVDRI) until one resolves the DID.
+type ResolvePolicy int
+
+const (
+	// FirstNonEmpty policy returns the DID document of the first VDRI finding the DID: the next VDRI is tried
+	// only if the DID is not found. Other errors are returned right away. This is the default policy.
+	FirstNonEmpty ResolvePolicy = iota
+	// FirstNonError policy returns the result of the first VDRI resolving the DID without error: the next VDRI
+	// is tried on any error.
+	FirstNonError
+)
+
 // provider contains dependencies for the did creator
 type provider interface {
 	LegacyKMS() legacykms.KeyManager
@@ -34,6 +48,8 @@ type Registry struct {
 	crypto             legacykms.KeyManager
 	defServiceEndpoint string
 	defServiceType     string
+	resolvePolicy      ResolvePolicy
+	proofSuites        []verifier.SignatureSuite
 }
 
 // New return new instance of vdri
@@ -56,19 +72,30 @@ func (r *Registry) Resolve(did string, opts ...vdriapi.ResolveOpts) (*diddoc.Doc
 		opt(resolveOpts)
 	}
 
+	if resolveOpts.Timeout > 0 {
+		return r.resolveWithTimeout(did, resolveOpts, opts...)
+	}
+
+	return r.resolve(did, resolveOpts, &resolution{}, opts...)
+}
+
+// resolve resolves did, the DID document read is recorded in res before its proof is verified, then flagged once
+// its proof is verified. A DID document whose proof is invalid is not recorded.
+func (r *Registry) resolve(did string, resolveOpts *vdriapi.ResolveDIDOpts, res *resolution,
+	opts ...vdriapi.ResolveOpts) (*diddoc.Doc, error) {
 	didMethod, err := getDidMethod(did)
 	if err != nil {
 		return nil, err
 	}
 
 	// resolve did method
-	method, err := r.resolveVDRI(didMethod)
+	_, err = r.resolveVDRI(didMethod)
 	if err != nil {
 		return nil, err
 	}
 
 	// Obtain the DID Document
-	didDoc, err := method.Read(did, opts...)
+	didDoc, err := r.read(didMethod, did, opts...)
 	if err != nil {
 		if errors.Is(err, vdriapi.ErrNotFound) {
 			return nil, err
@@ -82,6 +109,18 @@ func (r *Registry) Resolve(did string, opts ...vdriapi.ResolveOpts) (*diddoc.Doc
 		return nil, errors.New("result type 'resolution-result' not supported")
 	}
 
+	res.setDoc(didDoc)
+
+	if resolveOpts.VerifyProof {
+		if err := r.verifyProof(didDoc); err != nil {
+			res.setDoc(nil)
+
+			return nil, err
+		}
+
+		res.setProofVerified()
+	}
+
 	return didDoc, nil
 }
 
@@ -93,9 +132,26 @@ func (r *Registry) Create(didMethod string, opts ...vdriapi.DocOpts) (*diddoc.Do
 		opt(docOpts)
 	}
 
-	_, base58PubKey, err := r.crypto.CreateKeySet()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create DID: %w", err)
+	base58PubKey := docOpts.SigningKey
+
+	var err error
+
+	if base58PubKey == "" {
+		_, base58PubKey, err = r.crypto.CreateKeySet()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create DID: %w", err)
+		}
+	}
+
+	// the key agreement key is the X25519 key of a key set of its own, independent of the signing key, unless a key
+	// is supplied
+	if docOpts.KeyAgreement && docOpts.KeyAgreementKey == "" {
+		keyAgreementKey, _, err := r.crypto.CreateKeySet()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create DID key agreement key: %w", err)
+		}
+
+		opts = append(opts, vdriapi.WithKeyAgreementKey(keyAgreementKey))
 	}
 
 	method, err := r.resolveVDRI(didMethod)
@@ -155,6 +211,39 @@ func (r *Registry) Close() error {
 	return nil
 }
 
+// read reads the DID with the VDRIs accepting didMethod, in their order, according to the resolve policy.
+func (r *Registry) read(didMethod, did string, opts ...vdriapi.ResolveOpts) (*diddoc.Doc, error) {
+	var (
+		doc *diddoc.Doc
+		err error
+	)
+
+	for _, v := range r.vdri {
+		if !v.Accept(didMethod) {
+			continue
+		}
+
+		doc, err = v.Read(did, opts...)
+		if !r.readNext(doc, err) {
+			break
+		}
+	}
+
+	return doc, err
+}
+
+// readNext tells if the next VDRI should be tried after a VDRI read doc or failed with err.
+func (r *Registry) readNext(doc *diddoc.Doc, err error) bool {
+	switch {
+	case err == nil:
+		return doc == nil && r.resolvePolicy == FirstNonEmpty
+	case errors.Is(err, vdriapi.ErrNotFound):
+		return true
+	default:
+		return r.resolvePolicy == FirstNonError
+	}
+}
+
 func (r *Registry) resolveVDRI(method string) (vdriapi.VDRI, error) {
 	for _, v := range r.vdri {
 		if v.Accept(method) {
@@ -165,13 +254,31 @@ func (r *Registry) resolveVDRI(method string) (vdriapi.VDRI, error) {
 	return nil, fmt.Errorf("did method %s not supported for vdri", method)
 }
 
-// WithVDRI adds did method implementation for store
+// WithVDRI adds did method implementation for store.
+// The VDRIs are tried in the order they are added, the first added VDRI has the highest priority.
 func WithVDRI(method vdriapi.VDRI) Option {
 	return func(opts *Registry) {
 		opts.vdri = append(opts.vdri, method)
 	}
 }
 
+// WithResolvePolicy sets the policy of DID resolution by the VDRIs accepting the DID method (FirstNonEmpty by
+// default).
+func WithResolvePolicy(policy ResolvePolicy) Option {
+	return func(opts *Registry) {
+		opts.resolvePolicy = policy
+	}
+}
+
+// WithProofSignatureSuites sets the signature suites verifying the proofs of the DID documents resolved with the
+// vdriapi.WithVerifyProof option (Ed25519Signature2018, JsonWebSignature2020 and EcdsaSecp256k1Signature2019 by
+// default).
+func WithProofSignatureSuites(suites ...verifier.SignatureSuite) Option {
+	return func(opts *Registry) {
+		opts.proofSuites = suites
+	}
+}
+
 // WithDefaultServiceType is default service type for this creator
 func WithDefaultServiceType(serviceType string) Option {
 	return func(opts *Registry) {
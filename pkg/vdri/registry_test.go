@@ -7,15 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package vdri
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
 )
 
@@ -118,6 +123,77 @@ func TestRegistry_Resolve(t *testing.T) {
 	})
 }
 
+func TestRegistry_ResolvePolicy(t *testing.T) {
+	const didID = "did:example:123"
+
+	fallbackDoc := &did.Doc{ID: didID}
+
+	missing := &mockvdri.MockVDRI{
+		AcceptValue: true, ReadFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+			return nil, vdriapi.ErrNotFound
+		}}
+	failing := &mockvdri.MockVDRI{
+		AcceptValue: true, ReadFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+			return nil, fmt.Errorf("read error")
+		}}
+	fallback := &mockvdri.MockVDRI{
+		AcceptValue: true, ReadFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+			return fallbackDoc, nil
+		}}
+
+	t.Run("test local resolver misses and fallback hits", func(t *testing.T) {
+		for _, policy := range []ResolvePolicy{FirstNonEmpty, FirstNonError} {
+			registry := New(&mockprovider.Provider{}, WithVDRI(&mockvdri.MockVDRI{AcceptValue: false}),
+				WithVDRI(missing), WithVDRI(fallback), WithResolvePolicy(policy))
+
+			doc, err := registry.Resolve(didID)
+			require.NoError(t, err)
+			require.Equal(t, fallbackDoc, doc)
+		}
+	})
+
+	t.Run("test first non empty stops at error", func(t *testing.T) {
+		registry := New(&mockprovider.Provider{}, WithVDRI(failing), WithVDRI(fallback))
+
+		doc, err := registry.Resolve(didID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read error")
+		require.Nil(t, doc)
+	})
+
+	t.Run("test first non error skips error", func(t *testing.T) {
+		registry := New(&mockprovider.Provider{}, WithVDRI(failing), WithVDRI(fallback),
+			WithResolvePolicy(FirstNonError))
+
+		doc, err := registry.Resolve(didID)
+		require.NoError(t, err)
+		require.Equal(t, fallbackDoc, doc)
+	})
+
+	t.Run("test resolver with highest priority is used", func(t *testing.T) {
+		registry := New(&mockprovider.Provider{}, WithVDRI(fallback), WithVDRI(failing),
+			WithResolvePolicy(FirstNonError))
+
+		doc, err := registry.Resolve(didID)
+		require.NoError(t, err)
+		require.Equal(t, fallbackDoc, doc)
+	})
+
+	t.Run("test all resolvers miss", func(t *testing.T) {
+		registry := New(&mockprovider.Provider{}, WithVDRI(missing), WithVDRI(missing))
+
+		_, err := registry.Resolve(didID)
+		require.True(t, errors.Is(err, vdriapi.ErrNotFound))
+
+		registry = New(&mockprovider.Provider{}, WithVDRI(missing), WithVDRI(failing),
+			WithResolvePolicy(FirstNonError))
+
+		_, err = registry.Resolve(didID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read error")
+	})
+}
+
 func TestRegistry_Store(t *testing.T) {
 	t.Run("test invalid did input", func(t *testing.T) {
 		registry := New(&mockprovider.Provider{})
@@ -203,4 +279,69 @@ func TestRegistry_Create(t *testing.T) {
 		_, err := registry.Create("id")
 		require.NoError(t, err)
 	})
+	t.Run("test key agreement key", func(t *testing.T) {
+		var signingKey, keyAgreementKey string
+
+		kms, err := legacykms.New(&mockprovider.Provider{StorageProviderValue: mockstore.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		registry := New(&mockprovider.Provider{KMSValue: kms},
+			WithVDRI(&mockvdri.MockVDRI{AcceptValue: true,
+				BuildFunc: func(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (doc *did.Doc, e error) {
+					docOpts := &vdriapi.CreateDIDOpts{}
+					for _, opt := range opts {
+						opt(docOpts)
+					}
+					signingKey = pubKey.Value
+					keyAgreementKey = docOpts.KeyAgreementKey
+					return &did.Doc{ID: "1:id:123"}, nil
+				}}))
+
+		// no key agreement key by default
+		_, err = registry.Create("id")
+		require.NoError(t, err)
+		require.Empty(t, keyAgreementKey)
+
+		// an X25519 key independent of the signing key
+		_, err = registry.Create("id", vdriapi.WithKeyAgreement(true))
+		require.NoError(t, err)
+		require.NotEmpty(t, keyAgreementKey)
+
+		signingEncKey, err := cryptoutil.PublicEd25519toCurve25519(base58.Decode(signingKey))
+		require.NoError(t, err)
+		require.NotEqual(t, base58.Encode(signingEncKey), keyAgreementKey)
+
+		// the supplied key is reused
+		_, err = registry.Create("id", vdriapi.WithKeyAgreementKey("existingKey"))
+		require.NoError(t, err)
+		require.Equal(t, "existingKey", keyAgreementKey)
+
+		// the key agreement key creation fails
+		registry = New(&mockprovider.Provider{KMSValue: &mockkms.CloseableKMS{CreateKeyErr: errors.New("create error")}},
+			WithVDRI(&mockvdri.MockVDRI{AcceptValue: true}))
+
+		_, err = registry.Create("id", vdriapi.WithSigningKey("sigKey"), vdriapi.WithKeyAgreement(true))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create DID key agreement key")
+	})
+	t.Run("test signing key", func(t *testing.T) {
+		var signingKey string
+
+		registry := New(&mockprovider.Provider{KMSValue: &mockkms.CloseableKMS{CreateSigningKeyValue: "sigKey"}},
+			WithVDRI(&mockvdri.MockVDRI{AcceptValue: true,
+				BuildFunc: func(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (doc *did.Doc, e error) {
+					signingKey = pubKey.Value
+					return &did.Doc{ID: "1:id:123"}, nil
+				}}))
+
+		// a new key by default
+		_, err := registry.Create("id")
+		require.NoError(t, err)
+		require.Equal(t, "sigKey", signingKey)
+
+		// the supplied key is reused
+		_, err = registry.Create("id", vdriapi.WithSigningKey("existingKey"))
+		require.NoError(t, err)
+		require.Equal(t, "existingKey", signingKey)
+	})
 }
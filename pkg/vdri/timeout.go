@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	diddoc "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+)
+
+// resolution records the progress of a DID resolution: the DID document read, before its proof is verified, and
+// whether its proof is verified.
+type resolution struct {
+	mutex         sync.Mutex
+	doc           *diddoc.Doc
+	proofVerified bool
+}
+
+func (r *resolution) setDoc(doc *diddoc.Doc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.doc = doc
+	r.proofVerified = false
+}
+
+func (r *resolution) setProofVerified() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.proofVerified = true
+}
+
+func (r *resolution) getDoc() (*diddoc.Doc, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.doc, r.proofVerified
+}
+
+type resolveResult struct {
+	doc *diddoc.Doc
+	err error
+}
+
+// resolveWithTimeout resolves did within resolveOpts.Timeout. On timeout, the DID document resolved so far is
+// returned with a *vdriapi.PartialResultError if partial results are accepted, flagged if its proof is not verified
+// yet, vdriapi.ErrResolutionTimeout otherwise. The VDRIs can't be interrupted, the resolution completes in the background.
+func (r *Registry) resolveWithTimeout(did string, resolveOpts *vdriapi.ResolveDIDOpts,
+	opts ...vdriapi.ResolveOpts) (*diddoc.Doc, error) {
+	res := &resolution{}
+	done := make(chan resolveResult, 1)
+
+	go func() {
+		doc, err := r.resolve(did, resolveOpts, res, opts...)
+		done <- resolveResult{doc: doc, err: err}
+	}()
+
+	timer := time.NewTimer(resolveOpts.Timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-done:
+		return result.doc, result.err
+	case <-timer.C:
+	}
+
+	timeoutErr := fmt.Errorf("resolve %s : %w after %s", did, vdriapi.ErrResolutionTimeout, resolveOpts.Timeout)
+
+	partial, proofVerified := res.getDoc()
+	if partial == nil || !resolveOpts.PartialResult {
+		return nil, timeoutErr
+	}
+
+	return partial, &vdriapi.PartialResultError{Doc: partial, ProofVerified: proofVerified, Err: timeoutErr}
+}
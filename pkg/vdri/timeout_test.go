@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+)
+
+func TestRegistry_ResolveTimeout(t *testing.T) {
+	const (
+		subjectDID    = "did:example:subject"
+		controllerDID = "did:example:controller"
+		slowDelay     = 500 * time.Millisecond
+		timeout       = 50 * time.Millisecond
+	)
+
+	subjectPubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	controllerPubKey, controllerPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// the subject document is signed by its controller, whose document is slow to resolve
+	subjectDoc := signDoc(t, newControlledDoc(subjectDID, controllerDID, subjectPubKey), controllerDID+"#key-1",
+		controllerPrivKey)
+	controllerDoc := newDoc(controllerDID, controllerPubKey)
+
+	// newRegistry returns a registry whose backend reads the documents, after delays[id] for slow documents.
+	newRegistry := func(delays map[string]time.Duration) *Registry {
+		return New(&mockprovider.Provider{}, WithProofSignatureSuites(&offlineSuite{}), WithVDRI(&mockvdri.MockVDRI{
+			AcceptValue: true,
+			ReadFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+				time.Sleep(delays[didID])
+
+				for _, doc := range []*did.Doc{subjectDoc, controllerDoc} {
+					if doc.ID == didID {
+						return doc, nil
+					}
+				}
+
+				return nil, vdriapi.ErrNotFound
+			},
+		}))
+	}
+
+	t.Run("test resolution completing before the timeout", func(t *testing.T) {
+		doc, err := newRegistry(nil).Resolve(subjectDID, vdriapi.WithVerifyProof(true),
+			vdriapi.WithTimeout(slowDelay))
+		require.NoError(t, err)
+		require.Equal(t, subjectDoc, doc)
+
+		// no timeout by default
+		doc, err = newRegistry(map[string]time.Duration{subjectDID: timeout}).Resolve(subjectDID)
+		require.NoError(t, err)
+		require.Equal(t, subjectDoc, doc)
+	})
+
+	t.Run("test timeout fails by default", func(t *testing.T) {
+		registry := newRegistry(map[string]time.Duration{controllerDID: slowDelay})
+
+		start := time.Now()
+
+		doc, err := registry.Resolve(subjectDID, vdriapi.WithVerifyProof(true), vdriapi.WithTimeout(timeout))
+		require.True(t, errors.Is(err, vdriapi.ErrResolutionTimeout))
+		require.Nil(t, doc)
+		require.Less(t, int64(time.Since(start)), int64(slowDelay))
+
+		var partialErr *vdriapi.PartialResultError
+		require.False(t, errors.As(err, &partialErr))
+	})
+
+	t.Run("test timeout returns the partial result", func(t *testing.T) {
+		registry := newRegistry(map[string]time.Duration{controllerDID: slowDelay})
+
+		doc, err := registry.Resolve(subjectDID, vdriapi.WithVerifyProof(true), vdriapi.WithTimeout(timeout),
+			vdriapi.WithPartialResult(true))
+		require.True(t, errors.Is(err, vdriapi.ErrResolutionTimeout))
+		require.Equal(t, subjectDoc, doc)
+
+		var partialErr *vdriapi.PartialResultError
+		require.True(t, errors.As(err, &partialErr))
+		require.Equal(t, subjectDoc, partialErr.Doc)
+		require.Contains(t, err.Error(), "partial DID resolution result")
+
+		// the proof of the partial result is not verified yet
+		require.False(t, partialErr.ProofVerified)
+	})
+
+	t.Run("test resolution progress", func(t *testing.T) {
+		res := &resolution{}
+
+		res.setDoc(subjectDoc)
+		doc, proofVerified := res.getDoc()
+		require.Equal(t, subjectDoc, doc)
+		require.False(t, proofVerified)
+
+		res.setProofVerified()
+		_, proofVerified = res.getDoc()
+		require.True(t, proofVerified)
+
+		// a DID document whose proof is invalid is not a partial result
+		res.setDoc(nil)
+		doc, proofVerified = res.getDoc()
+		require.Nil(t, doc)
+		require.False(t, proofVerified)
+	})
+
+	t.Run("test timeout before any document is resolved", func(t *testing.T) {
+		registry := newRegistry(map[string]time.Duration{subjectDID: slowDelay})
+
+		doc, err := registry.Resolve(subjectDID, vdriapi.WithTimeout(timeout), vdriapi.WithPartialResult(true))
+		require.True(t, errors.Is(err, vdriapi.ErrResolutionTimeout))
+		require.Nil(t, doc)
+	})
+
+	t.Run("test errors are returned before the timeout", func(t *testing.T) {
+		_, err := newRegistry(nil).Resolve("did:example:unknown", vdriapi.WithTimeout(slowDelay))
+		require.True(t, errors.Is(err, vdriapi.ErrNotFound))
+	})
+}
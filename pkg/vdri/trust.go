@@ -0,0 +1,91 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"fmt"
+
+	diddoc "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+)
+
+// Allowlist is a trust registry trusting a fixed list of DIDs.
+type Allowlist struct {
+	dids map[string]bool
+}
+
+// NewAllowlist returns a trust registry trusting dids only.
+func NewAllowlist(dids ...string) *Allowlist {
+	a := &Allowlist{dids: make(map[string]bool, len(dids))}
+
+	for _, did := range dids {
+		a.dids[did] = true
+	}
+
+	return a
+}
+
+// IsTrusted returns true if did is in the allowlist.
+func (a *Allowlist) IsTrusted(did string) (bool, error) {
+	return a.dids[did], nil
+}
+
+// BatchResult is the result of the resolution of a DID of a batch (see ResolveBatch).
+type BatchResult struct {
+	DID string
+	Doc *diddoc.Doc
+	// TrustStatus is consulted from the trust registry of the resolution, it is empty without trust registry (see
+	// vdriapi.WithTrustRegistry).
+	TrustStatus vdriapi.TrustStatus
+	Err         error
+}
+
+// ResolveWithTrust resolves did as Resolve and returns the trust status of did consulted from the trust registry set
+// with vdriapi.WithTrustRegistry, empty without trust registry. Untrusted DIDs still resolve. If the trust registry
+// fails, the DID document is returned with TrustStatusUnknown and the error of the trust registry.
+func (r *Registry) ResolveWithTrust(did string, opts ...vdriapi.ResolveOpts) (*diddoc.Doc, vdriapi.TrustStatus,
+	error) {
+	resolveOpts := &vdriapi.ResolveDIDOpts{}
+
+	for _, opt := range opts {
+		opt(resolveOpts)
+	}
+
+	doc, err := r.Resolve(did, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resolveOpts.TrustRegistry == nil {
+		return doc, "", nil
+	}
+
+	trusted, err := resolveOpts.TrustRegistry.IsTrusted(did)
+	if err != nil {
+		return doc, vdriapi.TrustStatusUnknown, fmt.Errorf("trust registry lookup : %w", err)
+	}
+
+	if !trusted {
+		return doc, vdriapi.TrustStatusUntrusted, nil
+	}
+
+	return doc, vdriapi.TrustStatusTrusted, nil
+}
+
+// ResolveBatch resolves dids with the same options (see ResolveWithTrust), eg: the DIDs of the issuers of
+// credentials, and returns their results in the same order. The failure of a resolution does not fail the batch, it
+// is returned in the result of the DID.
+func (r *Registry) ResolveBatch(dids []string, opts ...vdriapi.ResolveOpts) []*BatchResult {
+	results := make([]*BatchResult, len(dids))
+
+	for i, did := range dids {
+		doc, status, err := r.ResolveWithTrust(did, opts...)
+		results[i] = &BatchResult{DID: did, Doc: doc, TrustStatus: status, Err: err}
+	}
+
+	return results
+}
@@ -0,0 +1,104 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+)
+
+type failingTrustRegistry struct{}
+
+func (f *failingTrustRegistry) IsTrusted(string) (bool, error) {
+	return false, errors.New("trust registry unreachable")
+}
+
+func TestRegistry_ResolveWithTrust(t *testing.T) {
+	const (
+		trustedDID   = "did:example:trusted"
+		untrustedDID = "did:example:untrusted"
+		unknownDID   = "did:example:unknown"
+	)
+
+	registry := New(&mockprovider.Provider{}, WithVDRI(&mockvdri.MockVDRI{
+		AcceptValue: true, ReadFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+			if didID == unknownDID {
+				return nil, vdriapi.ErrNotFound
+			}
+
+			return &did.Doc{ID: didID}, nil
+		}}))
+
+	allowlist := NewAllowlist(trustedDID)
+
+	t.Run("test trusted and untrusted DIDs are annotated", func(t *testing.T) {
+		doc, status, err := registry.ResolveWithTrust(trustedDID, vdriapi.WithTrustRegistry(allowlist))
+		require.NoError(t, err)
+		require.Equal(t, trustedDID, doc.ID)
+		require.Equal(t, vdriapi.TrustStatusTrusted, status)
+
+		doc, status, err = registry.ResolveWithTrust(untrustedDID, vdriapi.WithTrustRegistry(allowlist))
+		require.NoError(t, err)
+		require.Equal(t, untrustedDID, doc.ID)
+		require.Equal(t, vdriapi.TrustStatusUntrusted, status)
+	})
+
+	t.Run("test no trust status without trust registry", func(t *testing.T) {
+		doc, status, err := registry.ResolveWithTrust(untrustedDID)
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+		require.Empty(t, status)
+	})
+
+	t.Run("test trust registry failure", func(t *testing.T) {
+		doc, status, err := registry.ResolveWithTrust(trustedDID, vdriapi.WithTrustRegistry(&failingTrustRegistry{}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "trust registry lookup : trust registry unreachable")
+		require.NotNil(t, doc)
+		require.Equal(t, vdriapi.TrustStatusUnknown, status)
+	})
+
+	t.Run("test batch resolution", func(t *testing.T) {
+		results := registry.ResolveBatch([]string{trustedDID, untrustedDID, unknownDID},
+			vdriapi.WithTrustRegistry(allowlist))
+		require.Len(t, results, 3)
+
+		require.Equal(t, trustedDID, results[0].DID)
+		require.NoError(t, results[0].Err)
+		require.Equal(t, trustedDID, results[0].Doc.ID)
+		require.Equal(t, vdriapi.TrustStatusTrusted, results[0].TrustStatus)
+
+		require.Equal(t, untrustedDID, results[1].DID)
+		require.NoError(t, results[1].Err)
+		require.Equal(t, untrustedDID, results[1].Doc.ID)
+		require.Equal(t, vdriapi.TrustStatusUntrusted, results[1].TrustStatus)
+
+		require.Equal(t, unknownDID, results[2].DID)
+		require.True(t, errors.Is(results[2].Err, vdriapi.ErrNotFound))
+		require.Nil(t, results[2].Doc)
+		require.Empty(t, results[2].TrustStatus)
+	})
+}
+
+func TestAllowlist_IsTrusted(t *testing.T) {
+	allowlist := NewAllowlist("did:example:1", "did:example:2")
+
+	for did, expected := range map[string]bool{
+		"did:example:1": true, "did:example:2": true, "did:example:3": false, "": false,
+	} {
+		trusted, err := allowlist.IsTrusted(did)
+		require.NoError(t, err)
+		require.Equal(t, expected, trusted, did)
+	}
+}
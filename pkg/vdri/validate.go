@@ -0,0 +1,130 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net/url"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	diddoc "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+)
+
+// x25519KeySize is the size of the X25519 key agreement keys
+const x25519KeySize = 32
+
+// Validate runs the validation of the creation of a DID with Create, without creating it: no key is created in the
+// KMS, the DID document is not published nor stored. It returns the DID document Create would build, with ephemeral
+// keys, and the validation errors: the key options, the constraints of the DID method (the method must be supported
+// by a VDRI implementing vdriapi.DocBuilder, which builds the document and, eg: the request submitting it to a
+// ledger) and the service blocks of the document. The document is nil if it could not be built.
+func (r *Registry) Validate(didMethod string, opts ...vdriapi.DocOpts) (*diddoc.Doc, []error) {
+	docOpts := &vdriapi.CreateDIDOpts{KeyType: defaultKeyType}
+
+	for _, opt := range opts {
+		opt(docOpts)
+	}
+
+	method, err := r.resolveVDRI(didMethod)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	builder, ok := method.(vdriapi.DocBuilder)
+	if !ok {
+		return nil, []error{fmt.Errorf("did method %s does not support validation", didMethod)}
+	}
+
+	if errs := validateKeyOpts(docOpts); len(errs) > 0 {
+		return nil, errs
+	}
+
+	// ephemeral keys, the keys of the DID are created by the KMS on Create
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, []error{fmt.Errorf("generate ephemeral key: %w", err)}
+	}
+
+	if docOpts.KeyAgreement && docOpts.KeyAgreementKey == "" {
+		keyAgreementKey := make([]byte, x25519KeySize)
+
+		_, err = rand.Read(keyAgreementKey)
+		if err != nil {
+			return nil, []error{fmt.Errorf("generate ephemeral key agreement key: %w", err)}
+		}
+
+		opts = append(opts, vdriapi.WithKeyAgreementKey(base58.Encode(keyAgreementKey)))
+	}
+
+	doc, err := builder.BuildDoc(&vdriapi.PubKey{Value: base58.Encode(pubKey), Type: docOpts.KeyType},
+		r.applyDefaultDocOpts(docOpts, opts...)...)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return doc, validateServices(doc)
+}
+
+// validateKeyOpts validates the key options of the creation of a DID.
+func validateKeyOpts(docOpts *vdriapi.CreateDIDOpts) []error {
+	var errs []error
+
+	// the KMS creates Ed25519 keys
+	if docOpts.KeyType != defaultKeyType {
+		errs = append(errs, fmt.Errorf("key type %s not supported", docOpts.KeyType))
+	}
+
+	if docOpts.KeyAgreementKey != "" && len(base58.Decode(docOpts.KeyAgreementKey)) != x25519KeySize {
+		errs = append(errs, fmt.Errorf("key agreement key is not a base58 encoded X25519 key"))
+	}
+
+	return errs
+}
+
+// validateServices validates the service blocks of doc.
+func validateServices(doc *diddoc.Doc) []error {
+	var errs []error
+
+	for _, s := range doc.Service {
+		if s.Type == "" {
+			errs = append(errs, fmt.Errorf("service %s: type is empty", s.ID))
+		}
+
+		endpoint, err := url.Parse(s.ServiceEndpoint)
+
+		switch {
+		case s.ServiceEndpoint == "":
+			errs = append(errs, fmt.Errorf("service %s: endpoint is empty", s.ID))
+		case err != nil:
+			errs = append(errs, fmt.Errorf("service %s: invalid endpoint: %w", s.ID, err))
+		case !endpoint.IsAbs():
+			errs = append(errs, fmt.Errorf("service %s: endpoint %s is not an absolute URI", s.ID, s.ServiceEndpoint))
+		}
+
+		for _, key := range s.RecipientKeys {
+			if !hasPublicKey(doc, key) {
+				errs = append(errs, fmt.Errorf("service %s: recipient key %s not found", s.ID, key))
+			}
+		}
+	}
+
+	return errs
+}
+
+func hasPublicKey(doc *diddoc.Doc, id string) bool {
+	for _, pk := range doc.PublicKey {
+		if pk.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,141 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/httpbinding"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
+)
+
+func TestRegistry_Validate(t *testing.T) {
+	var published int32
+
+	ledger := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&published, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ledger.Close()
+
+	ledgerVDRI, err := httpbinding.New(ledger.URL, httpbinding.WithAccept(func(method string) bool {
+		return method == "ledger"
+	}))
+	require.NoError(t, err)
+
+	peerVDRI, err := peer.New(mockstorage.NewMockStoreProvider())
+	require.NoError(t, err)
+
+	// the KMS is not used to validate, no key is created
+	registry := New(&mockprovider.Provider{}, WithVDRI(ledgerVDRI), WithVDRI(peerVDRI),
+		WithDefaultServiceType(vdriapi.DIDCommServiceType), WithDefaultServiceEndpoint("https://agent.example.com"))
+
+	t.Run("test valid DID documents are built without publishing them", func(t *testing.T) {
+		doc, errs := registry.Validate("ledger")
+		require.Empty(t, errs)
+		require.NotNil(t, doc)
+		require.Len(t, doc.PublicKey, 1)
+		require.Equal(t, defaultKeyType, doc.PublicKey[0].Type)
+		require.Len(t, doc.Service, 1)
+		require.Equal(t, "https://agent.example.com", doc.Service[0].ServiceEndpoint)
+
+		doc, errs = registry.Validate("peer", vdriapi.WithKeyAgreement(true))
+		require.Empty(t, errs)
+		require.NotEmpty(t, doc.ID)
+		require.Len(t, doc.KeyAgreement, 1)
+
+		// the peer DID is not stored
+		_, err = peerVDRI.Read(doc.ID)
+		require.Error(t, err)
+	})
+
+	t.Run("test invalid constructions are reported", func(t *testing.T) {
+		for _, tc := range []struct {
+			name   string
+			method string
+			opts   []vdriapi.DocOpts
+			errs   []string
+		}{
+			{
+				name: "unsupported method", method: "unknown", errs: []string{"did method unknown not supported"},
+			},
+			{
+				name: "key material", method: "peer",
+				opts: []vdriapi.DocOpts{vdriapi.WithKeyType("RsaVerificationKey2018"), vdriapi.WithKeyAgreementKey("abc")},
+				errs: []string{"key type RsaVerificationKey2018 not supported", "key agreement key is not"},
+			},
+			{
+				name: "service endpoint", method: "ledger",
+				opts: []vdriapi.DocOpts{vdriapi.WithServiceEndpoint("/relative")},
+				errs: []string{"endpoint /relative is not an absolute URI"},
+			},
+			{
+				name: "request", method: "ledger",
+				opts: []vdriapi.DocOpts{vdriapi.WithRequestBuilder(func([]byte) (io.Reader, error) {
+					return nil, errors.New("invalid header")
+				})},
+				errs: []string{"failed to build request : invalid header"},
+			},
+		} {
+			doc, errs := registry.Validate(tc.method, tc.opts...)
+			require.Len(t, errs, len(tc.errs), tc.name)
+
+			for i, e := range tc.errs {
+				require.Contains(t, errs[i].Error(), e, tc.name)
+			}
+
+			if tc.name != "service endpoint" {
+				require.Nil(t, doc, tc.name)
+			}
+		}
+	})
+
+	t.Run("test method without validation", func(t *testing.T) {
+		doc, errs := New(&mockprovider.Provider{}, WithVDRI(&mockvdri.MockVDRI{AcceptValue: true})).Validate("other")
+		require.Nil(t, doc)
+		require.Len(t, errs, 1)
+		require.EqualError(t, errs[0], "did method other does not support validation")
+	})
+
+	t.Run("test service blocks", func(t *testing.T) {
+		doc, errs := New(&mockprovider.Provider{}, WithVDRI(ledgerVDRI),
+			WithDefaultServiceType("custom")).Validate("ledger")
+		require.NotNil(t, doc)
+		require.Len(t, errs, 1)
+		require.EqualError(t, errs[0], "service #endpoint-1: endpoint is empty")
+
+		doc.Service[0].Type = ""
+		doc.Service[0].ServiceEndpoint = "https://agent.example.com"
+		doc.Service[0].RecipientKeys = []string{"#key-2"}
+
+		errs = validateServices(doc)
+		require.Len(t, errs, 2)
+		require.EqualError(t, errs[0], "service #endpoint-1: type is empty")
+		require.EqualError(t, errs[1], "service #endpoint-1: recipient key #key-2 not found")
+
+		doc.Service[0].Type = "custom"
+		doc.Service[0].RecipientKeys = nil
+		doc.Service[0].ServiceEndpoint = "http://[::1"
+
+		errs = validateServices(doc)
+		require.Len(t, errs, 1)
+		require.Contains(t, errs[0].Error(), "invalid endpoint")
+	})
+
+	require.Zero(t, atomic.LoadInt32(&published))
+}
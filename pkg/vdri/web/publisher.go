@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package web
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+)
+
+const (
+	didMethodPrefix  = "did:web:"
+	wellKnownPath    = "/.well-known"
+	documentFileName = "did.json"
+
+	dirPermissions  = 0755
+	filePermissions = 0644
+)
+
+// Sink receives the did:web documents to serve, eg: a directory served by a web server or an upload to a storage
+// bucket. path is the URL path of the document on the DID domain, eg: /.well-known/did.json.
+type Sink interface {
+	Put(domain, path string, doc []byte) error
+}
+
+// FileSink writes the documents to a directory served by a web server as the root of the DID domain.
+type FileSink struct {
+	dir string
+}
+
+// NewFileSink returns a sink writing the documents to dir.
+func NewFileSink(dir string) *FileSink {
+	return &FileSink{dir: dir}
+}
+
+// Put writes doc at path in the directory of the sink, creating the parent directories of path.
+func (s *FileSink) Put(_, path string, doc []byte) error {
+	name := filepath.Join(s.dir, filepath.FromSlash(path))
+
+	err := os.MkdirAll(filepath.Dir(name), dirPermissions)
+	if err != nil {
+		return fmt.Errorf("create document directory : %w", err)
+	}
+
+	err = ioutil.WriteFile(name, doc, filePermissions)
+	if err != nil {
+		return fmt.Errorf("write document : %w", err)
+	}
+
+	return nil
+}
+
+// Publisher publishes the did:web documents to a sink, so they are served at the URL their DID resolves to.
+type Publisher struct {
+	sink Sink
+}
+
+// NewPublisher returns a publisher of the did:web documents to sink.
+func NewPublisher(sink Sink) *Publisher {
+	return &Publisher{sink: sink}
+}
+
+// Publish serializes doc and puts it in the sink at the path its DID resolves to (see DocumentPath).
+func (p *Publisher) Publish(doc *did.Doc) error {
+	domain, path, err := DocumentPath(doc.ID)
+	if err != nil {
+		return fmt.Errorf("publish did:web document : %w", err)
+	}
+
+	bytes, err := doc.JSONBytes()
+	if err != nil {
+		return fmt.Errorf("publish did:web document : %w", err)
+	}
+
+	err = p.sink.Put(domain, path, bytes)
+	if err != nil {
+		return fmt.Errorf("publish did:web document : %w", err)
+	}
+
+	return nil
+}
+
+// DocumentPath returns the domain, including its port if any, and the URL path of the document of the did:web DID
+// id: /.well-known/did.json for a DID without path, eg: did:web:example.com, or the path of the DID followed by
+// /did.json, eg: /user/alice/did.json for did:web:example.com:user:alice.
+func DocumentPath(id string) (string, string, error) {
+	if !strings.HasPrefix(id, didMethodPrefix) {
+		return "", "", fmt.Errorf("not a did:web DID : %s", id)
+	}
+
+	segments := strings.Split(strings.TrimPrefix(id, didMethodPrefix), ":")
+
+	// the port of the domain is percent encoded
+	domain, err := url.PathUnescape(segments[0])
+	if err != nil || domain == "" || strings.ContainsAny(domain, "/\\") {
+		return "", "", fmt.Errorf("invalid did:web domain : %s", id)
+	}
+
+	if len(segments) == 1 {
+		return domain, wellKnownPath + "/" + documentFileName, nil
+	}
+
+	for _, segment := range segments[1:] {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, "/\\%") {
+			return "", "", fmt.Errorf("invalid did:web path : %s", id)
+		}
+	}
+
+	return domain, "/" + strings.Join(segments[1:], "/") + "/" + documentFileName, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package web
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+)
+
+type failingSink struct{}
+
+func (f *failingSink) Put(string, string, []byte) error {
+	return errors.New("upload failed")
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	newDir := func(t *testing.T) string {
+		dir, err := ioutil.TempDir("", "didweb")
+		require.NoError(t, err)
+
+		t.Cleanup(func() { require.NoError(t, os.RemoveAll(dir)) })
+
+		return dir
+	}
+
+	t.Run("test document of a domain DID is written at the well-known path", func(t *testing.T) {
+		dir := newDir(t)
+
+		doc := &did.Doc{Context: []string{did.Context}, ID: "did:web:example.com"}
+		require.NoError(t, NewPublisher(NewFileSink(dir)).Publish(doc))
+
+		bytes, err := ioutil.ReadFile(filepath.Join(dir, ".well-known", "did.json"))
+		require.NoError(t, err)
+
+		published, err := did.ParseDocument(bytes)
+		require.NoError(t, err)
+		require.Equal(t, doc.ID, published.ID)
+	})
+
+	t.Run("test document of a DID with a path is written at its path", func(t *testing.T) {
+		dir := newDir(t)
+
+		doc := &did.Doc{Context: []string{did.Context}, ID: "did:web:example.com%3A3000:user:alice"}
+		require.NoError(t, NewPublisher(NewFileSink(dir)).Publish(doc))
+
+		bytes, err := ioutil.ReadFile(filepath.Join(dir, "user", "alice", "did.json"))
+		require.NoError(t, err)
+
+		published, err := did.ParseDocument(bytes)
+		require.NoError(t, err)
+		require.Equal(t, doc.ID, published.ID)
+	})
+
+	t.Run("test publish errors", func(t *testing.T) {
+		err := NewPublisher(NewFileSink(newDir(t))).Publish(&did.Doc{ID: "did:peer:123"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not a did:web DID")
+
+		err = NewPublisher(&failingSink{}).Publish(&did.Doc{Context: []string{did.Context}, ID: "did:web:example.com"})
+		require.EqualError(t, err, "publish did:web document : upload failed")
+
+		// the directory can't be created over a file
+		dir := newDir(t)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".well-known"), nil, filePermissions))
+
+		err = NewPublisher(NewFileSink(dir)).Publish(&did.Doc{Context: []string{did.Context}, ID: "did:web:example.com"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create document directory")
+	})
+}
+
+func TestDocumentPath(t *testing.T) {
+	t.Run("test DID document paths", func(t *testing.T) {
+		for id, expected := range map[string][2]string{
+			"did:web:w3c-ccg.github.io":            {"w3c-ccg.github.io", "/.well-known/did.json"},
+			"did:web:w3c-ccg.github.io:user:alice": {"w3c-ccg.github.io", "/user/alice/did.json"},
+			"did:web:example.com%3A3000":           {"example.com:3000", "/.well-known/did.json"},
+		} {
+			domain, path, err := DocumentPath(id)
+			require.NoError(t, err, id)
+			require.Equal(t, expected[0], domain, id)
+			require.Equal(t, expected[1], path, id)
+		}
+	})
+
+	t.Run("test invalid DIDs", func(t *testing.T) {
+		for _, id := range []string{
+			"did:example:123", "did:web:", "did:web:%zz", "did:web:example.com/a",
+			"did:web:example.com:..:etc", "did:web:example.com::alice", "did:web:example.com:a%2Fb",
+		} {
+			_, _, err := DocumentPath(id)
+			require.Error(t, err, id)
+		}
+	})
+}